@@ -4,6 +4,11 @@ import (
 	"embed"
 	"io/fs"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
@@ -54,19 +59,45 @@ const SwaggerUIHTML = `<!DOCTYPE html>
 				validatorUrl: null,
 				tryItOutEnabled: true
 			});
+			window.ui = ui;
 		};
 	</script>
 </body>
 </html>`
 
+// sandboxDemoHTML is the demo mode banner added to the documentation page, before </body>.
+// SANDBOX_PATH is replaced with the quoted path sandbox sessions are created with.
+const sandboxDemoHTML = `	<div id="sandbox-demo" style="position: fixed; bottom: 0; left: 0; right: 0; padding: 10px 20px; background: #1b1b1b; color: #fff; font-family: sans-serif; font-size: 14px; z-index: 1000;">
+		<strong>Demo mode:</strong> try the API with a throwaway sandbox account; it has tight quotas and is deleted with everything it creates once it expires.
+		<button id="sandbox-start" type="button" style="margin-left: 10px;">Start sandbox session</button>
+		<span id="sandbox-status" style="margin-left: 10px;"></span>
+	</div>
+	<script>
+		document.getElementById('sandbox-start').onclick = async function() {
+			const status = document.getElementById('sandbox-status');
+			status.textContent = 'Creating sandbox account...';
+			try {
+				const res = await fetch(SANDBOX_PATH, { method: 'POST' });
+				const body = await res.json();
+				if (!res.ok) {
+					status.textContent = (body.error && body.error.message) || ('Request failed with status ' + res.status);
+					return;
+				}
+				window.ui.preauthorizeApiKey('BearerAuth', body.data.access_token);
+				status.textContent = 'Signed in as ' + body.data.user.username + ' until ' + new Date(body.data.expires_at).toLocaleString() + '; requests from this page now use its token.';
+			} catch (err) {
+				status.textContent = 'Failed to create sandbox account: ' + err;
+			}
+		};
+	</script>
+</body>`
+
 // ServeOpenAPISpec serves the OpenAPI specification
 func ServeOpenAPISpec(c *gin.Context) {
 	// Read the embedded OpenAPI spec file
 	spec, err := openAPIFile.ReadFile("openapi.yaml")
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "Failed to load OpenAPI specification",
-		})
+		response.Error(c, errors.WrapError(err, "Failed to load OpenAPI specification"))
 		return
 	}
 
@@ -90,6 +121,42 @@ func ServeOpenAPIDocs(c *gin.Context) {
 	c.String(http.StatusOK, SwaggerUIHTML)
 }
 
+// devServerURL is the server URL the specification is written with
+const devServerURL = "http://localhost:8080"
+
+// SpecHandler serves the OpenAPI specification like ServeOpenAPISpec, with its server entries
+// pointing at serverURL, the external URL of the deployment
+func SpecHandler(serverURL string) gin.HandlerFunc {
+	spec, err := openAPIFile.ReadFile("openapi.yaml")
+	if err != nil {
+		return ServeOpenAPISpec
+	}
+	if serverURL != "" && serverURL != devServerURL {
+		spec = []byte(strings.ReplaceAll(string(spec), "- url: "+devServerURL, "- url: "+serverURL))
+	}
+
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/yaml", spec)
+	}
+}
+
+// DocsHandler serves the documentation page like ServeOpenAPIDocs, loading the specification
+// from specPath. With a sandboxPath it also offers a demo mode, signing the visitor in as a
+// sandbox account created by a POST to that path and pre-filling its access token in
+// "Try it out".
+func DocsHandler(specPath, sandboxPath string) gin.HandlerFunc {
+	page := strings.Replace(SwaggerUIHTML, `"/openapi.yaml"`, strconv.Quote(specPath), 1)
+	if sandboxPath != "" {
+		demo := strings.Replace(sandboxDemoHTML, "SANDBOX_PATH", strconv.Quote(sandboxPath), 1)
+		page = strings.Replace(page, "</body>", demo, 1)
+	}
+
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/html; charset=utf-8")
+		c.String(http.StatusOK, page)
+	}
+}
+
 // GetOpenAPIFileSystem returns the filesystem for the OpenAPI spec
 func GetOpenAPIFileSystem() fs.FS {
 	return openAPIFile
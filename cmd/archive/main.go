@@ -0,0 +1,99 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"time"
+
+	"go-backend-api/internal/config"
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/repositories"
+	"go-backend-api/internal/services"
+	"go-backend-api/internal/storage"
+)
+
+// Restores partitions archived by the partition maintenance job from ARCHIVE_STORAGE.
+// A restored partition is kept past its retention until it is released, after which the
+// job archives and drops it again:
+//
+//	go run ./cmd/archive restore -table audit_logs -month 2025-09
+//	go run ./cmd/archive release -table audit_logs -month 2025-09
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+	if command != "restore" && command != "release" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	table := flags.String("table", "", "partitioned table: "+fmt.Sprint(models.PartitionedTables))
+	monthFlag := flags.String("month", "", "month of the partition, as YYYY-MM")
+	_ = flags.Parse(os.Args[2:])
+
+	if !slices.Contains(models.PartitionedTables, *table) {
+		log.Fatalf("-table must be one of %v", models.PartitionedTables)
+	}
+	month, err := time.Parse("2006-01", *monthFlag)
+	if err != nil {
+		log.Fatal("-month must be given as YYYY-MM")
+	}
+
+	cfg := config.LoadConfig()
+	var policy services.PartitionPolicy
+	if cfg.Archive.Storage != "" {
+		policy.Archive, err = storage.New(storage.Config{
+			Backend:           cfg.Archive.Storage,
+			LocalDir:          cfg.Archive.LocalDir,
+			S3Bucket:          cfg.Archive.S3Bucket,
+			S3Region:          cfg.Archive.S3Region,
+			S3Endpoint:        cfg.Archive.S3Endpoint,
+			S3AccessKeyID:     cfg.Archive.S3AccessKeyID,
+			S3SecretAccessKey: cfg.Archive.S3SecretAccessKey,
+			S3PathStyle:       cfg.Archive.S3PathStyle,
+			Prefix:            cfg.Archive.Prefix,
+		})
+		if err != nil {
+			log.Fatal("Failed to initialize archive storage:", err)
+		}
+	}
+
+	if err := database.Connect(cfg.Database.URL, database.Options{
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		QueryTimeout:     cfg.Database.QueryTimeout,
+	}); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	partitionService := services.NewPartitionService(repositories.NewPartitionRepository(database.GetDB()), repositories.NewAuditLogRepository(database.GetDB()), policy)
+	partition := *table + "_" + month.Format("2006_01")
+
+	switch command {
+	case "restore":
+		rows, err := partitionService.Restore(*table, month)
+		if err != nil {
+			log.Fatal("Failed to restore partition: ", err)
+		}
+		log.Printf("Restored %d rows into %s; release it once done so that retention applies again", rows, partition)
+	case "release":
+		if err := partitionService.Release(*table, month); err != nil {
+			log.Fatal("Failed to release partition: ", err)
+		}
+		log.Printf("Released %s; the partition job archives and drops it once past retention", partition)
+	}
+}
+
+func usage() {
+	log.Fatal("usage: archive restore|release -table <table> -month YYYY-MM")
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/user"
+	"time"
+
+	"go-backend-api/internal/config"
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/repositories"
+	"go-backend-api/internal/services"
+)
+
+// Manages the break-glass accounts of admins from the host. Provisioning prints a credential
+// once and leaves the account disabled; enabling it lets the admin sign in with
+// POST /api/v1/auth/break-glass, bypassing step-up verification, until it expires:
+//
+//	go run ./cmd/breakglass provision -email admin@example.com
+//	go run ./cmd/breakglass enable -email admin@example.com -for 30m
+//	go run ./cmd/breakglass disable -email admin@example.com
+//	go run ./cmd/breakglass list
+func main() {
+	log.SetFlags(0)
+	if len(os.Args) < 2 {
+		usage()
+	}
+	command := os.Args[1]
+	if command != "provision" && command != "enable" && command != "disable" && command != "list" {
+		usage()
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	email := flags.String("email", "", "email address of the admin")
+	duration := flags.Duration("for", 0, "how long to enable the account (BREAK_GLASS_DEFAULT_DURATION when not given)")
+	_ = flags.Parse(os.Args[2:])
+	if command != "list" && *email == "" {
+		log.Fatal("-email is required")
+	}
+
+	cfg := config.LoadConfig()
+	ids.SetTimeOrdered(cfg.App.TimeOrderedIDs)
+	if err := database.Connect(cfg.Database.URL, database.Options{
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		QueryTimeout:     cfg.Database.QueryTimeout,
+	}); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	db := database.GetDB()
+	userRepo := repositories.NewUserRepository(db, clock.Real)
+	breakGlassService := services.NewBreakGlassService(repositories.NewBreakGlassRepository(db), userRepo, repositories.NewRefreshTokenRepository(db, clock.Real),
+		repositories.NewAuditLogRepository(db), services.BreakGlassConfig{
+			DefaultDuration: cfg.Security.BreakGlassDefaultDuration,
+			MaxDuration:     cfg.Security.BreakGlassMaxDuration,
+		})
+	by := operator()
+
+	switch command {
+	case "provision":
+		credential, err := breakGlassService.Provision(*email, by)
+		if err != nil {
+			log.Fatal("Failed to provision break-glass account: ", err)
+		}
+		log.Printf("Provisioned the break-glass account of %s; it is disabled until enabled. Store this credential offline, it is not shown again:", *email)
+		fmt.Println(credential)
+	case "enable":
+		account, err := breakGlassService.Enable(*email, *duration, by)
+		if err != nil {
+			log.Fatal("Failed to enable break-glass account: ", err)
+		}
+		log.Printf("Enabled the break-glass account of %s until %s; its sessions are revoked when it expires", *email, account.EnabledUntil.Format(time.RFC3339))
+	case "disable":
+		if err := breakGlassService.Disable(*email, by); err != nil {
+			log.Fatal("Failed to disable break-glass account: ", err)
+		}
+		log.Printf("Disabled the break-glass account of %s and revoked their sessions", *email)
+	case "list":
+		accounts, err := breakGlassService.List()
+		if err != nil {
+			log.Fatal("Failed to list break-glass accounts: ", err)
+		}
+		for _, account := range accounts {
+			admin, err := userRepo.GetByID(account.UserID)
+			if err != nil {
+				log.Fatal("Failed to get user: ", err)
+			}
+			status := "disabled"
+			if account.EnabledAt(time.Now()) {
+				status = fmt.Sprintf("enabled until %s by %s", account.EnabledUntil.Format(time.RFC3339), *account.EnabledBy)
+			}
+			lastUsed := "never"
+			if account.LastUsedAt != nil {
+				lastUsed = account.LastUsedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%s\t%s\tlast used %s\n", admin.Email, status, lastUsed)
+		}
+	}
+}
+
+// operator identifies who runs the command, as user@host, for the audit log
+func operator() string {
+	name := "unknown"
+	if current, err := user.Current(); err == nil {
+		name = current.Username
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return name + "@" + host
+}
+
+func usage() {
+	log.Fatal("usage: breakglass provision|enable|disable|list [-email <admin email>] [-for <duration>]")
+}
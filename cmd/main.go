@@ -1,39 +1,111 @@
 package main
 
 import (
+	"expvar"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
 	"go-backend-api/api"
+	"go-backend-api/internal/alerting"
 	"go-backend-api/internal/config"
 	"go-backend-api/internal/database"
+	"go-backend-api/internal/events"
 	"go-backend-api/internal/handlers"
+	"go-backend-api/internal/jobs"
 	"go-backend-api/internal/logger"
+	"go-backend-api/internal/mailer"
 	"go-backend-api/internal/middleware"
+	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/fieldcrypt"
+	"go-backend-api/internal/pkg/geoip"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/links"
+	"go-backend-api/internal/pkg/metrics"
+	"go-backend-api/internal/pkg/redact"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+	"go-backend-api/internal/pkg/shortid"
+	"go-backend-api/internal/pkg/validation"
+	"go-backend-api/internal/push"
 	"go-backend-api/internal/repositories"
+	"go-backend-api/internal/search"
+	"go-backend-api/internal/selftest"
 	"go-backend-api/internal/services"
+	"go-backend-api/internal/storage"
+	"go-backend-api/internal/webhooks/inbound"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+// apiPrefix is the path prefix of the versioned API
+const apiPrefix = "/api/v1"
+
 func main() {
 	// Load configuration
 	cfg := config.LoadConfig()
 
-	// Initialize logger
+	// "main --check" (or "main healthcheck") runs the self-test instead of serving and exits
+	// non-zero when a check fails; add --json for a machine-readable report
+	if args := os.Args[1:]; len(args) > 0 && (args[0] == "--check" || args[0] == "healthcheck") {
+		runSelfTest(cfg, slices.Contains(args[1:], "--json"))
+	}
+
+	// Initialize logger; the standard logger used by services is redacted as well
 	logger := logger.NewLogger(cfg.App.LogLevel)
+	log.SetOutput(redact.NewWriter(os.Stderr))
 
 	// Connect to database
-	if err := database.Connect(cfg.Database.URL); err != nil {
+	if cfg.Database.QueryTimeout > 0 && cfg.Database.QueryTimeout <= cfg.Database.StatementTimeout {
+		logger.Warnf("DB_QUERY_TIMEOUT (%s) should exceed DB_STATEMENT_TIMEOUT (%s), or timed out statements close their connections", cfg.Database.QueryTimeout, cfg.Database.StatementTimeout)
+	}
+	if err := database.Connect(cfg.Database.URL, database.Options{
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		QueryTimeout:     cfg.Database.QueryTimeout,
+	}); err != nil {
 		logger.Fatal("Failed to connect to database:", err)
 	}
 	defer database.Close()
+	if cfg.Database.LogQueries {
+		database.SetQueryLogger(logger.Query)
+	}
 
 	// Note: Run migrations manually using the SQL file
 	// psql -h localhost -p 5433 -U go_user -d go_learning_db -f internal/database/migrations_v2.sql
+	switch cfg.Database.SchemaCheck {
+	case database.SchemaCheckOff, database.SchemaCheckWarn, database.SchemaCheckStrict:
+	default:
+		logger.Fatalf("Invalid DB_SCHEMA_CHECK %q: use %q, %q or %q", cfg.Database.SchemaCheck, database.SchemaCheckOff, database.SchemaCheckWarn, database.SchemaCheckStrict)
+	}
+	if err := database.VerifySchema(database.GetDB(), cfg.Database.SchemaCheck); err != nil {
+		logger.Fatal("Schema check failed:", err)
+	}
 
 	// Set Gin mode
 	if cfg.IsProduction() {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	response.SetEnvelope(cfg.Server.ResponseEnvelope)
+	ids.SetTimeOrdered(cfg.App.TimeOrderedIDs)
+
+	// Short link IDs are keyed, so post numbers cannot be enumerated through them
+	if cfg.Security.ShortIDSecret != "" {
+		shortid.SetKey(cfg.Security.ShortIDSecret)
+	} else if cfg.IsProduction() {
+		logger.Fatal("SHORT_ID_SECRET must be set in production")
+	} else {
+		logger.Warn("SHORT_ID_SECRET is not set: short links use the public development key")
+	}
 
 	// Initialize JWT manager
 	jwtManager := auth.NewJWTManager(
@@ -44,37 +116,645 @@ func main() {
 		cfg.JWT.AccessExpiration,
 		cfg.JWT.RefreshExpiration,
 	)
+	if len(cfg.JWT.CustomClaims) > 0 {
+		jwtManager.SetClaimsEnricher(auth.StaticClaims(cfg.JWT.CustomClaims))
+	}
+
+	// Background goroutines (reloads, cleanups, scheduled jobs) stop when main returns
+	stopBackground := make(chan struct{})
+	defer close(stopBackground)
+
+	// Load reserved username and blocked email domain lists
+	blocklist, err := security.NewBlocklist(cfg.Security.ReservedUsernames, cfg.Security.BlockedEmailDomains, cfg.Security.BlocklistFile)
+	if err != nil {
+		logger.Fatal("Failed to load blocklist:", err)
+	}
+	blocklist.StartAutoReload(cfg.Security.BlocklistRefresh, stopBackground)
+
+	// Load the keys user fields are encrypted at rest with
+	if cfg.Security.FieldEncryptionKeys != "" {
+		keyring, err := fieldcrypt.NewKeyring(cfg.Security.FieldEncryptionKeys, cfg.Security.BlindIndexKey)
+		if err != nil {
+			logger.Fatal("Failed to load field encryption keys:", err)
+		}
+		fieldcrypt.SetDefault(keyring)
+	}
+
+	// Watch database connectivity, so readiness and alerts follow outages and reconnections
+	dbMonitor := database.NewMonitor(database.GetDB(), database.MonitorConfig{
+		Interval:         cfg.Database.HealthInterval,
+		FailureThreshold: cfg.Database.HealthFailures,
+		MaxBackoff:       cfg.Database.ReconnectMaxBackoff,
+	})
+	dbMonitor.Start(stopBackground)
+	expvar.Publish("database_health", expvar.Func(func() interface{} { return dbMonitor.Status() }))
+
+	// Connect to the read replica, whose lag decides whether stale-ok reads may use it
+	var replicaMonitor *database.ReplicaMonitor
+	if cfg.Database.ReplicaURL != "" {
+		replicaDB, err := database.Open(cfg.Database.ReplicaURL, database.Options{
+			MaxOpenConns:     cfg.Database.MaxOpenConns,
+			MaxIdleConns:     cfg.Database.MaxIdleConns,
+			ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+			StatementTimeout: cfg.Database.StatementTimeout,
+			QueryTimeout:     cfg.Database.QueryTimeout,
+		})
+		if err != nil {
+			logger.Fatal("Failed to connect to read replica:", err)
+		}
+		defer replicaDB.Close()
+		replicaMonitor = database.NewReplicaMonitor(replicaDB, cfg.Database.ReplicaLagInterval, cfg.Database.ReplicaMaxStaleness)
+		replicaMonitor.Start(stopBackground)
+		expvar.Publish("database_replica", expvar.Func(func() interface{} { return replicaMonitor.Status() }))
+	}
+
+	// Initialize CAPTCHA verification for auth endpoints, requested after repeated failures from an IP
+	var captchaGuard *security.CaptchaGuard
+	if cfg.Security.CaptchaEnabled {
+		verifier, err := security.NewCaptchaVerifier(cfg.Security.CaptchaProvider, cfg.Security.CaptchaSecret)
+		if err != nil {
+			logger.Fatal("Failed to initialize captcha verifier:", err)
+		}
+		failures := security.NewFailureTracker(cfg.Security.CaptchaFailureWindow, 0)
+		failures.StartCleanup(cfg.Security.CaptchaFailureWindow, stopBackground)
+		captchaGuard = security.NewCaptchaGuard(verifier, failures, cfg.Security.CaptchaThreshold)
+	}
+
+	// Bound concurrent password hashing so login storms queue (and are shed) instead of exhausting CPU
+	hashPool := security.NewHashPool(cfg.Security.HashMaxParallel, cfg.Security.HashMaxQueue)
+
+	// Destructive admin requests must be signed, so a leaked admin token alone cannot destroy
+	// data. Only development and test setups may run without a signing secret. Used signatures
+	// are recorded in the database, so a signed request is accepted once across instances.
+	usedSignatureRepo := repositories.NewUsedSignatureRepository(database.GetDB())
+	var requestSigner *security.RequestSigner
+	if cfg.Security.AdminSigningSecret != "" {
+		requestSigner = security.NewRequestSigner(cfg.Security.AdminSigningSecret, cfg.Security.AdminSignatureMaxSkew, usedSignatureRepo)
+	} else if cfg.IsProduction() {
+		logger.Fatal("ADMIN_SIGNING_SECRET must be set in production")
+	} else {
+		logger.Warn("ADMIN_SIGNING_SECRET is not set: destructive admin requests are accepted without a signature")
+	}
+
+	// Per-route latency and error metrics, also watched by alerting
+	routeMetrics := metrics.NewRouteMetrics(cfg.Server.RouteMetricsWindow)
+	loginFailures := &metrics.Counter{}
+	breakGlassLogins := &metrics.Counter{}
+
+	// Rate limit per client IP and route, with a bounded number of buckets
+	rateLimiter := security.NewRateLimiter(cfg.Security.RateLimitRequests, cfg.Security.RateLimitWindow, cfg.Security.RateLimitBurst, cfg.Security.RateLimitMaxEntries)
+	rateLimiter.StartCleanup(5*time.Minute, stopBackground)
+
+	// Sandbox accounts of the API console demo mode get quotas of their own, and so does
+	// creating them
+	var sandboxQuota, sandboxSessions *security.RateLimiter
+	if cfg.Sandbox.Enabled {
+		sandboxQuota = security.NewRateLimiter(cfg.Sandbox.RateLimitRequests, cfg.Sandbox.RateLimitWindow, cfg.Sandbox.RateLimitRequests, 0)
+		sandboxQuota.StartCleanup(5*time.Minute, stopBackground)
+		sandboxSessions = security.NewRateLimiter(cfg.Sandbox.SessionsPerHour, time.Hour, cfg.Sandbox.SessionsPerHour, 0)
+		sandboxSessions.StartCleanup(5*time.Minute, stopBackground)
+		if cfg.IsProduction() {
+			logger.Warn("SANDBOX_ENABLED is on: anyone can create sandbox accounts from /docs")
+		}
+	}
 
 	// Initialize repositories
-	userRepo := repositories.NewUserRepository(database.GetDB())
+	userRepo := repositories.NewUserRepository(database.GetDB(), clock.Real)
 	postRepo := repositories.NewPostRepository(database.GetDB())
-	refreshTokenRepo := repositories.NewRefreshTokenRepository(database.GetDB())
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(database.GetDB(), clock.Real)
+	usernameHistoryRepo := repositories.NewUsernameHistoryRepository(database.GetDB())
+	emailChangeRepo := repositories.NewEmailChangeRepository(database.GetDB())
+	inviteRepo := repositories.NewInviteRepository(database.GetDB())
+	auditLogRepo := repositories.NewAuditLogRepository(database.GetDB())
+	loginChallengeRepo := repositories.NewLoginChallengeRepository(database.GetDB())
+	apiKeyRepo := repositories.NewAPIKeyRepository(database.GetDB())
+	apiUsageRepo := repositories.NewAPIUsageRepository(database.GetDB())
+	oauthClientRepo := repositories.NewOAuthClientRepository(database.GetDB())
+	loginStatsRepo := repositories.NewLoginStatsRepository(database.GetDB())
+	authorStatsRepo := repositories.NewAuthorStatsRepository(database.GetDB())
+	partitionRepo := repositories.NewPartitionRepository(database.GetDB())
+	profileRepo := repositories.NewProfileRepository(database.GetDB())
+	commentRepo := repositories.NewCommentRepository(database.GetDB())
+	reactionRepo := repositories.NewReactionRepository(database.GetDB())
+	postLockRepo := repositories.NewPostLockRepository(database.GetDB())
+	postAutosaveRepo := repositories.NewPostAutosaveRepository(database.GetDB())
+	policyRepo := repositories.NewPolicyRepository(database.GetDB())
+	parentalConsentRepo := repositories.NewParentalConsentRepository(database.GetDB())
+	legalHoldRepo := repositories.NewLegalHoldRepository(database.GetDB())
+	breakGlassRepo := repositories.NewBreakGlassRepository(database.GetDB())
+	incidentModeRepo := repositories.NewIncidentModeRepository(database.GetDB())
+	syncRepo := repositories.NewSyncRepository(database.GetDB())
+	deletedRecordRepo := repositories.NewDeletedRecordRepository(database.GetDB())
+	savedSearchRepo := repositories.NewSavedSearchRepository(database.GetDB())
+	feedRepo := repositories.NewFeedRepository(database.GetDB())
+	billingRepo := repositories.NewBillingRepository(database.GetDB())
+	webhookEventRepo := repositories.NewWebhookEventRepository(database.GetDB())
+	digestRepo := repositories.NewDigestRepository(database.GetDB())
+	emailTemplateRepo := repositories.NewEmailTemplateRepository(database.GetDB())
+	pushRepo := repositories.NewPushRepository(database.GetDB())
+	announcementRepo := repositories.NewAnnouncementRepository(database.GetDB())
+
+	// Domain events (plan, subscription, post and comment changes) are logged; downstream consumers subscribe here
+	eventBus := events.NewBus()
+	eventBus.Subscribe(events.All, func(event *events.Event) {
+		logger.Infof("Event %s: %+v", event.Type, event.Data)
+	})
+
+	// Plans set the quotas, per-user rate limits and features of users. Access tokens carry
+	// the plan of their user when issued, and API key requests the current one.
+	planService := services.NewPlanService(userRepo, nil, map[string]*models.PlanLimits{
+		models.PlanFree: planLimits(models.PlanFree, cfg.Plans.Free),
+		models.PlanPro:  planLimits(models.PlanPro, cfg.Plans.Pro),
+	}, eventBus)
+	jwtManager.SetPlanResolver(planService)
+	planLimiters := make(map[string]*security.RateLimiter)
+	for _, plan := range []string{models.PlanFree, models.PlanPro} {
+		if requests := planService.Limits(plan).RateLimitRequests; requests > 0 {
+			planLimiters[plan] = security.NewRateLimiter(requests, time.Minute, requests, 0)
+			planLimiters[plan].StartCleanup(5*time.Minute, stopBackground)
+		}
+	}
+
+	// Initialize GeoIP lookups; location data is simply omitted when no database is available
+	var geoLocator security.GeoLocator
+	locator, err := geoip.Open(cfg.GeoIP.CityDBPath, cfg.GeoIP.ASNDBPath)
+	if err != nil {
+		logger.Warnf("GeoIP disabled: %v", err)
+	} else if locator != nil {
+		geoLocator = locator
+		defer locator.Close()
+	}
+
+	// Initialize sign-in risk scoring (step-up verification for risky logins)
+	var riskScorer *security.RiskScorer
+	if cfg.Security.RiskScoringEnabled {
+		datacenterASNs := cfg.Security.RiskDatacenterASNs
+		if len(datacenterASNs) == 0 {
+			datacenterASNs = security.DefaultDatacenterASNs
+		}
+		riskScorer = security.NewRiskScorer(geoLocator, datacenterASNs)
+	}
+
+	// Email templates saved by admins replace the embedded defaults
+	emailTemplateService := services.NewEmailTemplateService(emailTemplateRepo, clock.Real)
+	if err := emailTemplateService.Refresh(); err != nil {
+		logger.Error("Failed to load email templates, using the defaults:", err)
+	}
+	mailer.SetOverrides(emailTemplateService)
+
+	// Initialize mailer; addresses the mail provider reported as bounced or complaining are not mailed
+	emailDeliveryService := services.NewEmailDeliveryService(userRepo)
+	mail := mailer.WithSuppression(mailer.New(mailer.Config{
+		Driver:       cfg.Mail.Driver,
+		From:         cfg.Mail.From,
+		SMTPHost:     cfg.Mail.SMTPHost,
+		SMTPPort:     cfg.Mail.SMTPPort,
+		SMTPUsername: cfg.Mail.SMTPUsername,
+		SMTPPassword: cfg.Mail.SMTPPassword,
+	}), emailDeliveryService)
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, refreshTokenRepo, jwtManager)
-	postService := services.NewPostService(postRepo, userRepo)
+	if cfg.App.DeletedUserPosts != models.DeletedUserPostsDelete && cfg.App.DeletedUserPosts != models.DeletedUserPostsAnonymize {
+		logger.Fatalf("Invalid DELETED_USER_POSTS %q: use %q or %q", cfg.App.DeletedUserPosts, models.DeletedUserPostsDelete, models.DeletedUserPostsAnonymize)
+	}
+	// Password requirements come from the environment and apply to registration and password changes
+	passwordPolicy := security.DefaultPasswordPolicy()
+	passwordPolicy.MinLength = cfg.Security.PasswordMinLength
+	passwordPolicy.RequireUppercase = cfg.Security.PasswordRequireUpper
+	passwordPolicy.RequireLowercase = cfg.Security.PasswordRequireLower
+	passwordPolicy.RequireNumbers = cfg.Security.PasswordRequireNumber
+	passwordPolicy.RequireSpecial = cfg.Security.PasswordRequireSpecial
+
+	userService := services.NewUserService(userRepo, refreshTokenRepo, usernameHistoryRepo, emailChangeRepo, inviteRepo, auditLogRepo, loginChallengeRepo, policyRepo, parentalConsentRepo, legalHoldRepo, breakGlassRepo, jwtManager, blocklist, hashPool, riskScorer, geoLocator, mail, services.UserServiceConfig{
+		UsernameCooldown:   cfg.Security.UsernameCooldown,
+		EmailChangeTTL:     cfg.Security.EmailChangeTTL,
+		EmailChangeUndoTTL: cfg.Security.EmailChangeUndoTTL,
+		BaseURL:            cfg.App.ExternalURL(""),
+		InviteOnly:         cfg.Security.InviteOnly,
+		StepUpThreshold:    cfg.Security.RiskStepUpThreshold,
+		StepUpCodeTTL:      cfg.Security.StepUpCodeTTL,
+		StepUpMaxAttempts:  cfg.Security.StepUpMaxAttempts,
+		NotifyNewSignIns:   cfg.Security.NotifyNewSignIns,
+		MaxDevicesPerUser:  cfg.Security.MaxDevicesPerUser,
+		DeletedUserPosts:   cfg.App.DeletedUserPosts,
+		PasswordPolicy:     passwordPolicy,
+		LoginFailures:      loginFailures,
+		BreakGlassLogins:   breakGlassLogins,
+		ImportMaxRows:      cfg.Security.ImportMaxRows,
+		ParentalConsentAge: cfg.AgeGate.ConsentAge,
+		ParentalConsentTTL: cfg.AgeGate.ConsentTTL,
+		MinimumAge:         cfg.AgeGate.MinimumAge,
+		RequireBirthdate:   cfg.AgeGate.RequireBirthdate,
+	})
+	inviteService := services.NewInviteService(inviteRepo, services.InviteServiceConfig{
+		QuotaPerUser: cfg.Security.InviteQuotaPerUser,
+		DefaultTTL:   cfg.Security.InviteTTL,
+	})
+	policyService := services.NewPolicyService(policyRepo)
+	legalHoldService := services.NewLegalHoldService(legalHoldRepo, userRepo, auditLogRepo)
+	tokenRevocationService := services.NewTokenRevocationService(userRepo, refreshTokenRepo, oauthClientRepo, incidentModeRepo, auditLogRepo, jwtManager, services.TokenRevocationConfig{
+		IncidentAccessTokenTTL: cfg.Security.IncidentAccessTokenTTL,
+		IncidentMode:           cfg.Security.IncidentMode,
+	})
+	if err := tokenRevocationService.Refresh(); err != nil {
+		logger.Error("Failed to load incident mode:", err)
+	}
+	if tokenRevocationService.IncidentMode().Enabled {
+		logger.Warnf("Incident mode is on: access tokens are issued for %s", cfg.Security.IncidentAccessTokenTTL)
+	}
+	breakGlassService := services.NewBreakGlassService(breakGlassRepo, userRepo, refreshTokenRepo, auditLogRepo, services.BreakGlassConfig{
+		DefaultDuration: cfg.Security.BreakGlassDefaultDuration,
+		MaxDuration:     cfg.Security.BreakGlassMaxDuration,
+	})
+	tombstoneService := services.NewTombstoneService(deletedRecordRepo, cfg.Database.TombstoneRetentionDays)
+	syncService := services.NewSyncService(syncRepo, tombstoneService)
+	postServiceConfig := services.PostServiceConfig{
+		ExcerptWords:       cfg.Posts.ExcerptWords,
+		WordsPerMinute:     cfg.Posts.WordsPerMinute,
+		MaxContentBytes:    cfg.Posts.MaxContentBytes,
+		LockTTL:            cfg.Posts.LockTTL,
+		ParentalConsentAge: cfg.AgeGate.ConsentAge,
+		Events:             eventBus,
+	}
+	// Posts are also indexed in the search backend, if any; searches fall back to Postgres
+	// while it cannot be reached
+	if cfg.Search.Backend != "" {
+		index, err := search.New(search.Config{
+			Backend:  cfg.Search.Backend,
+			URL:      cfg.Search.URL,
+			Index:    cfg.Search.Index,
+			Username: cfg.Search.Username,
+			Password: cfg.Search.Password,
+		})
+		if index == nil {
+			logger.Fatal("Failed to initialize search backend:", err)
+		}
+		if err != nil {
+			logger.Warnf("Search backend %s is unavailable, searching Postgres until it is: %v", index.Name(), err)
+		}
+		postServiceConfig.Search = search.NewIndexer(index, cfg.Search.QueueSize)
+		postServiceConfig.Search.Start(stopBackground)
+	}
+	// With fan-out-on-write, new posts and follows are written to the stored feeds in the background
+	if cfg.Posts.FeedFanOut {
+		postServiceConfig.Feed = services.NewFeedFanOut(feedRepo, cfg.Posts.FeedMaxEntries, cfg.Posts.FeedQueueSize)
+		postServiceConfig.Feed.Start(stopBackground)
+	}
+	postService := services.NewPostService(postRepo, userRepo, reactionRepo, postLockRepo, postAutosaveRepo, legalHoldRepo, auditLogRepo, postServiceConfig)
+	// Post listings of clients sending "Prefer: stale-ok" are read from the replica, if any;
+	// only its read methods are used, so the repositories that write stay on the primary
+	var replicaPostService models.PostService
+	if replicaMonitor != nil {
+		replicaDB := replicaMonitor.DB()
+		replicaPostService = services.NewPostService(repositories.NewPostRepository(replicaDB), repositories.NewUserRepository(replicaDB, clock.Real), repositories.NewReactionRepository(replicaDB),
+			postLockRepo, postAutosaveRepo, legalHoldRepo, auditLogRepo, postServiceConfig)
+	}
+	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, reactionRepo, mail, services.CommentServiceConfig{
+		MaxDepth:    cfg.Posts.CommentMaxDepth,
+		MaxMentions: cfg.Posts.CommentMaxMentions,
+		Events:      eventBus,
+	})
+	reactionService := services.NewReactionService(reactionRepo, postRepo, commentRepo)
+	savedSearchService := services.NewSavedSearchService(savedSearchRepo, userRepo, planService, mail, services.SavedSearchServiceConfig{
+		BaseURL:    cfg.App.ExternalURL(""),
+		MaxPerUser: cfg.Posts.SavedSearchLimit,
+		AlertPosts: cfg.Posts.SavedSearchAlertPosts,
+	})
+	digestService := services.NewDigestService(digestRepo, userRepo, mail, services.DigestServiceConfig{
+		BaseURL:   cfg.App.ExternalURL(""),
+		Posts:     cfg.Digest.Posts,
+		BatchSize: cfg.Digest.BatchSize,
+		SendRate:  cfg.Digest.SendRate,
+	})
+	// Published posts and new comments are pushed to the devices of the subscribers of their topics
+	pushSenders, err := push.New(push.Config{
+		FCMCredentialsFile: cfg.Push.FCMCredentialsFile,
+		FCMProjectID:       cfg.Push.FCMProjectID,
+		APNsKeyFile:        cfg.Push.APNsKeyFile,
+		APNsKeyID:          cfg.Push.APNsKeyID,
+		APNsTeamID:         cfg.Push.APNsTeamID,
+		APNsTopic:          cfg.Push.APNsTopic,
+		APNsSandbox:        cfg.Push.APNsSandbox,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize push notifications:", err)
+	}
+	pushService := services.NewPushService(pushRepo, postRepo, userRepo, services.PushServiceConfig{
+		MaxDevices: cfg.Push.MaxDevices,
+		MaxTopics:  cfg.Push.MaxTopics,
+	})
+	pushFanOut := services.NewPushFanOut(pushRepo, postRepo, commentRepo, userRepo, pushSenders, cfg.Push.QueueSize, clock.Real)
+	pushFanOut.Start(stopBackground)
+	eventBus.Subscribe(events.PostPublished, pushFanOut.HandleEvent)
+	eventBus.Subscribe(events.CommentCreated, pushFanOut.HandleEvent)
+	lastSeen := middleware.NewLastSeenRecorder(userRepo, cfg.Security.LastSeenThrottle)
+	lastSeen.Start(stopBackground)
+	announcementService := services.NewAnnouncementService(announcementRepo, userRepo, clock.Real)
+	profileService := services.NewProfileService(profileRepo, userRepo, userService, postServiceConfig.Feed, cfg.Posts.ProfileLatestPosts, cfg.AgeGate.ConsentAge)
+	apiKeyService := services.NewAPIKeyService(apiKeyRepo, userRepo, planService)
+	apiUsageService := services.NewAPIUsageService(apiUsageRepo, apiKeyRepo)
+	billingService := services.NewBillingService(billingRepo, userRepo, planService, eventBus, services.BillingServiceConfig{
+		ProPriceIDs: cfg.Billing.StripeProPriceIDs,
+	})
+
+	// Inbound webhooks: each provider brings its verifier and handler, the registry deduplicates events
+	webhooks := inbound.NewRegistry(webhookEventRepo)
+	if cfg.Billing.StripeWebhookSecret != "" {
+		webhooks.Register(&inbound.Provider{
+			Name:     "stripe",
+			Verifier: &inbound.Stripe{Secret: cfg.Billing.StripeWebhookSecret, Tolerance: cfg.Billing.StripeWebhookTolerance},
+			EventID:  inbound.JSONField("id"),
+			Handle: func(d *inbound.Delivery) error {
+				return billingService.HandleStripeEvent(d.Body)
+			},
+		})
+	}
+	if cfg.Mail.SendGridWebhookPassword != "" {
+		// SendGrid batches events without a delivery ID; marking addresses is idempotent instead
+		webhooks.Register(&inbound.Provider{
+			Name:     "sendgrid",
+			Verifier: &inbound.Basic{Username: cfg.Mail.SendGridWebhookUsername, Password: cfg.Mail.SendGridWebhookPassword},
+			Handle: func(d *inbound.Delivery) error {
+				return emailDeliveryService.HandleSendGridEvents(d.Body)
+			},
+		})
+	}
+	oauthClientService := services.NewOAuthClientService(oauthClientRepo, jwtManager)
+	loginStatsService := services.NewLoginStatsService(loginStatsRepo)
+	authorStatsService := services.NewAuthorStatsService(authorStatsRepo)
+	if covered := time.Duration(cfg.Database.PartitionMonthsAhead) * 28 * 24 * time.Hour; covered < cfg.JWT.RefreshExpiration {
+		logger.Warnf("DB_PARTITION_MONTHS_AHEAD=%d does not cover JWT_REFRESH_EXPIRATION=%s; refresh tokens may expire past the last partition", cfg.Database.PartitionMonthsAhead, cfg.JWT.RefreshExpiration)
+	}
+	partitionPolicy := services.PartitionPolicy{
+		MonthsAhead: cfg.Database.PartitionMonthsAhead,
+		Retention: map[string]time.Duration{
+			models.PartitionedRefreshTokens: time.Duration(cfg.Database.RefreshTokenRetentionDays) * 24 * time.Hour,
+			models.PartitionedAuditLogs:     time.Duration(cfg.Database.AuditLogRetentionDays) * 24 * time.Hour,
+		},
+		Archived: make(map[string]bool),
+	}
+	if cfg.Archive.Storage != "" {
+		archive, err := storage.New(storage.Config{
+			Backend:           cfg.Archive.Storage,
+			LocalDir:          cfg.Archive.LocalDir,
+			S3Bucket:          cfg.Archive.S3Bucket,
+			S3Region:          cfg.Archive.S3Region,
+			S3Endpoint:        cfg.Archive.S3Endpoint,
+			S3AccessKeyID:     cfg.Archive.S3AccessKeyID,
+			S3SecretAccessKey: cfg.Archive.S3SecretAccessKey,
+			S3PathStyle:       cfg.Archive.S3PathStyle,
+			Prefix:            cfg.Archive.Prefix,
+		})
+		if err != nil {
+			logger.Fatal("Failed to initialize archive storage:", err)
+		}
+		partitionPolicy.Archive = archive
+		for _, table := range cfg.Archive.Tables {
+			if !slices.Contains(models.PartitionedTables, table) {
+				logger.Fatalf("ARCHIVE_TABLES: %s is not a partitioned table (expected %s)", table, strings.Join(models.PartitionedTables, ", "))
+			}
+			partitionPolicy.Archived[table] = true
+		}
+	}
+	partitionService := services.NewPartitionService(partitionRepo, auditLogRepo, partitionPolicy)
+	lifecycleService := services.NewLifecycleService(userRepo, legalHoldRepo, auditLogRepo, mail, services.LifecyclePolicy{
+		WarnAfterDays:       cfg.Lifecycle.WarnAfterDays,
+		DeactivateAfterDays: cfg.Lifecycle.DeactivateAfterDays,
+		PurgeAfterDays:      cfg.Lifecycle.PurgeAfterDays,
+		ExcludedAccounts:    cfg.Lifecycle.ExcludedAccounts,
+		BaseURL:             cfg.App.ExternalURL(""),
+		DeletedUserPosts:    cfg.App.DeletedUserPosts,
+	})
+	// Each stage only follows the one before it, so a stage without its predecessor never applies
+	if cfg.Lifecycle.DeactivateAfterDays > 0 && cfg.Lifecycle.WarnAfterDays == 0 {
+		logger.Warn("LIFECYCLE_DEACTIVATE_AFTER_DAYS is set without LIFECYCLE_WARN_AFTER_DAYS: only warned accounts are deactivated")
+	}
+	if cfg.Lifecycle.PurgeAfterDays > 0 && cfg.Lifecycle.DeactivateAfterDays == 0 {
+		logger.Warn("LIFECYCLE_PURGE_AFTER_DAYS is set without LIFECYCLE_DEACTIVATE_AFTER_DAYS: only accounts deactivated by admins are purged")
+	}
+
+	// Schedule background jobs
+	scheduler := jobs.NewScheduler()
+	scheduler.Register("stale-accounts", cfg.Lifecycle.Interval, func() error {
+		report, err := lifecycleService.Run(cfg.Lifecycle.DryRun)
+		if err != nil {
+			return err
+		}
+		logger.Infof("Stale account policies (dry run: %t): %d warned, %d deactivated, %d purged, %d held, %d excluded",
+			report.DryRun, len(report.Warned), len(report.Deactivated), len(report.Purged), len(report.Held), report.Excluded)
+		return nil
+	})
+	scheduler.Register("login-stats", cfg.Security.LoginStatsInterval, loginStatsService.Rollup)
+	scheduler.Register("author-stats", cfg.Posts.StatsInterval, authorStatsService.Compute)
+	scheduler.Register("scheduled-posts", cfg.Posts.ScheduleInterval, postService.PublishScheduledPosts)
+	scheduler.Register("saved-searches", cfg.Posts.SavedSearchInterval, savedSearchService.SendAlerts)
+	scheduler.Register("digests", cfg.Digest.Interval, digestService.SendDigests)
+	scheduler.Register("push-held", cfg.Push.HeldInterval, pushFanOut.SendHeld)
+	scheduler.Register("email-templates", cfg.Mail.TemplateRefreshInterval, emailTemplateService.Refresh)
+	scheduler.Register("incident-mode", cfg.Security.IncidentRefreshInterval, tokenRevocationService.Refresh)
+	scheduler.Register("api-usage", cfg.Server.UsageFlushInterval, apiUsageService.Flush)
+	if fieldcrypt.Default() != nil {
+		scheduler.Register("field-key-rotation", cfg.Security.FieldKeyRotationInterval, userService.RotateEncryptionKeys)
+	}
+
+	// Partitions are also ensured on startup, since inserts fail without a partition to go to
+	maintainPartitions := func() error {
+		report, err := partitionService.Maintain()
+		if err != nil {
+			return err
+		}
+		if len(report.Dropped) > 0 || len(report.Held) > 0 {
+			logger.Infof("Partition maintenance: %d partitions ensured, archived %v, dropped %v, held %v", len(report.Created), report.Archived, report.Dropped, report.Held)
+		}
+		return nil
+	}
+	if err := maintainPartitions(); err != nil {
+		logger.Error("Failed to maintain partitions:", err)
+	}
+	scheduler.Register("partitions", cfg.Database.PartitionInterval, maintainPartitions)
+	scheduler.Register("tombstones", cfg.Database.TombstonePruneInterval, func() error {
+		pruned, err := tombstoneService.Prune()
+		if err != nil {
+			return err
+		}
+		if pruned > 0 {
+			logger.Infof("Pruned %d tombstones older than %d days", pruned, cfg.Database.TombstoneRetentionDays)
+		}
+		return nil
+	})
+
+	if cfg.Sandbox.Enabled {
+		scheduler.Register("sandbox", cfg.Sandbox.PurgeInterval, func() error {
+			purged, err := userService.PurgeSandboxUsers(time.Now().Add(-cfg.Sandbox.UserTTL))
+			if purged > 0 {
+				logger.Infof("Purged %d expired sandbox users", purged)
+			}
+			return err
+		})
+	}
+
+	scheduler.Register("webhook-events", cfg.Webhooks.PruneInterval, func() error {
+		deleted, err := webhookEventRepo.DeleteReceivedBefore(time.Now().Add(-cfg.Webhooks.EventRetention))
+		if deleted > 0 {
+			logger.Infof("Forgot %d webhook events older than %s", deleted, cfg.Webhooks.EventRetention)
+		}
+		return err
+	})
+
+	if requestSigner != nil {
+		scheduler.Register("used-signatures", time.Minute, func() error {
+			_, err := usedSignatureRepo.DeleteExpired(time.Now())
+			return err
+		})
+	}
+
+	scheduler.Register("break-glass", cfg.Security.BreakGlassExpiryInterval, func() error {
+		_, err := breakGlassService.Expire()
+		return err
+	})
+
+	// Alert on error rate, sign-in failure and database health anomalies, and on every
+	// break-glass sign-in
+	alertChannels, err := alerting.NewChannels(alerting.ChannelsConfig{
+		Names:           cfg.Alerting.Channels,
+		WebhookURL:      cfg.Alerting.WebhookURL,
+		ServerURL:       cfg.App.ExternalURL(""),
+		Mailer:          mail,
+		EmailRecipients: cfg.Alerting.EmailRecipients,
+	})
+	if err != nil {
+		logger.Fatal("Failed to initialize alert channels:", err)
+	}
+	watcher := alerting.NewWatcher(cfg.Alerting.Cooldown, alertChannels...)
+	if cfg.Alerting.ServerErrorRate > 0 {
+		watcher.Add(alerting.Rule{
+			Name:        "server-error-rate",
+			Description: "share of requests ending in 5xx",
+			Threshold:   cfg.Alerting.ServerErrorRate,
+			Measure:     alerting.Ratio(routeMetrics.ServerErrors, routeMetrics.Requests, uint64(cfg.Alerting.MinRequests)),
+		})
+	}
+	if cfg.Alerting.AuthFailures > 0 {
+		watcher.Add(alerting.Rule{
+			Name:        "auth-failures",
+			Description: "failed sign-in attempts since the last check",
+			Threshold:   float64(cfg.Alerting.AuthFailures),
+			Measure:     alerting.CounterDelta(loginFailures.Value),
+		})
+	}
+	watcher.Add(alerting.Rule{
+		Name:        "break-glass",
+		Description: "sign-ins with break-glass accounts since the last check",
+		Threshold:   1,
+		Measure:     alerting.CounterDelta(breakGlassLogins.Value),
+	})
+	if cfg.Alerting.DBFailedChecks > 0 {
+		// Without the health monitor the rule pings on its own
+		measure := func() float64 { return float64(dbMonitor.ConsecutiveFailures()) }
+		if cfg.Database.HealthInterval <= 0 {
+			measure = alerting.ConsecutiveFailures(dbMonitor.Check)
+		}
+		watcher.Add(alerting.Rule{
+			Name:        "database",
+			Description: "consecutive failed database pings",
+			Threshold:   float64(cfg.Alerting.DBFailedChecks),
+			Measure:     measure,
+		})
+	}
+	scheduler.Register("alerts", cfg.Alerting.Interval, watcher.Evaluate)
+
+	scheduler.Start(stopBackground)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(userService, jwtManager)
+	authHandler := handlers.NewAuthHandler(userService, jwtManager, captchaGuard)
+	sandboxHandler := handlers.NewSandboxHandler(userService, cfg.Sandbox.UserTTL)
 	userHandler := handlers.NewUserHandler(userService)
-	postHandler := handlers.NewPostHandler(postService)
+	postHandler := handlers.NewPostHandler(postService, replicaPostService, replicaMonitor)
+	profileHandler := handlers.NewProfileHandler(profileService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	reactionHandler := handlers.NewReactionHandler(reactionService)
+	inviteHandler := handlers.NewInviteHandler(inviteService)
+	securityHandler := handlers.NewSecurityHandler(loginStatsService, tokenRevocationService)
+	authorStatsHandler := handlers.NewAuthorStatsHandler(authorStatsService)
+	logHandler := handlers.NewLogHandler(logger)
+	adminHandler := handlers.NewAdminHandler(userService, postService, lifecycleService, hashPool, rateLimiter, routeMetrics)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	usageHandler := handlers.NewUsageHandler(apiUsageService)
+	planHandler := handlers.NewPlanHandler(planService)
+	emailDeliveryHandler := handlers.NewEmailDeliveryHandler(emailDeliveryService)
+	savedSearchHandler := handlers.NewSavedSearchHandler(savedSearchService)
+	digestHandler := handlers.NewDigestHandler(digestService)
+	pushHandler := handlers.NewPushHandler(pushService)
+	announcementHandler := handlers.NewAnnouncementHandler(announcementService)
+	emailTemplateHandler := handlers.NewEmailTemplateHandler(emailTemplateService)
+	oauthClientHandler := handlers.NewOAuthClientHandler(oauthClientService)
+	policyHandler := handlers.NewPolicyHandler(policyService)
+	legalHoldHandler := handlers.NewLegalHoldHandler(legalHoldService)
+	syncHandler := handlers.NewSyncHandler(syncService)
+	readOnlyMode := middleware.NewReadOnlyMode(cfg.Server.ReadOnly)
+	if cfg.Server.ReadOnly {
+		logger.Warn("Starting in read-only mode: writes outside of the auth and admin routes are rejected")
+	}
+	readOnlyHandler := handlers.NewReadOnlyHandler(readOnlyMode, logger)
+
+	// Trim and normalize every bound request before validation
+	binding.Validator = validation.NewBindingValidator(binding.Validator)
 
 	// Create Gin router
 	router := gin.New()
+	// Client IPs key rate limits, CAPTCHA failures, sandbox quotas and sign-in risk, so
+	// X-Forwarded-For and X-Real-IP are only believed from the configured proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(handlers.MethodNotAllowed)
+	router.NoRoute(handlers.RouteNotFound(cfg.App.ExternalPathPrefix + apiPrefix))
 
 	// Add middleware
 	router.Use(logger.GinLogger())
+	router.Use(middleware.RouteMetricsMiddleware(routeMetrics))
 	router.Use(logger.GinRecovery())
 	router.Use(middleware.CORS())
 
 	// OpenAPI documentation and specification endpoints (at root level)
-	router.GET("/docs", api.ServeOpenAPIDocs)
-	router.GET("/api-docs", api.ServeOpenAPIDocs) // Alias for /docs
-	router.GET("/openapi.yaml", api.ServeOpenAPISpec)
-	router.GET("/openapi.json", api.ServeOpenAPISpec)
+	// In demo mode the docs page offers sandbox sessions
+	// Behind a reverse proxy path prefix, the page reaches the API under the prefix and the
+	// specification lists the external URL as its server
+	sandboxPath := ""
+	if cfg.Sandbox.Enabled {
+		sandboxPath = cfg.App.ExternalPathPrefix + apiPrefix + "/auth/sandbox"
+	}
+	docsHandler := api.DocsHandler(cfg.App.ExternalPathPrefix+"/openapi.yaml", sandboxPath)
+	router.GET("/docs", docsHandler)
+	router.GET("/api-docs", docsHandler) // Alias for /docs
+	specHandler := api.SpecHandler(cfg.App.ExternalURL(""))
+	router.GET("/openapi.yaml", specHandler)
+	router.GET("/openapi.json", specHandler)
 
 	// API routes with /api/v1 prefix
-	api := router.Group("/api/v1")
+	api := router.Group(apiPrefix)
+	// Clients can also ask for the version by vendor media type; the negotiated one is recorded in the route metrics
+	api.Use(middleware.MediaTypeMiddleware(routeMetrics))
+	// Authenticated requests are counted per user, API key, OAuth client and route
+	if cfg.Server.UsageFlushInterval > 0 {
+		api.Use(middleware.UsageMiddleware(apiUsageService))
+	}
+	if cfg.Security.RateLimitRequests > 0 {
+		api.Use(rateLimiter.Middleware(security.ThrottleScopeRoute))
+	}
+	// In read-only mode writes are rejected, except to sign in and out and for admins, who
+	// must be able to turn it off again
+	api.Use(middleware.ReadOnlyMiddleware(readOnlyMode, apiPrefix+"/auth/", apiPrefix+"/admin/", apiPrefix+"/users/logout"))
+	// Clients preferring links get _links in post and user responses and in page meta
+	linkBuilder := links.NewBuilder(cfg.App.ExternalURL(""))
+	linkBuilder.Handle(links.RoutePost, apiPrefix+"/posts/:id")
+	linkBuilder.Handle(links.RoutePostComments, apiPrefix+"/posts/:id/comments")
+	linkBuilder.Handle(links.RoutePosts, apiPrefix+"/posts")
+	linkBuilder.Handle(links.RoutePublicProfile, apiPrefix+"/public/users/:username")
+	linkBuilder.Handle(links.RouteShortLink, apiPrefix+"/p/:shortID")
+	api.Use(middleware.LinksMiddleware(linkBuilder))
 	{
 		// Health check endpoint (under /api/v1 for consistency)
 		api.GET("/health", func(c *gin.Context) {
@@ -84,47 +764,260 @@ func main() {
 			})
 		})
 
+		// Readiness follows the database health monitor, so load balancers stop routing
+		// requests during an outage without every probe pinging the database
+		api.GET("/health/ready", func(c *gin.Context) {
+			// Error details stay out of this public endpoint; they are logged and in /debug/vars
+			status := dbMonitor.Status()
+			if !status.Healthy {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "unhealthy_since": status.UnhealthySince})
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"status": "ready"})
+		})
+
 		// Public routes (no authentication required)
 		authGroup := api.Group("/auth")
 		{
 			authGroup.POST("/register", authHandler.Register)
 			authGroup.POST("/login", authHandler.Login)
+			authGroup.POST("/login/verify", authHandler.VerifyLogin)
+			authGroup.POST("/break-glass", authHandler.BreakGlassLogin)
+			if cfg.Sandbox.Enabled {
+				authGroup.POST("/sandbox", sandboxSessions.Middleware(security.ThrottleScopeSandboxSessions), sandboxHandler.CreateSession)
+			}
 			authGroup.POST("/refresh", authHandler.Refresh)
+			authGroup.POST("/token", oauthClientHandler.Token)
+			authGroup.POST("/password-strength", authHandler.PasswordStrength)
+
+			// Email change links are opened from emails (GET) or submitted by clients (POST)
+			authGroup.GET("/email/confirm", authHandler.ConfirmEmail)
+			authGroup.POST("/email/confirm", authHandler.ConfirmEmail)
+			authGroup.GET("/email/undo", authHandler.UndoEmail)
+			authGroup.POST("/email/undo", authHandler.UndoEmail)
+			authGroup.GET("/parental-consent/confirm", authHandler.ConfirmParentalConsent)
+			authGroup.POST("/parental-consent/confirm", authHandler.ConfirmParentalConsent)
+		}
+
+		// Inbound webhooks, authenticated by their provider's verifier
+		api.POST("/webhooks/:provider", webhooks.Handler())
+
+		// Shareable post links (no authentication required)
+		api.GET("/p/:shortID", postHandler.GetByShortID)
+
+		// Public profile routes (no authentication required)
+		public := api.Group("/public")
+		{
+			public.GET("/users/:username", profileHandler.GetPublicProfile)
+			public.GET("/policies", policyHandler.ListCurrent)
+			public.GET("/saved-searches/unsubscribe", savedSearchHandler.Unsubscribe)
+			public.POST("/saved-searches/unsubscribe", savedSearchHandler.Unsubscribe)
+			public.GET("/digest/unsubscribe", digestHandler.Unsubscribe)
+			public.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
 		}
 
 		// Protected routes (authentication required)
 		protected := api.Group("/")
-		protected.Use(middleware.AuthMiddleware(jwtManager))
+		protected.Use(middleware.AuthMiddleware(jwtManager, apiKeyService))
+		protected.Use(middleware.TokenDenylistMiddleware(userRepo, oauthClientRepo))
+		if cfg.Sandbox.Enabled {
+			protected.Use(middleware.SandboxQuotaMiddleware(sandboxQuota))
+		}
+		protected.Use(middleware.PlanRateLimitMiddleware(planLimiters))
+		protected.Use(lastSeen.Middleware())
+		protected.Use(middleware.PasswordResetMiddleware(userRepo, "/api/v1/users/password"))
+		// Users who have not accepted a new mandatory policy can still accept it, or decline it
+		// by deleting their account, which the profile route serves
+		protected.Use(middleware.PolicyAcceptanceMiddleware(policyRepo, "/api/v1/users/policies", "/api/v1/users/policies/accept",
+			"/api/v1/users/profile", "/api/v1/users/password", "/api/v1/users/logout"))
 		{
 			// Current user endpoint
-			protected.GET("/me", userHandler.GetMe)
+			protected.GET("/me", middleware.RequireScope(models.ScopeUsersRead), userHandler.GetMe)
+			protected.GET("/feed", middleware.RequireScope(models.ScopePostsRead), postHandler.Feed)
+
+			// Announcement banners, e.g. maintenance notices, for clients to display
+			protected.GET("/announcements", middleware.RequireScope(models.ScopeUsersRead), announcementHandler.ListActive)
+			protected.POST("/announcements/:id/dismiss", middleware.RequireScope(models.ScopeUsersWrite), announcementHandler.Dismiss)
 
 			// User routes
 			users := protected.Group("/users")
 			{
-				users.GET("/profile", userHandler.GetProfile)
-				users.PUT("/profile", userHandler.UpdateProfile)
-				users.DELETE("/profile", userHandler.DeleteProfile)
+				users.GET("/profile", middleware.RequireScope(models.ScopeUsersRead), userHandler.GetProfile)
+				users.PUT("/profile", middleware.RequireScope(models.ScopeUsersWrite), userHandler.UpdateProfile)
+				users.DELETE("/profile", middleware.RequireScope(models.ScopeUsersWrite), userHandler.DeleteProfile)
+				users.GET("/sessions", middleware.RequireScope(models.ScopeUsersRead), userHandler.ListSessions)
+				users.GET("/stats", middleware.RequireScope(models.ScopeUsersRead), authorStatsHandler.Mine)
+				users.GET("/plan", middleware.RequireScope(models.ScopeUsersRead), planHandler.Get)
+				users.GET("/preferences", middleware.RequireScope(models.ScopeUsersRead), userHandler.GetPreferences)
+				users.GET("/digest", middleware.RequireScope(models.ScopeUsersRead), digestHandler.Get)
+				users.PUT("/digest", middleware.RequireScope(models.ScopeUsersWrite), digestHandler.Update)
+				users.POST("/devices", middleware.RequireScope(models.ScopeUsersWrite), pushHandler.RegisterDevice)
+				users.GET("/devices", middleware.RequireScope(models.ScopeUsersRead), pushHandler.ListDevices)
+				users.DELETE("/devices/:id", middleware.RequireScope(models.ScopeUsersWrite), pushHandler.DeleteDevice)
+				users.GET("/push/topics", middleware.RequireScope(models.ScopeUsersRead), pushHandler.ListTopics)
+				users.PUT("/push/topics/:topic", middleware.RequireScope(models.ScopeUsersWrite), pushHandler.Subscribe)
+				users.DELETE("/push/topics/:topic", middleware.RequireScope(models.ScopeUsersWrite), pushHandler.Unsubscribe)
+				users.GET("/push/quiet-hours", middleware.RequireScope(models.ScopeUsersRead), pushHandler.GetQuietHours)
+				users.PUT("/push/quiet-hours", middleware.RequireScope(models.ScopeUsersWrite), pushHandler.UpdateQuietHours)
+				users.PUT("/preferences", middleware.RequireScope(models.ScopeUsersWrite), userHandler.UpdatePreferences)
+				users.PUT("/password", middleware.RequireScope(models.ScopeUsersWrite), userHandler.ChangePassword)
 				users.POST("/logout", userHandler.Logout)
-				users.PUT("/:id/activate", userHandler.ActivateUser)
-				users.PUT("/:id/deactivate", userHandler.DeactivateUser)
+				users.GET("/policies", middleware.RequireScope(models.ScopeUsersRead), policyHandler.GetStatus)
+				users.POST("/policies/accept", middleware.RequireScope(models.ScopeUsersWrite), policyHandler.Accept)
+				users.POST("/parental-consent", middleware.RequireScope(models.ScopeUsersWrite), userHandler.RequestParentalConsent)
+				users.POST("/:id/follow", middleware.RequireScope(models.ScopeUsersWrite), profileHandler.Follow)
+				users.DELETE("/:id/follow", middleware.RequireScope(models.ScopeUsersWrite), profileHandler.Unfollow)
+				users.POST("/api-keys", middleware.RequireScope(models.ScopeUsersWrite), middleware.RequireFeature(planService, models.FeatureAPIKeys), apiKeyHandler.Create)
+				users.GET("/api-keys", middleware.RequireScope(models.ScopeUsersRead), apiKeyHandler.List)
+				users.DELETE("/api-keys/:id", middleware.RequireScope(models.ScopeUsersWrite), apiKeyHandler.Revoke)
+				users.GET("/api-keys/:id/usage", middleware.RequireScope(models.ScopeUsersRead), usageHandler.APIKey)
+				users.POST("/saved-searches", middleware.RequireScope(models.ScopeUsersWrite), savedSearchHandler.Create)
+				users.GET("/saved-searches", middleware.RequireScope(models.ScopeUsersRead), savedSearchHandler.List)
+				users.PUT("/saved-searches/:id", middleware.RequireScope(models.ScopeUsersWrite), savedSearchHandler.Update)
+				users.DELETE("/saved-searches/:id", middleware.RequireScope(models.ScopeUsersWrite), savedSearchHandler.Delete)
 			}
 
 			// Post routes
 			posts := protected.Group("/posts")
 			{
-				posts.POST("", postHandler.Create)
-				posts.GET("", postHandler.GetAll)
-				posts.GET("/:id", postHandler.GetByID)
-				posts.PUT("/:id", postHandler.Update)
-				posts.DELETE("/:id", postHandler.Delete)
+				posts.POST("", middleware.RequireScope(models.ScopePostsWrite), postHandler.Create)
+				posts.GET("", middleware.RequireScope(models.ScopePostsRead), postHandler.GetAll)
+				posts.GET("/schedule", middleware.RequireScope(models.ScopePostsRead), postHandler.Schedule)
+				posts.GET("/search", middleware.RequireScope(models.ScopePostsRead), postHandler.Search)
+				posts.GET("/search/suggest", middleware.RequireScope(models.ScopePostsRead), postHandler.Suggest)
+				posts.GET("/:id", middleware.RequireScope(models.ScopePostsRead), postHandler.GetByID)
+				posts.PUT("/:id", middleware.RequireScope(models.ScopePostsWrite), postHandler.Update)
+				posts.DELETE("/:id", middleware.RequireScope(models.ScopePostsWrite), postHandler.Delete)
+				posts.POST("/:id/archive", middleware.RequireScope(models.ScopePostsWrite), postHandler.Archive)
+				posts.POST("/:id/lock", middleware.RequireScope(models.ScopePostsWrite), postHandler.Lock)
+				posts.DELETE("/:id/lock", middleware.RequireScope(models.ScopePostsWrite), postHandler.Unlock)
+				posts.PUT("/:id/autosave", middleware.RequireScope(models.ScopePostsWrite), postHandler.Autosave)
+				posts.GET("/:id/autosave", middleware.RequireScope(models.ScopePostsRead), postHandler.GetAutosave)
+				posts.POST("/:id/unarchive", middleware.RequireScope(models.ScopePostsWrite), postHandler.Unarchive)
+				posts.POST("/:id/comments", middleware.RequireScope(models.ScopePostsWrite), commentHandler.Create)
+				posts.GET("/:id/comments", middleware.RequireScope(models.ScopePostsRead), commentHandler.List)
+				posts.GET("/:id/comments/:comment_id/replies", middleware.RequireScope(models.ScopePostsRead), commentHandler.Replies)
+				posts.PUT("/:id/reaction", middleware.RequireScope(models.ScopePostsWrite), middleware.DenySandbox(), reactionHandler.ReactToPost)
+				posts.DELETE("/:id/reaction", middleware.RequireScope(models.ScopePostsWrite), middleware.DenySandbox(), reactionHandler.UnreactToPost)
+				posts.PUT("/:id/comments/:comment_id/reaction", middleware.RequireScope(models.ScopePostsWrite), middleware.DenySandbox(), reactionHandler.ReactToComment)
+				posts.DELETE("/:id/comments/:comment_id/reaction", middleware.RequireScope(models.ScopePostsWrite), middleware.DenySandbox(), reactionHandler.UnreactToComment)
+			}
+
+			// Change feed of offline-capable clients
+			sync := protected.Group("/sync")
+			sync.Use(middleware.RequireScope(models.ScopePostsRead))
+			{
+				sync.GET("/posts", syncHandler.Posts)
+			}
+
+			// Invite routes (regular users are limited by INVITE_QUOTA_PER_USER)
+			invites := protected.Group("/invites")
+			invites.Use(middleware.RequireScope(models.ScopeUsersWrite))
+			{
+				invites.POST("", inviteHandler.Create)
+				invites.GET("", inviteHandler.ListMine)
+				invites.DELETE("/:id", inviteHandler.Revoke)
+			}
+
+			// Admin routes
+			admin := protected.Group("/admin")
+			admin.Use(middleware.RequireAdmin(), middleware.RequireScope(models.ScopeUsersAdmin))
+			// Destructive requests must also be signed, see ADMIN_SIGNING_SECRET
+			signed := middleware.RequireSignedRequest(requestSigner)
+			{
+				admin.GET("/users", adminHandler.ListUsers)
+				admin.POST("/users/import", signed, adminHandler.ImportUsers)
+				admin.GET("/posts", adminHandler.ListPosts)
+				admin.POST("/search/reindex", adminHandler.ReindexSearch)
+				admin.POST("/feed/rebuild", adminHandler.RebuildFeeds)
+				admin.POST("/users/:id/force-password-reset", signed, adminHandler.ForcePasswordReset)
+				admin.PUT("/users/:id/activate", signed, userHandler.ActivateUser)
+				admin.PUT("/users/:id/deactivate", signed, userHandler.DeactivateUser)
+				admin.PUT("/users/:id/plan", signed, planHandler.Update)
+				admin.DELETE("/users/:id/email-undeliverable", signed, emailDeliveryHandler.ClearUndeliverable)
+				admin.GET("/users/:id/legal-hold", legalHoldHandler.Get)
+				admin.PUT("/users/:id/legal-hold", legalHoldHandler.Place)
+				admin.DELETE("/users/:id/legal-hold", signed, legalHoldHandler.Clear)
+				admin.GET("/legal-holds", legalHoldHandler.List)
+				admin.GET("/lifecycle/report", adminHandler.LifecycleReport)
+				admin.GET("/stats/password-hashing", adminHandler.PasswordHashingStats)
+				admin.GET("/stats/rate-limiter", adminHandler.RateLimiterStats)
+				admin.GET("/stats/authors", authorStatsHandler.List)
+				admin.GET("/usage", usageHandler.List)
+				admin.GET("/metrics/routes", adminHandler.RouteMetrics)
+				admin.GET("/log-level", logHandler.Get)
+				admin.PUT("/log-level", signed, logHandler.Update)
+				admin.GET("/read-only", readOnlyHandler.Get)
+				admin.PUT("/read-only", signed, readOnlyHandler.Update)
+				admin.GET("/security/login-stats", securityHandler.LoginStats)
+				admin.POST("/security/revoke-all-tokens", signed, securityHandler.RevokeAllTokens)
+				admin.GET("/security/incident-mode", securityHandler.GetIncidentMode)
+				admin.PUT("/security/incident-mode", signed, securityHandler.UpdateIncidentMode)
+				admin.POST("/invites", inviteHandler.Create)
+				admin.GET("/invites", inviteHandler.List)
+				admin.GET("/invites/:id", inviteHandler.GetByID)
+				admin.DELETE("/invites/:id", signed, inviteHandler.Revoke)
+				admin.POST("/oauth-clients", oauthClientHandler.Create)
+				admin.GET("/oauth-clients", oauthClientHandler.List)
+				admin.DELETE("/oauth-clients/:id", signed, oauthClientHandler.Revoke)
+				admin.POST("/policies", policyHandler.Publish)
+				admin.GET("/policies", policyHandler.List)
+				admin.GET("/email-templates", emailTemplateHandler.List)
+				admin.GET("/email-templates/:name", emailTemplateHandler.Get)
+				admin.PUT("/email-templates/:name", signed, emailTemplateHandler.Update)
+				admin.POST("/email-templates/:name/preview", emailTemplateHandler.Preview)
+				admin.POST("/announcements", announcementHandler.Create)
+				admin.GET("/announcements", announcementHandler.List)
+				admin.PUT("/announcements/:id", announcementHandler.Update)
+				admin.DELETE("/announcements/:id", signed, announcementHandler.Delete)
 			}
 		}
 	}
 
+	// Runtime diagnostics (opt-in), restricted to admins since profiles expose internals
+	if cfg.Server.DebugEndpoints {
+		debugHandler := handlers.NewDebugHandler(database.GetDB())
+		debug := router.Group("/debug")
+		debug.Use(middleware.AuthMiddleware(jwtManager, apiKeyService))
+		debug.Use(middleware.TokenDenylistMiddleware(userRepo, oauthClientRepo))
+		debug.Use(middleware.RequireAdmin(), middleware.RequireScope(models.ScopeUsersAdmin))
+		{
+			debug.GET("/pprof/*profile", debugHandler.Pprof)
+			debug.POST("/pprof/*profile", debugHandler.Pprof)
+			debug.GET("/vars", debugHandler.Vars)
+			debug.GET("/goroutines", debugHandler.Goroutines)
+			debug.GET("/dbpool", debugHandler.DBPool)
+		}
+	}
+
 	// Start server
 	logger.Infof("Server starting on port %s", cfg.Server.Port)
-	if err := router.Run(":" + cfg.Server.Port); err != nil {
+	if err := http.ListenAndServe(":"+cfg.Server.Port, middleware.HeadAsGet(router.Handler())); err != nil {
 		logger.Fatal("Failed to start server:", err)
 	}
 }
+
+// planLimits returns the limits of plan set in c
+func planLimits(plan string, c config.PlanConfig) *models.PlanLimits {
+	return &models.PlanLimits{
+		Plan:              plan,
+		RateLimitRequests: c.RateLimitRequests,
+		SavedSearches:     c.SavedSearches,
+		APIKeys:           c.APIKeys,
+		Features:          c.Features,
+	}
+}
+
+// runSelfTest prints the self-test report of cfg and exits, with status 1 when a check failed
+func runSelfTest(cfg *config.Config, asJSON bool) {
+	report := selftest.Run(cfg)
+	if asJSON {
+		_ = report.WriteJSON(os.Stdout)
+	} else {
+		report.WriteText(os.Stdout)
+	}
+	if !report.Passed() {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Generates the column lists and scan destinations of model structs from their db tags,
+// so that repository queries select and scan every mapped field in the same order.
+// Run through go generate in internal/repositories:
+//
+//	go generate ./internal/repositories
+func main() {
+	modelsDir := flag.String("models", "../models", "directory of the models package")
+	types := flag.String("types", "", "comma-separated model types to map")
+	out := flag.String("out", "mapping_gen.go", "output file")
+	flag.Parse()
+
+	structs, err := parseStructs(*modelsDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by cmd/mapgen from the db tags of internal/models; DO NOT EDIT.\n\n")
+	buf.WriteString("package repositories\n\n")
+	buf.WriteString("import (\n\t\"go-backend-api/internal/models\"\n\n\t\"github.com/lib/pq\"\n)\n")
+
+	usesArray := false
+	for _, name := range strings.Split(*types, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := structs[name]; !ok {
+			log.Fatalf("model type %s not found in %s", name, *modelsDir)
+		}
+		fields, err := mappedFields(structs, name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		prefix := lowerFirst(name)
+
+		columns := make([]string, len(fields))
+		destinations := make([]string, len(fields))
+		for i, field := range fields {
+			columns[i] = field.column
+			destinations[i] = "&" + prefix + "." + field.name
+			if field.array {
+				destinations[i] = "pq.Array(" + destinations[i] + ")"
+				usesArray = true
+			}
+		}
+
+		fmt.Fprintf(&buf, "\n// %sColumns are the columns models.%s is mapped to, in the order of %sFields\n", prefix, name, prefix)
+		fmt.Fprintf(&buf, "const %sColumns = `%s`\n", prefix, strings.Join(columns, ", "))
+		fmt.Fprintf(&buf, "\n// %sFields returns the scan destinations of %sColumns in %s\n", prefix, prefix, prefix)
+		fmt.Fprintf(&buf, "func %sFields(%s *models.%s) []interface{} {\n", prefix, prefix, name)
+		fmt.Fprintf(&buf, "\treturn []interface{}{\n\t\t%s,\n\t}\n}\n", strings.Join(destinations, ",\n\t\t"))
+	}
+
+	source := buf.String()
+	if !usesArray {
+		source = strings.Replace(source, "\n\n\t\"github.com/lib/pq\"", "", 1)
+	}
+	formatted, err := format.Source([]byte(source))
+	if err != nil {
+		log.Fatalf("failed to format generated code: %v", err)
+	}
+	if err := os.WriteFile(*out, formatted, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// field is a struct field mapped to a column
+type field struct {
+	name   string
+	column string
+	array  bool // Scanned through pq.Array
+}
+
+// parseStructs parses the struct types of a package directory by name
+func parseStructs(dir string) (map[string]*ast.StructType, error) {
+	packages, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dir, err)
+	}
+
+	structs := make(map[string]*ast.StructType)
+	for _, pkg := range packages {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				if spec, ok := node.(*ast.TypeSpec); ok {
+					if st, ok := spec.Type.(*ast.StructType); ok {
+						structs[spec.Name.Name] = st
+					}
+				}
+				return true
+			})
+		}
+	}
+	return structs, nil
+}
+
+// mappedFields lists the fields of a struct with a db tag, in declaration order. Fields of
+// embedded structs are included where they are embedded; fields tagged db:"-" are not.
+func mappedFields(structs map[string]*ast.StructType, name string) ([]field, error) {
+	var fields []field
+	for _, f := range structs[name].Fields.List {
+		if len(f.Names) == 0 {
+			embedded, ok := f.Type.(*ast.Ident)
+			if !ok || structs[embedded.Name] == nil {
+				continue
+			}
+			nested, err := mappedFields(structs, embedded.Name)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, nested...)
+			continue
+		}
+		if f.Tag == nil {
+			continue
+		}
+		tag, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s.%s: malformed tag: %w", name, f.Names[0].Name, err)
+		}
+		column := reflect.StructTag(tag).Get("db")
+		if column == "" || column == "-" {
+			continue
+		}
+		array, ok := f.Type.(*ast.ArrayType)
+		fields = append(fields, field{
+			name:   f.Names[0].Name,
+			column: column,
+			array:  ok && array.Len == nil,
+		})
+	}
+	return fields, nil
+}
+
+// lowerFirst lowercases the leading initialism or letter of a type name: APIKey becomes
+// apiKey, OAuthClient oauthClient and User user
+func lowerFirst(name string) string {
+	if strings.HasPrefix(name, "OAuth") {
+		return "oauth" + name[len("OAuth"):]
+	}
+	runes := []rune(name)
+	for i := range runes {
+		if !unicode.IsUpper(runes[i]) {
+			break
+		}
+		// The last capital before a lowercase letter starts the next word
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
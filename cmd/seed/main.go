@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"go-backend-api/internal/config"
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/seed"
+)
+
+// Seeds the database with the data of a profile. It runs against the configured
+// DATABASE_URL after migrations, and can be run again to restore the seeded rows:
+//
+//	go run ./cmd/seed -profile dev
+//	SEED_PASSWORD=... go run ./cmd/seed -profile staging -users 5000 -posts 50000
+func main() {
+	cfg := config.LoadConfig()
+
+	profile := flag.String("profile", seed.ProfileFor(cfg.App.Environment), "seed profile: dev or staging (defaults to the one matching ENVIRONMENT)")
+	users := flag.Int("users", seed.DefaultStagingUsers, "number of staging users")
+	posts := flag.Int("posts", seed.DefaultStagingPosts, "number of staging posts")
+	flag.Parse()
+
+	// Seeded accounts share a known or operator-chosen password; production must never have them
+	if cfg.IsProduction() {
+		log.Fatal("Refusing to seed a production database")
+	}
+	if *profile == "" {
+		log.Fatalf("No seed profile matches ENVIRONMENT=%s; pass -profile %s or %s", cfg.App.Environment, seed.ProfileDev, seed.ProfileStaging)
+	}
+
+	if err := database.Connect(cfg.Database.URL, database.Options{
+		MaxOpenConns:     cfg.Database.MaxOpenConns,
+		MaxIdleConns:     cfg.Database.MaxIdleConns,
+		ConnMaxLifetime:  cfg.Database.ConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.Database.ConnMaxIdleTime,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		QueryTimeout:     cfg.Database.QueryTimeout,
+	}); err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer database.Close()
+
+	result, err := seed.Run(database.GetDB(), seed.Options{
+		Profile:        *profile,
+		Users:          *users,
+		Posts:          *posts,
+		Password:       os.Getenv("SEED_PASSWORD"),
+		ExcerptWords:   cfg.Posts.ExcerptWords,
+		WordsPerMinute: cfg.Posts.WordsPerMinute,
+	})
+	if err != nil {
+		log.Fatal("Failed to seed database:", err)
+	}
+
+	log.Printf("Seeded the %s profile: %d users, %d posts, %d follows, %d reactions written",
+		*profile, result.Users, result.Posts, result.Follows, result.Reactions)
+}
@@ -0,0 +1,151 @@
+// Package alerting watches operational signals (error rates, authentication failures,
+// database health) and notifies configured channels when a threshold is crossed.
+package alerting
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert is a threshold crossing reported to channels
+type Alert struct {
+	Rule        string    `json:"rule"`
+	Description string    `json:"description"`
+	Value       float64   `json:"value"`
+	Threshold   float64   `json:"threshold"`
+	FiredAt     time.Time `json:"fired_at"`
+}
+
+// Message renders the alert as a single line of text
+func (a *Alert) Message() string {
+	return fmt.Sprintf("%s: %s is %g (threshold %g)", a.Rule, a.Description, a.Value, a.Threshold)
+}
+
+// Channel delivers alerts, e.g. to the log, a webhook or an email address
+type Channel interface {
+	Name() string
+	Send(alert *Alert) error
+}
+
+// Rule fires when its measured value reaches Threshold
+type Rule struct {
+	Name        string
+	Description string
+	Threshold   float64
+	// Measure returns the current value of the watched signal. It is called once per evaluation,
+	// so measures built from counters report the change since the previous evaluation.
+	Measure func() float64
+}
+
+// Watcher evaluates rules and fires their alerts through every channel.
+// After a rule fires it stays silent for the cooldown, so a sustained problem
+// does not cause an alert storm.
+type Watcher struct {
+	cooldown time.Duration
+	channels []Channel
+
+	mutex     sync.Mutex
+	rules     []Rule
+	lastFired map[string]time.Time
+}
+
+// NewWatcher creates a watcher delivering alerts to channels at most once per cooldown per rule
+func NewWatcher(cooldown time.Duration, channels ...Channel) *Watcher {
+	return &Watcher{
+		cooldown:  cooldown,
+		channels:  channels,
+		lastFired: make(map[string]time.Time),
+	}
+}
+
+// Add registers a rule
+func (w *Watcher) Add(rule Rule) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.rules = append(w.rules, rule)
+}
+
+// Evaluate measures every rule once and fires the ones over their threshold and out of cooldown.
+// It is meant to run as a scheduled job; channel failures are returned together.
+func (w *Watcher) Evaluate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	now := time.Now()
+	var failures []string
+	for _, rule := range w.rules {
+		// Measure every rule, even in cooldown, so counter deltas stay aligned with evaluations
+		value := rule.Measure()
+		if value < rule.Threshold {
+			continue
+		}
+		if last, ok := w.lastFired[rule.Name]; ok && now.Sub(last) < w.cooldown {
+			continue
+		}
+		w.lastFired[rule.Name] = now
+
+		alert := &Alert{
+			Rule:        rule.Name,
+			Description: rule.Description,
+			Value:       value,
+			Threshold:   rule.Threshold,
+			FiredAt:     now,
+		}
+		for _, channel := range w.channels {
+			if err := channel.Send(alert); err != nil {
+				failures = append(failures, fmt.Sprintf("%s via %s: %v", rule.Name, channel.Name(), err))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to deliver alerts: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// CounterDelta measures how much a monotonically increasing counter grew since the previous measurement
+func CounterDelta(counter func() uint64) func() float64 {
+	previous := counter()
+	return func() float64 {
+		current := counter()
+		delta := current - previous
+		previous = current
+		return float64(delta)
+	}
+}
+
+// Ratio measures the growth of numerator relative to the growth of denominator since the
+// previous measurement, e.g. the share of requests that failed. It measures zero unless the
+// denominator grew by at least minDenominator, so a handful of requests cannot trigger it.
+func Ratio(numerator, denominator func() uint64, minDenominator uint64) func() float64 {
+	prevNum, prevDen := numerator(), denominator()
+	return func() float64 {
+		num, den := numerator(), denominator()
+		deltaNum, deltaDen := num-prevNum, den-prevDen
+		prevNum, prevDen = num, den
+
+		if deltaDen == 0 || deltaDen < minDenominator {
+			return 0
+		}
+		return float64(deltaNum) / float64(deltaDen)
+	}
+}
+
+// ConsecutiveFailures measures how many measurements in a row check has failed
+func ConsecutiveFailures(check func() error) func() float64 {
+	failures := 0
+	return func() float64 {
+		if err := check(); err != nil {
+			failures++
+			log.Printf("Alert check failed (%d in a row): %v", failures, err)
+		} else {
+			failures = 0
+		}
+		return float64(failures)
+	}
+}
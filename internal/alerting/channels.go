@@ -0,0 +1,123 @@
+package alerting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/mailer"
+)
+
+// webhookTimeout bounds how long a webhook delivery may take
+const webhookTimeout = 5 * time.Second
+
+// ChannelsConfig describes the channels alerts are delivered to
+type ChannelsConfig struct {
+	Names           []string // "log", "webhook" and/or "email"
+	WebhookURL      string
+	ServerURL       string // External URL of the server, so receivers can tell deployments apart
+	Mailer          mailer.Mailer
+	EmailRecipients []string
+}
+
+// NewChannels creates the configured channels
+func NewChannels(cfg ChannelsConfig) ([]Channel, error) {
+	var channels []Channel
+	for _, name := range cfg.Names {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "log":
+			channels = append(channels, &logChannel{})
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("webhook alert channel requires a webhook URL")
+			}
+			channels = append(channels, &webhookChannel{
+				url:    cfg.WebhookURL,
+				server: cfg.ServerURL,
+				client: &http.Client{Timeout: webhookTimeout},
+			})
+		case "email":
+			if cfg.Mailer == nil || len(cfg.EmailRecipients) == 0 {
+				return nil, fmt.Errorf("email alert channel requires a mailer and recipients")
+			}
+			channels = append(channels, &emailChannel{mailer: cfg.Mailer, recipients: cfg.EmailRecipients})
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown alert channel %q", name)
+		}
+	}
+	return channels, nil
+}
+
+// logChannel writes alerts to the application log
+type logChannel struct{}
+
+// Name returns the channel name
+func (c *logChannel) Name() string { return "log" }
+
+// Send logs the alert
+func (c *logChannel) Send(alert *Alert) error {
+	log.Printf("[alert] %s", alert.Message())
+	return nil
+}
+
+// webhookChannel posts alerts as JSON to a URL
+type webhookChannel struct {
+	url    string
+	server string
+	client *http.Client
+}
+
+// Name returns the channel name
+func (c *webhookChannel) Name() string { return "webhook" }
+
+// Send posts the alert, treating any non-2xx response as a failure
+func (c *webhookChannel) Send(alert *Alert) error {
+	body, err := json.Marshal(struct {
+		*Alert
+		Text   string `json:"text"`             // For chat webhooks that display a text field
+		Server string `json:"server,omitempty"` // External URL of the server that fired it
+	}{alert, alert.Message(), c.server})
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	resp, err := c.client.Post(c.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post alert: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// emailChannel emails alerts to a fixed list of recipients
+type emailChannel struct {
+	mailer     mailer.Mailer
+	recipients []string
+}
+
+// Name returns the channel name
+func (c *emailChannel) Name() string { return "email" }
+
+// Send emails the alert to every recipient
+func (c *emailChannel) Send(alert *Alert) error {
+	for _, to := range c.recipients {
+		err := c.mailer.Send(&mailer.Message{
+			To:      to,
+			Subject: "[alert] " + alert.Rule,
+			Body:    alert.Message() + "\n\nFired at " + alert.FiredAt.Format(time.RFC3339) + ".\n",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to email %s: %w", to, err)
+		}
+	}
+	return nil
+}
@@ -4,28 +4,68 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // DB holds the database connection
 var DB *sql.DB
 
-// Connect establishes a connection to the database
-func Connect(databaseURL string) error {
-	var err error
-	DB, err = sql.Open("postgres", databaseURL)
+// Options configures the connection pool and how long statements may run
+type Options struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// StatementTimeout is set as statement_timeout on every session, so that Postgres cancels
+	// statements running longer (0 keeps the server's setting). Transactions can raise it
+	// for themselves with SET LOCAL statement_timeout.
+	StatementTimeout time.Duration
+	// QueryTimeout is the deadline of statements whose context has none (0 disables), which
+	// includes every repository query. Keep it above StatementTimeout: a statement the
+	// server cancels keeps its connection, one whose deadline passes closes it.
+	QueryTimeout time.Duration
+}
+
+// Connect establishes a connection to the database. Connections report their statements
+// to the query logger while query logging is on (see SetQueryLogger), and statements that
+// run out of time fail with an error whose Timeout method reports true.
+func Connect(databaseURL string, opts Options) error {
+	db, err := Open(databaseURL, opts)
 	if err != nil {
-		return fmt.Errorf("failed to open database: %w", err)
+		return err
 	}
+	DB = db
+
+	log.Println("Successfully connected to database")
+	return nil
+}
+
+// Open opens and pings a connection pool other than DB, such as a read replica. Its
+// connections behave like those of Connect.
+func Open(databaseURL string, opts Options) (*sql.DB, error) {
+	connector, err := pq.NewConnector(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db := sql.OpenDB(wrappedConnector{
+		Connector:        connector,
+		statementTimeout: opts.StatementTimeout,
+		queryTimeout:     opts.QueryTimeout,
+	})
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	db.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
 
 	// Test the connection
-	if err = DB.Ping(); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+	if err = db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	log.Println("Successfully connected to database")
-	return nil
+	return db, nil
 }
 
 // Close closes the database connection
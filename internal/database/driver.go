@@ -0,0 +1,266 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pgQueryCanceled is the Postgres error code of statements cancelled by statement_timeout
+// or, after a context deadline passed, by the driver
+const pgQueryCanceled = "57014"
+
+// queryTimeoutError marks an error as a statement running out of time. It unwraps to the
+// driver error and reports Timeout like net.Error, which the API maps to a 503.
+type queryTimeoutError struct {
+	err error
+}
+
+func (e *queryTimeoutError) Error() string { return e.err.Error() }
+func (e *queryTimeoutError) Unwrap() error { return e.err }
+func (e *queryTimeoutError) Timeout() bool { return true }
+
+// markTimeout wraps err in a queryTimeoutError if the statement ran out of time
+func markTimeout(err error) error {
+	if err == nil {
+		return nil
+	}
+	var pqErr *pq.Error
+	if (errors.As(err, &pqErr) && pqErr.Code == pgQueryCanceled) || errors.Is(err, context.DeadlineExceeded) {
+		return &queryTimeoutError{err: err}
+	}
+	return err
+}
+
+// wrappedConnector opens connections that set the session's statement timeout, give
+// statements the query timeout and report them to the query logger. Sessions run in UTC and
+// time arguments are sent in UTC, so TIMESTAMP columns, which keep no timezone, hold UTC
+// whatever the timezone of the server or the database.
+type wrappedConnector struct {
+	driver.Connector
+	statementTimeout time.Duration
+	queryTimeout     time.Duration
+}
+
+func (c wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wrapped := &wrappedConn{Conn: conn, queryTimeout: c.queryTimeout}
+
+	// CURRENT_TIMESTAMP and NOW() yield UTC in TIMESTAMP columns
+	if _, err := wrapped.ExecContext(ctx, "SET TIME ZONE 'UTC'", nil); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to set session timezone: %w", err)
+	}
+	if c.statementTimeout > 0 {
+		// SET takes no parameters; the value is a whole number of milliseconds
+		query := fmt.Sprintf("SET statement_timeout = %d", c.statementTimeout.Milliseconds())
+		if _, err := wrapped.ExecContext(ctx, query, nil); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	}
+	return wrapped, nil
+}
+
+// withQueryTimeout gives a statement the query timeout, unless its context already has a
+// deadline. Callers that need longer, such as background jobs, pass their own.
+func withQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// utcArgs converts time arguments to UTC. The driver sends times with their offset, which
+// Postgres drops when storing them in a TIMESTAMP column, keeping the local wall clock.
+func utcArgs(args []driver.NamedValue) []driver.NamedValue {
+	var converted []driver.NamedValue
+	for i, arg := range args {
+		t, ok := arg.Value.(time.Time)
+		if !ok || t.Location() == time.UTC {
+			continue
+		}
+		if converted == nil {
+			converted = slices.Clone(args)
+		}
+		converted[i].Value = t.UTC()
+	}
+	if converted == nil {
+		return args
+	}
+	return converted
+}
+
+// wrappedConn wraps a driver connection. Its optional methods fall back to the
+// behaviour database/sql has for drivers without them.
+type wrappedConn struct {
+	driver.Conn
+	queryTimeout time.Duration
+}
+
+func (c *wrappedConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := withQueryTimeout(ctx, c.queryTimeout)
+	defer cancel()
+
+	args = utcArgs(args)
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, query, args)
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	logQuery(query, args, start, rows, err)
+	return result, markTimeout(err)
+}
+
+func (c *wrappedConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	ctx, cancel := withQueryTimeout(ctx, c.queryTimeout)
+
+	args = utcArgs(args)
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, query, args)
+	if err != nil {
+		cancel()
+		logQuery(query, args, start, 0, err)
+		return nil, markTimeout(err)
+	}
+	return &wrappedRows{Rows: rows, query: query, args: args, start: start, cancel: cancel}, nil
+}
+
+func (c *wrappedConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var stmt driver.Stmt
+	var err error
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		stmt, err = preparer.PrepareContext(ctx, query)
+	} else {
+		stmt, err = c.Conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &wrappedStmt{Stmt: stmt, query: query, queryTimeout: c.queryTimeout}, nil
+}
+
+// BeginTx passes ctx on as is: the driver watches it for the whole transaction, so the
+// query timeout applies to the transaction's statements instead
+func (c *wrappedConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *wrappedConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *wrappedConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// wrappedStmt wraps a prepared statement
+type wrappedStmt struct {
+	driver.Stmt
+	query        string
+	queryTimeout time.Duration
+}
+
+func (s *wrappedStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := s.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return nil, fmt.Errorf("driver statement does not support ExecContext")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	args = utcArgs(args)
+	start := time.Now()
+	result, err := execer.ExecContext(ctx, args)
+	var rows int64
+	if err == nil {
+		rows, _ = result.RowsAffected()
+	}
+	logQuery(s.query, args, start, rows, err)
+	return result, markTimeout(err)
+}
+
+func (s *wrappedStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := s.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return nil, fmt.Errorf("driver statement does not support QueryContext")
+	}
+	ctx, cancel := withQueryTimeout(ctx, s.queryTimeout)
+
+	args = utcArgs(args)
+	start := time.Now()
+	rows, err := queryer.QueryContext(ctx, args)
+	if err != nil {
+		cancel()
+		logQuery(s.query, args, start, 0, err)
+		return nil, markTimeout(err)
+	}
+	return &wrappedRows{Rows: rows, query: s.query, args: args, start: start, cancel: cancel}, nil
+}
+
+// wrappedRows counts the rows read from a result set and logs the query once it is closed,
+// so that the duration covers reading the rows. The query timeout also runs until then.
+type wrappedRows struct {
+	driver.Rows
+	query  string
+	args   []driver.NamedValue
+	start  time.Time
+	cancel context.CancelFunc
+	count  int64
+	err    error
+}
+
+func (r *wrappedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	if err == nil {
+		r.count++
+	} else if err != io.EOF {
+		r.err = err
+		return markTimeout(err)
+	}
+	return err
+}
+
+func (r *wrappedRows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	logQuery(r.query, r.args, r.start, r.count, r.err)
+	return err
+}
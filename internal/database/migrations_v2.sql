@@ -2,82 +2,648 @@
 
 -- Enable UUID extension
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+-- Trigram similarity for search suggestions and typo-tolerant search
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
 
 -- Drop existing tables if they exist (for clean migration)
+DROP TABLE IF EXISTS used_signatures CASCADE;
+DROP TABLE IF EXISTS incident_mode CASCADE;
+DROP TABLE IF EXISTS webhook_events CASCADE;
+DROP TABLE IF EXISTS announcement_dismissals CASCADE;
+DROP TABLE IF EXISTS announcements CASCADE;
+DROP TABLE IF EXISTS digest_subscriptions CASCADE;
+DROP TABLE IF EXISTS push_held_notifications CASCADE;
+DROP TABLE IF EXISTS push_quiet_hours CASCADE;
+DROP TABLE IF EXISTS push_subscriptions CASCADE;
+DROP TABLE IF EXISTS push_devices CASCADE;
+DROP TABLE IF EXISTS email_templates CASCADE;
+DROP TABLE IF EXISTS subscriptions CASCADE;
+DROP TABLE IF EXISTS api_usage_daily CASCADE;
+DROP TABLE IF EXISTS oauth_clients CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS saved_searches CASCADE;
+DROP TABLE IF EXISTS feed_entries CASCADE;
+DROP TABLE IF EXISTS login_challenges CASCADE;
+DROP TABLE IF EXISTS invite_uses CASCADE;
+DROP TABLE IF EXISTS invites CASCADE;
+DROP TABLE IF EXISTS username_history CASCADE;
+DROP TABLE IF EXISTS email_change_requests CASCADE;
+DROP TABLE IF EXISTS post_title_words CASCADE;
 DROP TABLE IF EXISTS posts CASCADE;
 DROP TABLE IF EXISTS users CASCADE;
 
+-- refresh_tokens and audit_logs are partitioned by month. Tables created before partitioning
+-- are dropped: their rows reference the users recreated above and cannot be kept anyway.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM pg_class WHERE relname = 'refresh_tokens' AND relkind = 'r' AND relnamespace = current_schema()::regnamespace) THEN
+        DROP TABLE refresh_tokens;
+    END IF;
+    IF EXISTS (SELECT 1 FROM pg_class WHERE relname = 'audit_logs' AND relkind = 'r' AND relnamespace = current_schema()::regnamespace) THEN
+        DROP TABLE audit_logs;
+    END IF;
+END $$;
+
 -- Create users table with UUID and security enhancements
 CREATE TABLE users (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
-    username VARCHAR(20) UNIQUE NOT NULL,
-    email VARCHAR(255) UNIQUE NOT NULL,
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(), -- The API sets UUIDv7 IDs (see internal/pkg/ids)
+    username VARCHAR(20) NOT NULL,
+    username_skeleton VARCHAR(40) NOT NULL,
+    email VARCHAR(255) NOT NULL,
+    pending_email VARCHAR(255),
+    email_undeliverable VARCHAR(20), -- bounce or complaint when the mail provider reported the address; mail to it is suppressed
+    email_undeliverable_at TIMESTAMP,
     password VARCHAR(255) NOT NULL,
-    is_active BOOLEAN DEFAULT true,
+    role VARCHAR(20) NOT NULL DEFAULT 'user',
+    plan VARCHAR(20) NOT NULL DEFAULT 'free', -- free or pro; see PlanResolver for billing-driven plans
+    is_active BOOLEAN NOT NULL DEFAULT true, -- Scanned into a bool, so never NULL
+    deactivated_at TIMESTAMP, -- When the account was last deactivated, NULL while active
+    must_change_password BOOLEAN NOT NULL DEFAULT false,
     last_login TIMESTAMP,
+    last_seen_at TIMESTAMP,
+    inactivity_warned_at TIMESTAMP,
+    tokens_denied_before TIMESTAMP,
     failed_login_attempts INTEGER DEFAULT 0,
     locked_until TIMESTAMP,
+    preferred_languages TEXT[] NOT NULL DEFAULT '{}', -- ISO 639-1 codes listed first in the default feed
+    timezone VARCHAR(64) NOT NULL DEFAULT 'UTC', -- IANA name; emails and exports show times in it
+    phone_number TEXT, -- E.164, encrypted by the API (see internal/pkg/fieldcrypt)
+    phone_number_index VARCHAR(64), -- Blind index of the phone number, for lookups
+    birthdate DATE, -- Optional; accounts under the parental consent age are restricted without consent
+    parental_consent_at TIMESTAMP, -- When a parent confirmed consent for an account under that age
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 -- Create posts table with UUID and security enhancements
 CREATE TABLE posts (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(), -- The API sets UUIDv7 IDs
+    number BIGSERIAL NOT NULL UNIQUE, -- Encoded as the short ID of shareable links (see internal/pkg/shortid)
     title VARCHAR(200) NOT NULL,
     content TEXT NOT NULL,
-    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    excerpt TEXT NOT NULL DEFAULT '', -- Plain-text start of the content, computed by the API
+    reading_time_minutes INTEGER NOT NULL DEFAULT 0,
+    content_format VARCHAR(10) NOT NULL DEFAULT 'markdown', -- markdown, html or text; html is sanitized on write
+    content_sanitized BOOLEAN NOT NULL DEFAULT false, -- Sanitization removed markup from the submitted content
+    language VARCHAR(8) NOT NULL DEFAULT '', -- Detected ISO 639-1 code, empty when undetermined
+    view_count BIGINT NOT NULL DEFAULT 0, -- Reads of the post by anyone but its author
+    author_id UUID REFERENCES users(id) ON DELETE CASCADE, -- NULL once the author is deleted with DELETED_USER_POSTS=anonymize
     is_published BOOLEAN DEFAULT false,
+    visibility VARCHAR(10) NOT NULL DEFAULT 'public' CHECK (visibility IN ('public', 'unlisted', 'followers', 'private')), -- Unlisted posts are readable by link but never listed
+    archived_at TIMESTAMP, -- Archived posts are hidden from listings except the author's own
+    scheduled_at TIMESTAMP, -- Unpublished posts are published automatically once this time passes
+    published_at TIMESTAMP, -- When the post was last published; saved search alerts match posts published since their last check
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
--- Create refresh tokens table for JWT security
+-- Create the words of listed public post titles, kept by a trigger on posts, from which search
+-- corrections are picked
+CREATE TABLE IF NOT EXISTS post_title_words (
+    word TEXT PRIMARY KEY, -- Lowercase
+    posts INTEGER NOT NULL -- Published, unarchived public posts with the word in their title
+);
+
+-- Create refresh tokens table for JWT security, partitioned by the month tokens expire in so
+-- that expired tokens are dropped a partition at a time. Unique keys of a partitioned table
+-- must include the partition key.
 CREATE TABLE IF NOT EXISTS refresh_tokens (
-    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
     user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-    token_id VARCHAR(32) NOT NULL UNIQUE,
+    token_id VARCHAR(32) NOT NULL,
     token_hash VARCHAR(255) NOT NULL,
     expires_at TIMESTAMP NOT NULL,
     is_revoked BOOLEAN DEFAULT false,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-    revoked_at TIMESTAMP
+    revoked_at TIMESTAMP,
+    ip_address INET,
+    user_agent TEXT,
+    country VARCHAR(2),
+    city VARCHAR(100),
+    device_id VARCHAR(128),
+    fingerprint VARCHAR(64),
+    PRIMARY KEY (id, expires_at),
+    UNIQUE (token_id, expires_at)
+) PARTITION BY RANGE (expires_at);
+
+-- Create username history table to support renames and old-username redirects
+CREATE TABLE IF NOT EXISTS username_history (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_username VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    reserved_until TIMESTAMP NOT NULL
 );
 
--- Create audit log table for security monitoring
-CREATE TABLE IF NOT EXISTS audit_logs (
+-- Create email change requests table (confirmation sent to the new address, undo link to the old one)
+CREATE TABLE IF NOT EXISTS email_change_requests (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    old_email VARCHAR(255) NOT NULL,
+    new_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    undo_token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    undo_expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
+    cancelled_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create invites table for invite-only registration (only a hash of the code is stored)
+CREATE TABLE IF NOT EXISTS invites (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    code_hash VARCHAR(64) NOT NULL UNIQUE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    email VARCHAR(255),
+    max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    use_count INTEGER NOT NULL DEFAULT 0 CHECK (use_count >= 0),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create invite uses table tracking which users registered with which invite
+CREATE TABLE IF NOT EXISTS invite_uses (
+    invite_id UUID NOT NULL REFERENCES invites(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    used_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (invite_id, user_id)
+);
+
+-- Create login challenges table for step-up verification of risky sign-ins (only a hash of the code is stored)
+CREATE TABLE IF NOT EXISTS login_challenges (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    ip_address INET,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create API keys table for scoped, long-lived credentials (only a hash of the key is stored)
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    key_hash VARCHAR(64) NOT NULL UNIQUE,
+    hint VARCHAR(8) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    last_used_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create OAuth clients table for machine clients using the client credentials grant (only a hash of the secret is stored)
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    client_id VARCHAR(64) NOT NULL UNIQUE,
+    secret_hash VARCHAR(64) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    tokens_denied_before TIMESTAMP, -- Access tokens issued at or before it are denied
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create audit log table for security monitoring, partitioned by the month of the event so
+-- that logs past their retention are dropped a partition at a time
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id UUID NOT NULL DEFAULT uuid_generate_v4(),
     user_id UUID REFERENCES users(id) ON DELETE SET NULL,
     action VARCHAR(50) NOT NULL,
     resource_type VARCHAR(50),
     resource_id UUID,
     ip_address INET,
     user_agent TEXT,
+    country VARCHAR(2),
+    city VARCHAR(100),
     details JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+-- Create daily API usage per caller (user, API key or OAuth client) and route, flushed from
+-- in-memory counters for usage reports and future billing or quota enforcement
+CREATE TABLE IF NOT EXISTS api_usage_daily (
+    day DATE NOT NULL,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    api_key_id UUID REFERENCES api_keys(id) ON DELETE CASCADE,
+    client_id VARCHAR(64),
+    method VARCHAR(10) NOT NULL,
+    route VARCHAR(255) NOT NULL,
+    requests BIGINT NOT NULL DEFAULT 0,
+    errors BIGINT NOT NULL DEFAULT 0
+);
+
+-- Create Stripe subscriptions of users, kept up to date by the Stripe webhook; users are on
+-- the pro plan while one of them is active on a pro price
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id VARCHAR(255) PRIMARY KEY, -- Stripe subscription ID
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    customer_id VARCHAR(255) NOT NULL,
+    status VARCHAR(30) NOT NULL,
+    price_id VARCHAR(255),
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    current_period_end TIMESTAMP,
+    event_at TIMESTAMP NOT NULL, -- Creation time of the latest event applied; older events delivered late are ignored
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create versions of transactional email templates edited by admins; the latest version of a
+-- template replaces its default embedded in internal/mailer/templates
+CREATE TABLE IF NOT EXISTS email_templates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(100) NOT NULL, -- Default template file name without extension, e.g. login_otp
+    version INTEGER NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (name, version)
+);
+
+-- Create weekly digest opt-ins; users without a row get no digest
+CREATE TABLE IF NOT EXISTS digest_subscriptions (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    next_send_at TIMESTAMP NOT NULL,
+    last_sent_at TIMESTAMP,
+    unsubscribe_token_hash VARCHAR(64) UNIQUE, -- Hash of the unsubscribe token in the latest digest
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create app installations receiving push notifications; a token belongs to one user at a time
+CREATE TABLE IF NOT EXISTS push_devices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    platform VARCHAR(10) NOT NULL CHECK (platform IN ('fcm', 'apns')),
+    token TEXT NOT NULL UNIQUE, -- FCM registration token or APNs device token
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create push topic subscriptions, e.g. author:<user ID> for new posts, post:<post ID> for new comments
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    topic VARCHAR(100) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, topic)
+);
+
+-- Create daily quiet hours, in the timezone of the user's preferences
+CREATE TABLE IF NOT EXISTS push_quiet_hours (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    start_time VARCHAR(5) NOT NULL, -- HH:MM
+    end_time VARCHAR(5) NOT NULL, -- HH:MM, before start_time when spanning midnight
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create notifications held during quiet hours; a newer one with the same collapse key replaces the older
+CREATE TABLE IF NOT EXISTS push_held_notifications (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    collapse_key VARCHAR(100) NOT NULL,
+    title TEXT NOT NULL,
+    body TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}',
+    release_at TIMESTAMP NOT NULL, -- End of the quiet hours it arrived in
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, collapse_key)
+);
+
+-- Create announcement banners; empty roles or plans put every user in the audience
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    message TEXT NOT NULL,
+    severity VARCHAR(10) NOT NULL DEFAULT 'info' CHECK (severity IN ('info', 'warning', 'critical')),
+    dismissible BOOLEAN NOT NULL DEFAULT true,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP CHECK (ends_at > starts_at), -- Runs until deleted when NULL
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    plans TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create announcement dismissals, so that a dismissed banner is not shown to the user again
+CREATE TABLE IF NOT EXISTS announcement_dismissals (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    dismissed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, announcement_id)
+);
+
+-- Create handled inbound webhook events, so that redelivered events are processed once
+CREATE TABLE IF NOT EXISTS webhook_events (
+    provider VARCHAR(50) NOT NULL, -- e.g. stripe
+    event_id VARCHAR(255) NOT NULL, -- the provider's event or delivery ID
+    received_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    claimed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- when the delivery being processed claimed the event
+    processed_at TIMESTAMP, -- NULL while the event is being processed
+    PRIMARY KEY (provider, event_id)
+);
+
+-- Create the state of security incident mode, shared by every instance; the single row exists
+-- while incident mode is enabled
+CREATE TABLE IF NOT EXISTS incident_mode (
+    id BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+    enabled_at TIMESTAMP NOT NULL,
+    enabled_by UUID REFERENCES users(id) ON DELETE SET NULL, -- NULL when INCIDENT_MODE enabled it
+    revoked_before TIMESTAMP, -- Cutoff of the revocation when enabling, NULL when INCIDENT_MODE enabled it
+    revoked_users BIGINT,
+    revoked_sessions BIGINT,
+    revoked_clients BIGINT
+);
+
+-- Create used signatures of signed admin requests, shared by every instance so that a signature
+-- is accepted once whichever instance verifies it
+CREATE TABLE IF NOT EXISTS used_signatures (
+    signature VARCHAR(64) PRIMARY KEY, -- hex HMAC-SHA256
+    expires_at TIMESTAMP NOT NULL -- when its timestamp leaves the allowed skew
+);
+
+-- Create hourly login statistics, rolled up from login audit logs for abuse investigation
+CREATE TABLE IF NOT EXISTS login_stats_hourly (
+    hour TIMESTAMP NOT NULL,
+    ip_address INET,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    successes INTEGER NOT NULL DEFAULT 0,
+    failures INTEGER NOT NULL DEFAULT 0
+);
+
+-- Create follows between users, counted on public profiles
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    followee_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+-- Create stored feeds: the posts of followed authors fanned out to each follower on publish,
+-- when FEED_FANOUT is on, so reading a feed is a range scan instead of a join across follows
+CREATE TABLE IF NOT EXISTS feed_entries (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    published_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Create soft editing locks on posts, held by one editing session until released or expired
+CREATE TABLE IF NOT EXISTS post_locks (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token VARCHAR(128) NOT NULL,
+    acquired_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+-- Create autosaved drafts of posts, kept apart so autosaving leaves the post and its updated_at untouched
+CREATE TABLE IF NOT EXISTS post_autosaves (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    title VARCHAR(200) NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    saved_at TIMESTAMP NOT NULL
+);
+
+-- Create daily per-author statistics, computed by the author stats job
+CREATE TABLE IF NOT EXISTS author_stats (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    day DATE NOT NULL,
+    posts INTEGER NOT NULL DEFAULT 0,
+    published_posts INTEGER NOT NULL DEFAULT 0,
+    views BIGINT NOT NULL DEFAULT 0,
+    likes INTEGER NOT NULL DEFAULT 0,
+    reactions INTEGER NOT NULL DEFAULT 0,
+    followers INTEGER NOT NULL DEFAULT 0,
+    follower_growth INTEGER NOT NULL DEFAULT 0, -- Change in followers since the author's previous day
+    computed_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, day)
+);
+
+-- Create comments on posts; replies reference their parent and are one level deeper
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(), -- The API sets UUIDv7 IDs
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_comment_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+    depth INTEGER NOT NULL DEFAULT 0,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create reactions to posts and comments; each row targets exactly one of them
+DO $$ BEGIN
+    CREATE TYPE reaction_type AS ENUM ('like', 'heart', 'laugh', 'wow', 'sad', 'angry');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+
+CREATE TABLE IF NOT EXISTS reactions (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+    reaction reaction_type NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK ((post_id IS NULL) <> (comment_id IS NULL))
+);
+
+-- Create policy documents: the published versions of the terms of service and privacy policy.
+-- Publishing a mandatory version requires users to accept it (or a later version) again.
+CREATE TABLE IF NOT EXISTS policy_documents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('terms', 'privacy')),
+    version VARCHAR(50) NOT NULL,
+    url TEXT NOT NULL,
+    mandatory BOOLEAN NOT NULL DEFAULT true,
+    published_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    published_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (kind, version)
+);
+
+-- Create policy acceptances recording which versions each user accepted and when
+CREATE TABLE IF NOT EXISTS policy_acceptances (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES policy_documents(id) ON DELETE CASCADE,
+    accepted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, document_id)
+);
+
+-- Create parental consent requests: links sent to the parent of an account under the consent
+-- age (only a hash of the token is stored)
+CREATE TABLE IF NOT EXISTS parental_consents (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    parent_email VARCHAR(255) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP,
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Create legal holds: users whose data must be preserved until the hold is cleared. Deleting
+-- a held account is refused by the API, and by RESTRICT as a backstop.
+CREATE TABLE IF NOT EXISTS legal_holds (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE RESTRICT,
+    reason TEXT NOT NULL,
+    placed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    placed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create tombstones of deleted posts, comments and users, so sync clients and other consumers
+-- learn about removals. Rows are written by the record_deletion trigger, which also covers rows
+-- removed by cascades, and pruned once past DB_TOMBSTONE_RETENTION_DAYS.
+CREATE TABLE IF NOT EXISTS deleted_records (
+    resource_type VARCHAR(30) NOT NULL,
+    resource_id UUID NOT NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+-- Create break-glass accounts: emergency credentials of admins, stored hashed. They are
+-- disabled (enabled_until NULL) until an operator enables them from the host.
+CREATE TABLE IF NOT EXISTS break_glass_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    credential_hash VARCHAR(64) NOT NULL,
+    enabled_until TIMESTAMP,
+    enabled_by VARCHAR(255),
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create saved searches: full-text queries a user is alerted about when newly published posts
+-- match them. The alert job checks each search at its frequency ('off' after unsubscribing),
+-- matching posts published after checked_at. Only a hash of the unsubscribe token from the
+-- latest alert is stored.
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query VARCHAR(200) NOT NULL,
+    frequency VARCHAR(10) NOT NULL DEFAULT 'daily' CHECK (frequency IN ('hourly', 'daily', 'weekly', 'off')),
+    checked_at TIMESTAMP NOT NULL,
+    next_check_at TIMESTAMP NOT NULL,
+    unsubscribe_token_hash VARCHAR(64) UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 -- Create indexes for better performance and security
-CREATE INDEX idx_users_email ON users(email);
-CREATE INDEX idx_users_username ON users(username);
+-- Case-insensitive uniqueness (emails are stored lowercased and NFC-normalized, usernames NFC-normalized)
+CREATE UNIQUE INDEX idx_users_email_lower ON users(LOWER(email));
+CREATE UNIQUE INDEX idx_users_username_lower ON users(LOWER(username));
+-- Homoglyph protection: usernames that fold to the same skeleton are considered duplicates
+CREATE UNIQUE INDEX idx_users_username_skeleton ON users(username_skeleton);
+CREATE UNIQUE INDEX idx_users_phone_number_index ON users(phone_number_index);
 CREATE INDEX idx_users_is_active ON users(is_active);
+CREATE INDEX idx_users_role ON users(role);
+CREATE INDEX idx_users_last_seen_at ON users(last_seen_at);
 CREATE INDEX idx_users_locked_until ON users(locked_until);
+CREATE INDEX idx_users_username_trgm ON users USING GIN (LOWER(username) gin_trgm_ops);
 
 CREATE INDEX idx_posts_author_id ON posts(author_id);
 CREATE INDEX idx_posts_created_at ON posts(created_at DESC);
 CREATE INDEX idx_posts_is_published ON posts(is_published);
 CREATE INDEX idx_posts_author_published ON posts(author_id, is_published);
+CREATE INDEX idx_posts_not_archived ON posts(created_at DESC) WHERE archived_at IS NULL;
+CREATE INDEX idx_posts_language ON posts(language, created_at DESC) WHERE archived_at IS NULL;
+CREATE INDEX idx_posts_listed ON posts(created_at DESC) WHERE archived_at IS NULL AND visibility IN ('public', 'followers');
+CREATE INDEX idx_posts_scheduled ON posts(scheduled_at) WHERE scheduled_at IS NOT NULL;
+CREATE INDEX idx_posts_author_scheduled ON posts(author_id, scheduled_at) WHERE scheduled_at IS NOT NULL;
+CREATE INDEX idx_posts_published_at ON posts(published_at) WHERE is_published = true AND archived_at IS NULL;
+-- The join-based feed reads the published posts of each followed author newest first
+CREATE INDEX idx_posts_author_published_at ON posts(author_id, published_at DESC) WHERE is_published = true AND archived_at IS NULL;
+-- Full-text search of titles and content, in the language-neutral simple configuration
+CREATE INDEX idx_posts_search ON posts USING GIN (to_tsvector('simple', title || ' ' || content));
+-- Trigrams of titles, for suggestions and fuzzy matches of misspelled searches
+CREATE INDEX idx_posts_title_trgm ON posts USING GIN (LOWER(title) gin_trgm_ops);
+-- Trigrams of title words, for the corrections of sparse searches
+CREATE INDEX idx_post_title_words_trgm ON post_title_words USING GIN (word gin_trgm_ops);
 
 CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
-CREATE INDEX IF NOT EXISTS idx_refresh_tokens_token_id ON refresh_tokens(token_id);
 CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
 CREATE INDEX IF NOT EXISTS idx_refresh_tokens_is_revoked ON refresh_tokens(is_revoked);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_device ON refresh_tokens(user_id, device_id);
+
+CREATE INDEX IF NOT EXISTS idx_username_history_user_id ON username_history(user_id);
+CREATE INDEX IF NOT EXISTS idx_username_history_old_username ON username_history(LOWER(old_username), changed_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_email_change_requests_user_id ON email_change_requests(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_invites_created_by ON invites(created_by);
+CREATE INDEX IF NOT EXISTS idx_invite_uses_user_id ON invite_uses(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_login_challenges_user_id ON login_challenges(user_id);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
 
 CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_action ON audit_logs(user_id, action, created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_action ON audit_logs(action);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_audit_logs_ip_address ON audit_logs(ip_address);
 
+-- One row per day, caller and route; callers without an API key or client share NULLs
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_usage_daily_key ON api_usage_daily(day, user_id, api_key_id, client_id, method, route) NULLS NOT DISTINCT;
+CREATE INDEX IF NOT EXISTS idx_api_usage_daily_api_key ON api_usage_daily(api_key_id, day DESC) WHERE api_key_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_api_usage_daily_user ON api_usage_daily(user_id, day DESC);
+CREATE INDEX IF NOT EXISTS idx_api_usage_daily_day ON api_usage_daily(day DESC);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_user_id ON subscriptions(user_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_customer_id ON subscriptions(customer_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_events_received_at ON webhook_events(received_at);
+CREATE INDEX IF NOT EXISTS idx_used_signatures_expires_at ON used_signatures(expires_at);
+CREATE INDEX IF NOT EXISTS idx_digest_subscriptions_next_send_at ON digest_subscriptions(next_send_at);
+CREATE INDEX IF NOT EXISTS idx_push_devices_user_id ON push_devices(user_id, updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_topic ON push_subscriptions(topic, user_id);
+CREATE INDEX IF NOT EXISTS idx_push_held_notifications_release_at ON push_held_notifications(release_at);
+CREATE INDEX IF NOT EXISTS idx_announcements_starts_at ON announcements(starts_at DESC);
+CREATE INDEX IF NOT EXISTS idx_announcement_dismissals_announcement_id ON announcement_dismissals(announcement_id);
+
+CREATE INDEX IF NOT EXISTS idx_login_stats_hourly_hour ON login_stats_hourly(hour DESC);
+CREATE INDEX IF NOT EXISTS idx_login_stats_hourly_ip ON login_stats_hourly(ip_address, hour DESC);
+CREATE INDEX IF NOT EXISTS idx_login_stats_hourly_user ON login_stats_hourly(user_id, hour DESC);
+
+CREATE INDEX IF NOT EXISTS idx_follows_followee_id ON follows(followee_id);
+
+-- A stored feed is read newest first, and unfollowing removes an author's entries
+CREATE INDEX IF NOT EXISTS idx_feed_entries_user_published ON feed_entries(user_id, published_at DESC, post_id DESC);
+CREATE INDEX IF NOT EXISTS idx_feed_entries_user_author ON feed_entries(user_id, author_id);
+CREATE INDEX IF NOT EXISTS idx_feed_entries_post_id ON feed_entries(post_id);
+
+CREATE INDEX IF NOT EXISTS idx_author_stats_day ON author_stats(day DESC);
+
+CREATE INDEX IF NOT EXISTS idx_comments_post_id ON comments(post_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_comment_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_comments_author_id ON comments(author_id);
+
+-- One reaction per user and target, and per-target counts by type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_user_post ON reactions(user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_reactions_user_comment ON reactions(user_id, comment_id) WHERE comment_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_reactions_post_id ON reactions(post_id, reaction) WHERE post_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_reactions_comment_id ON reactions(comment_id, reaction) WHERE comment_id IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_policy_documents_kind ON policy_documents(kind, published_at DESC);
+
+CREATE INDEX IF NOT EXISTS idx_parental_consents_user_id ON parental_consents(user_id);
+
+-- The sync change feed reads posts and tombstones in (change time, id) order
+CREATE INDEX IF NOT EXISTS idx_posts_updated_at_id ON posts(updated_at, id);
+CREATE INDEX IF NOT EXISTS idx_deleted_records_deleted_at ON deleted_records(resource_type, deleted_at, resource_id);
+CREATE INDEX IF NOT EXISTS idx_deleted_records_prune ON deleted_records(deleted_at);
+
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches(user_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_due ON saved_searches(next_check_at) WHERE frequency <> 'off';
+
+CREATE INDEX IF NOT EXISTS idx_break_glass_accounts_enabled_until ON break_glass_accounts(enabled_until) WHERE enabled_until IS NOT NULL;
+
 -- Create function to update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -88,14 +654,73 @@ END;
 $$ language 'plpgsql';
 
 -- Create triggers to automatically update the updated_at column
+-- (activity tracking via last_seen_at, inactivity warnings and re-encryption under a new key,
+-- which sets app.key_rotation, are not considered a modification of the user)
 CREATE TRIGGER update_users_updated_at 
     BEFORE UPDATE ON users
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+    FOR EACH ROW
+    WHEN (OLD.last_seen_at IS NOT DISTINCT FROM NEW.last_seen_at
+          AND OLD.inactivity_warned_at IS NOT DISTINCT FROM NEW.inactivity_warned_at
+          AND current_setting('app.key_rotation', true) IS DISTINCT FROM 'on')
+    EXECUTE FUNCTION update_updated_at_column();
 
 CREATE TRIGGER update_posts_updated_at 
     BEFORE UPDATE ON posts
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_comments_updated_at 
+    BEFORE UPDATE ON comments
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Create function recording a tombstone of each deleted row, of the resource type given as
+-- the trigger argument
+CREATE OR REPLACE FUNCTION record_deletion()
+RETURNS TRIGGER AS $$
+BEGIN
+    INSERT INTO deleted_records (resource_type, resource_id) VALUES (TG_ARGV[0], OLD.id)
+    ON CONFLICT (resource_type, resource_id) DO UPDATE SET deleted_at = EXCLUDED.deleted_at;
+    RETURN OLD;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER record_posts_deletion
+    AFTER DELETE ON posts
+    FOR EACH ROW EXECUTE FUNCTION record_deletion('post');
+
+CREATE TRIGGER record_comments_deletion
+    AFTER DELETE ON comments
+    FOR EACH ROW EXECUTE FUNCTION record_deletion('comment');
+
+CREATE TRIGGER record_users_deletion
+    AFTER DELETE ON users
+    FOR EACH ROW EXECUTE FUNCTION record_deletion('user');
+
+-- Create function counting the words of a post's title in post_title_words while the post is
+-- published, unarchived and public. Words no longer in any such title are removed.
+CREATE OR REPLACE FUNCTION update_post_title_words()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.is_published AND OLD.archived_at IS NULL AND OLD.visibility = 'public' THEN
+        UPDATE post_title_words SET posts = posts - 1
+        WHERE word IN (SELECT regexp_split_to_table(LOWER(OLD.title), '[^[:alnum:]]+'));
+        DELETE FROM post_title_words
+        WHERE word IN (SELECT regexp_split_to_table(LOWER(OLD.title), '[^[:alnum:]]+')) AND posts <= 0;
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.is_published AND NEW.archived_at IS NULL AND NEW.visibility = 'public' THEN
+        INSERT INTO post_title_words (word, posts)
+        SELECT DISTINCT w.word, 1 FROM regexp_split_to_table(LOWER(NEW.title), '[^[:alnum:]]+') AS w(word)
+        WHERE w.word <> ''
+        ORDER BY w.word
+        ON CONFLICT (word) DO UPDATE SET posts = post_title_words.posts + 1;
+    END IF;
+    RETURN NULL;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER update_posts_title_words
+    AFTER INSERT OR DELETE OR UPDATE OF title, is_published, archived_at, visibility ON posts
+    FOR EACH ROW EXECUTE FUNCTION update_post_title_words();
+
 -- Create function to clean up expired refresh tokens
 CREATE OR REPLACE FUNCTION cleanup_expired_tokens()
 RETURNS void AS $$
@@ -115,11 +740,30 @@ BEGIN
 END;
 $$ language 'plpgsql';
 
+-- Create function to create the partition of a monthly partitioned table holding the given
+-- month, named after the table and month (audit_logs_2026_10). The partition maintenance job
+-- calls it to create partitions ahead of time.
+CREATE OR REPLACE FUNCTION create_monthly_partition(parent_table TEXT, in_month DATE)
+RETURNS TEXT AS $$
+DECLARE
+    month_start DATE := date_trunc('month', in_month);
+    partition_name TEXT := parent_table || '_' || to_char(month_start, 'YYYY_MM');
+BEGIN
+    EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+        partition_name, parent_table, month_start, month_start + INTERVAL '1 month');
+    RETURN partition_name;
+END;
+$$ language 'plpgsql';
+
+-- Create the partitions of the current and next three months
+SELECT create_monthly_partition(parent_table, (CURRENT_DATE + make_interval(months => ahead))::date)
+FROM unnest(ARRAY['refresh_tokens', 'audit_logs']) AS parent_table, generate_series(0, 3) AS ahead;
+
 -- Insert some sample data for testing
-INSERT INTO users (username, email, password, is_active) VALUES 
-    ('admin', 'admin@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', true),
-    ('testuser', 'test@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', true)
-ON CONFLICT (email) DO NOTHING;
+INSERT INTO users (username, username_skeleton, email, password, role, is_active) VALUES 
+    ('admin', 'admin', 'admin@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'admin', true),
+    ('testuser', 'testuser', 'test@example.com', '$2a$10$92IXUNpkjO0rOQ5byMi.Ye4oKoEa3Ro9llC/.og/at2.uheWG/igi', 'user', true)
+ON CONFLICT (LOWER(email)) DO NOTHING;
 
 -- Insert some sample posts
 INSERT INTO posts (title, content, author_id, is_published) 
@@ -149,6 +793,10 @@ SELECT
 FROM users u WHERE u.username = 'testuser'
 ON CONFLICT DO NOTHING;
 
+-- The sample posts are shorter than an excerpt, and in English
+UPDATE posts SET excerpt = content, reading_time_minutes = 1, language = 'en' WHERE excerpt = '';
+UPDATE posts SET published_at = created_at WHERE is_published = true AND published_at IS NULL;
+
 -- Create a view for published posts with author information
 CREATE VIEW published_posts_with_author AS
 SELECT 
@@ -162,8 +810,8 @@ SELECT
     u.username as author_username,
     u.email as author_email
 FROM posts p
-JOIN users u ON p.author_id = u.id
-WHERE p.is_published = true
+LEFT JOIN users u ON p.author_id = u.id
+WHERE p.is_published = true AND p.archived_at IS NULL
 ORDER BY p.created_at DESC;
 
 -- Grant necessary permissions
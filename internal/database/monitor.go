@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/pkg/metrics"
+)
+
+// Defaults of the health monitor
+const (
+	DefaultHealthTimeout    = 5 * time.Second
+	DefaultHealthFailures   = 3
+	DefaultReconnectMax     = time.Minute
+	initialReconnectBackoff = time.Second
+)
+
+// MonitorConfig configures the database health monitor
+type MonitorConfig struct {
+	// Interval between pings while the database is healthy (0 disables the monitor)
+	Interval time.Duration
+	// Timeout of each ping
+	Timeout time.Duration
+	// FailureThreshold is how many pings in a row must fail before the database is unhealthy
+	FailureThreshold int
+	// MaxBackoff caps the delay between reconnection attempts, which starts at a second and doubles
+	MaxBackoff time.Duration
+}
+
+// HealthStatus is a snapshot of the database health monitor
+type HealthStatus struct {
+	Healthy             bool       `json:"healthy"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	LastCheckedAt       *time.Time `json:"last_checked_at,omitempty"`
+	UnhealthySince      *time.Time `json:"unhealthy_since,omitempty"`
+	Checks              uint64     `json:"checks"`
+	Failures            uint64     `json:"failures"`
+	Reconnects          uint64     `json:"reconnects"` // Recoveries from unhealthy
+}
+
+// Monitor pings the database in the background and tracks whether it is healthy. A failed
+// ping is retried with backoff rather than at the regular interval: database/sql discards
+// connections the driver reports as bad and dials new ones, so each retry is a reconnection
+// attempt. Readiness checks read Healthy instead of pinging on every request.
+type Monitor struct {
+	db  *sql.DB
+	cfg MonitorConfig
+
+	checks     metrics.Counter
+	failures   metrics.Counter
+	reconnects metrics.Counter
+
+	mutex          sync.RWMutex
+	healthy        bool
+	consecutive    int
+	lastError      error
+	lastCheckedAt  time.Time
+	unhealthySince time.Time
+}
+
+// NewMonitor creates a health monitor of db; the database is assumed healthy until checked
+func NewMonitor(db *sql.DB, cfg MonitorConfig) *Monitor {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultHealthTimeout
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultHealthFailures
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultReconnectMax
+	}
+	return &Monitor{db: db, cfg: cfg, healthy: true}
+}
+
+// Start pings the database until stop is closed
+func (m *Monitor) Start(stop <-chan struct{}) {
+	if m.cfg.Interval <= 0 {
+		return
+	}
+
+	go func() {
+		delay, backoff := m.cfg.Interval, time.Duration(0)
+		for {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				return
+			}
+
+			if m.Check() == nil {
+				delay, backoff = m.cfg.Interval, 0
+				continue
+			}
+			if backoff == 0 {
+				backoff = initialReconnectBackoff
+			} else {
+				backoff = min(2*backoff, m.cfg.MaxBackoff)
+			}
+			delay = backoff
+		}
+	}()
+}
+
+// Check pings the database once and records the outcome
+func (m *Monitor) Check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.cfg.Timeout)
+	defer cancel()
+	err := m.db.PingContext(ctx)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.checks.Inc()
+	m.lastCheckedAt = time.Now()
+	m.lastError = err
+	if err == nil {
+		if !m.healthy {
+			m.reconnects.Inc()
+			log.Printf("Database reconnected after being unhealthy for %s", time.Since(m.unhealthySince).Round(time.Second))
+		}
+		m.healthy, m.consecutive = true, 0
+		return nil
+	}
+
+	m.failures.Inc()
+	m.consecutive++
+	log.Printf("Database ping failed (%d in a row): %v", m.consecutive, err)
+	if m.healthy && m.consecutive >= m.cfg.FailureThreshold {
+		m.healthy, m.unhealthySince = false, m.lastCheckedAt
+		log.Printf("Database is unhealthy after %d failed pings; retrying with backoff", m.consecutive)
+	}
+	return err
+}
+
+// Healthy reports whether the database answered recently
+func (m *Monitor) Healthy() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.healthy
+}
+
+// ConsecutiveFailures returns how many pings in a row have failed
+func (m *Monitor) ConsecutiveFailures() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.consecutive
+}
+
+// Status returns a snapshot of the monitor
+func (m *Monitor) Status() *HealthStatus {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := &HealthStatus{
+		Healthy:             m.healthy,
+		ConsecutiveFailures: m.consecutive,
+		Checks:              m.checks.Value(),
+		Failures:            m.failures.Value(),
+		Reconnects:          m.reconnects.Value(),
+	}
+	if m.lastError != nil {
+		status.LastError = m.lastError.Error()
+	}
+	if !m.lastCheckedAt.IsZero() {
+		checkedAt := m.lastCheckedAt
+		status.LastCheckedAt = &checkedAt
+	}
+	if !m.healthy {
+		since := m.unhealthySince
+		status.UnhealthySince = &since
+	}
+	return status
+}
@@ -0,0 +1,134 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go-backend-api/internal/pkg/redact"
+)
+
+// QueryEvent describes an executed statement
+type QueryEvent struct {
+	Query    string
+	Args     []string // Formatted, with sensitive values redacted
+	Duration time.Duration
+	Rows     int64 // Rows affected or, for queries, rows read
+	Err      error
+}
+
+// queryLogger receives a QueryEvent for every statement while query logging is on
+var queryLogger atomic.Pointer[func(QueryEvent)]
+
+// SetQueryLogger turns query logging on with fn receiving every statement, or off with nil.
+// It can be called at any time; open connections pick the change up immediately.
+func SetQueryLogger(fn func(QueryEvent)) {
+	if fn == nil {
+		queryLogger.Store(nil)
+		return
+	}
+	queryLogger.Store(&fn)
+}
+
+// QueryLogging reports whether query logging is on
+func QueryLogging() bool {
+	return queryLogger.Load() != nil
+}
+
+// maxLoggedArg is how many characters of a parameter are logged
+const maxLoggedArg = 64
+
+// sensitiveColumns hold credentials or their hashes; parameters bound to them are redacted
+var sensitiveColumns = map[string]bool{
+	"password": true, "token": true, "token_hash": true, "undo_token_hash": true,
+	"key_hash": true, "code_hash": true, "secret_hash": true, "fingerprint": true,
+}
+
+var (
+	comparedParamPattern = regexp.MustCompile(`(?i)(\w+)\s*(?:=|<>|!=)\s*\$(\d+)`)
+	insertPattern        = regexp.MustCompile(`(?is)INSERT INTO \w+\s*\(([^)]*)\)\s*VALUES\s*\(([^)]*)\)`)
+	placeholderPattern   = regexp.MustCompile(`^\$(\d+)$`)
+)
+
+// sensitiveParams finds the 1-based parameters of a query bound to sensitive columns,
+// in comparisons (password = $1) and INSERT ... VALUES lists
+func sensitiveParams(query string) map[int]bool {
+	params := make(map[int]bool)
+	for _, match := range comparedParamPattern.FindAllStringSubmatch(query, -1) {
+		if sensitiveColumns[strings.ToLower(match[1])] {
+			n, _ := strconv.Atoi(match[2])
+			params[n] = true
+		}
+	}
+	for _, match := range insertPattern.FindAllStringSubmatch(query, -1) {
+		columns := strings.Split(match[1], ",")
+		values := strings.Split(match[2], ",")
+		for i := 0; i < len(columns) && i < len(values); i++ {
+			placeholder := placeholderPattern.FindStringSubmatch(strings.TrimSpace(values[i]))
+			if placeholder != nil && sensitiveColumns[strings.ToLower(strings.TrimSpace(columns[i]))] {
+				n, _ := strconv.Atoi(placeholder[1])
+				params[n] = true
+			}
+		}
+	}
+	return params
+}
+
+// formatArgs formats the parameters of a query for logging
+func formatArgs(query string, args []driver.NamedValue) []string {
+	if len(args) == 0 {
+		return nil
+	}
+	sensitive := sensitiveParams(query)
+	formatted := make([]string, len(args))
+	for i, arg := range args {
+		formatted[i] = formatArg(arg.Value, sensitive[arg.Ordinal])
+	}
+	return formatted
+}
+
+// formatArg formats a parameter, redacting it if sensitive and otherwise any personal data
+// or credentials in it, e.g. emails in the arrays of bulk inserts
+func formatArg(value driver.Value, sensitive bool) string {
+	var s string
+	switch v := value.(type) {
+	case nil:
+		return "NULL"
+	case []byte:
+		return "<" + strconv.Itoa(len(v)) + " bytes>"
+	case time.Time:
+		s = v.Format(time.RFC3339Nano)
+	case string:
+		s = v
+	default:
+		s = fmt.Sprint(v)
+	}
+
+	if sensitive {
+		return redact.Placeholder
+	}
+	s = redact.String(s)
+	if len(s) > maxLoggedArg {
+		return s[:maxLoggedArg] + "…"
+	}
+	return s
+}
+
+// logQuery reports a statement to the query logger, if logging is on
+func logQuery(query string, args []driver.NamedValue, start time.Time, rows int64, err error) {
+	fn := queryLogger.Load()
+	if fn == nil || err == driver.ErrSkip {
+		return
+	}
+	(*fn)(QueryEvent{
+		Query:    strings.Join(strings.Fields(query), " "),
+		Args:     formatArgs(query, args),
+		Duration: time.Since(start),
+		Rows:     rows,
+		Err:      err,
+	})
+}
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// replicaLagQuery measures how far a replica is behind its primary, in seconds. A replica
+// that has replayed everything it received reports no lag, even when the primary has been
+// idle since its last transaction; a server that is not a replica reports none either.
+const replicaLagQuery = `SELECT CASE
+			  WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			  ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+		  END`
+
+// ReplicaStatus is a snapshot of the replica lag monitor
+type ReplicaStatus struct {
+	Fresh         bool       `json:"fresh"`
+	LagSeconds    float64    `json:"lag_seconds"`
+	MaxStaleness  string     `json:"max_staleness"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastCheckedAt *time.Time `json:"last_checked_at,omitempty"`
+}
+
+// ReplicaMonitor measures the replication lag of a read replica in the background, so that
+// reads accepting stale data are only routed to it while the lag is within a bound
+type ReplicaMonitor struct {
+	db           *sql.DB
+	interval     time.Duration
+	maxStaleness time.Duration
+
+	mutex         sync.RWMutex
+	lag           time.Duration
+	lastError     error
+	lastCheckedAt time.Time
+}
+
+// NewReplicaMonitor creates a lag monitor of db; the replica is not fresh until checked
+func NewReplicaMonitor(db *sql.DB, interval, maxStaleness time.Duration) *ReplicaMonitor {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	return &ReplicaMonitor{db: db, interval: interval, maxStaleness: maxStaleness}
+}
+
+// DB returns the replica connection
+func (m *ReplicaMonitor) DB() *sql.DB {
+	return m.db
+}
+
+// Start measures the lag every interval until stop is closed
+func (m *ReplicaMonitor) Start(stop <-chan struct{}) {
+	_ = m.Check()
+
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				_ = m.Check()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// Check measures the lag once and records the outcome
+func (m *ReplicaMonitor) Check() error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	defer cancel()
+	var seconds float64
+	err := m.db.QueryRowContext(ctx, replicaLagQuery).Scan(&seconds)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if err != nil && m.lastError == nil {
+		log.Printf("Replica lag check failed; stale reads go to the primary: %v", err)
+	} else if err == nil && m.lastError != nil {
+		log.Println("Replica lag check recovered")
+	}
+	m.lastCheckedAt = time.Now()
+	m.lastError = err
+	if err == nil {
+		m.lag = time.Duration(seconds * float64(time.Second))
+	}
+	return err
+}
+
+// Fresh reports whether the replica may serve reads: its last lag check, no older than two
+// intervals, succeeded and found it at most the maximum staleness behind. The bound covers
+// the time since that check, so a replica that stops replaying is detected promptly.
+func (m *ReplicaMonitor) Fresh() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	since := time.Since(m.lastCheckedAt)
+	if m.lastCheckedAt.IsZero() || m.lastError != nil || since > 2*m.interval {
+		return false
+	}
+	return m.lag+since <= m.maxStaleness
+}
+
+// Status returns a snapshot of the monitor
+func (m *ReplicaMonitor) Status() ReplicaStatus {
+	fresh := m.Fresh()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := ReplicaStatus{
+		Fresh:        fresh,
+		LagSeconds:   m.lag.Seconds(),
+		MaxStaleness: m.maxStaleness.String(),
+	}
+	if m.lastError != nil {
+		status.LastError = m.lastError.Error()
+	}
+	if !m.lastCheckedAt.IsZero() {
+		checked := m.lastCheckedAt
+		status.LastCheckedAt = &checked
+	}
+	return status
+}
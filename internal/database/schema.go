@@ -0,0 +1,208 @@
+package database
+
+import (
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Modes of the startup schema check
+const (
+	SchemaCheckOff    = "off"
+	SchemaCheckWarn   = "warn"   // Log drift and start anyway
+	SchemaCheckStrict = "strict" // Refuse to start on drift
+)
+
+// migrationSQL is the migration the live schema is expected to match
+//
+//go:embed migrations_v2.sql
+var migrationSQL string
+
+var (
+	sqlCommentPattern  = regexp.MustCompile(`--[^\n]*`)
+	createTablePattern = regexp.MustCompile(`(?is)CREATE TABLE (?:IF NOT EXISTS )?(\w+)\s*\((.*?)\n\)(?: PARTITION BY [^;]*)?;`)
+	createIndexPattern = regexp.MustCompile(`(?i)CREATE (?:UNIQUE )?INDEX (?:IF NOT EXISTS )?(\w+)`)
+)
+
+// tableConstraints start the lines of a CREATE TABLE that are constraints rather than columns
+var tableConstraints = []string{"PRIMARY", "UNIQUE", "CHECK", "FOREIGN", "CONSTRAINT", "EXCLUDE"}
+
+// Schema is the tables, with their columns, and indexes of a database
+type Schema struct {
+	Tables  map[string][]string
+	Indexes []string
+}
+
+// ExpectedSchema reads the schema the migration creates
+func ExpectedSchema() *Schema {
+	source := sqlCommentPattern.ReplaceAllString(migrationSQL, "")
+	schema := &Schema{Tables: make(map[string][]string)}
+
+	for _, match := range createTablePattern.FindAllStringSubmatch(source, -1) {
+		table := strings.ToLower(match[1])
+		for _, definition := range splitDefinitions(match[2]) {
+			name := strings.Fields(definition)[0]
+			isConstraint := false
+			for _, keyword := range tableConstraints {
+				isConstraint = isConstraint || strings.EqualFold(name, keyword)
+			}
+			if !isConstraint {
+				schema.Tables[table] = append(schema.Tables[table], strings.ToLower(name))
+			}
+		}
+	}
+	for _, match := range createIndexPattern.FindAllStringSubmatch(source, -1) {
+		schema.Indexes = append(schema.Indexes, strings.ToLower(match[1]))
+	}
+
+	return schema
+}
+
+// splitDefinitions splits the body of a CREATE TABLE at the commas between definitions,
+// leaving those inside parentheses such as CHECK (...) or DECIMAL(10,2)
+func splitDefinitions(body string) []string {
+	var definitions []string
+	depth, start := 0, 0
+	flush := func(end int) {
+		if definition := strings.TrimSpace(body[start:end]); definition != "" {
+			definitions = append(definitions, definition)
+		}
+	}
+	for i, r := range body {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				flush(i)
+				start = i + 1
+			}
+		}
+	}
+	flush(len(body))
+	return definitions
+}
+
+// LiveSchema reads the tables, columns and indexes of the connected database's current schema
+func LiveSchema(db *sql.DB) (*Schema, error) {
+	schema := &Schema{Tables: make(map[string][]string)}
+
+	rows, err := db.Query(`SELECT table_name, column_name FROM information_schema.columns WHERE table_schema = current_schema()`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, fmt.Errorf("failed to scan column: %w", err)
+		}
+		schema.Tables[table] = append(schema.Tables[table], column)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read columns: %w", err)
+	}
+
+	indexRows, err := db.Query(`SELECT indexname FROM pg_indexes WHERE schemaname = current_schema()`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+	defer indexRows.Close()
+	for indexRows.Next() {
+		var index string
+		if err := indexRows.Scan(&index); err != nil {
+			return nil, fmt.Errorf("failed to scan index: %w", err)
+		}
+		schema.Indexes = append(schema.Indexes, index)
+	}
+	if err := indexRows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read indexes: %w", err)
+	}
+
+	return schema, nil
+}
+
+// Drift lists what the expected schema has and the live one lacks: missing tables, columns
+// of existing tables and indexes. Objects only the live schema has are not drift, since
+// operators may add their own.
+func Drift(expected, live *Schema) []string {
+	var drift []string
+
+	tables := make([]string, 0, len(expected.Tables))
+	for table := range expected.Tables {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	for _, table := range tables {
+		liveColumns, ok := live.Tables[table]
+		if !ok {
+			drift = append(drift, "missing table "+table)
+			continue
+		}
+		have := make(map[string]bool, len(liveColumns))
+		for _, column := range liveColumns {
+			have[column] = true
+		}
+		for _, column := range expected.Tables[table] {
+			if !have[column] {
+				drift = append(drift, "missing column "+table+"."+column)
+			}
+		}
+	}
+
+	haveIndexes := make(map[string]bool, len(live.Indexes))
+	for _, index := range live.Indexes {
+		haveIndexes[index] = true
+	}
+	for _, index := range expected.Indexes {
+		if !haveIndexes[index] {
+			drift = append(drift, "missing index "+index)
+		}
+	}
+
+	return drift
+}
+
+// VerifySchema compares the live schema with the migration. Drift is logged; in strict
+// mode it is also returned as an error, so that the server refuses to start instead of
+// failing requests with SQL errors about missing columns.
+func VerifySchema(db *sql.DB, mode string) error {
+	if mode == SchemaCheckOff {
+		return nil
+	}
+
+	live, err := LiveSchema(db)
+	if err != nil {
+		return fmt.Errorf("failed to check schema: %w", err)
+	}
+	drift := Drift(ExpectedSchema(), live)
+	if len(drift) == 0 {
+		log.Println("Database schema matches the migrations")
+		return nil
+	}
+
+	for _, problem := range drift {
+		log.Printf("Schema drift: %s", problem)
+	}
+	if mode == SchemaCheckStrict {
+		return fmt.Errorf("database schema differs from the migrations in %d places; run the migrations or set DB_SCHEMA_CHECK=warn", len(drift))
+	}
+	log.Printf("Database schema differs from the migrations in %d places; run the migrations to avoid SQL errors", len(drift))
+	return nil
+}
+
+// Migrate runs the migration on db. It drops every table first, so it is only meant for
+// new or throwaway databases, such as those of the integration tests.
+func Migrate(db *sql.DB) error {
+	if _, err := db.Exec(migrationSQL); err != nil {
+		return fmt.Errorf("failed to run migrations: %w", err)
+	}
+	return nil
+}
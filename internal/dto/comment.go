@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// CommentResponse is the API representation of a comment, with nested replies in threaded views
+type CommentResponse struct {
+	ID              uuid.UUID          `json:"id"`
+	PostID          uuid.UUID          `json:"post_id"`
+	ParentCommentID *uuid.UUID         `json:"parent_comment_id"`
+	Depth           int                `json:"depth"`
+	Content         string             `json:"content"`
+	Author          *AuthorResponse    `json:"author,omitempty"`
+	ReplyCount      int                `json:"reply_count"`
+	Reactions       map[string]int     `json:"reactions"`
+	Replies         []*CommentResponse `json:"replies,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at"`
+}
+
+// NewCommentResponse maps a comment entity and its nested replies to their API representation
+func NewCommentResponse(comment *models.Comment) *CommentResponse {
+	if comment == nil {
+		return nil
+	}
+	response := &CommentResponse{
+		ID:              comment.ID,
+		PostID:          comment.PostID,
+		ParentCommentID: comment.ParentID,
+		Depth:           comment.Depth,
+		Content:         comment.Content,
+		Author:          NewAuthorResponse(comment.Author),
+		ReplyCount:      comment.ReplyCount,
+		Reactions:       reactionCounts(comment.Reactions),
+		CreatedAt:       comment.CreatedAt,
+		UpdatedAt:       comment.UpdatedAt,
+	}
+	if len(comment.Replies) > 0 {
+		response.Replies = NewCommentResponses(comment.Replies)
+	}
+	return response
+}
+
+// NewCommentResponses maps comment entities to their API representation
+func NewCommentResponses(comments []*models.Comment) []*CommentResponse {
+	responses := make([]*CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		responses = append(responses, NewCommentResponse(comment))
+	}
+	return responses
+}
@@ -0,0 +1,189 @@
+package dto
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/links"
+	"go-backend-api/internal/pkg/localtime"
+	"go-backend-api/internal/pkg/shortid"
+
+	"github.com/google/uuid"
+)
+
+// PostResponse is the API representation of a post
+type PostResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	ShortID     string          `json:"short_id"` // Resolved by GET /p/:shortID once published
+	Title       string          `json:"title"`
+	Content     string          `json:"content"`
+	Excerpt     string          `json:"excerpt"`
+	ReadingTime int             `json:"reading_time_minutes"`
+	Format      string          `json:"content_format"`
+	Sanitized   bool            `json:"content_sanitized"` // Unsafe HTML was removed from the submitted content
+	Language    string          `json:"language"`          // Detected ISO 639-1 code, empty when undetermined
+	AuthorID    *uuid.UUID      `json:"author_id"`         // null for posts of deleted authors
+	Author      *AuthorResponse `json:"author,omitempty"`
+	IsPublished bool            `json:"is_published"`
+	Visibility  string          `json:"visibility"`             // public, unlisted, followers or private
+	PublishedAt *time.Time      `json:"published_at,omitempty"` // When the post was last published
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"`
+	ScheduledAt *time.Time      `json:"scheduled_at,omitempty"` // When the unpublished post is published automatically
+	Reactions   map[string]int  `json:"reactions"`
+	LockedBy    *uuid.UUID      `json:"locked_by,omitempty"`       // Holder of the editing lock, shown to the author
+	LockExpires *time.Time      `json:"lock_expires_at,omitempty"` // When the editing lock lapses without a heartbeat
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Links       links.Links     `json:"_links,omitempty"` // Only when the client prefers links
+}
+
+// PostSummaryResponse is the API representation of a post in listings.
+// It carries the excerpt instead of the full content.
+type PostSummaryResponse struct {
+	ID          uuid.UUID       `json:"id"`
+	ShortID     string          `json:"short_id"`
+	Title       string          `json:"title"`
+	Excerpt     string          `json:"excerpt"`
+	ReadingTime int             `json:"reading_time_minutes"`
+	Language    string          `json:"language"`
+	AuthorID    *uuid.UUID      `json:"author_id"` // null for posts of deleted authors
+	Author      *AuthorResponse `json:"author,omitempty"`
+	IsPublished bool            `json:"is_published"`
+	Visibility  string          `json:"visibility"`
+	PublishedAt *time.Time      `json:"published_at,omitempty"`
+	ArchivedAt  *time.Time      `json:"archived_at,omitempty"`
+	ScheduledAt *time.Time      `json:"scheduled_at,omitempty"`
+	Reactions   map[string]int  `json:"reactions,omitempty"` // Omitted when the post has no reactions
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+	Links       links.Links     `json:"_links,omitempty"`
+}
+
+// NewPostResponse maps a post entity to its API representation
+func NewPostResponse(post *models.Post) *PostResponse {
+	if post == nil {
+		return nil
+	}
+	response := &PostResponse{
+		ID:          post.ID,
+		ShortID:     shortid.Encode(post.Number),
+		Title:       post.Title,
+		Content:     post.Content,
+		Excerpt:     post.Excerpt,
+		ReadingTime: post.ReadingTime,
+		Format:      post.ContentFormat,
+		Sanitized:   post.ContentSanitized,
+		Language:    post.Language,
+		AuthorID:    postAuthorID(post),
+		Author:      NewAuthorResponse(post.Author),
+		IsPublished: post.IsPublished,
+		Visibility:  post.Visibility,
+		PublishedAt: post.PublishedAt,
+		ArchivedAt:  post.ArchivedAt,
+		ScheduledAt: post.ScheduledAt,
+		Reactions:   reactionCounts(post.Reactions),
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+	}
+	if post.Lock != nil {
+		response.LockedBy = &post.Lock.UserID
+		response.LockExpires = &post.Lock.ExpiresAt
+	}
+	return response
+}
+
+// NewPostSummaryResponse maps a post entity to its listing representation
+func NewPostSummaryResponse(post *models.Post) *PostSummaryResponse {
+	return &PostSummaryResponse{
+		ID:          post.ID,
+		ShortID:     shortid.Encode(post.Number),
+		Title:       post.Title,
+		Excerpt:     post.Excerpt,
+		ReadingTime: post.ReadingTime,
+		Language:    post.Language,
+		AuthorID:    postAuthorID(post),
+		Author:      NewAuthorResponse(post.Author),
+		IsPublished: post.IsPublished,
+		Visibility:  post.Visibility,
+		PublishedAt: post.PublishedAt,
+		ArchivedAt:  post.ArchivedAt,
+		ScheduledAt: post.ScheduledAt,
+		Reactions:   post.Reactions,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+	}
+}
+
+// NewPostSummaryResponses maps post entities to their listing representation
+func NewPostSummaryResponses(posts []*models.Post) []*PostSummaryResponse {
+	responses := make([]*PostSummaryResponse, 0, len(posts))
+	for _, post := range posts {
+		responses = append(responses, NewPostSummaryResponse(post))
+	}
+	return responses
+}
+
+// PostSearchResponse is the API representation of a page of search results
+type PostSearchResponse struct {
+	Query string `json:"query"`
+	// DidYouMean is the query with misspelled words corrected, offered when few posts match
+	DidYouMean string                 `json:"did_you_mean,omitempty"`
+	Posts      []*PostSummaryResponse `json:"posts"`
+}
+
+// reactionCounts returns the counts of reactions by type, empty rather than nil
+func reactionCounts(counts models.ReactionCounts) map[string]int {
+	if counts == nil {
+		return map[string]int{}
+	}
+	return counts
+}
+
+// postAuthorID returns the author ID of a post, or nil once the post was anonymized
+func postAuthorID(post *models.Post) *uuid.UUID {
+	if post.AuthorID == uuid.Nil {
+		return nil
+	}
+	authorID := post.AuthorID
+	return &authorID
+}
+
+// PostScheduleResponse is the API representation of an author's post schedule, for calendars
+type PostScheduleResponse struct {
+	Timezone string                 `json:"timezone"` // IANA timezone of the author, in which the times and dates are
+	From     time.Time              `json:"from"`
+	To       time.Time              `json:"to"` // Exclusive
+	Days     []*ScheduleDayResponse `json:"days"`
+}
+
+// ScheduleDayResponse lists the posts scheduled on a calendar day of the author, soonest first
+type ScheduleDayResponse struct {
+	Date  string                 `json:"date"` // 2006-01-02 in the author's timezone
+	Posts []*PostSummaryResponse `json:"posts"`
+}
+
+// NewPostScheduleResponse maps a schedule to its API representation. Posts are the listing
+// representations of schedule.Posts, in the same order, and are grouped by the day they are
+// scheduled on, with their scheduled times shown in the author's timezone.
+func NewPostScheduleResponse(schedule *models.PostSchedule, posts []*PostSummaryResponse) *PostScheduleResponse {
+	response := &PostScheduleResponse{
+		Timezone: schedule.Location.String(),
+		From:     schedule.From,
+		To:       schedule.To,
+		Days:     []*ScheduleDayResponse{},
+	}
+	var day *ScheduleDayResponse
+	for i, post := range schedule.Posts {
+		if post.ScheduledAt == nil {
+			continue
+		}
+		local := post.ScheduledAt.In(schedule.Location)
+		posts[i].ScheduledAt = &local
+		if date := local.Format(localtime.DateLayout); day == nil || day.Date != date {
+			day = &ScheduleDayResponse{Date: date}
+			response.Days = append(response.Days, day)
+		}
+		day.Posts = append(day.Posts, posts[i])
+	}
+	return response
+}
@@ -0,0 +1,145 @@
+// Package dto defines the API representations of entities. Handlers map entities to these
+// types explicitly, so fields added to an entity are never exposed unless mapped here.
+package dto
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/links"
+
+	"github.com/google/uuid"
+)
+
+// UserResponse is the API representation of a user
+type UserResponse struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	PendingEmail *string   `json:"pending_email,omitempty"`
+	// EmailUndeliverable is bounce or complaint while mail to Email is suppressed
+	EmailUndeliverable   *string     `json:"email_undeliverable,omitempty"`
+	EmailUndeliverableAt *time.Time  `json:"email_undeliverable_at,omitempty"`
+	Role                 string      `json:"role"`
+	IsActive             bool        `json:"is_active"`
+	DeactivatedAt        *time.Time  `json:"deactivated_at,omitempty"`
+	MustChangePassword   bool        `json:"must_change_password"`
+	LastLogin            *time.Time  `json:"last_login,omitempty"`
+	LastSeenAt           *time.Time  `json:"last_seen_at,omitempty"`
+	InactivityWarnedAt   *time.Time  `json:"inactivity_warned_at,omitempty"`
+	PhoneNumber          string      `json:"phone_number,omitempty"`
+	Birthdate            string      `json:"birthdate,omitempty"` // YYYY-MM-DD
+	ParentalConsentAt    *time.Time  `json:"parental_consent_at,omitempty"`
+	CreatedAt            time.Time   `json:"created_at"`
+	UpdatedAt            time.Time   `json:"updated_at"`
+	Links                links.Links `json:"_links,omitempty"` // Only when the client prefers links
+}
+
+// AuthorResponse is the API representation of a post author
+type AuthorResponse struct {
+	ID       uuid.UUID `json:"id"`
+	Username string    `json:"username"`
+}
+
+// LoginResponse is the API representation of a successful sign-in or token refresh
+type LoginResponse struct {
+	AccessToken  string       `json:"access_token"`
+	RefreshToken string       `json:"refresh_token"`
+	TokenType    string       `json:"token_type"`
+	ExpiresIn    int          `json:"expires_in"`
+	User         UserResponse `json:"user"`
+}
+
+// NewUserResponse maps a user entity to its API representation
+func NewUserResponse(user *models.User) *UserResponse {
+	if user == nil {
+		return nil
+	}
+	return &UserResponse{
+		ID:                   user.ID,
+		Username:             user.Username,
+		Email:                user.Email,
+		PendingEmail:         user.PendingEmail,
+		EmailUndeliverable:   user.EmailUndeliverable,
+		EmailUndeliverableAt: user.EmailUndeliverableAt,
+		Role:                 user.Role,
+		IsActive:             user.IsActive,
+		DeactivatedAt:        user.DeactivatedAt,
+		MustChangePassword:   user.MustChangePassword,
+		LastLogin:            user.LastLogin,
+		LastSeenAt:           user.LastSeenAt,
+		InactivityWarnedAt:   user.InactivityWarnedAt,
+		PhoneNumber:          string(user.PhoneNumber),
+		Birthdate:            formatDate(user.Birthdate),
+		ParentalConsentAt:    user.ParentalConsentAt,
+		CreatedAt:            user.CreatedAt,
+		UpdatedAt:            user.UpdatedAt,
+	}
+}
+
+// formatDate formats an optional date as YYYY-MM-DD, or "" when unset
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02")
+}
+
+// NewUserResponses maps user entities to their API representation
+func NewUserResponses(users []*models.User) []*UserResponse {
+	responses := make([]*UserResponse, 0, len(users))
+	for _, user := range users {
+		responses = append(responses, NewUserResponse(user))
+	}
+	return responses
+}
+
+// NewAuthorResponse maps a user entity to the public author shown on posts
+func NewAuthorResponse(user *models.User) *AuthorResponse {
+	if user == nil {
+		return nil
+	}
+	return &AuthorResponse{
+		ID:       user.ID,
+		Username: user.Username,
+	}
+}
+
+// NewLoginResponse maps a sign-in result to its API representation
+func NewLoginResponse(login *models.LoginResponse) *LoginResponse {
+	if login == nil {
+		return nil
+	}
+	return &LoginResponse{
+		AccessToken:  login.AccessToken,
+		RefreshToken: login.RefreshToken,
+		TokenType:    login.TokenType,
+		ExpiresIn:    login.ExpiresIn,
+		User:         *NewUserResponse(&login.User),
+	}
+}
+
+// ProfilePageResponse is the API representation of a public profile page
+type ProfilePageResponse struct {
+	ID             uuid.UUID              `json:"id"`
+	Username       string                 `json:"username"`
+	CreatedAt      time.Time              `json:"created_at"`
+	PublishedPosts int                    `json:"published_posts"`
+	Followers      int                    `json:"followers"`
+	LatestPosts    []*PostSummaryResponse `json:"latest_posts"`
+}
+
+// NewProfilePageResponse maps a profile page to its API representation
+func NewProfilePageResponse(page *models.ProfilePage) *ProfilePageResponse {
+	if page == nil {
+		return nil
+	}
+	return &ProfilePageResponse{
+		ID:             page.Profile.ID,
+		Username:       page.Profile.Username,
+		CreatedAt:      page.Profile.CreatedAt,
+		PublishedPosts: page.PublishedPosts,
+		Followers:      page.Followers,
+		LatestPosts:    NewPostSummaryResponses(page.LatestPosts),
+	}
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event types
+const (
+	// PlanChanged is published with a PlanChange when a user moves to another plan
+	PlanChanged = "plan.changed"
+	// SubscriptionCreated, SubscriptionUpdated and SubscriptionCancelled are published with a
+	// SubscriptionChange when the billing provider reports a change to a user's subscription
+	SubscriptionCreated   = "subscription.created"
+	SubscriptionUpdated   = "subscription.updated"
+	SubscriptionCancelled = "subscription.cancelled"
+	// PostPublished is published with a PostPublication when a post goes live, whether right
+	// away, by an update or on its schedule
+	PostPublished = "post.published"
+	// CommentCreated is published with a CommentCreation when a comment is written
+	CommentCreated = "comment.created"
+)
+
+// All subscribes a handler to every event type
+const All = "*"
+
+// Event is something that happened in the domain, for consumers outside of the service
+// that caused it
+type Event struct {
+	Type       string      `json:"type"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// PlanChange is the data of a PlanChanged event
+type PlanChange struct {
+	UserID uuid.UUID `json:"user_id"`
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Source string    `json:"source"` // What changed the plan, e.g. admin or stripe
+}
+
+// SubscriptionChange is the data of the subscription events
+type SubscriptionChange struct {
+	UserID         uuid.UUID `json:"user_id"`
+	SubscriptionID string    `json:"subscription_id"`
+	CustomerID     string    `json:"customer_id"`
+	Status         string    `json:"status"`
+	PriceID        string    `json:"price_id,omitempty"`
+}
+
+// PostPublication is the data of a PostPublished event
+type PostPublication struct {
+	PostID   uuid.UUID `json:"post_id"`
+	AuthorID uuid.UUID `json:"author_id"`
+	Title    string    `json:"title"`
+}
+
+// CommentCreation is the data of a CommentCreated event
+type CommentCreation struct {
+	CommentID uuid.UUID  `json:"comment_id"`
+	PostID    uuid.UUID  `json:"post_id"`
+	AuthorID  uuid.UUID  `json:"author_id"`
+	ParentID  *uuid.UUID `json:"parent_id,omitempty"`
+}
+
+// Handler consumes events. It runs on the publishing goroutine, so slow work belongs on a
+// queue of its own.
+type Handler func(event *Event)
+
+// Publisher publishes events to their consumers
+type Publisher interface {
+	Publish(eventType string, data interface{})
+}
+
+// Bus delivers published events to the handlers subscribed to their type, in the order they
+// subscribed
+type Bus struct {
+	mutex    sync.RWMutex
+	handlers map[string][]Handler
+}
+
+// NewBus creates an event bus without subscribers
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+// Subscribe makes handler consume the events of eventType, or every event for All
+func (b *Bus) Subscribe(eventType string, handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers[eventType] = append(b.handlers[eventType], handler)
+}
+
+// Publish delivers an event to its subscribers. A handler that panics is logged and does not
+// keep the event from the others, nor fail the publisher.
+func (b *Bus) Publish(eventType string, data interface{}) {
+	event := &Event{Type: eventType, OccurredAt: time.Now().UTC(), Data: data}
+
+	b.mutex.RLock()
+	handlers := append(append([]Handler(nil), b.handlers[eventType]...), b.handlers[All]...)
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		deliver(handler, event)
+	}
+}
+
+// deliver calls handler with event, recovering from panics
+func deliver(handler Handler, event *Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+	handler(event)
+}
+
+// Discard publishes events to no one, for services wired without consumers
+var Discard Publisher = discard{}
+
+type discard struct{}
+
+func (discard) Publish(string, interface{}) {}
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"strconv"
+	"time"
+
+	"go-backend-api/internal/dto"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/metrics"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AdminHandler handles administrative requests
+type AdminHandler struct {
+	userService      models.UserService
+	postService      models.PostService
+	lifecycleService models.LifecycleService
+	hashPool         *security.HashPool
+	rateLimiter      *security.RateLimiter
+	routeMetrics     *metrics.RouteMetrics
+}
+
+// RouteMetricsReport lists the slowest or most failing routes
+type RouteMetricsReport struct {
+	Since  time.Time            `json:"since"`
+	Window int                  `json:"window"`
+	Sort   string               `json:"sort"`
+	Routes []metrics.RouteStats `json:"routes"`
+	// MediaTypes counts requests by negotiated representation, e.g. application/json or
+	// application/vnd.gobackend.v1+json
+	MediaTypes map[string]uint64 `json:"media_types"`
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(userService models.UserService, postService models.PostService, lifecycleService models.LifecycleService, hashPool *security.HashPool, rateLimiter *security.RateLimiter, routeMetrics *metrics.RouteMetrics) *AdminHandler {
+	return &AdminHandler{
+		userService:      userService,
+		postService:      postService,
+		lifecycleService: lifecycleService,
+		hashPool:         hashPool,
+		rateLimiter:      rateLimiter,
+		routeMetrics:     routeMetrics,
+	}
+}
+
+// adminLocation returns the timezone the signed-in admin chose, which CSV exports show times
+// in; NDJSON keeps the UTC timestamps of JSON responses
+func (h *AdminHandler) adminLocation(c *gin.Context) *time.Location {
+	id, ok := c.Get("user_id")
+	adminID, isUUID := id.(uuid.UUID)
+	if !ok || !isUUID {
+		return time.UTC
+	}
+	prefs, err := h.userService.GetPreferences(adminID)
+	if err != nil {
+		return time.UTC
+	}
+	return prefs.Location()
+}
+
+// userCSVHeader is the header record of user exports, matching userCSVRow
+var userCSVHeader = []string{"id", "username", "email", "email_undeliverable", "role", "is_active", "must_change_password", "last_login", "last_seen_at", "created_at", "updated_at"}
+
+// userCSVRow formats a user as a CSV record, with its times in loc
+func userCSVRow(user *dto.UserResponse, loc *time.Location) []string {
+	return []string{
+		user.ID.String(), user.Username, user.Email, csvString(user.EmailUndeliverable), user.Role, strconv.FormatBool(user.IsActive), strconv.FormatBool(user.MustChangePassword),
+		csvTime(user.LastLogin, loc), csvTime(user.LastSeenAt, loc), csvTime(&user.CreatedAt, loc), csvTime(&user.UpdatedAt, loc),
+	}
+}
+
+// postCSVHeader is the header record of post exports, matching postCSVRow
+var postCSVHeader = []string{"id", "title", "excerpt", "reading_time_minutes", "language", "author_id", "is_published", "visibility", "archived_at", "created_at", "updated_at"}
+
+// postCSVRow formats a post as a CSV record, with its times in loc
+func postCSVRow(post *dto.PostSummaryResponse, loc *time.Location) []string {
+	authorID := ""
+	if post.AuthorID != nil {
+		authorID = post.AuthorID.String()
+	}
+	return []string{
+		post.ID.String(), post.Title, post.Excerpt, strconv.Itoa(post.ReadingTime), post.Language, authorID, strconv.FormatBool(post.IsPublished),
+		post.Visibility, csvTime(post.ArchivedAt, loc), csvTime(&post.CreatedAt, loc), csvTime(&post.UpdatedAt, loc),
+	}
+}
+
+// ListUsers lists all users with pagination, or streams every user as CSV or NDJSON
+// @Summary      List users
+// @Description  List all users including last login and last seen timestamps (admin only). With format=csv, format=ndjson or Accept: application/x-ndjson every user is streamed, oldest first, ignoring pagination; the X-Export-Status trailer reports whether the export completed. CSV fields starting with =, +, -, @, a tab or a carriage return are prefixed with an apostrophe, so spreadsheets do not run them as formulas.
+// @Tags         admin
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Security     BearerAuth
+// @Param        page      query     int     false  "Page number"  default(1)
+// @Param        per_page  query     int     false  "Items per page"  default(10)
+// @Param        format    query     string  false  "json, csv or ndjson"  default(json)
+// @Success      200       {object}  response.PaginatedResponse{data=[]dto.UserResponse}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		export := newExporter(c, format, "users", userCSVHeader)
+		loc := h.adminLocation(c)
+		export.finish(h.userService.ExportUsers(func(user *models.User) error {
+			record := dto.NewUserResponse(user)
+			return export.write(record, userCSVRow(record, loc))
+		}))
+		return
+	}
+
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	users, total, err := h.userService.ListUsers(paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, userResponses(c, users), paging.meta(total))
+}
+
+// ImportUsers creates users in bulk
+// @Summary      Import users
+// @Description  Create users from a CSV file with username, email and optional role columns, or from NDJSON with one such object per line (admin only). Every created user gets a generated temporary password, returned only in this response, that must be changed on first sign-in. Rows are created in chunks, each in its own transaction. With dry_run=true rows are only validated.
+// @Tags         admin
+// @Accept       text/csv,application/x-ndjson
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        dry_run  query     bool  false  "Only validate the rows"  default(false)
+// @Success      200      {object}  response.Response{data=models.UserImportReport}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      413      {object}  response.Response
+// @Failure      415      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/users/import [post]
+func (h *AdminHandler) ImportUsers(c *gin.Context) {
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		response.Error(c, errors.NewInvalidParamError("dry_run", "dry_run must be a boolean"))
+		return
+	}
+
+	rows, ok := readUserImport(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.userService.ImportUsers(rows, dryRun)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	// The report holds temporary passwords, which must not linger in caches
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, report)
+}
+
+// ListPosts lists all posts with pagination, or streams every post as CSV or NDJSON
+// @Summary      List posts
+// @Description  List all posts, including unpublished and archived ones (admin only). With format=csv, format=ndjson or Accept: application/x-ndjson every post is streamed, oldest first, ignoring pagination; the X-Export-Status trailer reports whether the export completed. CSV fields starting with =, +, -, @, a tab or a carriage return are prefixed with an apostrophe, so spreadsheets do not run them as formulas.
+// @Tags         admin
+// @Accept       json
+// @Produce      json,text/csv,application/x-ndjson
+// @Security     BearerAuth
+// @Param        page      query     int     false  "Page number"  default(1)
+// @Param        per_page  query     int     false  "Items per page"  default(10)
+// @Param        format    query     string  false  "json, csv or ndjson"  default(json)
+// @Success      200       {object}  response.PaginatedResponse{data=[]dto.PostSummaryResponse}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /admin/posts [get]
+func (h *AdminHandler) ListPosts(c *gin.Context) {
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != "" {
+		export := newExporter(c, format, "posts", postCSVHeader)
+		loc := h.adminLocation(c)
+		export.finish(h.postService.ExportPosts(func(post *models.Post) error {
+			record := dto.NewPostSummaryResponse(post)
+			return export.write(record, postCSVRow(record, loc))
+		}))
+		return
+	}
+
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	posts, total, err := h.postService.ListAllPosts(paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, postSummaryResponses(c, posts), paging.meta(total))
+}
+
+// ForcePasswordReset requires a user to change their password
+// @Summary      Force password reset
+// @Description  Flag a user as required to change their password and revoke all of their sessions (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/users/{id}/force-password-reset [post]
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ForcePasswordReset(userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Password reset required for user", nil)
+}
+
+// ReindexSearch indexes every published post in the external search backend
+// @Summary      Reindex search
+// @Description  Index every published post in the configured OpenSearch or Elasticsearch index and remove other posts from it, e.g. after enabling the backend or when it missed updates (admin only). Returns the number of posts indexed.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=map[string]int}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /admin/search/reindex [post]
+func (h *AdminHandler) ReindexSearch(c *gin.Context) {
+	indexed, err := h.postService.ReindexSearch()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Posts reindexed", map[string]int{"indexed": indexed})
+}
+
+// RebuildFeeds rebuilds the stored feeds of fan-out-on-write
+// @Summary      Rebuild feeds
+// @Description  Replace every stored feed with the latest posts of the authors its user follows, e.g. after turning FEED_FANOUT on or when the feed worker dropped updates (admin only). Returns the number of feed entries stored.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=map[string]int64}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /admin/feed/rebuild [post]
+func (h *AdminHandler) RebuildFeeds(c *gin.Context) {
+	entries, err := h.postService.RebuildFeeds()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Feeds rebuilt", map[string]int64{"entries": entries})
+}
+
+// LifecycleReport reports what the stale account policies would do
+// @Summary      Stale account report
+// @Description  Dry-run the stale account policies and list the users that would be warned, deactivated or purged (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.LifecycleReport}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/lifecycle/report [get]
+func (h *AdminHandler) LifecycleReport(c *gin.Context) {
+	report, err := h.lifecycleService.Run(true)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// PasswordHashingStats reports password hashing pool activity
+// @Summary      Password hashing stats
+// @Description  Report concurrency, queue depth, shed requests and queue latency of password hashing (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=security.HashPoolStats}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /admin/stats/password-hashing [get]
+func (h *AdminHandler) PasswordHashingStats(c *gin.Context) {
+	response.Success(c, h.hashPool.Stats())
+}
+
+// RateLimiterStats reports rate limiter activity
+// @Summary      Rate limiter stats
+// @Description  Report the number of rate limit buckets, the bucket cap, and allowed, rejected and evicted counts (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=security.RateLimiterStats}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /admin/stats/rate-limiter [get]
+func (h *AdminHandler) RateLimiterStats(c *gin.Context) {
+	response.Success(c, h.rateLimiter.Stats())
+}
+
+// RouteMetrics reports per-route latency percentiles and error rates
+// @Summary      Route metrics
+// @Description  List routes ordered by latency percentile, error rate or request count over the most recent requests of each route (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sort   query     string  false  "Ordering"  Enums(p95, p99, p50, error_rate, requests)  default(p95)
+// @Param        limit  query     int     false  "Maximum number of routes"  default(10)
+// @Success      200    {object}  response.Response{data=RouteMetricsReport}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
+// @Router       /admin/metrics/routes [get]
+func (h *AdminHandler) RouteMetrics(c *gin.Context) {
+	sortBy, ok := queryEnum(c, "sort", metrics.SortByP95, metrics.SortKeys...)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", 10, 1, 1000)
+	if !ok {
+		return
+	}
+
+	response.Success(c, RouteMetricsReport{
+		Since:      h.routeMetrics.Since(),
+		Window:     h.routeMetrics.Window(),
+		Sort:       sortBy,
+		Routes:     h.routeMetrics.Top(sortBy, limit),
+		MediaTypes: h.routeMetrics.MediaTypes(),
+	})
+}
@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// AnnouncementHandler handles announcement banner requests
+type AnnouncementHandler struct {
+	announcementService models.AnnouncementService
+}
+
+// NewAnnouncementHandler creates a new announcement handler
+func NewAnnouncementHandler(announcementService models.AnnouncementService) *AnnouncementHandler {
+	return &AnnouncementHandler{announcementService: announcementService}
+}
+
+// ListActive lists the announcements to show the current user
+// @Summary      List announcements
+// @Description  List the running announcements, such as maintenance notices or feature news, whose audience includes the authenticated user's role and plan and that they have not dismissed; critical ones first, then warnings, then info
+// @Tags         announcements
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.Announcement}
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /announcements [get]
+func (h *AnnouncementHandler) ListActive(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	announcements, err := h.announcementService.ListActive(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, announcements)
+}
+
+// Dismiss stops showing an announcement to the current user
+// @Summary      Dismiss announcement
+// @Description  Stop showing a running announcement to the authenticated user on any of their devices; dismissing it again succeeds. Pinned announcements, which are not dismissible, cannot be dismissed.
+// @Tags         announcements
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Announcement ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /announcements/{id}/dismiss [post]
+func (h *AnnouncementHandler) Dismiss(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.announcementService.Dismiss(id, userUUID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Announcement dismissed", nil)
+}
+
+// Create creates an announcement
+// @Summary      Create announcement
+// @Description  Create an announcement banner shown from starts_at (default now) until ends_at (default until deleted) to the users with one of the roles and one of the plans; empty lists allow anyone (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.AnnouncementRequest  true  "Announcement"
+// @Success      201      {object}  response.Response{data=models.Announcement}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/announcements [post]
+func (h *AnnouncementHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	announcement, err := h.announcementService.CreateAnnouncement(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, announcement)
+}
+
+// List lists all announcements with pagination
+// @Summary      List all announcements
+// @Description  List all announcements, including ones that have ended or not started yet, the latest to start first (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"  default(1)
+// @Param        per_page  query     int  false  "Items per page"  default(10)
+// @Success      200       {object}  response.PaginatedResponse{data=[]models.Announcement}
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /admin/announcements [get]
+func (h *AnnouncementHandler) List(c *gin.Context) {
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	announcements, total, err := h.announcementService.ListAnnouncements(paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, announcements, paging.meta(total))
+}
+
+// Update replaces an announcement
+// @Summary      Update announcement
+// @Description  Replace an announcement; it keeps its start time when starts_at is left out. Users who dismissed it are not shown it again (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Announcement ID"
+// @Param        request  body      models.AnnouncementRequest  true  "Announcement"
+// @Success      200      {object}  response.Response{data=models.Announcement}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/announcements/{id} [put]
+func (h *AnnouncementHandler) Update(c *gin.Context) {
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	announcement, err := h.announcementService.UpdateAnnouncement(id, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, announcement)
+}
+
+// Delete deletes an announcement
+// @Summary      Delete announcement
+// @Description  Delete an announcement and stop showing it; to keep it listed for the record, end it instead (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id           path      string  true   "Announcement ID"
+// @Success      200          {object}  response.Response
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/announcements/{id} [delete]
+func (h *AnnouncementHandler) Delete(c *gin.Context) {
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.announcementService.DeleteAnnouncement(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Announcement deleted successfully", nil)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// APIKeyHandler handles API key requests
+type APIKeyHandler struct {
+	apiKeyService models.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new API key handler
+func NewAPIKeyHandler(apiKeyService models.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create creates an API key for the current user
+// @Summary      Create API key
+// @Description  Create a scoped API key for the authenticated user. The key may only carry scopes the caller holds. The key is only returned once.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.CreateAPIKeyRequest  true  "API key data"
+// @Success      201      {object}  response.Response{data=models.APIKey}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/api-keys [post]
+func (h *APIKeyHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	scopes, _ := c.Get("scopes")
+	grantedScopes, _ := scopes.([]string)
+
+	apiKey, err := h.apiKeyService.CreateAPIKey(userUUID, grantedScopes, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, apiKey)
+}
+
+// List lists the current user's API keys
+// @Summary      List API keys
+// @Description  List the API keys of the authenticated user
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.APIKey}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/api-keys [get]
+func (h *APIKeyHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	keys, err := h.apiKeyService.ListAPIKeys(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, keys)
+}
+
+// Revoke revokes one of the current user's API keys
+// @Summary      Revoke API key
+// @Description  Revoke an API key of the authenticated user so it can no longer be used
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "API key ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.apiKeyService.RevokeAPIKey(id, userUUID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "API key revoked successfully", nil)
+}
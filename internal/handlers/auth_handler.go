@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"net/http"
+
+	"go-backend-api/internal/dto"
 	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
 	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,13 +17,15 @@ import (
 type AuthHandler struct {
 	userService models.UserService
 	jwtManager  *auth.JWTManager
+	captcha     *security.CaptchaGuard
 }
 
-// NewAuthHandler creates a new auth handler
-func NewAuthHandler(userService models.UserService, jwtManager *auth.JWTManager) *AuthHandler {
+// NewAuthHandler creates a new auth handler. A nil captcha guard disables CAPTCHA verification.
+func NewAuthHandler(userService models.UserService, jwtManager *auth.JWTManager, captcha *security.CaptchaGuard) *AuthHandler {
 	return &AuthHandler{
 		userService: userService,
 		jwtManager:  jwtManager,
+		captcha:     captcha,
 	}
 }
 
@@ -29,8 +36,9 @@ func NewAuthHandler(userService models.UserService, jwtManager *auth.JWTManager)
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.CreateUserRequest  true  "User registration data"
-// @Success      201      {object}  response.Response{data=models.User}
+// @Success      201      {object}  response.Response{data=dto.UserResponse}
 // @Failure      400      {object}  response.Response
+// @Failure      403      {object}  response.Response
 // @Failure      500      {object}  response.Response
 // @Router       /auth/register [post]
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -40,13 +48,20 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	clientIP := c.ClientIP()
+	if err := h.captcha.Check(req.CaptchaToken, clientIP); err != nil {
+		response.Error(c, err)
+		return
+	}
+
 	user, err := h.userService.CreateUser(&req)
 	if err != nil {
+		h.recordFailure(clientIP, err)
 		response.Error(c, err)
 		return
 	}
 
-	response.Created(c, user)
+	response.Created(c, userResponse(c, user))
 }
 
 // Login handles user login
@@ -56,9 +71,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.LoginRequest  true  "Login credentials"
-// @Success      200      {object}  response.Response{data=models.LoginResponse}
+// @Success      200      {object}  response.Response{data=dto.LoginResponse}
+// @Success      202      {object}  response.Response{data=models.StepUpChallenge}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
 // @Failure      500      {object}  response.Response
 // @Router       /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
@@ -68,13 +85,108 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	loginResp, err := h.userService.AuthenticateUser(&req)
+	clientIP := c.ClientIP()
+	if err := h.captcha.Check(req.CaptchaToken, clientIP); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	req.Client = clientInfo(c)
+	loginResp, challenge, err := h.userService.AuthenticateUser(&req)
 	if err != nil {
+		h.recordFailure(clientIP, err)
 		response.Error(c, err)
 		return
 	}
 
-	response.Success(c, loginResp)
+	// Failures are not forgotten on success, so signing in to an account of their own between
+	// guesses does not spare a client the CAPTCHA
+	if challenge != nil {
+		response.Accepted(c, "Verification code sent to your email", challenge)
+		return
+	}
+
+	response.Success(c, dto.NewLoginResponse(loginResp))
+}
+
+// VerifyLogin completes a sign-in that required step-up verification
+// @Summary      Verify sign-in
+// @Description  Complete a risky sign-in with the one-time code emailed to the user
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.VerifyLoginRequest  true  "Challenge and code"
+// @Success      200      {object}  response.Response{data=dto.LoginResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/login/verify [post]
+func (h *AuthHandler) VerifyLogin(c *gin.Context) {
+	var req models.VerifyLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	req.Client = clientInfo(c)
+	loginResp, err := h.userService.VerifyLogin(&req)
+	if err != nil {
+		h.recordFailure(req.Client.IPAddress, err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, dto.NewLoginResponse(loginResp))
+}
+
+// BreakGlassLogin signs an admin in with a break-glass credential
+// @Summary      Break-glass sign-in
+// @Description  Emergency sign-in for admins with a pre-provisioned break-glass credential, only while an operator has enabled it on the host with cmd/breakglass. Step-up verification and CAPTCHA are skipped; every attempt is audited and logged, and successful ones raise the break-glass alert. The sessions it opens are revoked when the account expires or is disabled.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.BreakGlassLoginRequest  true  "Email and break-glass credential"
+// @Success      200      {object}  response.Response{data=dto.LoginResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/break-glass [post]
+func (h *AuthHandler) BreakGlassLogin(c *gin.Context) {
+	var req models.BreakGlassLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	req.Client = clientInfo(c)
+	loginResp, err := h.userService.AuthenticateBreakGlass(&req)
+	if err != nil {
+		h.recordFailure(req.Client.IPAddress, err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, dto.NewLoginResponse(loginResp))
+}
+
+// clientInfo extracts the client details recorded with security events
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress:   c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+		DeviceID:    security.DeviceID(c.Request),
+		Fingerprint: security.DeviceFingerprint(c.Request),
+	}
+}
+
+// recordFailure counts a failed attempt towards requiring a CAPTCHA from the client.
+// Server-side errors are not the client's fault and are not counted.
+func (h *AuthHandler) recordFailure(clientIP string, err error) {
+	if appErr, ok := err.(*errors.AppError); ok && appErr.Code >= http.StatusInternalServerError {
+		return
+	}
+	h.captcha.RecordFailure(clientIP)
 }
 
 // Refresh handles refresh token requests
@@ -84,7 +196,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Param        request  body      models.RefreshTokenRequest  true  "Refresh token"
-// @Success      200      {object}  response.Response{data=models.LoginResponse}
+// @Success      200      {object}  response.Response{data=dto.LoginResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
 // @Failure      500      {object}  response.Response
@@ -96,11 +208,135 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
+	req.Client = clientInfo(c)
 	loginResp, err := h.userService.RefreshToken(&req)
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.Success(c, loginResp)
+	response.Success(c, dto.NewLoginResponse(loginResp))
+}
+
+// ConfirmEmail confirms a pending email change
+// @Summary      Confirm email change
+// @Description  Apply a pending email change using the token sent to the new address
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                    false  "Confirmation token (alternative to body)"
+// @Param        request  body      models.EmailTokenRequest  false  "Confirmation token"
+// @Success      200      {object}  response.Response{data=dto.UserResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/email/confirm [post]
+func (h *AuthHandler) ConfirmEmail(c *gin.Context) {
+	token, ok := bindEmailToken(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.ConfirmEmailChange(token)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Email address updated successfully", userResponse(c, user))
+}
+
+// UndoEmail cancels or reverts an email change
+// @Summary      Undo email change
+// @Description  Cancel a pending email change, or revert a confirmed one, using the token sent to the old address
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                    false  "Undo token (alternative to body)"
+// @Param        request  body      models.EmailTokenRequest  false  "Undo token"
+// @Success      200      {object}  response.Response{data=dto.UserResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/email/undo [post]
+func (h *AuthHandler) UndoEmail(c *gin.Context) {
+	token, ok := bindEmailToken(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.UndoEmailChange(token)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Email change reverted successfully", userResponse(c, user))
+}
+
+// ConfirmParentalConsent records a parent's consent to an account
+// @Summary      Confirm parental consent
+// @Description  Lift the restrictions of an account under the parental consent age using the token emailed to the parent
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                    false  "Consent token (alternative to body)"
+// @Param        request  body      models.EmailTokenRequest  false  "Consent token"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/parental-consent/confirm [post]
+func (h *AuthHandler) ConfirmParentalConsent(c *gin.Context) {
+	token, ok := bindEmailToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.ConfirmParentalConsent(token, clientInfo(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Parental consent confirmed", nil)
+}
+
+// PasswordStrength estimates the strength of a password for live feedback
+// @Summary      Estimate password strength
+// @Description  Estimate how guessable a password is, with feedback, and whether it meets the registration policy. Nothing is stored.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.PasswordStrengthRequest  true  "Password to estimate"
+// @Success      200      {object}  response.Response{data=models.PasswordStrengthResponse}
+// @Failure      400      {object}  response.Response
+// @Router       /auth/password-strength [post]
+func (h *AuthHandler) PasswordStrength(c *gin.Context) {
+	var req models.PasswordStrengthRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	strength, err := h.userService.CheckPasswordStrength(&req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, strength)
+}
+
+// bindEmailToken reads an email token from the query string (email links) or the JSON body
+func bindEmailToken(c *gin.Context) (string, bool) {
+	if token := c.Query("token"); token != "" {
+		return token, true
+	}
+
+	var req models.EmailTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Token == "" {
+		response.BadRequest(c, "Token is required")
+		return "", false
+	}
+
+	return req.Token, true
 }
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// authorStatsSortColumns are the sort keys accepted by the admin author stats listing
+var authorStatsSortColumns = security.IdentifierAllowlist{
+	"posts":           "s.posts",
+	"published_posts": "s.published_posts",
+	"views":           "s.views",
+	"likes":           "s.likes",
+	"reactions":       "s.reactions",
+	"followers":       "s.followers",
+	"follower_growth": "s.follower_growth",
+}
+
+// AuthorStatsHandler handles author statistics requests
+type AuthorStatsHandler struct {
+	authorStatsService models.AuthorStatsService
+}
+
+// NewAuthorStatsHandler creates a new author stats handler
+func NewAuthorStatsHandler(authorStatsService models.AuthorStatsService) *AuthorStatsHandler {
+	return &AuthorStatsHandler{authorStatsService: authorStatsService}
+}
+
+// Mine returns the daily stats of the current user
+// @Summary      My author stats
+// @Description  Get the daily snapshots of the current user's posts, views, likes and follower growth, newest first. Snapshots are computed by a nightly job.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        days  query     int  false  "Days to cover, including today"  default(30)
+// @Success      200   {object}  response.Response{data=[]models.AuthorStats}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /users/stats [get]
+func (h *AuthorStatsHandler) Mine(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	id, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	days, ok := queryInt(c, "days", 30, 1, 366)
+	if !ok {
+		return
+	}
+
+	snapshots, err := h.authorStatsService.GetForUser(id, days)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, snapshots)
+}
+
+// List returns the latest stats of every author
+// @Summary      Author stats
+// @Description  List the latest daily snapshot of every author with posts or followers (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        sort      query     string  false  "posts, published_posts, views, likes, reactions, followers or follower_growth, prefixed with - for descending"  default(-views)
+// @Param        page      query     int     false  "Page number"  default(1)
+// @Param        per_page  query     int     false  "Items per page"  default(10)
+// @Success      200       {object}  response.PaginatedResponse{data=[]models.AuthorStats}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /admin/stats/authors [get]
+func (h *AuthorStatsHandler) List(c *gin.Context) {
+	orderBy, ok := sortParam(c, authorStatsSortColumns, "-views")
+	if !ok {
+		return
+	}
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	snapshots, total, err := h.authorStatsService.ListAuthors(orderBy, paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, snapshots, paging.meta(total))
+}
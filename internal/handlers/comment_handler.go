@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"go-backend-api/internal/dto"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Comment listing views
+const (
+	commentViewFlat     = "flat"
+	commentViewThreaded = "threaded"
+)
+
+// Nested replies shown per comment in threaded views
+const (
+	defaultRepliesPerBranch = 3
+	maxRepliesPerBranch     = 10
+)
+
+// CommentHandler handles comment requests
+type CommentHandler struct {
+	commentService models.CommentService
+}
+
+// NewCommentHandler creates a new comment handler
+func NewCommentHandler(commentService models.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// Create comments on a post
+// @Summary      Comment on a post
+// @Description  Comment on a post, or reply to one of its comments with parent_comment_id. Replies are limited to COMMENT_MAX_DEPTH levels, and @username mentions notify the mentioned users by email.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                       true  "Post ID"
+// @Param        request  body      models.CreateCommentRequest  true  "Comment data"
+// @Success      201      {object}  response.Response{data=dto.CommentResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /posts/{id}/comments [post]
+func (h *CommentHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(postID, userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, dto.NewCommentResponse(comment))
+}
+
+// List lists the comments of a post
+// @Summary      List comments of a post
+// @Description  List comments oldest first. The flat view pages through every comment; the threaded view pages through top-level comments with up to `replies` of the earliest replies nested per comment at every level. reply_count tells whether a branch has more replies, loaded with the replies endpoint.
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id        path      string  true   "Post ID"
+// @Param        view      query     string  false  "flat or threaded"  default(flat)
+// @Param        page      query     int     false  "Page number"  default(1)
+// @Param        per_page  query     int     false  "Items per page"  default(10)
+// @Param        replies   query     int     false  "Nested replies per comment (threaded view)"  default(3)
+// @Success      200       {object}  response.PaginatedResponse{data=[]dto.CommentResponse}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      404       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /posts/{id}/comments [get]
+func (h *CommentHandler) List(c *gin.Context) {
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+	view, ok := queryEnum(c, "view", commentViewFlat, commentViewFlat, commentViewThreaded)
+	if !ok {
+		return
+	}
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+	replies, ok := queryInt(c, "replies", defaultRepliesPerBranch, 0, maxRepliesPerBranch)
+	if !ok {
+		return
+	}
+
+	var comments []*models.Comment
+	var total int
+	var err error
+
+	if view == commentViewThreaded {
+		comments, total, err = h.commentService.GetThreads(postID, viewerID(c), paging.Page, paging.PerPage, replies)
+	} else {
+		comments, total, err = h.commentService.GetComments(postID, viewerID(c), paging.Page, paging.PerPage)
+	}
+
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, dto.NewCommentResponses(comments), paging.meta(total))
+}
+
+// Replies lists the replies to a comment
+// @Summary      List replies to a comment
+// @Description  Page through the direct replies of a comment, each with up to `replies` of its earliest replies nested, to expand one branch of a threaded view
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true   "Post ID"
+// @Param        comment_id  path      string  true   "Comment ID"
+// @Param        page        query     int     false  "Page number"  default(1)
+// @Param        per_page    query     int     false  "Items per page"  default(10)
+// @Param        replies     query     int     false  "Nested replies per reply"  default(3)
+// @Success      200         {object}  response.PaginatedResponse{data=[]dto.CommentResponse}
+// @Failure      400         {object}  response.Response
+// @Failure      401         {object}  response.Response
+// @Failure      404         {object}  response.Response
+// @Failure      500         {object}  response.Response
+// @Router       /posts/{id}/comments/{comment_id}/replies [get]
+func (h *CommentHandler) Replies(c *gin.Context) {
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+	commentID, ok := pathUUID(c, "comment_id")
+	if !ok {
+		return
+	}
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+	replies, ok := queryInt(c, "replies", defaultRepliesPerBranch, 0, maxRepliesPerBranch)
+	if !ok {
+		return
+	}
+
+	comments, total, err := h.commentService.GetReplies(postID, commentID, viewerID(c), paging.Page, paging.PerPage, replies)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, dto.NewCommentResponses(comments), paging.meta(total))
+}
@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"database/sql"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"strconv"
+	"time"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DebugHandler serves runtime diagnostics: pprof profiles, expvar and snapshots of
+// goroutines and the database pool. Its routes must only be mounted behind admin checks.
+type DebugHandler struct {
+	db *sql.DB
+}
+
+// GoroutineStats is a snapshot of the goroutines of the process
+type GoroutineStats struct {
+	Count      int `json:"count"`
+	GOMAXPROCS int `json:"gomaxprocs"`
+	NumCPU     int `json:"num_cpu"`
+}
+
+// DBPoolStats is a snapshot of the database connection pool
+type DBPoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationMs     float64 `json:"wait_duration_ms"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// NewDebugHandler creates a new debug handler
+func NewDebugHandler(db *sql.DB) *DebugHandler {
+	return &DebugHandler{db: db}
+}
+
+// Pprof serves net/http/pprof. The route must be mounted as /debug/pprof/*profile,
+// since the pprof index resolves profile names relative to /debug/pprof/.
+func (h *DebugHandler) Pprof(c *gin.Context) {
+	switch c.Param("profile") {
+	case "/cmdline":
+		pprof.Cmdline(c.Writer, c.Request)
+	case "/profile":
+		pprof.Profile(c.Writer, c.Request)
+	case "/symbol":
+		pprof.Symbol(c.Writer, c.Request)
+	case "/trace":
+		pprof.Trace(c.Writer, c.Request)
+	default:
+		pprof.Index(c.Writer, c.Request)
+	}
+}
+
+// Vars serves the expvar variables, including memstats and cmdline
+func (h *DebugHandler) Vars(c *gin.Context) {
+	expvar.Handler().ServeHTTP(c.Writer, c.Request)
+}
+
+// Goroutines reports the goroutine count
+// @Summary      Goroutine snapshot
+// @Description  Report the number of goroutines, or dump every goroutine stack as text with stacks=true (admin only, requires DEBUG_ENDPOINTS_ENABLED)
+// @Tags         debug
+// @Produce      json
+// @Produce      plain
+// @Security     BearerAuth
+// @Param        stacks  query     bool  false  "Dump goroutine stacks instead"
+// @Success      200     {object}  response.Response{data=GoroutineStats}
+// @Failure      400     {object}  response.Response
+// @Failure      401     {object}  response.Response
+// @Failure      403     {object}  response.Response
+// @Router       /debug/goroutines [get]
+func (h *DebugHandler) Goroutines(c *gin.Context) {
+	stacks, err := strconv.ParseBool(c.DefaultQuery("stacks", "false"))
+	if err != nil {
+		response.Error(c, errors.NewInvalidParamError("stacks", "stacks must be a boolean"))
+		return
+	}
+
+	if stacks {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		_ = runtimepprof.Lookup("goroutine").WriteTo(c.Writer, 2)
+		return
+	}
+
+	response.Success(c, GoroutineStats{
+		Count:      runtime.NumGoroutine(),
+		GOMAXPROCS: runtime.GOMAXPROCS(0),
+		NumCPU:     runtime.NumCPU(),
+	})
+}
+
+// DBPool reports database connection pool usage
+// @Summary      Database pool snapshot
+// @Description  Report open, in-use and idle connections and how long requests waited for one (admin only, requires DEBUG_ENDPOINTS_ENABLED)
+// @Tags         debug
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=DBPoolStats}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /debug/dbpool [get]
+func (h *DebugHandler) DBPool(c *gin.Context) {
+	stats := h.db.Stats()
+
+	response.Success(c, DBPoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationMs:     float64(stats.WaitDuration) / float64(time.Millisecond),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	})
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// DigestHandler handles weekly digest requests
+type DigestHandler struct {
+	digestService models.DigestService
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(digestService models.DigestService) *DigestHandler {
+	return &DigestHandler{digestService: digestService}
+}
+
+// Get tells whether the current user receives the weekly digest
+// @Summary      Get digest settings
+// @Description  Tell whether the authenticated user opted in to the weekly digest of top posts from the authors they follow, and when it is sent next
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.DigestSettings}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/digest [get]
+func (h *DigestHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	settings, err := h.digestService.GetSettings(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, settings)
+}
+
+// Update opts the current user in to or out of the weekly digest
+// @Summary      Update digest settings
+// @Description  Opt in to or out of the weekly digest. The first digest is sent a week after opting in; opting in again keeps the schedule.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.UpdateDigestRequest  true  "Digest settings"
+// @Success      200      {object}  response.Response{data=models.DigestSettings}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/digest [put]
+func (h *DigestHandler) Update(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateDigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	settings, err := h.digestService.UpdateSettings(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, settings)
+}
+
+// Unsubscribe opts a user out of the weekly digest from the link in a digest
+// @Summary      Unsubscribe from the weekly digest
+// @Description  Opt out of the weekly digest using the token in the latest digest email
+// @Tags         public
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                    false  "Unsubscribe token (alternative to body)"
+// @Param        request  body      models.EmailTokenRequest  false  "Unsubscribe token"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /public/digest/unsubscribe [post]
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token, ok := bindEmailToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.digestService.Unsubscribe(token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Unsubscribed from the weekly digest", nil)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EmailDeliveryHandler handles requests about the deliverability of user addresses
+type EmailDeliveryHandler struct {
+	emailDeliveryService models.EmailDeliveryService
+}
+
+// NewEmailDeliveryHandler creates a new email delivery handler
+func NewEmailDeliveryHandler(emailDeliveryService models.EmailDeliveryService) *EmailDeliveryHandler {
+	return &EmailDeliveryHandler{emailDeliveryService: emailDeliveryService}
+}
+
+// ClearUndeliverable lets mail reach a user's address again
+// @Summary      Clear undeliverable email
+// @Description  Clear the bounce or complaint mark of a user's address so that emails are sent to it again (admin only), e.g. once the user fixed their mailbox. Changing the address also clears it.
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id           path      string  true   "User ID"
+// @Success      200          {object}  response.Response
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/users/{id}/email-undeliverable [delete]
+func (h *EmailDeliveryHandler) ClearUndeliverable(c *gin.Context) {
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.emailDeliveryService.ClearUndeliverable(userID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Email marked deliverable", nil)
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"io"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EmailTemplateHandler handles email template management requests
+type EmailTemplateHandler struct {
+	emailTemplateService models.EmailTemplateService
+}
+
+// NewEmailTemplateHandler creates a new email template handler
+func NewEmailTemplateHandler(emailTemplateService models.EmailTemplateService) *EmailTemplateHandler {
+	return &EmailTemplateHandler{emailTemplateService: emailTemplateService}
+}
+
+// List lists the transactional email templates
+// @Summary      List email templates
+// @Description  List the transactional email templates with their current version, 0 while the embedded default is used (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.EmailTemplateSummary}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/email-templates [get]
+func (h *EmailTemplateHandler) List(c *gin.Context) {
+	templates, err := h.emailTemplateService.List()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, templates)
+}
+
+// Get gets an email template with its default and saved versions
+// @Summary      Get email template
+// @Description  Get the current subject and body of an email template, its embedded default and every saved version, newest first (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name  path      string  true  "Template name, e.g. login_otp"
+// @Success      200   {object}  response.Response{data=models.EmailTemplate}
+// @Failure      401   {object}  response.Response
+// @Failure      403   {object}  response.Response
+// @Failure      404   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /admin/email-templates/{name} [get]
+func (h *EmailTemplateHandler) Get(c *gin.Context) {
+	tmpl, err := h.emailTemplateService.Get(c.Param("name"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, tmpl)
+}
+
+// Update saves a new version of an email template
+// @Summary      Update email template
+// @Description  Save a new version of an email template in Go text/template syntax, used by emails right away (admin only). Saving the default's content reverts the template.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                             false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        name         path      string                             true   "Template name, e.g. login_otp"
+// @Param        request      body      models.UpdateEmailTemplateRequest  true   "Subject and body"
+// @Success      200          {object}  response.Response{data=models.EmailTemplateVersion}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      409          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/email-templates/{name} [put]
+func (h *EmailTemplateHandler) Update(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	adminUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	version, err := h.emailTemplateService.Update(adminUUID, c.Param("name"), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, version)
+}
+
+// Preview renders an email template with sample data
+// @Summary      Preview email template
+// @Description  Render the current version of an email template, or the given subject and body, with sample data (admin only). Nothing is sent or saved; fields missing from data render as <no value>.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        name     path      string                             true   "Template name, e.g. login_otp"
+// @Param        request  body      models.PreviewEmailTemplateRequest  false  "Sample data and optional subject and body"
+// @Success      200      {object}  response.Response{data=models.EmailPreview}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/email-templates/{name}/preview [post]
+func (h *EmailTemplateHandler) Preview(c *gin.Context) {
+	var req models.PreviewEmailTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	preview, err := h.emailTemplateService.Preview(c.Param("name"), &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, preview)
+}
@@ -0,0 +1,200 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/middleware"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// envelopeAPIKeys authenticates the key "gba_readonly" with the posts:read scope only
+type envelopeAPIKeys struct {
+	models.APIKeyService
+}
+
+func (envelopeAPIKeys) Authenticate(key string) (*models.TokenClaims, error) {
+	if key != models.APIKeyPrefix+"readonly" {
+		return nil, errors.ErrInvalidAPIKey
+	}
+	keyID := uuid.New()
+	return &models.TokenClaims{UserID: uuid.New(), Role: models.RoleUser, APIKeyID: &keyID, Scopes: []string{models.ScopePostsRead}}, nil
+}
+
+// envelopeUsers holds a single active user; other UserRepository methods are not used
+type envelopeUsers struct {
+	models.UserRepository
+	active uuid.UUID
+}
+
+func (r envelopeUsers) GetTokenState(id uuid.UUID) (*models.UserTokenState, error) {
+	if id != r.active {
+		return nil, models.ErrNotFound
+	}
+	return &models.UserTokenState{IsActive: true}, nil
+}
+
+// envelopeClients holds no machine clients; other OAuthClientRepository methods are not used
+type envelopeClients struct {
+	models.OAuthClientRepository
+}
+
+func (envelopeClients) GetByClientID(string) (*models.OAuthClient, error) {
+	return nil, models.ErrNotFound
+}
+
+// envelopeAnnouncements refuses every dismissal with a 403 that has no reason of its own
+type envelopeAnnouncements struct {
+	models.AnnouncementService
+}
+
+func (envelopeAnnouncements) Dismiss(uuid.UUID, uuid.UUID) error {
+	return errors.NewAppError(http.StatusForbidden, "Announcements cannot be dismissed", nil)
+}
+
+// envelopeRouter mounts the middleware chain of cmd/main.go in front of routes that fail
+// at each layer
+func envelopeRouter(jwtManager *auth.JWTManager, active uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	announcements := NewAnnouncementHandler(envelopeAnnouncements{})
+
+	// Handlers reached without authentication refuse the request themselves
+	r.GET("/unauthenticated", announcements.ListActive)
+
+	protected := r.Group("/")
+	protected.Use(middleware.AuthMiddleware(jwtManager, envelopeAPIKeys{}))
+	protected.Use(middleware.TokenDenylistMiddleware(envelopeUsers{active: active}, envelopeClients{}))
+	ok := func(c *gin.Context) { response.Success(c, nil) }
+	protected.GET("/posts", middleware.RequireScope(models.ScopePostsRead), ok)
+	protected.GET("/profile", middleware.RequireScope(models.ScopeUsersRead), ok)
+	protected.GET("/admin", middleware.RequireAdmin(), ok)
+	protected.POST("/announcements/:id/dismiss", middleware.RequireScope(models.ScopeUsersWrite), announcements.Dismiss)
+
+	// RequireScope without AuthMiddleware in front
+	r.GET("/scoped", middleware.RequireScope(models.ScopeUsersRead), ok)
+	return r
+}
+
+func TestAuthFailuresShareTheErrorEnvelope(t *testing.T) {
+	jwtManager := auth.NewJWTManager("access-secret", "refresh-secret", "issuer", "audience", time.Hour, 24*time.Hour)
+	user := &models.User{ID: uuid.New(), Username: "member", Role: models.RoleUser, Plan: models.PlanFree}
+	tokens, err := jwtManager.GenerateTokenPair(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := jwtManager.GenerateTokenPair(&models.User{ID: uuid.New(), Username: "gone", Role: models.RoleUser})
+	if err != nil {
+		t.Fatal(err)
+	}
+	clientToken, err := jwtManager.GenerateClientToken("client_gone", []string{models.ScopeUsersAdmin})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := envelopeRouter(jwtManager, user.ID)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header map[string]string
+		status int
+		reason string
+	}{
+		{"no credentials", http.MethodGet, "/posts", nil, http.StatusUnauthorized, "AUTH_REQUIRED"},
+		{"not a bearer token", http.MethodGet, "/posts", map[string]string{"Authorization": "Basic dXNlcjpwYXNz"}, http.StatusUnauthorized, "AUTH_HEADER_INVALID"},
+		{"invalid token", http.MethodGet, "/posts", map[string]string{"Authorization": "Bearer not-a-jwt"}, http.StatusUnauthorized, "TOKEN_INVALID"},
+		{"refresh token", http.MethodGet, "/posts", map[string]string{"Authorization": "Bearer " + tokens.RefreshToken}, http.StatusUnauthorized, "TOKEN_INVALID"},
+		{"invalid API key", http.MethodGet, "/posts", map[string]string{middleware.APIKeyHeader: models.APIKeyPrefix + "unknown"}, http.StatusUnauthorized, "API_KEY_INVALID"},
+		{"deleted user", http.MethodGet, "/posts", map[string]string{"Authorization": "Bearer " + deleted.AccessToken}, http.StatusUnauthorized, "TOKEN_REVOKED"},
+		{"deleted client", http.MethodGet, "/admin", map[string]string{"Authorization": "Bearer " + clientToken}, http.StatusUnauthorized, "TOKEN_REVOKED"},
+		{"scope without authentication", http.MethodGet, "/scoped", nil, http.StatusUnauthorized, "AUTH_REQUIRED"},
+		{"missing scope", http.MethodGet, "/profile", map[string]string{middleware.APIKeyHeader: models.APIKeyPrefix + "readonly"}, http.StatusForbidden, "INSUFFICIENT_SCOPE"},
+		{"not an admin", http.MethodGet, "/admin", map[string]string{"Authorization": "Bearer " + tokens.AccessToken}, http.StatusForbidden, "ADMIN_REQUIRED"},
+		{"handler without a user", http.MethodGet, "/unauthenticated", nil, http.StatusUnauthorized, "UNAUTHORIZED"},
+		{"service refusal", http.MethodPost, "/announcements/" + uuid.NewString() + "/dismiss", map[string]string{"Authorization": "Bearer " + tokens.AccessToken}, http.StatusForbidden, "FORBIDDEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, envelope := range []bool{true, false} {
+				req := httptest.NewRequest(tt.method, tt.path, nil)
+				for name, value := range tt.header {
+					req.Header.Set(name, value)
+				}
+				if !envelope {
+					req.Header.Set("Prefer", response.PreferEnvelopeNone)
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				if w.Code != tt.status {
+					t.Fatalf("envelope %v: got %d, want %d: %s", envelope, w.Code, tt.status, w.Body)
+				}
+				if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+					t.Errorf("envelope %v: Content-Type %q", envelope, ct)
+				}
+				assertErrorInfo(t, w, envelope, tt.status, tt.reason)
+			}
+		})
+	}
+}
+
+// assertErrorInfo checks that w carries exactly {success: false, error: {code, reason, message}},
+// or the error object alone when the envelope is off
+func assertErrorInfo(t *testing.T, w *httptest.ResponseRecorder, envelope bool, status int, reason string) {
+	t.Helper()
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("envelope %v: body is not a JSON object: %v: %s", envelope, err, w.Body)
+	}
+	raw := w.Body.Bytes()
+	if envelope {
+		if keys := sortedKeys(body); !slices.Equal(keys, []string{"error", "success"}) {
+			t.Errorf("envelope: got keys %v, want [error success]", keys)
+		}
+		if string(body["success"]) != "false" {
+			t.Errorf("envelope: success = %s, want false", body["success"])
+		}
+		raw = body["error"]
+	}
+
+	var info map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &info); err != nil {
+		t.Fatalf("envelope %v: error is not a JSON object: %v: %s", envelope, err, raw)
+	}
+	if keys := sortedKeys(info); !slices.Equal(keys, []string{"code", "message", "reason"}) {
+		t.Errorf("envelope %v: got error keys %v, want [code message reason]", envelope, keys)
+	}
+	var decoded response.ErrorInfo
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Code != status {
+		t.Errorf("envelope %v: code = %d, want %d", envelope, decoded.Code, status)
+	}
+	if decoded.Reason != reason {
+		t.Errorf("envelope %v: reason = %q, want %q", envelope, decoded.Reason, reason)
+	}
+	if decoded.Message == "" {
+		t.Errorf("envelope %v: empty message", envelope)
+	}
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
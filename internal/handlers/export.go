@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Export formats of list endpoints that can stream every row instead of a page
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+)
+
+// ndjsonContentType is the media type of newline-delimited JSON
+const ndjsonContentType = "application/x-ndjson"
+
+// exportFlushRows is how many rows are written between flushes to the client
+const exportFlushRows = 100
+
+// exportWriteTimeout bounds each flush of an export. Every flush extends the write deadline,
+// so an export may outlast WRITE_TIMEOUT for as long as the client keeps reading.
+const exportWriteTimeout = time.Minute
+
+// exportStatusTrailer is sent after the body of an export: "complete", or "failed" if the
+// export stopped early. The status code is committed with the first row, so it cannot tell.
+const exportStatusTrailer = "X-Export-Status"
+
+// exportFormat reads the export format of a list request: csv or ndjson from the format query
+// parameter, or ndjson from an Accept: application/x-ndjson header. It returns "" for the
+// regular paginated JSON response.
+func exportFormat(c *gin.Context) (string, bool) {
+	format, ok := queryEnum(c, "format", "", "json", exportCSV, exportNDJSON)
+	if !ok {
+		return "", false
+	}
+	if format == "" && strings.Contains(c.GetHeader("Accept"), ndjsonContentType) {
+		return exportNDJSON, true
+	}
+	if format == "json" {
+		return "", true
+	}
+	return format, true
+}
+
+// exporter streams the rows of an export as CSV or NDJSON, flushing as it goes
+// so that no more than a few rows are ever buffered
+type exporter struct {
+	c          *gin.Context
+	controller *http.ResponseController
+	format     string
+	name       string
+	header     []string
+	csv        *csv.Writer
+	json       *json.Encoder
+	started    bool
+	rows       int
+}
+
+// newExporter creates an exporter writing name.csv with the given CSV header, or NDJSON
+func newExporter(c *gin.Context, format, name string, header []string) *exporter {
+	return &exporter{
+		c:          c,
+		controller: http.NewResponseController(c.Writer),
+		format:     format,
+		name:       name,
+		header:     header,
+	}
+}
+
+// write writes a row: record as a JSON line, or fields as a CSV record, neutralized with
+// csvSafe. Headers go out with the first row, so an export that fails before any row still
+// gets an error response.
+func (e *exporter) write(record interface{}, fields []string) error {
+	if !e.started {
+		if err := e.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if e.csv != nil {
+		err = e.csv.Write(csvSafe(fields))
+	} else {
+		err = e.json.Encode(record)
+	}
+	if err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+// start sends the response headers and, for CSV, the header record
+func (e *exporter) start() error {
+	e.started = true
+	header := e.c.Writer.Header()
+	header.Set("Trailer", exportStatusTrailer)
+	if e.format == exportCSV {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+		header.Set("Content-Disposition", `attachment; filename="`+e.name+`.csv"`)
+	} else {
+		header.Set("Content-Type", ndjsonContentType)
+	}
+	e.c.Status(http.StatusOK)
+
+	if err := e.extendDeadline(); err != nil {
+		return err
+	}
+	if e.format == exportCSV {
+		e.csv = csv.NewWriter(e.c.Writer)
+		return e.csv.Write(e.header)
+	}
+	e.json = json.NewEncoder(e.c.Writer)
+	return nil
+}
+
+// flush sends buffered rows to the client
+func (e *exporter) flush() error {
+	if e.csv != nil {
+		e.csv.Flush()
+		if err := e.csv.Error(); err != nil {
+			return err
+		}
+	}
+	if err := e.extendDeadline(); err != nil {
+		return err
+	}
+	return e.controller.Flush()
+}
+
+// extendDeadline gives the next flush exportWriteTimeout to complete
+func (e *exporter) extendDeadline() error {
+	err := e.controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	if err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return err
+	}
+	return nil
+}
+
+// finish completes an export whose rows were written until err. Before the first row an error
+// gets a regular error response; afterwards it can only be logged and reported in the trailer.
+func (e *exporter) finish(err error) {
+	if !e.started {
+		if err != nil {
+			response.Error(e.c, err)
+			return
+		}
+		err = e.start()
+	}
+	if err == nil {
+		err = e.flush()
+	}
+
+	status := "complete"
+	if err != nil {
+		log.Printf("Export of %s stopped after %d rows: %v", e.name, e.rows, err)
+		status = "failed"
+	}
+	e.c.Writer.Header().Set(exportStatusTrailer, status)
+}
+
+// csvFormulaPrefixes are the first characters that make spreadsheets read a field as a formula
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvSafe prefixes with a quote, in place, the fields a spreadsheet would run as formulas, so
+// that user-controlled text such as a username or post title cannot inject one into an export
+// (CSV injection). Spreadsheets show the quoted value as text.
+func csvSafe(fields []string) []string {
+	for i, field := range fields {
+		if field != "" && strings.IndexByte(csvFormulaPrefixes, field[0]) >= 0 {
+			fields[i] = "'" + field
+		}
+	}
+	return fields
+}
+
+// csvTime formats an optional timestamp for CSV in loc, empty when unset
+func csvTime(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return ""
+	}
+	return t.In(loc).Format(time.RFC3339)
+}
+
+// csvString formats an optional string, "" when unset
+func csvString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// exportUsers streams users and reports the timezone of a single admin; other UserService
+// methods are not used
+type exportUsers struct {
+	models.UserService
+	adminID  uuid.UUID
+	timezone string
+	users    []*models.User
+}
+
+func (s *exportUsers) GetPreferences(id uuid.UUID) (*models.UserPreferences, error) {
+	if id != s.adminID {
+		return nil, models.ErrNotFound
+	}
+	return &models.UserPreferences{Timezone: s.timezone}, nil
+}
+
+func (s *exportUsers) ExportUsers(fn func(*models.User) error) error {
+	for _, user := range s.users {
+		if err := fn(user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportUsersCSV exports the users as the admin and returns the records of the CSV, header first
+func exportUsersCSV(t *testing.T, svc *exportUsers, adminID uuid.UUID) [][]string {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin/users", func(c *gin.Context) {
+		c.Set("user_id", adminID)
+		c.Next()
+	}, NewAdminHandler(svc, nil, nil, nil, nil, nil).ListUsers)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/users?format=csv", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("got %d: %s", w.Code, w.Body)
+	}
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not CSV: %v: %s", err, w.Body)
+	}
+	return records
+}
+
+func TestCSVExportShowsTimesInTheAdminTimezoneAcrossDaylightSavingChanges(t *testing.T) {
+	if _, err := time.LoadLocation("America/New_York"); err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	at := func(hour, minute int, month time.Month, day int) *time.Time {
+		t := time.Date(2026, month, day, hour, minute, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name      string
+		lastLogin *time.Time
+		want      string
+	}{
+		{"before spring forward", at(6, 59, time.March, 8), "2026-03-08T01:59:00-05:00"},
+		{"after spring forward", at(7, 0, time.March, 8), "2026-03-08T03:00:00-04:00"},
+		// The same wall-clock time twice, told apart by the offset
+		{"first 01:30 of fall back", at(5, 30, time.November, 1), "2026-11-01T01:30:00-04:00"},
+		{"second 01:30 of fall back", at(6, 30, time.November, 1), "2026-11-01T01:30:00-05:00"},
+	}
+
+	adminID := uuid.New()
+	svc := &exportUsers{adminID: adminID, timezone: "America/New_York"}
+	for _, tt := range tests {
+		svc.users = append(svc.users, &models.User{ID: uuid.New(), Username: tt.name, LastLogin: tt.lastLogin, CreatedAt: *tt.lastLogin, UpdatedAt: *tt.lastLogin})
+	}
+	records := exportUsersCSV(t, svc, adminID)
+	if len(records) != len(tests)+1 {
+		t.Fatalf("got %d records, want a header and %d users", len(records), len(tests))
+	}
+	column := slices.Index(records[0], "last_login")
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := records[i+1][column]; got != tt.want {
+				t.Errorf("last_login = %q, want %q", got, tt.want)
+			}
+			exported, err := time.Parse(time.RFC3339, records[i+1][column])
+			if err != nil || !exported.Equal(*tt.lastLogin) {
+				t.Errorf("last_login %q reads back as %v, want %v", records[i+1][column], exported, tt.lastLogin)
+			}
+		})
+	}
+
+	// Admins without a timezone of their own get UTC
+	records = exportUsersCSV(t, svc, uuid.New())
+	if got := records[1][column]; got != "2026-03-08T06:59:00Z" {
+		t.Errorf("without a timezone: last_login = %q, want UTC", got)
+	}
+}
+
+func TestCSVExportNeutralizesFormulas(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	adminID := uuid.New()
+	svc := &exportUsers{adminID: adminID}
+	values := []string{`=HYPERLINK("http://evil.example","x")`, "+1+2", "-2+3", "@SUM(A1)", "\tcmd", "\rcmd", "plain", "a=b"}
+	for _, value := range values {
+		svc.users = append(svc.users, &models.User{ID: uuid.New(), Username: value, Email: value, CreatedAt: now, UpdatedAt: now})
+	}
+
+	records := exportUsersCSV(t, svc, adminID)
+	username, email := slices.Index(records[0], "username"), slices.Index(records[0], "email")
+	for i, value := range values {
+		want := value
+		if strings.ContainsAny(value[:1], "=+-@\t\r") {
+			want = "'" + value
+		}
+		for _, column := range []int{username, email} {
+			if got := records[i+1][column]; got != want {
+				t.Errorf("%s = %q, want %q", records[0][column], got, want)
+			}
+		}
+	}
+	// Other fields are left alone
+	if got := records[1][slices.Index(records[0], "created_at")]; got != "2026-10-14T09:00:00Z" {
+		t.Errorf("created_at = %q", got)
+	}
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportBytes caps the size of an import upload
+const maxImportBytes = 32 << 20
+
+// importTimeout replaces READ_TIMEOUT and WRITE_TIMEOUT for imports, which upload and
+// create tens of thousands of rows
+const importTimeout = 10 * time.Minute
+
+// userImportColumns are the CSV columns of a user import; username and email are required
+var userImportColumns = []string{"username", "email", "role"}
+
+// readUserImport reads the rows of a user import from a text/csv body, whose header names
+// the columns, or an application/x-ndjson body with one JSON object per line
+func readUserImport(c *gin.Context) ([]*models.ImportUserRow, bool) {
+	controller := http.NewResponseController(c.Writer)
+	deadline := time.Now().Add(importTimeout)
+	// Servers without deadlines (e.g. in tests) report ErrNotSupported, which is fine to ignore
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline)
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportBytes)
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+
+	var rows []*models.ImportUserRow
+	var err error
+	switch mediaType {
+	case "text/csv":
+		rows, err = readUserImportCSV(body)
+	case ndjsonContentType:
+		rows, err = readUserImportNDJSON(body)
+	default:
+		response.Error(c, errors.NewAppErrorWithReason(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "Import must be text/csv or "+ndjsonContentType))
+		return nil, false
+	}
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		response.Error(c, errors.NewAppErrorWithReason(http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", "Import is larger than "+strconv.Itoa(maxImportBytes>>20)+" MiB; split the file"))
+		return nil, false
+	}
+	if err != nil {
+		response.Error(c, errors.NewAppErrorWithReason(http.StatusBadRequest, "IMPORT_MALFORMED", "Import could not be parsed").WithDetails(err.Error()))
+		return nil, false
+	}
+	return rows, true
+}
+
+// readUserImportCSV parses a CSV user import
+func readUserImportCSV(body io.Reader) ([]*models.ImportUserRow, error) {
+	reader := csv.NewReader(body)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Spreadsheet applications may start the file with a byte order mark
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !slices.Contains(userImportColumns, name) {
+			return nil, fmt.Errorf("unknown column %q; columns are %s", name, strings.Join(userImportColumns, ", "))
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"username", "email"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing column %q", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var rows []*models.ImportUserRow
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, &models.ImportUserRow{
+			Username: field(record, "username"),
+			Email:    field(record, "email"),
+			Role:     field(record, "role"),
+		})
+	}
+}
+
+// readUserImportNDJSON parses an NDJSON user import
+func readUserImportNDJSON(body io.Reader) ([]*models.ImportUserRow, error) {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+
+	var rows []*models.ImportUserRow
+	for {
+		row := &models.ImportUserRow{}
+		if err := decoder.Decode(row); err == io.EOF {
+			return rows, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("row %d: %w", len(rows)+1, err)
+		}
+		rows = append(rows, row)
+	}
+}
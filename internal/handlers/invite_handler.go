@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// InviteHandler handles invite requests
+type InviteHandler struct {
+	inviteService models.InviteService
+}
+
+// NewInviteHandler creates a new invite handler
+func NewInviteHandler(inviteService models.InviteService) *InviteHandler {
+	return &InviteHandler{
+		inviteService: inviteService,
+	}
+}
+
+// Create creates an invite code
+// @Summary      Create invite
+// @Description  Create an invite code. Admins may create multi-use invites; other users are limited to their quota of single-use invites. The code is only returned once.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.CreateInviteRequest  true  "Invite data"
+// @Success      201      {object}  response.Response{data=models.Invite}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /invites [post]
+// @Router       /admin/invites [post]
+func (h *InviteHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.CreateInviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	isAdmin := c.GetString("role") == models.RoleAdmin
+	invite, err := h.inviteService.CreateInvite(userUUID, isAdmin, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, invite)
+}
+
+// ListMine lists the invites created by the current user
+// @Summary      List my invites
+// @Description  List the invites created by the authenticated user
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.Invite}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /invites [get]
+func (h *InviteHandler) ListMine(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	invites, err := h.inviteService.ListUserInvites(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, invites)
+}
+
+// List lists all invites with pagination
+// @Summary      List invites
+// @Description  List all invites with their usage counts (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"  default(1)
+// @Param        per_page  query     int  false  "Items per page"  default(10)
+// @Success      200       {object}  response.PaginatedResponse{data=[]models.Invite}
+// @Failure      401       {object}  response.Response
+// @Failure      403       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /admin/invites [get]
+func (h *InviteHandler) List(c *gin.Context) {
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	invites, total, err := h.inviteService.ListInvites(paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, invites, paging.meta(total))
+}
+
+// GetByID gets an invite and the registrations made with it
+// @Summary      Get invite
+// @Description  Get an invite including which users registered with it (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Invite ID"
+// @Success      200  {object}  response.Response{data=models.Invite}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/invites/{id} [get]
+func (h *InviteHandler) GetByID(c *gin.Context) {
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	invite, err := h.inviteService.GetInvite(id)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, invite)
+}
+
+// Revoke revokes an invite
+// @Summary      Revoke invite
+// @Description  Revoke an invite so it can no longer be used. Users may revoke their own invites; admins may revoke any.
+// @Tags         invites
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id   path      string  true  "Invite ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /invites/{id} [delete]
+// @Router       /admin/invites/{id} [delete]
+func (h *InviteHandler) Revoke(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	isAdmin := c.GetString("role") == models.RoleAdmin
+	if err := h.inviteService.RevokeInvite(id, userUUID, isAdmin); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Invite revoked successfully", nil)
+}
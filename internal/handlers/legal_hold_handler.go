@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// LegalHoldHandler handles legal hold requests (admin only)
+type LegalHoldHandler struct {
+	legalHoldService models.LegalHoldService
+}
+
+// NewLegalHoldHandler creates a new legal hold handler
+func NewLegalHoldHandler(legalHoldService models.LegalHoldService) *LegalHoldHandler {
+	return &LegalHoldHandler{
+		legalHoldService: legalHoldService,
+	}
+}
+
+// Place places a legal hold on a user
+// @Summary      Place legal hold
+// @Description  Place a legal hold on a user, or update its reason (admin only). Until it is cleared, the account and posts of the user cannot be deleted, by the user or by the lifecycle and retention jobs; every refused deletion is audited.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                        true  "User ID"
+// @Param        request  body      models.PlaceLegalHoldRequest  true  "Hold reason"
+// @Success      200      {object}  response.Response{data=models.LegalHold}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/users/{id}/legal-hold [put]
+func (h *LegalHoldHandler) Place(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	adminUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.PlaceLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	hold, err := h.legalHoldService.Place(adminUUID, userID, &req, clientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, hold)
+}
+
+// Get gets the legal hold of a user
+// @Summary      Get legal hold
+// @Description  Get the legal hold of a user (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response{data=models.LegalHold}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/users/{id}/legal-hold [get]
+func (h *LegalHoldHandler) Get(c *gin.Context) {
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	hold, err := h.legalHoldService.Get(userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, hold)
+}
+
+// Clear clears the legal hold of a user
+// @Summary      Clear legal hold
+// @Description  Clear the legal hold of a user, making their data deletable again (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/users/{id}/legal-hold [delete]
+func (h *LegalHoldHandler) Clear(c *gin.Context) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	adminUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.legalHoldService.Clear(adminUUID, userID, clientInfo(c)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Legal hold cleared", nil)
+}
+
+// List lists every legal hold
+// @Summary      List legal holds
+// @Description  List the users under legal hold, newest hold first (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.LegalHold}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/legal-holds [get]
+func (h *LegalHoldHandler) List(c *gin.Context) {
+	holds, err := h.legalHoldService.List()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, holds)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"go-backend-api/internal/dto"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/links"
+	"go-backend-api/internal/pkg/shortid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// postResponse maps a post to its API representation, with its links when the client asked
+// for them
+func postResponse(c *gin.Context, post *models.Post) *dto.PostResponse {
+	resp := dto.NewPostResponse(post)
+	if builder := links.FromContext(c); builder != nil && resp != nil {
+		resp.Links = postLinks(builder, post)
+	}
+	return resp
+}
+
+// postSummaryResponses maps posts to their listing representation, with their links when the
+// client asked for them
+func postSummaryResponses(c *gin.Context, posts []*models.Post) []*dto.PostSummaryResponse {
+	responses := dto.NewPostSummaryResponses(posts)
+	if builder := links.FromContext(c); builder != nil {
+		for i, post := range posts {
+			responses[i].Links = postLinks(builder, post)
+		}
+	}
+	return responses
+}
+
+// userResponse maps a user to its API representation, with its links when the client asked
+// for them
+func userResponse(c *gin.Context, user *models.User) *dto.UserResponse {
+	resp := dto.NewUserResponse(user)
+	if builder := links.FromContext(c); builder != nil && resp != nil {
+		resp.Links = userLinks(builder, user)
+	}
+	return resp
+}
+
+// userResponses maps users to their API representation, with their links when the client
+// asked for them
+func userResponses(c *gin.Context, users []*models.User) []*dto.UserResponse {
+	responses := dto.NewUserResponses(users)
+	if builder := links.FromContext(c); builder != nil {
+		for i, user := range users {
+			responses[i].Links = userLinks(builder, user)
+		}
+	}
+	return responses
+}
+
+// postLinks links a post to itself, its comments, unless it was anonymized its author and,
+// once it is published for anyone with the link, its shareable short link
+func postLinks(builder *links.Builder, post *models.Post) links.Links {
+	id := post.ID.String()
+	postLinks := links.Links{
+		"self":     {Href: builder.URL(links.RoutePost, id)},
+		"comments": {Href: builder.URL(links.RoutePostComments, id)},
+	}
+	shareable := post.Visibility == models.PostVisibilityPublic || post.Visibility == models.PostVisibilityUnlisted
+	if post.IsPublished && !post.IsArchived() && shareable && post.Number > 0 {
+		postLinks["share"] = links.Link{Href: builder.URL(links.RouteShortLink, shortid.Encode(post.Number))}
+	}
+	if post.Author != nil {
+		postLinks["author"] = links.Link{Href: builder.URL(links.RoutePublicProfile, post.Author.Username)}
+	}
+	return postLinks
+}
+
+// userLinks links a user to their public profile and their posts
+func userLinks(builder *links.Builder, user *models.User) links.Links {
+	return links.Links{
+		"self":  {Href: builder.URL(links.RoutePublicProfile, user.Username)},
+		"posts": {Href: builder.URL(links.RoutePosts) + "?author_id=" + user.ID.String()},
+	}
+}
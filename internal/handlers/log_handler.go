@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/logger"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LogHandler changes logging at runtime: the log level and SQL query logging
+type LogHandler struct {
+	logger *logger.Logger
+}
+
+// LogSettings is the current logging configuration
+type LogSettings struct {
+	Level      string `json:"level"`
+	SQLQueries bool   `json:"sql_queries"` // Statements are logged with redacted parameters
+}
+
+// UpdateLogSettingsRequest changes the settings that are set
+type UpdateLogSettingsRequest struct {
+	Level      *string `json:"level" validate:"omitempty,oneof=debug info warn error"`
+	SQLQueries *bool   `json:"sql_queries"`
+}
+
+// NewLogHandler creates a new log handler
+func NewLogHandler(logger *logger.Logger) *LogHandler {
+	return &LogHandler{logger: logger}
+}
+
+// Get reports the logging configuration
+// @Summary      Get log settings
+// @Description  Report the log level and whether SQL query logging is on (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=LogSettings}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /admin/log-level [get]
+func (h *LogHandler) Get(c *gin.Context) {
+	response.Success(c, h.settings())
+}
+
+// Update changes the log level and turns SQL query logging on or off, until the next restart
+// @Summary      Update log settings
+// @Description  Set the log level and turn SQL query logging on or off until the next restart; omitted settings are kept (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                    false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        settings     body      UpdateLogSettingsRequest  true   "Settings to change"
+// @Success      200          {object}  response.Response{data=LogSettings}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Router       /admin/log-level [put]
+func (h *LogHandler) Update(c *gin.Context) {
+	var req UpdateLogSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if req.Level != nil {
+		if err := h.logger.SetLevelName(*req.Level); err != nil {
+			response.BadRequest(c, err.Error())
+			return
+		}
+	}
+	if req.SQLQueries != nil {
+		if *req.SQLQueries {
+			database.SetQueryLogger(h.logger.Query)
+		} else {
+			database.SetQueryLogger(nil)
+		}
+	}
+
+	settings := h.settings()
+	h.logger.WithContext(c).WithField("level", settings.Level).WithField("sql_queries", settings.SQLQueries).Warn("Log settings changed")
+	response.Success(c, settings)
+}
+
+// settings reads the current logging configuration
+func (h *LogHandler) settings() *LogSettings {
+	return &LogSettings{Level: h.logger.LevelName(), SQLQueries: database.QueryLogging()}
+}
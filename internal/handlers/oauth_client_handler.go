@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// OAuthClientHandler handles machine client registration and the client credentials grant
+type OAuthClientHandler struct {
+	clientService models.OAuthClientService
+}
+
+// NewOAuthClientHandler creates a new OAuth client handler
+func NewOAuthClientHandler(clientService models.OAuthClientService) *OAuthClientHandler {
+	return &OAuthClientHandler{
+		clientService: clientService,
+	}
+}
+
+// Token issues an access token to a machine client
+// @Summary      Issue client token
+// @Description  Exchange client credentials for a scoped access token (grant_type=client_credentials). Credentials may be sent in the body or with HTTP Basic auth. No refresh token is issued.
+// @Tags         auth
+// @Accept       x-www-form-urlencoded
+// @Accept       json
+// @Produce      json
+// @Param        request  body      models.ClientTokenRequest  true  "Token request"
+// @Success      200      {object}  response.Response{data=models.ClientTokenResponse}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /auth/token [post]
+func (h *OAuthClientHandler) Token(c *gin.Context) {
+	var req models.ClientTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID = clientID
+		req.ClientSecret = clientSecret
+	}
+
+	token, err := h.clientService.IssueToken(&req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Success(c, token)
+}
+
+// Create registers a machine client
+// @Summary      Register OAuth client
+// @Description  Register a machine client for the client credentials grant (admin only). The client secret is only returned once.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.CreateOAuthClientRequest  true  "Client data"
+// @Success      201      {object}  response.Response{data=models.OAuthClient}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/oauth-clients [post]
+func (h *OAuthClientHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	client, err := h.clientService.CreateClient(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, client)
+}
+
+// List lists registered machine clients
+// @Summary      List OAuth clients
+// @Description  List registered machine clients (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.OAuthClient}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/oauth-clients [get]
+func (h *OAuthClientHandler) List(c *gin.Context) {
+	clients, err := h.clientService.ListClients()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, clients)
+}
+
+// Revoke revokes a machine client
+// @Summary      Revoke OAuth client
+// @Description  Revoke a machine client so it can no longer obtain tokens; requests with the tokens it already has get 401 with reason TOKEN_REVOKED (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id   path      string  true  "OAuth client ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/oauth-clients/{id} [delete]
+func (h *OAuthClientHandler) Revoke(c *gin.Context) {
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.clientService.RevokeClient(id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "OAuth client revoked successfully", nil)
+}
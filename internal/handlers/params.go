@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+	"go-backend-api/internal/pkg/text"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Pagination limits shared by list endpoints
+const (
+	defaultPerPage = 10
+	maxPerPage     = 100
+)
+
+// The helpers below read path and query parameters. On invalid input they write a 400
+// response with reason INVALID_PARAMETER and return false, so handlers can simply return.
+
+// pagination holds the page and per_page query parameters of a list request
+type pagination struct {
+	Page    int
+	PerPage int
+}
+
+// meta builds the response metadata for a page of total items
+func (p pagination) meta(total int) response.PaginationMeta {
+	return response.PaginationMeta{
+		Page:       p.Page,
+		PerPage:    p.PerPage,
+		Total:      total,
+		TotalPages: (total + p.PerPage - 1) / p.PerPage,
+	}
+}
+
+// pathUUID parses the named path parameter as a UUID
+func pathUUID(c *gin.Context, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(name))
+	if err != nil {
+		response.Error(c, errors.NewInvalidParamError(name, name+" must be a UUID"))
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// queryUUID parses the named query parameter as a UUID, returning nil when it is absent
+func queryUUID(c *gin.Context, name string) (*uuid.UUID, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+	id, err := uuid.Parse(value)
+	if err != nil {
+		response.Error(c, errors.NewInvalidParamError(name, name+" must be a UUID"))
+		return nil, false
+	}
+	return &id, true
+}
+
+// paginationParams reads the page (default 1) and per_page (default 10, at most 100) query parameters
+func paginationParams(c *gin.Context) (pagination, bool) {
+	page, ok := queryInt(c, "page", 1, 1, 0)
+	if !ok {
+		return pagination{}, false
+	}
+	perPage, ok := queryInt(c, "per_page", defaultPerPage, 1, maxPerPage)
+	if !ok {
+		return pagination{}, false
+	}
+	return pagination{Page: page, PerPage: perPage}, true
+}
+
+// queryInt reads an integer query parameter within [lo, hi], or def when absent. A hi of 0 means unbounded.
+func queryInt(c *gin.Context, name string, def, lo, hi int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < lo || (hi > 0 && n > hi) {
+		details := name + " must be an integer of at least " + strconv.Itoa(lo)
+		if hi > 0 {
+			details = name + " must be an integer between " + strconv.Itoa(lo) + " and " + strconv.Itoa(hi)
+		}
+		response.Error(c, errors.NewInvalidParamError(name, details))
+		return 0, false
+	}
+	return n, true
+}
+
+// queryEnum reads a query parameter that must be one of allowed, or def when absent
+func queryEnum(c *gin.Context, name, def string, allowed ...string) (string, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return def, true
+	}
+	for _, candidate := range allowed {
+		if value == candidate {
+			return value, true
+		}
+	}
+	response.Error(c, errors.NewInvalidParamError(name, name+" must be one of: "+strings.Join(allowed, ", ")))
+	return "", false
+}
+
+// queryLanguage reads a query parameter that must be an ISO 639-1 language code, returning "" when absent
+func queryLanguage(c *gin.Context, name string) (string, bool) {
+	value := c.Query(name)
+	if value == "" || text.IsLanguageCode(value) {
+		return value, true
+	}
+	response.Error(c, errors.NewInvalidParamError(name, name+" must be a two-letter lowercase ISO 639-1 language code"))
+	return "", false
+}
+
+// sortParam reads a sort query parameter such as "created_at" or "-created_at" (descending)
+// and resolves it through columns, returning an ORDER BY clause. def applies when absent.
+func sortParam(c *gin.Context, columns security.IdentifierAllowlist, def string) (string, bool) {
+	value := c.DefaultQuery("sort", def)
+	descending := strings.HasPrefix(value, "-")
+	column, err := columns.Resolve(strings.TrimPrefix(value, "-"))
+	if err != nil {
+		keys := make([]string, 0, len(columns))
+		for key := range columns {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		response.Error(c, errors.NewInvalidParamError("sort", "sort must be one of: "+strings.Join(keys, ", ")+", optionally prefixed with -"))
+		return "", false
+	}
+	return columns.OrderBy(strings.TrimPrefix(value, "-"), column, descending), true
+}
+
+// preferStaleOK is the Prefer header preference of clients that accept data a bounded
+// amount of time out of date in exchange for a faster answer
+const preferStaleOK = "stale-ok"
+
+// prefersStale reports whether the request carries "Prefer: stale-ok"
+func prefersStale(c *gin.Context) bool {
+	return response.Prefers(c, preferStaleOK)
+}
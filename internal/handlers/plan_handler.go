@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PlanHandler handles plan requests
+type PlanHandler struct {
+	planService models.PlanService
+}
+
+// NewPlanHandler creates a new plan handler
+func NewPlanHandler(planService models.PlanService) *PlanHandler {
+	return &PlanHandler{planService: planService}
+}
+
+// Get gets the plan of the current user and its limits
+// @Summary      Get plan
+// @Description  Get the plan of the current user with its quotas, per-user rate limit and features
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.PlanLimits}
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/plan [get]
+func (h *PlanHandler) Get(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	limits, err := h.planService.GetUserLimits(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, limits)
+}
+
+// Update moves a user to another plan
+// @Summary      Update plan
+// @Description  Move a user to the free or pro plan (admin only). Access tokens issued before keep the old plan until they are refreshed; API keys get the new one right away.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                    false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id           path      string                    true   "User ID"
+// @Param        request      body      models.UpdatePlanRequest  true   "New plan"
+// @Success      200          {object}  response.Response{data=models.PlanLimits}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/users/{id}/plan [put]
+func (h *PlanHandler) Update(c *gin.Context) {
+	userID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	limits, err := h.planService.UpdatePlan(userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, limits)
+}
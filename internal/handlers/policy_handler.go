@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PolicyHandler handles terms of service and privacy policy requests
+type PolicyHandler struct {
+	policyService models.PolicyService
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(policyService models.PolicyService) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+	}
+}
+
+// ListCurrent lists the current policy versions
+// @Summary      List current policies
+// @Description  List the current version of the terms of service and privacy policy, which must be accepted to register
+// @Tags         policies
+// @Produce      json
+// @Success      200  {object}  response.Response{data=[]models.PolicyDocument}
+// @Failure      500  {object}  response.Response
+// @Router       /public/policies [get]
+func (h *PolicyHandler) ListCurrent(c *gin.Context) {
+	docs, err := h.policyService.ListCurrent()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, docs)
+}
+
+// GetStatus gets the policy versions the current user accepted and has yet to accept
+// @Summary      Get my policy status
+// @Description  Get the current policy versions, the mandatory versions the authenticated user has yet to accept and the versions they accepted. Available while acceptance is pending.
+// @Tags         policies
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.PolicyStatus}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/policies [get]
+func (h *PolicyHandler) GetStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	status, err := h.policyService.GetStatus(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// Accept records the current user accepting the current policy versions
+// @Summary      Accept policies
+// @Description  Accept current policy versions, given by kind. Once every pending mandatory version is accepted, the API is available again.
+// @Tags         policies
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.AcceptPoliciesRequest  true  "Accepted versions"
+// @Success      200      {object}  response.Response{data=models.PolicyStatus}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/policies/accept [post]
+func (h *PolicyHandler) Accept(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.AcceptPoliciesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	status, err := h.policyService.Accept(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, status)
+}
+
+// Publish publishes a new policy version
+// @Summary      Publish policy
+// @Description  Publish a new version of the terms of service or privacy policy (admin only). It becomes current immediately; unless mandatory is false, users must accept it before using the API again.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.PublishPolicyRequest  true  "Policy version"
+// @Success      201      {object}  response.Response{data=models.PolicyDocument}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /admin/policies [post]
+func (h *PolicyHandler) Publish(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.PublishPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	doc, err := h.policyService.Publish(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, doc)
+}
+
+// List lists every published policy version
+// @Summary      List policies
+// @Description  List every published version of the terms of service and privacy policy, newest first (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.PolicyDocument}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /admin/policies [get]
+func (h *PolicyHandler) List(c *gin.Context) {
+	docs, err := h.policyService.ListDocuments()
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, docs)
+}
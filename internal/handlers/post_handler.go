@@ -1,8 +1,10 @@
 package handlers
 
 import (
-	"strconv"
+	"io"
 
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/dto"
 	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/response"
 
@@ -13,26 +15,47 @@ import (
 // PostHandler handles post requests
 type PostHandler struct {
 	postService models.PostService
+	// replicaPostService reads from the replica, for listings requested with "Prefer: stale-ok"
+	replicaPostService models.PostService
+	replica            *database.ReplicaMonitor
 }
 
-// NewPostHandler creates a new post handler
-func NewPostHandler(postService models.PostService) *PostHandler {
+// NewPostHandler creates a new post handler. Without a replica (nil), every request is
+// served by postService.
+func NewPostHandler(postService, replicaPostService models.PostService, replica *database.ReplicaMonitor) *PostHandler {
 	return &PostHandler{
-		postService: postService,
+		postService:        postService,
+		replicaPostService: replicaPostService,
+		replica:            replica,
 	}
 }
 
+// listingService picks the service a listing is read with: the replica when the client
+// prefers stale-ok and the replica lag is within its bound, the primary otherwise
+func (h *PostHandler) listingService(c *gin.Context) models.PostService {
+	if h.replica == nil {
+		return h.postService
+	}
+	response.Vary(c, "Prefer")
+	if !prefersStale(c) || !h.replica.Fresh() {
+		return h.postService
+	}
+	c.Header("Preference-Applied", preferStaleOK)
+	return h.replicaPostService
+}
+
 // Create creates a new post
 // @Summary      Create a new post
-// @Description  Create a new post (authenticated users only)
+// @Description  Create a new post (authenticated users only). HTML content (content_format=html) is sanitized, and content_sanitized tells whether unsafe markup was removed.
 // @Tags         posts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request  body      models.CreatePostRequest  true  "Post data"
-// @Success      201      {object}  response.Response{data=models.Post}
+// @Success      201      {object}  response.Response{data=dto.PostResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
+// @Failure      413      {object}  response.Response
 // @Failure      500      {object}  response.Response
 // @Router       /posts [post]
 func (h *PostHandler) Create(c *gin.Context) {
@@ -60,12 +83,12 @@ func (h *PostHandler) Create(c *gin.Context) {
 		return
 	}
 
-	response.Created(c, post)
+	response.Created(c, postResponse(c, post))
 }
 
 // GetAll gets all posts with pagination
 // @Summary      Get all posts
-// @Description  Get all posts with pagination support
+// @Description  Get all posts with pagination support. Listings carry an excerpt and reading time instead of the full content. Archived posts are left out, except when authors filter by their own ID. Without lang, posts in the viewer's preferred languages are listed first. With "Prefer: stale-ok", the listing may be served from a read replica at most DB_REPLICA_MAX_STALENESS behind, which the response confirms with "Preference-Applied: stale-ok".
 // @Tags         posts
 // @Accept       json
 // @Produce      json
@@ -73,36 +96,36 @@ func (h *PostHandler) Create(c *gin.Context) {
 // @Param        page      query     int     false  "Page number"  default(1)
 // @Param        per_page  query     int     false  "Items per page"  default(10)
 // @Param        author_id query     string  false  "Filter by author ID"
-// @Success      200       {object}  response.PaginatedResponse{data=[]models.Post}
+// @Param        lang      query     string  false  "Filter by detected ISO 639-1 language, e.g. vi (not combined with author_id)"
+// @Param        Prefer    header    string  false  "stale-ok to accept a replica read"
+// @Success      200       {object}  response.PaginatedResponse{data=[]dto.PostSummaryResponse}
 // @Failure      400       {object}  response.Response
 // @Failure      401       {object}  response.Response
 // @Failure      500       {object}  response.Response
 // @Router       /posts [get]
 func (h *PostHandler) GetAll(c *gin.Context) {
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
-	authorID := c.Query("author_id")
-
-	if page < 1 {
-		page = 1
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
 	}
-	if perPage < 1 || perPage > 100 {
-		perPage = 10
+	authorID, ok := queryUUID(c, "author_id")
+	if !ok {
+		return
+	}
+	lang, ok := queryLanguage(c, "lang")
+	if !ok {
+		return
 	}
 
 	var posts []*models.Post
 	var total int
 	var err error
 
-	if authorID != "" {
-		authorUUID, parseErr := uuid.Parse(authorID)
-		if parseErr != nil {
-			response.BadRequest(c, "Invalid author_id")
-			return
-		}
-		posts, total, err = h.postService.GetPostsByAuthor(authorUUID, page, perPage)
+	service := h.listingService(c)
+	if authorID != nil {
+		posts, total, err = service.GetPostsByAuthor(*authorID, viewerID(c), paging.Page, paging.PerPage)
 	} else {
-		posts, total, err = h.postService.GetPosts(page, perPage)
+		posts, total, err = service.GetPosts(viewerID(c), lang, paging.Page, paging.PerPage)
 	}
 
 	if err != nil {
@@ -110,45 +133,197 @@ func (h *PostHandler) GetAll(c *gin.Context) {
 		return
 	}
 
-	totalPages := (total + perPage - 1) / perPage
-	meta := response.PaginationMeta{
-		Page:       page,
-		PerPage:    perPage,
-		Total:      total,
-		TotalPages: totalPages,
+	response.Paginated(c, postSummaryResponses(c, posts), paging.meta(total))
+}
+
+// Feed lists the posts of the authors the current user follows
+// @Summary      Get feed
+// @Description  List the published posts of the authors the current user follows, newest first, public and followers-only alike. Listings carry an excerpt and reading time instead of the full content. With FEED_FANOUT on, the feed is read from the user's stored feed, which keeps the latest FEED_MAX_ENTRIES posts and may briefly lag behind new posts and follows.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        page      query     int  false  "Page number"  default(1)
+// @Param        per_page  query     int  false  "Items per page"  default(10)
+// @Success      200       {object}  response.PaginatedResponse{data=[]dto.PostSummaryResponse}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /feed [get]
+func (h *PostHandler) Feed(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
 	}
 
-	response.Paginated(c, posts, meta)
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	posts, total, err := h.postService.GetFeed(userUUID, paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, postSummaryResponses(c, posts), paging.meta(total))
+}
+
+// Schedule lists the current user's scheduled posts
+// @Summary      My post schedule
+// @Description  List the current user's scheduled posts between from and to, grouped by calendar day for calendar views. Dates are read in the user's timezone preference, from its first to, with to, its last instant, however long daylight saving changes make the day; RFC 3339 times are taken as is, to exclusive. Without from the schedule starts today, and without to it spans 30 days; ranges are at most a year.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        from  query     string  false  "First day (2006-01-02) or RFC 3339 time"
+// @Param        to    query     string  false  "Last day (2006-01-02) or exclusive RFC 3339 time"
+// @Success      200   {object}  response.Response{data=dto.PostScheduleResponse}
+// @Failure      400   {object}  response.Response
+// @Failure      401   {object}  response.Response
+// @Failure      500   {object}  response.Response
+// @Router       /posts/schedule [get]
+func (h *PostHandler) Schedule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	schedule, err := h.postService.GetSchedule(userUUID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, dto.NewPostScheduleResponse(schedule, postSummaryResponses(c, schedule.Posts)))
+}
+
+// Search searches posts
+// @Summary      Search posts
+// @Description  Search the published posts the viewer may list, in their titles and content, best matches first. q takes words, "quoted phrases", or and -excluded words; posts whose titles resemble q are also found, to tolerate typos. When fewer than 3 posts match, did_you_mean offers q with misspelled words corrected.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q         query     string  true   "Search query (1-200 characters)"
+// @Param        page      query     int     false  "Page number"  default(1)
+// @Param        per_page  query     int     false  "Items per page"  default(10)
+// @Success      200       {object}  response.PaginatedResponse{data=dto.PostSearchResponse}
+// @Failure      400       {object}  response.Response
+// @Failure      401       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /posts/search [get]
+func (h *PostHandler) Search(c *gin.Context) {
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	query := c.Query("q")
+	result, err := h.postService.SearchPosts(viewerID(c), query, paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, dto.PostSearchResponse{
+		Query:      query,
+		DidYouMean: result.DidYouMean,
+		Posts:      postSummaryResponses(c, result.Posts),
+	}, paging.meta(result.Total))
+}
+
+// Suggest suggests completions of a partly typed search
+// @Summary      Search suggestions
+// @Description  Suggest titles of posts the viewer may list and usernames of authors of public posts resembling q, for search-as-you-type. Matching tolerates typos; titles and usernames starting with q come first.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q      query     string  true   "Partly typed search (2-200 characters)"
+// @Param        limit  query     int     false  "Most suggestions (1-20)"  default(10)
+// @Success      200    {object}  response.Response{data=[]models.SearchSuggestion}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /posts/search/suggest [get]
+func (h *PostHandler) Suggest(c *gin.Context) {
+	limit, ok := queryInt(c, "limit", 10, 1, 20)
+	if !ok {
+		return
+	}
+
+	suggestions, err := h.postService.SuggestSearch(viewerID(c), c.Query("q"), limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, suggestions)
 }
 
 // GetByID gets a post by ID
 // @Summary      Get post by ID
-// @Description  Get a specific post by its ID
+// @Description  Get a specific post by its ID. Archived posts are only visible to their author.
 // @Tags         posts
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        id   path      string  true  "Post ID"
-// @Success      200  {object}  response.Response{data=models.Post}
+// @Success      200  {object}  response.Response{data=dto.PostResponse}
 // @Failure      400  {object}  response.Response
 // @Failure      401  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /posts/{id} [get]
 func (h *PostHandler) GetByID(c *gin.Context) {
-	postID, err := uuid.Parse(c.Param("id"))
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	post, err := h.postService.GetPostByID(postID, viewerID(c))
 	if err != nil {
-		response.BadRequest(c, "Invalid post ID")
+		response.Error(c, err)
 		return
 	}
 
-	post, err := h.postService.GetPostByID(postID)
+	response.Success(c, postResponse(c, post))
+}
+
+// GetByShortID gets a published post by the short ID of its shareable link
+// @Summary      Get post by short ID
+// @Description  Resolve the short ID of a shareable link to its post, without signing in. Only published posts that are not archived are found.
+// @Tags         posts
+// @Produce      json
+// @Param        shortID  path      string  true  "Short ID of the post"
+// @Success      200      {object}  response.Response{data=dto.PostResponse}
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /p/{shortID} [get]
+func (h *PostHandler) GetByShortID(c *gin.Context) {
+	post, err := h.postService.GetPostByShortID(c.Param("shortID"), viewerID(c))
 	if err != nil {
 		response.Error(c, err)
 		return
 	}
 
-	response.Success(c, post)
+	response.Success(c, postResponse(c, post))
 }
 
 // Update updates a post
@@ -160,11 +335,14 @@ func (h *PostHandler) GetByID(c *gin.Context) {
 // @Security     BearerAuth
 // @Param        id       path      string                true  "Post ID"
 // @Param        request  body      models.UpdatePostRequest  true  "Post update data"
-// @Success      200      {object}  response.Response{data=models.Post}
+// @Success      200      {object}  response.Response{data=dto.PostResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
 // @Failure      403      {object}  response.Response
 // @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      423      {object}  response.Response
+// @Failure      413      {object}  response.Response
 // @Failure      500      {object}  response.Response
 // @Router       /posts/{id} [put]
 func (h *PostHandler) Update(c *gin.Context) {
@@ -180,9 +358,8 @@ func (h *PostHandler) Update(c *gin.Context) {
 		return
 	}
 
-	postID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		response.BadRequest(c, "Invalid post ID")
+	postID, ok := pathUUID(c, "id")
+	if !ok {
 		return
 	}
 
@@ -198,7 +375,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 		return
 	}
 
-	response.SuccessWithMessage(c, "Post updated successfully", post)
+	response.SuccessWithMessage(c, "Post updated successfully", postResponse(c, post))
 }
 
 // Delete deletes a post
@@ -214,6 +391,7 @@ func (h *PostHandler) Update(c *gin.Context) {
 // @Failure      401  {object}  response.Response
 // @Failure      403  {object}  response.Response
 // @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /posts/{id} [delete]
 func (h *PostHandler) Delete(c *gin.Context) {
@@ -229,13 +407,12 @@ func (h *PostHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	postID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		response.BadRequest(c, "Invalid post ID")
+	postID, ok := pathUUID(c, "id")
+	if !ok {
 		return
 	}
 
-	err = h.postService.DeletePost(postID, userUUID)
+	err := h.postService.DeletePost(postID, userUUID)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -243,3 +420,271 @@ func (h *PostHandler) Delete(c *gin.Context) {
 
 	response.SuccessWithMessage(c, "Post deleted successfully", nil)
 }
+
+// Archive archives a post
+// @Summary      Archive a post
+// @Description  Archive a post (author only). The post is unpublished and hidden from listings except the author's own until it is unarchived.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Post ID"
+// @Success      200  {object}  response.Response{data=dto.PostResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /posts/{id}/archive [post]
+func (h *PostHandler) Archive(c *gin.Context) {
+	h.changeArchived(c, h.postService.ArchivePost, "Post archived successfully")
+}
+
+// Unarchive restores an archived post
+// @Summary      Unarchive a post
+// @Description  Restore an archived post (author only) as an unpublished draft
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Post ID"
+// @Success      200  {object}  response.Response{data=dto.PostResponse}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /posts/{id}/unarchive [post]
+func (h *PostHandler) Unarchive(c *gin.Context) {
+	h.changeArchived(c, h.postService.UnarchivePost, "Post unarchived successfully")
+}
+
+// Lock takes or renews the editing lock of a post
+// @Summary      Lock a post for editing
+// @Description  Take the soft editing lock of a post (author only), or renew it by sending the lock_token it returned. Editing clients renew the lock as a heartbeat; it expires after POST_LOCK_TTL without one. While a lock is held, updates must carry its lock_token and other sessions get 423.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true   "Post ID"
+// @Param        request  body      models.PostLockRequest  false  "Token of the lock to renew"
+// @Success      200      {object}  response.Response{data=models.PostLock}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      423      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /posts/{id}/lock [post]
+func (h *PostHandler) Lock(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional: without one a new lock is taken
+	var req models.PostLockRequest
+	if err := c.ShouldBindJSON(&req); err != nil && err != io.EOF {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	lock, err := h.postService.LockPost(postID, userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, lock)
+}
+
+// Unlock releases the editing lock of a post
+// @Summary      Unlock a post
+// @Description  Release the editing lock held with lock_token (author only), e.g. when the editor is closed
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Post ID"
+// @Param        lock_token  query     string  true  "Token of the lock to release"
+// @Success      200         {object}  response.Response
+// @Failure      400         {object}  response.Response
+// @Failure      401         {object}  response.Response
+// @Failure      403         {object}  response.Response
+// @Failure      404         {object}  response.Response
+// @Failure      409         {object}  response.Response
+// @Failure      500         {object}  response.Response
+// @Router       /posts/{id}/lock [delete]
+func (h *PostHandler) Unlock(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	token := c.Query("lock_token")
+	if token == "" {
+		response.BadRequest(c, "lock_token is required")
+		return
+	}
+
+	if err := h.postService.UnlockPost(postID, userUUID, token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Post unlocked successfully", nil)
+}
+
+// Autosave stores the unsaved draft of a post
+// @Summary      Autosave a post draft
+// @Description  Store the editor's unsaved title and content (author only) so they can be recovered after a crash. The post itself, its updated_at and its listings are untouched; saving the post discards the autosave. While the post is locked, the autosave must carry the lock's lock_token.
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                      true  "Post ID"
+// @Param        request  body      models.AutosavePostRequest  true  "Draft title and content"
+// @Success      200      {object}  response.Response{data=models.PostAutosave}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      403      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      423      {object}  response.Response
+// @Failure      413      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /posts/{id}/autosave [put]
+func (h *PostHandler) Autosave(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.AutosavePostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	autosave, err := h.postService.AutosavePost(postID, userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, autosave)
+}
+
+// GetAutosave gets the autosaved draft of a post
+// @Summary      Get a post's autosaved draft
+// @Description  Get the draft last autosaved for a post (author only), to recover work that was never saved
+// @Tags         posts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Post ID"
+// @Success      200  {object}  response.Response{data=models.PostAutosave}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /posts/{id}/autosave [get]
+func (h *PostHandler) GetAutosave(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	autosave, err := h.postService.GetAutosave(postID, userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, autosave)
+}
+
+// changeArchived applies an archive transition to the post in the path on behalf of the current user
+func (h *PostHandler) changeArchived(c *gin.Context, transition func(id, authorID uuid.UUID) (*models.Post, error), message string) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	post, err := transition(postID, userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, message, postResponse(c, post))
+}
+
+// viewerID returns the current user's ID, or uuid.Nil for callers that are not a user
+func viewerID(c *gin.Context) uuid.UUID {
+	userID, _ := c.Get("user_id")
+	id, _ := userID.(uuid.UUID)
+	return id
+}
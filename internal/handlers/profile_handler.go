@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/url"
+	"strings"
+
+	"go-backend-api/internal/dto"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ProfileHandler handles public profile and follow requests
+type ProfileHandler struct {
+	profileService models.ProfileService
+}
+
+// NewProfileHandler creates a new profile handler
+func NewProfileHandler(profileService models.ProfileService) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+	}
+}
+
+// GetPublicProfile gets a user's public profile page by username
+// @Summary      Get public user profile
+// @Description  Get everything a profile page renders in one response: the public profile, published post and follower counts, and the latest published posts. Previous usernames return a 301 redirect payload pointing to the current username
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Param        username  path      string  true  "Username"
+// @Success      200       {object}  response.Response{data=dto.ProfilePageResponse}
+// @Success      301       {object}  response.Response{data=models.UsernameRedirect}
+// @Failure      404       {object}  response.Response
+// @Failure      500       {object}  response.Response
+// @Router       /public/users/{username} [get]
+func (h *ProfileHandler) GetPublicProfile(c *gin.Context) {
+	page, redirect, err := h.profileService.GetProfilePage(c.Param("username"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if redirect != nil {
+		redirect.Location = strings.TrimSuffix(c.FullPath(), ":username") + url.PathEscape(redirect.Username)
+		response.MovedPermanently(c, redirect.Location, redirect)
+		return
+	}
+
+	response.Success(c, dto.NewProfilePageResponse(page))
+}
+
+// Follow follows a user
+// @Summary      Follow a user
+// @Description  Follow an active user. Following someone already followed succeeds without change.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/{id}/follow [post]
+func (h *ProfileHandler) Follow(c *gin.Context) {
+	userUUID, followeeID, ok := followParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.profileService.FollowUser(userUUID, followeeID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "User followed successfully", nil)
+}
+
+// Unfollow stops following a user
+// @Summary      Unfollow a user
+// @Description  Stop following a user
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/{id}/follow [delete]
+func (h *ProfileHandler) Unfollow(c *gin.Context) {
+	userUUID, followeeID, ok := followParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.profileService.UnfollowUser(userUUID, followeeID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "User unfollowed successfully", nil)
+}
+
+// followParams reads the current user and the user in the path, responding with an error when either is missing
+func followParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	followeeID, ok := pathUUID(c, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userUUID, followeeID, true
+}
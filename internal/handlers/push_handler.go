@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PushHandler handles push device, topic and quiet hours requests
+type PushHandler struct {
+	pushService models.PushService
+}
+
+// NewPushHandler creates a new push handler
+func NewPushHandler(pushService models.PushService) *PushHandler {
+	return &PushHandler{pushService: pushService}
+}
+
+// RegisterDevice registers a device of the current user for push notifications
+// @Summary      Register push device
+// @Description  Register an FCM registration token or APNs device token of the authenticated user. Registering a token again refreshes it; a token registered by another account moves to this one. Beyond the device limit, the least recently registered device is forgotten.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.RegisterDeviceRequest  true  "Device"
+// @Success      201      {object}  response.Response{data=models.PushDevice}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/devices [post]
+func (h *PushHandler) RegisterDevice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.RegisterDeviceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	device, err := h.pushService.RegisterDevice(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, device)
+}
+
+// ListDevices lists the push devices of the current user
+// @Summary      List push devices
+// @Description  List the devices the authenticated user receives push notifications on, most recently registered first
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.PushDevice}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/devices [get]
+func (h *PushHandler) ListDevices(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	devices, err := h.pushService.ListDevices(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, devices)
+}
+
+// DeleteDevice stops push notifications to a device of the current user
+// @Summary      Delete push device
+// @Description  Stop push notifications to a device, e.g. when signing out of the app
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Device ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/devices/{id} [delete]
+func (h *PushHandler) DeleteDevice(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.pushService.DeleteDevice(userUUID, id); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Push device deleted successfully", nil)
+}
+
+// ListTopics lists the push topics the current user is subscribed to
+// @Summary      List push topics
+// @Description  List the topics the authenticated user receives push notifications of, newest first
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.PushSubscription}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/push/topics [get]
+func (h *PushHandler) ListTopics(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	subs, err := h.pushService.ListTopics(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, subs)
+}
+
+// Subscribe subscribes the current user to a push topic
+// @Summary      Subscribe to push topic
+// @Description  Receive push notifications of a topic: author:<user ID> for the posts an author publishes, post:<post ID> for the comments on a post. Subscribing again keeps the subscription.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        topic  path      string  true  "Topic, e.g. author:<user ID>"
+// @Success      200    {object}  response.Response{data=models.PushSubscription}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      404    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /users/push/topics/{topic} [put]
+func (h *PushHandler) Subscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	sub, err := h.pushService.Subscribe(userUUID, c.Param("topic"))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, sub)
+}
+
+// Unsubscribe unsubscribes the current user from a push topic
+// @Summary      Unsubscribe from push topic
+// @Description  Stop push notifications of a topic; unsubscribing from a topic the user is not subscribed to succeeds
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        topic  path      string  true  "Topic, e.g. author:<user ID>"
+// @Success      200    {object}  response.Response
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /users/push/topics/{topic} [delete]
+func (h *PushHandler) Unsubscribe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	if err := h.pushService.Unsubscribe(userUUID, c.Param("topic")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Unsubscribed from push topic", nil)
+}
+
+// GetQuietHours gets the quiet hours of the current user
+// @Summary      Get quiet hours
+// @Description  Get the daily period during which push notifications to the authenticated user are held, in the timezone of their preferences
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.QuietHoursSettings}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/push/quiet-hours [get]
+func (h *PushHandler) GetQuietHours(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	settings, err := h.pushService.GetQuietHours(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, settings)
+}
+
+// UpdateQuietHours sets the quiet hours of the current user
+// @Summary      Update quiet hours
+// @Description  Set the daily period, HH:MM to HH:MM in the timezone of the user's preferences, during which push notifications are held and sent when it ends; a period ending before it starts spans midnight. Empty times turn quiet hours off.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.UpdateQuietHoursRequest  true  "Quiet hours"
+// @Success      200      {object}  response.Response{data=models.QuietHoursSettings}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/push/quiet-hours [put]
+func (h *PushHandler) UpdateQuietHours(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdateQuietHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	settings, err := h.pushService.UpdateQuietHours(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, settings)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ReactionHandler handles reaction requests
+type ReactionHandler struct {
+	reactionService models.ReactionService
+}
+
+// NewReactionHandler creates a new reaction handler
+func NewReactionHandler(reactionService models.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// ReactToPost sets the current user's reaction to a post
+// @Summary      React to a post
+// @Description  Set the current user's reaction to a post, replacing their previous one. Returns the post's reaction counts by type.
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                  true  "Post ID"
+// @Param        request  body      models.ReactionRequest  true  "Reaction"
+// @Success      200      {object}  response.Response{data=map[string]int}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /posts/{id}/reaction [put]
+func (h *ReactionHandler) ReactToPost(c *gin.Context) {
+	userID, postID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	counts, err := h.reactionService.ReactToPost(postID, userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, counts)
+}
+
+// UnreactToPost removes the current user's reaction to a post
+// @Summary      Remove reaction from a post
+// @Description  Remove the current user's reaction to a post. Returns the post's reaction counts by type.
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Post ID"
+// @Success      200  {object}  response.Response{data=map[string]int}
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      409  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /posts/{id}/reaction [delete]
+func (h *ReactionHandler) UnreactToPost(c *gin.Context) {
+	userID, postID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+
+	counts, err := h.reactionService.UnreactToPost(postID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, counts)
+}
+
+// ReactToComment sets the current user's reaction to a comment
+// @Summary      React to a comment
+// @Description  Set the current user's reaction to a comment, replacing their previous one. Returns the comment's reaction counts by type.
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string                  true  "Post ID"
+// @Param        comment_id  path      string                  true  "Comment ID"
+// @Param        request     body      models.ReactionRequest  true  "Reaction"
+// @Success      200         {object}  response.Response{data=map[string]int}
+// @Failure      400         {object}  response.Response
+// @Failure      401         {object}  response.Response
+// @Failure      404         {object}  response.Response
+// @Failure      409         {object}  response.Response
+// @Failure      500         {object}  response.Response
+// @Router       /posts/{id}/comments/{comment_id}/reaction [put]
+func (h *ReactionHandler) ReactToComment(c *gin.Context) {
+	userID, postID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+	commentID, ok := pathUUID(c, "comment_id")
+	if !ok {
+		return
+	}
+
+	var req models.ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	counts, err := h.reactionService.ReactToComment(postID, commentID, userID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, counts)
+}
+
+// UnreactToComment removes the current user's reaction to a comment
+// @Summary      Remove reaction from a comment
+// @Description  Remove the current user's reaction to a comment. Returns the comment's reaction counts by type.
+// @Tags         reactions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id          path      string  true  "Post ID"
+// @Param        comment_id  path      string  true  "Comment ID"
+// @Success      200         {object}  response.Response{data=map[string]int}
+// @Failure      400         {object}  response.Response
+// @Failure      401         {object}  response.Response
+// @Failure      404         {object}  response.Response
+// @Failure      409         {object}  response.Response
+// @Failure      500         {object}  response.Response
+// @Router       /posts/{id}/comments/{comment_id}/reaction [delete]
+func (h *ReactionHandler) UnreactToComment(c *gin.Context) {
+	userID, postID, ok := reactionParams(c)
+	if !ok {
+		return
+	}
+	commentID, ok := pathUUID(c, "comment_id")
+	if !ok {
+		return
+	}
+
+	counts, err := h.reactionService.UnreactToComment(postID, commentID, userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, counts)
+}
+
+// reactionParams gets the current user and the post ID of a reaction request,
+// writing the error response when either is missing
+func reactionParams(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	postID, ok := pathUUID(c, "id")
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userUUID, postID, true
+}
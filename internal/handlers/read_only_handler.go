@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"go-backend-api/internal/logger"
+	"go-backend-api/internal/middleware"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyHandler turns read-only mode on and off at runtime
+type ReadOnlyHandler struct {
+	mode   *middleware.ReadOnlyMode
+	logger *logger.Logger
+}
+
+// ReadOnlySettings is the current read-only mode
+type ReadOnlySettings struct {
+	Enabled bool `json:"enabled"` // Writes outside of the auth and admin routes return 503
+}
+
+// UpdateReadOnlyRequest turns read-only mode on or off
+type UpdateReadOnlyRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// NewReadOnlyHandler creates a new read-only mode handler
+func NewReadOnlyHandler(mode *middleware.ReadOnlyMode, logger *logger.Logger) *ReadOnlyHandler {
+	return &ReadOnlyHandler{mode: mode, logger: logger}
+}
+
+// Get reports whether read-only mode is on
+// @Summary      Get read-only mode
+// @Description  Report whether the API rejects writes outside of the auth and admin routes (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=ReadOnlySettings}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /admin/read-only [get]
+func (h *ReadOnlyHandler) Get(c *gin.Context) {
+	response.Success(c, &ReadOnlySettings{Enabled: h.mode.Enabled()})
+}
+
+// Update turns read-only mode on or off, until the next restart
+// @Summary      Update read-only mode
+// @Description  Turn read-only mode on or off until the next restart, e.g. around a migration or failover. While it is on, writes outside of the auth and admin routes return 503 with reason READ_ONLY (admin only).
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                 false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        settings     body      UpdateReadOnlyRequest  true   "Read-only mode"
+// @Success      200          {object}  response.Response{data=ReadOnlySettings}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Router       /admin/read-only [put]
+func (h *ReadOnlyHandler) Update(c *gin.Context) {
+	var req UpdateReadOnlyRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	h.mode.Set(*req.Enabled)
+	h.logger.WithContext(c).WithField("read_only", *req.Enabled).Warn("Read-only mode changed")
+	response.Success(c, &ReadOnlySettings{Enabled: h.mode.Enabled()})
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"go-backend-api/internal/middleware"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MethodNotAllowed responds with a 405 to a request whose path is only registered under other methods
+func MethodNotAllowed(c *gin.Context) {
+	allow := middleware.CompleteAllowHeader(c)
+	response.Error(c, errors.ErrMethodNotAllowed.WithDetails("Allowed methods: "+allow))
+}
+
+// RouteNotFound responds with a 404 to paths that match no route, pointing clients at the API prefix and docs
+func RouteNotFound(apiPrefix string) gin.HandlerFunc {
+	hint := "API routes are served under " + apiPrefix + "; see /docs for the full list"
+	return func(c *gin.Context) {
+		response.Error(c, errors.ErrRouteNotFound.WithDetails(hint))
+	}
+}
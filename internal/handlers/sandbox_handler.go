@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"time"
+
+	"go-backend-api/internal/dto"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SandboxHandler provisions the throwaway accounts of the API console demo mode
+type SandboxHandler struct {
+	userService models.UserService
+	userTTL     time.Duration
+}
+
+// SandboxSession is a signed-in sandbox account
+type SandboxSession struct {
+	dto.LoginResponse
+	// ExpiresAt is when the account becomes due for deletion with everything it created
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// NewSandboxHandler creates a new sandbox handler for accounts deleted userTTL after creation
+func NewSandboxHandler(userService models.UserService, userTTL time.Duration) *SandboxHandler {
+	return &SandboxHandler{userService: userService, userTTL: userTTL}
+}
+
+// CreateSession creates a sandbox account and signs it in
+// @Summary      Create sandbox session
+// @Description  Create a throwaway account with the sandbox role and sign it in, for trying the API from /docs. Sandbox accounts have tight request quotas and are deleted with their content once they expire. Only available when SANDBOX_ENABLED is on.
+// @Tags         auth
+// @Produce      json
+// @Success      201  {object}  response.Response{data=SandboxSession}
+// @Failure      429  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Failure      503  {object}  response.Response
+// @Router       /auth/sandbox [post]
+func (h *SandboxHandler) CreateSession(c *gin.Context) {
+	loginResp, err := h.userService.CreateSandboxUser(clientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	response.Created(c, &SandboxSession{
+		LoginResponse: *dto.NewLoginResponse(loginResp),
+		ExpiresAt:     loginResp.User.CreatedAt.Add(h.userTTL),
+	})
+}
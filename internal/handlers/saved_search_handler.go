@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SavedSearchHandler handles saved search requests
+type SavedSearchHandler struct {
+	savedSearchService models.SavedSearchService
+}
+
+// NewSavedSearchHandler creates a new saved search handler
+func NewSavedSearchHandler(savedSearchService models.SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// Create saves a search for the current user
+// @Summary      Save search
+// @Description  Save a full-text search of post titles and content. Newly published posts matching it are emailed hourly, daily (default) or weekly.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.SavedSearchRequest  true  "Saved search data"
+// @Success      201      {object}  response.Response{data=models.SavedSearch}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/saved-searches [post]
+func (h *SavedSearchHandler) Create(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	search, err := h.savedSearchService.CreateSavedSearch(userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Created(c, search)
+}
+
+// List lists the current user's saved searches
+// @Summary      List saved searches
+// @Description  List the saved searches of the authenticated user
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=[]models.SavedSearch}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/saved-searches [get]
+func (h *SavedSearchHandler) List(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	searches, err := h.savedSearchService.ListSavedSearches(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, searches)
+}
+
+// Update changes one of the current user's saved searches
+// @Summary      Update saved search
+// @Description  Rename a saved search, change its query or its alert frequency. Use frequency off to stop alerts; a new query or turning alerts back on only alerts posts published from then on.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      string                           true  "Saved search ID"
+// @Param        request  body      models.UpdateSavedSearchRequest  true  "Saved search changes"
+// @Success      200      {object}  response.Response{data=models.SavedSearch}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/saved-searches/{id} [put]
+func (h *SavedSearchHandler) Update(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	search, err := h.savedSearchService.UpdateSavedSearch(id, userUUID, &req)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, search)
+}
+
+// Delete deletes one of the current user's saved searches
+// @Summary      Delete saved search
+// @Description  Delete a saved search of the authenticated user, stopping its alerts
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      string  true  "Saved search ID"
+// @Success      200  {object}  response.Response
+// @Failure      400  {object}  response.Response
+// @Failure      401  {object}  response.Response
+// @Failure      404  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/saved-searches/{id} [delete]
+func (h *SavedSearchHandler) Delete(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchService.DeleteSavedSearch(id, userUUID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Saved search deleted successfully", nil)
+}
+
+// Unsubscribe turns off the alerts of a saved search from the link in an alert
+// @Summary      Unsubscribe from saved search alerts
+// @Description  Turn off the alerts of a saved search using the token in its latest alert email. The search is kept with frequency off.
+// @Tags         public
+// @Accept       json
+// @Produce      json
+// @Param        token    query     string                    false  "Unsubscribe token (alternative to body)"
+// @Param        request  body      models.EmailTokenRequest  false  "Unsubscribe token"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /public/saved-searches/unsubscribe [post]
+func (h *SavedSearchHandler) Unsubscribe(c *gin.Context) {
+	token, ok := bindEmailToken(c)
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchService.Unsubscribe(token); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Unsubscribed from saved search alerts", nil)
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"io"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SecurityHandler handles security reporting and incident response requests
+type SecurityHandler struct {
+	loginStatsService      models.LoginStatsService
+	tokenRevocationService models.TokenRevocationService
+}
+
+// NewSecurityHandler creates a new security handler
+func NewSecurityHandler(loginStatsService models.LoginStatsService, tokenRevocationService models.TokenRevocationService) *SecurityHandler {
+	return &SecurityHandler{
+		loginStatsService:      loginStatsService,
+		tokenRevocationService: tokenRevocationService,
+	}
+}
+
+// LoginStats reports sign-in successes and failures
+// @Summary      Login stats
+// @Description  Summarize sign-in successes and failures per hour, with the client IPs and accounts that saw the most failures (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        hours  query     int  false  "Hours to cover, including the current one"  default(24)
+// @Param        limit  query     int  false  "Maximum number of IPs and accounts"  default(10)
+// @Success      200    {object}  response.Response{data=models.LoginStatsReport}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      403    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /admin/security/login-stats [get]
+func (h *SecurityHandler) LoginStats(c *gin.Context) {
+	hours, ok := queryInt(c, "hours", 24, 1, 24*31)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit", 10, 1, 100)
+	if !ok {
+		return
+	}
+
+	report, err := h.loginStatsService.Report(hours, limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// RevokeAllTokens revokes sessions and access tokens in bulk
+// @Summary      Revoke tokens
+// @Description  Revoke the sessions and access tokens issued at or before issued_before (default now) of the listed users, or of every user without user_ids, e.g. after a credential leak. Affected users must sign in again. Revoking every user's tokens also denies every machine client's access tokens; API keys are not revoked, and the audit entry says so (admin only).
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                      false  "Request signature, required when ADMIN_SIGNING_SECRET is set"
+// @Param        request      body      models.RevokeTokensRequest  false  "Users and cutoff"
+// @Success      200          {object}  response.Response{data=models.TokenRevocation}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/security/revoke-all-tokens [post]
+func (h *SecurityHandler) RevokeAllTokens(c *gin.Context) {
+	adminID, ok := h.adminID(c)
+	if !ok {
+		return
+	}
+
+	// An empty body revokes every token issued so far
+	var req models.RevokeTokensRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	revocation, err := h.tokenRevocationService.RevokeTokens(adminID, &req, clientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, revocation)
+}
+
+// GetIncidentMode reports the state of incident mode
+// @Summary      Get incident mode
+// @Description  Report whether security incident mode is on and the lifetime of access tokens issued now (admin only)
+// @Tags         admin
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.IncidentMode}
+// @Failure      401  {object}  response.Response
+// @Failure      403  {object}  response.Response
+// @Router       /admin/security/incident-mode [get]
+func (h *SecurityHandler) GetIncidentMode(c *gin.Context) {
+	response.Success(c, h.tokenRevocationService.IncidentMode())
+}
+
+// UpdateIncidentMode turns incident mode on or off for every instance
+// @Summary      Update incident mode
+// @Description  Turn security incident mode on or off for every instance. Turning it on revokes every session and access token issued so far, the admin's own and machine client tokens included but not API keys, and access tokens issued while it is on last INCIDENT_ACCESS_TOKEN_TTL (admin only).
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string                            false  "Request signature, required when ADMIN_SIGNING_SECRET is set"
+// @Param        settings     body      models.UpdateIncidentModeRequest  true   "Incident mode"
+// @Success      200          {object}  response.Response{data=models.IncidentMode}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/security/incident-mode [put]
+func (h *SecurityHandler) UpdateIncidentMode(c *gin.Context) {
+	adminID, ok := h.adminID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateIncidentModeRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.Enabled == nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	incident, err := h.tokenRevocationService.SetIncidentMode(adminID, *req.Enabled, clientInfo(c))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, incident)
+}
+
+// adminID gets the ID of the authenticated admin, responding 401 when it is missing
+func (h *SecurityHandler) adminID(c *gin.Context) (uuid.UUID, bool) {
+	adminID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return uuid.Nil, false
+	}
+
+	adminUUID, ok := adminID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return uuid.Nil, false
+	}
+	return adminUUID, true
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits of the changes returned by one sync request
+const (
+	defaultSyncLimit = 500
+	maxSyncLimit     = 1000
+)
+
+// SyncHandler handles the change feed of offline-capable clients
+type SyncHandler struct {
+	syncService models.SyncService
+}
+
+// NewSyncHandler creates a new sync handler
+func NewSyncHandler(syncService models.SyncService) *SyncHandler {
+	return &SyncHandler{
+		syncService: syncService,
+	}
+}
+
+// Posts lists the posts created, updated and deleted since a checkpoint
+// @Summary      Sync posts
+// @Description  List the IDs of posts created, updated and deleted (or archived) since a checkpoint, oldest change first, so clients download only what changed. Pass the returned cursor as since on the next call; while has_more is true, more changes are waiting. Without since, every post is listed; posts the user may not list at their visibility (unlisted, private or followers-only posts of authors they do not follow) are left out; a since older than DB_TOMBSTONE_RETENTION_DAYS returns 410, as deletions before it are no longer tracked.
+// @Tags         sync
+// @Produce      json
+// @Security     BearerAuth
+// @Param        since  query     string  false  "RFC 3339 timestamp or cursor of an earlier sync"
+// @Param        limit  query     int     false  "Maximum number of changes"  default(500)
+// @Success      200    {object}  response.Response{data=models.SyncChanges}
+// @Failure      400    {object}  response.Response
+// @Failure      401    {object}  response.Response
+// @Failure      410    {object}  response.Response
+// @Failure      500    {object}  response.Response
+// @Router       /sync/posts [get]
+func (h *SyncHandler) Posts(c *gin.Context) {
+	limit, ok := queryInt(c, "limit", defaultSyncLimit, 1, maxSyncLimit)
+	if !ok {
+		return
+	}
+
+	changes, err := h.syncService.PostChanges(c.Query("since"), viewerID(c), limit)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, changes)
+}
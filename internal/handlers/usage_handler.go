@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UsageHandler handles API usage requests
+type UsageHandler struct {
+	usageService models.APIUsageService
+}
+
+// NewUsageHandler creates a new usage handler
+func NewUsageHandler(usageService models.APIUsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+// usagePeriodsParam reads the granularity and periods query parameters of a usage report:
+// up to 366 days (30 by default) or 24 months (12 by default)
+func usagePeriodsParam(c *gin.Context) (string, int, bool) {
+	granularity, ok := queryEnum(c, "granularity", models.UsageDaily, models.UsageDaily, models.UsageMonthly)
+	if !ok {
+		return "", 0, false
+	}
+
+	def, hi := 30, 366
+	if granularity == models.UsageMonthly {
+		def, hi = 12, 24
+	}
+	periods, ok := queryInt(c, "periods", def, 1, hi)
+	if !ok {
+		return "", 0, false
+	}
+
+	return granularity, periods, true
+}
+
+// APIKey reports the usage of one of the current user's API keys
+// @Summary      API key usage
+// @Description  Report the requests made with one of the current user's API keys per day or month and route, newest first, over the last periods including the current one. Days and months are in UTC, and counts lag behind by up to USAGE_FLUSH_INTERVAL.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id           path      string  true   "API key ID"
+// @Param        granularity  query     string  false  "daily or monthly"  default(daily)
+// @Param        periods      query     int     false  "Days (1-366, default 30) or months (1-24, default 12) to cover"
+// @Success      200          {object}  response.Response{data=models.APIUsageReport}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /users/api-keys/{id}/usage [get]
+func (h *UsageHandler) APIKey(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	id, ok := pathUUID(c, "id")
+	if !ok {
+		return
+	}
+	granularity, periods, ok := usagePeriodsParam(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.usageService.GetAPIKeyUsage(id, userUUID, granularity, periods)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, report)
+}
+
+// List lists the API usage of every caller
+// @Summary      API usage
+// @Description  List the requests of every user, API key and OAuth client per day or month and route, newest first and most requested first within a period, over the last periods including the current one (admin only). Filter by user_id, api_key_id or client_id. Days and months are in UTC, and counts lag behind by up to USAGE_FLUSH_INTERVAL.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        granularity  query     string  false  "daily or monthly"  default(daily)
+// @Param        periods      query     int     false  "Days (1-366, default 30) or months (1-24, default 12) to cover"
+// @Param        user_id      query     string  false  "Only this user's requests"
+// @Param        api_key_id   query     string  false  "Only requests made with this API key"
+// @Param        client_id    query     string  false  "Only this OAuth client's requests"
+// @Param        page         query     int     false  "Page number"  default(1)
+// @Param        per_page     query     int     false  "Items per page"  default(10)
+// @Success      200          {object}  response.PaginatedResponse{data=[]models.APIUsage}
+// @Failure      400          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/usage [get]
+func (h *UsageHandler) List(c *gin.Context) {
+	granularity, periods, ok := usagePeriodsParam(c)
+	if !ok {
+		return
+	}
+	userID, ok := queryUUID(c, "user_id")
+	if !ok {
+		return
+	}
+	apiKeyID, ok := queryUUID(c, "api_key_id")
+	if !ok {
+		return
+	}
+	paging, ok := paginationParams(c)
+	if !ok {
+		return
+	}
+
+	filter := &models.APIUsageFilter{
+		Granularity: granularity,
+		UserID:      userID,
+		APIKeyID:    apiKeyID,
+		ClientID:    c.Query("client_id"),
+	}
+	usage, total, err := h.usageService.ListUsage(filter, periods, paging.Page, paging.PerPage)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Paginated(c, usage, paging.meta(total))
+}
@@ -27,7 +27,7 @@ func NewUserHandler(userService models.UserService) *UserHandler {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  response.Response{data=models.User}
+// @Success      200  {object}  response.Response{data=dto.UserResponse}
 // @Failure      401  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
@@ -51,7 +51,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, user)
+	response.Success(c, userResponse(c, user))
 }
 
 // GetMe gets the current user's information
@@ -61,7 +61,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Success      200  {object}  response.Response{data=models.User}
+// @Success      200  {object}  response.Response{data=dto.UserResponse}
 // @Failure      401  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
@@ -85,7 +85,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, user)
+	response.Success(c, userResponse(c, user))
 }
 
 // UpdateProfile updates the current user's profile
@@ -96,7 +96,7 @@ func (h *UserHandler) GetMe(c *gin.Context) {
 // @Produce      json
 // @Security     BearerAuth
 // @Param        request  body      models.UpdateUserRequest  true  "User update data"
-// @Success      200      {object}  response.Response{data=models.User}
+// @Success      200      {object}  response.Response{data=dto.UserResponse}
 // @Failure      400      {object}  response.Response
 // @Failure      401      {object}  response.Response
 // @Failure      500      {object}  response.Response
@@ -126,7 +126,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	response.SuccessWithMessage(c, "Profile updated successfully", user)
+	response.SuccessWithMessage(c, "Profile updated successfully", userResponse(c, user))
 }
 
 // DeleteProfile deletes the current user's account
@@ -138,6 +138,7 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 // @Security     BearerAuth
 // @Success      200  {object}  response.Response
 // @Failure      401  {object}  response.Response
+// @Failure      409  {object}  response.Response
 // @Failure      500  {object}  response.Response
 // @Router       /users/profile [delete]
 func (h *UserHandler) DeleteProfile(c *gin.Context) {
@@ -153,7 +154,7 @@ func (h *UserHandler) DeleteProfile(c *gin.Context) {
 		return
 	}
 
-	err := h.userService.DeleteUser(userUUID)
+	err := h.userService.DeleteUser(userUUID, clientInfo(c))
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -162,27 +163,230 @@ func (h *UserHandler) DeleteProfile(c *gin.Context) {
 	response.SuccessWithMessage(c, "User deleted successfully", nil)
 }
 
-// ActivateUser activates a user account
-// @Summary      Activate user account
-// @Description  Activate a user account by ID
+// ListSessions lists the current user's active sessions
+// @Summary      List sessions
+// @Description  List the authenticated user's active sessions with the IP address, user agent and location they were issued to
 // @Tags         users
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        id   path      string  true  "User ID"
-// @Success      200  {object}  response.Response
+// @Success      200  {object}  response.Response{data=[]models.RefreshToken}
+// @Failure      401  {object}  response.Response
+// @Failure      500  {object}  response.Response
+// @Router       /users/sessions [get]
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	sessions, err := h.userService.ListSessions(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, sessions)
+}
+
+// ChangePassword changes the current user's password
+// @Summary      Change password
+// @Description  Change the authenticated user's password. Clears a forced password reset and signs out other sessions.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.ChangePasswordRequest  true  "Password change data"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/password [put]
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	claimsInterface, exists := c.Get("claims")
+	if !exists {
+		response.Unauthorized(c, "Token claims not found")
+		return
+	}
+
+	claims, ok := claimsInterface.(*models.TokenClaims)
+	if !ok {
+		response.Unauthorized(c, "Invalid token claims")
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if err := h.userService.ChangePassword(userUUID, claims.TokenID, &req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Password changed successfully", nil)
+}
+
+// RequestParentalConsent sends a parental consent link for the current user
+// @Summary      Request parental consent
+// @Description  Email a consent link to a parent of the authenticated user, whose account cannot publish posts or be found by its profile until a parent opens it. Only for accounts under the parental consent age.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.ParentalConsentRequest  true  "Parent email"
+// @Success      200      {object}  response.Response
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      409      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/parental-consent [post]
+func (h *UserHandler) RequestParentalConsent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.ParentalConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	if err := h.userService.RequestParentalConsent(userUUID, &req); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Parental consent requested", nil)
+}
+
+// GetPreferences gets the current user's preferences
+// @Summary      Get preferences
+// @Description  Get the authenticated user's personalization settings
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  response.Response{data=models.UserPreferences}
 // @Failure      401  {object}  response.Response
 // @Failure      404  {object}  response.Response
 // @Failure      500  {object}  response.Response
-// @Router       /users/{id}/activate [put]
-func (h *UserHandler) ActivateUser(c *gin.Context) {
-	userID, err := uuid.Parse(c.Param("id"))
+// @Router       /users/preferences [get]
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	prefs, err := h.userService.GetPreferences(userUUID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, prefs)
+}
+
+// UpdatePreferences updates the current user's preferences
+// @Summary      Update preferences
+// @Description  Replace the authenticated user's personalization settings. Posts in preferred_languages are listed first in the default post feed, in no particular order among themselves.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        request  body      models.UpdatePreferencesRequest  true  "Preferences"
+// @Success      200      {object}  response.Response{data=models.UserPreferences}
+// @Failure      400      {object}  response.Response
+// @Failure      401      {object}  response.Response
+// @Failure      404      {object}  response.Response
+// @Failure      500      {object}  response.Response
+// @Router       /users/preferences [put]
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		response.Unauthorized(c, "User not authenticated")
+		return
+	}
+
+	userUUID, ok := userID.(uuid.UUID)
+	if !ok {
+		response.Unauthorized(c, "Invalid user ID")
+		return
+	}
+
+	var req models.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "Invalid request data")
+		return
+	}
+
+	prefs, err := h.userService.UpdatePreferences(userUUID, &req)
 	if err != nil {
-		response.BadRequest(c, "Invalid user ID")
+		response.Error(c, err)
+		return
+	}
+
+	response.SuccessWithMessage(c, "Preferences updated successfully", prefs)
+}
+
+// ActivateUser activates a user account
+// @Summary      Activate user account
+// @Description  Activate a user account by ID (admin only)
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id           path      string  true   "User ID"
+// @Success      200          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/users/{id}/activate [put]
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	userID, ok := pathUUID(c, "id")
+	if !ok {
 		return
 	}
 
-	err = h.userService.ActivateUser(userID)
+	err := h.userService.ActivateUser(userID)
 	if err != nil {
 		response.Error(c, err)
 		return
@@ -193,25 +397,26 @@ func (h *UserHandler) ActivateUser(c *gin.Context) {
 
 // DeactivateUser deactivates a user account
 // @Summary      Deactivate user account
-// @Description  Deactivate a user account by ID
-// @Tags         users
+// @Description  Deactivate a user account by ID, revoking its sessions and access tokens (admin only)
+// @Tags         admin
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
-// @Param        id   path      string  true  "User ID"
-// @Success      200  {object}  response.Response
-// @Failure      401  {object}  response.Response
-// @Failure      404  {object}  response.Response
-// @Failure      500  {object}  response.Response
-// @Router       /users/{id}/deactivate [put]
+// @Param        X-Signature  header    string  false  "Request signature, required on admin routes when ADMIN_SIGNING_SECRET is set"
+// @Param        id           path      string  true   "User ID"
+// @Success      200          {object}  response.Response
+// @Failure      401          {object}  response.Response
+// @Failure      403          {object}  response.Response
+// @Failure      404          {object}  response.Response
+// @Failure      500          {object}  response.Response
+// @Router       /admin/users/{id}/deactivate [put]
 func (h *UserHandler) DeactivateUser(c *gin.Context) {
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		response.BadRequest(c, "Invalid user ID")
+	userID, ok := pathUUID(c, "id")
+	if !ok {
 		return
 	}
 
-	err = h.userService.DeactivateUser(userID)
+	err := h.userService.DeactivateUser(userID)
 	if err != nil {
 		response.Error(c, err)
 		return
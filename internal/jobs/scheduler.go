@@ -0,0 +1,73 @@
+package jobs
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of background work run periodically by the scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler runs registered jobs on fixed intervals
+type Scheduler struct {
+	mutex sync.Mutex
+	jobs  []Job
+}
+
+// NewScheduler creates a new job scheduler
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job to the scheduler. Jobs with a non-positive interval are disabled.
+func (s *Scheduler) Register(name string, interval time.Duration, run func() error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.jobs = append(s.jobs, Job{Name: name, Interval: interval, Run: run})
+}
+
+// Start runs every registered job in its own goroutine until stop is closed.
+// A job never overlaps with itself; a run that takes longer than the interval delays the next one.
+func (s *Scheduler) Start(stop <-chan struct{}) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, job := range s.jobs {
+		if job.Interval <= 0 {
+			continue
+		}
+
+		go func(job Job) {
+			ticker := time.NewTicker(job.Interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					runJob(job)
+				case <-stop:
+					return
+				}
+			}
+		}(job)
+	}
+}
+
+// runJob runs a job once, logging failures and recovering from panics so the schedule keeps going
+func runJob(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Job %s panicked: %v", job.Name, r)
+		}
+	}()
+
+	if err := job.Run(); err != nil {
+		log.Printf("Job %s failed: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/pkg/testutil"
+)
+
+func TestSchedulerStops(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+
+	var runs, slowRuns atomic.Int32
+	slowStarted := make(chan struct{}, 1)
+	s := NewScheduler()
+	s.Register("fast", time.Millisecond, func() error {
+		runs.Add(1)
+		return nil
+	})
+	s.Register("panics", time.Millisecond, func() error {
+		panic("job failed")
+	})
+	// A run in progress when stop is closed finishes before its goroutine exits
+	s.Register("slow", time.Millisecond, func() error {
+		if slowRuns.Add(1) == 1 {
+			slowStarted <- struct{}{}
+		}
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	})
+	s.Register("disabled", 0, func() error {
+		t.Error("disabled job ran")
+		return nil
+	})
+
+	stop := make(chan struct{})
+	s.Start(stop)
+	<-slowStarted
+	time.Sleep(5 * time.Millisecond)
+	close(stop)
+
+	if runs.Load() == 0 {
+		t.Error("fast job never ran")
+	}
+}
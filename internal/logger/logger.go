@@ -1,9 +1,15 @@
 package logger
 
 import (
+	"fmt"
 	"os"
 	"time"
 
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/redact"
+	"go-backend-api/internal/pkg/response"
+
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
 )
@@ -13,33 +19,92 @@ type Logger struct {
 	*logrus.Logger
 }
 
+// Levels are the names of the log levels that can be set
+var Levels = []string{"debug", "info", "warn", "error"}
+
 // NewLogger creates a new logger instance
 func NewLogger(level string) *Logger {
 	log := logrus.New()
 
-	// Set log level
+	// Set log level, defaulting to info
+	l := &Logger{Logger: log}
+	if err := l.SetLevelName(level); err != nil {
+		log.SetLevel(logrus.InfoLevel)
+	}
+
+	// Set JSON formatter for structured logging, redacting personal data and credentials
+	log.SetFormatter(&redactingFormatter{Formatter: &logrus.JSONFormatter{
+		TimestampFormat: time.RFC3339,
+	}})
+
+	// Set output to stdout
+	log.SetOutput(os.Stdout)
+
+	return l
+}
+
+// redactingFormatter redacts the message and the string and error fields of entries
+// before formatting them
+type redactingFormatter struct {
+	logrus.Formatter
+}
+
+// Format redacts an entry and formats it
+func (f *redactingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	redacted := *entry
+	redacted.Message = redact.String(entry.Message)
+	redacted.Data = make(logrus.Fields, len(entry.Data))
+	for key, value := range entry.Data {
+		switch v := value.(type) {
+		case string:
+			value = redact.String(v)
+		case error:
+			value = redact.Error(v)
+		}
+		redacted.Data[key] = value
+	}
+	return f.Formatter.Format(&redacted)
+}
+
+// SetLevelName sets the log level by name, one of Levels. It can be called at any time.
+func (l *Logger) SetLevelName(level string) error {
 	switch level {
 	case "debug":
-		log.SetLevel(logrus.DebugLevel)
+		l.SetLevel(logrus.DebugLevel)
 	case "info":
-		log.SetLevel(logrus.InfoLevel)
+		l.SetLevel(logrus.InfoLevel)
 	case "warn":
-		log.SetLevel(logrus.WarnLevel)
+		l.SetLevel(logrus.WarnLevel)
 	case "error":
-		log.SetLevel(logrus.ErrorLevel)
+		l.SetLevel(logrus.ErrorLevel)
 	default:
-		log.SetLevel(logrus.InfoLevel)
+		return fmt.Errorf("unknown log level %q", level)
 	}
+	return nil
+}
 
-	// Set JSON formatter for structured logging
-	log.SetFormatter(&logrus.JSONFormatter{
-		TimestampFormat: time.RFC3339,
-	})
-
-	// Set output to stdout
-	log.SetOutput(os.Stdout)
+// LevelName returns the name of the log level
+func (l *Logger) LevelName() string {
+	if l.GetLevel() == logrus.WarnLevel {
+		return "warn"
+	}
+	return l.GetLevel().String()
+}
 
-	return &Logger{Logger: log}
+// Query logs an SQL statement reported by the database query logger: at info level,
+// or warn when it failed
+func (l *Logger) Query(event database.QueryEvent) {
+	entry := l.WithFields(logrus.Fields{
+		"query":       event.Query,
+		"args":        event.Args,
+		"duration_ms": float64(event.Duration.Microseconds()) / 1000,
+		"rows":        event.Rows,
+	})
+	if event.Err != nil {
+		entry.WithError(event.Err).Warn("SQL query failed")
+		return
+	}
+	entry.Info("SQL query")
 }
 
 // GinLogger returns a gin.HandlerFunc for logging HTTP requests
@@ -71,7 +136,8 @@ func (l *Logger) GinRecovery() gin.HandlerFunc {
 			"method": c.Request.Method,
 		}).Error("Panic recovered")
 
-		c.AbortWithStatus(500)
+		response.Error(c, errors.ErrInternal)
+		c.Abort()
 	})
 }
 
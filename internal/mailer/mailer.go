@@ -0,0 +1,84 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+)
+
+// Message represents an outgoing email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends emails
+type Mailer interface {
+	Send(msg *Message) error
+}
+
+// Config holds mailer configuration
+type Config struct {
+	Driver       string // "log" or "smtp"
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// New creates a mailer for the configured driver
+func New(cfg Config) Mailer {
+	if cfg.Driver == "smtp" {
+		return &smtpMailer{cfg: cfg}
+	}
+	return &logMailer{from: cfg.From}
+}
+
+// logMailer writes emails to the application log instead of sending them (development).
+// It stands in for a mailbox, so it writes to its own unredacted logger: the links and codes
+// in emails would otherwise be redacted.
+type logMailer struct {
+	from string
+}
+
+// mailLog is where logMailer writes emails
+var mailLog = log.New(os.Stderr, "", log.LstdFlags)
+
+// Send logs the email
+func (m *logMailer) Send(msg *Message) error {
+	mailLog.Printf("[mailer] from=%s to=%s subject=%q\n%s", m.from, msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// smtpMailer sends emails through an SMTP server
+type smtpMailer struct {
+	cfg Config
+}
+
+// Send sends the email using SMTP with PLAIN authentication when credentials are configured
+func (m *smtpMailer) Send(msg *Message) error {
+	addr := m.cfg.SMTPHost + ":" + m.cfg.SMTPPort
+
+	var auth smtp.Auth
+	if m.cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTPUsername, m.cfg.SMTPPassword, m.cfg.SMTPHost)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", m.cfg.From)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	b.WriteString(msg.Body)
+
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, []byte(b.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,34 @@
+package mailer
+
+import "log"
+
+// Suppressor tells which addresses must not be mailed, such as those that bounced
+type Suppressor interface {
+	IsSuppressed(address string) (bool, error)
+}
+
+// suppressingMailer drops the emails of suppressed addresses before they reach the next mailer
+type suppressingMailer struct {
+	next       Mailer
+	suppressor Suppressor
+}
+
+// WithSuppression wraps a mailer so that emails to the addresses suppressor reports are
+// dropped. Callers see them as sent: retrying would only be suppressed again.
+func WithSuppression(next Mailer, suppressor Suppressor) Mailer {
+	return &suppressingMailer{next: next, suppressor: suppressor}
+}
+
+// Send sends the email unless its recipient is suppressed. When suppression cannot be
+// checked the email is sent, since a bounce costs less than a lost sign-in code.
+func (m *suppressingMailer) Send(msg *Message) error {
+	suppressed, err := m.suppressor.IsSuppressed(msg.To)
+	if err != nil {
+		log.Printf("[mailer] failed to check suppression of %q, sending anyway: %v", msg.Subject, err)
+	}
+	if suppressed {
+		log.Printf("[mailer] suppressed %q to an undeliverable address", msg.Subject)
+		return nil
+	}
+	return m.next.Send(msg)
+}
@@ -0,0 +1,124 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"text/template"
+)
+
+//go:embed templates/*.txt
+var templateFiles embed.FS
+
+// templates holds the parsed default email templates.
+// Each file starts with a "Subject: ..." line followed by a blank line and the body.
+var templates = template.Must(template.ParseFS(templateFiles, "templates/*.txt"))
+
+// Overrides replaces default templates, e.g. with versions edited by admins
+type Overrides interface {
+	// Template returns the subject and body replacing the named default template, and false
+	// to use the default
+	Template(name string) (subject, body string, ok bool)
+}
+
+// overrides is consulted by Render before the default templates
+var overrides atomic.Pointer[Overrides]
+
+// SetOverrides makes Render use the templates of o over the defaults, or only the defaults with nil
+func SetOverrides(o Overrides) {
+	if o == nil {
+		overrides.Store(nil)
+		return
+	}
+	overrides.Store(&o)
+}
+
+// Names returns the names of the default templates, sorted
+func Names() []string {
+	files, _ := fs.Glob(templateFiles, "templates/*.txt")
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(strings.TrimPrefix(file, "templates/"), ".txt"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the subject and body of a default template, and false if there is none
+func Default(name string) (subject, body string, ok bool) {
+	content, err := templateFiles.ReadFile("templates/" + name + ".txt")
+	if err != nil {
+		return "", "", false
+	}
+	subject, body, _ = strings.Cut(string(content), "\n\n")
+	return strings.TrimPrefix(subject, "Subject: "), body, true
+}
+
+// Parse checks that a subject and body form a valid template
+func Parse(name, subject, body string) error {
+	_, err := parse(name, subject, body)
+	return err
+}
+
+// parse parses a subject and body as one template, in the format of the default files
+func parse(name, subject, body string) (*template.Template, error) {
+	if strings.Contains(subject, "\n") {
+		return nil, fmt.Errorf("subject of email template %s spans several lines", name)
+	}
+	return template.New(name).Parse("Subject: " + subject + "\n\n" + body)
+}
+
+// Render renders the named template (file name without extension) into a message for the
+// recipient. An override that fails to render is logged and the default is used instead.
+func Render(name, to string, data interface{}) (*Message, error) {
+	if o := overrides.Load(); o != nil {
+		if subject, body, ok := (*o).Template(name); ok {
+			msg, err := RenderText(name, subject, body, to, data)
+			if err == nil {
+				return msg, nil
+			}
+			log.Printf("[mailer] falling back to the default %s template: %v", name, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name+".txt", data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+
+	return message(name, to, buf.String())
+}
+
+// RenderText renders a template given as a subject and body into a message for the recipient
+func RenderText(name, subject, body, to string, data interface{}) (*Message, error) {
+	tmpl, err := parse(name, subject, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse email template %s: %w", name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render email template %s: %w", name, err)
+	}
+
+	return message(name, to, buf.String())
+}
+
+// message splits a rendered template into the subject line and the body of a message
+func message(name, to, rendered string) (*Message, error) {
+	subject, body, found := strings.Cut(rendered, "\n\n")
+	if !found || !strings.HasPrefix(subject, "Subject: ") {
+		return nil, fmt.Errorf("email template %s is missing a subject line", name)
+	}
+
+	return &Message{
+		To:      to,
+		Subject: strings.TrimPrefix(subject, "Subject: "),
+		Body:    body,
+	}, nil
+}
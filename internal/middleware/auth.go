@@ -1,27 +1,52 @@
 package middleware
 
 import (
+	"net/http"
 	"strings"
 
+	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
 	"go-backend-api/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
-// AuthMiddleware validates JWT tokens
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// APIKeyHeader is the request header carrying an API key
+const APIKeyHeader = "X-API-Key"
+
+// AuthMiddleware validates JWT tokens, and API keys when apiKeys is not nil.
+// API keys are accepted in the X-API-Key header or as a bearer token.
+func AuthMiddleware(jwtManager *auth.JWTManager, apiKeys models.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
+		if apiKeys != nil {
+			key := c.GetHeader(APIKeyHeader)
+			if key == "" && strings.HasPrefix(authHeader, "Bearer "+models.APIKeyPrefix) {
+				key = strings.TrimPrefix(authHeader, "Bearer ")
+			}
+			if key != "" {
+				claims, err := apiKeys.Authenticate(key)
+				if err != nil {
+					response.Error(c, err)
+					c.Abort()
+					return
+				}
+				setClaims(c, claims)
+				c.Next()
+				return
+			}
+		}
+
 		if authHeader == "" {
-			response.Unauthorized(c, "Authorization header required")
+			response.Error(c, errors.ErrAuthRequired)
 			c.Abort()
 			return
 		}
 
 		// Check if the header starts with "Bearer "
 		if !strings.HasPrefix(authHeader, "Bearer ") {
-			response.Unauthorized(c, "Invalid authorization header format")
+			response.Error(c, errors.ErrInvalidAuthHeader)
 			c.Abort()
 			return
 		}
@@ -32,16 +57,72 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		// Validate the token
 		claims, err := jwtManager.ValidateAccessToken(tokenString)
 		if err != nil {
-			response.Unauthorized(c, "Invalid token")
+			response.Error(c, errors.ErrInvalidToken)
 			c.Abort()
 			return
 		}
 
-		// Set user information in context
+		setClaims(c, claims)
+
+		c.Next()
+	}
+}
+
+// setClaims sets the authenticated user's information in the request context.
+// Machine client tokens have no user, so only client_id is set for them.
+func setClaims(c *gin.Context, claims *models.TokenClaims) {
+	if claims.IsClient() {
+		c.Set("client_id", claims.ClientID)
+	} else {
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
-		c.Set("token_id", claims.TokenID)
-		c.Set("claims", claims)
+		c.Set("role", claims.Role)
+	}
+	c.Set("token_id", claims.TokenID)
+	c.Set("scopes", claims.Scopes)
+	c.Set("custom_claims", claims.Custom)
+	c.Set("claims", claims)
+}
+
+// RequireScope allows only requests whose token or API key grants every given scope.
+// It must be used after AuthMiddleware.
+func RequireScope(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		tokenClaims, ok := claims.(*models.TokenClaims)
+		if !ok {
+			response.Error(c, errors.ErrAuthRequired)
+			c.Abort()
+			return
+		}
+
+		for _, scope := range scopes {
+			if !tokenClaims.HasScope(scope) {
+				response.Error(c, errors.NewAppErrorWithReason(http.StatusForbidden, errors.ErrInsufficientScope.Reason, "Missing required scope: "+scope))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// RequireAdmin allows only authenticated users with the admin role, and machine clients
+// granted the users:admin scope. It must be used after AuthMiddleware and
+// TokenDenylistMiddleware, which rejects the tokens of revoked clients and sets the user's
+// current role.
+func RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, _ := c.Get("claims")
+		tokenClaims, _ := claims.(*models.TokenClaims)
+		isAdminClient := tokenClaims != nil && tokenClaims.IsClient() && tokenClaims.HasScope(models.ScopeUsersAdmin)
+
+		if c.GetString("role") != models.RoleAdmin && !isAdminClient {
+			response.Error(c, errors.ErrAdminRequired)
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -10,11 +10,14 @@ import (
 func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization")
+		c.Header("Access-Control-Allow-Methods", "GET, HEAD, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Accept, Authorization, X-Device-ID, X-API-Key, Prefer")
 		c.Header("Access-Control-Allow-Credentials", "true")
+		// Unwrapped listings carry their pagination in headers
+		c.Header("Access-Control-Expose-Headers", "X-Page, X-Per-Page, X-Total-Count, X-Total-Pages, Preference-Applied")
 
 		if c.Request.Method == "OPTIONS" {
+			CompleteAllowHeader(c)
 			c.AbortWithStatus(http.StatusOK)
 			return
 		}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HeadAsGet serves HEAD requests with the matching GET route, so every GET endpoint
+// answers HEAD without registering it twice. net/http discards the body for HEAD
+// requests, so clients only receive the status and headers.
+func HeadAsGet(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			r = r.Clone(r.Context())
+			r.Method = http.MethodGet
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// CompleteAllowHeader adds HEAD (served by GET routes, see HeadAsGet) and OPTIONS (answered
+// by CORS) to the Allow header gin sets for paths registered under other methods, and returns it.
+func CompleteAllowHeader(c *gin.Context) string {
+	header := c.Writer.Header().Get("Allow")
+	if header == "" {
+		return ""
+	}
+
+	allowed := strings.Split(header, ", ")
+	for _, method := range allowed {
+		if method == http.MethodGet {
+			allowed = append(allowed, http.MethodHead)
+			break
+		}
+	}
+	allowed = append(allowed, http.MethodOptions)
+
+	allow := strings.Join(allowed, ", ")
+	c.Header("Allow", allow)
+	return allow
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+)
+
+// errorReason returns the reason of the error response in w, enveloped or not
+func errorReason(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body struct {
+		Reason string `json:"reason"`
+		Error  *struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("error response is not JSON: %v: %s", err, w.Body.String())
+	}
+	if body.Error != nil {
+		return body.Error.Reason
+	}
+	return body.Reason
+}
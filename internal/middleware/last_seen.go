@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// lastSeenQueueSize is how many updates wait for the last-seen worker before new ones are dropped
+const lastSeenQueueSize = 1000
+
+// LastSeenRecorder records user activity on authenticated requests.
+// Writes are throttled per user in memory (and again in the database) so that a busy
+// client causes at most one update per throttle window. The in-memory throttle keeps at
+// most security.DefaultRateLimitMaxEntries users, evicting the least recently active, and
+// updates are written by a single background worker; updates arriving while its queue is
+// full are dropped and logged, as the next request after the window records them again.
+type LastSeenRecorder struct {
+	userRepo models.UserRepository
+	throttle time.Duration
+	limiter  *security.RateLimiter
+	queue    chan lastSeenUpdate
+}
+
+// lastSeenUpdate is a queued write of a user's last activity
+type lastSeenUpdate struct {
+	userID uuid.UUID
+	seenAt time.Time
+}
+
+// NewLastSeenRecorder creates a recorder writing at most one update per user per throttle
+func NewLastSeenRecorder(userRepo models.UserRepository, throttle time.Duration) *LastSeenRecorder {
+	return &LastSeenRecorder{
+		userRepo: userRepo,
+		throttle: throttle,
+		limiter:  security.NewRateLimiter(1, throttle, 1, 0),
+		queue:    make(chan lastSeenUpdate, lastSeenQueueSize),
+	}
+}
+
+// Start writes queued updates, and drops users idle for a while from the throttle, in the
+// background until stop is closed
+func (r *LastSeenRecorder) Start(stop <-chan struct{}) {
+	r.limiter.StartCleanup(5*time.Minute, stop)
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case update := <-r.queue:
+				if err := r.userRepo.UpdateLastSeen(update.userID, update.seenAt, r.throttle); err != nil {
+					log.Printf("Failed to update last seen for user %s: %v", update.userID, err)
+				}
+			}
+		}
+	}()
+}
+
+// Middleware queues an update of the authenticated user's last activity after the request.
+// It must be used after AuthMiddleware.
+func (r *LastSeenRecorder) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		userID, ok := c.Get("user_id")
+		if !ok {
+			return
+		}
+		userUUID, ok := userID.(uuid.UUID)
+		if !ok {
+			return
+		}
+
+		if !r.limiter.Allow(userUUID.String()) {
+			return
+		}
+		select {
+		case r.queue <- lastSeenUpdate{userID: userUUID, seenAt: time.Now()}:
+		default:
+			log.Printf("Last seen queue is full, dropping the update for user %s", userUUID)
+		}
+	}
+}
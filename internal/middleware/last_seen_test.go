@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/testutil"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// lastSeenRepo sends the users whose last activity is written; other UserRepository methods
+// are not used
+type lastSeenRepo struct {
+	models.UserRepository
+	updates chan uuid.UUID
+}
+
+func (r *lastSeenRepo) UpdateLastSeen(id uuid.UUID, seenAt time.Time, throttle time.Duration) error {
+	r.updates <- id
+	return nil
+}
+
+// lastSeenRouter serves requests authenticated as the user in the X-User header
+func lastSeenRouter(recorder *LastSeenRecorder) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		if id, err := uuid.Parse(c.GetHeader("X-User")); err == nil {
+			c.Set("user_id", id)
+		}
+		c.Next()
+	}, recorder.Middleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func requestAs(r http.Handler, userID uuid.UUID) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-User", userID.String())
+	r.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func TestLastSeenIsWrittenOncePerThrottleWindow(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+	stop := make(chan struct{})
+	defer close(stop)
+
+	repo := &lastSeenRepo{updates: make(chan uuid.UUID, 10)}
+	recorder := NewLastSeenRecorder(repo, time.Hour)
+	recorder.Start(stop)
+	r := lastSeenRouter(recorder)
+
+	alice, bob := uuid.New(), uuid.New()
+	for range 5 {
+		requestAs(r, alice)
+		requestAs(r, bob)
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	written := map[uuid.UUID]int{}
+	for range 2 {
+		select {
+		case id := <-repo.updates:
+			written[id]++
+		case <-time.After(time.Second):
+			t.Fatalf("got updates %v, want one per user", written)
+		}
+	}
+	if written[alice] != 1 || written[bob] != 1 {
+		t.Errorf("got updates %v, want one per user", written)
+	}
+	select {
+	case id := <-repo.updates:
+		t.Errorf("unexpected update for %s within the throttle window", id)
+	case <-time.After(10 * time.Millisecond):
+	}
+}
+
+func TestLastSeenUpdatesAreDroppedWhenTheQueueIsFull(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+
+	// Without a worker nothing drains the queue, and requests must neither block nor start
+	// goroutines of their own
+	repo := &lastSeenRepo{updates: make(chan uuid.UUID)}
+	recorder := NewLastSeenRecorder(repo, time.Hour)
+	r := lastSeenRouter(recorder)
+	for range lastSeenQueueSize + 10 {
+		requestAs(r, uuid.New())
+	}
+	if len(recorder.queue) != lastSeenQueueSize {
+		t.Errorf("queued %d updates, want %d", len(recorder.queue), lastSeenQueueSize)
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"go-backend-api/internal/pkg/links"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreferLinks is the Prefer header preference of clients that want _links sections
+const PreferLinks = "links"
+
+// LinksMiddleware makes the responses to requests sent with "Prefer: links" carry _links
+// sections built by builder: posts and users link to related resources, and listings to
+// their previous and next pages.
+func LinksMiddleware(builder *links.Builder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Vary(c, "Prefer")
+		if response.Prefers(c, PreferLinks) {
+			links.WithBuilder(c, builder)
+			c.Writer.Header().Add("Preference-Applied", PreferLinks)
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,142 @@
+package middleware
+
+import (
+	"mime"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/metrics"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Media types of API representations. Clients select a version either by URL (/api/v1)
+// or, equivalently, by asking for the vendor media type of that version.
+const (
+	JSONMediaType     = "application/json"
+	VendorMediaTypeV1 = "application/vnd.gobackend.v1+json"
+)
+
+// MediaTypeKey is the context key of the representation negotiated for a request
+const MediaTypeKey = "media_type"
+
+// vendorMediaType matches the vendor media types of every API version
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.gobackend\.v(\d+)\+json$`)
+
+// supportedVendorTypes maps the vendor media types served to themselves
+var supportedVendorTypes = map[string]bool{VendorMediaTypeV1: true}
+
+// acceptRange is a media range of an Accept header with its quality
+type acceptRange struct {
+	mediaType string
+	quality   float64
+}
+
+// MediaTypeMiddleware negotiates the representation of a request from its Accept header,
+// recording it in the route metrics and under MediaTypeKey. Clients asking for the vendor
+// media type get JSON responses labelled with it; clients asking only for vendor versions
+// that are not served get a 406, and request bodies labelled with one a 415. Other Accept
+// values get plain JSON, as before.
+func MediaTypeMiddleware(routeMetrics *metrics.RouteMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if contentType := c.ContentType(); vendorMediaType.MatchString(contentType) && !supportedVendorTypes[contentType] {
+			response.Error(c, errors.ErrUnsupportedMediaType.WithDetails("Send "+VendorMediaTypeV1+" or "+JSONMediaType))
+			c.Abort()
+			return
+		}
+
+		mediaType, ok := negotiateMediaType(c.GetHeader("Accept"))
+		if !ok {
+			response.Error(c, errors.ErrNotAcceptable.WithDetails("Accept "+VendorMediaTypeV1+" or "+JSONMediaType))
+			c.Abort()
+			return
+		}
+
+		c.Set(MediaTypeKey, mediaType)
+		response.Vary(c, "Accept")
+		routeMetrics.ObserveMediaType(mediaType)
+		if mediaType != JSONMediaType {
+			c.Writer = &mediaTypeWriter{ResponseWriter: c.Writer, mediaType: mediaType}
+		}
+		c.Next()
+	}
+}
+
+// negotiateMediaType picks the representation for an Accept header: the preferred supported
+// vendor media type, or JSON for ranges JSON falls under. Only when every acceptable range
+// names a vendor version that is not served is negotiation reported as failed.
+func negotiateMediaType(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return JSONMediaType, true
+	}
+
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		quality := 1.0
+		if q, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(q, 64); err != nil {
+				continue
+			}
+		}
+		if quality > 0 {
+			ranges = append(ranges, acceptRange{mediaType: mediaType, quality: quality})
+		}
+	}
+	sort.SliceStable(ranges, func(i, j int) bool { return ranges[i].quality > ranges[j].quality })
+
+	onlyUnsupported := len(ranges) > 0
+	for _, r := range ranges {
+		switch {
+		case supportedVendorTypes[r.mediaType]:
+			return r.mediaType, true
+		case vendorMediaType.MatchString(r.mediaType):
+			continue
+		case r.mediaType == JSONMediaType || r.mediaType == "application/*" || r.mediaType == "*/*":
+			return JSONMediaType, true
+		}
+		onlyUnsupported = false
+	}
+	// Other media types, e.g. text/html from browsers, have always been answered with JSON
+	return JSONMediaType, !onlyUnsupported
+}
+
+// mediaTypeWriter labels JSON responses with the negotiated vendor media type; responses of
+// other types, such as CSV exports, keep theirs
+type mediaTypeWriter struct {
+	gin.ResponseWriter
+	mediaType string
+}
+
+// relabel replaces a JSON Content-Type before the headers are written
+func (w *mediaTypeWriter) relabel() {
+	if w.Written() {
+		return
+	}
+	header := w.Header()
+	if mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type")); err == nil && mediaType == JSONMediaType {
+		header.Set("Content-Type", w.mediaType+"; charset=utf-8")
+	}
+}
+
+func (w *mediaTypeWriter) WriteHeaderNow() {
+	w.relabel()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *mediaTypeWriter) Write(data []byte) (int, error) {
+	w.relabel()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *mediaTypeWriter) WriteString(s string) (int, error) {
+	w.relabel()
+	return w.ResponseWriter.WriteString(s)
+}
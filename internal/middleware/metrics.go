@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"time"
+
+	"go-backend-api/internal/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// unmatchedRoute groups requests that matched no route so unknown paths do not
+// each get their own entry
+const unmatchedRoute = "(unmatched)"
+
+// RouteMetricsMiddleware records the latency and status of every request under its route template
+func RouteMetricsMiddleware(routeMetrics *metrics.RouteMetrics) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		routeMetrics.Observe(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
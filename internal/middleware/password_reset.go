@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PasswordResetMiddleware blocks authenticated requests from users who have been
+// required to change their password, except for the routes listed in allowedPaths.
+// The flag is read from the database so it applies to access tokens issued before the reset.
+// It must be used after AuthMiddleware.
+func PasswordResetMiddleware(userRepo models.UserRepository, allowedPaths ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedPaths))
+	for _, path := range allowedPaths {
+		allowed[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := allowed[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		userID, ok := c.Get("user_id")
+		if !ok {
+			c.Next()
+			return
+		}
+		userUUID, ok := userID.(uuid.UUID)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		mustChange, err := userRepo.MustChangePassword(userUUID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+		if mustChange {
+			response.Error(c, errors.ErrPasswordChangeRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
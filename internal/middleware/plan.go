@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// requestPlan returns the plan of the authenticated user, or "" for machine clients
+func requestPlan(c *gin.Context) string {
+	claims, _ := c.Get("claims")
+	tokenClaims, ok := claims.(*models.TokenClaims)
+	if !ok || tokenClaims.IsClient() {
+		return ""
+	}
+	if tokenClaims.Plan == "" {
+		return models.PlanFree
+	}
+	return tokenClaims.Plan
+}
+
+// PlanRateLimitMiddleware limits the requests of each user with the limiter of their plan, on
+// top of the per-client rate limit. Plans without a limiter, and machine clients, are not
+// limited. It must be used after AuthMiddleware.
+func PlanRateLimitMiddleware(limiters map[string]*security.RateLimiter) gin.HandlerFunc {
+	userKey := func(c *gin.Context) string {
+		userID, _ := c.Get("user_id")
+		id, _ := userID.(uuid.UUID)
+		return "plan:" + id.String()
+	}
+	limits := make(map[string]gin.HandlerFunc, len(limiters))
+	for plan, limiter := range limiters {
+		limits[plan] = limiter.MiddlewareBy(security.ThrottleScopePlan, userKey)
+	}
+
+	return func(c *gin.Context) {
+		if limit, ok := limits[requestPlan(c)]; ok {
+			limit(c)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireFeature allows only users whose plan includes feature. Machine clients have no plan
+// and are allowed. It must be used after AuthMiddleware.
+func RequireFeature(plans models.PlanService, feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		plan := requestPlan(c)
+		if plan != "" && !plans.Limits(plan).HasFeature(feature) {
+			response.Error(c, errors.ErrPlanFeatureRequired)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// PolicyAcceptanceMiddleware blocks authenticated requests from users who have not accepted
+// the latest mandatory version of a policy, except for the routes listed in allowedPaths.
+// Sandbox accounts are throwaways nobody signed up for, so they are not asked to accept.
+// It must be used after AuthMiddleware.
+func PolicyAcceptanceMiddleware(policyRepo models.PolicyRepository, allowedPaths ...string) gin.HandlerFunc {
+	allowed := make(map[string]struct{}, len(allowedPaths))
+	for _, path := range allowedPaths {
+		allowed[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := allowed[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		userID, ok := c.Get("user_id")
+		if !ok || c.GetString("role") == models.RoleSandbox {
+			c.Next()
+			return
+		}
+		userUUID, ok := userID.(uuid.UUID)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		pending, err := policyRepo.HasPending(userUUID)
+		if err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+		if pending {
+			response.Error(c, errors.ErrPolicyAcceptanceRequired.WithDetails("Accept the pending versions listed at /users/policies"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
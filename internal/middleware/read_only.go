@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadOnlyMode is a switch, flipped at runtime, that makes the API reject writes
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+// NewReadOnlyMode creates a read-only switch in the given state
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	mode := &ReadOnlyMode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+// Enabled reports whether writes are rejected
+func (m *ReadOnlyMode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// Set turns read-only mode on or off
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+// ReadOnlyMiddleware rejects write requests with 503 while read-only mode is on. Requests
+// with a safe method (GET, HEAD, OPTIONS) always pass, as do writes to routes under one of
+// allowedPrefixes. Requests matching no route pass through, so they still get a 404 or 405.
+func ReadOnlyMiddleware(mode *ReadOnlyMode, allowedPrefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !mode.Enabled() || isSafeMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			c.Next()
+			return
+		}
+		for _, prefix := range allowedPrefixes {
+			if strings.HasPrefix(route, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		response.Error(c, errors.ErrReadOnly)
+		c.Abort()
+	}
+}
+
+// isSafeMethod reports whether a method only reads
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"time"
+
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireSignedRequest rejects requests without a valid, unused signature of the signer in
+// the X-Signature header. It is meant for destructive admin endpoints, after AuthMiddleware
+// and RequireAdmin. A nil signer lets every request through; the server only runs without a
+// signing secret outside production.
+func RequireSignedRequest(signer *security.RequestSigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if signer == nil {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.Error(c, errors.NewAppError(http.StatusBadRequest, "Failed to read request body", err))
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		err = signer.Verify(c.GetHeader(security.SignatureHeader), c.Request.Method, c.Request.URL.RequestURI(), body, time.Now())
+		if err != nil {
+			var appErr *errors.AppError
+			switch {
+			case errors.As(err, &appErr):
+				// Recording the signature as used failed
+				response.Error(c, appErr)
+			case errors.Is(err, security.ErrSignatureMissing):
+				response.Error(c, errors.ErrSignatureRequired.WithDetails(err.Error()))
+			default:
+				response.Error(c, errors.ErrSignatureInvalid.WithDetails(err.Error()))
+			}
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SandboxQuotaMiddleware limits the requests of each sandbox account with limiter, on top of
+// the per-client rate limit; other accounts are not affected. It must be used after
+// AuthMiddleware.
+func SandboxQuotaMiddleware(limiter *security.RateLimiter) gin.HandlerFunc {
+	return limiter.MiddlewareBy(security.ThrottleScopeSandbox, func(c *gin.Context) string {
+		if c.GetString("role") != models.RoleSandbox {
+			return ""
+		}
+		userID, _ := c.Get("user_id")
+		id, _ := userID.(uuid.UUID)
+		return "sandbox:" + id.String()
+	})
+}
+
+// DenySandbox rejects sandbox accounts with 403 SANDBOX_RESTRICTED, on routes whose writes
+// other users would see. It must be used after AuthMiddleware.
+func DenySandbox() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") == models.RoleSandbox {
+			response.Error(c, errors.ErrSandboxRestricted)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,320 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// tokenStateRepo serves token states from memory; other UserRepository methods are not used
+type tokenStateRepo struct {
+	models.UserRepository
+	mu    sync.Mutex
+	state map[uuid.UUID]models.UserTokenState
+}
+
+func (r *tokenStateRepo) GetTokenState(id uuid.UUID) (*models.UserTokenState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.state[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	return &state, nil
+}
+
+// deactivate does what DeactivateUser does to the token state: the account becomes inactive
+// and tokens issued until cutoff are denied
+func (r *tokenStateRepo) deactivate(id uuid.UUID, cutoff time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.state[id] = models.UserTokenState{IsActive: false, TokensDeniedBefore: &cutoff}
+}
+
+func (r *tokenStateRepo) activate(id uuid.UUID) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state := r.state[id]
+	state.IsActive = true
+	r.state[id] = state
+}
+
+// clientRepo serves machine clients from memory; other OAuthClientRepository methods are not used
+type clientRepo struct {
+	models.OAuthClientRepository
+	mu      sync.Mutex
+	clients map[string]*models.OAuthClient
+}
+
+func (r *clientRepo) GetByClientID(clientID string) (*models.OAuthClient, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	copied := *client
+	return &copied, nil
+}
+
+func (r *clientRepo) revoke(clientID string, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[clientID].RevokedAt = &at
+}
+
+func (r *clientRepo) DenyAllTokensIssuedBefore(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, client := range r.clients {
+		client.TokensDeniedBefore = &cutoff
+	}
+	return int64(len(r.clients)), nil
+}
+
+// denylistRouter serves GET /ok behind TokenDenylistMiddleware for the claims of each request,
+// calling handle before responding
+func denylistRouter(repo models.UserRepository, claims func(*http.Request) *models.TokenClaims, handle func()) *gin.Engine {
+	return denylistClientRouter(repo, &clientRepo{clients: map[string]*models.OAuthClient{}}, claims, handle)
+}
+
+func denylistClientRouter(repo models.UserRepository, clients models.OAuthClientRepository, claims func(*http.Request) *models.TokenClaims, handle func()) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set("claims", claims(c.Request))
+		c.Next()
+	})
+	r.Use(TokenDenylistMiddleware(repo, clients))
+	r.GET("/ok", func(c *gin.Context) {
+		if handle != nil {
+			handle()
+		}
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func serve(r http.Handler) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	return w
+}
+
+func TestTokenDenylistDeniesTokensOnDeactivation(t *testing.T) {
+	userID := uuid.New()
+	issuedAt := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	repo := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{userID: {IsActive: true}}}
+	claims := &models.TokenClaims{UserID: userID, Type: "access", IssuedAt: issuedAt}
+	r := denylistRouter(repo, func(*http.Request) *models.TokenClaims { return claims }, nil)
+
+	if w := serve(r); w.Code != http.StatusOK {
+		t.Fatalf("before deactivation: got %d, want 200", w.Code)
+	}
+
+	repo.deactivate(userID, issuedAt.Add(time.Minute))
+	w := serve(r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("after deactivation: got %d, want 401", w.Code)
+	}
+	if reason := errorReason(t, w); reason != "TOKEN_REVOKED" {
+		t.Errorf("after deactivation: got reason %q, want TOKEN_REVOKED", reason)
+	}
+
+	// Reactivating the account does not bring outstanding tokens back
+	repo.activate(userID)
+	if w := serve(r); w.Code != http.StatusUnauthorized {
+		t.Errorf("after reactivation: got %d for a token issued before deactivation, want 401", w.Code)
+	}
+	claims.IssuedAt = issuedAt.Add(2 * time.Minute)
+	if w := serve(r); w.Code != http.StatusOK {
+		t.Errorf("after reactivation: got %d for a token issued since, want 200", w.Code)
+	}
+}
+
+func TestTokenDenylistRejectsTokensMintedDuringDeactivation(t *testing.T) {
+	userID := uuid.New()
+	cutoff := time.Date(2026, 10, 14, 9, 0, 0, 500_000_000, time.UTC)
+	repo := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{userID: {IsActive: true}}}
+	repo.deactivate(userID, cutoff)
+
+	tests := []struct {
+		name     string
+		issuedAt time.Time
+	}{
+		// Issue times have second precision, so the cutoff's own second is denied
+		{"in the cutoff second", cutoff.Truncate(time.Second)},
+		// A refresh that passed its checks before the account was deactivated mints a token
+		// after the cutoff; it is still refused while the account stays inactive
+		{"by a racing refresh", cutoff.Add(time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &models.TokenClaims{UserID: userID, Type: "access", IssuedAt: tt.issuedAt}
+			r := denylistRouter(repo, func(*http.Request) *models.TokenClaims { return claims }, nil)
+			if w := serve(r); w.Code != http.StatusUnauthorized {
+				t.Errorf("got %d, want 401", w.Code)
+			}
+		})
+	}
+}
+
+func TestTokenDenylistInFlightRequests(t *testing.T) {
+	userID := uuid.New()
+	issuedAt := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	repo := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{userID: {IsActive: true}}}
+	claims := &models.TokenClaims{UserID: userID, Type: "access", IssuedAt: issuedAt}
+
+	// Requests wait in the handler, past the check, until the account is deactivated
+	const inFlight = 8
+	entered := make(chan struct{}, inFlight)
+	release := make(chan struct{})
+	r := denylistRouter(repo, func(*http.Request) *models.TokenClaims { return claims }, func() {
+		entered <- struct{}{}
+		<-release
+	})
+
+	codes := make(chan int, inFlight)
+	var wg sync.WaitGroup
+	for i := 0; i < inFlight; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(r).Code
+		}()
+	}
+	for i := 0; i < inFlight; i++ {
+		<-entered
+	}
+
+	repo.deactivate(userID, issuedAt.Add(time.Minute))
+
+	// New requests racing with the ones in flight are refused from now on
+	after := denylistRouter(repo, func(*http.Request) *models.TokenClaims { return claims }, nil)
+	var refused sync.WaitGroup
+	for i := 0; i < inFlight; i++ {
+		refused.Add(1)
+		go func() {
+			defer refused.Done()
+			if code := serve(after).Code; code != http.StatusUnauthorized {
+				t.Errorf("request started after deactivation: got %d, want 401", code)
+			}
+		}()
+	}
+	refused.Wait()
+
+	// Requests already past the check complete
+	close(release)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request in flight during deactivation: got %d, want 200", code)
+		}
+	}
+}
+
+func TestTokenDenylistSkipsAPIKeysAndUnknownUsers(t *testing.T) {
+	repo := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{}}
+	keyID := uuid.New()
+
+	apiKey := denylistRouter(repo, func(*http.Request) *models.TokenClaims {
+		return &models.TokenClaims{UserID: uuid.New(), APIKeyID: &keyID}
+	}, nil)
+	if w := serve(apiKey); w.Code != http.StatusOK {
+		t.Errorf("API key: got %d, want 200", w.Code)
+	}
+
+	deleted := denylistRouter(repo, func(*http.Request) *models.TokenClaims {
+		return &models.TokenClaims{UserID: uuid.New(), Type: "access", IssuedAt: time.Now()}
+	}, nil)
+	if w := serve(deleted); w.Code != http.StatusUnauthorized {
+		t.Errorf("deleted user: got %d, want 401", w.Code)
+	}
+}
+
+func TestTokenDenylistRejectsTokensOfRevokedClients(t *testing.T) {
+	users := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{}}
+	clients := &clientRepo{clients: map[string]*models.OAuthClient{"client_cron": {ClientID: "client_cron"}}}
+	claims := &models.TokenClaims{ClientID: "client_cron", Type: "access", Scopes: []string{models.ScopeUsersAdmin}, IssuedAt: time.Now()}
+	r := denylistClientRouter(users, clients, func(*http.Request) *models.TokenClaims { return claims }, nil)
+
+	if w := serve(r); w.Code != http.StatusOK {
+		t.Fatalf("before revocation: got %d, want 200", w.Code)
+	}
+
+	clients.revoke("client_cron", time.Now())
+	w := serve(r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("after revocation: got %d, want 401", w.Code)
+	}
+	if reason := errorReason(t, w); reason != "TOKEN_REVOKED" {
+		t.Errorf("after revocation: got reason %q, want TOKEN_REVOKED", reason)
+	}
+
+	claims.ClientID = "client_deleted"
+	if w := serve(r); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown client: got %d, want 401", w.Code)
+	}
+}
+
+func TestTokenDenylistRejectsClientTokensIssuedBeforeTheCutoff(t *testing.T) {
+	users := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{}}
+	clients := &clientRepo{clients: map[string]*models.OAuthClient{"client_cron": {ClientID: "client_cron"}}}
+	issuedAt := time.Now().Truncate(time.Second)
+	claims := &models.TokenClaims{ClientID: "client_cron", Type: "access", Scopes: []string{models.ScopeUsersAdmin}, IssuedAt: issuedAt}
+	r := denylistClientRouter(users, clients, func(*http.Request) *models.TokenClaims { return claims }, nil)
+
+	// A cutoff within the second the token was issued in denies it
+	if _, err := clients.DenyAllTokensIssuedBefore(issuedAt.Add(500 * time.Millisecond)); err != nil {
+		t.Fatalf("DenyAllTokensIssuedBefore: %v", err)
+	}
+	w := serve(r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("token issued before the cutoff: got %d, want 401", w.Code)
+	}
+	if reason := errorReason(t, w); reason != "TOKEN_REVOKED" {
+		t.Errorf("token issued before the cutoff: got reason %q, want TOKEN_REVOKED", reason)
+	}
+
+	// The client obtains a new token with its secret
+	claims.IssuedAt = issuedAt.Add(time.Second)
+	if w := serve(r); w.Code != http.StatusOK {
+		t.Errorf("token issued after the cutoff: got %d, want 200", w.Code)
+	}
+}
+
+func TestDemotedAdminsLoseAdminAccessBeforeTheirTokenExpires(t *testing.T) {
+	userID := uuid.New()
+	repo := &tokenStateRepo{state: map[uuid.UUID]models.UserTokenState{userID: {IsActive: true, Role: models.RoleAdmin}}}
+	claims := &models.TokenClaims{UserID: userID, Role: models.RoleAdmin, Type: "access", Scopes: []string{models.ScopeUsersAdmin}, IssuedAt: time.Now()}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", func(c *gin.Context) {
+		setClaims(c, claims)
+		c.Next()
+	}, TokenDenylistMiddleware(repo, &clientRepo{}), RequireAdmin(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	get := func() int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin", nil))
+		return w.Code
+	}
+
+	if code := get(); code != http.StatusOK {
+		t.Fatalf("as admin: got %d, want 200", code)
+	}
+	repo.mu.Lock()
+	repo.state[userID] = models.UserTokenState{IsActive: true, Role: models.RoleUser}
+	repo.mu.Unlock()
+	if code := get(); code != http.StatusForbidden {
+		t.Errorf("after demotion, with the token still saying admin: got %d, want 403", code)
+	}
+}
@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// UsageMiddleware counts every authenticated request towards the usage of its caller: the
+// user, the API key it was made with, or the OAuth client. It reads the claims once the
+// request was handled, so it may run before AuthMiddleware; unauthenticated requests and
+// requests that matched no route are not counted.
+func UsageMiddleware(usage models.APIUsageService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		claims, _ := c.Get("claims")
+		tokenClaims, ok := claims.(*models.TokenClaims)
+		if !ok || c.FullPath() == "" {
+			return
+		}
+
+		req := &models.APIRequest{
+			APIKeyID: tokenClaims.APIKeyID,
+			ClientID: tokenClaims.ClientID,
+			Method:   c.Request.Method,
+			Route:    c.FullPath(),
+			Status:   c.Writer.Status(),
+			At:       time.Now(),
+		}
+		if !tokenClaims.IsClient() && tokenClaims.UserID != uuid.Nil {
+			userID := tokenClaims.UserID
+			req.UserID = &userID
+		}
+		usage.Record(req)
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Announcement severities, from least to most urgent
+const (
+	AnnouncementInfo     = "info"
+	AnnouncementWarning  = "warning"
+	AnnouncementCritical = "critical"
+)
+
+// Announcement is a banner, such as a maintenance notice or feature news, shown to the users
+// in its audience while it runs
+type Announcement struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	Message  string    `json:"message" db:"message"`
+	Severity string    `json:"severity" db:"severity"` // info, warning or critical
+	// Dismissible announcements stop being shown to a user once they dismiss them
+	Dismissible bool       `json:"dismissible" db:"dismissible"`
+	StartsAt    time.Time  `json:"starts_at" db:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at,omitempty" db:"ends_at"` // Runs until deleted when unset
+	// Roles and Plans restrict the audience to the users with one of them; empty lists allow anyone
+	Roles     []string   `json:"roles" db:"roles"`
+	Plans     []string   `json:"plans" db:"plans"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// IsActiveAt returns true if the announcement runs at t
+func (a *Announcement) IsActiveAt(t time.Time) bool {
+	return !t.Before(a.StartsAt) && (a.EndsAt == nil || t.Before(*a.EndsAt))
+}
+
+// IsFor returns true if a user with role and plan is in the audience of the announcement
+func (a *Announcement) IsFor(role, plan string) bool {
+	return (len(a.Roles) == 0 || slices.Contains(a.Roles, role)) && (len(a.Plans) == 0 || slices.Contains(a.Plans, plan))
+}
+
+// AnnouncementRepository defines the interface for announcement data operations
+type AnnouncementRepository interface {
+	Create(announcement *Announcement) error
+	GetByID(id uuid.UUID) (*Announcement, error)
+	List(limit, offset int) ([]*Announcement, error)
+	Count() (int, error)
+	// ListActive lists the announcements running at now for a user with role and plan that
+	// they have not dismissed, the most severe first
+	ListActive(userID uuid.UUID, role, plan string, now time.Time) ([]*Announcement, error)
+	Update(announcement *Announcement) error
+	Delete(id uuid.UUID) error
+	// Dismiss records a user dismissing an announcement; dismissing it again keeps the first time
+	Dismiss(announcementID, userID uuid.UUID, at time.Time) error
+}
+
+// AnnouncementService defines the interface for announcement business logic
+type AnnouncementService interface {
+	// ListActive lists the announcements to show a user now
+	ListActive(userID uuid.UUID) ([]*Announcement, error)
+	Dismiss(id, userID uuid.UUID) error
+	CreateAnnouncement(adminID uuid.UUID, req *AnnouncementRequest) (*Announcement, error)
+	ListAnnouncements(page, perPage int) ([]*Announcement, int, error)
+	UpdateAnnouncement(id uuid.UUID, req *AnnouncementRequest) (*Announcement, error)
+	DeleteAnnouncement(id uuid.UUID) error
+}
+
+// AnnouncementRequest represents the request to create or replace an announcement
+type AnnouncementRequest struct {
+	Message     string     `json:"message" validate:"required,max=1000"`
+	Severity    string     `json:"severity,omitempty" validate:"omitempty,oneof=info warning critical"` // Defaults to info
+	Dismissible *bool      `json:"dismissible,omitempty"`                                               // Defaults to true
+	StartsAt    *time.Time `json:"starts_at,omitempty"`                                                 // Defaults to now, or the current start when replacing
+	EndsAt      *time.Time `json:"ends_at,omitempty"`
+	Roles       []string   `json:"roles,omitempty" validate:"max=3,dive,oneof=user admin sandbox"`
+	Plans       []string   `json:"plans,omitempty" validate:"max=2,dive,oneof=free pro"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix marks API keys so they can be told apart from JWTs
+const APIKeyPrefix = "gba_"
+
+// APIKey represents a long-lived, scoped credential a user creates for scripts and integrations
+type APIKey struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	Name       string     `json:"name" db:"name"`
+	Key        string     `json:"key,omitempty" db:"-"` // Only returned when the key is created
+	KeyHash    string     `json:"-" db:"key_hash"`
+	Hint       string     `json:"hint" db:"hint"` // Last characters of the key, to tell keys apart
+	Scopes     []string   `json:"scopes" db:"scopes"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsUsable returns true if the key has not been revoked and has not expired
+func (k *APIKey) IsUsable() bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt))
+}
+
+// APIKeyRepository defines the interface for API key data operations
+type APIKeyRepository interface {
+	Create(key *APIKey) error
+	GetByID(id uuid.UUID) (*APIKey, error)
+	GetByKeyHash(keyHash string) (*APIKey, error)
+	ListByUser(userID uuid.UUID) ([]*APIKey, error)
+	Revoke(id uuid.UUID) error
+	TouchLastUsed(id uuid.UUID, usedAt time.Time) error
+}
+
+// APIKeyService defines the interface for API key business logic
+type APIKeyService interface {
+	CreateAPIKey(userID uuid.UUID, grantedScopes []string, req *CreateAPIKeyRequest) (*APIKey, error)
+	ListAPIKeys(userID uuid.UUID) ([]*APIKey, error)
+	RevokeAPIKey(id, userID uuid.UUID) error
+	Authenticate(key string) (*TokenClaims, error)
+}
+
+// CreateAPIKeyRequest represents the request to create an API key
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" validate:"required,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,dive,required"`
+	ExpiresInDays int      `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
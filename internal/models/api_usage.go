@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// API usage granularities
+const (
+	UsageDaily   = "daily"
+	UsageMonthly = "monthly"
+)
+
+// APIRequest is an authenticated request counted towards the usage of its caller: a user
+// signed in with a JWT or an API key, or an OAuth client
+type APIRequest struct {
+	UserID   *uuid.UUID
+	APIKeyID *uuid.UUID
+	ClientID string
+	Method   string
+	Route    string // Route template, so IDs in URLs do not split the counts
+	Status   int
+	At       time.Time
+}
+
+// APIUsage counts the requests of a caller to a route within a day or month
+type APIUsage struct {
+	Period   time.Time  `json:"period"` // Start of the day or month, in UTC
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+	APIKeyID *uuid.UUID `json:"api_key_id,omitempty"`
+	ClientID string     `json:"client_id,omitempty"`
+	Method   string     `json:"method"`
+	Route    string     `json:"route"`
+	Requests int64      `json:"requests"`
+	Errors   int64      `json:"errors"` // Requests answered with a 4xx or 5xx status
+}
+
+// APIUsageReport is the usage of an API key per period and route
+type APIUsageReport struct {
+	Granularity string      `json:"granularity"`
+	From        time.Time   `json:"from"`
+	To          time.Time   `json:"to"`
+	Requests    int64       `json:"requests"`
+	Errors      int64       `json:"errors"`
+	Usage       []*APIUsage `json:"usage"`
+}
+
+// APIUsageFilter selects usage from From until To, rolled up by Granularity, of the callers
+// set in it (all callers when none is set)
+type APIUsageFilter struct {
+	Granularity string
+	From        time.Time
+	To          time.Time
+	UserID      *uuid.UUID
+	APIKeyID    *uuid.UUID
+	ClientID    string
+}
+
+// APIUsageRepository defines the interface for API usage data operations
+type APIUsageRepository interface {
+	// Add adds counted requests to the daily rollups
+	Add(usage []*APIUsage) error
+	// List gets the usage matching the filter per period, caller and route, newest periods first
+	// and the most requested routes first within them
+	List(filter *APIUsageFilter, limit, offset int) ([]*APIUsage, error)
+	Count(filter *APIUsageFilter) (int, error)
+}
+
+// APIUsageService defines the interface for API usage tracking and reporting
+type APIUsageService interface {
+	// Record counts a request in memory, until the next flush
+	Record(req *APIRequest)
+	// Flush adds the requests counted since the last flush to the daily rollups
+	Flush() error
+	// GetAPIKeyUsage reports the usage of one of the user's API keys over the last periods
+	GetAPIKeyUsage(keyID, userID uuid.UUID, granularity string, periods int) (*APIUsageReport, error)
+	// ListUsage lists the usage of every caller, or those set in the filter, over the last periods
+	ListUsage(filter *APIUsageFilter, periods, page, perPage int) ([]*APIUsage, int, error)
+}
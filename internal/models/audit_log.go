@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions
+const (
+	AuditActionLoginSuccess          = "login_success"
+	AuditActionLoginFailed           = "login_failed"
+	AuditActionStepUpRequired        = "login_step_up_required"
+	AuditActionStepUpSuccess         = "login_step_up_success"
+	AuditActionStepUpFailed          = "login_step_up_failed"
+	AuditActionUserDeleted           = "user_deleted"
+	AuditActionParentalConsent       = "parental_consent_confirmed"
+	AuditActionLegalHoldPlaced       = "legal_hold_placed"
+	AuditActionLegalHoldCleared      = "legal_hold_cleared"
+	AuditActionLegalHoldBlocked      = "legal_hold_blocked" // A deletion refused because of a legal hold
+	AuditActionBreakGlassProvisioned = "break_glass_provisioned"
+	AuditActionBreakGlassEnabled     = "break_glass_enabled"
+	AuditActionBreakGlassDisabled    = "break_glass_disabled"
+	AuditActionBreakGlassExpired     = "break_glass_expired"
+	AuditActionBreakGlassLogin       = "break_glass_login"
+	AuditActionBreakGlassLoginFailed = "break_glass_login_failed"
+	AuditActionTokensRevoked         = "tokens_revoked"
+	AuditActionIncidentModeEnabled   = "incident_mode_enabled"
+	AuditActionIncidentModeDisabled  = "incident_mode_disabled"
+)
+
+// AuditLog represents a security-relevant event
+type AuditLog struct {
+	ID           uuid.UUID       `json:"id" db:"id"`
+	UserID       *uuid.UUID      `json:"user_id,omitempty" db:"user_id"`
+	Action       string          `json:"action" db:"action"`
+	ResourceType *string         `json:"resource_type,omitempty" db:"resource_type"`
+	ResourceID   *uuid.UUID      `json:"resource_id,omitempty" db:"resource_id"`
+	IPAddress    *string         `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent    *string         `json:"user_agent,omitempty" db:"user_agent"`
+	Country      *string         `json:"country,omitempty" db:"country"`
+	City         *string         `json:"city,omitempty" db:"city"`
+	Details      json.RawMessage `json:"details,omitempty" db:"details"`
+	CreatedAt    time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogRepository defines the interface for audit log data operations
+type AuditLogRepository interface {
+	Create(entry *AuditLog) error
+	GetLatestByUserAndAction(userID uuid.UUID, action string) (*AuditLog, error)
+	ExistsForUserFromIP(userID uuid.UUID, action, ipAddress string) (bool, error)
+}
+
+// ClientInfo describes the client making a request. It is filled in by handlers, not bound from JSON.
+type ClientInfo struct {
+	IPAddress   string `json:"-"`
+	UserAgent   string `json:"-"`
+	DeviceID    string `json:"-"` // Client-provided, may be empty
+	Fingerprint string `json:"-"` // Computed by the server from request headers
+}
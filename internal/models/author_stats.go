@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuthorStats is a daily snapshot of an author's posts, audience and engagement
+type AuthorStats struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username,omitempty"` // Set in admin listings
+	Day            time.Time `json:"day"`
+	Posts          int       `json:"posts"`
+	PublishedPosts int       `json:"published_posts"`
+	Views          int64     `json:"views"`     // Total reads of the author's posts
+	Likes          int       `json:"likes"`     // Like reactions to the author's posts
+	Reactions      int       `json:"reactions"` // Reactions of every type to the author's posts
+	Followers      int       `json:"followers"`
+	FollowerGrowth int       `json:"follower_growth"` // Change in followers since the previous snapshot
+	ComputedAt     time.Time `json:"computed_at"`
+}
+
+// AuthorStatsRepository defines the interface for author statistics data operations
+type AuthorStatsRepository interface {
+	// Compute snapshots the stats of every user with posts or followers for day, replacing
+	// an earlier snapshot of the same day. It returns the number of authors written.
+	Compute(day, now time.Time) (int, error)
+	// History gets the snapshots of an author since the given day, newest first
+	History(userID uuid.UUID, since time.Time) ([]*AuthorStats, error)
+	// ListLatest gets the authors of the latest snapshot day in the given order
+	ListLatest(orderBy string, limit, offset int) ([]*AuthorStats, error)
+	CountLatest() (int, error)
+}
+
+// AuthorStatsService defines the interface for author analytics
+type AuthorStatsService interface {
+	Compute() error
+	// GetForUser gets the snapshots of the last days of an author, newest first
+	GetForUser(userID uuid.UUID, days int) ([]*AuthorStats, error)
+	ListAuthors(orderBy string, page, perPage int) ([]*AuthorStats, int, error)
+}
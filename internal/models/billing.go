@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription is a user's subscription at the billing provider (Stripe), as last reported
+// by its webhook
+type Subscription struct {
+	ID                string     `json:"id"` // Stripe subscription ID
+	UserID            uuid.UUID  `json:"user_id"`
+	CustomerID        string     `json:"customer_id"`
+	Status            string     `json:"status"` // Stripe status, e.g. active, past_due or canceled
+	PriceID           string     `json:"price_id,omitempty"`
+	CancelAtPeriodEnd bool       `json:"cancel_at_period_end"`
+	CurrentPeriodEnd  *time.Time `json:"current_period_end,omitempty"`
+	// EventAt is when the latest event applied to the subscription was created, so that older
+	// events delivered late do not undo newer ones
+	EventAt   time.Time `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsActive returns true if the subscription entitles its user to what it pays for. Past due
+// subscriptions keep it while the provider retries the payment.
+func (s *Subscription) IsActive() bool {
+	switch s.Status {
+	case "active", "trialing", "past_due":
+		return true
+	}
+	return false
+}
+
+// BillingRepository defines the interface for billing data operations
+type BillingRepository interface {
+	// SaveSubscription creates or updates a subscription, unless it was updated by an event
+	// created after sub.EventAt; it returns false when it was not saved
+	SaveSubscription(sub *Subscription) (bool, error)
+	ListSubscriptionsByUser(userID uuid.UUID) ([]*Subscription, error)
+	// GetUserIDByCustomer finds the user of the subscriptions of a billing customer
+	GetUserIDByCustomer(customerID string) (uuid.UUID, error)
+}
+
+// BillingService defines the interface for billing webhooks
+type BillingService interface {
+	// HandleStripeEvent processes a verified Stripe webhook event. Redeliveries are filtered
+	// out by the inbound webhook registry before they get here.
+	HandleStripeEvent(payload []byte) error
+}
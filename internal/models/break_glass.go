@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BreakGlassAccount is an emergency credential of an admin for recovering access when normal
+// sign-in is unavailable. It is provisioned ahead of time and stays disabled until an operator
+// enables it from the host with cmd/breakglass; until EnabledUntil it then signs in without
+// step-up verification.
+type BreakGlassAccount struct {
+	UserID         uuid.UUID  `json:"user_id" db:"user_id"`
+	CredentialHash string     `json:"-" db:"credential_hash"`
+	EnabledUntil   *time.Time `json:"enabled_until,omitempty" db:"enabled_until"`
+	EnabledBy      *string    `json:"enabled_by,omitempty" db:"enabled_by"` // Operator and host that enabled it
+	LastUsedAt     *time.Time `json:"last_used_at,omitempty" db:"last_used_at"`
+	CreatedAt      time.Time  `json:"created_at" db:"created_at"`
+}
+
+// EnabledAt reports if the account may sign in at t
+func (a *BreakGlassAccount) EnabledAt(t time.Time) bool {
+	return a.EnabledUntil != nil && t.Before(*a.EnabledUntil)
+}
+
+// BreakGlassRepository defines the interface for break-glass account data operations
+type BreakGlassRepository interface {
+	// Provision creates the account of a user, or replaces its credential and disables it
+	Provision(account *BreakGlassAccount) error
+	GetByUserID(userID uuid.UUID) (*BreakGlassAccount, error)
+	// List gets every account, oldest first
+	List() ([]*BreakGlassAccount, error)
+	Enable(userID uuid.UUID, until time.Time, enabledBy string) error
+	Disable(userID uuid.UUID) error
+	MarkUsed(userID uuid.UUID, usedAt time.Time) error
+	// DisableExpired disables the accounts enabled until now or earlier, returning their users
+	DisableExpired(now time.Time) ([]uuid.UUID, error)
+}
+
+// BreakGlassService defines the interface for managing break-glass accounts from the host.
+// The operator is whoever runs the command, recorded in the audit log.
+type BreakGlassService interface {
+	// Provision creates or rotates the disabled credential of an admin, returning it once
+	Provision(email, operator string) (string, error)
+	// Enable enables the account of an admin for duration, capped by the configured maximum
+	Enable(email string, duration time.Duration, operator string) (*BreakGlassAccount, error)
+	// Disable disables the account of an admin and signs them out everywhere
+	Disable(email, operator string) error
+	List() ([]*BreakGlassAccount, error)
+	// Expire disables the accounts whose time ran out, returning how many were
+	Expire() (int, error)
+}
+
+// BreakGlassLoginRequest represents a sign-in with a break-glass credential
+type BreakGlassLoginRequest struct {
+	Email      string     `json:"email" validate:"required,email" normalize:"email"`
+	Credential string     `json:"credential" validate:"required" normalize:"-"`
+	Client     ClientInfo `json:"-"`
+}
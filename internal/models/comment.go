@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Comment represents a comment on a post. Replies point to their parent comment and are one
+// level deeper; top-level comments have no parent and a depth of 0.
+type Comment struct {
+	ID         uuid.UUID      `json:"id" db:"id"`
+	PostID     uuid.UUID      `json:"post_id" db:"post_id"`
+	AuthorID   uuid.UUID      `json:"author_id" db:"author_id"`
+	Author     *User          `json:"author,omitempty" db:"-"`
+	ParentID   *uuid.UUID     `json:"parent_comment_id,omitempty" db:"parent_comment_id"`
+	Depth      int            `json:"depth" db:"depth"`
+	Content    string         `json:"content" db:"content"`
+	ReplyCount int            `json:"reply_count" db:"-"`
+	Reactions  ReactionCounts `json:"reactions,omitempty" db:"-"`
+	Replies    []*Comment     `json:"replies,omitempty" db:"-"`
+	CreatedAt  time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// CommentRepository defines the interface for comment data operations.
+// Listings are oldest first and carry each comment's author and reply count.
+type CommentRepository interface {
+	Create(comment *Comment) error
+	GetByID(id uuid.UUID) (*Comment, error)
+	ListByPost(postID uuid.UUID, limit, offset int) ([]*Comment, error)
+	CountByPost(postID uuid.UUID) (int, error)
+	ListTopLevel(postID uuid.UUID, limit, offset int) ([]*Comment, error)
+	CountTopLevel(postID uuid.UUID) (int, error)
+	// ListReplies gets up to perParent of the earliest replies to each of the parents
+	ListReplies(parentIDs []uuid.UUID, perParent int) ([]*Comment, error)
+	ListRepliesOf(parentID uuid.UUID, limit, offset int) ([]*Comment, error)
+}
+
+// CommentService defines the interface for comment business logic
+type CommentService interface {
+	CreateComment(postID, authorID uuid.UUID, req *CreateCommentRequest) (*Comment, error)
+	GetComments(postID, viewerID uuid.UUID, page, perPage int) ([]*Comment, int, error)
+	// GetThreads gets a page of top-level comments, each with its earliest replies nested up to
+	// the depth limit, at most repliesPerBranch per comment
+	GetThreads(postID, viewerID uuid.UUID, page, perPage, repliesPerBranch int) ([]*Comment, int, error)
+	// GetReplies pages through the direct replies of a comment, with their earliest replies nested
+	GetReplies(postID, commentID, viewerID uuid.UUID, page, perPage, repliesPerBranch int) ([]*Comment, int, error)
+}
+
+// CreateCommentRequest represents the request to comment on a post or reply to a comment
+type CreateCommentRequest struct {
+	Content         string     `json:"content" validate:"required,min=1,max=10000"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id,omitempty"`
+}
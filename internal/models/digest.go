@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DigestSubscription is a user's opt-in to the weekly digest of top posts from the authors they
+// follow; users without one get no digest
+type DigestSubscription struct {
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	NextSendAt time.Time  `json:"next_send_at" db:"next_send_at"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty" db:"last_sent_at"`
+	// UnsubscribeTokenHash is the hash of the unsubscribe token in the latest digest
+	UnsubscribeTokenHash *string   `json:"-" db:"unsubscribe_token_hash"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// DigestSettings is whether a user receives the weekly digest, and when
+type DigestSettings struct {
+	Enabled    bool       `json:"enabled"`
+	NextSendAt *time.Time `json:"next_send_at,omitempty"`
+	LastSentAt *time.Time `json:"last_sent_at,omitempty"`
+}
+
+// UpdateDigestRequest represents the request to opt in to or out of the weekly digest
+type UpdateDigestRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// DigestRepository defines the interface for digest subscription data operations
+type DigestRepository interface {
+	// Subscribe creates a subscription, keeping the schedule of an existing one
+	Subscribe(sub *DigestSubscription) error
+	GetByUser(userID uuid.UUID) (*DigestSubscription, error)
+	GetByUnsubscribeTokenHash(tokenHash string) (*DigestSubscription, error)
+	Unsubscribe(userID uuid.UUID) error
+	// ListDue lists up to limit subscriptions whose next digest is due at now or earlier
+	ListDue(now time.Time, limit int) ([]*DigestSubscription, error)
+	// TopPosts gets up to limit posts by the authors the user follows that the user may list,
+	// published in [since, until), with the most reactions and then views first and their author set
+	TopPosts(userID uuid.UUID, since, until time.Time, limit int) ([]*Post, error)
+	// Update saves the schedule and unsubscribe token of a subscription
+	Update(sub *DigestSubscription) error
+}
+
+// DigestService defines the interface for the weekly digest
+type DigestService interface {
+	GetSettings(userID uuid.UUID) (*DigestSettings, error)
+	UpdateSettings(userID uuid.UUID, req *UpdateDigestRequest) (*DigestSettings, error)
+	// Unsubscribe opts out the user whose latest digest carried the token
+	Unsubscribe(token string) error
+	// SendDigests emails the due digests in batches, no faster than the configured send rate
+	SendDigests() error
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailChangeRequest represents a pending or completed email address change
+type EmailChangeRequest struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        uuid.UUID  `json:"user_id" db:"user_id"`
+	OldEmail      string     `json:"old_email" db:"old_email"`
+	NewEmail      string     `json:"new_email" db:"new_email"`
+	TokenHash     string     `json:"-" db:"token_hash"`
+	UndoTokenHash string     `json:"-" db:"undo_token_hash"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	UndoExpiresAt time.Time  `json:"undo_expires_at" db:"undo_expires_at"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CancelledAt   *time.Time `json:"cancelled_at,omitempty" db:"cancelled_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+}
+
+// IsPending returns true if the request has been neither confirmed nor cancelled
+func (r *EmailChangeRequest) IsPending() bool {
+	return r.ConfirmedAt == nil && r.CancelledAt == nil
+}
+
+// EmailChangeRepository defines the interface for email change request data operations
+type EmailChangeRepository interface {
+	Create(req *EmailChangeRequest) error
+	GetByTokenHash(tokenHash string) (*EmailChangeRequest, error)
+	GetByUndoTokenHash(undoTokenHash string) (*EmailChangeRequest, error)
+	MarkConfirmed(id uuid.UUID, at time.Time) error
+	MarkCancelled(id uuid.UUID, at time.Time) error
+	CancelPendingForUser(userID uuid.UUID, at time.Time) error
+}
+
+// EmailTokenRequest represents a request carrying an email confirmation or undo token
+type EmailTokenRequest struct {
+	Token string `json:"token" form:"token" validate:"required"`
+}
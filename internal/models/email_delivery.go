@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// Why the address of a user is undeliverable
+const (
+	// EmailBounce is a permanent delivery failure, e.g. a mailbox that does not exist
+	EmailBounce = "bounce"
+	// EmailComplaint is a recipient reporting our mail as spam
+	EmailComplaint = "complaint"
+)
+
+// EmailDeliveryService defines the interface for tracking undeliverable addresses reported by
+// the mail provider
+type EmailDeliveryService interface {
+	// HandleSendGridEvents processes a batch of the SendGrid event webhook, marking the
+	// addresses of bounces and spam reports undeliverable. Other events are ignored.
+	HandleSendGridEvents(payload []byte) error
+	// IsSuppressed checks if mail to an address must not be sent
+	IsSuppressed(address string) (bool, error)
+	// ClearUndeliverable lets mail reach the address of a user again, e.g. once their mailbox
+	// is fixed
+	ClearUndeliverable(userID uuid.UUID) error
+}
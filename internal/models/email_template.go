@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmailTemplateVersion is a version of a transactional email template saved by an admin. The
+// latest version of a template replaces its embedded default.
+type EmailTemplateVersion struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	Name      string     `json:"name" db:"name"`
+	Version   int        `json:"version" db:"version"`
+	Subject   string     `json:"subject" db:"subject"`
+	Body      string     `json:"body" db:"body"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// EmailTemplateSummary describes a template in the template listing
+type EmailTemplateSummary struct {
+	Name string `json:"name"`
+	// Version is the current version, 0 while the embedded default is used
+	Version   int        `json:"version"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// EmailTemplate is a template with its embedded default and saved versions
+type EmailTemplate struct {
+	Name string `json:"name"`
+	// Subject and Body are what emails are rendered from: the current version, or the default
+	Subject        string                  `json:"subject"`
+	Body           string                  `json:"body"`
+	Version        int                     `json:"version"`
+	DefaultSubject string                  `json:"default_subject"`
+	DefaultBody    string                  `json:"default_body"`
+	Versions       []*EmailTemplateVersion `json:"versions"` // Newest first
+}
+
+// EmailPreview is a template rendered with sample data
+type EmailPreview struct {
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// UpdateEmailTemplateRequest represents the request to save a new version of a template
+type UpdateEmailTemplateRequest struct {
+	Subject string `json:"subject" validate:"required,max=255"`
+	Body    string `json:"body" validate:"required,max=20000"`
+}
+
+// PreviewEmailTemplateRequest represents the request to render a template with sample data;
+// without subject and body the current version is rendered
+type PreviewEmailTemplateRequest struct {
+	Subject string                 `json:"subject,omitempty" validate:"required_with=Body,max=255"`
+	Body    string                 `json:"body,omitempty" validate:"required_with=Subject,max=20000"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+}
+
+// EmailTemplateRepository defines the interface for email template data operations
+type EmailTemplateRepository interface {
+	// Create saves a new version of a template, numbering it after the latest one
+	Create(version *EmailTemplateVersion) error
+	// ListCurrent gets the latest version of each overridden template
+	ListCurrent() ([]*EmailTemplateVersion, error)
+	// ListByName gets the versions of a template, newest first
+	ListByName(name string) ([]*EmailTemplateVersion, error)
+}
+
+// EmailTemplateService defines the interface for managing email template overrides
+type EmailTemplateService interface {
+	List() ([]*EmailTemplateSummary, error)
+	Get(name string) (*EmailTemplate, error)
+	// Update saves a new version of a template, which emails use right away
+	Update(adminID uuid.UUID, name string, req *UpdateEmailTemplateRequest) (*EmailTemplateVersion, error)
+	Preview(name string, req *PreviewEmailTemplateRequest) (*EmailPreview, error)
+	// Template returns the current version of a template, and false while its default is used
+	Template(name string) (subject, body string, ok bool)
+	// Refresh reloads the current versions, picking up versions saved by other instances
+	Refresh() error
+}
@@ -0,0 +1,14 @@
+package models
+
+import "errors"
+
+// Repository sentinel errors. Repositories return (or wrap) these so services can
+// branch with errors.Is instead of checking for nil results or driver errors.
+var (
+	// ErrNotFound is returned when a lookup or write matched no record
+	ErrNotFound = errors.New("record not found")
+	// ErrDuplicate is returned when a write violates a unique constraint
+	ErrDuplicate = errors.New("record already exists")
+	// ErrConflict is returned when a record is not in a state that allows the write
+	ErrConflict = errors.New("record is in a conflicting state")
+)
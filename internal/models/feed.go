@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FeedRepository defines the interface for the stored feeds of fan-out-on-write. Feeds keep
+// the newest entries only, at most limit per user.
+type FeedRepository interface {
+	// FanOut stores a post in the feeds of every follower of its author
+	FanOut(postID, authorID uuid.UUID, publishedAt time.Time, limit int) error
+	// RemovePost removes a post from every feed
+	RemovePost(postID uuid.UUID) error
+	// Backfill stores the latest published posts of followeeID the follower may list in their feed
+	Backfill(followerID, followeeID uuid.UUID, limit int) error
+	// RemoveAuthor removes the posts of followeeID from the feed of followerID
+	RemoveAuthor(followerID, followeeID uuid.UUID) error
+	// Rebuild replaces every stored feed with the latest posts of the followed authors,
+	// returning how many entries it stored
+	Rebuild(limit int) (int64, error)
+	// GetPosts gets a page of the posts of a user's feed the user may still list, newest first
+	GetPosts(userID uuid.UUID, limit, offset int) ([]*Post, error)
+	Count(userID uuid.UUID) (int, error)
+}
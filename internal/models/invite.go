@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Invite represents an invitation code for invite-only registration
+type Invite struct {
+	ID        uuid.UUID    `json:"id" db:"id"`
+	Code      string       `json:"code,omitempty" db:"-"` // Only returned when the invite is created
+	CodeHash  string       `json:"-" db:"code_hash"`
+	CreatedBy *uuid.UUID   `json:"created_by,omitempty" db:"created_by"`
+	Email     *string      `json:"email,omitempty" db:"email"` // Restricts the invite to one address
+	MaxUses   int          `json:"max_uses" db:"max_uses"`
+	UseCount  int          `json:"use_count" db:"use_count"`
+	ExpiresAt time.Time    `json:"expires_at" db:"expires_at"`
+	RevokedAt *time.Time   `json:"revoked_at,omitempty" db:"revoked_at"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	Uses      []*InviteUse `json:"uses,omitempty" db:"-"`
+}
+
+// IsUsable returns true if the invite can still be redeemed
+func (i *Invite) IsUsable() bool {
+	return i.RevokedAt == nil && time.Now().Before(i.ExpiresAt) && i.UseCount < i.MaxUses
+}
+
+// InviteUse records a registration made with an invite
+type InviteUse struct {
+	InviteID uuid.UUID `json:"invite_id" db:"invite_id"`
+	UserID   uuid.UUID `json:"user_id" db:"user_id"`
+	UsedAt   time.Time `json:"used_at" db:"used_at"`
+}
+
+// InviteRepository defines the interface for invite data operations
+type InviteRepository interface {
+	Create(invite *Invite) error
+	GetByID(id uuid.UUID) (*Invite, error)
+	GetByCodeHash(codeHash string) (*Invite, error)
+	List(limit, offset int) ([]*Invite, error)
+	Count() (int, error)
+	ListByCreator(userID uuid.UUID) ([]*Invite, error)
+	CountByCreator(userID uuid.UUID) (int, error)
+	Claim(id uuid.UUID) (bool, error)
+	Release(id uuid.UUID) error
+	RecordUse(inviteID, userID uuid.UUID) error
+	GetUses(inviteID uuid.UUID) ([]*InviteUse, error)
+	Revoke(id uuid.UUID) error
+}
+
+// InviteService defines the interface for invite business logic
+type InviteService interface {
+	CreateInvite(creatorID uuid.UUID, isAdmin bool, req *CreateInviteRequest) (*Invite, error)
+	GetInvite(id uuid.UUID) (*Invite, error)
+	ListInvites(page, perPage int) ([]*Invite, int, error)
+	ListUserInvites(userID uuid.UUID) ([]*Invite, error)
+	RevokeInvite(id, requesterID uuid.UUID, isAdmin bool) error
+}
+
+// CreateInviteRequest represents the request to create an invite
+type CreateInviteRequest struct {
+	Email         string `json:"email" validate:"omitempty,email" normalize:"email"`
+	MaxUses       int    `json:"max_uses" validate:"omitempty,min=1,max=1000"`
+	ExpiresInDays int    `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LegalHold preserves the data of a user: while it is in place, neither the user nor the
+// lifecycle and retention jobs can delete their account, posts or audit trail
+type LegalHold struct {
+	UserID   uuid.UUID  `json:"user_id" db:"user_id"`
+	Reason   string     `json:"reason" db:"reason"`
+	PlacedBy *uuid.UUID `json:"placed_by,omitempty" db:"placed_by"`
+	PlacedAt time.Time  `json:"placed_at" db:"placed_at"`
+}
+
+// LegalHoldRepository defines the interface for legal hold data operations
+type LegalHoldRepository interface {
+	// Place places a hold, or updates the reason of an existing one
+	Place(hold *LegalHold) error
+	GetByUserID(userID uuid.UUID) (*LegalHold, error)
+	// List gets every hold, newest first
+	List() ([]*LegalHold, error)
+	Clear(userID uuid.UUID) error
+	IsHeld(userID uuid.UUID) (bool, error)
+}
+
+// LegalHoldService defines the interface for legal hold business logic
+type LegalHoldService interface {
+	Place(adminID, userID uuid.UUID, req *PlaceLegalHoldRequest, client ClientInfo) (*LegalHold, error)
+	Get(userID uuid.UUID) (*LegalHold, error)
+	List() ([]*LegalHold, error)
+	Clear(adminID, userID uuid.UUID, client ClientInfo) error
+}
+
+// PlaceLegalHoldRequest represents the request to place a legal hold on a user
+type PlaceLegalHoldRequest struct {
+	Reason string `json:"reason" validate:"required,max=1000"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Account lifecycle actions
+const (
+	LifecycleActionWarn       = "warn"
+	LifecycleActionDeactivate = "deactivate"
+	LifecycleActionPurge      = "purge"
+)
+
+// LifecycleCandidate is a user selected by a stale account policy
+type LifecycleCandidate struct {
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+	InactiveDays   int       `json:"inactive_days"`
+	Action         string    `json:"action"`
+	Error          string    `json:"error,omitempty"`
+}
+
+// LifecycleReport summarizes a run of the stale account policies
+type LifecycleReport struct {
+	DryRun      bool                  `json:"dry_run"`
+	RanAt       time.Time             `json:"ran_at"`
+	Warned      []*LifecycleCandidate `json:"warned"`
+	Deactivated []*LifecycleCandidate `json:"deactivated"`
+	Purged      []*LifecycleCandidate `json:"purged"`
+	// Held lists the accounts due for purging that are kept because of a legal hold
+	Held     []*LifecycleCandidate `json:"held"`
+	Excluded int                   `json:"excluded"`
+}
+
+// LifecycleService defines the interface for stale account policies
+type LifecycleService interface {
+	Run(dryRun bool) (*LifecycleReport, error)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Step-up authentication methods
+const (
+	StepUpMethodEmailOTP = "email_otp"
+)
+
+// LoginChallenge represents a pending step-up verification for a risky sign-in
+type LoginChallenge struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	CodeHash   string     `json:"-" db:"code_hash"`
+	IPAddress  *string    `json:"ip_address,omitempty" db:"ip_address"`
+	Attempts   int        `json:"attempts" db:"attempts"`
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty" db:"consumed_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// LoginChallengeRepository defines the interface for login challenge data operations
+type LoginChallengeRepository interface {
+	Create(challenge *LoginChallenge) error
+	GetByID(id uuid.UUID) (*LoginChallenge, error)
+	// UseAttempt atomically counts a verification attempt of a challenge that is unconsumed,
+	// unexpired at now and below maxAttempts, reporting false when it is none of these
+	UseAttempt(id uuid.UUID, maxAttempts int, now time.Time) (bool, error)
+	Consume(id uuid.UUID) (bool, error)
+}
+
+// StepUpChallenge is returned instead of tokens when a sign-in needs additional verification
+type StepUpChallenge struct {
+	ChallengeID uuid.UUID `json:"challenge_id"`
+	Method      string    `json:"method"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// VerifyLoginRequest represents the request to complete a step-up sign-in
+type VerifyLoginRequest struct {
+	ChallengeID uuid.UUID  `json:"challenge_id" validate:"required"`
+	Code        string     `json:"code" validate:"required,len=6,numeric"`
+	Client      ClientInfo `json:"-"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoginHourStats counts sign-in attempts within an hour
+type LoginHourStats struct {
+	Hour      time.Time `json:"hour"`
+	Successes int       `json:"successes"`
+	Failures  int       `json:"failures"`
+}
+
+// LoginIPStats counts sign-in attempts from a client IP address
+type LoginIPStats struct {
+	IPAddress string    `json:"ip_address"`
+	Successes int       `json:"successes"`
+	Failures  int       `json:"failures"`
+	Users     int       `json:"users"`     // Distinct known accounts attempted
+	LastHour  time.Time `json:"last_hour"` // Latest hour with attempts
+}
+
+// LoginUserStats counts sign-in attempts against an account
+type LoginUserStats struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Successes int       `json:"successes"`
+	Failures  int       `json:"failures"`
+	IPs       int       `json:"ips"` // Distinct client IPs seen
+}
+
+// LoginStatsReport summarizes sign-in attempts over a period
+type LoginStatsReport struct {
+	From      time.Time         `json:"from"`
+	To        time.Time         `json:"to"`
+	Successes int               `json:"successes"`
+	Failures  int               `json:"failures"`
+	Hourly    []*LoginHourStats `json:"hourly"`
+	TopIPs    []*LoginIPStats   `json:"top_ips"`
+	TopUsers  []*LoginUserStats `json:"top_users"`
+}
+
+// LoginStatsRepository defines the interface for login statistics data operations
+type LoginStatsRepository interface {
+	// Rollup aggregates login audit logs into hourly statistics, recomputing the latest hour
+	// already rolled up and everything after it. It returns the number of rows written.
+	Rollup() (int, error)
+	Hourly(from, to time.Time) ([]*LoginHourStats, error)
+	TopIPs(from, to time.Time, limit int) ([]*LoginIPStats, error)
+	TopUsers(from, to time.Time, limit int) ([]*LoginUserStats, error)
+}
+
+// LoginStatsService defines the interface for login analytics
+type LoginStatsService interface {
+	Rollup() error
+	Report(hours, limit int) (*LoginStatsReport, error)
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ParentalConsent represents a request for a parent to consent to an account under the
+// parental consent age
+type ParentalConsent struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	ParentEmail string     `json:"parent_email" db:"parent_email"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	ConfirmedAt *time.Time `json:"confirmed_at,omitempty" db:"confirmed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+}
+
+// ParentalConsentRepository defines the interface for parental consent data operations
+type ParentalConsentRepository interface {
+	Create(consent *ParentalConsent) error
+	GetByTokenHash(tokenHash string) (*ParentalConsent, error)
+	// Confirm marks a request confirmed and records the consent on its user atomically
+	Confirm(id uuid.UUID, at time.Time) error
+}
+
+// ParentalConsentRequest represents the request to send a parental consent link
+type ParentalConsentRequest struct {
+	ParentEmail string `json:"parent_email" validate:"required,email" normalize:"email"`
+}
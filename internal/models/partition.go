@@ -0,0 +1,68 @@
+package models
+
+import (
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tables partitioned by month: refresh tokens by the month they expire in, audit logs by
+// the month of the event
+const (
+	PartitionedRefreshTokens = "refresh_tokens"
+	PartitionedAuditLogs     = "audit_logs"
+)
+
+// PartitionedTables lists the tables partitioned by month
+var PartitionedTables = []string{PartitionedRefreshTokens, PartitionedAuditLogs}
+
+// Partition is the partition of a monthly partitioned table holding the rows from From,
+// inclusive, to To, exclusive
+type Partition struct {
+	Table string    `json:"table"`
+	Name  string    `json:"name"`
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	// Restored partitions were recreated from an archive and are exempt from retention
+	Restored bool `json:"restored"`
+}
+
+// PartitionMaintenanceReport summarizes a run of the partition maintenance job
+type PartitionMaintenanceReport struct {
+	Created  []string `json:"created"` // Partitions ensured to exist, including ones that already did
+	Archived []string `json:"archived"`
+	Dropped  []string `json:"dropped"` // Including the archived partitions
+	// Held lists the partitions kept past retention because they hold rows of users under legal hold
+	Held []string `json:"held"`
+}
+
+// PartitionRepository defines the interface for managing monthly partitions
+type PartitionRepository interface {
+	// Create creates the partition of table holding month unless it exists, and returns its name
+	Create(table string, month time.Time) (string, error)
+	// List gets the monthly partitions of table, oldest first
+	List(table string) ([]*Partition, error)
+	Drop(partition *Partition) error
+	// Archive writes the rows of a partition to w as JSON lines, calls store once all are
+	// written and, if it succeeds, drops the partition. Writes to the partition wait until
+	// then, so that no row is dropped without being archived. It returns the rows archived.
+	Archive(partition *Partition, w io.Writer, store func() error) (int, error)
+	// Restore recreates the partition of table holding month from the JSON lines of Archive
+	// and marks it restored. It returns the rows restored.
+	Restore(table string, month time.Time, r io.Reader) (int, error)
+	// Release clears the restored mark of a partition, subjecting it to retention again
+	Release(table string, month time.Time) error
+	// HeldUserIDs gets the users under legal hold that rows of a partition belong to
+	HeldUserIDs(partition *Partition) ([]uuid.UUID, error)
+}
+
+// PartitionService defines the interface for partition maintenance
+type PartitionService interface {
+	// Maintain creates the partitions of the coming months and drops those past their retention
+	Maintain() (*PartitionMaintenanceReport, error)
+	// Restore recreates an archived partition of table from storage
+	Restore(table string, month time.Time) (int, error)
+	// Release lets retention archive and drop a restored partition again
+	Release(table string, month time.Time) error
+}
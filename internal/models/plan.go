@@ -0,0 +1,77 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Plans of users
+const (
+	PlanFree = "free"
+	PlanPro  = "pro"
+)
+
+// IsValidPlan returns true if plan is a known plan
+func IsValidPlan(plan string) bool {
+	return plan == PlanFree || plan == PlanPro
+}
+
+// Features that plans may include
+const (
+	// FeatureAPIKeys allows creating API keys
+	FeatureAPIKeys = "api_keys"
+)
+
+// PlanLimits are the quotas, rate limit and features a plan grants its users
+type PlanLimits struct {
+	Plan string `json:"plan"`
+	// RateLimitRequests is how many requests per minute each user may make, on top of the
+	// per-client limit; 0 for no per-user limit
+	RateLimitRequests int `json:"rate_limit_requests"`
+	// SavedSearches is how many searches a user may save
+	SavedSearches int `json:"saved_searches"`
+	// APIKeys is how many usable API keys a user may hold; 0 for no limit
+	APIKeys  int      `json:"api_keys"`
+	Features []string `json:"features"`
+}
+
+// HasFeature returns true if the plan includes feature
+func (l *PlanLimits) HasFeature(feature string) bool {
+	for _, f := range l.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanResolver tells which plan a user is on. The default one reads the plan stored on the
+// user; an external billing system can provide its own to drive plans directly.
+type PlanResolver interface {
+	ResolvePlan(user *User) (string, error)
+}
+
+// UpdatePlanRequest represents the request to move a user to another plan
+type UpdatePlanRequest struct {
+	Plan string `json:"plan" validate:"required,oneof=free pro"`
+}
+
+// PlanService defines the interface for resolving plans and their limits
+type PlanService interface {
+	PlanResolver
+	// Limits returns the limits of plan, or of the free plan when it is unknown
+	Limits(plan string) *PlanLimits
+	// GetUserLimits returns the limits of the plan a user is on
+	GetUserLimits(userID uuid.UUID) (*PlanLimits, error)
+	// UpdatePlan moves a user to another plan, returning the limits of the new plan. Access
+	// tokens issued before carry the old plan until they are refreshed.
+	UpdatePlan(userID uuid.UUID, req *UpdatePlanRequest) (*PlanLimits, error)
+	// ChangePlan moves a user to a known plan on behalf of source (e.g. admin or stripe),
+	// publishing a plan change event when the plan differs
+	ChangePlan(userID uuid.UUID, plan, source string) (*PlanLimits, error)
+}
+
+// Sources of plan changes
+const (
+	PlanSourceAdmin  = "admin"
+	PlanSourceStripe = "stripe"
+)
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Policy document kinds
+const (
+	PolicyTerms   = "terms"
+	PolicyPrivacy = "privacy"
+)
+
+// PolicyDocument is a published version of the terms of service or the privacy policy. The
+// latest version of each kind is current; users must accept the current versions to register,
+// and accept again once a mandatory version is published.
+type PolicyDocument struct {
+	ID      uuid.UUID `json:"id" db:"id"`
+	Kind    string    `json:"kind" db:"kind"`
+	Version string    `json:"version" db:"version"`
+	URL     string    `json:"url" db:"url"` // Where the document is published
+	// Mandatory versions block the API for users until they accept it or a later version;
+	// other versions, e.g. with editorial changes, only need accepting at registration
+	Mandatory   bool       `json:"mandatory" db:"mandatory"`
+	PublishedBy *uuid.UUID `json:"published_by,omitempty" db:"published_by"`
+	PublishedAt time.Time  `json:"published_at" db:"published_at"`
+}
+
+// PolicyAcceptance records a user accepting a version of a policy document
+type PolicyAcceptance struct {
+	DocumentID uuid.UUID `json:"document_id"`
+	Kind       string    `json:"kind"`
+	Version    string    `json:"version"`
+	AcceptedAt time.Time `json:"accepted_at"`
+}
+
+// PolicyStatus describes which policy versions a user accepted and has yet to accept
+type PolicyStatus struct {
+	Current []*PolicyDocument `json:"current"`
+	// Pending lists the mandatory versions not yet accepted; the API is blocked until they are
+	Pending  []*PolicyDocument   `json:"pending"`
+	Accepted []*PolicyAcceptance `json:"accepted"`
+}
+
+// PolicyRepository defines the interface for policy document data operations
+type PolicyRepository interface {
+	Create(doc *PolicyDocument) error
+	// List gets every published version, newest first
+	List() ([]*PolicyDocument, error)
+	// ListCurrent gets the latest version of each kind
+	ListCurrent() ([]*PolicyDocument, error)
+	// ListPending gets the latest mandatory version of each kind, unless the user accepted
+	// it or a later version
+	ListPending(userID uuid.UUID) ([]*PolicyDocument, error)
+	// HasPending reports whether ListPending would return any version
+	HasPending(userID uuid.UUID) (bool, error)
+	// ListAcceptances gets the versions a user accepted, newest first
+	ListAcceptances(userID uuid.UUID) ([]*PolicyAcceptance, error)
+	Accept(userID uuid.UUID, documentIDs []uuid.UUID, acceptedAt time.Time) error
+}
+
+// PolicyService defines the interface for policy document business logic
+type PolicyService interface {
+	Publish(publisherID uuid.UUID, req *PublishPolicyRequest) (*PolicyDocument, error)
+	ListDocuments() ([]*PolicyDocument, error)
+	ListCurrent() ([]*PolicyDocument, error)
+	GetStatus(userID uuid.UUID) (*PolicyStatus, error)
+	// Accept records a user accepting current versions, given by kind
+	Accept(userID uuid.UUID, req *AcceptPoliciesRequest) (*PolicyStatus, error)
+}
+
+// PublishPolicyRequest represents the request to publish a policy version
+type PublishPolicyRequest struct {
+	Kind      string `json:"kind" validate:"required,oneof=terms privacy"`
+	Version   string `json:"version" validate:"required,max=50"`
+	URL       string `json:"url" validate:"required,url,max=2048"`
+	Mandatory *bool  `json:"mandatory,omitempty"` // Defaults to true
+}
+
+// AcceptPoliciesRequest represents the request to accept the current policy versions
+type AcceptPoliciesRequest struct {
+	// AcceptedPolicies maps policy kinds to the versions accepted, which must be current
+	AcceptedPolicies map[string]string `json:"accepted_policies" validate:"required,min=1"`
+}
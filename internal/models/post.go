@@ -8,57 +8,234 @@ import (
 
 // Post represents a post entity
 type Post struct {
-	ID          uuid.UUID `json:"id" db:"id"`
-	Title       string    `json:"title" db:"title"`
-	Content     string    `json:"content" db:"content"`
-	AuthorID    uuid.UUID `json:"author_id" db:"author_id"`
-	Author      *User     `json:"author,omitempty" db:"-"`
-	IsPublished bool      `json:"is_published" db:"is_published"`
-	CreatedAt   time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+	ID               uuid.UUID      `json:"id" db:"id"`
+	Number           int64          `json:"-" db:"number"` // Sequence number behind the short ID of shareable links
+	Title            string         `json:"title" db:"title"`
+	Content          string         `json:"content" db:"content"`
+	Excerpt          string         `json:"excerpt" db:"excerpt"`
+	ReadingTime      int            `json:"reading_time_minutes" db:"reading_time_minutes"`
+	ContentFormat    string         `json:"content_format" db:"content_format"`       // HTML content is sanitized when written
+	ContentSanitized bool           `json:"content_sanitized" db:"content_sanitized"` // Sanitizing changed the submitted content
+	Language         string         `json:"language" db:"language"`                   // Detected ISO 639-1 code, empty when undetermined
+	AuthorID         uuid.UUID      `json:"author_id" db:"author_id"`                 // uuid.Nil once the author was deleted and the post anonymized
+	Author           *User          `json:"author,omitempty" db:"-"`
+	IsPublished      bool           `json:"is_published" db:"is_published"`
+	Visibility       string         `json:"visibility" db:"visibility"` // Who may read and list the post, a PostVisibility* value
+	ArchivedAt       *time.Time     `json:"archived_at,omitempty" db:"archived_at"`
+	ScheduledAt      *time.Time     `json:"scheduled_at,omitempty" db:"scheduled_at"` // When the unpublished post is published automatically
+	PublishedAt      *time.Time     `json:"published_at,omitempty" db:"published_at"` // When the post was last published
+	Reactions        ReactionCounts `json:"reactions,omitempty" db:"-"`
+	Lock             *PostLock      `json:"-" db:"-"` // Active editing lock, only loaded for the author
+	CreatedAt        time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Post content formats
+const (
+	ContentFormatMarkdown = "markdown"
+	ContentFormatHTML     = "html"
+	ContentFormatText     = "text"
+)
+
+// Post visibility levels. Authors always see their own posts; published posts are seen by
+// others according to their visibility.
+const (
+	// PostVisibilityPublic posts are listed and readable by anyone
+	PostVisibilityPublic = "public"
+	// PostVisibilityUnlisted posts are readable by anyone with their link but never listed
+	PostVisibilityUnlisted = "unlisted"
+	// PostVisibilityFollowers posts are listed and readable only by followers of the author
+	PostVisibilityFollowers = "followers"
+	// PostVisibilityPrivate posts are only seen by their author
+	PostVisibilityPrivate = "private"
+)
+
+// PostFilter narrows and orders post listings
+type PostFilter struct {
+	// Language only lists posts detected to be in this ISO 639-1 language
+	Language string
+	// PreferredLanguages lists posts in these languages before all others
+	PreferredLanguages []string
+	// ViewerID lists their own posts at any visibility and the followers-only posts of the
+	// authors they follow, besides public posts (uuid.Nil lists public posts only)
+	ViewerID uuid.UUID
+}
+
+// PostState is a stage of the post lifecycle: draft → scheduled → published → archived.
+// It is derived from the published, scheduled and archived fields of the post.
+type PostState string
+
+// Post lifecycle states
+const (
+	PostStateDraft     PostState = "draft"
+	PostStateScheduled PostState = "scheduled"
+	PostStatePublished PostState = "published"
+	PostStateArchived  PostState = "archived"
+)
+
+// State returns the lifecycle state of the post
+func (p *Post) State() PostState {
+	switch {
+	case p.IsArchived():
+		return PostStateArchived
+	case p.IsPublished:
+		return PostStatePublished
+	case p.IsScheduled():
+		return PostStateScheduled
+	default:
+		return PostStateDraft
+	}
+}
+
+// IsScheduled reports whether the post waits to be published automatically
+func (p *Post) IsScheduled() bool {
+	return p.ScheduledAt != nil
+}
+
+// PostSchedule lists an author's scheduled posts between From and To, for calendars
+type PostSchedule struct {
+	// Location is the author's timezone, in which From and To and the calendar days are
+	Location *time.Location
+	From     time.Time
+	To       time.Time
+	// Posts are ordered by the time they are scheduled for
+	Posts []*Post
+}
+
+// VisibleTo reports whether a viewer may read the post by its ID or link, follower telling
+// whether they follow its author. Unlike listings, unlisted posts are readable.
+func (p *Post) VisibleTo(viewerID uuid.UUID, follower bool) bool {
+	if viewerID != uuid.Nil && p.AuthorID == viewerID {
+		return true
+	}
+	switch p.Visibility {
+	case PostVisibilityPublic, PostVisibilityUnlisted:
+		return true
+	case PostVisibilityFollowers:
+		return follower
+	default:
+		return false
+	}
+}
+
+// IsArchived reports whether the post is archived.
+// Archived posts are unpublished and only visible to their author until unarchived.
+func (p *Post) IsArchived() bool {
+	return p.ArchivedAt != nil
 }
 
 // PostRepository defines the interface for post data operations
 type PostRepository interface {
 	Create(post *Post) error
 	GetByID(id uuid.UUID) (*Post, error)
-	GetByAuthorID(authorID uuid.UUID, limit, offset int) ([]*Post, error)
+	GetByNumber(number int64) (*Post, error)
+	// GetByAuthorID gets the author's posts the viewer may list
+	GetByAuthorID(authorID, viewerID uuid.UUID, includeArchived bool, limit, offset int) ([]*Post, error)
 	GetAll(limit, offset int) ([]*Post, error)
-	GetAllWithAuthor(limit, offset int) ([]*Post, error)
+	// Export calls fn with every post, oldest first, reading them in batches
+	Export(fn func(*Post) error) error
+	GetAllWithAuthor(filter PostFilter, limit, offset int) ([]*Post, error)
+	// GetPublished gets the published posts that are public
 	GetPublished(limit, offset int) ([]*Post, error)
+	// GetScheduled gets the author's posts scheduled for from or later, and before to
+	GetScheduled(authorID uuid.UUID, from, to time.Time) ([]*Post, error)
+	// PublishScheduled publishes the posts scheduled for now or earlier, returning them
+	PublishScheduled(now time.Time) ([]*Post, error)
 	Update(post *Post) error
 	Delete(id uuid.UUID) error
-	Count() (int, error)
-	CountByAuthorID(authorID uuid.UUID) (int, error)
+	IncrementViews(id uuid.UUID) error
+	// IsFollower reports whether the viewer follows the author, who shares followers-only posts with them
+	IsFollower(authorID, viewerID uuid.UUID) (bool, error)
+	// GetFolloweeIDs gets the IDs of the authors the viewer follows
+	GetFolloweeIDs(viewerID uuid.UUID) ([]uuid.UUID, error)
+	// GetFeed gets the published posts the user may list of the authors they follow, newest first
+	GetFeed(userID uuid.UUID, limit, offset int) ([]*Post, error)
+	CountFeed(userID uuid.UUID) (int, error)
+	Count(filter PostFilter) (int, error)
+	CountAll() (int, error)
+	CountByAuthorID(authorID, viewerID uuid.UUID, includeArchived bool) (int, error)
 	CountPublished() (int, error)
+	// Search gets the published posts the viewer may list matching the query in full text or,
+	// for misspelled queries, by the similarity of their titles, best matches first
+	Search(query string, viewerID uuid.UUID, limit, offset int) ([]*Post, error)
+	CountSearch(query string, viewerID uuid.UUID) (int, error)
+	// GetListedByIDs gets those of the posts with the IDs that are published and the viewer
+	// may list, in no particular order
+	GetListedByIDs(ids []uuid.UUID, viewerID uuid.UUID) ([]*Post, error)
+	// SuggestTitles gets the published posts the viewer may list whose titles resemble prefix
+	SuggestTitles(prefix string, viewerID uuid.UUID, limit int) ([]*Post, error)
+	// SuggestAuthors gets the usernames resembling prefix of active authors of public posts
+	SuggestAuthors(prefix string, limit int) ([]string, error)
+	// SimilarWord gets the word of public post titles most similar to word, or "" without one
+	SimilarWord(word string) (string, error)
 }
 
 // PostService defines the interface for post business logic
 type PostService interface {
 	CreatePost(authorID uuid.UUID, req *CreatePostRequest) (*Post, error)
-	GetPostByID(id uuid.UUID) (*Post, error)
-	GetPosts(page, perPage int) ([]*Post, int, error)
-	GetPostsByAuthor(authorID uuid.UUID, page, perPage int) ([]*Post, int, error)
+	GetPostByID(id, viewerID uuid.UUID) (*Post, error)
+	// GetPostByShortID gets a published post by the short ID of its shareable link
+	GetPostByShortID(shortID string, viewerID uuid.UUID) (*Post, error)
+	// GetPosts lists posts, in lang when given, otherwise in the viewer's preferred languages first
+	GetPosts(viewerID uuid.UUID, lang string, page, perPage int) ([]*Post, int, error)
+	GetPostsByAuthor(authorID, viewerID uuid.UUID, page, perPage int) ([]*Post, int, error)
 	GetPublishedPosts(page, perPage int) ([]*Post, int, error)
+	// ListAllPosts lists every post, including archived and unpublished ones, for admins
+	ListAllPosts(page, perPage int) ([]*Post, int, error)
+	// ExportPosts calls fn with every post, including archived ones, oldest first
+	ExportPosts(fn func(*Post) error) error
 	UpdatePost(id, authorID uuid.UUID, req *UpdatePostRequest) (*Post, error)
 	DeletePost(id, authorID uuid.UUID) error
 	PublishPost(id, authorID uuid.UUID) error
 	UnpublishPost(id, authorID uuid.UUID) error
+	ArchivePost(id, authorID uuid.UUID) (*Post, error)
+	// GetSchedule lists the author's scheduled posts between from and to, each a date in the
+	// author's timezone (to is inclusive) or an RFC 3339 time (to is exclusive)
+	GetSchedule(authorID uuid.UUID, from, to string) (*PostSchedule, error)
+	// PublishScheduledPosts publishes the posts whose scheduled time has come
+	PublishScheduledPosts() error
+	UnarchivePost(id, authorID uuid.UUID) (*Post, error)
+	LockPost(id, authorID uuid.UUID, req *PostLockRequest) (*PostLock, error)
+	UnlockPost(id, authorID uuid.UUID, token string) error
+	AutosavePost(id, authorID uuid.UUID, req *AutosavePostRequest) (*PostAutosave, error)
+	GetAutosave(id, authorID uuid.UUID) (*PostAutosave, error)
 	ValidatePost(post *Post) error
+	// SearchPosts searches the posts the viewer may list, suggesting a corrected query when
+	// few match
+	SearchPosts(viewerID uuid.UUID, query string, page, perPage int) (*PostSearchResult, error)
+	// SuggestSearch offers post titles and authors completing a partly typed search
+	SuggestSearch(viewerID uuid.UUID, prefix string, limit int) ([]*SearchSuggestion, error)
+	// ReindexSearch indexes every published post in the external search index, returning how many
+	ReindexSearch() (int, error)
+	// GetFeed gets the published posts of the authors the user follows, newest first
+	GetFeed(userID uuid.UUID, page, perPage int) ([]*Post, int, error)
+	// RebuildFeeds rebuilds every stored feed from the follows, returning how many entries it stored
+	RebuildFeeds() (int64, error)
 }
 
 // CreatePostRequest represents the request to create a post
 type CreatePostRequest struct {
 	Title       string `json:"title" validate:"required,min=1,max=200"`
 	Content     string `json:"content" validate:"required,min=1"`
+	Format      string `json:"content_format,omitempty" validate:"omitempty,oneof=markdown html text"` // Defaults to markdown
 	IsPublished bool   `json:"is_published,omitempty"`
+	Visibility  string `json:"visibility,omitempty" validate:"omitempty,oneof=public unlisted followers private"` // Defaults to public
+	// ScheduledAt publishes the post automatically at an RFC 3339 time, or a local date and
+	// time (2006-01-02T15:04) in the author's timezone
+	ScheduledAt string `json:"scheduled_at,omitempty" validate:"omitempty,max=64"`
 }
 
 // UpdatePostRequest represents the request to update a post
 type UpdatePostRequest struct {
 	Title       string `json:"title,omitempty" validate:"omitempty,min=1,max=200"`
 	Content     string `json:"content,omitempty" validate:"omitempty,min=1"`
+	Format      string `json:"content_format,omitempty" validate:"omitempty,oneof=markdown html text"`
 	IsPublished *bool  `json:"is_published,omitempty"`
+	Visibility  string `json:"visibility,omitempty" validate:"omitempty,oneof=public unlisted followers private"`
+	// ScheduledAt schedules the post like CreatePostRequest.ScheduledAt; empty cancels the schedule
+	ScheduledAt *string `json:"scheduled_at,omitempty" validate:"omitempty,max=64"`
+	// LockToken is required while the post is locked, to prove the save comes from the lock holder
+	LockToken string `json:"lock_token,omitempty" validate:"omitempty,max=128"`
 }
 
 // PostWithAuthor represents a post with author information
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostAutosave is the latest unsaved draft of a post, kept apart from the post itself
+// so autosaving does not touch the post's updated_at
+type PostAutosave struct {
+	PostID  uuid.UUID `json:"post_id" db:"post_id"`
+	Title   string    `json:"title" db:"title"`
+	Content string    `json:"content" db:"content"`
+	SavedAt time.Time `json:"saved_at" db:"saved_at"`
+}
+
+// PostAutosaveRepository defines the interface for post autosave data operations
+type PostAutosaveRepository interface {
+	// Save replaces the autosaved draft of a post
+	Save(autosave *PostAutosave) error
+	GetByPostID(postID uuid.UUID) (*PostAutosave, error)
+	Delete(postID uuid.UUID) error
+}
+
+// AutosavePostRequest represents the request to autosave the draft of a post
+type AutosavePostRequest struct {
+	Title   string `json:"title" validate:"max=200"`
+	Content string `json:"content"`
+	// LockToken is required while the post is locked, as for updates
+	LockToken string `json:"lock_token,omitempty" validate:"omitempty,max=128"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PostLock is a soft lock held by one editing session of a post.
+// It expires unless the session renews it with heartbeats.
+type PostLock struct {
+	PostID     uuid.UUID `json:"post_id" db:"post_id"`
+	UserID     uuid.UUID `json:"locked_by" db:"user_id"`
+	Token      string    `json:"lock_token" db:"token"`
+	AcquiredAt time.Time `json:"acquired_at" db:"acquired_at"`
+	ExpiresAt  time.Time `json:"expires_at" db:"expires_at"`
+}
+
+// PostLockRepository defines the interface for post lock data operations
+type PostLockRepository interface {
+	// Acquire takes or renews the lock, unless another token holds it unexpired (ErrNotFound)
+	Acquire(lock *PostLock) error
+	// GetActive gets the unexpired lock of a post
+	GetActive(postID uuid.UUID) (*PostLock, error)
+	Release(postID uuid.UUID, token string) error
+}
+
+// PostLockRequest represents the request to take or renew a post lock
+type PostLockRequest struct {
+	// LockToken renews the lock held with this token; omitted to take a new lock
+	LockToken string `json:"lock_token,omitempty" validate:"omitempty,max=128"`
+}
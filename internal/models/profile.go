@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Follow records that a user follows another user
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id" db:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id" db:"followee_id"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ProfilePage is everything a public profile page renders: the profile, its counts and
+// the latest published posts (as summaries, without content)
+type ProfilePage struct {
+	Profile        PublicProfile `json:"profile"`
+	PublishedPosts int           `json:"published_posts"`
+	Followers      int           `json:"followers"`
+	LatestPosts    []*Post       `json:"latest_posts"`
+}
+
+// ProfileRepository defines the interface for public profile and follow data operations
+type ProfileRepository interface {
+	// GetPage gets the page of an active user, unless they were born after restrictedBornAfter
+	// (when set) and have no parental consent
+	GetPage(username string, postLimit int, restrictedBornAfter *time.Time) (*ProfilePage, error)
+	Follow(follow *Follow) error
+	Unfollow(followerID, followeeID uuid.UUID) error
+}
+
+// ProfileService defines the interface for public profile business logic
+type ProfileService interface {
+	GetProfilePage(username string) (*ProfilePage, *UsernameRedirect, error)
+	FollowUser(followerID, followeeID uuid.UUID) error
+	UnfollowUser(followerID, followeeID uuid.UUID) error
+}
@@ -0,0 +1,137 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of push topics, named kind:ID
+const (
+	// PushTopicAuthor notifies the posts a user publishes, e.g. author:<user ID>
+	PushTopicAuthor = "author"
+	// PushTopicPost notifies the comments written on a post, e.g. post:<post ID>
+	PushTopicPost = "post"
+)
+
+// PushTopic returns the name of the topic of kind about id
+func PushTopic(kind string, id uuid.UUID) string {
+	return kind + ":" + id.String()
+}
+
+// ParsePushTopic splits a topic name into its kind and ID, returning false when it names no topic
+func ParsePushTopic(topic string) (string, uuid.UUID, bool) {
+	kind, rawID, found := strings.Cut(topic, ":")
+	if !found || (kind != PushTopicAuthor && kind != PushTopicPost) {
+		return "", uuid.Nil, false
+	}
+	id, err := uuid.Parse(rawID)
+	if err != nil || id == uuid.Nil {
+		return "", uuid.Nil, false
+	}
+	return kind, id, true
+}
+
+// PushDevice is an app installation a user receives push notifications on
+type PushDevice struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	UserID   uuid.UUID `json:"-" db:"user_id"`
+	Platform string    `json:"platform" db:"platform"` // fcm or apns
+	// Token is the address of the installation at its push service
+	Token     string    `json:"-" db:"token"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// RegisterDeviceRequest represents the request to receive push notifications on a device
+type RegisterDeviceRequest struct {
+	Platform string `json:"platform" validate:"required,oneof=fcm apns"`
+	Token    string `json:"token" validate:"required,max=4096"`
+}
+
+// PushSubscription is a user's subscription to the notifications of a topic
+type PushSubscription struct {
+	UserID    uuid.UUID `json:"-" db:"user_id"`
+	Topic     string    `json:"topic" db:"topic"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// QuietHours is the daily period, in the user's timezone, during which push notifications
+// are held rather than sent. Start and End are HH:MM; a period ending before it starts spans
+// midnight.
+type QuietHours struct {
+	UserID    uuid.UUID `json:"-"`
+	Start     string    `json:"start"`
+	End       string    `json:"end"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// QuietHoursSettings is a user's quiet hours and the timezone they are read in
+type QuietHoursSettings struct {
+	Enabled  bool   `json:"enabled"`
+	Start    string `json:"start,omitempty"`
+	End      string `json:"end,omitempty"`
+	Timezone string `json:"timezone"`
+}
+
+// UpdateQuietHoursRequest represents the request to set the quiet hours; leaving both times
+// empty turns them off
+type UpdateQuietHoursRequest struct {
+	Start string `json:"start" validate:"required_with=End,omitempty,datetime=15:04"`
+	End   string `json:"end" validate:"required_with=Start,omitempty,datetime=15:04,nefield=Start"`
+}
+
+// HeldPushNotification is a notification held during its recipient's quiet hours
+type HeldPushNotification struct {
+	UserID      uuid.UUID
+	CollapseKey string
+	Title       string
+	Body        string
+	Data        map[string]string
+	ReleaseAt   time.Time
+	CreatedAt   time.Time
+}
+
+// PushRepository defines the interface for push device, topic and quiet hours data operations
+type PushRepository interface {
+	// SaveDevice registers a device for a user, taking its token over from any other user it
+	// was registered for; the ID and creation time of an existing registration are kept
+	SaveDevice(device *PushDevice) error
+	ListDevices(userID uuid.UUID) ([]*PushDevice, error)
+	DeleteDevice(userID, id uuid.UUID) error
+	DeleteDeviceByToken(token string) error
+	// TrimDevices deletes the least recently registered devices of a user beyond the keep newest
+	TrimDevices(userID uuid.UUID, keep int) error
+
+	// Subscribe subscribes a user to a topic, keeping an existing subscription
+	Subscribe(sub *PushSubscription) error
+	Unsubscribe(userID uuid.UUID, topic string) error
+	ListSubscriptions(userID uuid.UUID) ([]*PushSubscription, error)
+	CountSubscriptions(userID uuid.UUID) (int, error)
+	// ListSubscribers lists up to limit users subscribed to a topic with IDs after after, in ID order
+	ListSubscribers(topic string, after uuid.UUID, limit int) ([]uuid.UUID, error)
+
+	GetQuietHours(userID uuid.UUID) (*QuietHours, error)
+	SaveQuietHours(quiet *QuietHours) error
+	DeleteQuietHours(userID uuid.UUID) error
+
+	// Hold stores a notification until its release time, replacing the one held for its user
+	// with the same collapse key
+	Hold(n *HeldPushNotification) error
+	// TakeHeldDue deletes and returns up to limit held notifications released at now or earlier
+	TakeHeldDue(now time.Time, limit int) ([]*HeldPushNotification, error)
+}
+
+// PushService defines the interface for managing push devices, topics and quiet hours
+type PushService interface {
+	RegisterDevice(userID uuid.UUID, req *RegisterDeviceRequest) (*PushDevice, error)
+	ListDevices(userID uuid.UUID) ([]*PushDevice, error)
+	DeleteDevice(userID, id uuid.UUID) error
+	ListTopics(userID uuid.UUID) ([]*PushSubscription, error)
+	// Subscribe subscribes a user to a topic about an existing user or a post they may read
+	Subscribe(userID uuid.UUID, topic string) (*PushSubscription, error)
+	Unsubscribe(userID uuid.UUID, topic string) error
+	GetQuietHours(userID uuid.UUID) (*QuietHoursSettings, error)
+	UpdateQuietHours(userID uuid.UUID, req *UpdateQuietHoursRequest) (*QuietHoursSettings, error)
+}
@@ -0,0 +1,44 @@
+package models
+
+import "github.com/google/uuid"
+
+// Reaction types a user can react to posts and comments with
+const (
+	ReactionLike  = "like"
+	ReactionHeart = "heart"
+	ReactionLaugh = "laugh"
+	ReactionWow   = "wow"
+	ReactionSad   = "sad"
+	ReactionAngry = "angry"
+)
+
+// ReactionTypes lists every reaction type
+var ReactionTypes = []string{ReactionLike, ReactionHeart, ReactionLaugh, ReactionWow, ReactionSad, ReactionAngry}
+
+// ReactionCounts counts the reactions to a post or comment by type; types without reactions are absent
+type ReactionCounts map[string]int
+
+// ReactionRepository defines the interface for reaction data operations.
+// A user has at most one reaction per post or comment; setting another replaces it.
+type ReactionRepository interface {
+	SetForPost(userID, postID uuid.UUID, reaction string) error
+	RemoveForPost(userID, postID uuid.UUID) error
+	SetForComment(userID, commentID uuid.UUID, reaction string) error
+	RemoveForComment(userID, commentID uuid.UUID) error
+	CountForPosts(postIDs []uuid.UUID) (map[uuid.UUID]ReactionCounts, error)
+	CountForComments(commentIDs []uuid.UUID) (map[uuid.UUID]ReactionCounts, error)
+}
+
+// ReactionService defines the interface for reaction business logic.
+// Every change returns the updated counts of the post or comment.
+type ReactionService interface {
+	ReactToPost(postID, userID uuid.UUID, req *ReactionRequest) (ReactionCounts, error)
+	UnreactToPost(postID, userID uuid.UUID) (ReactionCounts, error)
+	ReactToComment(postID, commentID, userID uuid.UUID, req *ReactionRequest) (ReactionCounts, error)
+	UnreactToComment(postID, commentID, userID uuid.UUID) (ReactionCounts, error)
+}
+
+// ReactionRequest represents the request to react to a post or comment
+type ReactionRequest struct {
+	Reaction string `json:"reaction" validate:"required,oneof=like heart laugh wow sad angry"`
+}
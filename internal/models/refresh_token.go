@@ -16,16 +16,33 @@ type RefreshToken struct {
 	IsRevoked bool       `json:"is_revoked" db:"is_revoked"`
 	CreatedAt time.Time  `json:"created_at" db:"created_at"`
 	RevokedAt *time.Time `json:"revoked_at,omitempty" db:"revoked_at"`
+	SessionMetadata
+}
+
+// SessionMetadata describes the client a refresh token (session) was issued to
+type SessionMetadata struct {
+	IPAddress   *string `json:"ip_address,omitempty" db:"ip_address"`
+	UserAgent   *string `json:"user_agent,omitempty" db:"user_agent"`
+	Country     *string `json:"country,omitempty" db:"country"`
+	City        *string `json:"city,omitempty" db:"city"`
+	DeviceID    *string `json:"device_id,omitempty" db:"device_id"`
+	Fingerprint *string `json:"-" db:"fingerprint"`
 }
 
 // RefreshTokenRepository defines the interface for refresh token data operations
 type RefreshTokenRepository interface {
-	Create(tokenID, tokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	Create(tokenID, tokenHash string, userID uuid.UUID, expiresAt time.Time, meta SessionMetadata) error
 	GetByTokenID(tokenID string) (*RefreshToken, error)
 	Revoke(tokenID string) error
 	RevokeAllForUser(userID uuid.UUID) error
+	RevokeAllForUserExcept(userID uuid.UUID, keepTokenID string) error
+	// RevokeIssuedBefore revokes tokens created at or before cutoff, of userIDs or of everyone when empty
+	RevokeIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error)
 	IsValid(tokenID string) (bool, error)
 	IsValidWithLock(tokenID string) (bool, error)
-	RotateToken(oldTokenID, newTokenID, newTokenHash string, userID uuid.UUID, expiresAt time.Time) error
+	RotateToken(oldTokenID, newTokenID, newTokenHash string, userID uuid.UUID, expiresAt time.Time, meta SessionMetadata) error
+	ListActiveForUser(userID uuid.UUID) ([]*RefreshToken, error)
+	RevokeForDevice(userID uuid.UUID, deviceID, fingerprint string) error
+	RevokeOldestBeyond(userID uuid.UUID, keep int) error
 	DeleteExpired() error
 }
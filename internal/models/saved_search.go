@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Saved search alert frequencies
+const (
+	SavedSearchHourly = "hourly"
+	SavedSearchDaily  = "daily"
+	SavedSearchWeekly = "weekly"
+	// SavedSearchOff keeps the search without alerts, as after unsubscribing
+	SavedSearchOff = "off"
+)
+
+// SavedSearch is a full-text query a user is alerted about when newly published posts match it
+type SavedSearch struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	Query     string    `json:"query" db:"query"`         // Web search syntax: words, "quoted phrases", or and -excluded words
+	Frequency string    `json:"frequency" db:"frequency"` // How often matches are sent, or off
+	// CheckedAt is when matches were last looked for; posts published later are new to the user
+	CheckedAt   time.Time `json:"checked_at" db:"checked_at"`
+	NextCheckAt time.Time `json:"next_check_at" db:"next_check_at"`
+	// UnsubscribeTokenHash is the hash of the unsubscribe token in the latest alert
+	UnsubscribeTokenHash *string   `json:"-" db:"unsubscribe_token_hash"`
+	CreatedAt            time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt            time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SavedSearchPeriod returns how long apart the searches of a frequency are checked, and false
+// for frequencies without alerts
+func SavedSearchPeriod(frequency string) (time.Duration, bool) {
+	switch frequency {
+	case SavedSearchHourly:
+		return time.Hour, true
+	case SavedSearchDaily:
+		return 24 * time.Hour, true
+	case SavedSearchWeekly:
+		return 7 * 24 * time.Hour, true
+	default:
+		return 0, false
+	}
+}
+
+// SavedSearchRepository defines the interface for saved search data operations
+type SavedSearchRepository interface {
+	Create(search *SavedSearch) error
+	GetByID(id uuid.UUID) (*SavedSearch, error)
+	GetByUnsubscribeTokenHash(tokenHash string) (*SavedSearch, error)
+	ListByUser(userID uuid.UUID) ([]*SavedSearch, error)
+	CountByUser(userID uuid.UUID) (int, error)
+	// ListDue lists up to limit searches with alerts whose next check is at now or earlier
+	ListDue(now time.Time, limit int) ([]*SavedSearch, error)
+	// Matches gets up to limit posts the search's user may list, published after the search was
+	// checked and until until, oldest first, leaving out the user's own posts
+	Matches(search *SavedSearch, until time.Time, limit int) ([]*Post, error)
+	Update(search *SavedSearch) error
+	Delete(id uuid.UUID) error
+}
+
+// SavedSearchService defines the interface for saved search business logic
+type SavedSearchService interface {
+	CreateSavedSearch(userID uuid.UUID, req *SavedSearchRequest) (*SavedSearch, error)
+	ListSavedSearches(userID uuid.UUID) ([]*SavedSearch, error)
+	UpdateSavedSearch(id, userID uuid.UUID, req *UpdateSavedSearchRequest) (*SavedSearch, error)
+	DeleteSavedSearch(id, userID uuid.UUID) error
+	// Unsubscribe turns off the alerts of the search whose alert carried the token
+	Unsubscribe(token string) error
+	// SendAlerts emails the users of due searches the posts newly matching them
+	SendAlerts() error
+}
+
+// SavedSearchRequest represents the request to save a search
+type SavedSearchRequest struct {
+	Name      string `json:"name" validate:"required,max=100"`
+	Query     string `json:"query" validate:"required,min=1,max=200"`
+	Frequency string `json:"frequency,omitempty" validate:"omitempty,oneof=hourly daily weekly off"` // Defaults to daily
+}
+
+// UpdateSavedSearchRequest represents the request to change a saved search; empty fields are kept
+type UpdateSavedSearchRequest struct {
+	Name      string `json:"name,omitempty" validate:"omitempty,max=100"`
+	Query     string `json:"query,omitempty" validate:"omitempty,min=1,max=200"`
+	Frequency string `json:"frequency,omitempty" validate:"omitempty,oneof=hourly daily weekly off"`
+}
@@ -0,0 +1,46 @@
+package models
+
+// Token scopes limit what an access token or API key may do
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+	ScopeUsersAdmin = "users:admin"
+)
+
+// AllScopes lists every scope known to the API
+var AllScopes = []string{ScopePostsRead, ScopePostsWrite, ScopeUsersRead, ScopeUsersWrite, ScopeUsersAdmin}
+
+// IsValidScope returns true if scope is known to the API
+func IsValidScope(scope string) bool {
+	for _, s := range AllScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesForRole returns the scopes granted to user sessions with the given role. Sandbox
+// accounts may read and write posts, which are kept private to them, but not change users.
+func ScopesForRole(role string) []string {
+	if role == RoleSandbox {
+		return []string{ScopePostsRead, ScopePostsWrite, ScopeUsersRead}
+	}
+	scopes := []string{ScopePostsRead, ScopePostsWrite, ScopeUsersRead, ScopeUsersWrite}
+	if role == RoleAdmin {
+		scopes = append(scopes, ScopeUsersAdmin)
+	}
+	return scopes
+}
+
+// HasScope returns true if scope is one of scopes
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
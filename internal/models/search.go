@@ -0,0 +1,28 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// Search suggestion types
+const (
+	SearchSuggestionTitle  = "title"
+	SearchSuggestionAuthor = "author"
+)
+
+// SearchSuggestion is a completion offered for a partly typed search
+type SearchSuggestion struct {
+	Type string `json:"type"` // title or author
+	Text string `json:"text"` // The post title or the author's username
+	// PostID and ShortID are the post of a title suggestion
+	PostID  *uuid.UUID `json:"post_id,omitempty"`
+	ShortID string     `json:"short_id,omitempty"`
+}
+
+// PostSearchResult is a page of posts matching a search
+type PostSearchResult struct {
+	Posts []*Post
+	Total int
+	// DidYouMean is the query with misspelled words corrected, offered when few posts match
+	DidYouMean string
+}
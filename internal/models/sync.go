@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SyncCheckpoint is a position in the change feed: changes at At with an ID above ID, and
+// every change after At, come after it. A checkpoint from a plain timestamp has a nil ID.
+type SyncCheckpoint struct {
+	At time.Time
+	ID uuid.UUID
+}
+
+// PostChange is a post that changed after a checkpoint, at its last change
+type PostChange struct {
+	ID        uuid.UUID
+	ChangedAt time.Time
+	CreatedAt *time.Time // Nil when the post was deleted
+	Visible   bool       // False when the post was deleted or archived
+}
+
+// SyncChanges lists the posts created, updated and deleted since a checkpoint, oldest change
+// first. Clients apply them and pass Cursor as the next since; while HasMore is set, more
+// changes are waiting.
+type SyncChanges struct {
+	// Created lists posts created after the checkpoint. The first change of a post created
+	// during an earlier page may be listed in Updated instead, so clients upsert both.
+	Created []uuid.UUID `json:"created"`
+	Updated []uuid.UUID `json:"updated"`
+	// Deleted lists posts deleted or archived after the checkpoint
+	Deleted []uuid.UUID `json:"deleted"`
+	Cursor  string      `json:"cursor"`
+	HasMore bool        `json:"has_more"`
+}
+
+// SyncRepository defines the interface for change feed data operations
+type SyncRepository interface {
+	// PostChanges gets up to limit posts the viewer may list changed after a checkpoint,
+	// oldest change first
+	PostChanges(since SyncCheckpoint, viewerID uuid.UUID, limit int) ([]*PostChange, error)
+}
+
+// SyncService defines the interface for the change feed of sync clients
+type SyncService interface {
+	// PostChanges lists the changes of posts the viewer may list after since, an RFC 3339
+	// timestamp or a cursor returned by an earlier call; an empty since starts from the beginning
+	PostChanges(since string, viewerID uuid.UUID, limit int) (*SyncChanges, error)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevokeTokensRequest represents the request to revoke tokens in bulk, e.g. after a credential
+// leak. Without user IDs the tokens of every user are revoked; without issued_before, every
+// token issued so far.
+type RevokeTokensRequest struct {
+	UserIDs      []uuid.UUID `json:"user_ids,omitempty" validate:"omitempty,max=10000"`
+	IssuedBefore *time.Time  `json:"issued_before,omitempty"`
+}
+
+// TokenRevocation reports a bulk token revocation
+type TokenRevocation struct {
+	// Cutoff is the issue time at or before which tokens were revoked
+	Cutoff time.Time `json:"cutoff"`
+	// Users counts the users whose access tokens are now denied
+	Users int64 `json:"users"`
+	// Sessions counts the refresh tokens revoked
+	Sessions int64 `json:"sessions"`
+	// Clients counts the machine clients whose access tokens are now denied, when every
+	// user's tokens were revoked
+	Clients int64 `json:"clients"`
+}
+
+// IncidentMode is the state of security incident mode. While it is enabled, access tokens are
+// issued with a shortened lifetime; enabling it revokes every token issued before.
+type IncidentMode struct {
+	Enabled        bool       `json:"enabled"`
+	AccessTokenTTL string     `json:"access_token_ttl"` // Lifetime of access tokens issued now
+	EnabledAt      *time.Time `json:"enabled_at,omitempty"`
+	EnabledBy      *uuid.UUID `json:"enabled_by,omitempty"`
+	// Revocation reports the tokens revoked when incident mode was enabled
+	Revocation *TokenRevocation `json:"revocation,omitempty"`
+}
+
+// UpdateIncidentModeRequest represents the request to enable or disable incident mode
+type UpdateIncidentModeRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+}
+
+// TokenRevocationService defines the interface for revoking tokens in response to incidents
+type TokenRevocationService interface {
+	RevokeTokens(adminID uuid.UUID, req *RevokeTokensRequest, client ClientInfo) (*TokenRevocation, error)
+	IncidentMode() *IncidentMode
+	// SetIncidentMode enables or disables incident mode; enabling it revokes every token
+	// issued so far, forcing everyone to sign in again
+	SetIncidentMode(adminID uuid.UUID, enabled bool, client ClientInfo) (*IncidentMode, error)
+	// Refresh reloads the state of incident mode, picking up changes made on other instances
+	Refresh() error
+}
+
+// IncidentModeRepository stores the state of incident mode, shared by every instance
+type IncidentModeRepository interface {
+	// Get returns the stored state, disabled when none is stored; AccessTokenTTL is left empty
+	Get() (*IncidentMode, error)
+	// Enable stores incident mode as enabled, replacing the state stored before
+	Enable(mode *IncidentMode) error
+	Disable() error
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Resource types recorded in deleted_records
+const (
+	ResourcePost    = "post"
+	ResourceComment = "comment"
+	ResourceUser    = "user"
+)
+
+// DeletedRecord is the tombstone of a deleted post, comment or user. Tombstones are written by
+// the database on every delete, cascades included, and pruned once past their retention.
+type DeletedRecord struct {
+	ResourceType string    `json:"resource_type" db:"resource_type"`
+	ResourceID   uuid.UUID `json:"resource_id" db:"resource_id"`
+	DeletedAt    time.Time `json:"deleted_at" db:"deleted_at"`
+}
+
+// DeletedRecordRepository defines the interface for tombstone data operations
+type DeletedRecordRepository interface {
+	// Prune deletes the tombstones of records deleted before cutoff
+	Prune(cutoff time.Time) (int64, error)
+}
+
+// TombstoneService defines the interface for tombstone retention
+type TombstoneService interface {
+	// Prune deletes the tombstones past their retention and reports how many were deleted
+	Prune() (int64, error)
+	// Horizon is the oldest point deletions are still known from; the zero time while
+	// tombstones are kept forever
+	Horizon() time.Time
+}
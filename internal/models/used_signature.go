@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// UsedSignatureRepository records the signatures of signed requests that were accepted. It is
+// shared by every instance, so a signature is accepted once whichever instance verifies it.
+type UsedSignatureRepository interface {
+	// Use records signature as used until expiresAt, reporting false if it already was
+	Use(signature string, expiresAt time.Time) (bool, error)
+	// DeleteExpired forgets the signatures that expired before now, returning how many
+	DeleteExpired(now time.Time) (int64, error)
+}
@@ -3,34 +3,186 @@ package models
 import (
 	"time"
 
+	"go-backend-api/internal/pkg/fieldcrypt"
+
 	"github.com/google/uuid"
 )
 
+// User roles
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+	// RoleSandbox is held by the throwaway accounts of the API console demo mode
+	RoleSandbox = "sandbox"
+)
+
+// SandboxEmailDomain is the domain of the addresses of sandbox accounts, which cannot receive mail
+const SandboxEmailDomain = "sandbox.invalid"
+
 // User represents a user entity
 type User struct {
-	ID        uuid.UUID  `json:"id" db:"id"`
-	Username  string     `json:"username" db:"username"`
-	Email     string     `json:"email" db:"email"`
-	Password  string     `json:"-" db:"password"` // Hidden from JSON output
-	IsActive  bool       `json:"is_active" db:"is_active"`
-	LastLogin *time.Time `json:"last_login,omitempty" db:"last_login"`
-	CreatedAt time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+	ID           uuid.UUID `json:"id" db:"id"`
+	Username     string    `json:"username" db:"username"`
+	Email        string    `json:"email" db:"email"`
+	PendingEmail *string   `json:"pending_email,omitempty" db:"pending_email"`
+	// EmailUndeliverable is why mail to Email is suppressed (EmailBounce or EmailComplaint),
+	// nil while it is deliverable
+	EmailUndeliverable   *string    `json:"email_undeliverable,omitempty" db:"email_undeliverable"`
+	EmailUndeliverableAt *time.Time `json:"email_undeliverable_at,omitempty" db:"email_undeliverable_at"`
+	Password             string     `json:"-" db:"password"` // Hidden from JSON output
+	Role                 string     `json:"role" db:"role"`
+	Plan                 string     `json:"plan" db:"plan"`
+	IsActive             bool       `json:"is_active" db:"is_active"`
+	DeactivatedAt        *time.Time `json:"deactivated_at,omitempty" db:"deactivated_at"`
+	MustChangePassword   bool       `json:"must_change_password" db:"must_change_password"`
+	LastLogin            *time.Time `json:"last_login,omitempty" db:"last_login"`
+	LastSeenAt           *time.Time `json:"last_seen_at,omitempty" db:"last_seen_at"`
+	InactivityWarnedAt   *time.Time `json:"inactivity_warned_at,omitempty" db:"inactivity_warned_at"`
+	// PhoneNumber is encrypted at rest and looked up by PhoneNumberIndex, its blind index
+	PhoneNumber      fieldcrypt.String `json:"phone_number,omitempty" db:"phone_number"`
+	PhoneNumberIndex *string           `json:"-" db:"phone_number_index"`
+	// Birthdate is optional and only given at registration; a user under the parental consent
+	// age is restricted until ParentalConsentAt is set
+	Birthdate         *time.Time `json:"birthdate,omitempty" db:"birthdate"`
+	ParentalConsentAt *time.Time `json:"parental_consent_at,omitempty" db:"parental_consent_at"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Sanitize clears credentials that must never leave the service layer and returns the user
+func (u *User) Sanitize() *User {
+	u.Password = ""
+	return u
+}
+
+// IsAdmin returns true if the user has the admin role
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// LastActivityAt returns the most recent known activity of the user, falling back to the signup time
+func (u *User) LastActivityAt() time.Time {
+	if u.LastSeenAt != nil {
+		return *u.LastSeenAt
+	}
+	if u.LastLogin != nil {
+		return *u.LastLogin
+	}
+	return u.CreatedAt
+}
+
+// AgeOn returns the age in full years of someone born on birthdate, on the date of t
+func AgeOn(birthdate, t time.Time) int {
+	y1, m1, d1 := birthdate.Date()
+	y2, m2, d2 := t.Date()
+	age := y2 - y1
+	if m2 < m1 || (m2 == m1 && d2 < d1) {
+		age--
+	}
+	return age
+}
+
+// ParentalConsentCutoff returns the birthdate after which users are under consentAge on the
+// date of now, or nil when consentAge is 0
+func ParentalConsentCutoff(consentAge int, now time.Time) *time.Time {
+	if consentAge <= 0 {
+		return nil
+	}
+	y, m, d := now.UTC().AddDate(-consentAge, 0, 0).Date()
+	cutoff := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	return &cutoff
+}
+
+// NeedsParentalConsent returns true if the user is under consentAge and no parent has
+// consented yet. Users without a birthdate, or with a consentAge of 0, never need consent.
+func (u *User) NeedsParentalConsent(consentAge int, now time.Time) bool {
+	cutoff := ParentalConsentCutoff(consentAge, now)
+	if cutoff == nil || u.Birthdate == nil || u.ParentalConsentAt != nil {
+		return false
+	}
+	return u.Birthdate.After(*cutoff)
+}
+
+// Policies for the posts of deleted users
+const (
+	DeletedUserPostsDelete    = "delete"
+	DeletedUserPostsAnonymize = "anonymize"
+)
+
+// UserDeletion describes the deletion of a user and what happened to their data
+type UserDeletion struct {
+	UserID      uuid.UUID `json:"user_id"`
+	Username    string    `json:"username"`
+	Reason      string    `json:"reason"`       // e.g. "self" or "lifecycle"
+	PostsPolicy string    `json:"posts_policy"` // DeletedUserPostsDelete or DeletedUserPostsAnonymize
+	Posts       int       `json:"posts"`
+	Sessions    int       `json:"sessions"`
+}
+
+// UserTokenState is what access token checks need to know about a user
+type UserTokenState struct {
+	IsActive bool
+	// Role is the user's current role, which may differ from the one their tokens carry
+	Role string
+	// TokensDeniedBefore rejects access tokens issued at or before it, e.g. on deactivation
+	TokensDeniedBefore *time.Time
 }
 
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	Create(user *User) error
+	// CreateBatch creates users atomically, generating IDs for users without one
+	CreateBatch(users []*User) error
 	GetByID(id uuid.UUID) (*User, error)
 	GetByEmail(email string) (*User, error)
 	GetByUsername(username string) (*User, error)
 	Update(user *User) error
 	Delete(id uuid.UUID) error
+	// DeleteCascade deletes a user together with their sessions, and deletes or anonymizes their
+	// posts according to deletion.PostsPolicy, recording an audit entry in the same transaction.
+	// It fills in the username and counts of deletion.
+	DeleteCascade(deletion *UserDeletion, client ClientInfo) error
 	ExistsByEmail(email string) (bool, error)
 	ExistsByUsername(username string) (bool, error)
+	ExistsByConfusableUsername(username string, excludeID uuid.UUID) (bool, error)
+	// ExistsByPhoneNumberIndex checks if another user has the phone number of a blind index
+	ExistsByPhoneNumberIndex(index string, excludeID uuid.UUID) (bool, error)
+	// ReencryptPhoneNumbers re-encrypts up to limit phone numbers not encrypted with the
+	// active key, returning how many were
+	ReencryptPhoneNumbers(activeKeyID string, limit int) (int, error)
+	// TakenEmails and TakenUsernames check many identifiers at once, returning those that
+	// ExistsByEmail, or ExistsByUsername and ExistsByConfusableUsername, would report
+	TakenEmails(emails []string) (map[string]bool, error)
+	TakenUsernames(usernames []string) (map[string]bool, error)
 	UpdateLastLogin(id uuid.UUID) error
+	UpdateLastSeen(id uuid.UUID, seenAt time.Time, throttle time.Duration) error
+	List(limit, offset int) ([]*User, error)
+	// Export calls fn with every user, oldest first, reading them in batches
+	Export(fn func(*User) error) error
+	Count() (int, error)
 	Activate(id uuid.UUID) error
 	Deactivate(id uuid.UUID) error
+	UpdatePassword(id uuid.UUID, hashedPassword string) error
+	SetMustChangePassword(id uuid.UUID, mustChange bool) error
+	MustChangePassword(id uuid.UUID) (bool, error)
+	DenyTokensIssuedBefore(id uuid.UUID, cutoff time.Time) error
+	// DenyAllTokensIssuedBefore denies tokens issued at or before cutoff of userIDs, or of everyone
+	// when empty, returning how many users were updated
+	DenyAllTokensIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error)
+	GetTokenState(id uuid.UUID) (*UserTokenState, error)
+	ListInactiveSince(cutoff time.Time) ([]*User, error)
+	// ListIDsByRoleCreatedBefore lists the IDs of up to limit users with role created before cutoff, oldest first
+	ListIDsByRoleCreatedBefore(role string, cutoff time.Time, limit int) ([]uuid.UUID, error)
+	MarkInactivityWarned(id uuid.UUID, warnedAt time.Time) error
+	// MarkEmailUndeliverable marks the address of the user with email as undeliverable for
+	// reason, returning false when no user has it. A complaint is never downgraded to a bounce.
+	MarkEmailUndeliverable(email, reason string, at time.Time) (bool, error)
+	ClearEmailUndeliverable(id uuid.UUID) error
+	// IsEmailUndeliverable checks if the address of a user with email is marked undeliverable
+	IsEmailUndeliverable(email string) (bool, error)
+	UpdatePlan(id uuid.UUID, plan string) error
+	GetPreferences(id uuid.UUID) (*UserPreferences, error)
+	UpdatePreferences(id uuid.UUID, prefs *UserPreferences) error
 }
 
 // UserService defines the interface for user business logic
@@ -39,37 +191,138 @@ type UserService interface {
 	GetUserByID(id uuid.UUID) (*User, error)
 	GetUserByEmail(email string) (*User, error)
 	UpdateUser(id uuid.UUID, req *UpdateUserRequest) (*User, error)
-	DeleteUser(id uuid.UUID) error
+	DeleteUser(id uuid.UUID, client ClientInfo) error
 	ValidateUser(user *User) error
-	AuthenticateUser(req *LoginRequest) (*LoginResponse, error)
+	AuthenticateUser(req *LoginRequest) (*LoginResponse, *StepUpChallenge, error)
+	VerifyLogin(req *VerifyLoginRequest) (*LoginResponse, error)
+	// AuthenticateBreakGlass signs an admin in with an enabled break-glass credential, without step-up verification
+	AuthenticateBreakGlass(req *BreakGlassLoginRequest) (*LoginResponse, error)
+	// CreateSandboxUser creates a throwaway account with the sandbox role and signs it in
+	CreateSandboxUser(client ClientInfo) (*LoginResponse, error)
+	// PurgeSandboxUsers deletes the sandbox accounts created before cutoff, returning how many were
+	PurgeSandboxUsers(cutoff time.Time) (int, error)
+	ListSessions(userID uuid.UUID) ([]*RefreshToken, error)
 	RefreshToken(req *RefreshTokenRequest) (*LoginResponse, error)
 	Logout(userID uuid.UUID, tokenID string) error
 	ActivateUser(id uuid.UUID) error
 	DeactivateUser(id uuid.UUID) error
+	GetPublicProfile(username string) (*PublicProfile, *UsernameRedirect, error)
+	ListUsers(page, perPage int) ([]*User, int, error)
+	// ExportUsers calls fn with every user, sanitized, oldest first
+	ExportUsers(fn func(*User) error) error
+	ChangePassword(id uuid.UUID, tokenID string, req *ChangePasswordRequest) error
+	ForcePasswordReset(id uuid.UUID) error
+	// ImportUsers creates users with generated temporary passwords that must be changed on first
+	// sign-in, reporting the outcome of every row. A dry run only validates the rows.
+	ImportUsers(rows []*ImportUserRow, dryRun bool) (*UserImportReport, error)
+	ConfirmEmailChange(token string) (*User, error)
+	UndoEmailChange(token string) (*User, error)
+	// RequestParentalConsent emails a consent link to the parent of a user under the parental consent age
+	RequestParentalConsent(id uuid.UUID, req *ParentalConsentRequest) error
+	// ConfirmParentalConsent lifts the restrictions of an account using the token sent to the parent
+	ConfirmParentalConsent(token string, client ClientInfo) error
+	CheckPasswordStrength(req *PasswordStrengthRequest) (*PasswordStrengthResponse, error)
+	GetPreferences(id uuid.UUID) (*UserPreferences, error)
+	UpdatePreferences(id uuid.UUID, req *UpdatePreferencesRequest) (*UserPreferences, error)
+	// RotateEncryptionKeys re-encrypts encrypted user fields still under an old key
+	RotateEncryptionKeys() error
 }
 
 // CreateUserRequest represents the request to create a user
 type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,username"`
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,password"`
+	Email    string `json:"email" validate:"required,email" normalize:"email"`
+	Password string `json:"password" validate:"required,password" normalize:"-"`
+	// InviteCode is required when registration is invite-only
+	InviteCode string `json:"invite_code,omitempty"`
+	// CaptchaToken is required after repeated failures from the client when CAPTCHA is enabled
+	CaptchaToken string `json:"captcha_token,omitempty"`
+	// AcceptedPolicies maps policy kinds to the accepted versions; every current policy must be accepted
+	AcceptedPolicies map[string]string `json:"accepted_policies,omitempty"`
+	// Birthdate (YYYY-MM-DD) may be required by the age gate. Under the parental consent age,
+	// ParentEmail is sent a consent link; the account is restricted until it is confirmed.
+	Birthdate   string `json:"birthdate,omitempty" validate:"omitempty,datetime=2006-01-02"`
+	ParentEmail string `json:"parent_email,omitempty" validate:"omitempty,email" normalize:"email"`
 }
 
 // UpdateUserRequest represents the request to update a user
 type UpdateUserRequest struct {
 	Username string `json:"username,omitempty" validate:"omitempty,username"`
-	Email    string `json:"email,omitempty" validate:"omitempty,email"`
+	Email    string `json:"email,omitempty" validate:"omitempty,email" normalize:"email"`
+	// PhoneNumber is in E.164 format, e.g. +14155552671
+	PhoneNumber string `json:"phone_number,omitempty" validate:"omitempty,e164"`
+}
+
+// DefaultTimezone is the timezone of users who have not chosen one
+const DefaultTimezone = "UTC"
+
+// UserPreferences holds a user's personalization settings
+type UserPreferences struct {
+	// PreferredLanguages are ISO 639-1 codes whose posts the default feed lists first
+	PreferredLanguages []string `json:"preferred_languages" db:"preferred_languages"`
+	// Timezone is the IANA name of the timezone times are shown in to the user, in emails
+	// and exports; API timestamps stay in UTC
+	Timezone string `json:"timezone" db:"timezone"`
+}
+
+// Location returns the user's timezone, UTC when it is unset or no longer known
+func (p *UserPreferences) Location() *time.Location {
+	if p == nil || p.Timezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(p.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// UpdatePreferencesRequest represents the request to update the current user's preferences
+type UpdatePreferencesRequest struct {
+	PreferredLanguages []string `json:"preferred_languages" validate:"max=10,dive,len=2,lowercase,alpha"`
+	// Timezone is an IANA name such as Asia/Ho_Chi_Minh; UTC when omitted
+	Timezone string `json:"timezone,omitempty" validate:"omitempty,max=64,timezone"`
+}
+
+// ChangePasswordRequest represents the request to change the current user's password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required" normalize:"-"`
+	NewPassword     string `json:"new_password" validate:"required,password" normalize:"-"`
+}
+
+// PasswordStrengthRequest represents the request to estimate a password's strength.
+// Username and email are optional and make guesses based on them count against the password.
+type PasswordStrengthRequest struct {
+	Password string `json:"password" validate:"required,max=256" normalize:"-"`
+	Username string `json:"username,omitempty" validate:"omitempty,max=255"`
+	Email    string `json:"email,omitempty" validate:"omitempty,max=255" normalize:"email"`
+}
+
+// PasswordStrengthResponse represents the estimated strength of a password
+type PasswordStrengthResponse struct {
+	// Score ranges from 0 (too guessable) to 4 (very unguessable)
+	Score        int      `json:"score"`
+	GuessesLog10 float64  `json:"guesses_log10"`
+	CrackTime    string   `json:"crack_time"`
+	Warning      string   `json:"warning,omitempty"`
+	Suggestions  []string `json:"suggestions,omitempty"`
+	// MeetsPolicy reports whether the password would be accepted at registration
+	MeetsPolicy bool `json:"meets_policy"`
 }
 
 // LoginRequest represents the request to login a user
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email    string `json:"email" validate:"required,email" normalize:"email"`
+	Password string `json:"password" validate:"required" normalize:"-"`
+	// CaptchaToken is required after repeated failures from the client when CAPTCHA is enabled
+	CaptchaToken string     `json:"captcha_token,omitempty"`
+	Client       ClientInfo `json:"-"`
 }
 
 // RefreshTokenRequest represents the request to refresh a token
 type RefreshTokenRequest struct {
-	RefreshToken string `json:"refresh_token" validate:"required"`
+	RefreshToken string     `json:"refresh_token" validate:"required"`
+	Client       ClientInfo `json:"-"`
 }
 
 // LoginResponse represents the response after successful login
@@ -85,6 +338,33 @@ type LoginResponse struct {
 type TokenClaims struct {
 	UserID   uuid.UUID `json:"user_id"`
 	Username string    `json:"username"`
+	Role     string    `json:"role"`
+	Plan     string    `json:"plan,omitempty"` // Plan of the user when the token was issued
 	TokenID  string    `json:"token_id"`
 	Type     string    `json:"type"` // "access" or "refresh"
+	Scopes   []string  `json:"scopes,omitempty"`
+	// ClientID is set instead of the user fields for tokens issued to machine clients
+	ClientID string `json:"client_id,omitempty"`
+	// APIKeyID is set when the request was authenticated with an API key instead of a JWT
+	APIKeyID *uuid.UUID `json:"api_key_id,omitempty"`
+	// Custom holds deployment-specific claims added by a claims enricher (e.g. tenant_id)
+	Custom map[string]interface{} `json:"custom,omitempty"`
+	// IssuedAt is when a JWT was issued (second precision); it is zero for API keys
+	IssuedAt time.Time `json:"-"`
+}
+
+// IsClient returns true if the token was issued to a machine client rather than a user
+func (c *TokenClaims) IsClient() bool {
+	return c.ClientID != ""
+}
+
+// HasScope returns true if the token grants scope
+func (c *TokenClaims) HasScope(scope string) bool {
+	return HasScope(c.Scopes, scope)
+}
+
+// CustomClaim returns a custom claim as a string, or an empty string if it is absent or not a string
+func (c *TokenClaims) CustomClaim(name string) string {
+	value, _ := c.Custom[name].(string)
+	return value
 }
@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+// Outcomes of a row in a user import
+const (
+	ImportRowCreated = "created"
+	ImportRowValid   = "valid" // Would be created; only reported by dry runs
+	ImportRowFailed  = "failed"
+)
+
+// ImportUserRow is a user to create in a bulk import, read from a CSV record or NDJSON line
+type ImportUserRow struct {
+	Username string `json:"username" validate:"required,username"`
+	Email    string `json:"email" validate:"required,email" normalize:"email"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=user admin"` // Defaults to user
+}
+
+// ImportRowResult is the outcome of a row in a user import
+type ImportRowResult struct {
+	Row      int        `json:"row"` // 1-based, not counting the CSV header
+	Username string     `json:"username"`
+	Email    string     `json:"email"`
+	Status   string     `json:"status"`
+	UserID   *uuid.UUID `json:"user_id,omitempty"`
+	// TemporaryPassword is only returned once; the user must change it on first sign-in
+	TemporaryPassword string `json:"temporary_password,omitempty"`
+	Error             string `json:"error,omitempty"`
+}
+
+// UserImportReport summarizes a user import
+type UserImportReport struct {
+	DryRun  bool               `json:"dry_run"`
+	Total   int                `json:"total"`
+	Created int                `json:"created"`
+	Valid   int                `json:"valid"`
+	Failed  int                `json:"failed"`
+	Rows    []*ImportRowResult `json:"rows"`
+}
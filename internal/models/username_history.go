@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UsernameHistory represents a previous username of a user
+type UsernameHistory struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	OldUsername   string    `json:"old_username" db:"old_username"`
+	ChangedAt     time.Time `json:"changed_at" db:"changed_at"`
+	ReservedUntil time.Time `json:"reserved_until" db:"reserved_until"`
+}
+
+// UsernameHistoryRepository defines the interface for username history data operations
+type UsernameHistoryRepository interface {
+	Create(entry *UsernameHistory) error
+	GetLatestByOldUsername(username string) (*UsernameHistory, error)
+	IsReserved(username string, excludeUserID uuid.UUID) (bool, error)
+	// ReservedAmong checks many usernames at once, returning those IsReserved would report for a new account
+	ReservedAmong(usernames []string) (map[string]bool, error)
+	GetByUserID(userID uuid.UUID) ([]*UsernameHistory, error)
+}
+
+// PublicProfile represents the publicly visible part of a user profile
+type PublicProfile struct {
+	ID        uuid.UUID `json:"id"`
+	Username  string    `json:"username"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UsernameRedirect is returned when a profile is looked up by a previous username
+type UsernameRedirect struct {
+	OldUsername string `json:"old_username"`
+	Username    string `json:"username"`
+	Location    string `json:"location,omitempty"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// WebhookClaim is the outcome of claiming an inbound webhook event
+type WebhookClaim int
+
+const (
+	// WebhookClaimed means the caller claimed the event and must process it
+	WebhookClaimed WebhookClaim = iota
+	// WebhookInFlight means another delivery of the event is being processed
+	WebhookInFlight
+	// WebhookProcessed means the event was processed
+	WebhookProcessed
+)
+
+// WebhookEventRepository records the inbound webhook events processed per provider, so that
+// redelivered events are processed once
+type WebhookEventRepository interface {
+	// Claim records at now that an event is being processed. An event whose processing was
+	// claimed before expiredBefore and never completed, say by an instance that crashed, is
+	// claimed again.
+	Claim(provider, eventID string, now, expiredBefore time.Time) (WebhookClaim, error)
+	// Complete records at now that a claimed event was processed
+	Complete(provider, eventID string, now time.Time) error
+	// Release forgets a claimed event whose processing failed, so its redelivery is processed
+	Release(provider, eventID string) error
+	// DeleteReceivedBefore forgets the events received before cutoff, returning how many
+	DeleteReceivedBefore(cutoff time.Time) (int64, error)
+}
@@ -5,9 +5,13 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math/big"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
@@ -21,6 +25,44 @@ type JWTManager struct {
 	refreshDuration  time.Duration
 	issuer           string
 	audience         string
+	enricher         ClaimsEnricher
+	plans            models.PlanResolver
+	accessLimit      atomic.Int64 // Cap on accessDuration set by LimitAccessDuration, 0 for none
+	clock            clock.Clock
+}
+
+// ClaimsEnricher returns additional claims (e.g. tenant ID) to embed in a user's access tokens.
+// Claims that collide with the registered claims issued by JWTManager are ignored.
+type ClaimsEnricher func(user *models.User) (map[string]interface{}, error)
+
+// StaticClaims returns a ClaimsEnricher adding the same claims to every access token
+func StaticClaims(claims map[string]string) ClaimsEnricher {
+	return func(user *models.User) (map[string]interface{}, error) {
+		custom := make(map[string]interface{}, len(claims))
+		for name, value := range claims {
+			custom[name] = value
+		}
+		return custom, nil
+	}
+}
+
+// reservedClaims are set by JWTManager itself and cannot be overridden by a ClaimsEnricher
+var reservedClaims = map[string]bool{
+	"user_id":   true,
+	"username":  true,
+	"role":      true,
+	"plan":      true,
+	"token_id":  true,
+	"type":      true,
+	"scope":     true,
+	"client_id": true,
+	"iss":       true,
+	"aud":       true,
+	"exp":       true,
+	"iat":       true,
+	"nbf":       true,
+	"sub":       true,
+	"jti":       true,
 }
 
 // TokenPair represents access and refresh token pair
@@ -40,9 +82,27 @@ func NewJWTManager(accessSecret, refreshSecret, issuer, audience string, accessD
 		refreshDuration:  refreshDuration,
 		issuer:           issuer,
 		audience:         audience,
+		clock:            clock.Real,
 	}
 }
 
+// SetClock makes tokens be issued and checked for expiry at the time of c instead of the
+// system clock
+func (j *JWTManager) SetClock(c clock.Clock) {
+	j.clock = clock.OrReal(c)
+}
+
+// SetClaimsEnricher registers a hook adding custom claims to access tokens generated by GenerateTokenPair
+func (j *JWTManager) SetClaimsEnricher(enricher ClaimsEnricher) {
+	j.enricher = enricher
+}
+
+// SetPlanResolver makes access tokens carry the plan resolved by plans, instead of the plan
+// stored on the user
+func (j *JWTManager) SetPlanResolver(plans models.PlanResolver) {
+	j.plans = plans
+}
+
 // GenerateTokenPair generates both access and refresh tokens
 func (j *JWTManager) GenerateTokenPair(user *models.User) (*TokenPair, error) {
 	// Generate unique token ID for tracking
@@ -51,8 +111,25 @@ func (j *JWTManager) GenerateTokenPair(user *models.User) (*TokenPair, error) {
 		return nil, fmt.Errorf("failed to generate token ID: %w", err)
 	}
 
+	plan := user.Plan
+	if j.plans != nil {
+		plan, err = j.plans.ResolvePlan(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve plan: %w", err)
+		}
+	}
+
+	// Collect custom claims for the access token
+	var custom map[string]interface{}
+	if j.enricher != nil {
+		custom, err = j.enricher(user)
+		if err != nil {
+			return nil, fmt.Errorf("failed to enrich token claims: %w", err)
+		}
+	}
+
 	// Generate access token
-	accessToken, err := j.generateAccessToken(user, tokenID)
+	accessToken, err := j.generateAccessToken(user, plan, tokenID, custom)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -67,29 +144,66 @@ func (j *JWTManager) GenerateTokenPair(user *models.User) (*TokenPair, error) {
 		AccessToken:  accessToken,
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    int(j.accessDuration.Seconds()),
+		ExpiresIn:    int(j.GetAccessDuration().Seconds()),
 	}, nil
 }
 
 // generateAccessToken creates an access token
-func (j *JWTManager) generateAccessToken(user *models.User, tokenID string) (string, error) {
+func (j *JWTManager) generateAccessToken(user *models.User, plan, tokenID string, custom map[string]interface{}) (string, error) {
 	claims := &models.TokenClaims{
 		UserID:   user.ID,
 		Username: user.Username,
+		Role:     user.Role,
+		Plan:     plan,
 		TokenID:  tokenID,
 		Type:     "access",
+		Scopes:   models.ScopesForRole(user.Role),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	now := j.clock.Now()
+	mapClaims := jwt.MapClaims{
 		"user_id":  claims.UserID.String(),
 		"username": claims.Username,
+		"role":     claims.Role,
+		"plan":     claims.Plan,
 		"token_id": claims.TokenID,
 		"type":     claims.Type,
+		"scope":    strings.Join(claims.Scopes, " "),
 		"iss":      j.issuer,
 		"aud":      j.audience,
-		"exp":      time.Now().Add(j.accessDuration).Unix(),
-		"iat":      time.Now().Unix(),
-		"nbf":      time.Now().Unix(),
+		"exp":      now.Add(j.GetAccessDuration()).Unix(),
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
+	}
+	for name, value := range custom {
+		if !reservedClaims[name] {
+			mapClaims[name] = value
+		}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, mapClaims)
+
+	return token.SignedString([]byte(j.accessSecretKey))
+}
+
+// GenerateClientToken generates an access token for a machine client. It has no user and no refresh token.
+func (j *JWTManager) GenerateClientToken(clientID string, scopes []string) (string, error) {
+	tokenID, err := generateTokenID()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token ID: %w", err)
+	}
+
+	now := j.clock.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"client_id": clientID,
+		"token_id":  tokenID,
+		"type":      "access",
+		"scope":     strings.Join(scopes, " "),
+		"iss":       j.issuer,
+		"aud":       j.audience,
+		"exp":       now.Add(j.GetAccessDuration()).Unix(),
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
 	})
 
 	return token.SignedString([]byte(j.accessSecretKey))
@@ -100,20 +214,23 @@ func (j *JWTManager) generateRefreshToken(user *models.User, tokenID string) (st
 	claims := &models.TokenClaims{
 		UserID:   user.ID,
 		Username: user.Username,
+		Role:     user.Role,
 		TokenID:  tokenID,
 		Type:     "refresh",
 	}
 
+	now := j.clock.Now()
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
 		"user_id":  claims.UserID.String(),
 		"username": claims.Username,
+		"role":     claims.Role,
 		"token_id": claims.TokenID,
 		"type":     claims.Type,
 		"iss":      j.issuer,
 		"aud":      j.audience,
-		"exp":      time.Now().Add(j.refreshDuration).Unix(),
-		"iat":      time.Now().Unix(),
-		"nbf":      time.Now().Unix(),
+		"exp":      now.Add(j.refreshDuration).Unix(),
+		"iat":      now.Unix(),
+		"nbf":      now.Unix(),
 	})
 
 	return token.SignedString([]byte(j.refreshSecretKey))
@@ -137,7 +254,7 @@ func (j *JWTManager) validateToken(tokenString, secretKey, expectedType string)
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(secretKey), nil
-	})
+	}, jwt.WithTimeFunc(j.clock.Now))
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse token: %w", err)
@@ -168,6 +285,18 @@ func (j *JWTManager) validateToken(tokenString, secretKey, expectedType string)
 		return nil, fmt.Errorf("invalid audience")
 	}
 
+	// Machine client tokens carry a client ID and scopes instead of a user
+	if clientID, ok := claims["client_id"].(string); ok && tokenType == "access" {
+		tokenID, _ := claims["token_id"].(string)
+		scope, _ := claims["scope"].(string)
+		return &models.TokenClaims{
+			ClientID: clientID,
+			TokenID:  tokenID,
+			Type:     tokenType,
+			Scopes:   strings.Fields(scope),
+		}, nil
+	}
+
 	// Extract user ID
 	userIDStr, ok := claims["user_id"].(string)
 	if !ok {
@@ -185,17 +314,60 @@ func (j *JWTManager) validateToken(tokenString, secretKey, expectedType string)
 		return nil, fmt.Errorf("invalid username in token")
 	}
 
+	// Extract role (tokens issued before roles existed carry none)
+	role, _ := claims["role"].(string)
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	// Extract plan (tokens issued before plans existed are on the free plan)
+	plan, _ := claims["plan"].(string)
+	if plan == "" {
+		plan = models.PlanFree
+	}
+
 	// Extract token ID
 	tokenID, ok := claims["token_id"].(string)
 	if !ok {
 		return nil, fmt.Errorf("invalid token_id in token")
 	}
 
+	// Extract scopes (space-separated as in OAuth; tokens issued before scopes existed get the role's scopes)
+	var scopes []string
+	if scope, ok := claims["scope"].(string); ok {
+		scopes = strings.Fields(scope)
+	} else if tokenType == "access" {
+		scopes = models.ScopesForRole(role)
+	}
+
+	// Collect custom claims added by a ClaimsEnricher
+	var custom map[string]interface{}
+	for name, value := range claims {
+		if reservedClaims[name] {
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]interface{})
+		}
+		custom[name] = value
+	}
+
+	// Extract issue time, used to reject tokens issued before a revocation
+	var issuedAt time.Time
+	if iat, ok := claims["iat"].(float64); ok {
+		issuedAt = time.Unix(int64(iat), 0)
+	}
+
 	return &models.TokenClaims{
 		UserID:   userID,
 		Username: username,
+		Role:     role,
+		Plan:     plan,
 		TokenID:  tokenID,
 		Type:     tokenType,
+		Scopes:   scopes,
+		Custom:   custom,
+		IssuedAt: issuedAt,
 	}, nil
 }
 
@@ -208,6 +380,20 @@ func generateTokenID() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// LimitAccessDuration caps the lifetime of access tokens generated from now on, e.g. during a
+// security incident. A limit of zero or less restores the configured duration.
+func (j *JWTManager) LimitAccessDuration(limit time.Duration) {
+	j.accessLimit.Store(int64(max(limit, 0)))
+}
+
+// GetAccessDuration returns the access token duration, capped by LimitAccessDuration
+func (j *JWTManager) GetAccessDuration() time.Duration {
+	if limit := time.Duration(j.accessLimit.Load()); limit > 0 && limit < j.accessDuration {
+		return limit
+	}
+	return j.accessDuration
+}
+
 // GetRefreshDuration returns the refresh token duration
 func (j *JWTManager) GetRefreshDuration() time.Duration {
 	return j.refreshDuration
@@ -215,6 +401,30 @@ func (j *JWTManager) GetRefreshDuration() time.Duration {
 
 // HashRefreshToken hashes a refresh token using SHA256
 func HashRefreshToken(token string) string {
+	return HashToken(token)
+}
+
+// GenerateOpaqueToken generates a cryptographically secure random token for one-time links
+func GenerateOpaqueToken() (string, error) {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// GenerateNumericCode generates a cryptographically secure numeric one-time code with the given number of digits
+func GenerateNumericCode(digits int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(digits)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", digits, n), nil
+}
+
+// HashToken hashes an opaque token using SHA256 for storage
+func HashToken(token string) string {
 	hash := sha256.Sum256([]byte(token))
 	return hex.EncodeToString(hash[:])
 }
@@ -1,22 +1,40 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
+	"time"
+
+	"go-backend-api/internal/pkg/redact"
 )
 
 // AppError represents an application error
 type AppError struct {
 	Code    int    `json:"code"`
+	Reason  string `json:"reason,omitempty"` // Machine-readable error code for clients
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
 	Err     error  `json:"-"`
+	// Throttle describes the limit a throttled (429 or 423) request ran into, if any
+	Throttle *Throttle `json:"-"`
+}
+
+// Throttle describes a limit a request ran into: what it limits, how many requests it allows,
+// how many are left and when another is allowed. It is the one shape every throttling
+// response carries, whichever rate limit, quota or lockout rejected the request.
+type Throttle struct {
+	Scope     string
+	Limit     int
+	Remaining int
+	Reset     time.Duration // Until another request is allowed
 }
 
-// Error implements the error interface
+// Error implements the error interface. Personal data and credentials in the underlying
+// error, such as the values database errors quote, are redacted.
 func (e *AppError) Error() string {
 	if e.Err != nil {
-		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+		return fmt.Sprintf("%s: %s", e.Message, redact.Error(e.Err))
 	}
 	return e.Message
 }
@@ -26,6 +44,21 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// WithDetails returns a copy of the error with details set, leaving predefined errors untouched
+func (e *AppError) WithDetails(details string) *AppError {
+	withDetails := *e
+	withDetails.Details = details
+	return &withDetails
+}
+
+// WithThrottle returns a copy of the error carrying the limit that throttled the request,
+// leaving predefined errors untouched
+func (e *AppError) WithThrottle(throttle Throttle) *AppError {
+	throttled := *e
+	throttled.Throttle = &throttle
+	return &throttled
+}
+
 // NewAppError creates a new application error
 func NewAppError(code int, message string, err error) *AppError {
 	return &AppError{
@@ -45,50 +78,191 @@ func NewAppErrorWithDetails(code int, message, details string, err error) *AppEr
 	}
 }
 
+// NewAppErrorWithReason creates a new application error with a machine-readable reason
+func NewAppErrorWithReason(code int, reason, message string) *AppError {
+	return &AppError{
+		Code:    code,
+		Reason:  reason,
+		Message: message,
+	}
+}
+
 // Predefined errors
 var (
 	// Authentication errors
-	ErrUnauthorized = NewAppError(http.StatusUnauthorized, "Unauthorized", nil)
-	ErrForbidden    = NewAppError(http.StatusForbidden, "Forbidden", nil)
-	ErrInvalidToken = NewAppError(http.StatusUnauthorized, "Invalid token", nil)
-	ErrTokenExpired = NewAppError(http.StatusUnauthorized, "Token expired", nil)
+	// ErrUnauthorized and ErrForbidden carry the reasons of 401 and 403 errors without one
+	ErrUnauthorized             = NewAppErrorWithReason(http.StatusUnauthorized, "UNAUTHORIZED", "Unauthorized")
+	ErrForbidden                = NewAppErrorWithReason(http.StatusForbidden, "FORBIDDEN", "Forbidden")
+	ErrAuthRequired             = NewAppErrorWithReason(http.StatusUnauthorized, "AUTH_REQUIRED", "Authorization header required")
+	ErrInvalidAuthHeader        = NewAppErrorWithReason(http.StatusUnauthorized, "AUTH_HEADER_INVALID", "Invalid authorization header format")
+	ErrInvalidToken             = NewAppErrorWithReason(http.StatusUnauthorized, "TOKEN_INVALID", "Invalid token")
+	ErrTokenExpired             = NewAppErrorWithReason(http.StatusUnauthorized, "TOKEN_EXPIRED", "Token expired")
+	ErrTokenRevoked             = NewAppErrorWithReason(http.StatusUnauthorized, "TOKEN_REVOKED", "Token has been revoked")
+	ErrInvalidRefreshToken      = NewAppErrorWithReason(http.StatusUnauthorized, "REFRESH_TOKEN_INVALID", "Invalid refresh token")
+	ErrInvalidCredentials       = NewAppErrorWithReason(http.StatusUnauthorized, "INVALID_CREDENTIALS", "Invalid email or password")
+	ErrAdminRequired            = NewAppErrorWithReason(http.StatusForbidden, "ADMIN_REQUIRED", "Admin access required")
+	ErrAccountDeactivated       = NewAppErrorWithReason(http.StatusForbidden, "ACCOUNT_DEACTIVATED", "Account is deactivated")
+	ErrPasswordChangeRequired   = NewAppErrorWithReason(http.StatusForbidden, "PASSWORD_CHANGE_REQUIRED", "Password change required")
+	ErrInviteRequired           = NewAppErrorWithReason(http.StatusForbidden, "INVITE_REQUIRED", "Registration requires an invite code")
+	ErrInvalidInvite            = NewAppErrorWithReason(http.StatusForbidden, "INVITE_INVALID", "Invite code is invalid, expired or already used")
+	ErrCaptchaRequired          = NewAppErrorWithReason(http.StatusForbidden, "CAPTCHA_REQUIRED", "CAPTCHA verification required")
+	ErrCaptchaInvalid           = NewAppErrorWithReason(http.StatusForbidden, "CAPTCHA_INVALID", "CAPTCHA verification failed")
+	ErrInvalidLoginChallenge    = NewAppErrorWithReason(http.StatusUnauthorized, "STEP_UP_INVALID", "Invalid or expired verification code")
+	ErrInvalidAPIKey            = NewAppErrorWithReason(http.StatusUnauthorized, "API_KEY_INVALID", "Invalid, expired or revoked API key")
+	ErrInsufficientScope        = NewAppErrorWithReason(http.StatusForbidden, "INSUFFICIENT_SCOPE", "Token does not grant the required scope")
+	ErrInvalidClient            = NewAppErrorWithReason(http.StatusUnauthorized, "INVALID_CLIENT", "Invalid client credentials")
+	ErrUnsupportedGrantType     = NewAppErrorWithReason(http.StatusBadRequest, "UNSUPPORTED_GRANT_TYPE", "Unsupported grant type")
+	ErrInvalidScope             = NewAppErrorWithReason(http.StatusBadRequest, "INVALID_SCOPE", "Requested scope is not allowed for this client")
+	ErrInvalidEmailToken        = NewAppError(http.StatusBadRequest, "Invalid or expired email token", nil)
+	ErrPolicyAcceptanceRequired = NewAppErrorWithReason(http.StatusForbidden, "POLICY_ACCEPTANCE_REQUIRED", "The current terms of service and privacy policy must be accepted")
+	ErrParentalConsentRequired  = NewAppErrorWithReason(http.StatusForbidden, "PARENTAL_CONSENT_REQUIRED", "A parent must consent to this account first")
+	ErrBelowMinimumAge          = NewAppErrorWithReason(http.StatusForbidden, "BELOW_MINIMUM_AGE", "You are not old enough to register")
+	ErrInvalidConsentToken      = NewAppError(http.StatusBadRequest, "Invalid or expired parental consent token", nil)
+	ErrInvalidUnsubscribeToken  = NewAppError(http.StatusBadRequest, "Invalid or outdated unsubscribe token", nil)
+	ErrSignatureRequired        = NewAppErrorWithReason(http.StatusUnauthorized, "SIGNATURE_REQUIRED", "This request must be signed in the X-Signature header")
+	ErrSignatureInvalid         = NewAppErrorWithReason(http.StatusUnauthorized, "SIGNATURE_INVALID", "Request signature is invalid, expired or already used")
+	ErrPlanFeatureRequired      = NewAppErrorWithReason(http.StatusForbidden, "PLAN_FEATURE_REQUIRED", "Your plan does not include this feature")
+	ErrSandboxRestricted        = NewAppErrorWithReason(http.StatusForbidden, "SANDBOX_RESTRICTED", "Sandbox accounts cannot interact with other users")
+	ErrWebhookSignatureInvalid  = NewAppErrorWithReason(http.StatusBadRequest, "WEBHOOK_SIGNATURE_INVALID", "Webhook signature or credentials are missing, invalid or expired")
 
 	// Validation errors
-	ErrInvalidInput = NewAppError(http.StatusBadRequest, "Invalid input", nil)
-	ErrValidation   = NewAppError(http.StatusBadRequest, "Validation failed", nil)
+	ErrInvalidInput            = NewAppError(http.StatusBadRequest, "Invalid input", nil)
+	ErrValidation              = NewAppError(http.StatusBadRequest, "Validation failed", nil)
+	ErrReservedUsername        = NewAppErrorWithDetails(http.StatusBadRequest, "Username is not allowed", "This username is reserved", nil)
+	ErrBlockedEmailDomain      = NewAppErrorWithDetails(http.StatusBadRequest, "Email domain is not allowed", "Please use a different email provider", nil)
+	ErrCommentTooDeep          = NewAppErrorWithReason(http.StatusBadRequest, "COMMENT_TOO_DEEP", "Replies cannot be nested any deeper; reply to an earlier comment in the thread instead")
+	ErrPolicyVersionNotCurrent = NewAppErrorWithReason(http.StatusBadRequest, "POLICY_VERSION_NOT_CURRENT", "Only the current policy versions can be accepted")
+	ErrBirthdateRequired       = NewAppErrorWithReason(http.StatusBadRequest, "BIRTHDATE_REQUIRED", "Birthdate is required to register")
+	ErrBreakGlassAdminOnly     = NewAppErrorWithReason(http.StatusBadRequest, "BREAK_GLASS_ADMIN_ONLY", "Break-glass accounts can only be provisioned for active admins")
+	ErrScheduledTimeInvalid    = NewAppErrorWithReason(http.StatusBadRequest, "SCHEDULED_TIME_INVALID", "Scheduled time must be an RFC 3339 time or a local date and time such as 2026-03-29T09:30")
+	ErrScheduledTimeSkipped    = NewAppErrorWithReason(http.StatusBadRequest, "SCHEDULED_TIME_SKIPPED", "Scheduled time is skipped by a daylight saving change in your timezone; choose another time or give a UTC offset")
+	ErrScheduledTimeInPast     = NewAppErrorWithReason(http.StatusBadRequest, "SCHEDULED_TIME_IN_PAST", "Scheduled time must be in the future")
+	ErrEmailTemplateInvalid    = NewAppErrorWithReason(http.StatusBadRequest, "EMAIL_TEMPLATE_INVALID", "Email template does not parse or render")
+	ErrWebhookPayloadInvalid   = NewAppErrorWithReason(http.StatusBadRequest, "WEBHOOK_PAYLOAD_INVALID", "Webhook payload is malformed")
+	ErrScheduleRangeInvalid    = NewAppErrorWithReason(http.StatusBadRequest, "SCHEDULE_RANGE_INVALID", "from and to must be dates or RFC 3339 times, with from before to and at most a year apart")
+	ErrSavedSearchLimit        = NewAppErrorWithReason(http.StatusBadRequest, "SAVED_SEARCH_LIMIT", "You have saved the most searches allowed; delete one first")
+	ErrAPIKeyLimit             = NewAppErrorWithReason(http.StatusBadRequest, "API_KEY_LIMIT", "You hold the most API keys your plan allows; revoke one first")
+	ErrPushTopicInvalid        = NewAppErrorWithReason(http.StatusBadRequest, "PUSH_TOPIC_INVALID", "Topics are author:<user ID> or post:<post ID>")
+	ErrPushTopicLimit          = NewAppErrorWithReason(http.StatusBadRequest, "PUSH_TOPIC_LIMIT", "You are subscribed to the most topics allowed; unsubscribe from one first")
+	ErrAnnouncementWindow      = NewAppErrorWithReason(http.StatusBadRequest, "ANNOUNCEMENT_WINDOW_INVALID", "ends_at must be after starts_at")
+	ErrAnnouncementPinned      = NewAppErrorWithReason(http.StatusBadRequest, "ANNOUNCEMENT_PINNED", "This announcement is pinned and cannot be dismissed")
 
 	// Not found errors
-	ErrNotFound     = NewAppError(http.StatusNotFound, "Resource not found", nil)
-	ErrUserNotFound = NewAppError(http.StatusNotFound, "User not found", nil)
-	ErrPostNotFound = NewAppError(http.StatusNotFound, "Post not found", nil)
+	ErrNotFound              = NewAppError(http.StatusNotFound, "Resource not found", nil)
+	ErrUserNotFound          = NewAppError(http.StatusNotFound, "User not found", nil)
+	ErrPostNotFound          = NewAppError(http.StatusNotFound, "Post not found", nil)
+	ErrCommentNotFound       = NewAppError(http.StatusNotFound, "Comment not found", nil)
+	ErrAutosaveNotFound      = NewAppError(http.StatusNotFound, "No autosaved draft for this post", nil)
+	ErrInviteNotFound        = NewAppError(http.StatusNotFound, "Invite not found", nil)
+	ErrAPIKeyNotFound        = NewAppError(http.StatusNotFound, "API key not found", nil)
+	ErrClientNotFound        = NewAppError(http.StatusNotFound, "OAuth client not found", nil)
+	ErrLegalHoldNotFound     = NewAppError(http.StatusNotFound, "User is not under legal hold", nil)
+	ErrBreakGlassNotFound    = NewAppError(http.StatusNotFound, "Break-glass account not found", nil)
+	ErrSavedSearchNotFound   = NewAppError(http.StatusNotFound, "Saved search not found", nil)
+	ErrEmailTemplateNotFound = NewAppError(http.StatusNotFound, "Email template not found", nil)
+	ErrPushDeviceNotFound    = NewAppError(http.StatusNotFound, "Push device not found", nil)
+	ErrAnnouncementNotFound  = NewAppError(http.StatusNotFound, "Announcement not found", nil)
+	// ErrWebhookProviderNotFound is returned for deliveries to providers that are not registered
+	ErrWebhookProviderNotFound = NewAppErrorWithReason(http.StatusNotFound, "WEBHOOK_PROVIDER_NOT_FOUND", "No webhook provider is registered under this name")
+
+	// Routing errors
+	ErrRouteNotFound    = NewAppErrorWithReason(http.StatusNotFound, "ROUTE_NOT_FOUND", "No route matches the requested path")
+	ErrMethodNotAllowed = NewAppErrorWithReason(http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method not allowed for this resource")
+
+	// Media type errors
+	ErrNotAcceptable        = NewAppErrorWithReason(http.StatusNotAcceptable, "NOT_ACCEPTABLE", "None of the accepted media types can be served")
+	ErrUnsupportedMediaType = NewAppErrorWithReason(http.StatusUnsupportedMediaType, "UNSUPPORTED_MEDIA_TYPE", "The request body media type is not supported")
 
 	// Conflict errors
-	ErrConflict   = NewAppError(http.StatusConflict, "Resource already exists", nil)
-	ErrUserExists = NewAppError(http.StatusConflict, "User already exists", nil)
+	ErrConflict             = NewAppError(http.StatusConflict, "Resource already exists", nil)
+	ErrUserExists           = NewAppError(http.StatusConflict, "User already exists", nil)
+	ErrUsernameConfusable   = NewAppErrorWithDetails(http.StatusConflict, "Username too similar to an existing username", "Choose a username that cannot be confused with another account", nil)
+	ErrUsernameReserved     = NewAppErrorWithDetails(http.StatusConflict, "Username is reserved", "This username was recently used by another account", nil)
+	ErrPhoneNumberTaken     = NewAppErrorWithDetails(http.StatusConflict, "Phone number already taken", "Phone number must be unique", nil)
+	ErrPostArchived         = NewAppErrorWithReason(http.StatusConflict, "POST_ARCHIVED", "Post is archived and must be unarchived first")
+	ErrPostNotArchived      = NewAppErrorWithReason(http.StatusConflict, "POST_NOT_ARCHIVED", "Post is not archived")
+	ErrPostPublished        = NewAppErrorWithReason(http.StatusConflict, "POST_PUBLISHED", "Post is published and must be unpublished before it can be scheduled")
+	ErrPostAlreadyPublished = NewAppErrorWithReason(http.StatusConflict, "POST_ALREADY_PUBLISHED", "Post is already published")
+	ErrPostNotPublished     = NewAppErrorWithReason(http.StatusConflict, "POST_NOT_PUBLISHED", "Post is a draft: it is neither published nor scheduled")
+	ErrPostLockNotHeld      = NewAppErrorWithReason(http.StatusConflict, "POST_LOCK_NOT_HELD", "Post lock has expired or is held by another session")
+	ErrLegalHold            = NewAppErrorWithReason(http.StatusConflict, "LEGAL_HOLD", "This data is under legal hold and cannot be deleted")
+	// ErrWebhookInFlight is returned for redeliveries of an event still being processed, so
+	// the provider retries them and learns the outcome
+	ErrWebhookInFlight = NewAppErrorWithReason(http.StatusConflict, "WEBHOOK_IN_FLIGHT", "Webhook event is being processed; retry later")
+
+	// Size errors
+	ErrWebhookTooLarge = NewAppErrorWithReason(http.StatusRequestEntityTooLarge, "WEBHOOK_TOO_LARGE", "Webhook payload is too large")
+
+	// Gone errors
+	ErrSyncCheckpointExpired = NewAppErrorWithReason(http.StatusGone, "SYNC_CHECKPOINT_EXPIRED", "Deletions before this checkpoint are no longer tracked; sync again without since")
+
+	// Locked errors
+	ErrPostLocked = NewAppErrorWithReason(http.StatusLocked, "POST_LOCKED", "Post is being edited in another session; retry once its lock is released or expires")
 
 	// Internal errors
 	ErrInternal = NewAppError(http.StatusInternalServerError, "Internal server error", nil)
 	ErrDatabase = NewAppError(http.StatusInternalServerError, "Database error", nil)
+
+	// Availability errors
+	ErrServerBusy   = NewAppErrorWithReason(http.StatusServiceUnavailable, "SERVER_BUSY", "Server is busy, please retry shortly")
+	ErrQueryTimeout = NewAppErrorWithReason(http.StatusServiceUnavailable, "QUERY_TIMEOUT", "The request took too long to process, please retry shortly")
+	ErrReadOnly     = NewAppErrorWithReason(http.StatusServiceUnavailable, "READ_ONLY", "The API is in read-only mode; writes are unavailable, please retry later")
+	ErrRateLimited  = NewAppErrorWithReason(http.StatusTooManyRequests, "RATE_LIMITED", "Rate limit exceeded. Please try again later.")
+	// ErrEncryptionUnavailable is returned when a field encrypted at rest is set but no keys are configured
+	ErrEncryptionUnavailable = NewAppErrorWithReason(http.StatusServiceUnavailable, "ENCRYPTION_UNAVAILABLE", "Field encryption is not configured on this server")
+	// ErrSearchIndexNotConfigured is returned when reindexing without an external search backend
+	ErrSearchIndexNotConfigured = NewAppErrorWithReason(http.StatusServiceUnavailable, "SEARCH_INDEX_NOT_CONFIGURED", "No external search backend is configured on this server")
+	// ErrFeedFanOutNotConfigured is returned when rebuilding feeds without fan-out-on-write
+	ErrFeedFanOutNotConfigured = NewAppErrorWithReason(http.StatusServiceUnavailable, "FEED_FANOUT_NOT_CONFIGURED", "Feed fan-out is not enabled on this server")
 )
 
-// WrapError wraps an existing error with additional context
+// WrapError wraps an existing error with additional context. Errors reporting a timeout,
+// such as a database statement running out of time, become ErrQueryTimeout.
 func WrapError(err error, message string) *AppError {
 	if appErr, ok := err.(*AppError); ok {
 		return appErr
 	}
+	var timeout interface{ Timeout() bool }
+	if stderrors.As(err, &timeout) && timeout.Timeout() {
+		wrapped := *ErrQueryTimeout
+		wrapped.Err = err
+		return &wrapped
+	}
 	return NewAppError(http.StatusInternalServerError, message, err)
 }
 
 // WrapErrorWithCode wraps an existing error with additional context and custom code
 func WrapErrorWithCode(err error, code int, message string) *AppError {
 	if appErr, ok := err.(*AppError); ok {
-		appErr.Code = code
-		appErr.Message = message
-		return appErr
+		// Copy so predefined errors are never modified
+		wrapped := *appErr
+		wrapped.Code = code
+		wrapped.Message = message
+		return &wrapped
 	}
 	return NewAppError(code, message, err)
 }
 
+// Is reports whether any error in err's chain matches target, so callers do not need to import the standard errors package alongside this one
+func Is(err, target error) bool {
+	return stderrors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, like the standard errors.As
+func As(err error, target interface{}) bool {
+	return stderrors.As(err, target)
+}
+
+// NewInvalidParamError creates a 400 error for an invalid path or query parameter
+func NewInvalidParamError(name, details string) *AppError {
+	return &AppError{
+		Code:    http.StatusBadRequest,
+		Reason:  "INVALID_PARAMETER",
+		Message: "Invalid " + name,
+		Details: details,
+	}
+}
+
 // NewErrorWithCode creates a new error with a specific HTTP status code
 func NewErrorWithCode(code int, message string) *AppError {
 	return NewAppError(code, message, nil)
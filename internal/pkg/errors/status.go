@@ -0,0 +1,43 @@
+package errors
+
+import "net/http"
+
+// httpStatuses are the HTTP statuses an AppError code may be sent as. Any other
+// code is treated as an internal error rather than written to the wire as-is.
+var httpStatuses = map[int]bool{
+	http.StatusBadRequest:            true,
+	http.StatusUnauthorized:          true,
+	http.StatusPaymentRequired:       true,
+	http.StatusForbidden:             true,
+	http.StatusNotFound:              true,
+	http.StatusMethodNotAllowed:      true,
+	http.StatusNotAcceptable:         true,
+	http.StatusRequestTimeout:        true,
+	http.StatusConflict:              true,
+	http.StatusGone:                  true,
+	http.StatusPreconditionFailed:    true,
+	http.StatusRequestEntityTooLarge: true,
+	http.StatusUnsupportedMediaType:  true,
+	http.StatusUnprocessableEntity:   true,
+	http.StatusLocked:                true,
+	http.StatusPreconditionRequired:  true,
+	http.StatusTooManyRequests:       true,
+	http.StatusInternalServerError:   true,
+	http.StatusNotImplemented:        true,
+	http.StatusBadGateway:            true,
+	http.StatusServiceUnavailable:    true,
+	http.StatusGatewayTimeout:        true,
+}
+
+// HTTPStatus maps an AppError code to the HTTP status to respond with, defaulting to 500 for unknown codes
+func HTTPStatus(code int) int {
+	if httpStatuses[code] {
+		return code
+	}
+	return http.StatusInternalServerError
+}
+
+// Status returns the HTTP status to respond with for this error
+func (e *AppError) Status() int {
+	return HTTPStatus(e.Code)
+}
@@ -0,0 +1,189 @@
+// Package fieldcrypt encrypts sensitive columns at rest with AES-256-GCM. Ciphertexts are
+// stored as "<key id>:<base64 of nonce and sealed value>", so the key a value was encrypted
+// with is known without decrypting it: old keys stay in the keyring to decrypt, new values
+// are encrypted with the active key, and rows with an old key id are re-encrypted by rotation.
+// Encryption is randomized, so equal values cannot be looked up by ciphertext; a blind index,
+// an HMAC of the value under a separate key, is stored alongside for that.
+package fieldcrypt
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// keySize is the size of AES-256 keys
+const keySize = 32
+
+var (
+	// ErrNotConfigured is returned when no keyring is configured
+	ErrNotConfigured = errors.New("fieldcrypt: field encryption is not configured")
+	// ErrUnknownKey is returned for ciphertexts of a key not in the keyring
+	ErrUnknownKey = errors.New("fieldcrypt: ciphertext encrypted with an unknown key")
+)
+
+// Keyring holds the encryption keys by id, the active key new values are encrypted with,
+// and the key of blind indexes
+type Keyring struct {
+	active   string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// NewKeyring parses keys as comma-separated id:key pairs, the first being active, and an
+// index key; keys are base64-encoded, encryption keys 32 bytes and the index key at least that.
+// Rotate by prepending a new key while the old ones stay listed until rotation has run.
+func NewKeyring(keys, indexKey string) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]cipher.AEAD)}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || strings.ContainsAny(id, ":%_") {
+			return nil, fmt.Errorf("fieldcrypt: key %q must be given as id:base64key", entry)
+		}
+		if _, exists := keyring.keys[id]; exists {
+			return nil, fmt.Errorf("fieldcrypt: key id %s is listed twice", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != keySize {
+			return nil, fmt.Errorf("fieldcrypt: key %s must be %d base64-encoded bytes", id, keySize)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		keyring.keys[id] = aead
+		if keyring.active == "" {
+			keyring.active = id
+		}
+	}
+	if keyring.active == "" {
+		return nil, fmt.Errorf("fieldcrypt: no encryption keys given")
+	}
+
+	index, err := base64.StdEncoding.DecodeString(strings.TrimSpace(indexKey))
+	if err != nil || len(index) < keySize {
+		return nil, fmt.Errorf("fieldcrypt: the blind index key must be at least %d base64-encoded bytes", keySize)
+	}
+	keyring.indexKey = index
+
+	return keyring, nil
+}
+
+// ActiveKeyID returns the id of the key new values are encrypted with
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Encrypt encrypts a value with the active key
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	aead := k.keys[k.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("fieldcrypt: failed to generate nonce: %w", err)
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(k.active))
+	return k.active + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value encrypted with any key of the keyring
+func (k *Keyring) Decrypt(ciphertext string) (string, error) {
+	id, encoded, ok := strings.Cut(ciphertext, ":")
+	if !ok {
+		return "", fmt.Errorf("fieldcrypt: malformed ciphertext")
+	}
+	aead, ok := k.keys[id]
+	if !ok {
+		return "", fmt.Errorf("%w %s", ErrUnknownKey, id)
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", fmt.Errorf("fieldcrypt: malformed ciphertext")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("fieldcrypt: failed to decrypt with key %s: %w", id, err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns the hex HMAC-SHA256 of a value, to store next to its ciphertext and look
+// it up by. Values must be normalized first; changing the index key requires recomputing
+// every stored index.
+func (k *Keyring) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// defaultKeyring is the keyring String values are encrypted with
+var defaultKeyring atomic.Pointer[Keyring]
+
+// SetDefault sets the keyring String values are encrypted with
+func SetDefault(keyring *Keyring) {
+	defaultKeyring.Store(keyring)
+}
+
+// Default returns the keyring String values are encrypted with, or nil if none is configured
+func Default() *Keyring {
+	return defaultKeyring.Load()
+}
+
+// String is a string stored encrypted with the default keyring. The empty string is
+// stored as NULL.
+type String string
+
+// Value encrypts the string for storage
+func (s String) Value() (driver.Value, error) {
+	if s == "" {
+		return nil, nil
+	}
+	keyring := Default()
+	if keyring == nil {
+		return nil, ErrNotConfigured
+	}
+	return keyring.Encrypt(string(s))
+}
+
+// Scan decrypts a stored string
+func (s *String) Scan(src interface{}) error {
+	var ciphertext string
+	switch v := src.(type) {
+	case nil:
+		*s = ""
+		return nil
+	case string:
+		ciphertext = v
+	case []byte:
+		ciphertext = string(v)
+	default:
+		return fmt.Errorf("fieldcrypt: cannot scan %T into String", src)
+	}
+
+	keyring := Default()
+	if keyring == nil {
+		return ErrNotConfigured
+	}
+	plaintext, err := keyring.Decrypt(ciphertext)
+	if err != nil {
+		return err
+	}
+	*s = String(plaintext)
+	return nil
+}
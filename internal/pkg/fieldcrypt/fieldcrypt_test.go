@@ -0,0 +1,117 @@
+package fieldcrypt
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// key returns a base64-encoded key of 32 copies of b
+func key(b byte) string {
+	return base64.StdEncoding.EncodeToString([]byte(strings.Repeat(string(b), keySize)))
+}
+
+func mustKeyring(t *testing.T, keys string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(keys, key('i'))
+	if err != nil {
+		t.Fatalf("NewKeyring(%q): %v", keys, err)
+	}
+	return keyring
+}
+
+func TestDecryptAcrossKeyRotation(t *testing.T) {
+	const phone = "+15555550123"
+	before := mustKeyring(t, "k1:"+key('a'))
+	oldCiphertext, err := before.Encrypt(phone)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// The new key is prepended, so it encrypts while k1 still decrypts
+	rotating := mustKeyring(t, "k2:"+key('b')+",k1:"+key('a'))
+	newCiphertext, err := rotating.Encrypt(phone)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(newCiphertext, "k2:") {
+		t.Fatalf("rotated keyring encrypted %q, want it under k2", newCiphertext)
+	}
+	after := mustKeyring(t, "k2:"+key('b'))
+
+	_, sealed, _ := strings.Cut(oldCiphertext, ":")
+	raw, _ := base64.StdEncoding.DecodeString(sealed)
+	raw[len(raw)-1] ^= 1
+	tampered := "k1:" + base64.StdEncoding.EncodeToString(raw)
+
+	tests := []struct {
+		name        string
+		keyring     *Keyring
+		ciphertext  string
+		wantErr     bool
+		wantUnknown bool
+	}{
+		{name: "old value before rotation", keyring: before, ciphertext: oldCiphertext},
+		{name: "old value during rotation", keyring: rotating, ciphertext: oldCiphertext},
+		{name: "new value during rotation", keyring: rotating, ciphertext: newCiphertext},
+		{name: "new value after the old key is removed", keyring: after, ciphertext: newCiphertext},
+		{name: "old value after the old key is removed", keyring: after, ciphertext: oldCiphertext, wantErr: true, wantUnknown: true},
+		{name: "new value with only the old key", keyring: before, ciphertext: newCiphertext, wantErr: true, wantUnknown: true},
+		// The key id is authenticated, so a value cannot be passed off as another key's
+		{name: "relabelled key id", keyring: mustKeyring(t, "k2:"+key('a')+",k1:"+key('b')), ciphertext: "k2:" + sealed, wantErr: true},
+		{name: "tampered value", keyring: rotating, ciphertext: tampered, wantErr: true},
+		{name: "no key id", keyring: rotating, ciphertext: sealed, wantErr: true},
+		{name: "truncated value", keyring: rotating, ciphertext: "k1:" + base64.StdEncoding.EncodeToString(raw[:4]), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.keyring.Decrypt(tt.ciphertext)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Decrypt = %q, want an error", got)
+				}
+				if errors.Is(err, ErrUnknownKey) != tt.wantUnknown {
+					t.Errorf("got %v, want ErrUnknownKey %v", err, tt.wantUnknown)
+				}
+				return
+			}
+			if err != nil || got != phone {
+				t.Errorf("Decrypt = %q, %v; want %q", got, err, phone)
+			}
+		})
+	}
+}
+
+func TestBlindIndexSurvivesKeyRotation(t *testing.T) {
+	before := mustKeyring(t, "k1:"+key('a'))
+	after := mustKeyring(t, "k2:"+key('b')+",k1:"+key('a'))
+	if before.BlindIndex("+15555550123") != after.BlindIndex("+15555550123") {
+		t.Error("blind index changed with the encryption keys")
+	}
+	if before.BlindIndex("+15555550123") == before.BlindIndex("+15555550124") {
+		t.Error("different values share a blind index")
+	}
+}
+
+func TestNewKeyringRejectsBadKeys(t *testing.T) {
+	tests := []struct {
+		name     string
+		keys     string
+		indexKey string
+	}{
+		{name: "no keys", keys: " , ", indexKey: key('i')},
+		{name: "no id", keys: key('a'), indexKey: key('i')},
+		{name: "id with a wildcard", keys: "k%1:" + key('a'), indexKey: key('i')},
+		{name: "short key", keys: "k1:" + base64.StdEncoding.EncodeToString([]byte("short")), indexKey: key('i')},
+		{name: "duplicate id", keys: "k1:" + key('a') + ",k1:" + key('b'), indexKey: key('i')},
+		{name: "short index key", keys: "k1:" + key('a'), indexKey: base64.StdEncoding.EncodeToString([]byte("short"))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewKeyring(tt.keys, tt.indexKey); err == nil {
+				t.Error("NewKeyring succeeded, want an error")
+			}
+		})
+	}
+}
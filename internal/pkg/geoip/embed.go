@@ -0,0 +1,11 @@
+//go:build geoip_embed
+
+package geoip
+
+import _ "embed"
+
+// embeddedCityDB is a GeoLite2/GeoIP2 City database compiled into the binary.
+// Place the database at data/GeoLite2-City.mmdb and build with -tags geoip_embed.
+//
+//go:embed data/GeoLite2-City.mmdb
+var embeddedCityDB []byte
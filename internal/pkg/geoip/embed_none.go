@@ -0,0 +1,6 @@
+//go:build !geoip_embed
+
+package geoip
+
+// embeddedCityDB is empty unless the binary is built with the geoip_embed tag
+var embeddedCityDB []byte
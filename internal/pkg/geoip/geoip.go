@@ -0,0 +1,97 @@
+package geoip
+
+import (
+	"fmt"
+	"net"
+
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// Locator resolves IP addresses using MaxMind GeoIP2/GeoLite2 databases.
+// It implements security.GeoLocator.
+type Locator struct {
+	city *geoip2.Reader
+	asn  *geoip2.Reader
+}
+
+// Open opens the City and ASN databases. Either path may be empty; when the City path is empty
+// a database embedded at build time (geoip_embed build tag) is used if present.
+// It returns nil without an error when no database is available, so callers can degrade gracefully.
+func Open(cityPath, asnPath string) (*Locator, error) {
+	locator := &Locator{}
+
+	switch {
+	case cityPath != "":
+		reader, err := geoip2.Open(cityPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open GeoIP city database: %w", err)
+		}
+		locator.city = reader
+	case len(embeddedCityDB) > 0:
+		reader, err := geoip2.FromBytes(embeddedCityDB)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load embedded GeoIP city database: %w", err)
+		}
+		locator.city = reader
+	}
+
+	if asnPath != "" {
+		reader, err := geoip2.Open(asnPath)
+		if err != nil {
+			locator.Close()
+			return nil, fmt.Errorf("failed to open GeoIP ASN database: %w", err)
+		}
+		locator.asn = reader
+	}
+
+	if locator.city == nil && locator.asn == nil {
+		return nil, nil
+	}
+
+	return locator, nil
+}
+
+// Lookup returns the location of ip, or nil if it is invalid, private or not in the databases
+func (l *Locator) Lookup(ip string) *security.GeoLocation {
+	parsed := net.ParseIP(ip)
+	if l == nil || parsed == nil || parsed.IsLoopback() || parsed.IsPrivate() {
+		return nil
+	}
+
+	location := &security.GeoLocation{}
+	if l.city != nil {
+		if record, err := l.city.City(parsed); err == nil {
+			location.Country = record.Country.IsoCode
+			location.City = record.City.Names["en"]
+			location.Latitude = record.Location.Latitude
+			location.Longitude = record.Location.Longitude
+		}
+	}
+	if l.asn != nil {
+		if record, err := l.asn.ASN(parsed); err == nil {
+			location.ASN = record.AutonomousSystemNumber
+			location.ASOrg = record.AutonomousSystemOrganization
+		}
+	}
+
+	if *location == (security.GeoLocation{}) {
+		return nil
+	}
+	return location
+}
+
+// Close closes the underlying databases
+func (l *Locator) Close() error {
+	if l == nil {
+		return nil
+	}
+	if l.city != nil {
+		l.city.Close()
+	}
+	if l.asn != nil {
+		l.asn.Close()
+	}
+	return nil
+}
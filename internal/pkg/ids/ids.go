@@ -0,0 +1,29 @@
+// Package ids generates the primary keys of new records. By default they are UUIDv7, which
+// start with their creation time, so new rows land next to each other in Postgres indexes
+// instead of at random pages. Existing random (v4) IDs stay valid: nothing depends on the
+// version of an ID, and the two kinds mix freely in the same columns.
+package ids
+
+import (
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// randomIDs turns time-ordered IDs off, see SetTimeOrdered
+var randomIDs atomic.Bool
+
+// SetTimeOrdered chooses between time-ordered (v7) and random (v4) IDs for new records. Call it
+// at startup; IDs are time-ordered until then.
+func SetTimeOrdered(enabled bool) {
+	randomIDs.Store(!enabled)
+}
+
+// New returns the ID of a new record
+func New() uuid.UUID {
+	if randomIDs.Load() {
+		return uuid.New()
+	}
+	// Like uuid.New, this only fails, and panics, when the system's random source does
+	return uuid.Must(uuid.NewV7())
+}
@@ -0,0 +1,44 @@
+package ids
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestNewIDsAreTimeOrdered(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	previous := New()
+	if previous.Version() != 7 {
+		t.Fatalf("got a version %d ID, want 7", previous.Version())
+	}
+	sec, nsec := previous.Time().UnixTime()
+	if created := time.Unix(sec, nsec); created.Before(before) || created.After(time.Now()) {
+		t.Errorf("ID carries time %s, want the time it was created", created)
+	}
+
+	// IDs sort in creation order even within the same millisecond
+	for range 1000 {
+		id := New()
+		if bytes.Compare(previous[:], id[:]) >= 0 {
+			t.Fatalf("ID %s sorts before %s, created earlier", id, previous)
+		}
+		previous = id
+	}
+}
+
+func TestRandomIDs(t *testing.T) {
+	SetTimeOrdered(false)
+	t.Cleanup(func() { SetTimeOrdered(true) })
+
+	seen := map[uuid.UUID]bool{}
+	for range 100 {
+		id := New()
+		if id.Version() != 4 || seen[id] {
+			t.Fatalf("got ID %s (version %d), want distinct version 4 IDs", id, id.Version())
+		}
+		seen[id] = true
+	}
+}
@@ -0,0 +1,100 @@
+// Package links builds the hypermedia links of API resources. Routes are registered by name
+// with their path template, so handlers link to a route without spelling out its path, and
+// links are absolute URLs under the configured external base URL.
+package links
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Names of the routes resources link to
+const (
+	RoutePost          = "post"
+	RoutePostComments  = "post.comments"
+	RoutePosts         = "posts"
+	RoutePublicProfile = "user.profile"
+	RouteShortLink     = "post.short"
+)
+
+// contextKey is the gin context key of the builder of requests that asked for links
+const contextKey = "links"
+
+// Link is a link to a related resource
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Links are the links of a resource by relation, serialized as its _links section
+type Links map[string]Link
+
+// Builder builds absolute links to named routes
+type Builder struct {
+	baseURL string
+	routes  map[string]string
+}
+
+// NewBuilder creates a link builder for URLs under baseURL
+func NewBuilder(baseURL string) *Builder {
+	return &Builder{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		routes:  make(map[string]string),
+	}
+}
+
+// Handle names the route with a path template in gin syntax, such as /api/v1/posts/:id
+func (b *Builder) Handle(name, path string) {
+	b.routes[name] = path
+}
+
+// URL builds the URL of the named route, filling its parameters in order with params. Unknown
+// routes have no URL.
+func (b *Builder) URL(name string, params ...string) string {
+	path, ok := b.routes[name]
+	if !ok {
+		return ""
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") && len(params) > 0 {
+			segments[i] = url.PathEscape(params[0])
+			params = params[1:]
+		}
+	}
+	return b.baseURL + strings.Join(segments, "/")
+}
+
+// Page builds the URL of another page of the listing at u, keeping its other parameters
+func (b *Builder) Page(u *url.URL, page int) string {
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	return b.baseURL + u.EscapedPath() + "?" + query.Encode()
+}
+
+// PageLinks builds the self, prev and next links of page of a listing at u with totalPages pages
+func (b *Builder) PageLinks(u *url.URL, page, totalPages int) Links {
+	links := Links{"self": {Href: b.Page(u, page)}}
+	if page > 1 {
+		links["prev"] = Link{Href: b.Page(u, min(page-1, max(totalPages, 1)))}
+	}
+	if page < totalPages {
+		links["next"] = Link{Href: b.Page(u, page+1)}
+	}
+	return links
+}
+
+// WithBuilder makes responses to the request carry links built by b
+func WithBuilder(c *gin.Context, b *Builder) {
+	c.Set(contextKey, b)
+}
+
+// FromContext returns the link builder of a request that asked for links, or nil
+func FromContext(c *gin.Context) *Builder {
+	b, _ := c.Get(contextKey)
+	builder, _ := b.(*Builder)
+	return builder
+}
@@ -0,0 +1,99 @@
+// Package localtime converts the dates and wall-clock times people enter in their own timezone
+// to instants. Unlike time.Date, it is explicit about daylight saving changes: a time that
+// occurs twice when clocks are set back resolves to its first occurrence, and a time skipped
+// when clocks jump forward is reported instead of being silently moved.
+package localtime
+
+import (
+	"errors"
+	"time"
+)
+
+// Layouts of local dates and times, which carry no UTC offset
+const (
+	DateLayout     = "2006-01-02"
+	DateTimeLayout = "2006-01-02T15:04"
+)
+
+// dateTimeSecondsLayout is DateTimeLayout with seconds, also accepted by Parse
+const dateTimeSecondsLayout = "2006-01-02T15:04:05"
+
+var (
+	// ErrInvalid is returned for strings that are not times or dates
+	ErrInvalid = errors.New("invalid local time")
+	// ErrNonexistent is returned for local times skipped by a daylight saving change
+	ErrNonexistent = errors.New("local time does not exist in this timezone")
+)
+
+// At returns the instant at which clocks in loc show the date and time of wall, whose own
+// location is ignored. Of a time that occurs twice, the first occurrence is returned. A time
+// that does not occur, because clocks jump forward over it, is moved forward by the length of
+// the jump and reported with ok set to false.
+func At(wall time.Time, loc *time.Location) (t time.Time, ok bool) {
+	year, month, day := wall.Date()
+	hour, minute, sec := wall.Clock()
+	asUTC := time.Date(year, month, day, hour, minute, sec, wall.Nanosecond(), time.UTC)
+
+	// A day on either side is past any offset, so these are the offsets in force before and
+	// after a change near wall, or the same offset twice when there is none
+	_, before := asUTC.Add(-24 * time.Hour).In(loc).Zone()
+	_, after := asUTC.Add(24 * time.Hour).In(loc).Zone()
+
+	first := asUTC.Add(-time.Duration(before) * time.Second)
+	second := asUTC.Add(-time.Duration(after) * time.Second)
+	if second.Before(first) {
+		first, second = second, first
+	}
+	for _, candidate := range []time.Time{first, second} {
+		if shows(candidate.In(loc), asUTC) {
+			return candidate.In(loc), true
+		}
+	}
+	// Read with the offset from before the jump, the time lands the length of the jump past it
+	return asUTC.Add(-time.Duration(before) * time.Second).In(loc), false
+}
+
+// shows reports whether t shows the date and time of wall
+func shows(t, wall time.Time) bool {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+	return wall.Equal(time.Date(year, month, day, hour, minute, second, t.Nanosecond(), time.UTC))
+}
+
+// Parse parses an RFC 3339 time, which is taken as is, or a local date and time in
+// DateTimeLayout (seconds optional), which is resolved in loc. Local times that do not occur
+// in loc are ErrNonexistent.
+func Parse(value string, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{DateTimeLayout, dateTimeSecondsLayout} {
+		wall, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		t, ok := At(wall, loc)
+		if !ok {
+			return time.Time{}, ErrNonexistent
+		}
+		return t, nil
+	}
+	return time.Time{}, ErrInvalid
+}
+
+// StartOfDay returns the first instant of the date in loc. That is midnight, unless clocks
+// jump forward over midnight, in which case the day starts when they land.
+func StartOfDay(date time.Time, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	t, _ := At(time.Date(year, month, day, 0, 0, 0, 0, time.UTC), loc)
+	return t
+}
+
+// ParseDate parses a date in DateLayout
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, ErrInvalid
+	}
+	return date, nil
+}
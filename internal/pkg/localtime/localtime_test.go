@@ -0,0 +1,100 @@
+package localtime
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data for %s not available: %v", name, err)
+	}
+	return loc
+}
+
+func utc(year int, month time.Month, day, hour, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestAtAcrossDaylightSavingChanges(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	tests := []struct {
+		name string
+		wall time.Time
+		want time.Time
+		ok   bool
+	}{
+		{"before spring forward", utc(2026, 3, 8, 1, 59), utc(2026, 3, 8, 6, 59), true},
+		// 02:00 to 02:59 are skipped; the time moves forward by the hour clocks jumped
+		{"skipped by spring forward", utc(2026, 3, 8, 2, 30), utc(2026, 3, 8, 7, 30), false},
+		{"after spring forward", utc(2026, 3, 8, 3, 0), utc(2026, 3, 8, 7, 0), true},
+		// 01:00 to 01:59 occur twice, first in EDT and then in EST
+		{"repeated by fall back", utc(2026, 11, 1, 1, 30), utc(2026, 11, 1, 5, 30), true},
+		{"after fall back", utc(2026, 11, 1, 2, 0), utc(2026, 11, 1, 7, 0), true},
+		{"no change", utc(2026, 7, 1, 12, 0), utc(2026, 7, 1, 16, 0), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := At(tt.wall, newYork)
+			if !got.Equal(tt.want) || ok != tt.ok {
+				t.Errorf("At(%s) = %v, %v; want %v, %v", tt.wall.Format(DateTimeLayout), got.UTC(), ok, tt.want, tt.ok)
+			}
+			if got.Location() != newYork {
+				t.Errorf("At(%s) is in %v, want %v", tt.wall.Format(DateTimeLayout), got.Location(), newYork)
+			}
+		})
+	}
+}
+
+func TestParseAcrossDaylightSavingChanges(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+
+	if _, err := Parse("2026-03-08T02:30", newYork); !errors.Is(err, ErrNonexistent) {
+		t.Errorf("skipped time: got %v, want ErrNonexistent", err)
+	}
+	got, err := Parse("2026-11-01T01:30:00", newYork)
+	if err != nil || !got.Equal(utc(2026, 11, 1, 5, 30)) {
+		t.Errorf("repeated time: got %v, %v; want the first occurrence %v", got.UTC(), err, utc(2026, 11, 1, 5, 30))
+	}
+	// An offset picks either occurrence, and is taken as is
+	got, err = Parse("2026-11-01T01:30:00-05:00", newYork)
+	if err != nil || !got.Equal(utc(2026, 11, 1, 6, 30)) {
+		t.Errorf("RFC 3339 time: got %v, %v; want %v", got.UTC(), err, utc(2026, 11, 1, 6, 30))
+	}
+	if _, err := Parse("2026-11-01 01:30", newYork); !errors.Is(err, ErrInvalid) {
+		t.Errorf("unknown layout: got %v, want ErrInvalid", err)
+	}
+}
+
+func TestStartOfDayAcrossDaylightSavingChanges(t *testing.T) {
+	newYork := mustLoad(t, "America/New_York")
+	santiago := mustLoad(t, "America/Santiago")
+
+	tests := []struct {
+		name   string
+		date   time.Time
+		loc    *time.Location
+		want   time.Time
+		length time.Duration
+	}{
+		{"spring forward", utc(2026, 3, 8, 0, 0), newYork, utc(2026, 3, 8, 5, 0), 23 * time.Hour},
+		{"fall back", utc(2026, 11, 1, 0, 0), newYork, utc(2026, 11, 1, 4, 0), 25 * time.Hour},
+		// Clocks in Chile jump from midnight to 01:00, so the day starts at 01:00
+		{"midnight skipped", utc(2026, 9, 6, 0, 0), santiago, utc(2026, 9, 6, 4, 0), 23 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := StartOfDay(tt.date, tt.loc)
+			if !start.Equal(tt.want) {
+				t.Fatalf("StartOfDay = %v, want %v", start.UTC(), tt.want)
+			}
+			if length := StartOfDay(tt.date.AddDate(0, 0, 1), tt.loc).Sub(start); length != tt.length {
+				t.Errorf("day lasts %v, want %v", length, tt.length)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package metrics
+
+import "sync/atomic"
+
+// Counter is a monotonically increasing event count, safe for concurrent use.
+// A nil Counter ignores increments and reads as zero.
+type Counter struct {
+	n atomic.Uint64
+}
+
+// Inc adds one to the counter
+func (c *Counter) Inc() {
+	if c == nil {
+		return
+	}
+	c.n.Add(1)
+}
+
+// Value returns the current count
+func (c *Counter) Value() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.n.Load()
+}
@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultWindow is how many latency samples are kept per route when none is configured
+const DefaultWindow = 1024
+
+// RouteMetrics keeps per-route request counts, error counts and a ring buffer of
+// recent latencies, so percentiles reflect the last Window requests of each route.
+// A nil RouteMetrics ignores observations and reports no routes.
+type RouteMetrics struct {
+	window  int
+	started time.Time
+
+	requests     Counter
+	serverErrors Counter
+
+	mu     sync.Mutex
+	routes map[string]*routeStats
+	// mediaTypes counts requests by the representation negotiated for them
+	mediaTypes map[string]uint64
+}
+
+// routeStats holds the counters and latency ring buffer of a single route
+type routeStats struct {
+	method       string
+	path         string
+	requests     uint64
+	clientErrors uint64
+	serverErrors uint64
+	maxLatency   time.Duration
+	latencies    []time.Duration
+	next         int
+}
+
+// RouteStats is a snapshot of a single route's activity
+type RouteStats struct {
+	Method       string  `json:"method"`
+	Route        string  `json:"route"`
+	Requests     uint64  `json:"requests"`
+	ClientErrors uint64  `json:"client_errors"`
+	ServerErrors uint64  `json:"server_errors"`
+	ErrorRate    float64 `json:"error_rate"`
+	Samples      int     `json:"samples"`
+	P50Ms        float64 `json:"p50_ms"`
+	P95Ms        float64 `json:"p95_ms"`
+	P99Ms        float64 `json:"p99_ms"`
+	MaxMs        float64 `json:"max_ms"`
+}
+
+// NewRouteMetrics creates route metrics keeping the last window latencies of each route
+func NewRouteMetrics(window int) *RouteMetrics {
+	if window < 1 {
+		window = DefaultWindow
+	}
+	return &RouteMetrics{
+		window:     window,
+		started:    time.Now(),
+		routes:     make(map[string]*routeStats),
+		mediaTypes: make(map[string]uint64),
+	}
+}
+
+// Window returns how many latency samples are kept per route
+func (m *RouteMetrics) Window() int {
+	if m == nil {
+		return 0
+	}
+	return m.window
+}
+
+// Since returns when the metrics started collecting
+func (m *RouteMetrics) Since() time.Time {
+	if m == nil {
+		return time.Time{}
+	}
+	return m.started
+}
+
+// Requests returns the number of requests observed across all routes
+func (m *RouteMetrics) Requests() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.requests.Value()
+}
+
+// ServerErrors returns the number of requests across all routes that ended with a 5xx status
+func (m *RouteMetrics) ServerErrors() uint64 {
+	if m == nil {
+		return 0
+	}
+	return m.serverErrors.Value()
+}
+
+// Observe records a completed request to a route
+func (m *RouteMetrics) Observe(method, path string, status int, latency time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.requests.Inc()
+	if status >= 500 {
+		m.serverErrors.Inc()
+	}
+
+	key := method + " " + path
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats, ok := m.routes[key]
+	if !ok {
+		stats = &routeStats{
+			method:    method,
+			path:      path,
+			latencies: make([]time.Duration, 0, m.window),
+		}
+		m.routes[key] = stats
+	}
+
+	stats.requests++
+	switch {
+	case status >= 500:
+		stats.serverErrors++
+	case status >= 400:
+		stats.clientErrors++
+	}
+	if latency > stats.maxLatency {
+		stats.maxLatency = latency
+	}
+
+	if len(stats.latencies) < m.window {
+		stats.latencies = append(stats.latencies, latency)
+		return
+	}
+	stats.latencies[stats.next] = latency
+	stats.next = (stats.next + 1) % m.window
+}
+
+// ObserveMediaType records the representation negotiated for a request
+func (m *RouteMetrics) ObserveMediaType(mediaType string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.mediaTypes[mediaType]++
+}
+
+// MediaTypes returns how many requests negotiated each representation
+func (m *RouteMetrics) MediaTypes() map[string]uint64 {
+	counts := make(map[string]uint64)
+	if m == nil {
+		return counts
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for mediaType, n := range m.mediaTypes {
+		counts[mediaType] = n
+	}
+	return counts
+}
+
+// Snapshot returns the stats of every observed route, in no particular order
+func (m *RouteMetrics) Snapshot() []RouteStats {
+	if m == nil {
+		return []RouteStats{}
+	}
+
+	m.mu.Lock()
+	routes := make([]*routeStats, 0, len(m.routes))
+	samples := make([][]time.Duration, 0, len(m.routes))
+	for _, stats := range m.routes {
+		routes = append(routes, stats)
+		samples = append(samples, append([]time.Duration(nil), stats.latencies...))
+	}
+	snapshot := make([]RouteStats, len(routes))
+	for i, stats := range routes {
+		snapshot[i] = RouteStats{
+			Method:       stats.method,
+			Route:        stats.path,
+			Requests:     stats.requests,
+			ClientErrors: stats.clientErrors,
+			ServerErrors: stats.serverErrors,
+			MaxMs:        milliseconds(stats.maxLatency),
+		}
+	}
+	m.mu.Unlock()
+
+	// Sorting happens outside the lock so a large window does not stall requests
+	for i := range snapshot {
+		latencies := samples[i]
+		sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+
+		snapshot[i].Samples = len(latencies)
+		snapshot[i].P50Ms = milliseconds(percentile(latencies, 0.50))
+		snapshot[i].P95Ms = milliseconds(percentile(latencies, 0.95))
+		snapshot[i].P99Ms = milliseconds(percentile(latencies, 0.99))
+		if snapshot[i].Requests > 0 {
+			snapshot[i].ErrorRate = float64(snapshot[i].ServerErrors) / float64(snapshot[i].Requests)
+		}
+	}
+
+	return snapshot
+}
+
+// Orderings accepted by Top
+const (
+	SortByP50       = "p50"
+	SortByP95       = "p95"
+	SortByP99       = "p99"
+	SortByErrorRate = "error_rate"
+	SortByRequests  = "requests"
+)
+
+// SortKeys lists the orderings accepted by Top
+var SortKeys = []string{SortByP95, SortByP99, SortByP50, SortByErrorRate, SortByRequests}
+
+// Top returns at most limit routes ordered from worst to best by the given key.
+// A limit of zero or less returns every route.
+func (m *RouteMetrics) Top(by string, limit int) []RouteStats {
+	snapshot := m.Snapshot()
+
+	key := func(s RouteStats) float64 {
+		switch by {
+		case SortByP50:
+			return s.P50Ms
+		case SortByP99:
+			return s.P99Ms
+		case SortByErrorRate:
+			return s.ErrorRate
+		case SortByRequests:
+			return float64(s.Requests)
+		default:
+			return s.P95Ms
+		}
+	}
+	sort.Slice(snapshot, func(i, j int) bool {
+		if ki, kj := key(snapshot[i]), key(snapshot[j]); ki != kj {
+			return ki > kj
+		}
+		if snapshot[i].Route != snapshot[j].Route {
+			return snapshot[i].Route < snapshot[j].Route
+		}
+		return snapshot[i].Method < snapshot[j].Method
+	})
+
+	if limit > 0 && len(snapshot) > limit {
+		snapshot = snapshot[:limit]
+	}
+	return snapshot
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(0, min(rank, len(sorted)-1))]
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
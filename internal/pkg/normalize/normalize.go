@@ -0,0 +1,114 @@
+package normalize
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// confusables maps characters that render like ASCII letters or digits to the character they imitate.
+// It is a small subset of the Unicode confusables table covering the most common spoofing cases.
+var confusables = map[rune]string{
+	// ASCII look-alikes
+	'0': "o", 'O': "o", '1': "l", 'I': "l", '|': "l",
+	// Cyrillic
+	'а': "a", 'А': "a", 'в': "b", 'В': "b", 'е': "e", 'Е': "e", 'ё': "e", 'к': "k", 'К': "k",
+	'м': "m", 'М': "m", 'н': "h", 'Н': "h", 'о': "o", 'О': "o", 'р': "p", 'Р': "p",
+	'с': "c", 'С': "c", 'т': "t", 'Т': "t", 'у': "y", 'У': "y", 'х': "x", 'Х': "x",
+	'і': "i", 'І': "l", 'ј': "j", 'Ј': "j", 'ѕ': "s", 'Ѕ': "s", 'ԁ': "d", 'ԛ': "q", 'ԝ': "w",
+	// Greek
+	'α': "a", 'Α': "a", 'β': "b", 'Β': "b", 'ε': "e", 'Ε': "e", 'Η': "h", 'ι': "i", 'Ι': "l",
+	'κ': "k", 'Κ': "k", 'Μ': "m", 'ν': "v", 'Ν': "n", 'ο': "o", 'Ο': "o", 'ρ': "p", 'Ρ': "p",
+	'τ': "t", 'Τ': "t", 'υ': "u", 'Υ': "y", 'χ': "x", 'Χ': "x", 'Ζ': "z",
+}
+
+// multiCharConfusables are ASCII sequences that render like a single letter
+var multiCharConfusables = strings.NewReplacer("rn", "m", "vv", "w")
+
+// Email normalizes an email address for storage and lookup (trimmed, NFC, lowercase)
+func Email(email string) string {
+	return strings.ToLower(norm.NFC.String(strings.TrimSpace(email)))
+}
+
+// Username normalizes a username for storage (trimmed, NFC). Case is preserved for display;
+// uniqueness is enforced case-insensitively by the database.
+func Username(username string) string {
+	return norm.NFC.String(strings.TrimSpace(username))
+}
+
+// UsernameSkeleton returns a folded form of a username used to detect homoglyph-confusable names.
+// Two usernames with the same skeleton look alike and must not both be registered.
+func UsernameSkeleton(username string) string {
+	folded := norm.NFKC.String(strings.TrimSpace(username))
+
+	var b strings.Builder
+	for _, r := range folded {
+		if mapped, ok := confusables[r]; ok {
+			b.WriteString(mapped)
+			continue
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+
+	return multiCharConfusables.Replace(b.String())
+}
+
+// Struct normalizes the string fields of the struct v points to, including nested structs,
+// string pointers and string slices. By default values are trimmed and NFC-normalized; the
+// `normalize` tag selects other behaviour:
+//
+//	normalize:"email"  trimmed, NFC and lowercased, as Email
+//	normalize:"lower"  trimmed, NFC and lowercased
+//	normalize:"-"      left untouched (passwords, secrets)
+func Struct(v interface{}) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return
+	}
+	normalizeValue(value.Elem(), "")
+}
+
+// normalizeValue normalizes value in place according to mode, the field's normalize tag
+func normalizeValue(value reflect.Value, mode string) {
+	if mode == "-" {
+		return
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		if !value.IsNil() {
+			normalizeValue(value.Elem(), mode)
+		}
+	case reflect.Struct:
+		fields := value.Type()
+		for i := 0; i < value.NumField(); i++ {
+			field := fields.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			normalizeValue(value.Field(i), field.Tag.Get("normalize"))
+		}
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.String || value.Type().Elem().Kind() == reflect.Struct || value.Type().Elem().Kind() == reflect.Ptr {
+			for i := 0; i < value.Len(); i++ {
+				normalizeValue(value.Index(i), mode)
+			}
+		}
+	case reflect.String:
+		if value.CanSet() {
+			value.SetString(normalizeString(value.String(), mode))
+		}
+	}
+}
+
+// normalizeString applies a normalize tag mode to s
+func normalizeString(s, mode string) string {
+	switch mode {
+	case "email", "lower":
+		return Email(s)
+	default:
+		return norm.NFC.String(strings.TrimSpace(s))
+	}
+}
@@ -0,0 +1,58 @@
+package redact
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "email", in: "sent welcome email to jane.doe+news@example.co.uk", want: "sent welcome email to [REDACTED]"},
+		{name: "phone number", in: "SMS to +15555550123 failed", want: "SMS to [REDACTED] failed"},
+		{name: "postgres key detail", in: `Key (email)=(jane@example.com) already exists`, want: `Key (email)=([REDACTED]) already exists`},
+		{name: "password parameter", in: "login password=hunter2 rejected", want: "login password=[REDACTED] rejected"},
+		{name: "JSON secret field", in: `{"client_secret": "s3cr3t", "name": "cron"}`, want: `{"client_secret": "[REDACTED]", "name": "cron"}`},
+		{name: "query string token", in: "GET /verify?token=abc123&next=/home", want: "GET /verify?token=[REDACTED]&next=/home"},
+		{name: "code parameter", in: "callback?auth_code=xyz789", want: "callback?auth_code=[REDACTED]"},
+		{name: "bearer header", in: "Authorization: Bearer abc.def-ghi", want: "Authorization: Bearer [REDACTED]"},
+		{name: "URL password", in: "dial postgres://app:p4ss@db:5432/app failed", want: "dial postgres://app:[REDACTED]@db:5432/app failed"},
+		{name: "JWT", in: "token eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0.c2ln expired", want: "token [REDACTED] expired"},
+		{name: "bcrypt hash", in: "hash $2a$10$" + strings.Repeat("a", 53), want: "hash [REDACTED]"},
+		{name: "hex digest", in: "refresh token " + strings.Repeat("ab", 32) + " revoked", want: "refresh token [REDACTED] revoked"},
+		// SQL placeholders and UUIDs are not personal data and help debugging
+		{name: "SQL placeholder", in: "UPDATE users SET password_hash = $1 WHERE id = $2", want: "UPDATE users SET password_hash = $1 WHERE id = $2"},
+		{name: "UUID", in: "user 0190a5c4-8b7e-7c3d-9a1f-2b3c4d5e6f70 deleted", want: "user 0190a5c4-8b7e-7c3d-9a1f-2b3c4d5e6f70 deleted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.in); got != tt.want {
+				t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestError(t *testing.T) {
+	if got := Error(nil); got != "" {
+		t.Errorf("Error(nil) = %q, want empty", got)
+	}
+	if got := Error(errors.New("no user jane@example.com")); got != "no user [REDACTED]" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWriterRedactsLogEntries(t *testing.T) {
+	var out bytes.Buffer
+	logger := log.New(NewWriter(&out), "", 0)
+	logger.Printf("password reset requested by %s", "jane@example.com")
+	if got, want := out.String(), "password reset requested by [REDACTED]\n"; got != want {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
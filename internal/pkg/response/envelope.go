@@ -0,0 +1,129 @@
+package response
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"go-backend-api/internal/pkg/links"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Envelope preferences of the Prefer header, overriding the configured default per request
+const (
+	PreferEnvelopeNone = "envelope=none"
+	PreferEnvelopeFull = "envelope=full"
+)
+
+// unwrapped is whether responses are sent without the {success, data} envelope by default
+var unwrapped atomic.Bool
+
+// SetEnvelope sets whether responses are wrapped in the {success, data} envelope by default.
+// Unwrapped responses carry the resource itself, or the error info for errors, and convey the
+// outcome by status code alone; the pagination meta moves to headers.
+func SetEnvelope(enabled bool) {
+	unwrapped.Store(!enabled)
+}
+
+// wantsEnvelope reports whether the response to c is wrapped: "Prefer: envelope=none" or
+// "Prefer: envelope=full" decide, and the configured default otherwise
+func wantsEnvelope(c *gin.Context) bool {
+	Vary(c, "Prefer")
+	switch {
+	case Prefers(c, PreferEnvelopeNone):
+		c.Writer.Header().Add("Preference-Applied", PreferEnvelopeNone)
+		return false
+	case Prefers(c, PreferEnvelopeFull):
+		c.Writer.Header().Add("Preference-Applied", PreferEnvelopeFull)
+		return true
+	}
+	return !unwrapped.Load()
+}
+
+// Prefers reports whether the request carries preference in a Prefer header, such as
+// "stale-ok" or "envelope=none". Parameters after a semicolon are ignored.
+func Prefers(c *gin.Context, preference string) bool {
+	for _, header := range c.Request.Header.Values("Prefer") {
+		for _, token := range strings.Split(header, ",") {
+			token, _, _ = strings.Cut(token, ";")
+			if strings.EqualFold(strings.ReplaceAll(strings.TrimSpace(token), " ", ""), preference) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Vary adds header to the Vary header of the response, unless it is listed already
+func Vary(c *gin.Context, header string) {
+	for _, value := range c.Writer.Header().Values("Vary") {
+		for _, name := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(name), header) {
+				return
+			}
+		}
+	}
+	c.Writer.Header().Add("Vary", header)
+}
+
+// write sends data with status, wrapped in the envelope with message when it is wanted.
+// Unwrapped responses without data have no body.
+func write(c *gin.Context, status int, message string, data interface{}) {
+	if wantsEnvelope(c) {
+		c.JSON(status, Response{
+			Success: true,
+			Message: message,
+			Data:    data,
+		})
+		return
+	}
+
+	if data == nil {
+		c.Status(status)
+		return
+	}
+	c.JSON(status, data)
+}
+
+// writePage sends a page of data, with its meta in the envelope or, unwrapped, in the
+// X-Page, X-Per-Page, X-Total-Count and X-Total-Pages headers and the Link header
+func writePage(c *gin.Context, data interface{}, meta PaginationMeta) {
+	if builder := links.FromContext(c); builder != nil {
+		meta.Links = builder.PageLinks(c.Request.URL, meta.Page, meta.TotalPages)
+	}
+
+	if wantsEnvelope(c) {
+		c.JSON(http.StatusOK, PaginatedResponse{
+			Success: true,
+			Data:    data,
+			Meta:    meta,
+		})
+		return
+	}
+
+	c.Header("X-Page", strconv.Itoa(meta.Page))
+	c.Header("X-Per-Page", strconv.Itoa(meta.PerPage))
+	c.Header("X-Total-Count", strconv.Itoa(meta.Total))
+	c.Header("X-Total-Pages", strconv.Itoa(meta.TotalPages))
+	for _, rel := range []string{"prev", "next"} {
+		if link, ok := meta.Links[rel]; ok {
+			c.Writer.Header().Add("Link", "<"+link.Href+`>; rel="`+rel+`"`)
+		}
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// writeError sends errorInfo with status, in the envelope when it is wanted
+func writeError(c *gin.Context, status int, errorInfo *ErrorInfo) {
+	if wantsEnvelope(c) {
+		c.JSON(status, Response{
+			Success: false,
+			Error:   errorInfo,
+		})
+		return
+	}
+
+	c.JSON(status, errorInfo)
+}
@@ -1,9 +1,11 @@
 package response
 
 import (
+	"fmt"
 	"net/http"
 
 	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/links"
 
 	"github.com/gin-gonic/gin"
 )
@@ -19,8 +21,11 @@ type Response struct {
 // ErrorInfo represents error information in response
 type ErrorInfo struct {
 	Code    int    `json:"code"`
+	Reason  string `json:"reason,omitempty"`
 	Message string `json:"message"`
 	Details string `json:"details,omitempty"`
+	// Throttle is set on 429 and 423 responses to requests a rate limit, quota or lockout rejected
+	Throttle *ThrottleInfo `json:"throttle,omitempty"`
 }
 
 // PaginatedResponse represents a paginated API response
@@ -37,124 +42,112 @@ type PaginationMeta struct {
 	PerPage    int `json:"per_page"`
 	Total      int `json:"total"`
 	TotalPages int `json:"total_pages"`
+	// Links are the self, prev and next pages, when the client asked for links
+	Links links.Links `json:"_links,omitempty"`
 }
 
 // Success sends a success response
 func Success(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusOK, Response{
-		Success: true,
-		Data:    data,
-	})
+	write(c, http.StatusOK, "", data)
 }
 
 // SuccessWithMessage sends a success response with message
 func SuccessWithMessage(c *gin.Context, message string, data interface{}) {
-	c.JSON(http.StatusOK, Response{
-		Success: true,
-		Message: message,
-		Data:    data,
-	})
+	write(c, http.StatusOK, message, data)
 }
 
 // Created sends a created response
 func Created(c *gin.Context, data interface{}) {
-	c.JSON(http.StatusCreated, Response{
-		Success: true,
-		Message: "Resource created successfully",
-		Data:    data,
-	})
+	write(c, http.StatusCreated, "Resource created successfully", data)
+}
+
+// Accepted sends an accepted response for requests that need a further step to complete
+func Accepted(c *gin.Context, message string, data interface{}) {
+	write(c, http.StatusAccepted, message, data)
+}
+
+// MovedPermanently sends a redirect response pointing to the new location of a resource
+func MovedPermanently(c *gin.Context, location string, data interface{}) {
+	c.Header("Location", location)
+	write(c, http.StatusMovedPermanently, "Resource moved permanently", data)
 }
 
 // Paginated sends a paginated response
 func Paginated(c *gin.Context, data interface{}, meta PaginationMeta) {
-	c.JSON(http.StatusOK, PaginatedResponse{
-		Success: true,
-		Data:    data,
-		Meta:    meta,
-	})
+	writePage(c, data, meta)
+}
+
+// defaultReasons are the reasons of authentication and authorization errors without one, so
+// clients can tell every 401 and 403 apart by reason whichever layer rejected the request
+var defaultReasons = map[int]string{
+	http.StatusUnauthorized: errors.ErrUnauthorized.Reason,
+	http.StatusForbidden:    errors.ErrForbidden.Reason,
 }
 
-// Error sends an error response
+// Error sends an error response. Every error body, from handlers and middleware alike, is
+// emitted here. The status comes from the error code via errors.HTTPStatus; server errors
+// are attached to the gin context so the request log records the underlying cause while the
+// body only carries the public message. Throttled errors also get their throttle part and the
+// Retry-After and RateLimit-* headers, so every 429 and 423 has the same shape.
 func Error(c *gin.Context, err error) {
 	appErr, ok := err.(*errors.AppError)
 	if !ok {
 		appErr = errors.WrapError(err, "Internal server error")
 	}
 
+	status := appErr.Status()
 	errorInfo := &ErrorInfo{
-		Code:    appErr.Code,
+		Code:    status,
+		Reason:  appErr.Reason,
 		Message: appErr.Message,
 		Details: appErr.Details,
 	}
+	if errorInfo.Reason == "" {
+		errorInfo.Reason = defaultReasons[status]
+	}
+	if status != appErr.Code {
+		// Unknown codes are programming errors; don't echo their message to the client
+		errorInfo = &ErrorInfo{
+			Code:    status,
+			Message: errors.ErrInternal.Message,
+		}
+	}
+	if appErr.Throttle != nil && status == appErr.Code {
+		errorInfo.Throttle = throttleInfo(c, appErr.Throttle)
+	}
+	if status >= http.StatusInternalServerError {
+		_ = c.Error(fmt.Errorf("status %d (code %d): %w", status, appErr.Code, appErr))
+	}
 
-	c.JSON(appErr.Code, Response{
-		Success: false,
-		Error:   errorInfo,
-	})
+	writeError(c, status, errorInfo)
 }
 
 // BadRequest sends a bad request response
 func BadRequest(c *gin.Context, message string) {
-	c.JSON(http.StatusBadRequest, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusBadRequest,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusBadRequest, message, nil))
 }
 
 // Unauthorized sends an unauthorized response
 func Unauthorized(c *gin.Context, message string) {
-	c.JSON(http.StatusUnauthorized, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusUnauthorized,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusUnauthorized, message, nil))
 }
 
 // Forbidden sends a forbidden response
 func Forbidden(c *gin.Context, message string) {
-	c.JSON(http.StatusForbidden, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusForbidden,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusForbidden, message, nil))
 }
 
 // NotFound sends a not found response
 func NotFound(c *gin.Context, message string) {
-	c.JSON(http.StatusNotFound, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusNotFound,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusNotFound, message, nil))
 }
 
 // Conflict sends a conflict response
 func Conflict(c *gin.Context, message string) {
-	c.JSON(http.StatusConflict, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusConflict,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusConflict, message, nil))
 }
 
 // InternalError sends an internal server error response
 func InternalError(c *gin.Context, message string) {
-	c.JSON(http.StatusInternalServerError, Response{
-		Success: false,
-		Error: &ErrorInfo{
-			Code:    http.StatusInternalServerError,
-			Message: message,
-		},
-	})
+	Error(c, errors.NewAppError(http.StatusInternalServerError, message, nil))
 }
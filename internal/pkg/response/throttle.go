@@ -0,0 +1,43 @@
+package response
+
+import (
+	"strconv"
+	"time"
+
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ThrottleInfo is the throttle part of an error response, telling clients which limit
+// rejected the request and when to retry
+type ThrottleInfo struct {
+	Scope     string `json:"scope"`     // Which limit applied, e.g. route or sandbox
+	Limit     int    `json:"limit"`     // Requests the limit allows at once
+	Remaining int    `json:"remaining"` // Requests left before the limit applies
+	Reset     int    `json:"reset"`     // Seconds until another request is allowed
+}
+
+// Throttled sends err, a 429 or 423 error, with the limit that rejected the request
+func Throttled(c *gin.Context, err *errors.AppError, throttle errors.Throttle) {
+	Error(c, err.WithThrottle(throttle))
+}
+
+// throttleInfo sets the Retry-After and RateLimit-* headers of a throttled response and
+// returns the throttle part of its body
+func throttleInfo(c *gin.Context, throttle *errors.Throttle) *ThrottleInfo {
+	// Clients must wait at least a second, or they would retry right away
+	reset := max(1, int(throttle.Reset.Round(time.Second).Seconds()))
+
+	c.Header("Retry-After", strconv.Itoa(reset))
+	c.Header("RateLimit-Limit", strconv.Itoa(throttle.Limit))
+	c.Header("RateLimit-Remaining", strconv.Itoa(throttle.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(reset))
+
+	return &ThrottleInfo{
+		Scope:     throttle.Scope,
+		Limit:     throttle.Limit,
+		Remaining: throttle.Remaining,
+		Reset:     reset,
+	}
+}
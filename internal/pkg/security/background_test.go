@@ -0,0 +1,53 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/pkg/testutil"
+)
+
+// stopAfter closes stop once the test is done, before leaks are checked
+func stopAfter(t *testing.T) chan struct{} {
+	stop := make(chan struct{})
+	t.Cleanup(func() { close(stop) })
+	return stop
+}
+
+func TestBlocklistAutoReloadStops(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+
+	path := filepath.Join(t.TempDir(), "blocklist.json")
+	if err := os.WriteFile(path, []byte(`{"reserved_usernames": ["root"]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	bl, err := NewBlocklist(nil, nil, path)
+	if err != nil {
+		t.Fatalf("NewBlocklist: %v", err)
+	}
+	bl.StartAutoReload(time.Millisecond, stopAfter(t))
+
+	// Let it reload a few times
+	time.Sleep(10 * time.Millisecond)
+	if !bl.IsReservedUsername("root") {
+		t.Error("reloaded blocklist lost its entries")
+	}
+}
+
+func TestRateLimiterCleanupStops(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+
+	rl := NewRateLimiter(10, time.Minute, 10, 100)
+	rl.StartCleanup(time.Millisecond, stopAfter(t))
+	time.Sleep(10 * time.Millisecond)
+}
+
+func TestFailureTrackerCleanupStops(t *testing.T) {
+	testutil.VerifyNoLeaks(t)
+
+	ft := NewFailureTracker(time.Minute, 0)
+	ft.StartCleanup(time.Millisecond, stopAfter(t))
+	time.Sleep(10 * time.Millisecond)
+}
@@ -0,0 +1,148 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReservedUsernames are usernames reserved when no configuration is provided
+var DefaultReservedUsernames = []string{"admin", "root", "administrator", "api", "www", "mail", "ftp", "test"}
+
+// Blocklist holds reserved usernames and blocked email domains.
+// Entries come from static configuration plus an optional JSON file that can be reloaded at runtime.
+type Blocklist struct {
+	mutex             sync.RWMutex
+	baseUsernames     []string
+	baseDomains       []string
+	filePath          string
+	reservedUsernames map[string]struct{}
+	blockedDomains    map[string]struct{}
+}
+
+// blocklistFile represents the JSON layout of a blocklist file
+type blocklistFile struct {
+	ReservedUsernames   []string `json:"reserved_usernames"`
+	BlockedEmailDomains []string `json:"blocked_email_domains"`
+}
+
+// NewBlocklist creates a blocklist from static entries and an optional file path
+func NewBlocklist(reservedUsernames, blockedDomains []string, filePath string) (*Blocklist, error) {
+	bl := &Blocklist{
+		baseUsernames: reservedUsernames,
+		baseDomains:   blockedDomains,
+		filePath:      filePath,
+	}
+
+	if err := bl.Reload(); err != nil {
+		return nil, err
+	}
+
+	return bl, nil
+}
+
+// Reload rebuilds the blocklist from the static entries and the blocklist file
+func (bl *Blocklist) Reload() error {
+	usernames := toSet(bl.baseUsernames)
+	domains := toSet(bl.baseDomains)
+
+	if bl.filePath != "" {
+		data, err := os.ReadFile(bl.filePath)
+		if err != nil {
+			return fmt.Errorf("failed to read blocklist file: %w", err)
+		}
+
+		var file blocklistFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse blocklist file: %w", err)
+		}
+
+		for entry := range toSet(file.ReservedUsernames) {
+			usernames[entry] = struct{}{}
+		}
+		for entry := range toSet(file.BlockedEmailDomains) {
+			domains[entry] = struct{}{}
+		}
+	}
+
+	bl.mutex.Lock()
+	bl.reservedUsernames = usernames
+	bl.blockedDomains = domains
+	bl.mutex.Unlock()
+
+	return nil
+}
+
+// StartAutoReload periodically reloads the blocklist file until stop is closed
+func (bl *Blocklist) StartAutoReload(interval time.Duration, stop <-chan struct{}) {
+	if bl.filePath == "" || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := bl.Reload(); err != nil {
+					// Keep serving the previous entries when the file is temporarily invalid
+					log.Printf("Failed to reload blocklist: %v", err)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// IsReservedUsername checks if a username is reserved (case-insensitive)
+func (bl *Blocklist) IsReservedUsername(username string) bool {
+	bl.mutex.RLock()
+	defer bl.mutex.RUnlock()
+
+	_, reserved := bl.reservedUsernames[strings.ToLower(strings.TrimSpace(username))]
+	return reserved
+}
+
+// IsBlockedEmail checks if an email address belongs to a blocked domain or one of its subdomains
+func (bl *Blocklist) IsBlockedEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+
+	bl.mutex.RLock()
+	defer bl.mutex.RUnlock()
+
+	for domain != "" {
+		if _, blocked := bl.blockedDomains[domain]; blocked {
+			return true
+		}
+		dot := strings.Index(domain, ".")
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+
+	return false
+}
+
+// toSet normalizes entries into a lowercase set, skipping blanks
+func toSet(entries []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(entries))
+	for _, entry := range entries {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry != "" {
+			set[entry] = struct{}{}
+		}
+	}
+	return set
+}
@@ -0,0 +1,134 @@
+package security
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/pkg/errors"
+)
+
+// CaptchaVerifier verifies a CAPTCHA response token submitted by a client
+type CaptchaVerifier interface {
+	Verify(token, remoteIP string) (bool, error)
+}
+
+// captchaVerifyURLs maps supported providers to their siteverify endpoints.
+// hCaptcha, reCAPTCHA and Turnstile share the same form-encoded siteverify protocol.
+var captchaVerifyURLs = map[string]string{
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+	"recaptcha": "https://www.google.com/recaptcha/api/siteverify",
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// siteVerifyCaptcha verifies tokens against a provider's siteverify endpoint
+type siteVerifyCaptcha struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// siteVerifyResponse represents the common part of a siteverify response
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// NewCaptchaVerifier creates a verifier for the given provider (hcaptcha, recaptcha or turnstile)
+func NewCaptchaVerifier(provider, secret string) (CaptchaVerifier, error) {
+	verifyURL, ok := captchaVerifyURLs[strings.ToLower(provider)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported captcha provider: %s", provider)
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("captcha secret is required for provider %s", provider)
+	}
+
+	return &siteVerifyCaptcha{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}, nil
+}
+
+// Verify checks a response token with the provider
+func (v *siteVerifyCaptcha) Verify(token, remoteIP string) (bool, error) {
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	resp, err := v.client.PostForm(v.verifyURL, form)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification returned status %d", resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("failed to decode captcha verification response: %w", err)
+	}
+
+	return result.Success, nil
+}
+
+// CaptchaGuard decides when a CAPTCHA is required and verifies it.
+// A CAPTCHA is only requested from clients with repeated recent failures; a nil guard disables CAPTCHA.
+type CaptchaGuard struct {
+	verifier  CaptchaVerifier
+	failures  *FailureTracker
+	threshold int
+}
+
+// NewCaptchaGuard creates a guard that requires a CAPTCHA once a client reaches threshold
+// failures. A threshold of zero requires a CAPTCHA on every request.
+func NewCaptchaGuard(verifier CaptchaVerifier, failures *FailureTracker, threshold int) *CaptchaGuard {
+	return &CaptchaGuard{
+		verifier:  verifier,
+		failures:  failures,
+		threshold: threshold,
+	}
+}
+
+// Required reports whether the client must solve a CAPTCHA
+func (g *CaptchaGuard) Required(clientIP string) bool {
+	if g == nil {
+		return false
+	}
+	return g.failures.Failures(clientIP) >= g.threshold
+}
+
+// Check verifies the CAPTCHA token when one is required for the client
+func (g *CaptchaGuard) Check(token, clientIP string) error {
+	if !g.Required(clientIP) {
+		return nil
+	}
+	if token == "" {
+		return errors.ErrCaptchaRequired
+	}
+
+	ok, err := g.verifier.Verify(token, clientIP)
+	if err != nil {
+		return errors.WrapErrorWithCode(err, http.StatusServiceUnavailable, "CAPTCHA verification unavailable")
+	}
+	if !ok {
+		return errors.ErrCaptchaInvalid
+	}
+
+	return nil
+}
+
+// RecordFailure records a failed attempt from the client
+func (g *CaptchaGuard) RecordFailure(clientIP string) {
+	if g == nil {
+		return
+	}
+	g.failures.RecordFailure(clientIP)
+}
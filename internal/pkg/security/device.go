@@ -0,0 +1,43 @@
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+// DeviceIDHeader is the request header carrying a client-provided device identifier
+const DeviceIDHeader = "X-Device-ID"
+
+// maxDeviceIDLength bounds client-provided device identifiers
+const maxDeviceIDLength = 128
+
+// fingerprintHeaders are the request headers that describe the client software and platform.
+// The IP address is deliberately excluded because it changes as devices move between networks.
+var fingerprintHeaders = []string{
+	"User-Agent",
+	"Accept-Language",
+	"Sec-CH-UA",
+	"Sec-CH-UA-Platform",
+	"Sec-CH-UA-Mobile",
+}
+
+// DeviceID returns the client-provided device identifier of a request, or an empty string
+func DeviceID(r *http.Request) string {
+	deviceID := strings.TrimSpace(r.Header.Get(DeviceIDHeader))
+	if len(deviceID) > maxDeviceIDLength {
+		return ""
+	}
+	return deviceID
+}
+
+// DeviceFingerprint computes a server-side fingerprint of the client from its request headers
+func DeviceFingerprint(r *http.Request) string {
+	hash := sha256.New()
+	for _, header := range fingerprintHeaders {
+		hash.Write([]byte(strings.TrimSpace(r.Header.Get(header))))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,138 @@
+package security
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/pkg/clock"
+)
+
+// DefaultFailureTrackerMaxEntries caps how many keys a failure tracker keeps when none is configured
+const DefaultFailureTrackerMaxEntries = 100000
+
+// FailureTracker counts recent failures per key (typically a client IP) within a sliding window.
+// The number of keys is capped: once full, the key whose last failure is the oldest is evicted,
+// so a flood of spoofed or rotating client IPs cannot grow memory without bound.
+type FailureTracker struct {
+	mutex      sync.Mutex
+	window     time.Duration
+	maxEntries int
+	failures   map[string]*list.Element
+	lru        *list.List // Keys ordered from the most to the least recent failure
+	clock      clock.Clock
+}
+
+// failureEntry holds the failures of a key, oldest first
+type failureEntry struct {
+	key   string
+	times []time.Time
+}
+
+// NewFailureTracker creates a failure tracker that forgets failures older than window, keeping
+// at most maxEntries keys (DefaultFailureTrackerMaxEntries when not positive)
+func NewFailureTracker(window time.Duration, maxEntries int) *FailureTracker {
+	if maxEntries < 1 {
+		maxEntries = DefaultFailureTrackerMaxEntries
+	}
+	return &FailureTracker{
+		window:     window,
+		maxEntries: maxEntries,
+		failures:   make(map[string]*list.Element),
+		lru:        list.New(),
+		clock:      clock.Real,
+	}
+}
+
+// SetClock makes the window slide with c instead of the system clock
+func (ft *FailureTracker) SetClock(c clock.Clock) {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	ft.clock = clock.OrReal(c)
+}
+
+// RecordFailure records a failure for key
+func (ft *FailureTracker) RecordFailure(key string) {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	now := ft.clock.Now()
+	if elem, ok := ft.failures[key]; ok {
+		entry := elem.Value.(*failureEntry)
+		entry.times = append(ft.pruned(entry.times, now), now)
+		ft.lru.MoveToFront(elem)
+		return
+	}
+
+	if ft.lru.Len() >= ft.maxEntries {
+		ft.remove(ft.lru.Back())
+	}
+	ft.failures[key] = ft.lru.PushFront(&failureEntry{key: key, times: []time.Time{now}})
+}
+
+// Failures returns the number of failures for key within the window
+func (ft *FailureTracker) Failures(key string) int {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	elem, ok := ft.failures[key]
+	if !ok {
+		return 0
+	}
+	entry := elem.Value.(*failureEntry)
+	entry.times = ft.pruned(entry.times, ft.clock.Now())
+	if len(entry.times) == 0 {
+		ft.remove(elem)
+	}
+	return len(entry.times)
+}
+
+// StartCleanup periodically drops keys without recent failures until stop is closed
+func (ft *FailureTracker) StartCleanup(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ft.cleanup()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// cleanup drops the keys whose last failure is outside the window
+func (ft *FailureTracker) cleanup() {
+	ft.mutex.Lock()
+	defer ft.mutex.Unlock()
+
+	// Keys are in order of their last failure, so stop at the first one still in the window
+	cutoff := ft.clock.Now().Add(-ft.window)
+	for elem := ft.lru.Back(); elem != nil; elem = ft.lru.Back() {
+		entry := elem.Value.(*failureEntry)
+		if entry.times[len(entry.times)-1].After(cutoff) {
+			break
+		}
+		ft.remove(elem)
+	}
+}
+
+// pruned drops the failures outside the window from times
+func (ft *FailureTracker) pruned(times []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-ft.window)
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+// remove forgets the key of elem; the caller must hold the mutex
+func (ft *FailureTracker) remove(elem *list.Element) {
+	ft.lru.Remove(elem)
+	delete(ft.failures, elem.Value.(*failureEntry).key)
+}
@@ -0,0 +1,50 @@
+package security
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/pkg/clock"
+)
+
+func TestFailureTrackerCountsWithinTheWindow(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	ft := NewFailureTracker(10*time.Minute, 0)
+	ft.SetClock(clk)
+
+	ft.RecordFailure("198.51.100.7")
+	clk.Advance(6 * time.Minute)
+	ft.RecordFailure("198.51.100.7")
+	if got := ft.Failures("198.51.100.7"); got != 2 {
+		t.Errorf("got %d failures, want 2", got)
+	}
+	clk.Advance(5 * time.Minute)
+	if got := ft.Failures("198.51.100.7"); got != 1 {
+		t.Errorf("after the first left the window: got %d failures, want 1", got)
+	}
+	clk.Advance(10 * time.Minute)
+	ft.cleanup()
+	if got := len(ft.failures); got != 0 {
+		t.Errorf("cleanup kept %d keys without recent failures", got)
+	}
+}
+
+func TestFailureTrackerEvictsTheOldestKeys(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	ft := NewFailureTracker(time.Hour, 100)
+	ft.SetClock(clk)
+
+	// A client failing repeatedly stays tracked while a flood of spoofed IPs passes through
+	for i := range 1000 {
+		ft.RecordFailure("198.51.100.7")
+		ft.RecordFailure(fmt.Sprintf("203.0.113.%d/%d", i%256, i))
+		clk.Advance(time.Second)
+	}
+	if got := len(ft.failures); got != 100 {
+		t.Errorf("tracking %d keys, want at most 100", got)
+	}
+	if got := ft.Failures("198.51.100.7"); got != 1000 {
+		t.Errorf("repeat offender has %d failures, want 1000", got)
+	}
+}
@@ -0,0 +1,36 @@
+package security
+
+import "math"
+
+// GeoLocation describes where an IP address is located and which network it belongs to
+type GeoLocation struct {
+	Country   string  `json:"country,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude,omitempty"`
+	Longitude float64 `json:"longitude,omitempty"`
+	ASN       uint    `json:"asn,omitempty"`
+	ASOrg     string  `json:"as_org,omitempty"`
+}
+
+// HasCoordinates reports whether the location has a usable position
+func (l *GeoLocation) HasCoordinates() bool {
+	return l != nil && (l.Latitude != 0 || l.Longitude != 0)
+}
+
+// GeoLocator resolves IP addresses to locations. Lookups return nil when the address is unknown.
+type GeoLocator interface {
+	Lookup(ip string) *GeoLocation
+}
+
+// earthRadiusKm is the mean radius of the Earth
+const earthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two locations in kilometres
+func DistanceKm(a, b *GeoLocation) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLon := (b.Longitude - a.Longitude) * math.Pi / 180
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Sqrt(h))
+}
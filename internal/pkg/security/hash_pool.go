@@ -0,0 +1,174 @@
+package security
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrHashPoolSaturated is returned when too many password hash operations are already waiting
+var ErrHashPoolSaturated = errors.New("password hashing capacity exceeded")
+
+// HashPool bounds how many bcrypt operations run in parallel so login storms queue
+// instead of exhausting CPU, and sheds requests once the queue is full.
+// A nil HashPool runs every operation immediately.
+type HashPool struct {
+	slots    chan struct{}
+	maxQueue int64
+	queued   int64
+
+	mu        sync.Mutex
+	completed uint64
+	shed      uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+// HashPoolStats is a snapshot of HashPool activity
+type HashPoolStats struct {
+	MaxParallel    int     `json:"max_parallel"`
+	MaxQueue       int     `json:"max_queue"`
+	InFlight       int     `json:"in_flight"`
+	Queued         int     `json:"queued"`
+	Completed      uint64  `json:"completed"`
+	Shed           uint64  `json:"shed"`
+	AvgQueueWaitMs float64 `json:"avg_queue_wait_ms"`
+	MaxQueueWaitMs float64 `json:"max_queue_wait_ms"`
+}
+
+// NewHashPool creates a pool running at most maxParallel hash operations at once,
+// with at most maxQueue operations waiting for a slot
+func NewHashPool(maxParallel, maxQueue int) *HashPool {
+	if maxParallel < 1 {
+		maxParallel = 1
+	}
+	if maxQueue < 0 {
+		maxQueue = 0
+	}
+	return &HashPool{
+		slots:    make(chan struct{}, maxParallel),
+		maxQueue: int64(maxQueue),
+	}
+}
+
+// Generate hashes a password with bcrypt at the given cost
+func (p *HashPool) Generate(password string, cost int) (string, error) {
+	if err := p.acquire(); err != nil {
+		return "", err
+	}
+	defer p.release()
+
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedBytes), nil
+}
+
+// Compare verifies a password against a bcrypt hash
+func (p *HashPool) Compare(hashedPassword, password string) error {
+	if err := p.acquire(); err != nil {
+		return err
+	}
+	defer p.release()
+
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+// dummyHash is compared against when no account matches a sign-in, so unknown and known
+// accounts take the same bcrypt time. It is computed on first use.
+var (
+	dummyHash     []byte
+	dummyHashOnce sync.Once
+)
+
+// CompareDummy spends the same time as Compare on a hash that never matches
+func (p *HashPool) CompareDummy(password string) error {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password-for-timing-parity"), bcrypt.DefaultCost)
+	})
+
+	if err := p.acquire(); err != nil {
+		return err
+	}
+	defer p.release()
+
+	bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+	return nil
+}
+
+// Stats returns a snapshot of pool activity
+func (p *HashPool) Stats() HashPoolStats {
+	if p == nil {
+		return HashPoolStats{}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := HashPoolStats{
+		MaxParallel:    cap(p.slots),
+		MaxQueue:       int(p.maxQueue),
+		InFlight:       len(p.slots),
+		Queued:         int(atomic.LoadInt64(&p.queued)),
+		Completed:      p.completed,
+		Shed:           p.shed,
+		MaxQueueWaitMs: float64(p.maxWait) / float64(time.Millisecond),
+	}
+	if p.completed > 0 {
+		stats.AvgQueueWaitMs = float64(p.totalWait) / float64(p.completed) / float64(time.Millisecond)
+	}
+	return stats
+}
+
+// acquire waits for a free slot, or fails fast when the queue is full
+func (p *HashPool) acquire() error {
+	if p == nil {
+		return nil
+	}
+
+	// Take a slot straight away when one is free
+	select {
+	case p.slots <- struct{}{}:
+		p.recordWait(0)
+		return nil
+	default:
+	}
+
+	if atomic.AddInt64(&p.queued, 1) > p.maxQueue {
+		atomic.AddInt64(&p.queued, -1)
+		p.mu.Lock()
+		p.shed++
+		p.mu.Unlock()
+		return ErrHashPoolSaturated
+	}
+
+	start := time.Now()
+	p.slots <- struct{}{}
+	atomic.AddInt64(&p.queued, -1)
+	p.recordWait(time.Since(start))
+	return nil
+}
+
+// release frees the slot taken by acquire
+func (p *HashPool) release() {
+	if p == nil {
+		return
+	}
+	<-p.slots
+}
+
+// recordWait records how long an operation waited for a slot
+func (p *HashPool) recordWait(wait time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.completed++
+	p.totalWait += wait
+	if wait > p.maxWait {
+		p.maxWait = wait
+	}
+}
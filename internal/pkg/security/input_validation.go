@@ -5,37 +5,89 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// Patterns are compiled once at package init; the XSS signatures are merged into a
+// single alternation so each value is scanned once instead of once per signature.
+var (
+	usernamePattern      = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	emailPattern         = regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+	scriptElementPattern = regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
+	jsProtocolPattern    = regexp.MustCompile(`(?i)javascript:`)
+	eventHandlerPattern  = regexp.MustCompile(`(?i)\s*on\w+\s*=\s*"[^"]*"`)
+	xssPattern           = compileXSSPattern()
+)
+
+// Elements, protocols and event handler attributes that validateNoXSS rejects
+var (
+	xssPairedElements = []string{
+		"script", "iframe", "object", "embed", "applet", "form",
+		"textarea", "select", "option", "button", "style",
+	}
+	xssVoidElements  = []string{"input", "link", "meta"}
+	xssProtocols     = []string{"javascript", "vbscript"}
+	xssEventHandlers = []string{
+		"onload", "onerror", "onclick", "onmouseover", "onfocus", "onblur",
+		"onchange", "onsubmit", "onreset", "onselect", "onkeydown", "onkeyup",
+		"onkeypress", "onmousedown", "onmouseup", "onmousemove", "onmouseout", "onmouseenter",
+		"onmouseleave", "oncontextmenu", "ondblclick", "onwheel", "onabort", "oncanplay",
+		"oncanplaythrough", "ondurationchange", "onemptied", "onended", "onloadeddata", "onloadedmetadata",
+		"onloadstart", "onpause", "onplay", "onplaying", "onprogress", "onratechange",
+		"onseeked", "onseeking", "onstalled", "onsuspend", "ontimeupdate", "onvolumechange",
+		"onwaiting",
+	}
+)
+
+// compileXSSPattern builds one case-insensitive regex matching any of the XSS signatures.
+// RE2 has no backreferences, so each paired element gets its own alternative.
+func compileXSSPattern() *regexp.Regexp {
+	var alternatives []string
+	for _, element := range xssPairedElements {
+		alternatives = append(alternatives, "<"+element+"[^>]*>.*?</"+element+">")
+	}
+	alternatives = append(alternatives,
+		"<(?:"+strings.Join(xssVoidElements, "|")+")[^>]*>",
+		"(?:"+strings.Join(xssProtocols, "|")+"):",
+		"(?:"+strings.Join(xssEventHandlers, "|")+`)\s*=`,
+	)
+	return regexp.MustCompile("(?i)" + strings.Join(alternatives, "|"))
+}
+
 // InputValidator provides enhanced input validation and sanitization
 type InputValidator struct {
 	validator *validator.Validate
+	blocklist *Blocklist
 }
 
-// NewInputValidator creates a new input validator
+// NewInputValidator creates a new input validator using the default reserved usernames
 func NewInputValidator() *InputValidator {
+	blocklist, _ := NewBlocklist(DefaultReservedUsernames, nil, "")
+	return NewInputValidatorWithBlocklist(blocklist)
+}
+
+// NewInputValidatorWithBlocklist creates a new input validator backed by the given blocklist
+func NewInputValidatorWithBlocklist(blocklist *Blocklist) *InputValidator {
 	v := validator.New()
+	iv := &InputValidator{validator: v, blocklist: blocklist}
 
 	// Register custom validators
-	if err := v.RegisterValidation("username", validateUsername); err != nil {
+	if err := v.RegisterValidation("username", iv.validateUsername); err != nil {
 		panic("failed to register username validator: " + err.Error())
 	}
 	if err := v.RegisterValidation("password", validatePassword); err != nil {
 		panic("failed to register password validator: " + err.Error())
 	}
-	if err := v.RegisterValidation("email", validateEmail); err != nil {
+	if err := v.RegisterValidation("email", iv.validateEmail); err != nil {
 		panic("failed to register email validator: " + err.Error())
 	}
-	if err := v.RegisterValidation("no_sql_injection", validateNoSQLInjection); err != nil {
-		panic("failed to register no_sql_injection validator: " + err.Error())
-	}
 	if err := v.RegisterValidation("no_xss", validateNoXSS); err != nil {
 		panic("failed to register no_xss validator: " + err.Error())
 	}
 
-	return &InputValidator{validator: v}
+	return iv
 }
 
 // Validate validates a struct
@@ -60,16 +112,13 @@ func (iv *InputValidator) SanitizeString(input string) string {
 // SanitizeHTML sanitizes HTML input (more permissive than SanitizeString)
 func (iv *InputValidator) SanitizeHTML(input string) string {
 	// Remove script tags and their content
-	scriptRegex := regexp.MustCompile(`(?i)<script[^>]*>.*?</script>`)
-	sanitized := scriptRegex.ReplaceAllString(input, "")
+	sanitized := scriptElementPattern.ReplaceAllString(input, "")
 
 	// Remove javascript: protocols
-	jsRegex := regexp.MustCompile(`(?i)javascript:`)
-	sanitized = jsRegex.ReplaceAllString(sanitized, "")
+	sanitized = jsProtocolPattern.ReplaceAllString(sanitized, "")
 
 	// Remove on* event handlers
-	eventRegex := regexp.MustCompile(`(?i)\s*on\w+\s*=\s*"[^"]*"`)
-	sanitized = eventRegex.ReplaceAllString(sanitized, "")
+	sanitized = eventHandlerPattern.ReplaceAllString(sanitized, "")
 
 	// HTML escape remaining content
 	sanitized = html.EscapeString(sanitized)
@@ -78,37 +127,29 @@ func (iv *InputValidator) SanitizeHTML(input string) string {
 }
 
 // validateUsername validates username format and security
-func validateUsername(fl validator.FieldLevel) bool {
+func (iv *InputValidator) validateUsername(fl validator.FieldLevel) bool {
 	username := fl.Field().String()
 
 	// Length check
-	if len(username) < 3 || len(username) > 20 {
+	if length := utf8.RuneCountInString(username); length < 3 || length > 20 {
 		return false
 	}
 
 	// Only alphanumeric and underscores
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_]+$`, username)
-	if !matched {
+	if !usernamePattern.MatchString(username) {
 		return false
 	}
 
 	// Check for reserved usernames
-	reserved := []string{"admin", "root", "administrator", "api", "www", "mail", "ftp", "test"}
-	for _, reserved := range reserved {
-		if strings.EqualFold(username, reserved) {
-			return false
-		}
-	}
-
-	return true
+	return !iv.blocklist.IsReservedUsername(username)
 }
 
 // validatePassword validates password strength
 func validatePassword(fl validator.FieldLevel) bool {
 	password := fl.Field().String()
 
-	// Length check
-	if len(password) < 8 {
+	// Length check, in characters; bcrypt also caps the encoded length
+	if utf8.RuneCountInString(password) < 8 || len(password) > BcryptMaxBytes {
 		return false
 	}
 
@@ -151,12 +192,11 @@ func validatePassword(fl validator.FieldLevel) bool {
 }
 
 // validateEmail validates email format
-func validateEmail(fl validator.FieldLevel) bool {
+func (iv *InputValidator) validateEmail(fl validator.FieldLevel) bool {
 	email := fl.Field().String()
 
 	// Basic email regex
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(email) {
+	if !emailPattern.MatchString(email) {
 		return false
 	}
 
@@ -171,122 +211,11 @@ func validateEmail(fl validator.FieldLevel) bool {
 		}
 	}
 
-	return true
-}
-
-// validateNoSQLInjection validates against SQL injection patterns
-func validateNoSQLInjection(fl validator.FieldLevel) bool {
-	input := fl.Field().String()
-
-	// Common SQL injection patterns
-	sqlPatterns := []string{
-		"(?i)(union|select|insert|update|delete|drop|create|alter|exec|execute)",
-		"(?i)(or|and)\\s+\\d+\\s*=\\s*\\d+",
-		"(?i)(or|and)\\s+'.*'\\s*=\\s*'.*'",
-		"(?i)(or|and)\\s+\".*\"\\s*=\\s*\".*\"",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*=\\s*[a-zA-Z_][a-zA-Z0-9_]*",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*like\\s+",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*in\\s*\\(",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*between\\s+",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*is\\s+null",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*is\\s+not\\s+null",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*exists\\s*\\(",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*not\\s+exists\\s*\\(",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*in\\s*\\(",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*not\\s+in\\s*\\(",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*like\\s+",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*not\\s+like\\s+",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*regexp",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*not\\s+regexp",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*similar\\s+to",
-		"(?i)(or|and)\\s+[a-zA-Z_][a-zA-Z0-9_]*\\s*not\\s+similar\\s+to",
-	}
-
-	for _, pattern := range sqlPatterns {
-		matched, _ := regexp.MatchString(pattern, input)
-		if matched {
-			return false
-		}
-	}
-
-	return true
+	// Check for blocked (e.g. disposable) email domains
+	return !iv.blocklist.IsBlockedEmail(email)
 }
 
 // validateNoXSS validates against XSS patterns
 func validateNoXSS(fl validator.FieldLevel) bool {
-	input := fl.Field().String()
-
-	// Common XSS patterns
-	xssPatterns := []string{
-		"(?i)<script[^>]*>.*?</script>",
-		"(?i)<iframe[^>]*>.*?</iframe>",
-		"(?i)<object[^>]*>.*?</object>",
-		"(?i)<embed[^>]*>.*?</embed>",
-		"(?i)<applet[^>]*>.*?</applet>",
-		"(?i)<form[^>]*>.*?</form>",
-		"(?i)<input[^>]*>",
-		"(?i)<textarea[^>]*>.*?</textarea>",
-		"(?i)<select[^>]*>.*?</select>",
-		"(?i)<option[^>]*>.*?</option>",
-		"(?i)<button[^>]*>.*?</button>",
-		"(?i)<link[^>]*>",
-		"(?i)<meta[^>]*>",
-		"(?i)<style[^>]*>.*?</style>",
-		"(?i)<link[^>]*>",
-		"(?i)javascript:",
-		"(?i)vbscript:",
-		"(?i)onload\\s*=",
-		"(?i)onerror\\s*=",
-		"(?i)onclick\\s*=",
-		"(?i)onmouseover\\s*=",
-		"(?i)onfocus\\s*=",
-		"(?i)onblur\\s*=",
-		"(?i)onchange\\s*=",
-		"(?i)onsubmit\\s*=",
-		"(?i)onreset\\s*=",
-		"(?i)onselect\\s*=",
-		"(?i)onkeydown\\s*=",
-		"(?i)onkeyup\\s*=",
-		"(?i)onkeypress\\s*=",
-		"(?i)onmousedown\\s*=",
-		"(?i)onmouseup\\s*=",
-		"(?i)onmousemove\\s*=",
-		"(?i)onmouseout\\s*=",
-		"(?i)onmouseenter\\s*=",
-		"(?i)onmouseleave\\s*=",
-		"(?i)oncontextmenu\\s*=",
-		"(?i)ondblclick\\s*=",
-		"(?i)onwheel\\s*=",
-		"(?i)onabort\\s*=",
-		"(?i)oncanplay\\s*=",
-		"(?i)oncanplaythrough\\s*=",
-		"(?i)ondurationchange\\s*=",
-		"(?i)onemptied\\s*=",
-		"(?i)onended\\s*=",
-		"(?i)onerror\\s*=",
-		"(?i)onloadeddata\\s*=",
-		"(?i)onloadedmetadata\\s*=",
-		"(?i)onloadstart\\s*=",
-		"(?i)onpause\\s*=",
-		"(?i)onplay\\s*=",
-		"(?i)onplaying\\s*=",
-		"(?i)onprogress\\s*=",
-		"(?i)onratechange\\s*=",
-		"(?i)onseeked\\s*=",
-		"(?i)onseeking\\s*=",
-		"(?i)onstalled\\s*=",
-		"(?i)onsuspend\\s*=",
-		"(?i)ontimeupdate\\s*=",
-		"(?i)onvolumechange\\s*=",
-		"(?i)onwaiting\\s*=",
-	}
-
-	for _, pattern := range xssPatterns {
-		matched, _ := regexp.MatchString(pattern, input)
-		if matched {
-			return false
-		}
-	}
-
-	return true
+	return !xssPattern.MatchString(fl.Field().String())
 }
@@ -0,0 +1,99 @@
+package security
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// benchmarkInputs are a clean post body, which has to be scanned to the end, and one with a
+// signature near the end
+var benchmarkInputs = map[string]string{
+	"clean":     strings.Repeat("A perfectly ordinary sentence about Go, onboarding and formulas = fun. ", 20),
+	"malicious": strings.Repeat("A perfectly ordinary sentence. ", 20) + `<img src=x onerror="alert(1)">`,
+}
+
+// perSignaturePatterns are the XSS signatures as separate patterns, matched one by one with
+// regexp.MatchString the way validateNoXSS did before they were merged and compiled once
+func perSignaturePatterns() []string {
+	var patterns []string
+	for _, element := range xssPairedElements {
+		patterns = append(patterns, "(?i)<"+element+"[^>]*>.*?</"+element+">")
+	}
+	for _, element := range xssVoidElements {
+		patterns = append(patterns, "(?i)<"+element+"[^>]*>")
+	}
+	for _, protocol := range xssProtocols {
+		patterns = append(patterns, "(?i)"+protocol+":")
+	}
+	for _, handler := range xssEventHandlers {
+		patterns = append(patterns, `(?i)`+handler+`\s*=`)
+	}
+	return patterns
+}
+
+func BenchmarkNoXSS(b *testing.B) {
+	patterns := perSignaturePatterns()
+	for name, input := range benchmarkInputs {
+		b.Run("merged/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				xssPattern.MatchString(input)
+			}
+		})
+		b.Run("per signature/"+name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				for _, pattern := range patterns {
+					if matched, _ := regexp.MatchString(pattern, input); matched {
+						break
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkInputValidatorNoXSS(b *testing.B) {
+	iv := NewInputValidator()
+	type post struct {
+		Content string `validate:"no_xss"`
+	}
+	for name, input := range benchmarkInputs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = iv.Validate(&post{Content: input})
+			}
+		})
+	}
+}
+
+func BenchmarkSanitizeHTML(b *testing.B) {
+	iv := NewInputValidator()
+	for name, input := range benchmarkInputs {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				iv.SanitizeHTML(input)
+			}
+		})
+	}
+}
+
+func BenchmarkPasswordPolicy(b *testing.B) {
+	policy := DefaultPasswordPolicy()
+	for name, password := range map[string]string{
+		"strong":     "Tr0ub4dor&3-Horse!",
+		"sequential": "Xy9!abcdQ",
+		"repeated":   "Zq7!Zq7!mm",
+		"forbidden":  "MyPassword1!",
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = policy.ValidatePassword(password)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInputValidatorUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		valid    bool
+	}{
+		{"3 ASCII characters", "abc", true},
+		{"20 ASCII characters", strings.Repeat("a", 20), true},
+		{"2 ASCII characters", "ab", false},
+		{"21 ASCII characters", strings.Repeat("a", 21), false},
+		// Only ASCII letters, digits and underscores are allowed, however few bytes they take
+		{"3 CJK characters", cjk(3), false},
+		{"a single emoji", "go" + emoji(1), false},
+		{"accented letter", "josé", false},
+		{"reserved", "admin", false},
+	}
+
+	iv := NewInputValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := iv.validator.Var(tt.username, "username"); (err == nil) != tt.valid {
+				t.Errorf("username %q: got error %v, want valid %v", tt.username, err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestInputValidatorPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"8 characters of 17 bytes", "Ab1" + cjk(5), true},
+		{"7 characters of 14 bytes", "Ab1" + cjk(4), false},
+		{"emoji count as special characters", "Ab" + emoji(6), true},
+		{"two character types", "ab" + emoji(6), false},
+		{"72 bytes", "Ab1!xy" + cjk(22), true},
+		{"73 bytes", "Ab1!xyz" + cjk(22), false},
+	}
+
+	iv := NewInputValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := iv.validator.Var(tt.password, "password"); (err == nil) != tt.valid {
+				t.Errorf("password %q: got error %v, want valid %v", tt.password, err, tt.valid)
+			}
+		})
+	}
+}
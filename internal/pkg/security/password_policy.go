@@ -5,18 +5,25 @@ import (
 	"crypto/subtle"
 	"encoding/base64"
 	"fmt"
-	"regexp"
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
+
+	"go-backend-api/internal/pkg/clock"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
+// BcryptMaxBytes is the longest password bcrypt hashes, in bytes
+const BcryptMaxBytes = 72
+
 // PasswordPolicy defines password requirements
 type PasswordPolicy struct {
-	MinLength        int
-	MaxLength        int
+	MinLength int
+	MaxLength int
+	// MaxBytes caps the encoded length, since bcrypt rejects passwords over 72 bytes
+	MaxBytes         int
 	RequireUppercase bool
 	RequireLowercase bool
 	RequireNumbers   bool
@@ -29,7 +36,8 @@ type PasswordPolicy struct {
 func DefaultPasswordPolicy() *PasswordPolicy {
 	return &PasswordPolicy{
 		MinLength:        8,
-		MaxLength:        128,
+		MaxLength:        72,
+		MaxBytes:         BcryptMaxBytes,
 		RequireUppercase: true,
 		RequireLowercase: true,
 		RequireNumbers:   true,
@@ -44,13 +52,17 @@ func DefaultPasswordPolicy() *PasswordPolicy {
 
 // ValidatePassword validates a password against the policy
 func (pp *PasswordPolicy) ValidatePassword(password string) error {
-	// Length check
-	if len(password) < pp.MinLength {
+	// Length check, counted in characters rather than bytes
+	length := utf8.RuneCountInString(password)
+	if length < pp.MinLength {
 		return fmt.Errorf("password must be at least %d characters long", pp.MinLength)
 	}
-	if len(password) > pp.MaxLength {
+	if length > pp.MaxLength {
 		return fmt.Errorf("password must be no more than %d characters long", pp.MaxLength)
 	}
+	if pp.MaxBytes > 0 && len(password) > pp.MaxBytes {
+		return fmt.Errorf("password is too long; use fewer or simpler characters")
+	}
 
 	// Character type checks
 	var (
@@ -96,9 +108,10 @@ func (pp *PasswordPolicy) ValidatePassword(password string) error {
 
 	// Check for consecutive characters
 	if pp.MaxConsecutive > 0 {
+		runes := []rune(password)
 		consecutive := 1
-		for i := 1; i < len(password); i++ {
-			if password[i] == password[i-1] {
+		for i := 1; i < len(runes); i++ {
+			if runes[i] == runes[i-1] {
 				consecutive++
 				if consecutive > pp.MaxConsecutive {
 					return fmt.Errorf("password cannot contain more than %d consecutive identical characters", pp.MaxConsecutive)
@@ -117,6 +130,34 @@ func (pp *PasswordPolicy) ValidatePassword(password string) error {
 	return nil
 }
 
+// Describe summarizes the policy's requirements for display to users
+func (pp *PasswordPolicy) Describe() string {
+	var required []string
+	if pp.RequireUppercase {
+		required = append(required, "an uppercase letter")
+	}
+	if pp.RequireLowercase {
+		required = append(required, "a lowercase letter")
+	}
+	if pp.RequireNumbers {
+		required = append(required, "a number")
+	}
+	if pp.RequireSpecial {
+		required = append(required, "a special character")
+	}
+
+	description := fmt.Sprintf("Password must be %d-%d characters", pp.MinLength, pp.MaxLength)
+	switch len(required) {
+	case 0:
+	case 1:
+		description += " with " + required[0]
+	default:
+		description += " with " + strings.Join(required[:len(required)-1], ", ") + " and " + required[len(required)-1]
+	}
+
+	return description + ", without common words, sequences or repeats"
+}
+
 // checkCommonPatterns checks for common weak patterns
 func (pp *PasswordPolicy) checkCommonPatterns(password string) error {
 	// Sequential characters
@@ -144,10 +185,11 @@ func (pp *PasswordPolicy) checkCommonPatterns(password string) error {
 
 	// Repeated patterns (check for substrings that repeat)
 	// Go's regexp doesn't support backreferences, so we check manually
-	for patternLen := 2; patternLen <= len(password)/2; patternLen++ {
-		for i := 0; i <= len(password)-patternLen*2; i++ {
-			pattern := password[i : i+patternLen]
-			next := password[i+patternLen : i+patternLen*2]
+	runes := []rune(password)
+	for patternLen := 2; patternLen <= len(runes)/2; patternLen++ {
+		for i := 0; i <= len(runes)-patternLen*2; i++ {
+			pattern := string(runes[i : i+patternLen])
+			next := string(runes[i+patternLen : i+patternLen*2])
 			if pattern == next {
 				return fmt.Errorf("password contains repeated patterns")
 			}
@@ -206,94 +248,14 @@ func ConstantTimeCompare(a, b string) bool {
 	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
 }
 
-// PasswordStrength calculates password strength score (0-100)
-func PasswordStrength(password string) int {
-	score := 0
-
-	// Length score (max 25 points)
-	length := len(password)
-	if length >= 8 {
-		score += 10
-	}
-	if length >= 12 {
-		score += 10
-	}
-	if length >= 16 {
-		score += 5
-	}
-
-	// Character variety score (max 50 points)
-	var (
-		hasUpper   = false
-		hasLower   = false
-		hasNumber  = false
-		hasSpecial = false
-	)
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsNumber(char):
-			hasNumber = true
-		case unicode.IsPunct(char) || unicode.IsSymbol(char):
-			hasSpecial = true
-		}
-	}
-
-	if hasUpper {
-		score += 10
-	}
-	if hasLower {
-		score += 10
-	}
-	if hasNumber {
-		score += 10
-	}
-	if hasSpecial {
-		score += 10
-	}
-
-	// Complexity score (max 25 points)
-	uniqueChars := make(map[rune]bool)
-	for _, char := range password {
-		uniqueChars[char] = true
-	}
-	uniqueCount := len(uniqueChars)
-
-	if uniqueCount >= 8 {
-		score += 10
-	}
-	if uniqueCount >= 12 {
-		score += 10
-	}
-	if uniqueCount >= 16 {
-		score += 5
-	}
-
-	// Penalty for common patterns
-	if regexp.MustCompile(`(?i)(password|123456|qwerty)`).MatchString(password) {
-		score -= 20
-	}
-
-	if score < 0 {
-		score = 0
-	}
-	if score > 100 {
-		score = 100
-	}
-
-	return score
-}
-
 // AccountLockout represents account lockout information
 type AccountLockout struct {
 	Attempts    int
 	LastAttempt time.Time
 	LockedUntil *time.Time
 	IsLocked    bool
+	// Clock times attempts and lockouts; nil is the system clock
+	Clock clock.Clock
 }
 
 // NewAccountLockout creates a new account lockout tracker
@@ -309,10 +271,10 @@ func NewAccountLockout() *AccountLockout {
 // RecordFailedAttempt records a failed login attempt
 func (al *AccountLockout) RecordFailedAttempt(maxAttempts int, lockoutDuration time.Duration) {
 	al.Attempts++
-	al.LastAttempt = time.Now()
+	al.LastAttempt = clock.OrReal(al.Clock).Now()
 
 	if al.Attempts >= maxAttempts {
-		lockoutUntil := time.Now().Add(lockoutDuration)
+		lockoutUntil := al.LastAttempt.Add(lockoutDuration)
 		al.LockedUntil = &lockoutUntil
 		al.IsLocked = true
 	}
@@ -332,7 +294,7 @@ func (al *AccountLockout) IsCurrentlyLocked() bool {
 		return false
 	}
 
-	if al.LockedUntil != nil && time.Now().After(*al.LockedUntil) {
+	if al.LockedUntil != nil && clock.OrReal(al.Clock).Now().After(*al.LockedUntil) {
 		// Lockout period has expired
 		al.IsLocked = false
 		al.LockedUntil = nil
@@ -349,7 +311,7 @@ func (al *AccountLockout) GetRemainingLockoutTime() time.Duration {
 		return 0
 	}
 
-	remaining := time.Until(*al.LockedUntil)
+	remaining := al.LockedUntil.Sub(clock.OrReal(al.Clock).Now())
 	if remaining < 0 {
 		return 0
 	}
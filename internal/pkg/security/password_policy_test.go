@@ -0,0 +1,70 @@
+package security
+
+import (
+	"strings"
+	"testing"
+)
+
+// cjk returns n distinct CJK ideographs, 3 bytes each in UTF-8
+func cjk(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteRune(rune(0x4E00 + i*7))
+	}
+	return b.String()
+}
+
+// emoji returns n distinct emoji, 4 bytes each in UTF-8
+func emoji(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteRune(rune(0x1F600 + i))
+	}
+	return b.String()
+}
+
+func TestPasswordPolicyCountsCharacters(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		wantErr  string // Empty when the password is valid
+	}{
+		{"8 characters of 16 bytes", "Ab1!" + cjk(4), ""},
+		{"7 characters of 13 bytes", "Ab1!" + cjk(3), "at least 8 characters"},
+		{"emoji as special characters", "Ab1" + emoji(5), ""},
+		{"72 bytes of CJK", "Ab1!xy" + cjk(22), ""},
+		{"73 bytes of CJK", "Ab1!xyz" + cjk(22), "too long"},
+		{"72 bytes of emoji", "Ab1!" + emoji(17), ""},
+		{"76 bytes of emoji", "Ab1!" + emoji(18), "too long"},
+		{"73 ASCII characters", "Ab1!x" + strings.Repeat("xq7Z", 17), "no more than 72 characters"},
+		{"CJK has no case", "1!" + cjk(8), "uppercase"},
+		{"repeated emoji", "Ab1!" + strings.Repeat("😀", 4), "consecutive identical"},
+		{"repeated CJK pattern", "Ab1!" + cjk(2) + cjk(2), "repeated patterns"},
+	}
+
+	policy := DefaultPasswordPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.ValidatePassword(tt.password)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("ValidatePassword(%q) = %v, want valid", tt.password, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("ValidatePassword(%q) = %v, want an error containing %q", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicyFitsBcrypt(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	for _, password := range []string{"Ab1!xy" + cjk(22), "Ab1!" + emoji(17)} {
+		if err := policy.ValidatePassword(password); err != nil {
+			t.Fatalf("ValidatePassword(%q) = %v", password, err)
+		}
+		// Every password the policy accepts must hash
+		if _, err := HashPassword(password); err != nil {
+			t.Errorf("HashPassword of a %d-byte password: %v", len(password), err)
+		}
+	}
+}
@@ -0,0 +1,776 @@
+package security
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// PasswordStrengthEstimate is the result of estimating how hard a password is to guess.
+// The estimator follows the approach of zxcvbn: the password is split into the cheapest
+// sequence of guessable patterns (dictionary words, keyboard walks, sequences, repeats
+// and dates) with brute force filling the gaps, and the score buckets the guess count.
+type PasswordStrengthEstimate struct {
+	// Score ranges from 0 (too guessable) to 4 (very unguessable)
+	Score int `json:"score"`
+	// GuessesLog10 is the order of magnitude of guesses needed to crack the password
+	GuessesLog10 float64 `json:"guesses_log10"`
+	// CrackTime is a human readable estimate against an offline attack on a slow hash
+	CrackTime   string   `json:"crack_time"`
+	Warning     string   `json:"warning,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// Guess rate assumed for CrackTime: an offline attack against bcrypt-like hashing
+const offlineSlowHashGuessesPerSecond = 1e4
+
+// Gaps between patterns are costed at 10 guesses per character, as in zxcvbn
+const bruteforceCardinality = 10
+
+// Reference year and minimum year distance used to cost years and dates
+const (
+	referenceYear = 2026
+	minYearSpace  = 20
+)
+
+// commonPasswords are frequently used passwords and password fragments, most common first
+var commonPasswords = []string{
+	"password", "123456", "123456789", "12345678", "12345", "qwerty", "1234567", "111111",
+	"1234567890", "123123", "abc123", "1234", "password1", "iloveyou", "1q2w3e4r", "000000",
+	"qwerty123", "zaq12wsx", "dragon", "sunshine", "princess", "letmein", "654321", "monkey",
+	"1qaz2wsx", "123321", "qwertyuiop", "superman", "asdfghjkl", "football", "baseball",
+	"welcome", "admin", "login", "master", "hello", "freedom", "whatever", "qazwsx",
+	"trustno1", "shadow", "michael", "jennifer", "hunter", "buster", "soccer", "harley",
+	"batman", "andrew", "tigger", "charlie", "robert", "thomas", "hockey", "ranger",
+	"daniel", "starwars", "112233", "george", "computer", "michelle", "jessica",
+	"pepper", "zxcvbnm", "555555", "131313", "mustang", "access", "love",
+	"secret", "summer", "winter", "spring", "autumn", "flower", "cookie", "chocolate",
+	"cheese", "orange", "banana", "purple", "yellow", "silver", "golden", "diamond",
+	"matrix", "ninja", "pokemon", "killer", "jordan", "ginger", "maggie", "hannah",
+	"ashley", "nicole", "amanda", "samantha", "joshua", "matthew", "justin", "taylor",
+	"passw0rd", "p@ssword", "changeme", "default", "guest", "root", "test", "demo",
+	"user", "qwerty1", "abcdef", "abcd1234", "aaaaaa", "987654321", "mypassword", "letmein1",
+	"monday", "friday", "january", "october", "november", "december", "london", "paris",
+	"love123", "iloveu", "angel", "baby", "family", "forever", "happy", "lucky",
+	"money", "music", "internet", "google", "apple", "samsung", "facebook", "linkedin",
+}
+
+// commonPasswordRank maps each common password to its rank, used as its guess count
+var commonPasswordRank = rankedDictionary(commonPasswords)
+
+// maxDictionaryWordLength bounds the substrings looked up in the dictionary
+var maxDictionaryWordLength = longestWord(commonPasswords)
+
+// l33tSubstitutions maps l33t characters to the letters they commonly stand for
+var l33tSubstitutions = map[rune][]rune{
+	'4': {'a'}, '@': {'a'}, '8': {'b'}, '(': {'c'}, '{': {'c'}, '[': {'c'}, '<': {'c'},
+	'3': {'e'}, '6': {'g'}, '9': {'g'}, '1': {'i', 'l'}, '!': {'i'}, '|': {'i', 'l'},
+	'0': {'o'}, '$': {'s'}, '5': {'s'}, '+': {'t'}, '7': {'t'}, '%': {'x'}, '2': {'z'},
+}
+
+// qwertyRows and qwertyShiftedRows describe a US keyboard, used to detect keyboard walks
+var (
+	qwertyRows        = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+	qwertyShiftedRows = []string{"~!@#$%^&*()_+", "QWERTYUIOP{}|", "ASDFGHJKL:\"", "ZXCVBNM<>?"}
+)
+
+// keyboardGraph holds the neighbouring keys of every key, with shifted keys mapped to their base key
+var keyboardGraph, keyboardShift = buildKeyboardGraph()
+
+// dateSeparators are the characters accepted between the parts of a date
+var dateSeparators = "/\\-._ "
+
+// passwordMatch is a guessable pattern found at runes [i, j] of the password
+type passwordMatch struct {
+	pattern string
+	i, j    int
+	guesses float64
+	// rank is the dictionary rank, and userInput marks words taken from the user's own details
+	rank      int
+	userInput bool
+	reversed  bool
+	l33t      bool
+	// turns is the number of direction changes of a keyboard walk
+	turns int
+}
+
+// EstimatePasswordStrength estimates how guessable password is. userInputs are words
+// an attacker would try first for this user, such as their username and email address.
+func EstimatePasswordStrength(password string, userInputs ...string) *PasswordStrengthEstimate {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return &PasswordStrengthEstimate{
+			CrackTime:   crackTimeDisplay(0),
+			Warning:     "A password is required",
+			Suggestions: []string{"Use a few words, avoid common phrases"},
+		}
+	}
+
+	matches := dictionaryMatches(runes, userDictionary(userInputs))
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, repeatMatches(runes)...)
+	matches = append(matches, dateMatches(runes)...)
+
+	guessesLog10, sequence := cheapestSequence(len(runes), matches)
+	estimate := &PasswordStrengthEstimate{
+		Score:        scoreForGuesses(guessesLog10),
+		GuessesLog10: math.Round(guessesLog10*100) / 100,
+		CrackTime:    crackTimeDisplay(guessesLog10),
+	}
+	if estimate.Score <= 2 {
+		estimate.Warning, estimate.Suggestions = feedback(runes, sequence)
+	}
+
+	return estimate
+}
+
+// cheapestSequence finds the sequence of non-overlapping matches, with brute force in
+// between, that needs the fewest guesses. It returns log10 of the guesses and the matches used.
+func cheapestSequence(n int, matches []passwordMatch) (float64, []passwordMatch) {
+	byEnd := make([][]passwordMatch, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	bruteforceLog10 := math.Log10(bruteforceCardinality)
+	best := make([]float64, n+1)
+	via := make([]*passwordMatch, n+1)
+	for k := 1; k <= n; k++ {
+		best[k] = best[k-1] + bruteforceLog10
+		via[k] = nil
+		for idx := range byEnd[k-1] {
+			m := &byEnd[k-1][idx]
+			if cost := best[m.i] + math.Log10(m.guesses); cost < best[k] {
+				best[k] = cost
+				via[k] = m
+			}
+		}
+	}
+
+	var sequence []passwordMatch
+	for k := n; k > 0; {
+		if via[k] == nil {
+			k--
+			continue
+		}
+		sequence = append(sequence, *via[k])
+		k = via[k].i
+	}
+	// Combining several patterns costs the attacker a little extra
+	guessesLog10 := best[n] + math.Log10(factorial(len(sequence)))
+
+	return guessesLog10, sequence
+}
+
+// scoreForGuesses buckets a guess count into a 0-4 score using zxcvbn's thresholds
+func scoreForGuesses(guessesLog10 float64) int {
+	switch {
+	case guessesLog10 < 3:
+		return 0
+	case guessesLog10 < 6:
+		return 1
+	case guessesLog10 < 8:
+		return 2
+	case guessesLog10 < 10:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// crackTimeDisplay describes how long an offline attack on a slow hash would take
+func crackTimeDisplay(guessesLog10 float64) string {
+	seconds := math.Pow(10, guessesLog10) / offlineSlowHashGuessesPerSecond
+	units := []struct {
+		name    string
+		seconds float64
+	}{
+		{"year", 365 * 24 * 3600},
+		{"month", 31 * 24 * 3600},
+		{"day", 24 * 3600},
+		{"hour", 3600},
+		{"minute", 60},
+		{"second", 1},
+	}
+
+	if seconds < 1 {
+		return "less than a second"
+	}
+	if seconds >= 100*units[0].seconds {
+		return "centuries"
+	}
+	for _, unit := range units {
+		if seconds >= unit.seconds {
+			count := int(math.Round(seconds / unit.seconds))
+			if count == 1 {
+				return "1 " + unit.name
+			}
+			return strconv.Itoa(count) + " " + unit.name + "s"
+		}
+	}
+	return "less than a second"
+}
+
+// feedback explains the weakest part of the password, based on its longest match
+func feedback(runes []rune, sequence []passwordMatch) (string, []string) {
+	suggestions := []string{"Add another word or two. Uncommon words are better."}
+	if len(sequence) == 0 {
+		if len(runes) < 8 {
+			return "Short passwords are easy to guess", append(suggestions, "Use a longer password")
+		}
+		return "", suggestions
+	}
+
+	longest := sequence[0]
+	for _, m := range sequence[1:] {
+		if m.j-m.i > longest.j-longest.i {
+			longest = m
+		}
+	}
+
+	switch longest.pattern {
+	case "dictionary":
+		warning := "This is similar to a commonly used password"
+		switch {
+		case longest.userInput:
+			warning = "Passwords containing your name or email address are easy to guess"
+		case longest.i == 0 && longest.j == len(runes)-1 && longest.rank <= 10:
+			warning = "This is a top-10 common password"
+		case longest.i == 0 && longest.j == len(runes)-1 && longest.rank <= 100:
+			warning = "This is a very common password"
+		}
+		word := string(runes[longest.i : longest.j+1])
+		if startsUpper(word) {
+			suggestions = append(suggestions, "Capitalization doesn't help very much")
+		}
+		if longest.reversed {
+			suggestions = append(suggestions, "Reversed words aren't much harder to guess")
+		}
+		if longest.l33t {
+			suggestions = append(suggestions, "Predictable substitutions like '@' instead of 'a' don't help very much")
+		}
+		return warning, suggestions
+	case "keyboard":
+		if longest.turns == 0 {
+			return "Straight rows of keys are easy to guess", append(suggestions, "Use a longer keyboard pattern with more turns")
+		}
+		return "Short keyboard patterns are easy to guess", append(suggestions, "Use a longer keyboard pattern with more turns")
+	case "repeat":
+		return "Repeated characters and words are easy to guess", append(suggestions, "Avoid repeated words and characters")
+	case "sequence":
+		return "Sequences like abc or 6543 are easy to guess", append(suggestions, "Avoid sequences")
+	case "date":
+		return "Dates are often easy to guess", append(suggestions, "Avoid dates and years that are associated with you")
+	}
+
+	return "", suggestions
+}
+
+// dictionaryMatches finds common passwords and user inputs in the password, including
+// capitalised, reversed and l33t-substituted forms
+func dictionaryMatches(runes []rune, user map[string]int) []passwordMatch {
+	lower := []rune(strings.ToLower(string(runes)))
+	maxLen := maxDictionaryWordLength
+	for word := range user {
+		if n := len([]rune(word)); n > maxLen {
+			maxLen = n
+		}
+	}
+
+	var matches []passwordMatch
+	for i := 0; i < len(lower); i++ {
+		for j := i + 2; j < len(lower) && j-i < maxLen; j++ {
+			original := runes[i : j+1]
+			candidate := lower[i : j+1]
+			for _, reversed := range []bool{false, true} {
+				word := candidate
+				if reversed {
+					word = reverseRunes(candidate)
+				}
+				for _, variant := range l33tVariants(word) {
+					rank, userInput := lookupWord(variant, user)
+					if rank == 0 {
+						continue
+					}
+					substituted := string(variant) != string(word)
+					guesses := float64(rank) * uppercaseVariations(original)
+					if substituted {
+						guesses *= l33tVariations(word)
+					}
+					if reversed {
+						guesses *= 2
+					}
+					matches = append(matches, passwordMatch{
+						pattern: "dictionary", i: i, j: j, guesses: guesses,
+						rank: rank, userInput: userInput, reversed: reversed, l33t: substituted,
+					})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// lookupWord returns the rank of word in the user inputs or the common passwords
+func lookupWord(word []rune, user map[string]int) (int, bool) {
+	key := string(word)
+	if rank, ok := user[key]; ok {
+		return rank, true
+	}
+	return commonPasswordRank[key], false
+}
+
+// keyboardMatches finds runs of at least three adjacent keys on a QWERTY keyboard
+func keyboardMatches(runes []rune) []passwordMatch {
+	var matches []passwordMatch
+	i := 0
+	for i < len(runes)-1 {
+		j := i
+		turns := 0
+		lastDirection := -1
+		for j+1 < len(runes) {
+			direction := keyDirection(runes[j], runes[j+1])
+			if direction < 0 {
+				break
+			}
+			if lastDirection >= 0 && direction != lastDirection {
+				turns++
+			}
+			lastDirection = direction
+			j++
+		}
+		if j-i >= 2 {
+			matches = append(matches, passwordMatch{
+				pattern: "keyboard", i: i, j: j, turns: turns,
+				guesses: keyboardGuesses(runes[i:j+1], turns),
+			})
+		}
+		if j == i {
+			i++
+		} else {
+			i = j
+		}
+	}
+
+	return matches
+}
+
+// keyDirection returns the direction from key a to a neighbouring key b, or -1 if they are not adjacent
+func keyDirection(a, b rune) int {
+	neighbours, ok := keyboardGraph[baseKey(a)]
+	if !ok {
+		return -1
+	}
+	for direction, neighbour := range neighbours {
+		if neighbour != 0 && neighbour == baseKey(b) {
+			return direction
+		}
+	}
+	return -1
+}
+
+// keyboardGuesses estimates the guesses for a keyboard walk, as in zxcvbn's spatial matcher
+func keyboardGuesses(walk []rune, turns int) float64 {
+	startingPositions := float64(len(keyboardGraph))
+	averageDegree := 4.6
+	length := len(walk)
+
+	guesses := 0.0
+	for l := 2; l <= length; l++ {
+		for t := 1; t <= min(turns+1, l-1); t++ {
+			guesses += binomial(l-1, t-1) * startingPositions * math.Pow(averageDegree, float64(t))
+		}
+	}
+
+	shifted := 0
+	for _, r := range walk {
+		if keyboardShift[r] {
+			shifted++
+		}
+	}
+	if shifted > 0 {
+		unshifted := length - shifted
+		if unshifted == 0 {
+			guesses *= 2
+		} else {
+			variations := 0.0
+			for k := 1; k <= min(shifted, unshifted); k++ {
+				variations += binomial(length, k)
+			}
+			guesses *= variations
+		}
+	}
+
+	return guesses
+}
+
+// sequenceMatches finds runs of at least three characters with a constant step of one, like "abc" or "9876"
+func sequenceMatches(runes []rune) []passwordMatch {
+	var matches []passwordMatch
+	i := 0
+	for i < len(runes)-2 {
+		delta := runes[i+1] - runes[i]
+		if delta != 1 && delta != -1 {
+			i++
+			continue
+		}
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+		if j-i >= 2 {
+			guesses := sequenceBase(runes[i]) * float64(j-i+1)
+			if delta < 0 {
+				guesses *= 2
+			}
+			matches = append(matches, passwordMatch{pattern: "sequence", i: i, j: j, guesses: guesses})
+		}
+		i = j
+	}
+
+	return matches
+}
+
+// sequenceBase is the guess cost of the first character of a sequence
+func sequenceBase(first rune) float64 {
+	switch {
+	case strings.ContainsRune("aAzZ019", first):
+		return 4
+	case unicode.IsDigit(first):
+		return 10
+	case unicode.IsLetter(first):
+		return 26
+	default:
+		return 26 * 2
+	}
+}
+
+// repeatMatches finds a character or substring repeated at least twice in a row, like "aaa" or "abcabc"
+func repeatMatches(runes []rune) []passwordMatch {
+	var matches []passwordMatch
+	for i := 0; i < len(runes); i++ {
+		for unit := 1; unit <= (len(runes)-i)/2; unit++ {
+			count := 1
+			for i+(count+1)*unit <= len(runes) && string(runes[i+count*unit:i+(count+1)*unit]) == string(runes[i:i+unit]) {
+				count++
+			}
+			minCount := 2
+			if unit == 1 {
+				minCount = 3
+			}
+			if count < minCount {
+				continue
+			}
+			baseGuesses := math.Pow(bruteforceCardinality, float64(unit))
+			matches = append(matches, passwordMatch{
+				pattern: "repeat", i: i, j: i + count*unit - 1,
+				guesses: baseGuesses * float64(count),
+			})
+		}
+	}
+
+	return matches
+}
+
+// dateMatches finds years and day/month/year dates, written with or without separators
+func dateMatches(runes []rune) []passwordMatch {
+	var matches []passwordMatch
+	for i := 0; i < len(runes); i++ {
+		for j := i + 3; j < len(runes) && j-i < 10; j++ {
+			token := string(runes[i : j+1])
+			if year, ok := parseYear(token); ok {
+				matches = append(matches, passwordMatch{pattern: "date", i: i, j: j, guesses: yearSpace(year)})
+				continue
+			}
+			year, separator, ok := parseDate(token)
+			if !ok {
+				continue
+			}
+			guesses := yearSpace(year) * 365
+			if separator {
+				guesses *= 4
+			}
+			matches = append(matches, passwordMatch{pattern: "date", i: i, j: j, guesses: guesses})
+		}
+	}
+
+	return matches
+}
+
+// parseYear reports whether token is a four digit year between 1900 and 2049
+func parseYear(token string) (int, bool) {
+	if len(token) != 4 || !isDigits(token) {
+		return 0, false
+	}
+	year, _ := strconv.Atoi(token)
+	return year, year >= 1900 && year <= 2049
+}
+
+// parseDate reports whether token is a date in day/month/year, month/day/year or year/month/day
+// order, returning its year and whether the parts were separated
+func parseDate(token string) (int, bool, bool) {
+	var parts [][3]string
+	if isDigits(token) {
+		if len(token) < 4 || len(token) > 8 {
+			return 0, false, false
+		}
+		// Try every split of the digits into three parts
+		for a := 1; a < len(token)-1; a++ {
+			for b := a + 1; b < len(token); b++ {
+				parts = append(parts, [3]string{token[:a], token[a:b], token[b:]})
+			}
+		}
+	} else {
+		fields := strings.FieldsFunc(token, func(r rune) bool { return strings.ContainsRune(dateSeparators, r) })
+		if len(fields) != 3 || strings.Count(token, string(token[len(fields[0])])) != 2 {
+			return 0, false, false
+		}
+		parts = append(parts, [3]string{fields[0], fields[1], fields[2]})
+	}
+
+	for _, p := range parts {
+		if !isDigits(p[0]) || !isDigits(p[1]) || !isDigits(p[2]) {
+			continue
+		}
+		if year, ok := dayMonthYear(p); ok {
+			return year, !isDigits(token), true
+		}
+	}
+	return 0, false, false
+}
+
+// dayMonthYear interprets three numeric parts as a date in any common order and returns the year
+func dayMonthYear(p [3]string) (int, bool) {
+	n := [3]int{}
+	for k := range p {
+		if len(p[k]) > 4 || len(p[k]) == 3 {
+			return 0, false
+		}
+		n[k], _ = strconv.Atoi(p[k])
+	}
+	orders := [][3]int{{0, 1, 2}, {1, 0, 2}, {2, 1, 0}}
+	for _, o := range orders {
+		day, month, yearPart := n[o[0]], n[o[1]], n[o[2]]
+		if len(p[o[0]]) > 2 || len(p[o[1]]) > 2 || day < 1 || day > 31 || month < 1 || month > 12 {
+			continue
+		}
+		year := yearPart
+		if len(p[o[2]]) <= 2 {
+			// Two digit years map to 1950-2049
+			if year > 49 {
+				year += 1900
+			} else {
+				year += 2000
+			}
+		}
+		if year >= 1900 && year <= 2049 {
+			return year, true
+		}
+	}
+	return 0, false
+}
+
+// yearSpace is the number of years an attacker would try before reaching year
+func yearSpace(year int) float64 {
+	space := year - referenceYear
+	if space < 0 {
+		space = -space
+	}
+	return float64(max(space, minYearSpace))
+}
+
+// uppercaseVariations is the factor capitalisation adds to a dictionary word
+func uppercaseVariations(word []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range word {
+		if unicode.IsUpper(r) {
+			upper++
+		} else if unicode.IsLower(r) {
+			lower++
+		}
+	}
+	s := string(word)
+	switch {
+	case upper == 0:
+		return 1
+	case lower == 0, startsUpper(s) && upper == 1, unicode.IsUpper(word[len(word)-1]) && upper == 1:
+		// All caps, or only the first or last letter capitalised
+		return 2
+	}
+	variations := 0.0
+	for k := 1; k <= min(upper, lower); k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return variations
+}
+
+// l33tVariations is the factor l33t substitutions add to a dictionary word
+func l33tVariations(word []rune) float64 {
+	substituted := 0
+	for _, r := range word {
+		if _, ok := l33tSubstitutions[r]; ok {
+			substituted++
+		}
+	}
+	unsubstituted := len(word) - substituted
+	if unsubstituted == 0 {
+		return 2
+	}
+	variations := 0.0
+	for k := 1; k <= min(substituted, unsubstituted); k++ {
+		variations += binomial(len(word), k)
+	}
+	return math.Max(variations, 2)
+}
+
+// l33tVariants returns word and every decoding of its l33t characters, capped to keep lookups cheap
+func l33tVariants(word []rune) [][]rune {
+	const maxVariants = 8
+	variants := [][]rune{append([]rune(nil), word...)}
+	for pos, r := range word {
+		letters, ok := l33tSubstitutions[r]
+		if !ok {
+			continue
+		}
+		// Undecoded forms are kept too, since digits may be meant literally (e.g. "password1")
+		var decoded [][]rune
+		for _, variant := range variants {
+			for _, letter := range letters {
+				d := append([]rune(nil), variant...)
+				d[pos] = letter
+				decoded = append(decoded, d)
+			}
+		}
+		variants = append(variants, decoded...)
+		if len(variants) > maxVariants {
+			variants = variants[:maxVariants]
+		}
+	}
+	return variants
+}
+
+// userDictionary ranks the user inputs, splitting email addresses into their local part and domain
+func userDictionary(inputs []string) map[string]int {
+	dictionary := make(map[string]int)
+	rank := 1
+	add := func(word string) {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if len([]rune(word)) < 3 {
+			return
+		}
+		if _, ok := dictionary[word]; !ok {
+			dictionary[word] = rank
+			rank++
+		}
+	}
+	for _, input := range inputs {
+		add(input)
+		for _, part := range strings.FieldsFunc(input, func(r rune) bool { return r == '@' || r == '.' || r == '_' || r == '-' || r == '+' }) {
+			add(part)
+		}
+	}
+	return dictionary
+}
+
+// buildKeyboardGraph maps every base key to its neighbours in six directions
+// (left, right, up-left, up-right, down-left, down-right) on a staggered keyboard
+func buildKeyboardGraph() (map[rune][6]rune, map[rune]bool) {
+	graph := make(map[rune][6]rune)
+	shifted := make(map[rune]bool)
+	at := func(row, col int) rune {
+		if row < 0 || row >= len(qwertyRows) || col < 0 || col >= len(qwertyRows[row]) {
+			return 0
+		}
+		return rune(qwertyRows[row][col])
+	}
+	for row, keys := range qwertyRows {
+		for col, key := range keys {
+			graph[key] = [6]rune{
+				at(row, col-1), at(row, col+1),
+				at(row-1, col), at(row-1, col+1),
+				at(row+1, col-1), at(row+1, col),
+			}
+			shifted[rune(qwertyShiftedRows[row][col])] = true
+		}
+	}
+	return graph, shifted
+}
+
+// baseKey maps a shifted or upper case key to the key it is typed with
+func baseKey(r rune) rune {
+	for row, keys := range qwertyShiftedRows {
+		if idx := strings.IndexRune(keys, r); idx >= 0 {
+			return rune(qwertyRows[row][idx])
+		}
+	}
+	return r
+}
+
+// rankedDictionary maps each word to its 1-based rank
+func rankedDictionary(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for idx, word := range words {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = idx + 1
+		}
+	}
+	return ranks
+}
+
+// longestWord returns the length in runes of the longest word
+func longestWord(words []string) int {
+	longest := 0
+	for _, word := range words {
+		longest = max(longest, len([]rune(word)))
+	}
+	return longest
+}
+
+func reverseRunes(runes []rune) []rune {
+	reversed := make([]rune, len(runes))
+	for idx, r := range runes {
+		reversed[len(runes)-1-idx] = r
+	}
+	return reversed
+}
+
+func startsUpper(s string) bool {
+	for _, r := range s {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func binomial(n, k int) float64 {
+	if k < 0 || k > n {
+		return 0
+	}
+	result := 1.0
+	for i := 1; i <= k; i++ {
+		result = result * float64(n-k+i) / float64(i)
+	}
+	return result
+}
+
+func factorial(n int) float64 {
+	result := 1.0
+	for i := 2; i <= n; i++ {
+		result *= float64(i)
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package security
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// IdentifierAllowlist maps client-facing names, such as sort keys, to the SQL identifiers they stand for.
+// Query values are always passed as bind parameters, so user content is never screened for SQL
+// keywords; identifiers are the only input that can reach query text and must be resolved here.
+type IdentifierAllowlist map[string]string
+
+// Resolve returns the SQL identifier for name, or an error if name is not allowlisted
+func (a IdentifierAllowlist) Resolve(name string) (string, error) {
+	identifier, ok := a[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return "", fmt.Errorf("unknown identifier %q", name)
+	}
+	return identifier, nil
+}
+
+// ResolveOrDefault returns the SQL identifier for name, or fallback if name is not allowlisted
+func (a IdentifierAllowlist) ResolveOrDefault(name, fallback string) string {
+	if identifier, err := a.Resolve(name); err == nil {
+		return identifier
+	}
+	return fallback
+}
+
+// OrderBy builds an ORDER BY clause for name, falling back to fallback if name is not allowlisted
+func (a IdentifierAllowlist) OrderBy(name, fallback string, descending bool) string {
+	direction := "ASC"
+	if descending {
+		direction = "DESC"
+	}
+	return "ORDER BY " + a.ResolveOrDefault(name, fallback) + " " + direction
+}
+
+// RegisterQueryField registers a validation tag for request fields that are used to build
+// dynamic queries. A field with the tag is valid only if it is empty or allowlisted;
+// no other field is inspected. No request uses such a field yet.
+func (iv *InputValidator) RegisterQueryField(tag string, allowlist IdentifierAllowlist) error {
+	return iv.validator.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		if value == "" {
+			return true
+		}
+		_, err := allowlist.Resolve(value)
+		return err == nil
+	})
+}
@@ -1,119 +1,257 @@
 package security
 
 import (
-	"fmt"
-	"net/http"
+	"container/list"
+	"hash/fnv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"go-backend-api/internal/pkg/errors"
 	"go-backend-api/internal/pkg/response"
 
 	"github.com/gin-gonic/gin"
 )
 
-// RateLimiter implements token bucket rate limiting
+// DefaultRateLimitMaxEntries caps how many buckets a rate limiter keeps when none is configured
+const DefaultRateLimitMaxEntries = 100000
+
+// rateLimiterShards is how many independently locked maps the buckets are spread over
+const rateLimiterShards = 32
+
+// rateLimitIdleTTL is how long an unused bucket is kept before cleanup drops it
+const rateLimitIdleTTL = 10 * time.Minute
+
+// RateLimiter implements token bucket rate limiting.
+// Buckets are spread over shards to reduce lock contention, and the number of buckets
+// is capped: once a shard is full its least recently used bucket is evicted, so a flood
+// of spoofed client IPs cannot grow memory without bound.
 type RateLimiter struct {
-	requests map[string]*TokenBucket
-	mutex    sync.RWMutex
-	rate     int           // requests per minute
-	capacity int           // burst capacity
-	cleanup  time.Duration // cleanup interval
+	shards      [rateLimiterShards]rateLimitShard
+	rate        int           // tokens added per window
+	window      time.Duration // refill window
+	capacity    int           // burst capacity
+	maxPerShard int
+
+	allowed  atomic.Uint64
+	rejected atomic.Uint64
+	evicted  atomic.Uint64
+}
+
+// rateLimitShard holds a subset of the buckets, ordered from most to least recently used
+type rateLimitShard struct {
+	mutex   sync.Mutex
+	buckets map[string]*list.Element
+	lru     *list.List
 }
 
 // TokenBucket represents a token bucket for rate limiting
 type TokenBucket struct {
+	key        string
 	tokens     int
 	lastRefill time.Time
-	rate       int
-	capacity   int
+	lastSeen   time.Time
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(rate, capacity int) *RateLimiter {
-	rl := &RateLimiter{
-		requests: make(map[string]*TokenBucket),
-		rate:     rate,
-		capacity: capacity,
-		cleanup:  time.Minute * 5,
+// RateLimiterStats is a snapshot of RateLimiter activity
+type RateLimiterStats struct {
+	Rate       int     `json:"rate"`
+	WindowSecs float64 `json:"window_seconds"`
+	Capacity   int     `json:"capacity"`
+	Buckets    int     `json:"buckets"`
+	MaxEntries int     `json:"max_entries"`
+	Shards     int     `json:"shards"`
+	Allowed    uint64  `json:"allowed"`
+	Rejected   uint64  `json:"rejected"`
+	Evicted    uint64  `json:"evicted"`
+}
+
+// NewRateLimiter creates a rate limiter allowing rate requests per window with bursts of up
+// to capacity, keeping at most maxEntries buckets (DefaultRateLimitMaxEntries when not positive)
+func NewRateLimiter(rate int, window time.Duration, capacity, maxEntries int) *RateLimiter {
+	if rate < 1 {
+		rate = 1
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	if capacity < 1 {
+		capacity = rate
+	}
+	if maxEntries < 1 {
+		maxEntries = DefaultRateLimitMaxEntries
 	}
 
-	// Start cleanup goroutine
-	go rl.cleanupExpiredBuckets()
+	rl := &RateLimiter{
+		rate:        rate,
+		window:      window,
+		capacity:    capacity,
+		maxPerShard: max(1, (maxEntries+rateLimiterShards-1)/rateLimiterShards),
+	}
+	for i := range rl.shards {
+		rl.shards[i].buckets = make(map[string]*list.Element)
+		rl.shards[i].lru = list.New()
+	}
 
 	return rl
 }
 
 // Allow checks if a request is allowed for the given key
 func (rl *RateLimiter) Allow(key string) bool {
-	rl.mutex.Lock()
-	defer rl.mutex.Unlock()
+	ok, _ := rl.take(key)
+	return ok
+}
 
-	now := time.Now()
-	bucket, exists := rl.requests[key]
+// take consumes a token for key, returning whether one was available and, if not,
+// how long until the next token is added
+func (rl *RateLimiter) take(key string) (bool, time.Duration) {
+	shard := rl.shard(key)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
 
+	now := time.Now()
+	elem, exists := shard.buckets[key]
 	if !exists {
-		bucket = &TokenBucket{
+		if shard.lru.Len() >= rl.maxPerShard {
+			oldest := shard.lru.Back()
+			shard.lru.Remove(oldest)
+			delete(shard.buckets, oldest.Value.(*TokenBucket).key)
+			rl.evicted.Add(1)
+		}
+
+		shard.buckets[key] = shard.lru.PushFront(&TokenBucket{
+			key:        key,
 			tokens:     rl.capacity - 1,
 			lastRefill: now,
-			rate:       rl.rate,
-			capacity:   rl.capacity,
-		}
-		rl.requests[key] = bucket
-		return true
+			lastSeen:   now,
+		})
+		rl.allowed.Add(1)
+		return true, 0
 	}
 
-	// Refill tokens based on time elapsed
-	elapsed := now.Sub(bucket.lastRefill)
-	tokensToAdd := int(elapsed.Minutes()) * bucket.rate
+	shard.lru.MoveToFront(elem)
+	bucket := elem.Value.(*TokenBucket)
+	bucket.lastSeen = now
 
-	if tokensToAdd > 0 {
-		bucket.tokens = min(bucket.capacity, bucket.tokens+tokensToAdd)
-		bucket.lastRefill = now
+	// Refill whole tokens for the time elapsed, carrying the remainder over to the next call
+	interval := rl.window / time.Duration(rl.rate)
+	if tokensToAdd := int(now.Sub(bucket.lastRefill) / interval); tokensToAdd > 0 {
+		bucket.tokens += tokensToAdd
+		bucket.lastRefill = bucket.lastRefill.Add(time.Duration(tokensToAdd) * interval)
+		if bucket.tokens >= rl.capacity {
+			bucket.tokens = rl.capacity
+			bucket.lastRefill = now
+		}
 	}
 
 	// Check if tokens available
 	if bucket.tokens > 0 {
 		bucket.tokens--
-		return true
+		rl.allowed.Add(1)
+		return true, 0
 	}
 
-	return false
+	rl.rejected.Add(1)
+	return false, bucket.lastRefill.Add(interval).Sub(now)
+}
+
+// shard returns the shard holding key
+func (rl *RateLimiter) shard(key string) *rateLimitShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &rl.shards[h.Sum32()%rateLimiterShards]
 }
 
-// cleanupExpiredBuckets removes expired token buckets
-func (rl *RateLimiter) cleanupExpiredBuckets() {
-	ticker := time.NewTicker(rl.cleanup)
-	defer ticker.Stop()
+// StartCleanup periodically drops buckets unused for a while until stop is closed
+func (rl *RateLimiter) StartCleanup(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				rl.cleanupIdleBuckets(time.Now().Add(-rateLimitIdleTTL))
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
 
-	for range ticker.C {
-		rl.mutex.Lock()
-		now := time.Now()
-		for key, bucket := range rl.requests {
-			// Remove buckets that haven't been used for 10 minutes
-			if now.Sub(bucket.lastRefill) > time.Minute*10 {
-				delete(rl.requests, key)
+// cleanupIdleBuckets removes buckets not used since cutoff
+func (rl *RateLimiter) cleanupIdleBuckets(cutoff time.Time) {
+	for i := range rl.shards {
+		shard := &rl.shards[i]
+		shard.mutex.Lock()
+		// Buckets are in recency order, so stop at the first one still in use
+		for elem := shard.lru.Back(); elem != nil; elem = shard.lru.Back() {
+			bucket := elem.Value.(*TokenBucket)
+			if bucket.lastSeen.After(cutoff) {
+				break
 			}
+			shard.lru.Remove(elem)
+			delete(shard.buckets, bucket.key)
 		}
-		rl.mutex.Unlock()
+		shard.mutex.Unlock()
 	}
 }
 
-// RateLimitMiddleware creates a rate limiting middleware
-func RateLimitMiddleware(rate, capacity int) gin.HandlerFunc {
-	limiter := NewRateLimiter(rate, capacity)
+// Stats returns a snapshot of the limiter's bucket count and decisions
+func (rl *RateLimiter) Stats() RateLimiterStats {
+	buckets := 0
+	for i := range rl.shards {
+		shard := &rl.shards[i]
+		shard.mutex.Lock()
+		buckets += shard.lru.Len()
+		shard.mutex.Unlock()
+	}
+
+	return RateLimiterStats{
+		Rate:       rl.rate,
+		WindowSecs: rl.window.Seconds(),
+		Capacity:   rl.capacity,
+		Buckets:    buckets,
+		MaxEntries: rl.maxPerShard * rateLimiterShards,
+		Shards:     rateLimiterShards,
+		Allowed:    rl.allowed.Load(),
+		Rejected:   rl.rejected.Load(),
+		Evicted:    rl.evicted.Load(),
+	}
+}
+
+// Throttle scopes of the rate limiters, telling clients of a 429 which limit they ran into
+const (
+	ThrottleScopeRoute           = "route"            // Requests per client IP and route
+	ThrottleScopeSandbox         = "sandbox"          // Requests per sandbox account
+	ThrottleScopeSandboxSessions = "sandbox_sessions" // Sandbox accounts created per client IP
+	ThrottleScopePlan            = "plan"             // Requests per user, as their plan allows
+)
 
+// Middleware limits requests per client IP and route. The route template is used rather
+// than the raw path so that IDs in URLs do not create a bucket per resource. Rejected
+// requests get 429 with the throttle of scope.
+func (rl *RateLimiter) Middleware(scope string) gin.HandlerFunc {
+	return rl.MiddlewareBy(scope, func(c *gin.Context) string {
+		return c.ClientIP() + ":" + c.FullPath()
+	})
+}
+
+// MiddlewareBy limits requests per key returned by key; requests for which it returns ""
+// are not limited
+func (rl *RateLimiter) MiddlewareBy(scope string, key func(c *gin.Context) string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get client IP
-		clientIP := c.ClientIP()
-		key := fmt.Sprintf("%s:%s", clientIP, c.Request.URL.Path)
-
-		if !limiter.Allow(key) {
-			c.JSON(http.StatusTooManyRequests, response.Response{
-				Success: false,
-				Error: &response.ErrorInfo{
-					Code:    http.StatusTooManyRequests,
-					Message: "Rate limit exceeded. Please try again later.",
-				},
+		key := key(c)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if ok, retryAfter := rl.take(key); !ok {
+			response.Throttled(c, errors.ErrRateLimited, errors.Throttle{
+				Scope: scope,
+				Limit: rl.capacity,
+				Reset: retryAfter,
 			})
 			c.Abort()
 			return
@@ -123,6 +261,12 @@ func RateLimitMiddleware(rate, capacity int) gin.HandlerFunc {
 	}
 }
 
+// RateLimitMiddleware creates a rate limiting middleware allowing rate requests per minute.
+// Without StartCleanup idle buckets are only dropped once the entry cap is reached.
+func RateLimitMiddleware(rate, capacity int) gin.HandlerFunc {
+	return NewRateLimiter(rate, time.Minute, capacity, DefaultRateLimitMaxEntries).Middleware(ThrottleScopeRoute)
+}
+
 // AuthRateLimitMiddleware creates a rate limiting middleware for auth endpoints
 func AuthRateLimitMiddleware() gin.HandlerFunc {
 	// Stricter rate limiting for auth endpoints
@@ -134,11 +278,3 @@ func APIRateLimitMiddleware() gin.HandlerFunc {
 	// More lenient rate limiting for API endpoints
 	return RateLimitMiddleware(100, 200) // 100 requests per minute, burst of 200
 }
-
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
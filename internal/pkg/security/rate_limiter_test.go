@@ -0,0 +1,80 @@
+package security
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsBurstsPerKey(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour, 3, 0)
+	for i := range 5 {
+		if got, want := rl.Allow("alice"), i < 3; got != want {
+			t.Errorf("request %d of alice: got allowed %v, want %v", i+1, got, want)
+		}
+	}
+	if !rl.Allow("bob") {
+		t.Error("bob was limited by alice's requests")
+	}
+
+	stats := rl.Stats()
+	if stats.Allowed != 4 || stats.Rejected != 2 || stats.Buckets != 2 {
+		t.Errorf("got stats %+v, want 4 allowed, 2 rejected in 2 buckets", stats)
+	}
+}
+
+func TestRateLimiterCapsItsBuckets(t *testing.T) {
+	// One bucket per shard
+	rl := NewRateLimiter(1, time.Hour, 1, rateLimiterShards)
+	const keys = 10 * rateLimiterShards
+	for i := range keys {
+		rl.Allow("spoofed-" + strconv.Itoa(i))
+	}
+	stats := rl.Stats()
+	if stats.Buckets > stats.MaxEntries || stats.MaxEntries != rateLimiterShards {
+		t.Errorf("got %d buckets with a cap of %d, want at most %d", stats.Buckets, stats.MaxEntries, rateLimiterShards)
+	}
+	if stats.Evicted < keys-rateLimiterShards {
+		t.Errorf("got %d evictions, want at least %d", stats.Evicted, keys-rateLimiterShards)
+	}
+}
+
+func TestRateLimiterCleanupDropsIdleBuckets(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour, 1, 0)
+	rl.Allow("idle")
+	cutoff := time.Now()
+	time.Sleep(time.Millisecond)
+	rl.Allow("active")
+
+	rl.cleanupIdleBuckets(cutoff)
+	if buckets := rl.Stats().Buckets; buckets != 1 {
+		t.Fatalf("got %d buckets after cleanup, want only the active one", buckets)
+	}
+	// The idle key starts over with a full bucket, the active one is still limited
+	if !rl.Allow("idle") || rl.Allow("active") {
+		t.Error("cleanup dropped the wrong bucket")
+	}
+}
+
+func TestRateLimiterCountsConcurrentRequestsExactly(t *testing.T) {
+	rl := NewRateLimiter(1, time.Hour, 10, 0)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 100 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.Allow("shared") {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if allowed != 10 {
+		t.Errorf("allowed %d concurrent requests, want the burst of 10", allowed)
+	}
+}
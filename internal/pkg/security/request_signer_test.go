@@ -0,0 +1,154 @@
+package security
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// usedSignatures records used signatures in memory, like the used_signatures table
+type usedSignatures struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+}
+
+func (u *usedSignatures) Use(signature string, expiresAt time.Time) (bool, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if _, ok := u.used[signature]; ok {
+		return false, nil
+	}
+	u.used[signature] = expiresAt
+	return true, nil
+}
+
+func (u *usedSignatures) DeleteExpired(now time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestVerifySignature(t *testing.T) {
+	const path = "/api/v1/admin/users/1?hard=true"
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"confirm":true}`)
+	signer := NewRequestSigner("test secret", time.Minute, nil)
+	valid := signer.Sign("DELETE", path, body, now)
+	_, mac, _ := strings.Cut(valid, ",v1=")
+
+	tests := []struct {
+		name   string
+		header string
+		method string
+		path   string
+		body   []byte
+		now    time.Time
+		want   error
+	}{
+		{name: "valid", header: valid, want: nil},
+		{name: "lower case method", header: valid, method: "delete", want: nil},
+		{name: "at the edge of the window", header: valid, now: now.Add(time.Minute), want: nil},
+		{name: "signed ahead within the window", header: valid, now: now.Add(-time.Minute), want: nil},
+		{name: "missing", header: "", want: ErrSignatureMissing},
+		{name: "no timestamp", header: "v1=" + mac, want: ErrSignatureMalformed},
+		{name: "no MAC", header: "t=" + strconv.FormatInt(now.Unix(), 10), want: ErrSignatureMalformed},
+		{name: "short MAC", header: valid[:len(valid)-2], want: ErrSignatureMalformed},
+		{name: "too old", header: valid, now: now.Add(time.Minute + time.Second), want: ErrSignatureExpired},
+		{name: "too far ahead", header: valid, now: now.Add(-time.Minute - time.Second), want: ErrSignatureExpired},
+		{name: "timestamp moved", header: "t=" + strconv.FormatInt(now.Unix()+1, 10) + ",v1=" + mac, want: ErrSignatureMismatch},
+		{name: "other method", header: valid, method: "POST", want: ErrSignatureMismatch},
+		{name: "other path", header: valid, path: "/api/v1/admin/users/2?hard=true", want: ErrSignatureMismatch},
+		{name: "query dropped", header: valid, path: "/api/v1/admin/users/1", want: ErrSignatureMismatch},
+		{name: "body tampered", header: valid, body: []byte(`{"confirm":false}`), want: ErrSignatureMismatch},
+		{name: "other secret", header: NewRequestSigner("other secret", time.Minute, nil).Sign("DELETE", path, body, now), want: ErrSignatureMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each case gets its own store, so only the replay test below sees replays
+			signer := NewRequestSigner("test secret", time.Minute, &usedSignatures{used: map[string]time.Time{}})
+			method, reqPath, reqBody, at := "DELETE", path, body, now
+			if tt.method != "" {
+				method = tt.method
+			}
+			if tt.path != "" {
+				reqPath = tt.path
+			}
+			if tt.body != nil {
+				reqBody = tt.body
+			}
+			if !tt.now.IsZero() {
+				at = tt.now
+			}
+			if err := signer.Verify(tt.header, method, reqPath, reqBody, at); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSignaturesAreAcceptedOnce(t *testing.T) {
+	store := &usedSignatures{used: map[string]time.Time{}}
+	signer := NewRequestSigner("test secret", time.Minute, store)
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	header := signer.Sign("POST", "/api/v1/admin/read-only", nil, now)
+
+	for i, want := range []error{nil, ErrSignatureReplayed, ErrSignatureReplayed} {
+		if err := signer.Verify(header, "POST", "/api/v1/admin/read-only", nil, now.Add(time.Duration(i)*time.Second)); !errors.Is(err, want) {
+			t.Errorf("use %d: got %v, want %v", i+1, err, want)
+		}
+	}
+	// A rejected signature is not recorded, so it does not block the valid one
+	other := signer.Sign("POST", "/api/v1/admin/read-only", nil, now.Add(time.Second))
+	if err := signer.Verify(other, "POST", "/api/v1/admin/incident-mode", nil, now); !errors.Is(err, ErrSignatureMismatch) {
+		t.Fatalf("tampered: got %v, want ErrSignatureMismatch", err)
+	}
+	if err := signer.Verify(other, "POST", "/api/v1/admin/read-only", nil, now); err != nil {
+		t.Errorf("after the tampered attempt: got %v, want it accepted", err)
+	}
+	// Used signatures are kept until they could no longer be accepted anyway
+	if expiresAt, want := store.used[strings.SplitN(header, "v1=", 2)[1]], now.Add(time.Minute); !expiresAt.Equal(want) {
+		t.Errorf("signature kept until %s, want %s", expiresAt, want)
+	}
+}
+
+func TestSignaturesAreAcceptedOnceAcrossInstances(t *testing.T) {
+	// Two instances sharing the store
+	store := &usedSignatures{used: map[string]time.Time{}}
+	first := NewRequestSigner("test secret", time.Minute, store)
+	second := NewRequestSigner("test secret", time.Minute, store)
+
+	now := time.Now()
+	body := []byte(`{"confirm":true}`)
+	header := first.Sign("DELETE", "/api/v1/admin/users/1", body, now)
+	if err := first.Verify(header, "DELETE", "/api/v1/admin/users/1", body, now); err != nil {
+		t.Fatalf("first use: %v", err)
+	}
+	if err := second.Verify(header, "DELETE", "/api/v1/admin/users/1", body, now); !errors.Is(err, ErrSignatureReplayed) {
+		t.Errorf("replay on another instance: got %v, want ErrSignatureReplayed", err)
+	}
+	// Upper case hex decodes to the same MAC, so it is the same signature
+	prefix, mac, _ := strings.Cut(header, "v1=")
+	upper := prefix + "v1=" + strings.ToUpper(mac)
+	if err := second.Verify(upper, "DELETE", "/api/v1/admin/users/1", body, now); !errors.Is(err, ErrSignatureReplayed) {
+		t.Errorf("replay in upper case hex: got %v, want ErrSignatureReplayed", err)
+	}
+}
+
+func TestSignatureStoreFailuresAreReported(t *testing.T) {
+	failure := errors.New("database is down")
+	signer := NewRequestSigner("test secret", time.Minute, failingSignatures{failure})
+
+	now := time.Now()
+	header := signer.Sign("POST", "/", nil, now)
+	if err := signer.Verify(header, "POST", "/", nil, now); err != failure {
+		t.Errorf("got %v, want the store's error", err)
+	}
+}
+
+// failingSignatures fails to record any signature
+type failingSignatures struct{ err error }
+
+func (f failingSignatures) Use(string, time.Time) (bool, error) { return false, f.err }
+
+func (f failingSignatures) DeleteExpired(time.Time) (int64, error) { return 0, f.err }
@@ -0,0 +1,117 @@
+package security
+
+import "time"
+
+// Sign-in risk factors
+const (
+	RiskFactorNewIP            = "new_ip"
+	RiskFactorNewCountry       = "new_country"
+	RiskFactorImpossibleTravel = "impossible_travel"
+	RiskFactorDatacenterASN    = "datacenter_asn"
+)
+
+// riskWeights are the score contributions of each factor (scores are capped at 100)
+var riskWeights = map[string]int{
+	RiskFactorNewIP:            30,
+	RiskFactorNewCountry:       25,
+	RiskFactorImpossibleTravel: 50,
+	RiskFactorDatacenterASN:    30,
+}
+
+// maxTravelSpeedKmh is the fastest plausible travel speed between two sign-ins (a commercial flight)
+const maxTravelSpeedKmh = 1000.0
+
+// minTravelDistanceKm ignores short distances where GeoIP precision makes speed meaningless
+const minTravelDistanceKm = 500.0
+
+// DefaultDatacenterASNs are autonomous systems of large hosting providers, used when none are configured
+var DefaultDatacenterASNs = []uint{
+	16509, 14618, // Amazon
+	15169, 396982, // Google Cloud
+	8075,  // Microsoft
+	14061, // DigitalOcean
+	16276, // OVH
+	24940, // Hetzner
+	63949, // Linode
+	20473, // Vultr
+}
+
+// LoginHistory is what is known about the previous successful sign-ins of a user
+type LoginHistory struct {
+	HasPreviousLogins bool
+	KnownIP           bool
+	LastLocation      *GeoLocation
+	LastLoginAt       time.Time
+}
+
+// RiskAssessment is the result of scoring a sign-in attempt
+type RiskAssessment struct {
+	Score    int          `json:"score"`
+	Factors  []string     `json:"factors,omitempty"`
+	Location *GeoLocation `json:"location,omitempty"`
+}
+
+// RiskScorer computes a basic risk score for sign-in attempts
+type RiskScorer struct {
+	geo            GeoLocator
+	datacenterASNs map[uint]struct{}
+}
+
+// NewRiskScorer creates a risk scorer. The geo locator may be nil, in which case
+// location-based factors are skipped.
+func NewRiskScorer(geo GeoLocator, datacenterASNs []uint) *RiskScorer {
+	asns := make(map[uint]struct{}, len(datacenterASNs))
+	for _, asn := range datacenterASNs {
+		asns[asn] = struct{}{}
+	}
+
+	return &RiskScorer{
+		geo:            geo,
+		datacenterASNs: asns,
+	}
+}
+
+// Assess scores a sign-in from ip given the user's login history
+func (rs *RiskScorer) Assess(ip string, history LoginHistory, now time.Time) *RiskAssessment {
+	assessment := &RiskAssessment{}
+	if rs.geo != nil {
+		assessment.Location = rs.geo.Lookup(ip)
+	}
+
+	// A user's first sign-in has nothing to compare against
+	if history.HasPreviousLogins && !history.KnownIP {
+		assessment.add(RiskFactorNewIP)
+	}
+
+	location, last := assessment.Location, history.LastLocation
+	if location != nil && last != nil {
+		if location.Country != "" && last.Country != "" && location.Country != last.Country {
+			assessment.add(RiskFactorNewCountry)
+		}
+
+		if location.HasCoordinates() && last.HasCoordinates() && !history.LastLoginAt.IsZero() {
+			distance := DistanceKm(last, location)
+			hours := now.Sub(history.LastLoginAt).Hours()
+			if distance >= minTravelDistanceKm && (hours <= 0 || distance/hours > maxTravelSpeedKmh) {
+				assessment.add(RiskFactorImpossibleTravel)
+			}
+		}
+	}
+
+	if location != nil && location.ASN != 0 {
+		if _, ok := rs.datacenterASNs[location.ASN]; ok {
+			assessment.add(RiskFactorDatacenterASN)
+		}
+	}
+
+	return assessment
+}
+
+// add records a factor and its weight
+func (a *RiskAssessment) add(factor string) {
+	a.Factors = append(a.Factors, factor)
+	a.Score += riskWeights[factor]
+	if a.Score > 100 {
+		a.Score = 100
+	}
+}
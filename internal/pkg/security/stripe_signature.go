@@ -0,0 +1,65 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StripeSignatureHeader carries the signature of a Stripe webhook delivery:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256>", with one v1 per active signing secret
+const StripeSignatureHeader = "Stripe-Signature"
+
+// DefaultStripeSignatureTolerance is how old the timestamp of a Stripe delivery may be
+const DefaultStripeSignatureTolerance = 5 * time.Minute
+
+// VerifyStripeSignature checks the Stripe-Signature header of a webhook delivery of payload
+// against the endpoint's signing secret. The signature covers the timestamp and the raw body,
+// and is refused once its timestamp is further than tolerance from now.
+func VerifyStripeSignature(header string, payload []byte, secret string, tolerance time.Duration, now time.Time) error {
+	if header == "" {
+		return ErrSignatureMissing
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultStripeSignatureTolerance
+	}
+
+	var timestamp string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			// Stripe may send several while a secret is being rolled; other schemes are ignored
+			if signature, err := hex.DecodeString(value); err == nil && len(signature) == sha256.Size {
+				signatures = append(signatures, signature)
+			}
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrSignatureMalformed
+	}
+
+	signedAt := time.Unix(unix, 0)
+	if signedAt.Before(now.Add(-tolerance)) || signedAt.After(now.Add(tolerance)) {
+		return ErrSignatureExpired
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := mac.Sum(nil)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrSignatureMismatch
+}
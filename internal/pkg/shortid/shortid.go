@@ -0,0 +1,119 @@
+// Package shortid encodes the sequence numbers of records as short, URL-friendly base62 IDs
+// for shareable links. Numbers are scrambled with a keyed permutation before encoding, so
+// IDs do not reveal how many records there are and cannot be enumerated without the key.
+package shortid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"math/bits"
+	"strings"
+	"sync/atomic"
+)
+
+// alphabet holds the base62 digits, in order
+const alphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+// Length is the length of every short ID, enough for any 64-bit value
+const Length = 11
+
+// MaxNumber is the largest number with a short ID. The bits above it must be zero once an
+// ID is decoded, so only about one in 65,536 made-up IDs decodes at all.
+const MaxNumber = 1<<48 - 1
+
+// rounds is the number of Feistel rounds of the permutation
+const rounds = 6
+
+// developmentKey scrambles numbers until SetKey is called, so that development setups work
+// without configuration. It is public, so production must set a key of its own.
+const developmentKey = "go-backend-api development short ID key"
+
+// ErrInvalid is returned for strings that are not short IDs under the current key
+var ErrInvalid = errors.New("invalid short ID")
+
+// key is the secret of the permutation, see SetKey
+var key atomic.Pointer[[]byte]
+
+// SetKey sets the secret numbers are scrambled with. Call it at startup; changing it changes
+// every short ID, breaking links shared before.
+func SetKey(secret string) {
+	b := []byte(secret)
+	key.Store(&b)
+}
+
+// currentKey returns the secret set with SetKey, or the development key
+func currentKey() []byte {
+	if k := key.Load(); k != nil {
+		return *k
+	}
+	return []byte(developmentKey)
+}
+
+// Encode returns the short ID of n, or "" when n is not between 1 and MaxNumber
+func Encode(n int64) string {
+	if n <= 0 || n > MaxNumber {
+		return ""
+	}
+	v := permute(currentKey(), uint64(n), false)
+	var buf [Length]byte
+	for i := len(buf) - 1; i >= 0; i-- {
+		buf[i] = alphabet[v%62]
+		v /= 62
+	}
+	return string(buf[:])
+}
+
+// Decode returns the number a short ID encodes under the current key
+func Decode(id string) (int64, error) {
+	if len(id) != Length {
+		return 0, ErrInvalid
+	}
+	var v uint64
+	for i := 0; i < len(id); i++ {
+		digit := strings.IndexByte(alphabet, id[i])
+		if digit < 0 {
+			return 0, ErrInvalid
+		}
+		// IDs beyond 64 bits are invalid
+		hi, lo := bits.Mul64(v, 62)
+		sum, carry := bits.Add64(lo, uint64(digit), 0)
+		if hi != 0 || carry != 0 {
+			return 0, ErrInvalid
+		}
+		v = sum
+	}
+	n := permute(currentKey(), v, true)
+	if n == 0 || n > MaxNumber {
+		return 0, ErrInvalid
+	}
+	return int64(n), nil
+}
+
+// permute runs the Feistel network keyed with key over v, or its inverse
+func permute(key []byte, v uint64, inverse bool) uint64 {
+	left, right := uint32(v>>32), uint32(v)
+	if inverse {
+		left, right = right, left
+		for r := rounds - 1; r >= 0; r-- {
+			left, right = right, left^round(key, r, right)
+		}
+		return uint64(right)<<32 | uint64(left)
+	}
+	for r := 0; r < rounds; r++ {
+		left, right = right, left^round(key, r, right)
+	}
+	return uint64(left)<<32 | uint64(right)
+}
+
+// round is the Feistel round function: the first 32 bits of the HMAC-SHA256 of the round
+// number and half block
+func round(key []byte, r int, half uint32) uint32 {
+	var msg [5]byte
+	msg[0] = byte(r)
+	binary.BigEndian.PutUint32(msg[1:], half)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(msg[:])
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
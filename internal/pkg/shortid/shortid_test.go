@@ -0,0 +1,70 @@
+package shortid
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	SetKey("test key")
+	t.Cleanup(func() { key.Store(nil) })
+
+	for _, n := range []int64{1, 2, 61, 62, 1_000_000, MaxNumber} {
+		id := Encode(n)
+		if len(id) != Length {
+			t.Fatalf("Encode(%d) = %q, want %d characters", n, id, Length)
+		}
+		got, err := Decode(id)
+		if err != nil || got != n {
+			t.Errorf("Decode(Encode(%d)) = %d, %v", n, got, err)
+		}
+	}
+	for _, n := range []int64{0, -1, MaxNumber + 1} {
+		if id := Encode(n); id != "" {
+			t.Errorf("Encode(%d) = %q, want none", n, id)
+		}
+	}
+}
+
+func TestIDsDoNotFollowNumbers(t *testing.T) {
+	SetKey("test key")
+	t.Cleanup(func() { key.Store(nil) })
+
+	// Consecutive numbers share no common prefix beyond chance
+	a, b := Encode(1000), Encode(1001)
+	if a[:4] == b[:4] {
+		t.Errorf("IDs of consecutive numbers look alike: %q, %q", a, b)
+	}
+}
+
+func TestDecodeRejectsForeignIDs(t *testing.T) {
+	SetKey("test key")
+	t.Cleanup(func() { key.Store(nil) })
+	id := Encode(1000)
+
+	SetKey("other key")
+	if n, err := Decode(id); err == nil {
+		t.Errorf("ID of another key decoded to %d", n)
+	}
+
+	// Very few made-up IDs decode: the bits above MaxNumber must come out zero
+	SetKey("test key")
+	valid := 0
+	for i := 0; i < 10000; i++ {
+		id := Encode(int64(i + 1))
+		last := strings.IndexByte(alphabet, id[Length-1])
+		altered := id[:Length-1] + string(alphabet[(last+1+i%61)%62])
+		if _, err := Decode(altered); err == nil {
+			valid++
+		}
+	}
+	if valid > 10 {
+		t.Errorf("%d of 10000 altered IDs decoded", valid)
+	}
+
+	for _, id := range []string{"", "abc", strings.Repeat("Z", Length), strings.Repeat("-", Length), strings.Repeat("0", Length+1)} {
+		if _, err := Decode(id); err != ErrInvalid {
+			t.Errorf("Decode(%q) = %v, want ErrInvalid", id, err)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package testutil
+
+import (
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	"go-backend-api/internal/database"
+)
+
+// DatabaseURLEnv names the environment variable with the database integration tests use
+const DatabaseURLEnv = "TEST_DATABASE_URL"
+
+// The test database is migrated once per test binary
+var (
+	migrateOnce sync.Once
+	migrateErr  error
+)
+
+// DB connects to the database at TEST_DATABASE_URL, migrated on first use, skipping the
+// test when the variable is not set. The migration drops every table, so it must point to a
+// throwaway database. Tests share it, so they create their own rows, with unique emails and
+// usernames, rather than expect an empty table. The test fails if it leaves connections in use.
+func DB(t testing.TB) *sql.DB {
+	t.Helper()
+	url := os.Getenv(DatabaseURLEnv)
+	if url == "" {
+		t.Skipf("%s is not set", DatabaseURLEnv)
+	}
+
+	db, err := database.Open(url, database.Options{MaxOpenConns: 4, MaxIdleConns: 4})
+	if err != nil {
+		t.Fatalf("Failed to connect to the test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	migrateOnce.Do(func() { migrateErr = database.Migrate(db) })
+	if migrateErr != nil {
+		t.Fatalf("Failed to migrate the test database: %v", migrateErr)
+	}
+
+	VerifyDBConnections(t, db)
+	return db
+}
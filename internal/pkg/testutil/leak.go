@@ -0,0 +1,34 @@
+// Package testutil holds helpers shared by the tests of several packages. It is only
+// imported from _test.go files.
+package testutil
+
+import (
+	"database/sql"
+	"testing"
+
+	"go.uber.org/goleak"
+)
+
+// VerifyNoLeaks fails the test if goroutines it started are still running once it and its
+// cleanups are done. Goroutines running before the call, such as those of other tests, are
+// ignored. Register the cleanups that stop background workers after calling it, so they run
+// first.
+func VerifyNoLeaks(t testing.TB) {
+	t.Helper()
+	ignore := goleak.IgnoreCurrent()
+	t.Cleanup(func() {
+		goleak.VerifyNone(t, ignore)
+	})
+}
+
+// VerifyDBConnections fails the test if it leaves more connections of db in use than before
+// the call, like rows or transactions that were never closed
+func VerifyDBConnections(t testing.TB, db *sql.DB) {
+	t.Helper()
+	before := db.Stats().InUse
+	t.Cleanup(func() {
+		if inUse := db.Stats().InUse; inUse > before {
+			t.Errorf("%d database connections still in use, %d before the test", inUse, before)
+		}
+	})
+}
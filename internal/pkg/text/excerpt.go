@@ -0,0 +1,60 @@
+// Package text derives plain-text summaries from user-written post content.
+package text
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Defaults used when no excerpt length or reading speed is configured
+const (
+	DefaultExcerptWords   = 40
+	DefaultWordsPerMinute = 200
+)
+
+var (
+	// htmlBlocks are elements whose content is never prose
+	htmlBlocks = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	// Markdown: fenced code, images and links keep their text, then line prefixes and emphasis markers
+	markdownFences   = regexp.MustCompile("(?s)```.*?```")
+	markdownImages   = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLinks    = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	markdownPrefixes = regexp.MustCompile(`(?m)^\s*(#{1,6}|>+|[-*+]|\d+\.)\s+`)
+	markdownMarkers  = regexp.MustCompile("[*_~`]+")
+)
+
+// StripMarkup removes HTML tags and common Markdown syntax, leaving the readable text
+func StripMarkup(content string) string {
+	s := htmlBlocks.ReplaceAllString(content, " ")
+	s = htmlTags.ReplaceAllString(s, " ")
+	s = markdownFences.ReplaceAllString(s, " ")
+	s = markdownImages.ReplaceAllString(s, "$1")
+	s = markdownLinks.ReplaceAllString(s, "$1")
+	s = markdownPrefixes.ReplaceAllString(s, "")
+	s = markdownMarkers.ReplaceAllString(s, "")
+	return strings.Join(strings.Fields(html.UnescapeString(s)), " ")
+}
+
+// Excerpt returns the first words of the content's plain text, ending in an ellipsis when cut short
+func Excerpt(content string, words int) string {
+	if words < 1 {
+		words = DefaultExcerptWords
+	}
+	fields := strings.Fields(StripMarkup(content))
+	if len(fields) <= words {
+		return strings.Join(fields, " ")
+	}
+	return strings.Join(fields[:words], " ") + "…"
+}
+
+// ReadingTime estimates how many minutes reading the content takes, rounded up.
+// Any content with words takes at least a minute.
+func ReadingTime(content string, wordsPerMinute int) int {
+	if wordsPerMinute < 1 {
+		wordsPerMinute = DefaultWordsPerMinute
+	}
+	words := len(strings.Fields(StripMarkup(content)))
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
@@ -0,0 +1,26 @@
+package text
+
+import "github.com/abadojack/whatlanggo"
+
+// DetectLanguage returns the ISO 639-1 code of the content's language, or an empty
+// string when the content is too short or mixed for a reliable guess
+func DetectLanguage(content string) string {
+	info := whatlanggo.Detect(StripMarkup(content))
+	if !info.IsReliable() {
+		return ""
+	}
+	return info.Lang.Iso6391()
+}
+
+// IsLanguageCode reports whether code looks like an ISO 639-1 language code
+func IsLanguageCode(code string) bool {
+	if len(code) != 2 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
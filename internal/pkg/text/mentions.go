@@ -0,0 +1,29 @@
+package text
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches @username not preceded by a word character, so emails are not mentions.
+// Usernames are 3-20 letters, digits and underscores.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@(\w{3,20})\b`)
+
+// Mentions returns the distinct usernames mentioned in content, in order of first mention and
+// compared case-insensitively, keeping at most limit of them
+func Mentions(content string, limit int) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		if len(usernames) >= limit {
+			break
+		}
+		key := strings.ToLower(match[1])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, match[1])
+	}
+	return usernames
+}
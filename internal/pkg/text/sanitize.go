@@ -0,0 +1,13 @@
+package text
+
+import "github.com/microcosm-cc/bluemonday"
+
+// htmlPolicy allows the formatting, links and images of user-generated content
+// while dropping scripts, event handlers, styles and unsafe URLs
+var htmlPolicy = bluemonday.UGCPolicy()
+
+// SanitizeHTML removes dangerous markup from HTML content and reports whether anything was changed
+func SanitizeHTML(content string) (string, bool) {
+	sanitized := htmlPolicy.Sanitize(content)
+	return sanitized, sanitized != content
+}
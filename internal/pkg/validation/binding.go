@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"go-backend-api/internal/pkg/normalize"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// normalizingValidator normalizes bound request structs before handing them to the wrapped validator
+type normalizingValidator struct {
+	next binding.StructValidator
+}
+
+// NewBindingValidator wraps a gin binding validator so every request bound with c.ShouldBind*
+// is trimmed and NFC-normalized (see normalize.Struct) before validation. Install it at startup:
+//
+//	binding.Validator = validation.NewBindingValidator(binding.Validator)
+func NewBindingValidator(next binding.StructValidator) binding.StructValidator {
+	return &normalizingValidator{next: next}
+}
+
+// ValidateStruct normalizes obj in place, then validates it
+func (v *normalizingValidator) ValidateStruct(obj any) error {
+	normalize.Struct(obj)
+	if v.next == nil {
+		return nil
+	}
+	return v.next.ValidateStruct(obj)
+}
+
+// Engine returns the wrapped validator's engine
+func (v *normalizingValidator) Engine() any {
+	if v.next == nil {
+		return nil
+	}
+	return v.next.Engine()
+}
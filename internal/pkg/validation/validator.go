@@ -3,28 +3,46 @@ package validation
 import (
 	"regexp"
 	"strings"
+	"unicode/utf8"
+
+	"go-backend-api/internal/pkg/security"
 
 	"github.com/go-playground/validator/v10"
 )
 
+// Patterns are compiled once rather than on every validation
+var (
+	usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+)
+
 // Validator wraps the go-playground validator
 type Validator struct {
-	validator *validator.Validate
+	validator      *validator.Validate
+	passwordPolicy *security.PasswordPolicy
 }
 
-// NewValidator creates a new validator instance
+// NewValidator creates a new validator instance using the default password policy
 func NewValidator() *Validator {
+	return NewValidatorWithPasswordPolicy(nil)
+}
+
+// NewValidatorWithPasswordPolicy creates a new validator whose password tag enforces policy.
+// A nil policy uses security.DefaultPasswordPolicy.
+func NewValidatorWithPasswordPolicy(policy *security.PasswordPolicy) *Validator {
+	if policy == nil {
+		policy = security.DefaultPasswordPolicy()
+	}
 	v := validator.New()
 
 	// Register custom validators
 	if err := v.RegisterValidation("username", validateUsername); err != nil {
 		panic("failed to register username validator: " + err.Error())
 	}
-	if err := v.RegisterValidation("password", validatePassword); err != nil {
+	if err := v.RegisterValidation("password", passwordValidator(policy)); err != nil {
 		panic("failed to register password validator: " + err.Error())
 	}
 
-	return &Validator{validator: v}
+	return &Validator{validator: v, passwordPolicy: policy}
 }
 
 // Validate validates a struct
@@ -42,28 +60,19 @@ func validateUsername(fl validator.FieldLevel) bool {
 	username := fl.Field().String()
 
 	// Username should be 3-20 characters, alphanumeric and underscores only
-	if len(username) < 3 || len(username) > 20 {
+	if length := utf8.RuneCountInString(username); length < 3 || length > 20 {
 		return false
 	}
 
-	matched, _ := regexp.MatchString(`^[a-zA-Z0-9_]+$`, username)
-	return matched
+	// Being ASCII only also refuses look-alikes from other scripts (e.g. Cyrillic)
+	return usernamePattern.MatchString(username)
 }
 
-// validatePassword validates password strength
-func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	// Password should be at least 6 characters
-	if len(password) < 6 {
-		return false
+// passwordValidator validates passwords against policy
+func passwordValidator(policy *security.PasswordPolicy) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		return policy.ValidatePassword(fl.Field().String()) == nil
 	}
-
-	// Password should contain at least one letter and one number
-	hasLetter, _ := regexp.MatchString(`[a-zA-Z]`, password)
-	hasNumber, _ := regexp.MatchString(`[0-9]`, password)
-
-	return hasLetter && hasNumber
 }
 
 // ValidationError represents a validation error
@@ -84,7 +93,7 @@ func (v *Validator) GetValidationErrors(err error) []ValidationError {
 				Field:   strings.ToLower(e.Field()),
 				Tag:     e.Tag(),
 				Value:   e.Param(),
-				Message: getValidationMessage(e),
+				Message: v.getValidationMessage(e),
 			})
 		}
 	}
@@ -93,7 +102,7 @@ func (v *Validator) GetValidationErrors(err error) []ValidationError {
 }
 
 // getValidationMessage returns a human-readable validation message
-func getValidationMessage(e validator.FieldError) string {
+func (v *Validator) getValidationMessage(e validator.FieldError) string {
 	switch e.Tag() {
 	case "required":
 		return "This field is required"
@@ -106,7 +115,7 @@ func getValidationMessage(e validator.FieldError) string {
 	case "username":
 		return "Username must be 3-20 characters, alphanumeric and underscores only"
 	case "password":
-		return "Password must be at least 6 characters with letters and numbers"
+		return v.passwordPolicy.Describe()
 	default:
 		return "Invalid value"
 	}
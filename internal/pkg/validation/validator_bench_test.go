@@ -0,0 +1,25 @@
+package validation
+
+import "testing"
+
+type benchmarkSignup struct {
+	Username string `validate:"required,username"`
+	Password string `validate:"required,password"`
+}
+
+func BenchmarkValidateSignup(b *testing.B) {
+	v := NewValidator()
+	for name, req := range map[string]benchmarkSignup{
+		"valid":          {Username: "gopher_42", Password: "Tr0ub4dor&3-Horse!"},
+		"weak password":  {Username: "gopher_42", Password: "Zq7!Zq7!mm"},
+		"bad username":   {Username: "gopher-42!", Password: "Tr0ub4dor&3-Horse!"},
+		"multibyte name": {Username: "gôpher", Password: "Tr0ub4dor&3-Horse!"},
+	} {
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = v.Validate(&req)
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"go-backend-api/internal/pkg/security"
+)
+
+func TestValidateUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		valid    bool
+	}{
+		{"3 characters", "abc", true},
+		{"20 characters", strings.Repeat("a", 20), true},
+		{"underscores and digits", "go_pher_42", true},
+		{"2 characters", "ab", false},
+		{"21 characters", strings.Repeat("a", 21), false},
+		// Usernames are ASCII, so multibyte input is refused for its characters, not its length
+		{"3 CJK characters", "用户名", false},
+		{"emoji", "gopher😀", false},
+		{"Cyrillic look-alike", "pаypal", false},
+		{"Cyrillic only", "сергей", false},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.ValidateVar(tt.username, "username"); (err == nil) != tt.valid {
+				t.Errorf("username %q: got error %v, want valid %v", tt.username, err, tt.valid)
+			}
+		})
+	}
+}
+
+// ideographs are 22 distinct CJK characters, 66 bytes
+const ideographs = "一二三四五六七八九十甲乙丙丁戊己庚辛壬癸天地"
+
+func TestValidatePassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		valid    bool
+	}{
+		{"8 characters of 16 bytes", "Ab1!密码安全", true},
+		{"7 characters of 13 bytes", "Ab1!密码安", false},
+		{"emoji", "Ab1🔒🔑🛡️🚪", true},
+		{"72 bytes", "Ab1!xy" + ideographs, true},
+		{"73 bytes", "Ab1!xyz" + ideographs, false},
+		{"72 characters over 72 bytes", "Ab1!" + strings.Repeat("é", 68), false},
+	}
+
+	v := NewValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := v.ValidateVar(tt.password, "password"); (err == nil) != tt.valid {
+				t.Errorf("password %q (%d bytes): got error %v, want valid %v", tt.password, len(tt.password), err, tt.valid)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordFollowsPolicy(t *testing.T) {
+	// A policy allowing short passwords still cannot exceed what bcrypt hashes
+	policy := &security.PasswordPolicy{MinLength: 4, MaxLength: 100, MaxBytes: security.BcryptMaxBytes}
+	v := NewValidatorWithPasswordPolicy(policy)
+
+	if err := v.ValidateVar("密码安全", "password"); err != nil {
+		t.Errorf("4 CJK characters: %v", err)
+	}
+	if err := v.ValidateVar(strings.Repeat("密", 25), "password"); err == nil {
+		t.Error("75 bytes of CJK accepted")
+	}
+}
@@ -0,0 +1,157 @@
+package push
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// APNs hosts, reached over HTTP/2
+const (
+	apnsProductionHost = "https://api.push.apple.com"
+	apnsSandboxHost    = "https://api.sandbox.push.apple.com"
+)
+
+// apnsTokenTTL is how long a provider token is reused. Apple accepts tokens for an hour and
+// refuses new ones more often than every 20 minutes.
+const apnsTokenTTL = 50 * time.Minute
+
+// apnsSender sends notifications to iOS devices, authenticating with provider tokens signed by
+// the team's key
+type apnsSender struct {
+	host   string
+	keyID  string
+	teamID string
+	topic  string
+	key    *ecdsa.PrivateKey
+	client *http.Client
+
+	mutex    sync.Mutex
+	token    string
+	issuedAt time.Time
+}
+
+func newAPNsSender(cfg Config, client *http.Client) (*apnsSender, error) {
+	data, err := os.ReadFile(cfg.APNsKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read apns key: %w", err)
+	}
+	key, err := jwt.ParseECPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse apns key: %w", err)
+	}
+
+	host := apnsProductionHost
+	if cfg.APNsSandbox {
+		host = apnsSandboxHost
+	}
+	return &apnsSender{
+		host:   host,
+		keyID:  cfg.APNsKeyID,
+		teamID: cfg.APNsTeamID,
+		topic:  cfg.APNsTopic,
+		key:    key,
+		client: client,
+	}, nil
+}
+
+// Name returns the service notifications are sent to
+func (s *apnsSender) Name() string {
+	if s.host == apnsSandboxHost {
+		return "apns (sandbox)"
+	}
+	return "apns"
+}
+
+// Send delivers a notification to an iOS device. The data is passed as custom keys of the
+// payload, next to aps.
+func (s *apnsSender) Send(n *Notification) error {
+	token, err := s.providerToken()
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": n.Title, "body": n.Body},
+			"sound": "default",
+		},
+	}
+	for k, v := range n.Data {
+		if k != "aps" {
+			payload[k] = v
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode apns payload: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.host+"/3/device/"+url.PathEscape(n.Token), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+	if n.CollapseKey != "" {
+		req.Header.Set("apns-collapse-id", n.CollapseKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send apns notification: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&failure)
+	switch {
+	case resp.StatusCode == http.StatusGone, failure.Reason == "BadDeviceToken", failure.Reason == "Unregistered":
+		return ErrUnregistered
+	case failure.Reason == "ExpiredProviderToken":
+		s.mutex.Lock()
+		s.token = ""
+		s.mutex.Unlock()
+	}
+	return fmt.Errorf("apns responded with status %d: %s", resp.StatusCode, failure.Reason)
+}
+
+// providerToken returns the current provider token, signing a new one when it is due
+func (s *apnsSender) providerToken() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if s.token != "" && now.Sub(s.issuedAt) < apnsTokenTTL {
+		return s.token, nil
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns provider token: %w", err)
+	}
+
+	s.token, s.issuedAt = signed, now
+	return s.token, nil
+}
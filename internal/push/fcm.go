@@ -0,0 +1,191 @@
+package push
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// fcmScope is the OAuth scope of the FCM HTTP v1 API
+const fcmScope = "https://www.googleapis.com/auth/firebase.messaging"
+
+// fcmTokenMargin is how long before it expires an access token is renewed
+const fcmTokenMargin = time.Minute
+
+// fcmServiceAccount is the part of a Google service account key the sender reads
+type fcmServiceAccount struct {
+	ProjectID   string `json:"project_id"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// fcmSender sends notifications with the FCM HTTP v1 API, authenticating as a service account
+// with OAuth access tokens obtained by signed JWT assertions
+type fcmSender struct {
+	endpoint string
+	account  fcmServiceAccount
+	key      *rsa.PrivateKey
+	client   *http.Client
+
+	mutex       sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+func newFCMSender(credentialsFile, projectID string, client *http.Client) (*fcmSender, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fcm credentials: %w", err)
+	}
+	var account fcmServiceAccount
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("failed to parse fcm credentials: %w", err)
+	}
+	if account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("fcm credentials are not a service account key")
+	}
+	if account.TokenURI == "" {
+		account.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+	if projectID == "" {
+		projectID = account.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("fcm requires a project ID")
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(account.PrivateKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fcm private key: %w", err)
+	}
+
+	return &fcmSender{
+		endpoint: "https://fcm.googleapis.com/v1/projects/" + url.PathEscape(projectID) + "/messages:send",
+		account:  account,
+		key:      key,
+		client:   client,
+	}, nil
+}
+
+// Name returns the service notifications are sent to
+func (s *fcmSender) Name() string { return "fcm" }
+
+// Send delivers a notification to an Android device, or an iOS one registered through Firebase
+func (s *fcmSender) Send(n *Notification) error {
+	token, err := s.token()
+	if err != nil {
+		return err
+	}
+
+	message := map[string]interface{}{
+		"token":        n.Token,
+		"notification": map[string]string{"title": n.Title, "body": n.Body},
+	}
+	if len(n.Data) > 0 {
+		message["data"] = n.Data
+	}
+	if n.CollapseKey != "" {
+		message["android"] = map[string]string{"collapse_key": n.CollapseKey}
+		message["apns"] = map[string]interface{}{"headers": map[string]string{"apns-collapse-id": n.CollapseKey}}
+	}
+	body, err := json.Marshal(map[string]interface{}{"message": message})
+	if err != nil {
+		return fmt.Errorf("failed to encode fcm message: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send fcm message: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	var failure struct {
+		Error struct {
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(data, &failure)
+	// A 404 alone may come from a misconfigured project, so only the error code forgets devices
+	for _, detail := range failure.Error.Details {
+		if detail.ErrorCode == "UNREGISTERED" {
+			return ErrUnregistered
+		}
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		s.mutex.Lock()
+		s.accessToken = ""
+		s.mutex.Unlock()
+	}
+	return fmt.Errorf("fcm responded with status %d: %s", resp.StatusCode, failure.Error.Message)
+}
+
+// token returns an access token, exchanging a new assertion when the current one expires
+func (s *fcmSender) token() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	if s.accessToken != "" && now.Before(s.expiresAt.Add(-fcmTokenMargin)) {
+		return s.accessToken, nil
+	}
+
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.account.ClientEmail,
+		"scope": fcmScope,
+		"aud":   s.account.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fcm assertion: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	resp, err := s.client.Post(s.account.TokenURI, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to get fcm access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fcm token endpoint responded with status %d", resp.StatusCode)
+	}
+
+	var grant struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&grant); err != nil || grant.AccessToken == "" {
+		return "", fmt.Errorf("invalid fcm access token response")
+	}
+
+	s.accessToken = grant.AccessToken
+	s.expiresAt = now.Add(time.Duration(grant.ExpiresIn) * time.Second)
+	return s.accessToken, nil
+}
@@ -0,0 +1,128 @@
+// Package push sends notifications to mobile devices through Firebase Cloud Messaging (FCM)
+// and the Apple Push Notification service (APNs).
+package push
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Platforms devices register for
+const (
+	PlatformFCM  = "fcm"
+	PlatformAPNs = "apns"
+)
+
+// Platforms lists the supported platforms
+var Platforms = []string{PlatformFCM, PlatformAPNs}
+
+// sendTimeout bounds a single delivery to a push service
+const sendTimeout = 10 * time.Second
+
+// ErrUnregistered is returned when the push service no longer knows a device token, because
+// the app was uninstalled or the token was rotated; the device should be forgotten
+var ErrUnregistered = errors.New("push: device token is no longer registered")
+
+// Notification is a message shown on a device
+type Notification struct {
+	Token string
+	Title string
+	Body  string
+	// CollapseKey groups notifications about the same thing: a device offline when several
+	// arrive with the same key only shows the latest
+	CollapseKey string
+	// Data is passed to the app along with the notification, e.g. what to open when tapped
+	Data map[string]string
+}
+
+// Sender delivers notifications to the devices of a platform
+type Sender interface {
+	Send(n *Notification) error
+	// Name describes where notifications are sent, for logs
+	Name() string
+}
+
+// Config holds the credentials of the push services
+type Config struct {
+	// FCMCredentialsFile is the JSON key of a Google service account allowed to send messages
+	FCMCredentialsFile string
+	// FCMProjectID is the Firebase project; defaults to the project of the service account
+	FCMProjectID string
+
+	// APNsKeyFile is the .p8 signing key of the Apple developer team
+	APNsKeyFile string
+	APNsKeyID   string
+	APNsTeamID  string
+	// APNsTopic is the bundle ID of the app
+	APNsTopic string
+	// APNsSandbox sends to the development environment, for debug builds of the app
+	APNsSandbox bool
+}
+
+// New creates a sender per platform. Platforms without credentials get a sender writing
+// notifications to the log instead (development).
+func New(cfg Config) (map[string]Sender, error) {
+	client := &http.Client{Timeout: sendTimeout}
+	senders := make(map[string]Sender, len(Platforms))
+
+	if cfg.FCMCredentialsFile != "" {
+		fcm, err := newFCMSender(cfg.FCMCredentialsFile, cfg.FCMProjectID, client)
+		if err != nil {
+			return nil, err
+		}
+		senders[PlatformFCM] = fcm
+	}
+
+	if cfg.APNsKeyFile != "" {
+		if cfg.APNsKeyID == "" || cfg.APNsTeamID == "" || cfg.APNsTopic == "" {
+			return nil, fmt.Errorf("apns requires a key ID, team ID and topic")
+		}
+		apns, err := newAPNsSender(cfg, client)
+		if err != nil {
+			return nil, err
+		}
+		senders[PlatformAPNs] = apns
+	}
+
+	for _, platform := range Platforms {
+		if senders[platform] == nil {
+			senders[platform] = &logSender{platform: platform}
+		}
+	}
+	return senders, nil
+}
+
+// IsPlatform reports whether platform is supported
+func IsPlatform(platform string) bool {
+	for _, p := range Platforms {
+		if p == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// logSender writes notifications to the application log instead of sending them
+type logSender struct {
+	platform string
+}
+
+// Name returns the platform the sender stands in for
+func (s *logSender) Name() string { return s.platform + " (log)" }
+
+// Send logs the notification, with just enough of the token to tell devices apart
+func (s *logSender) Send(n *Notification) error {
+	log.Printf("[push] platform=%s token=%s collapse=%s title=%q\n%s", s.platform, tokenHint(n.Token), n.CollapseKey, n.Title, n.Body)
+	return nil
+}
+
+// tokenHint shortens a device token for logs
+func tokenHint(token string) string {
+	if len(token) <= 8 {
+		return token
+	}
+	return token[:8] + "…"
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/ids"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// announcementRepository implements AnnouncementRepository interface
+type announcementRepository struct {
+	db *sql.DB
+}
+
+// NewAnnouncementRepository creates a new announcement repository
+func NewAnnouncementRepository(db *sql.DB) models.AnnouncementRepository {
+	return &announcementRepository{db: db}
+}
+
+// Create creates a new announcement
+func (r *announcementRepository) Create(announcement *models.Announcement) error {
+	if announcement.ID == uuid.Nil {
+		announcement.ID = ids.New()
+	}
+
+	query := `INSERT INTO announcements (id, message, severity, dismissible, starts_at, ends_at, roles, plans, created_by, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.Exec(query, announcement.ID, announcement.Message, announcement.Severity, announcement.Dismissible,
+		announcement.StartsAt, announcement.EndsAt, pq.Array(announcement.Roles), pq.Array(announcement.Plans),
+		announcement.CreatedBy, announcement.CreatedAt, announcement.UpdatedAt)
+	if err != nil {
+		return writeError(err, "Failed to create announcement")
+	}
+
+	return nil
+}
+
+// GetByID gets an announcement by ID
+func (r *announcementRepository) GetByID(id uuid.UUID) (*models.Announcement, error) {
+	announcement := &models.Announcement{}
+	query := `SELECT ` + announcementColumns + ` FROM announcements WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(announcementFields(announcement)...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get announcement")
+	}
+
+	return announcement, nil
+}
+
+// List lists all announcements, the latest to start first
+func (r *announcementRepository) List(limit, offset int) ([]*models.Announcement, error) {
+	return r.list(`SELECT `+announcementColumns+` FROM announcements ORDER BY starts_at DESC, id LIMIT $1 OFFSET $2`, limit, offset)
+}
+
+// Count counts all announcements
+func (r *announcementRepository) Count() (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM announcements`).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count announcements")
+	}
+
+	return count, nil
+}
+
+// ListActive lists the announcements running at now whose audience includes role and plan and
+// that the user has not dismissed, the most severe and then the latest to start first
+func (r *announcementRepository) ListActive(userID uuid.UUID, role, plan string, now time.Time) ([]*models.Announcement, error) {
+	query := `SELECT ` + announcementColumns + ` FROM announcements a
+			  WHERE a.starts_at <= $2 AND (a.ends_at IS NULL OR a.ends_at > $2)
+			  AND (cardinality(a.roles) = 0 OR $3 = ANY(a.roles))
+			  AND (cardinality(a.plans) = 0 OR $4 = ANY(a.plans))
+			  AND NOT EXISTS (SELECT 1 FROM announcement_dismissals d WHERE d.announcement_id = a.id AND d.user_id = $1)
+			  ORDER BY CASE a.severity WHEN 'critical' THEN 0 WHEN 'warning' THEN 1 ELSE 2 END, a.starts_at DESC, a.id`
+
+	return r.list(query, userID, now, role, plan)
+}
+
+// list runs a multi-row announcement query
+func (r *announcementRepository) list(query string, args ...interface{}) ([]*models.Announcement, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list announcements")
+	}
+	defer rows.Close()
+
+	announcements := []*models.Announcement{}
+	for rows.Next() {
+		announcement := &models.Announcement{}
+		if err := rows.Scan(announcementFields(announcement)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan announcement")
+		}
+		announcements = append(announcements, announcement)
+	}
+
+	return announcements, rows.Err()
+}
+
+// Update updates an announcement
+func (r *announcementRepository) Update(announcement *models.Announcement) error {
+	query := `UPDATE announcements SET message = $1, severity = $2, dismissible = $3, starts_at = $4, ends_at = $5,
+			  roles = $6, plans = $7, updated_at = $8 WHERE id = $9`
+
+	result, err := r.db.Exec(query, announcement.Message, announcement.Severity, announcement.Dismissible,
+		announcement.StartsAt, announcement.EndsAt, pq.Array(announcement.Roles), pq.Array(announcement.Plans),
+		announcement.UpdatedAt, announcement.ID)
+	if err != nil {
+		return writeError(err, "Failed to update announcement")
+	}
+
+	return requireRowsAffected(result, "Failed to update announcement")
+}
+
+// Delete deletes an announcement and its dismissals
+func (r *announcementRepository) Delete(id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		return writeError(err, "Failed to delete announcement")
+	}
+
+	return requireRowsAffected(result, "Failed to delete announcement")
+}
+
+// Dismiss records a user dismissing an announcement, keeping when they first did
+func (r *announcementRepository) Dismiss(announcementID, userID uuid.UUID, at time.Time) error {
+	query := `INSERT INTO announcement_dismissals (announcement_id, user_id, dismissed_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, announcement_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, announcementID, userID, at); err != nil {
+		return writeError(err, "Failed to dismiss announcement")
+	}
+
+	return nil
+}
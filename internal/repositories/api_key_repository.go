@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// apiKeyRepository implements APIKeyRepository interface
+type apiKeyRepository struct {
+	db *sql.DB
+}
+
+// NewAPIKeyRepository creates a new API key repository
+func NewAPIKeyRepository(db *sql.DB) models.APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+// Create creates a new API key
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	query := `INSERT INTO api_keys (user_id, name, key_hash, hint, scopes, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+
+	err := r.db.QueryRow(query, key.UserID, key.Name, key.KeyHash, key.Hint, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedAt).Scan(&key.ID)
+	if err != nil {
+		return writeError(err, "Failed to create API key")
+	}
+
+	return nil
+}
+
+// GetByID gets an API key by ID
+func (r *apiKeyRepository) GetByID(id uuid.UUID) (*models.APIKey, error) {
+	return r.getOne(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id)
+}
+
+// GetByKeyHash gets an API key by the hash of the key
+func (r *apiKeyRepository) GetByKeyHash(keyHash string) (*models.APIKey, error) {
+	return r.getOne(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, keyHash)
+}
+
+// getOne runs a single-row API key query
+func (r *apiKeyRepository) getOne(query string, arg interface{}) (*models.APIKey, error) {
+	key := &models.APIKey{}
+
+	err := r.db.QueryRow(query, arg).Scan(apiKeyFields(key)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get API key")
+	}
+
+	return key, nil
+}
+
+// ListByUser lists the API keys of a user, newest first
+func (r *apiKeyRepository) ListByUser(userID uuid.UUID) ([]*models.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list API keys")
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key := &models.APIKey{}
+		err := rows.Scan(apiKeyFields(key)...)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan API key")
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// Revoke revokes an API key so it can no longer be used
+func (r *apiKeyRepository) Revoke(id uuid.UUID) error {
+	query := `UPDATE api_keys SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to revoke API key")
+	}
+
+	return requireRowsAffected(result, "Failed to revoke API key")
+}
+
+// TouchLastUsed records when an API key was last used
+func (r *apiKeyRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	query := `UPDATE api_keys SET last_used_at = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, usedAt, id)
+	if err != nil {
+		return errors.WrapError(err, "Failed to update API key last used time")
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// apiUsageRepository implements APIUsageRepository interface
+type apiUsageRepository struct {
+	db *sql.DB
+}
+
+// NewAPIUsageRepository creates a new API usage repository
+func NewAPIUsageRepository(db *sql.DB) models.APIUsageRepository {
+	return &apiUsageRepository{db: db}
+}
+
+// usageDayLayout formats the UTC days of usage, so the session time zone cannot shift them
+const usageDayLayout = "2006-01-02"
+
+// usagePeriods maps usage granularities to the date_trunc field of their periods
+var usagePeriods = map[string]string{
+	models.UsageDaily:   "day",
+	models.UsageMonthly: "month",
+}
+
+// usageMatches is the condition selecting the daily rollups of a filter, whose granularity,
+// from, to, user, API key and client are parameters $1 to $6
+const usageMatches = `day >= $2::date AND day < $3::date
+			  AND ($4::uuid IS NULL OR user_id = $4) AND ($5::uuid IS NULL OR api_key_id = $5)
+			  AND ($6::text = '' OR client_id = $6)`
+
+// usageGroups groups the daily rollups of a filter into its periods, per caller and route
+const usageGroups = `SELECT date_trunc($1::text, day::timestamp) AS period, user_id, api_key_id, client_id, method, route,
+			         SUM(requests) AS requests, SUM(errors) AS errors
+			  FROM api_usage_daily
+			  WHERE ` + usageMatches + `
+			  GROUP BY 1, 2, 3, 4, 5, 6`
+
+// Add adds counted requests to the daily rollups of their day, in one transaction
+func (r *apiUsageRepository) Add(usage []*models.APIUsage) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	stmt, err := tx.Prepare(`INSERT INTO api_usage_daily (day, user_id, api_key_id, client_id, method, route, requests, errors)
+			  VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8)
+			  ON CONFLICT (day, user_id, api_key_id, client_id, method, route)
+			  DO UPDATE SET requests = api_usage_daily.requests + EXCLUDED.requests,
+			                errors = api_usage_daily.errors + EXCLUDED.errors`)
+	if err != nil {
+		return errors.WrapError(err, "Failed to prepare API usage")
+	}
+	defer stmt.Close()
+
+	for _, u := range usage {
+		if _, err := stmt.Exec(u.Period.Format(usageDayLayout), u.UserID, u.APIKeyID, u.ClientID, u.Method, u.Route, u.Requests, u.Errors); err != nil {
+			return writeError(err, "Failed to add API usage")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, "Failed to commit API usage")
+	}
+
+	return nil
+}
+
+// List gets the usage matching the filter per period, caller and route, newest periods first
+// and the most requested routes first within them
+func (r *apiUsageRepository) List(filter *models.APIUsageFilter, limit, offset int) ([]*models.APIUsage, error) {
+	query := usageGroups + `
+			  ORDER BY period DESC, requests DESC, route, method
+			  LIMIT $7 OFFSET $8`
+
+	rows, err := r.db.Query(query, usagePeriods[filter.Granularity], filter.From.Format(usageDayLayout), filter.To.Format(usageDayLayout),
+		filter.UserID, filter.APIKeyID, filter.ClientID, limit, offset)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get API usage")
+	}
+	defer rows.Close()
+
+	usage := []*models.APIUsage{}
+	for rows.Next() {
+		u := &models.APIUsage{}
+		var clientID sql.NullString
+		if err := rows.Scan(&u.Period, &u.UserID, &u.APIKeyID, &clientID, &u.Method, &u.Route, &u.Requests, &u.Errors); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan API usage")
+		}
+		u.ClientID = clientID.String
+		usage = append(usage, u)
+	}
+
+	return usage, rows.Err()
+}
+
+// Count counts the periods, callers and routes with usage matching the filter
+func (r *apiUsageRepository) Count(filter *models.APIUsageFilter) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM (` + usageGroups + `) usage`
+
+	err := r.db.QueryRow(query, usagePeriods[filter.Granularity], filter.From.Format(usageDayLayout), filter.To.Format(usageDayLayout),
+		filter.UserID, filter.APIKeyID, filter.ClientID).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count API usage")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// auditLogRepository implements AuditLogRepository interface
+type auditLogRepository struct {
+	db *sql.DB
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *sql.DB) models.AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// queryRower is implemented by *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Create records an audit log entry
+func (r *auditLogRepository) Create(entry *models.AuditLog) error {
+	return insertAuditLog(r.db, entry)
+}
+
+// insertAuditLog records an audit log entry, also from inside another repository's transaction
+func insertAuditLog(db queryRower, entry *models.AuditLog) error {
+	query := `INSERT INTO audit_logs (user_id, action, resource_type, resource_id, ip_address, user_agent, country, city, details, created_at)
+			  VALUES ($1, $2, $3, $4, $5::inet, $6, $7, $8, $9, $10) RETURNING id`
+
+	var details interface{}
+	if len(entry.Details) > 0 {
+		details = []byte(entry.Details)
+	}
+
+	err := db.QueryRow(query, entry.UserID, entry.Action, entry.ResourceType, entry.ResourceID, entry.IPAddress, entry.UserAgent, entry.Country, entry.City, details, entry.CreatedAt).Scan(&entry.ID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create audit log")
+	}
+
+	return nil
+}
+
+// GetLatestByUserAndAction gets the most recent entry of an action for a user
+func (r *auditLogRepository) GetLatestByUserAndAction(userID uuid.UUID, action string) (*models.AuditLog, error) {
+	entry := &models.AuditLog{}
+	query := `SELECT id, user_id, action, resource_type, resource_id, HOST(ip_address), user_agent, country, city, details, created_at
+			  FROM audit_logs WHERE user_id = $1 AND action = $2
+			  ORDER BY created_at DESC LIMIT 1`
+
+	var details []byte
+	err := r.db.QueryRow(query, userID, action).Scan(
+		&entry.ID, &entry.UserID, &entry.Action, &entry.ResourceType, &entry.ResourceID,
+		&entry.IPAddress, &entry.UserAgent, &entry.Country, &entry.City, &details, &entry.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get audit log")
+	}
+	entry.Details = details
+
+	return entry, nil
+}
+
+// ExistsForUserFromIP checks if an action was recorded for a user from an IP address
+func (r *auditLogRepository) ExistsForUserFromIP(userID uuid.UUID, action, ipAddress string) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM audit_logs WHERE user_id = $1 AND action = $2 AND ip_address = $3::inet)`
+
+	err := r.db.QueryRow(query, userID, action, ipAddress).Scan(&exists)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check audit log")
+	}
+
+	return exists, nil
+}
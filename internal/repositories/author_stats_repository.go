@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// authorStatsRepository implements AuthorStatsRepository interface
+type authorStatsRepository struct {
+	db *sql.DB
+}
+
+// NewAuthorStatsRepository creates a new author stats repository
+func NewAuthorStatsRepository(db *sql.DB) models.AuthorStatsRepository {
+	return &authorStatsRepository{db: db}
+}
+
+// authorStatsColumns are the snapshot columns scanned by scanAuthorStats
+const authorStatsColumns = `s.user_id, s.day, s.posts, s.published_posts, s.views, s.likes, s.reactions, s.followers, s.follower_growth, s.computed_at`
+
+// Compute snapshots the stats of every user with posts or followers for day
+func (r *authorStatsRepository) Compute(day, now time.Time) (int, error) {
+	// Follower growth is measured against the author's latest earlier snapshot,
+	// so an author's first snapshot has no growth
+	query := `INSERT INTO author_stats (user_id, day, posts, published_posts, views, likes, reactions, followers, follower_growth, computed_at)
+			  SELECT u.id, $1::date, COALESCE(p.posts, 0), COALESCE(p.published_posts, 0), COALESCE(p.views, 0),
+			         COALESCE(r.likes, 0), COALESCE(r.reactions, 0), COALESCE(f.followers, 0),
+			         COALESCE(f.followers, 0) - COALESCE(prev.followers, COALESCE(f.followers, 0)), $2::timestamp
+			  FROM users u
+			  LEFT JOIN (SELECT author_id, COUNT(*) AS posts, COUNT(*) FILTER (WHERE is_published) AS published_posts,
+			                    SUM(view_count) AS views
+			             FROM posts WHERE archived_at IS NULL GROUP BY author_id) p ON p.author_id = u.id
+			  LEFT JOIN (SELECT po.author_id, COUNT(*) FILTER (WHERE re.reaction = 'like') AS likes, COUNT(*) AS reactions
+			             FROM reactions re JOIN posts po ON po.id = re.post_id
+			             WHERE po.archived_at IS NULL GROUP BY po.author_id) r ON r.author_id = u.id
+			  LEFT JOIN (SELECT followee_id, COUNT(*) AS followers FROM follows GROUP BY followee_id) f ON f.followee_id = u.id
+			  LEFT JOIN LATERAL (SELECT followers FROM author_stats
+			                     WHERE user_id = u.id AND day < $1 ORDER BY day DESC LIMIT 1) prev ON true
+			  WHERE p.author_id IS NOT NULL OR f.followee_id IS NOT NULL
+			  ON CONFLICT (user_id, day) DO UPDATE SET
+			      posts = EXCLUDED.posts, published_posts = EXCLUDED.published_posts, views = EXCLUDED.views,
+			      likes = EXCLUDED.likes, reactions = EXCLUDED.reactions, followers = EXCLUDED.followers,
+			      follower_growth = EXCLUDED.follower_growth, computed_at = EXCLUDED.computed_at`
+
+	result, err := r.db.Exec(query, day, now)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to compute author stats")
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to compute author stats")
+	}
+
+	return int(written), nil
+}
+
+// History gets the snapshots of an author since the given day, newest first
+func (r *authorStatsRepository) History(userID uuid.UUID, since time.Time) ([]*models.AuthorStats, error) {
+	query := `SELECT ` + authorStatsColumns + `, ''
+			  FROM author_stats s WHERE s.user_id = $1 AND s.day >= $2
+			  ORDER BY s.day DESC`
+
+	rows, err := r.db.Query(query, userID, since)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get author stats")
+	}
+	defer rows.Close()
+
+	return scanAuthorStats(rows)
+}
+
+// ListLatest gets the authors of the latest snapshot day in the given order
+func (r *authorStatsRepository) ListLatest(orderBy string, limit, offset int) ([]*models.AuthorStats, error) {
+	// orderBy comes from an identifier allowlist, never from raw input
+	query := `SELECT ` + authorStatsColumns + `, u.username
+			  FROM author_stats s
+			  JOIN users u ON u.id = s.user_id
+			  WHERE s.day = (SELECT MAX(day) FROM author_stats)
+			  ` + orderBy + `, u.username
+			  LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list author stats")
+	}
+	defer rows.Close()
+
+	return scanAuthorStats(rows)
+}
+
+// CountLatest counts the authors of the latest snapshot day
+func (r *authorStatsRepository) CountLatest() (int, error) {
+	query := `SELECT COUNT(*) FROM author_stats WHERE day = (SELECT MAX(day) FROM author_stats)`
+
+	var count int
+	if err := r.db.QueryRow(query).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count author stats")
+	}
+
+	return count, nil
+}
+
+// scanAuthorStats scans rows of authorStatsColumns followed by the username
+func scanAuthorStats(rows *sql.Rows) ([]*models.AuthorStats, error) {
+	var snapshots []*models.AuthorStats
+	for rows.Next() {
+		stats := &models.AuthorStats{}
+		err := rows.Scan(
+			&stats.UserID, &stats.Day, &stats.Posts, &stats.PublishedPosts, &stats.Views, &stats.Likes,
+			&stats.Reactions, &stats.Followers, &stats.FollowerGrowth, &stats.ComputedAt, &stats.Username,
+		)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan author stats")
+		}
+		snapshots = append(snapshots, stats)
+	}
+
+	return snapshots, nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// billingRepository implements BillingRepository interface
+type billingRepository struct {
+	db *sql.DB
+}
+
+// NewBillingRepository creates a new billing repository
+func NewBillingRepository(db *sql.DB) models.BillingRepository {
+	return &billingRepository{db: db}
+}
+
+// subscriptionColumns are the columns of a subscription, in the order of subscriptionFields
+const subscriptionColumns = `id, user_id, customer_id, status, price_id, cancel_at_period_end, current_period_end, event_at, created_at, updated_at`
+
+// subscriptionFields returns the scan destinations of subscriptionColumns in sub
+func subscriptionFields(sub *models.Subscription, priceID *sql.NullString) []interface{} {
+	return []interface{}{&sub.ID, &sub.UserID, &sub.CustomerID, &sub.Status, priceID, &sub.CancelAtPeriodEnd,
+		&sub.CurrentPeriodEnd, &sub.EventAt, &sub.CreatedAt, &sub.UpdatedAt}
+}
+
+// SaveSubscription creates or updates a subscription, unless a newer event already updated it
+func (r *billingRepository) SaveSubscription(sub *models.Subscription) (bool, error) {
+	query := `INSERT INTO subscriptions (id, user_id, customer_id, status, price_id, cancel_at_period_end, current_period_end, event_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, $9, $9)
+			  ON CONFLICT (id) DO UPDATE SET status = EXCLUDED.status, price_id = EXCLUDED.price_id,
+			      cancel_at_period_end = EXCLUDED.cancel_at_period_end, current_period_end = EXCLUDED.current_period_end,
+			      event_at = EXCLUDED.event_at, updated_at = EXCLUDED.updated_at
+			  WHERE subscriptions.event_at <= EXCLUDED.event_at`
+
+	result, err := r.db.Exec(query, sub.ID, sub.UserID, sub.CustomerID, sub.Status, sub.PriceID, sub.CancelAtPeriodEnd,
+		sub.CurrentPeriodEnd, sub.EventAt, sub.UpdatedAt)
+	if err != nil {
+		return false, writeError(err, "Failed to save subscription")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to save subscription")
+	}
+
+	return affected > 0, nil
+}
+
+// ListSubscriptionsByUser gets the subscriptions of a user, newest first
+func (r *billingRepository) ListSubscriptionsByUser(userID uuid.UUID) ([]*models.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE user_id = $1 ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list subscriptions")
+	}
+	defer rows.Close()
+
+	subs := []*models.Subscription{}
+	for rows.Next() {
+		sub := &models.Subscription{}
+		var priceID sql.NullString
+		if err := rows.Scan(subscriptionFields(sub, &priceID)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan subscription")
+		}
+		sub.PriceID = priceID.String
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// GetUserIDByCustomer finds the user of the subscriptions of a billing customer
+func (r *billingRepository) GetUserIDByCustomer(customerID string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	query := `SELECT user_id FROM subscriptions WHERE customer_id = $1 ORDER BY created_at DESC LIMIT 1`
+
+	err := r.db.QueryRow(query, customerID).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return uuid.Nil, models.ErrNotFound
+		}
+		return uuid.Nil, errors.WrapError(err, "Failed to get user by customer")
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// breakGlassRepository implements BreakGlassRepository interface
+type breakGlassRepository struct {
+	db *sql.DB
+}
+
+// NewBreakGlassRepository creates a new break-glass account repository
+func NewBreakGlassRepository(db *sql.DB) models.BreakGlassRepository {
+	return &breakGlassRepository{db: db}
+}
+
+// Provision creates a break-glass account; provisioning it again replaces the credential and
+// disables the account
+func (r *breakGlassRepository) Provision(account *models.BreakGlassAccount) error {
+	query := `INSERT INTO break_glass_accounts (user_id, credential_hash, created_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id) DO UPDATE SET credential_hash = EXCLUDED.credential_hash, enabled_until = NULL, enabled_by = NULL
+			  RETURNING created_at`
+
+	err := r.db.QueryRow(query, account.UserID, account.CredentialHash, account.CreatedAt).Scan(&account.CreatedAt)
+	if err != nil {
+		return writeError(err, "Failed to provision break-glass account")
+	}
+	account.EnabledUntil, account.EnabledBy = nil, nil
+
+	return nil
+}
+
+// GetByUserID gets the break-glass account of a user
+func (r *breakGlassRepository) GetByUserID(userID uuid.UUID) (*models.BreakGlassAccount, error) {
+	query := `SELECT user_id, credential_hash, enabled_until, enabled_by, last_used_at, created_at
+			  FROM break_glass_accounts WHERE user_id = $1`
+
+	account := &models.BreakGlassAccount{}
+	err := r.db.QueryRow(query, userID).Scan(&account.UserID, &account.CredentialHash, &account.EnabledUntil, &account.EnabledBy, &account.LastUsedAt, &account.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get break-glass account")
+	}
+
+	return account, nil
+}
+
+// List gets every break-glass account, oldest first
+func (r *breakGlassRepository) List() ([]*models.BreakGlassAccount, error) {
+	query := `SELECT user_id, credential_hash, enabled_until, enabled_by, last_used_at, created_at
+			  FROM break_glass_accounts ORDER BY created_at`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list break-glass accounts")
+	}
+	defer rows.Close()
+
+	accounts := []*models.BreakGlassAccount{}
+	for rows.Next() {
+		account := &models.BreakGlassAccount{}
+		if err := rows.Scan(&account.UserID, &account.CredentialHash, &account.EnabledUntil, &account.EnabledBy, &account.LastUsedAt, &account.CreatedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan break-glass account")
+		}
+		accounts = append(accounts, account)
+	}
+
+	return accounts, nil
+}
+
+// Enable enables a break-glass account until the given time
+func (r *breakGlassRepository) Enable(userID uuid.UUID, until time.Time, enabledBy string) error {
+	result, err := r.db.Exec(`UPDATE break_glass_accounts SET enabled_until = $2, enabled_by = $3 WHERE user_id = $1`, userID, until, enabledBy)
+	if err != nil {
+		return errors.WrapError(err, "Failed to enable break-glass account")
+	}
+	return requireRowsAffected(result, "Failed to enable break-glass account")
+}
+
+// Disable disables a break-glass account
+func (r *breakGlassRepository) Disable(userID uuid.UUID) error {
+	result, err := r.db.Exec(`UPDATE break_glass_accounts SET enabled_until = NULL, enabled_by = NULL WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to disable break-glass account")
+	}
+	return requireRowsAffected(result, "Failed to disable break-glass account")
+}
+
+// MarkUsed records a sign-in with a break-glass account
+func (r *breakGlassRepository) MarkUsed(userID uuid.UUID, usedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE break_glass_accounts SET last_used_at = $2 WHERE user_id = $1`, userID, usedAt); err != nil {
+		return errors.WrapError(err, "Failed to record break-glass sign-in")
+	}
+	return nil
+}
+
+// DisableExpired disables the break-glass accounts enabled until now or earlier
+func (r *breakGlassRepository) DisableExpired(now time.Time) ([]uuid.UUID, error) {
+	query := `UPDATE break_glass_accounts SET enabled_until = NULL, enabled_by = NULL
+			  WHERE enabled_until <= $1 RETURNING user_id`
+
+	rows, err := r.db.Query(query, now)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to expire break-glass accounts")
+	}
+	defer rows.Close()
+
+	var userIDs []uuid.UUID
+	for rows.Next() {
+		var userID uuid.UUID
+		if err := rows.Scan(&userID); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan break-glass account")
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	return userIDs, nil
+}
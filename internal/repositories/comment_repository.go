@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/ids"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// commentColumns selects a comment (aliased c) with its author's username and reply count.
+// Queries using it join users u on the author.
+const commentColumns = `c.id, c.post_id, c.author_id, c.parent_comment_id, c.depth, c.content, c.created_at, c.updated_at,
+			  u.username, (SELECT COUNT(*) FROM comments r WHERE r.parent_comment_id = c.id)`
+
+// rowScanner is implemented by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// commentRepository implements CommentRepository interface
+type commentRepository struct {
+	db *sql.DB
+}
+
+// NewCommentRepository creates a new comment repository
+func NewCommentRepository(db *sql.DB) models.CommentRepository {
+	return &commentRepository{db: db}
+}
+
+// Create creates a new comment
+func (r *commentRepository) Create(comment *models.Comment) error {
+	if comment.ID == uuid.Nil {
+		comment.ID = ids.New()
+	}
+
+	query := `INSERT INTO comments (id, post_id, author_id, parent_comment_id, depth, content, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.db.Exec(query, comment.ID, comment.PostID, comment.AuthorID, comment.ParentID, comment.Depth, comment.Content,
+		comment.CreatedAt, comment.UpdatedAt)
+	if err != nil {
+		return writeError(err, "Failed to create comment")
+	}
+
+	return nil
+}
+
+// GetByID gets a comment by ID
+func (r *commentRepository) GetByID(id uuid.UUID) (*models.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+			  FROM comments c JOIN users u ON u.id = c.author_id
+			  WHERE c.id = $1`
+
+	comment, err := scanComment(r.db.QueryRow(query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get comment by ID")
+	}
+
+	return comment, nil
+}
+
+// ListByPost gets all comments of a post regardless of nesting
+func (r *commentRepository) ListByPost(postID uuid.UUID, limit, offset int) ([]*models.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+			  FROM comments c JOIN users u ON u.id = c.author_id
+			  WHERE c.post_id = $1
+			  ORDER BY c.created_at, c.id LIMIT $2 OFFSET $3`
+
+	return r.list("Failed to get comments", query, postID, limit, offset)
+}
+
+// CountByPost returns the total number of comments of a post
+func (r *commentRepository) CountByPost(postID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1`
+
+	err := r.db.QueryRow(query, postID).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count comments")
+	}
+
+	return count, nil
+}
+
+// ListTopLevel gets the comments of a post that are not replies
+func (r *commentRepository) ListTopLevel(postID uuid.UUID, limit, offset int) ([]*models.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+			  FROM comments c JOIN users u ON u.id = c.author_id
+			  WHERE c.post_id = $1 AND c.parent_comment_id IS NULL
+			  ORDER BY c.created_at, c.id LIMIT $2 OFFSET $3`
+
+	return r.list("Failed to get comments", query, postID, limit, offset)
+}
+
+// CountTopLevel returns the number of comments of a post that are not replies
+func (r *commentRepository) CountTopLevel(postID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE post_id = $1 AND parent_comment_id IS NULL`
+
+	err := r.db.QueryRow(query, postID).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count comments")
+	}
+
+	return count, nil
+}
+
+// ListReplies gets up to perParent of the earliest replies to each parent in one query
+func (r *commentRepository) ListReplies(parentIDs []uuid.UUID, perParent int) ([]*models.Comment, error) {
+	if len(parentIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `SELECT ` + commentColumns + `
+			  FROM (
+			      SELECT *, ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY created_at, id) AS position
+			      FROM comments WHERE parent_comment_id = ANY($1::uuid[])
+			  ) c JOIN users u ON u.id = c.author_id
+			  WHERE c.position <= $2
+			  ORDER BY c.created_at, c.id`
+
+	return r.list("Failed to get replies", query, uuidArray(parentIDs), perParent)
+}
+
+// ListRepliesOf gets a page of the direct replies to a comment
+func (r *commentRepository) ListRepliesOf(parentID uuid.UUID, limit, offset int) ([]*models.Comment, error) {
+	query := `SELECT ` + commentColumns + `
+			  FROM comments c JOIN users u ON u.id = c.author_id
+			  WHERE c.parent_comment_id = $1
+			  ORDER BY c.created_at, c.id LIMIT $2 OFFSET $3`
+
+	return r.list("Failed to get replies", query, parentID, limit, offset)
+}
+
+// list runs a query selecting commentColumns
+func (r *commentRepository) list(message, query string, args ...interface{}) ([]*models.Comment, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, message)
+	}
+	defer rows.Close()
+
+	var comments []*models.Comment
+	for rows.Next() {
+		comment, err := scanComment(rows)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan comment")
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
+
+// uuidArray converts IDs to a Postgres array parameter, used as $n::uuid[]
+func uuidArray(ids []uuid.UUID) interface{} {
+	values := make([]string, len(ids))
+	for i, id := range ids {
+		values[i] = id.String()
+	}
+	return pq.Array(values)
+}
+
+// scanComment scans a row selected with commentColumns
+func scanComment(row rowScanner) (*models.Comment, error) {
+	comment := &models.Comment{Author: &models.User{}}
+	var parentID uuid.NullUUID
+
+	err := row.Scan(
+		&comment.ID, &comment.PostID, &comment.AuthorID, &parentID, &comment.Depth, &comment.Content,
+		&comment.CreatedAt, &comment.UpdatedAt, &comment.Author.Username, &comment.ReplyCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Author.ID = comment.AuthorID
+	if parentID.Valid {
+		comment.ParentID = &parentID.UUID
+	}
+	return comment, nil
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// deletedRecordRepository implements DeletedRecordRepository interface
+type deletedRecordRepository struct {
+	db *sql.DB
+}
+
+// NewDeletedRecordRepository creates a new tombstone repository
+func NewDeletedRecordRepository(db *sql.DB) models.DeletedRecordRepository {
+	return &deletedRecordRepository{db: db}
+}
+
+// Prune deletes the tombstones of records deleted before cutoff
+func (r *deletedRecordRepository) Prune(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM deleted_records WHERE deleted_at < $1`, cutoff)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to prune tombstones")
+	}
+
+	pruned, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to prune tombstones")
+	}
+	return pruned, nil
+}
@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// digestRepository implements DigestRepository interface
+type digestRepository struct {
+	db *sql.DB
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(db *sql.DB) models.DigestRepository {
+	return &digestRepository{db: db}
+}
+
+// Subscribe creates a digest subscription; subscribing again keeps the existing schedule
+func (r *digestRepository) Subscribe(sub *models.DigestSubscription) error {
+	query := `INSERT INTO digest_subscriptions (user_id, next_send_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $3) ON CONFLICT (user_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, sub.UserID, sub.NextSendAt, sub.CreatedAt); err != nil {
+		return writeError(err, "Failed to subscribe to digest")
+	}
+
+	return nil
+}
+
+// GetByUser gets the digest subscription of a user
+func (r *digestRepository) GetByUser(userID uuid.UUID) (*models.DigestSubscription, error) {
+	return r.getOne(`SELECT `+digestSubscriptionColumns+` FROM digest_subscriptions WHERE user_id = $1`, userID)
+}
+
+// GetByUnsubscribeTokenHash gets the digest subscription whose latest digest carried a token
+func (r *digestRepository) GetByUnsubscribeTokenHash(tokenHash string) (*models.DigestSubscription, error) {
+	return r.getOne(`SELECT `+digestSubscriptionColumns+` FROM digest_subscriptions WHERE unsubscribe_token_hash = $1`, tokenHash)
+}
+
+// getOne runs a single-row digest subscription query
+func (r *digestRepository) getOne(query string, arg interface{}) (*models.DigestSubscription, error) {
+	sub := &models.DigestSubscription{}
+
+	err := r.db.QueryRow(query, arg).Scan(digestSubscriptionFields(sub)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get digest subscription")
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe deletes the digest subscription of a user
+func (r *digestRepository) Unsubscribe(userID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM digest_subscriptions WHERE user_id = $1`, userID); err != nil {
+		return errors.WrapError(err, "Failed to unsubscribe from digest")
+	}
+
+	return nil
+}
+
+// ListDue lists the digest subscriptions due at now, longest overdue first
+func (r *digestRepository) ListDue(now time.Time, limit int) ([]*models.DigestSubscription, error) {
+	query := `SELECT ` + digestSubscriptionColumns + ` FROM digest_subscriptions
+			  WHERE next_send_at <= $1 ORDER BY next_send_at, user_id LIMIT $2`
+
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list due digests")
+	}
+	defer rows.Close()
+
+	var subs []*models.DigestSubscription
+	for rows.Next() {
+		sub := &models.DigestSubscription{}
+		if err := rows.Scan(digestSubscriptionFields(sub)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan digest subscription")
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// TopPosts gets the most reacted to, then most viewed, posts of the authors a user follows
+func (r *digestRepository) TopPosts(userID uuid.UUID, since, until time.Time, limit int) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `, u.username
+			  FROM posts p
+			  JOIN follows f ON f.followee_id = p.author_id AND f.follower_id = $1
+			  JOIN users u ON u.id = p.author_id
+			  WHERE p.is_published = true AND p.archived_at IS NULL
+			  AND p.published_at >= $2 AND p.published_at < $3 AND ` + listedTo("p", "$1") + `
+			  ORDER BY (SELECT COUNT(*) FROM reactions r WHERE r.post_id = p.id) DESC, p.view_count DESC, p.published_at DESC
+			  LIMIT $4`
+
+	rows, err := r.db.Query(query, userID, since, until, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get digest posts")
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{Author: &models.User{}}
+		if err := rows.Scan(append(postFields(post), &post.Author.Username)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post")
+		}
+		post.Author.ID = post.AuthorID
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Update saves the schedule and unsubscribe token of a digest subscription
+func (r *digestRepository) Update(sub *models.DigestSubscription) error {
+	query := `UPDATE digest_subscriptions SET next_send_at = $1, last_sent_at = $2, unsubscribe_token_hash = $3, updated_at = $4
+			  WHERE user_id = $5`
+
+	result, err := r.db.Exec(query, sub.NextSendAt, sub.LastSentAt, sub.UnsubscribeTokenHash, sub.UpdatedAt, sub.UserID)
+	if err != nil {
+		return writeError(err, "Failed to update digest subscription")
+	}
+
+	return requireRowsAffected(result, "Failed to update digest subscription")
+}
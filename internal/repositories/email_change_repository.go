@@ -0,0 +1,102 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// emailChangeRepository implements EmailChangeRepository interface
+type emailChangeRepository struct {
+	db *sql.DB
+}
+
+// NewEmailChangeRepository creates a new email change repository
+func NewEmailChangeRepository(db *sql.DB) models.EmailChangeRepository {
+	return &emailChangeRepository{db: db}
+}
+
+// Create creates a new email change request
+func (r *emailChangeRepository) Create(req *models.EmailChangeRequest) error {
+	query := `INSERT INTO email_change_requests (user_id, old_email, new_email, token_hash, undo_token_hash, expires_at, undo_expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id`
+
+	err := r.db.QueryRow(query, req.UserID, req.OldEmail, req.NewEmail, req.TokenHash, req.UndoTokenHash, req.ExpiresAt, req.UndoExpiresAt, req.CreatedAt).Scan(&req.ID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create email change request")
+	}
+
+	return nil
+}
+
+// GetByTokenHash gets an email change request by its confirmation token hash
+func (r *emailChangeRepository) GetByTokenHash(tokenHash string) (*models.EmailChangeRequest, error) {
+	return r.getOne(`SELECT id, user_id, old_email, new_email, token_hash, undo_token_hash, expires_at, undo_expires_at, confirmed_at, cancelled_at, created_at
+			  FROM email_change_requests WHERE token_hash = $1`, tokenHash)
+}
+
+// GetByUndoTokenHash gets an email change request by its undo token hash
+func (r *emailChangeRepository) GetByUndoTokenHash(undoTokenHash string) (*models.EmailChangeRequest, error) {
+	return r.getOne(`SELECT id, user_id, old_email, new_email, token_hash, undo_token_hash, expires_at, undo_expires_at, confirmed_at, cancelled_at, created_at
+			  FROM email_change_requests WHERE undo_token_hash = $1`, undoTokenHash)
+}
+
+// getOne runs a single-row email change request query
+func (r *emailChangeRepository) getOne(query string, arg interface{}) (*models.EmailChangeRequest, error) {
+	req := &models.EmailChangeRequest{}
+
+	err := r.db.QueryRow(query, arg).Scan(
+		&req.ID, &req.UserID, &req.OldEmail, &req.NewEmail, &req.TokenHash, &req.UndoTokenHash,
+		&req.ExpiresAt, &req.UndoExpiresAt, &req.ConfirmedAt, &req.CancelledAt, &req.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get email change request")
+	}
+
+	return req, nil
+}
+
+// MarkConfirmed marks an email change request as confirmed
+func (r *emailChangeRepository) MarkConfirmed(id uuid.UUID, at time.Time) error {
+	query := `UPDATE email_change_requests SET confirmed_at = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, at, id)
+	if err != nil {
+		return errors.WrapError(err, "Failed to confirm email change request")
+	}
+
+	return nil
+}
+
+// MarkCancelled marks an email change request as cancelled
+func (r *emailChangeRepository) MarkCancelled(id uuid.UUID, at time.Time) error {
+	query := `UPDATE email_change_requests SET cancelled_at = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, at, id)
+	if err != nil {
+		return errors.WrapError(err, "Failed to cancel email change request")
+	}
+
+	return nil
+}
+
+// CancelPendingForUser cancels all pending email change requests of a user
+func (r *emailChangeRepository) CancelPendingForUser(userID uuid.UUID, at time.Time) error {
+	query := `UPDATE email_change_requests SET cancelled_at = $1
+			  WHERE user_id = $2 AND confirmed_at IS NULL AND cancelled_at IS NULL`
+
+	_, err := r.db.Exec(query, at, userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to cancel pending email change requests")
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// emailTemplateRepository implements EmailTemplateRepository interface
+type emailTemplateRepository struct {
+	db *sql.DB
+}
+
+// NewEmailTemplateRepository creates a new email template repository
+func NewEmailTemplateRepository(db *sql.DB) models.EmailTemplateRepository {
+	return &emailTemplateRepository{db: db}
+}
+
+// Create saves a new version of a template. Two admins saving at once conflict on the
+// version number, and one of them gets 409.
+func (r *emailTemplateRepository) Create(version *models.EmailTemplateVersion) error {
+	query := `INSERT INTO email_templates (name, version, subject, body, created_by, created_at)
+			  SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5 FROM email_templates WHERE name = $1
+			  RETURNING id, version`
+
+	err := r.db.QueryRow(query, version.Name, version.Subject, version.Body, version.CreatedBy, version.CreatedAt).
+		Scan(&version.ID, &version.Version)
+	if err != nil {
+		return writeError(err, "Failed to save email template")
+	}
+
+	return nil
+}
+
+// ListCurrent gets the latest version of each overridden template
+func (r *emailTemplateRepository) ListCurrent() ([]*models.EmailTemplateVersion, error) {
+	return r.list(`SELECT DISTINCT ON (name) ` + emailTemplateVersionColumns + ` FROM email_templates ORDER BY name, version DESC`)
+}
+
+// ListByName gets the versions of a template, newest first
+func (r *emailTemplateRepository) ListByName(name string) ([]*models.EmailTemplateVersion, error) {
+	return r.list(`SELECT `+emailTemplateVersionColumns+` FROM email_templates WHERE name = $1 ORDER BY version DESC`, name)
+}
+
+// list runs an email template listing query
+func (r *emailTemplateRepository) list(query string, args ...interface{}) ([]*models.EmailTemplateVersion, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list email templates")
+	}
+	defer rows.Close()
+
+	versions := []*models.EmailTemplateVersion{}
+	for rows.Next() {
+		version := &models.EmailTemplateVersion{}
+		if err := rows.Scan(emailTemplateVersionFields(version)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan email template")
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/lib/pq"
+)
+
+// pgUniqueViolation is the Postgres error code for unique constraint violations
+const pgUniqueViolation = "23505"
+
+// writeError wraps a failed write, translating unique constraint violations into 409 Conflict
+func writeError(err error, message string) error {
+	var pqErr *pq.Error
+	if stderrors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+		return errors.NewAppError(http.StatusConflict, "Resource already exists", fmt.Errorf("%w: %v", models.ErrDuplicate, err))
+	}
+	return errors.WrapError(err, message)
+}
+
+// requireRowsAffected returns models.ErrNotFound when a write matched no rows
+func requireRowsAffected(result sql.Result, message string) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, message)
+	}
+	if affected == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
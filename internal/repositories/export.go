@@ -0,0 +1,6 @@
+package repositories
+
+// exportBatchSize is how many rows an export reads per query. Exports walk a table in
+// (created_at, id) order with a keyset cursor, so memory use and query time stay bounded
+// however large the table is, and no connection is held while rows are written out.
+const exportBatchSize = 500
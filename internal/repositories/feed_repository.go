@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// feedRepository implements FeedRepository interface
+type feedRepository struct {
+	db *sql.DB
+}
+
+// NewFeedRepository creates a new feed repository
+func NewFeedRepository(db *sql.DB) models.FeedRepository {
+	return &feedRepository{db: db}
+}
+
+// feedListed is the condition under which the post aliased p may be in the feeds of its
+// author's followers, who may list both public and followers-only posts
+const feedListed = `p.is_published = true AND p.archived_at IS NULL AND p.visibility IN ('` +
+	models.PostVisibilityPublic + `', '` + models.PostVisibilityFollowers + `')`
+
+// trimFeeds deletes the entries of the selected users beyond the newest limit ones, in the
+// query parameter limit; users is a query selecting user_id
+func trimFeeds(users, limit string) string {
+	return `DELETE FROM feed_entries fe USING (
+			    SELECT user_id, post_id FROM (
+			        SELECT user_id, post_id,
+			               ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY published_at DESC, post_id DESC) AS rank
+			        FROM feed_entries WHERE user_id IN (` + users + `)
+			    ) ranked WHERE rank > ` + limit + `
+			) old
+			WHERE fe.user_id = old.user_id AND fe.post_id = old.post_id`
+}
+
+// FanOut stores a post in the feeds of every follower of its author, then trims those feeds
+// to their newest limit entries
+func (r *feedRepository) FanOut(postID, authorID uuid.UUID, publishedAt time.Time, limit int) error {
+	query := `INSERT INTO feed_entries (user_id, post_id, author_id, published_at)
+			  SELECT follower_id, $1, $2, $3 FROM follows WHERE followee_id = $2
+			  ON CONFLICT (user_id, post_id) DO UPDATE SET published_at = EXCLUDED.published_at`
+
+	if _, err := r.db.Exec(query, postID, authorID, publishedAt); err != nil {
+		return writeError(err, "Failed to fan out post")
+	}
+
+	trim := trimFeeds(`SELECT follower_id FROM follows WHERE followee_id = $1`, "$2")
+	if _, err := r.db.Exec(trim, authorID, limit); err != nil {
+		return writeError(err, "Failed to trim feeds")
+	}
+
+	return nil
+}
+
+// RemovePost removes a post from every feed
+func (r *feedRepository) RemovePost(postID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM feed_entries WHERE post_id = $1`, postID); err != nil {
+		return writeError(err, "Failed to remove post from feeds")
+	}
+
+	return nil
+}
+
+// Backfill stores the latest limit published posts of followeeID the follower may list in
+// their feed, then trims it to its newest limit entries
+func (r *feedRepository) Backfill(followerID, followeeID uuid.UUID, limit int) error {
+	query := `INSERT INTO feed_entries (user_id, post_id, author_id, published_at)
+			  SELECT $1, p.id, p.author_id, COALESCE(p.published_at, p.created_at)
+			  FROM posts p
+			  WHERE p.author_id = $2 AND ` + feedListed + `
+			  ORDER BY p.published_at DESC, p.id DESC
+			  LIMIT $3
+			  ON CONFLICT (user_id, post_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, followerID, followeeID, limit); err != nil {
+		return writeError(err, "Failed to backfill feed")
+	}
+
+	if _, err := r.db.Exec(trimFeeds("$1::uuid", "$2"), followerID, limit); err != nil {
+		return writeError(err, "Failed to trim feed")
+	}
+
+	return nil
+}
+
+// RemoveAuthor removes the posts of followeeID from the feed of followerID
+func (r *feedRepository) RemoveAuthor(followerID, followeeID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM feed_entries WHERE user_id = $1 AND author_id = $2`, followerID, followeeID); err != nil {
+		return writeError(err, "Failed to remove author from feed")
+	}
+
+	return nil
+}
+
+// Rebuild replaces every stored feed with the latest limit published posts of the authors its
+// user follows, in one transaction, returning how many entries it stored
+func (r *feedRepository) Rebuild(limit int) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM feed_entries`); err != nil {
+		return 0, errors.WrapError(err, "Failed to clear feeds")
+	}
+
+	query := `INSERT INTO feed_entries (user_id, post_id, author_id, published_at)
+			  SELECT follower_id, id, author_id, published_at FROM (
+			      SELECT f.follower_id, p.id, p.author_id, COALESCE(p.published_at, p.created_at) AS published_at,
+			             ROW_NUMBER() OVER (PARTITION BY f.follower_id
+			                                ORDER BY COALESCE(p.published_at, p.created_at) DESC, p.id DESC) AS rank
+			      FROM follows f
+			      JOIN posts p ON p.author_id = f.followee_id
+			      WHERE ` + feedListed + `
+			  ) ranked
+			  WHERE rank <= $1`
+
+	result, err := tx.Exec(query, limit)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to rebuild feeds")
+	}
+	entries, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to rebuild feeds")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapError(err, "Failed to commit transaction")
+	}
+
+	return entries, nil
+}
+
+// GetPosts gets a page of the posts of a user's stored feed, newest first. Posts are checked
+// again when read, so entries of posts since unpublished, archived, made private or whose
+// author was unfollowed are left out until the worker removes them.
+func (r *feedRepository) GetPosts(userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `
+			  FROM feed_entries fe
+			  JOIN posts p ON p.id = fe.post_id
+			  WHERE fe.user_id = $1 AND ` + feedListed + ` AND ` + listedTo("p", "$1") + `
+			  ORDER BY fe.published_at DESC, fe.post_id DESC
+			  LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.Query(query, userID, limit, offset)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get feed")
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		if err := rows.Scan(postFields(post)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post")
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Count counts the posts of a user's stored feed the user may still list, like GetPosts, so
+// the total matches the pages
+func (r *feedRepository) Count(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM feed_entries fe
+			  JOIN posts p ON p.id = fe.post_id
+			  WHERE fe.user_id = $1 AND ` + feedListed + ` AND ` + listedTo("p", "$1")
+
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count feed entries")
+	}
+
+	return count, nil
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// incidentModeRepository implements IncidentModeRepository interface
+type incidentModeRepository struct {
+	db *sql.DB
+}
+
+// NewIncidentModeRepository creates a new incident mode repository
+func NewIncidentModeRepository(db *sql.DB) models.IncidentModeRepository {
+	return &incidentModeRepository{db: db}
+}
+
+// Get returns the stored state of incident mode, disabled when its row does not exist
+func (r *incidentModeRepository) Get() (*models.IncidentMode, error) {
+	query := `SELECT enabled_at, enabled_by, revoked_before, revoked_users, revoked_sessions, revoked_clients FROM incident_mode`
+
+	mode := &models.IncidentMode{Enabled: true}
+	var enabledBy uuid.NullUUID
+	var revokedBefore sql.NullTime
+	var revokedUsers, revokedSessions, revokedClients sql.NullInt64
+	err := r.db.QueryRow(query).Scan(&mode.EnabledAt, &enabledBy, &revokedBefore, &revokedUsers, &revokedSessions, &revokedClients)
+	if err == sql.ErrNoRows {
+		return &models.IncidentMode{}, nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get incident mode")
+	}
+
+	if enabledBy.Valid {
+		mode.EnabledBy = &enabledBy.UUID
+	}
+	if revokedBefore.Valid {
+		mode.Revocation = &models.TokenRevocation{Cutoff: revokedBefore.Time, Users: revokedUsers.Int64, Sessions: revokedSessions.Int64, Clients: revokedClients.Int64}
+	}
+	return mode, nil
+}
+
+// Enable stores incident mode as enabled, replacing the state stored before
+func (r *incidentModeRepository) Enable(mode *models.IncidentMode) error {
+	query := `INSERT INTO incident_mode (id, enabled_at, enabled_by, revoked_before, revoked_users, revoked_sessions, revoked_clients)
+			  VALUES (true, $1, $2, $3, $4, $5, $6)
+			  ON CONFLICT (id) DO UPDATE SET enabled_at = EXCLUDED.enabled_at, enabled_by = EXCLUDED.enabled_by,
+			  revoked_before = EXCLUDED.revoked_before, revoked_users = EXCLUDED.revoked_users, revoked_sessions = EXCLUDED.revoked_sessions,
+			  revoked_clients = EXCLUDED.revoked_clients`
+
+	var revokedBefore sql.NullTime
+	var revokedUsers, revokedSessions, revokedClients sql.NullInt64
+	if mode.Revocation != nil {
+		revokedBefore = sql.NullTime{Time: mode.Revocation.Cutoff, Valid: true}
+		revokedUsers = sql.NullInt64{Int64: mode.Revocation.Users, Valid: true}
+		revokedSessions = sql.NullInt64{Int64: mode.Revocation.Sessions, Valid: true}
+		revokedClients = sql.NullInt64{Int64: mode.Revocation.Clients, Valid: true}
+	}
+	if _, err := r.db.Exec(query, mode.EnabledAt, mode.EnabledBy, revokedBefore, revokedUsers, revokedSessions, revokedClients); err != nil {
+		return errors.WrapError(err, "Failed to enable incident mode")
+	}
+	return nil
+}
+
+// Disable removes the stored state of incident mode
+func (r *incidentModeRepository) Disable() error {
+	if _, err := r.db.Exec(`DELETE FROM incident_mode`); err != nil {
+		return errors.WrapError(err, "Failed to disable incident mode")
+	}
+	return nil
+}
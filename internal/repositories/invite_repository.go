@@ -0,0 +1,193 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// inviteRepository implements InviteRepository interface
+type inviteRepository struct {
+	db *sql.DB
+}
+
+// NewInviteRepository creates a new invite repository
+func NewInviteRepository(db *sql.DB) models.InviteRepository {
+	return &inviteRepository{db: db}
+}
+
+// Create creates a new invite
+func (r *inviteRepository) Create(invite *models.Invite) error {
+	query := `INSERT INTO invites (code_hash, created_by, email, max_uses, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := r.db.QueryRow(query, invite.CodeHash, invite.CreatedBy, invite.Email, invite.MaxUses, invite.ExpiresAt, invite.CreatedAt).Scan(&invite.ID)
+	if err != nil {
+		return writeError(err, "Failed to create invite")
+	}
+
+	return nil
+}
+
+// GetByID gets an invite by ID
+func (r *inviteRepository) GetByID(id uuid.UUID) (*models.Invite, error) {
+	return r.getOne(`SELECT `+inviteColumns+` FROM invites WHERE id = $1`, id)
+}
+
+// GetByCodeHash gets an invite by the hash of its code
+func (r *inviteRepository) GetByCodeHash(codeHash string) (*models.Invite, error) {
+	return r.getOne(`SELECT `+inviteColumns+` FROM invites WHERE code_hash = $1`, codeHash)
+}
+
+// getOne runs a single-row invite query
+func (r *inviteRepository) getOne(query string, arg interface{}) (*models.Invite, error) {
+	invite := &models.Invite{}
+
+	err := r.db.QueryRow(query, arg).Scan(inviteFields(invite)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get invite")
+	}
+
+	return invite, nil
+}
+
+// List lists invites with pagination, newest first
+func (r *inviteRepository) List(limit, offset int) ([]*models.Invite, error) {
+	return r.list(`SELECT `+inviteColumns+` FROM invites ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+}
+
+// Count counts all invites
+func (r *inviteRepository) Count() (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM invites`
+
+	err := r.db.QueryRow(query).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count invites")
+	}
+
+	return count, nil
+}
+
+// ListByCreator lists the invites created by a user, newest first
+func (r *inviteRepository) ListByCreator(userID uuid.UUID) ([]*models.Invite, error) {
+	return r.list(`SELECT `+inviteColumns+` FROM invites WHERE created_by = $1 ORDER BY created_at DESC`, userID)
+}
+
+// list runs a multi-row invite query
+func (r *inviteRepository) list(query string, args ...interface{}) ([]*models.Invite, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list invites")
+	}
+	defer rows.Close()
+
+	var invites []*models.Invite
+	for rows.Next() {
+		invite := &models.Invite{}
+		err := rows.Scan(inviteFields(invite)...)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan invite")
+		}
+		invites = append(invites, invite)
+	}
+
+	return invites, nil
+}
+
+// CountByCreator counts the invites created by a user
+func (r *inviteRepository) CountByCreator(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM invites WHERE created_by = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count invites")
+	}
+
+	return count, nil
+}
+
+// Claim atomically takes one use of an invite. It returns false if the invite is no longer usable.
+func (r *inviteRepository) Claim(id uuid.UUID) (bool, error) {
+	query := `UPDATE invites SET use_count = use_count + 1
+			  WHERE id = $1 AND use_count < max_uses AND revoked_at IS NULL AND expires_at > NOW()`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to claim invite")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to claim invite")
+	}
+
+	return affected > 0, nil
+}
+
+// Release gives back a use taken by Claim when registration did not complete
+func (r *inviteRepository) Release(id uuid.UUID) error {
+	query := `UPDATE invites SET use_count = use_count - 1 WHERE id = $1 AND use_count > 0`
+
+	_, err := r.db.Exec(query, id)
+	if err != nil {
+		return errors.WrapError(err, "Failed to release invite")
+	}
+
+	return nil
+}
+
+// RecordUse records which user registered with an invite
+func (r *inviteRepository) RecordUse(inviteID, userID uuid.UUID) error {
+	query := `INSERT INTO invite_uses (invite_id, user_id, used_at) VALUES ($1, $2, $3)`
+
+	_, err := r.db.Exec(query, inviteID, userID, time.Now())
+	if err != nil {
+		return errors.WrapError(err, "Failed to record invite use")
+	}
+
+	return nil
+}
+
+// GetUses gets the registrations made with an invite
+func (r *inviteRepository) GetUses(inviteID uuid.UUID) ([]*models.InviteUse, error) {
+	query := `SELECT invite_id, user_id, used_at FROM invite_uses WHERE invite_id = $1 ORDER BY used_at ASC`
+
+	rows, err := r.db.Query(query, inviteID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get invite uses")
+	}
+	defer rows.Close()
+
+	var uses []*models.InviteUse
+	for rows.Next() {
+		use := &models.InviteUse{}
+		if err := rows.Scan(&use.InviteID, &use.UserID, &use.UsedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan invite use")
+		}
+		uses = append(uses, use)
+	}
+
+	return uses, nil
+}
+
+// Revoke revokes an invite so it can no longer be redeemed
+func (r *inviteRepository) Revoke(id uuid.UUID) error {
+	query := `UPDATE invites SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to revoke invite")
+	}
+
+	return requireRowsAffected(result, "Failed to revoke invite")
+}
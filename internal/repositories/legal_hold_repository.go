@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// legalHoldRepository implements LegalHoldRepository interface
+type legalHoldRepository struct {
+	db *sql.DB
+}
+
+// NewLegalHoldRepository creates a new legal hold repository
+func NewLegalHoldRepository(db *sql.DB) models.LegalHoldRepository {
+	return &legalHoldRepository{db: db}
+}
+
+// Place places a legal hold; placing it again updates the reason but keeps who placed it and when
+func (r *legalHoldRepository) Place(hold *models.LegalHold) error {
+	query := `INSERT INTO legal_holds (user_id, reason, placed_by, placed_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id) DO UPDATE SET reason = EXCLUDED.reason
+			  RETURNING placed_by, placed_at`
+
+	err := r.db.QueryRow(query, hold.UserID, hold.Reason, hold.PlacedBy, hold.PlacedAt).Scan(&hold.PlacedBy, &hold.PlacedAt)
+	if err != nil {
+		return writeError(err, "Failed to place legal hold")
+	}
+
+	return nil
+}
+
+// GetByUserID gets the legal hold of a user
+func (r *legalHoldRepository) GetByUserID(userID uuid.UUID) (*models.LegalHold, error) {
+	query := `SELECT user_id, reason, placed_by, placed_at FROM legal_holds WHERE user_id = $1`
+
+	hold := &models.LegalHold{}
+	err := r.db.QueryRow(query, userID).Scan(&hold.UserID, &hold.Reason, &hold.PlacedBy, &hold.PlacedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get legal hold")
+	}
+
+	return hold, nil
+}
+
+// List gets every legal hold, newest first
+func (r *legalHoldRepository) List() ([]*models.LegalHold, error) {
+	query := `SELECT user_id, reason, placed_by, placed_at FROM legal_holds ORDER BY placed_at DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list legal holds")
+	}
+	defer rows.Close()
+
+	holds := []*models.LegalHold{}
+	for rows.Next() {
+		hold := &models.LegalHold{}
+		if err := rows.Scan(&hold.UserID, &hold.Reason, &hold.PlacedBy, &hold.PlacedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan legal hold")
+		}
+		holds = append(holds, hold)
+	}
+
+	return holds, nil
+}
+
+// Clear removes the legal hold of a user
+func (r *legalHoldRepository) Clear(userID uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM legal_holds WHERE user_id = $1`, userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to clear legal hold")
+	}
+	return requireRowsAffected(result, "Failed to clear legal hold")
+}
+
+// IsHeld checks if a user is under legal hold
+func (r *legalHoldRepository) IsHeld(userID uuid.UUID) (bool, error) {
+	var held bool
+	err := r.db.QueryRow(`SELECT EXISTS(SELECT 1 FROM legal_holds WHERE user_id = $1)`, userID).Scan(&held)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check legal hold")
+	}
+	return held, nil
+}
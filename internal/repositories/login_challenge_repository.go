@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// loginChallengeRepository implements LoginChallengeRepository interface
+type loginChallengeRepository struct {
+	db *sql.DB
+}
+
+// NewLoginChallengeRepository creates a new login challenge repository
+func NewLoginChallengeRepository(db *sql.DB) models.LoginChallengeRepository {
+	return &loginChallengeRepository{db: db}
+}
+
+// Create creates a new login challenge
+func (r *loginChallengeRepository) Create(challenge *models.LoginChallenge) error {
+	query := `INSERT INTO login_challenges (user_id, code_hash, ip_address, expires_at, created_at)
+			  VALUES ($1, $2, $3::inet, $4, $5) RETURNING id`
+
+	err := r.db.QueryRow(query, challenge.UserID, challenge.CodeHash, challenge.IPAddress, challenge.ExpiresAt, challenge.CreatedAt).Scan(&challenge.ID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to create login challenge")
+	}
+
+	return nil
+}
+
+// GetByID gets a login challenge by ID
+func (r *loginChallengeRepository) GetByID(id uuid.UUID) (*models.LoginChallenge, error) {
+	challenge := &models.LoginChallenge{}
+	query := `SELECT id, user_id, code_hash, HOST(ip_address), attempts, expires_at, consumed_at, created_at
+			  FROM login_challenges WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(
+		&challenge.ID, &challenge.UserID, &challenge.CodeHash, &challenge.IPAddress,
+		&challenge.Attempts, &challenge.ExpiresAt, &challenge.ConsumedAt, &challenge.CreatedAt,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get login challenge")
+	}
+
+	return challenge, nil
+}
+
+// UseAttempt counts a verification attempt in the same statement that checks the limit, so
+// concurrent guesses cannot each read a count below it
+func (r *loginChallengeRepository) UseAttempt(id uuid.UUID, maxAttempts int, now time.Time) (bool, error) {
+	query := `UPDATE login_challenges SET attempts = attempts + 1
+			  WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL AND expires_at > $3`
+
+	result, err := r.db.Exec(query, id, maxAttempts, now)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to update login challenge")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to update login challenge")
+	}
+
+	return affected > 0, nil
+}
+
+// Consume atomically marks a challenge as used. It returns false if it was already consumed.
+func (r *loginChallengeRepository) Consume(id uuid.UUID) (bool, error) {
+	query := `UPDATE login_challenges SET consumed_at = $1 WHERE id = $2 AND consumed_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to consume login challenge")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to consume login challenge")
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,62 @@
+//go:build integration
+
+package repositories
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/testutil"
+)
+
+func TestLoginChallengeAttemptsAreCountedAtomically(t *testing.T) {
+	db := testutil.DB(t)
+	user := newTestUser(true, false, nil)
+	if err := NewUserRepository(db, nil).Create(user); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	repo := NewLoginChallengeRepository(db)
+	now := time.Now().UTC()
+	challenge := &models.LoginChallenge{UserID: user.ID, CodeHash: "hash", ExpiresAt: now.Add(10 * time.Minute), CreatedAt: now}
+	if err := repo.Create(challenge); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.UseAttempt(challenge.ID, 5, now)
+			if err != nil {
+				t.Errorf("UseAttempt: %v", err)
+			}
+			if ok {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := allowed.Load(); got != 5 {
+		t.Errorf("%d parallel attempts allowed, want 5", got)
+	}
+
+	// Expired and consumed challenges allow no attempt
+	other := &models.LoginChallenge{UserID: user.ID, CodeHash: "hash", ExpiresAt: now.Add(10 * time.Minute), CreatedAt: now}
+	if err := repo.Create(other); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if ok, err := repo.UseAttempt(other.ID, 5, now.Add(time.Hour)); err != nil || ok {
+		t.Errorf("expired challenge: got %v, %v; want no attempt", ok, err)
+	}
+	if consumed, err := repo.Consume(other.ID); err != nil || !consumed {
+		t.Fatalf("Consume: %v, %v", consumed, err)
+	}
+	if ok, err := repo.UseAttempt(other.ID, 5, now); err != nil || ok {
+		t.Errorf("consumed challenge: got %v, %v; want no attempt", ok, err)
+	}
+}
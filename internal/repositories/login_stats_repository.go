@@ -0,0 +1,156 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// loginStatsRepository implements LoginStatsRepository interface
+type loginStatsRepository struct {
+	db *sql.DB
+}
+
+// NewLoginStatsRepository creates a new login stats repository
+func NewLoginStatsRepository(db *sql.DB) models.LoginStatsRepository {
+	return &loginStatsRepository{db: db}
+}
+
+// Rollup aggregates login audit logs into hourly statistics
+func (r *loginStatsRepository) Rollup() (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	// Serialize concurrent rollups (e.g. from several instances) while still allowing reads
+	if _, err := tx.Exec(`LOCK TABLE login_stats_hourly IN EXCLUSIVE MODE`); err != nil {
+		return 0, errors.WrapError(err, "Failed to lock login stats")
+	}
+
+	// The latest hour may have been rolled up while still in progress, so it is recomputed.
+	// Without any rows yet, all audit logs are rolled up.
+	var latest sql.NullTime
+	if err := tx.QueryRow(`SELECT MAX(hour) FROM login_stats_hourly`).Scan(&latest); err != nil {
+		return 0, errors.WrapError(err, "Failed to get latest login stats")
+	}
+	var since time.Time
+	if latest.Valid {
+		since = latest.Time
+	}
+
+	if _, err := tx.Exec(`DELETE FROM login_stats_hourly WHERE hour >= $1`, since); err != nil {
+		return 0, errors.WrapError(err, "Failed to clear login stats")
+	}
+
+	query := `INSERT INTO login_stats_hourly (hour, ip_address, user_id, successes, failures)
+			  SELECT date_trunc('hour', created_at), ip_address, user_id,
+			         COUNT(*) FILTER (WHERE action = $2), COUNT(*) FILTER (WHERE action = $3)
+			  FROM audit_logs
+			  WHERE action IN ($2, $3) AND created_at >= $1
+			  GROUP BY 1, 2, 3`
+
+	result, err := tx.Exec(query, since, models.AuditActionLoginSuccess, models.AuditActionLoginFailed)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to roll up login stats")
+	}
+	written, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to roll up login stats")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapError(err, "Failed to commit login stats")
+	}
+
+	return int(written), nil
+}
+
+// Hourly gets the attempt counts of every hour with attempts in [from, to)
+func (r *loginStatsRepository) Hourly(from, to time.Time) ([]*models.LoginHourStats, error) {
+	query := `SELECT hour, SUM(successes), SUM(failures)
+			  FROM login_stats_hourly WHERE hour >= $1 AND hour < $2
+			  GROUP BY hour ORDER BY hour`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get hourly login stats")
+	}
+	defer rows.Close()
+
+	var hours []*models.LoginHourStats
+	for rows.Next() {
+		hour := &models.LoginHourStats{}
+		if err := rows.Scan(&hour.Hour, &hour.Successes, &hour.Failures); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan login stats")
+		}
+		hours = append(hours, hour)
+	}
+
+	return hours, nil
+}
+
+// TopIPs gets the client IPs with the most failed attempts in [from, to)
+func (r *loginStatsRepository) TopIPs(from, to time.Time, limit int) ([]*models.LoginIPStats, error) {
+	query := `SELECT HOST(ip_address), SUM(successes), SUM(failures), COUNT(DISTINCT user_id), MAX(hour)
+			  FROM login_stats_hourly
+			  WHERE hour >= $1 AND hour < $2 AND ip_address IS NOT NULL
+			  GROUP BY ip_address
+			  HAVING SUM(failures) > 0
+			  ORDER BY SUM(failures) DESC, SUM(successes), ip_address
+			  LIMIT $3`
+
+	rows, err := r.db.Query(query, from, to, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get login stats by IP")
+	}
+	defer rows.Close()
+
+	var ips []*models.LoginIPStats
+	for rows.Next() {
+		ip := &models.LoginIPStats{}
+		if err := rows.Scan(&ip.IPAddress, &ip.Successes, &ip.Failures, &ip.Users, &ip.LastHour); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan login stats")
+		}
+		ips = append(ips, ip)
+	}
+
+	return ips, nil
+}
+
+// TopUsers gets the accounts with the most failed attempts in [from, to)
+func (r *loginStatsRepository) TopUsers(from, to time.Time, limit int) ([]*models.LoginUserStats, error) {
+	query := `SELECT s.user_id, u.username, SUM(s.successes), SUM(s.failures), COUNT(DISTINCT s.ip_address)
+			  FROM login_stats_hourly s
+			  JOIN users u ON u.id = s.user_id
+			  WHERE s.hour >= $1 AND s.hour < $2
+			  GROUP BY s.user_id, u.username
+			  HAVING SUM(s.failures) > 0
+			  ORDER BY SUM(s.failures) DESC, u.username
+			  LIMIT $3`
+
+	rows, err := r.db.Query(query, from, to, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get login stats by user")
+	}
+	defer rows.Close()
+
+	var users []*models.LoginUserStats
+	for rows.Next() {
+		user := &models.LoginUserStats{}
+		if err := rows.Scan(&user.UserID, &user.Username, &user.Successes, &user.Failures, &user.IPs); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan login stats")
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
@@ -0,0 +1,13 @@
+package repositories
+
+import "strings"
+
+// Column lists and scan destinations of the entities repositories read whole are generated
+// from the db tags of their models, so that adding a field updates every query at once
+//go:generate go run ../../cmd/mapgen -models ../models -types User,Post,APIKey,Invite,OAuthClient,PolicyDocument,SavedSearch,DigestSubscription,EmailTemplateVersion,PushDevice,PushSubscription,Announcement -out mapping_gen.go
+
+// qualify prefixes every column of a generated column list with a table alias, for queries
+// that join other tables
+func qualify(alias, columns string) string {
+	return alias + "." + strings.ReplaceAll(columns, ", ", ", "+alias+".")
+}
@@ -0,0 +1,234 @@
+// Code generated by cmd/mapgen from the db tags of internal/models; DO NOT EDIT.
+
+package repositories
+
+import (
+	"go-backend-api/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// userColumns are the columns models.User is mapped to, in the order of userFields
+const userColumns = `id, username, email, pending_email, email_undeliverable, email_undeliverable_at, password, role, plan, is_active, deactivated_at, must_change_password, last_login, last_seen_at, inactivity_warned_at, phone_number, phone_number_index, birthdate, parental_consent_at, created_at, updated_at`
+
+// userFields returns the scan destinations of userColumns in user
+func userFields(user *models.User) []interface{} {
+	return []interface{}{
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.PendingEmail,
+		&user.EmailUndeliverable,
+		&user.EmailUndeliverableAt,
+		&user.Password,
+		&user.Role,
+		&user.Plan,
+		&user.IsActive,
+		&user.DeactivatedAt,
+		&user.MustChangePassword,
+		&user.LastLogin,
+		&user.LastSeenAt,
+		&user.InactivityWarnedAt,
+		&user.PhoneNumber,
+		&user.PhoneNumberIndex,
+		&user.Birthdate,
+		&user.ParentalConsentAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+	}
+}
+
+// postColumns are the columns models.Post is mapped to, in the order of postFields
+const postColumns = `id, number, title, content, excerpt, reading_time_minutes, content_format, content_sanitized, language, author_id, is_published, visibility, archived_at, scheduled_at, published_at, created_at, updated_at`
+
+// postFields returns the scan destinations of postColumns in post
+func postFields(post *models.Post) []interface{} {
+	return []interface{}{
+		&post.ID,
+		&post.Number,
+		&post.Title,
+		&post.Content,
+		&post.Excerpt,
+		&post.ReadingTime,
+		&post.ContentFormat,
+		&post.ContentSanitized,
+		&post.Language,
+		&post.AuthorID,
+		&post.IsPublished,
+		&post.Visibility,
+		&post.ArchivedAt,
+		&post.ScheduledAt,
+		&post.PublishedAt,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+	}
+}
+
+// apiKeyColumns are the columns models.APIKey is mapped to, in the order of apiKeyFields
+const apiKeyColumns = `id, user_id, name, key_hash, hint, scopes, last_used_at, expires_at, revoked_at, created_at`
+
+// apiKeyFields returns the scan destinations of apiKeyColumns in apiKey
+func apiKeyFields(apiKey *models.APIKey) []interface{} {
+	return []interface{}{
+		&apiKey.ID,
+		&apiKey.UserID,
+		&apiKey.Name,
+		&apiKey.KeyHash,
+		&apiKey.Hint,
+		pq.Array(&apiKey.Scopes),
+		&apiKey.LastUsedAt,
+		&apiKey.ExpiresAt,
+		&apiKey.RevokedAt,
+		&apiKey.CreatedAt,
+	}
+}
+
+// inviteColumns are the columns models.Invite is mapped to, in the order of inviteFields
+const inviteColumns = `id, code_hash, created_by, email, max_uses, use_count, expires_at, revoked_at, created_at`
+
+// inviteFields returns the scan destinations of inviteColumns in invite
+func inviteFields(invite *models.Invite) []interface{} {
+	return []interface{}{
+		&invite.ID,
+		&invite.CodeHash,
+		&invite.CreatedBy,
+		&invite.Email,
+		&invite.MaxUses,
+		&invite.UseCount,
+		&invite.ExpiresAt,
+		&invite.RevokedAt,
+		&invite.CreatedAt,
+	}
+}
+
+// oauthClientColumns are the columns models.OAuthClient is mapped to, in the order of oauthClientFields
+const oauthClientColumns = `id, client_id, secret_hash, name, scopes, created_by, last_used_at, revoked_at, tokens_denied_before, created_at`
+
+// oauthClientFields returns the scan destinations of oauthClientColumns in oauthClient
+func oauthClientFields(oauthClient *models.OAuthClient) []interface{} {
+	return []interface{}{
+		&oauthClient.ID,
+		&oauthClient.ClientID,
+		&oauthClient.SecretHash,
+		&oauthClient.Name,
+		pq.Array(&oauthClient.Scopes),
+		&oauthClient.CreatedBy,
+		&oauthClient.LastUsedAt,
+		&oauthClient.RevokedAt,
+		&oauthClient.TokensDeniedBefore,
+		&oauthClient.CreatedAt,
+	}
+}
+
+// policyDocumentColumns are the columns models.PolicyDocument is mapped to, in the order of policyDocumentFields
+const policyDocumentColumns = `id, kind, version, url, mandatory, published_by, published_at`
+
+// policyDocumentFields returns the scan destinations of policyDocumentColumns in policyDocument
+func policyDocumentFields(policyDocument *models.PolicyDocument) []interface{} {
+	return []interface{}{
+		&policyDocument.ID,
+		&policyDocument.Kind,
+		&policyDocument.Version,
+		&policyDocument.URL,
+		&policyDocument.Mandatory,
+		&policyDocument.PublishedBy,
+		&policyDocument.PublishedAt,
+	}
+}
+
+// savedSearchColumns are the columns models.SavedSearch is mapped to, in the order of savedSearchFields
+const savedSearchColumns = `id, user_id, name, query, frequency, checked_at, next_check_at, unsubscribe_token_hash, created_at, updated_at`
+
+// savedSearchFields returns the scan destinations of savedSearchColumns in savedSearch
+func savedSearchFields(savedSearch *models.SavedSearch) []interface{} {
+	return []interface{}{
+		&savedSearch.ID,
+		&savedSearch.UserID,
+		&savedSearch.Name,
+		&savedSearch.Query,
+		&savedSearch.Frequency,
+		&savedSearch.CheckedAt,
+		&savedSearch.NextCheckAt,
+		&savedSearch.UnsubscribeTokenHash,
+		&savedSearch.CreatedAt,
+		&savedSearch.UpdatedAt,
+	}
+}
+
+// digestSubscriptionColumns are the columns models.DigestSubscription is mapped to, in the order of digestSubscriptionFields
+const digestSubscriptionColumns = `user_id, next_send_at, last_sent_at, unsubscribe_token_hash, created_at, updated_at`
+
+// digestSubscriptionFields returns the scan destinations of digestSubscriptionColumns in digestSubscription
+func digestSubscriptionFields(digestSubscription *models.DigestSubscription) []interface{} {
+	return []interface{}{
+		&digestSubscription.UserID,
+		&digestSubscription.NextSendAt,
+		&digestSubscription.LastSentAt,
+		&digestSubscription.UnsubscribeTokenHash,
+		&digestSubscription.CreatedAt,
+		&digestSubscription.UpdatedAt,
+	}
+}
+
+// emailTemplateVersionColumns are the columns models.EmailTemplateVersion is mapped to, in the order of emailTemplateVersionFields
+const emailTemplateVersionColumns = `id, name, version, subject, body, created_by, created_at`
+
+// emailTemplateVersionFields returns the scan destinations of emailTemplateVersionColumns in emailTemplateVersion
+func emailTemplateVersionFields(emailTemplateVersion *models.EmailTemplateVersion) []interface{} {
+	return []interface{}{
+		&emailTemplateVersion.ID,
+		&emailTemplateVersion.Name,
+		&emailTemplateVersion.Version,
+		&emailTemplateVersion.Subject,
+		&emailTemplateVersion.Body,
+		&emailTemplateVersion.CreatedBy,
+		&emailTemplateVersion.CreatedAt,
+	}
+}
+
+// pushDeviceColumns are the columns models.PushDevice is mapped to, in the order of pushDeviceFields
+const pushDeviceColumns = `id, user_id, platform, token, created_at, updated_at`
+
+// pushDeviceFields returns the scan destinations of pushDeviceColumns in pushDevice
+func pushDeviceFields(pushDevice *models.PushDevice) []interface{} {
+	return []interface{}{
+		&pushDevice.ID,
+		&pushDevice.UserID,
+		&pushDevice.Platform,
+		&pushDevice.Token,
+		&pushDevice.CreatedAt,
+		&pushDevice.UpdatedAt,
+	}
+}
+
+// pushSubscriptionColumns are the columns models.PushSubscription is mapped to, in the order of pushSubscriptionFields
+const pushSubscriptionColumns = `user_id, topic, created_at`
+
+// pushSubscriptionFields returns the scan destinations of pushSubscriptionColumns in pushSubscription
+func pushSubscriptionFields(pushSubscription *models.PushSubscription) []interface{} {
+	return []interface{}{
+		&pushSubscription.UserID,
+		&pushSubscription.Topic,
+		&pushSubscription.CreatedAt,
+	}
+}
+
+// announcementColumns are the columns models.Announcement is mapped to, in the order of announcementFields
+const announcementColumns = `id, message, severity, dismissible, starts_at, ends_at, roles, plans, created_by, created_at, updated_at`
+
+// announcementFields returns the scan destinations of announcementColumns in announcement
+func announcementFields(announcement *models.Announcement) []interface{} {
+	return []interface{}{
+		&announcement.ID,
+		&announcement.Message,
+		&announcement.Severity,
+		&announcement.Dismissible,
+		&announcement.StartsAt,
+		&announcement.EndsAt,
+		pq.Array(&announcement.Roles),
+		pq.Array(&announcement.Plans),
+		&announcement.CreatedBy,
+		&announcement.CreatedAt,
+		&announcement.UpdatedAt,
+	}
+}
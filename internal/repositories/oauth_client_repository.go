@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// oauthClientRepository implements OAuthClientRepository interface
+type oauthClientRepository struct {
+	db *sql.DB
+}
+
+// NewOAuthClientRepository creates a new OAuth client repository
+func NewOAuthClientRepository(db *sql.DB) models.OAuthClientRepository {
+	return &oauthClientRepository{db: db}
+}
+
+// Create creates a new OAuth client
+func (r *oauthClientRepository) Create(client *models.OAuthClient) error {
+	query := `INSERT INTO oauth_clients (client_id, secret_hash, name, scopes, created_by, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := r.db.QueryRow(query, client.ClientID, client.SecretHash, client.Name, pq.Array(client.Scopes), client.CreatedBy, client.CreatedAt).Scan(&client.ID)
+	if err != nil {
+		return writeError(err, "Failed to create OAuth client")
+	}
+
+	return nil
+}
+
+// GetByID gets an OAuth client by ID
+func (r *oauthClientRepository) GetByID(id uuid.UUID) (*models.OAuthClient, error) {
+	return r.getOne(`SELECT `+oauthClientColumns+` FROM oauth_clients WHERE id = $1`, id)
+}
+
+// GetByClientID gets an OAuth client by its public client ID
+func (r *oauthClientRepository) GetByClientID(clientID string) (*models.OAuthClient, error) {
+	return r.getOne(`SELECT `+oauthClientColumns+` FROM oauth_clients WHERE client_id = $1`, clientID)
+}
+
+// getOne runs a single-row OAuth client query
+func (r *oauthClientRepository) getOne(query string, arg interface{}) (*models.OAuthClient, error) {
+	client := &models.OAuthClient{}
+
+	err := r.db.QueryRow(query, arg).Scan(oauthClientFields(client)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get OAuth client")
+	}
+
+	return client, nil
+}
+
+// List lists all OAuth clients, newest first
+func (r *oauthClientRepository) List() ([]*models.OAuthClient, error) {
+	query := `SELECT ` + oauthClientColumns + ` FROM oauth_clients ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list OAuth clients")
+	}
+	defer rows.Close()
+
+	var clients []*models.OAuthClient
+	for rows.Next() {
+		client := &models.OAuthClient{}
+		err := rows.Scan(oauthClientFields(client)...)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan OAuth client")
+		}
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+// Revoke revokes an OAuth client so it can no longer obtain or use tokens
+func (r *oauthClientRepository) Revoke(id uuid.UUID) error {
+	query := `UPDATE oauth_clients SET revoked_at = $1 WHERE id = $2 AND revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to revoke OAuth client")
+	}
+
+	return requireRowsAffected(result, "Failed to revoke OAuth client")
+}
+
+// TouchLastUsed records when an OAuth client last obtained a token
+func (r *oauthClientRepository) TouchLastUsed(id uuid.UUID, usedAt time.Time) error {
+	query := `UPDATE oauth_clients SET last_used_at = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, usedAt, id)
+	if err != nil {
+		return errors.WrapError(err, "Failed to update OAuth client last used time")
+	}
+
+	return nil
+}
+
+// DenyAllTokensIssuedBefore denies the access tokens issued at or before cutoff of every client
+// not revoked yet. A cutoff earlier than a client's current one leaves it unchanged.
+func (r *oauthClientRepository) DenyAllTokensIssuedBefore(cutoff time.Time) (int64, error) {
+	query := `UPDATE oauth_clients SET tokens_denied_before = GREATEST(COALESCE(tokens_denied_before, $1), $1)
+			  WHERE revoked_at IS NULL`
+
+	result, err := r.db.Exec(query, cutoff)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to deny OAuth client tokens")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to deny OAuth client tokens")
+	}
+	return affected, nil
+}
@@ -0,0 +1,93 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// parentalConsentRepository implements ParentalConsentRepository interface
+type parentalConsentRepository struct {
+	db *sql.DB
+}
+
+// NewParentalConsentRepository creates a new parental consent repository
+func NewParentalConsentRepository(db *sql.DB) models.ParentalConsentRepository {
+	return &parentalConsentRepository{db: db}
+}
+
+// Create creates a new parental consent request
+func (r *parentalConsentRepository) Create(consent *models.ParentalConsent) error {
+	query := `INSERT INTO parental_consents (user_id, parent_email, token_hash, expires_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+
+	err := r.db.QueryRow(query, consent.UserID, consent.ParentEmail, consent.TokenHash, consent.ExpiresAt, consent.CreatedAt).Scan(&consent.ID)
+	if err != nil {
+		return writeError(err, "Failed to create parental consent request")
+	}
+
+	return nil
+}
+
+// GetByTokenHash gets a parental consent request by its token hash
+func (r *parentalConsentRepository) GetByTokenHash(tokenHash string) (*models.ParentalConsent, error) {
+	query := `SELECT id, user_id, parent_email, token_hash, expires_at, confirmed_at, created_at
+			  FROM parental_consents WHERE token_hash = $1`
+
+	consent := &models.ParentalConsent{}
+	err := r.db.QueryRow(query, tokenHash).Scan(
+		&consent.ID, &consent.UserID, &consent.ParentEmail, &consent.TokenHash,
+		&consent.ExpiresAt, &consent.ConfirmedAt, &consent.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get parental consent request")
+	}
+
+	return consent, nil
+}
+
+// Confirm marks a pending request confirmed and sets parental_consent_at on its user in one
+// transaction; an already confirmed request is not found
+func (r *parentalConsentRepository) Confirm(id uuid.UUID, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	var userID uuid.UUID
+	err = tx.QueryRow(`UPDATE parental_consents SET confirmed_at = $1
+			  WHERE id = $2 AND confirmed_at IS NULL RETURNING user_id`, at, id).Scan(&userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ErrNotFound
+		}
+		return errors.WrapError(err, "Failed to confirm parental consent request")
+	}
+
+	result, err := tx.Exec(`UPDATE users SET parental_consent_at = $1, updated_at = $1 WHERE id = $2`, at, userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to record parental consent")
+	}
+	if err := requireRowsAffected(result, "Failed to record parental consent"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, "Failed to commit transaction")
+	}
+
+	return nil
+}
@@ -0,0 +1,282 @@
+package repositories
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// partitionSuffixLayout is the month suffix of partition names, as in audit_logs_2026_10
+const partitionSuffixLayout = "2006_01"
+
+// restoredComment is the table comment marking restored partitions
+const restoredComment = "restored from archive"
+
+// archiveTimeout bounds archiving or restoring a partition, which reads or writes a month
+// of rows and may take far longer than the statement timeout of regular queries
+const archiveTimeout = time.Hour
+
+// restoreBatchSize is how many archived rows are inserted per statement
+const restoreBatchSize = 1000
+
+// maxArchivedRow is the longest JSON line of an archived row that can be restored
+const maxArchivedRow = 16 << 20
+
+// restoreQueries insert a JSON array of archived rows into each partitioned table. Rows of
+// users deleted since they were archived are treated as the foreign keys would have: audit
+// logs lose their user and refresh tokens are skipped.
+var restoreQueries = map[string]string{
+	models.PartitionedAuditLogs: `INSERT INTO audit_logs (id, user_id, action, resource_type, resource_id, ip_address, user_agent, country, city, details, created_at)
+		SELECT r.id, u.id, r.action, r.resource_type, r.resource_id, r.ip_address, r.user_agent, r.country, r.city, r.details, r.created_at
+		FROM json_populate_recordset(NULL::audit_logs, $1::json) r
+		LEFT JOIN users u ON u.id = r.user_id
+		ON CONFLICT DO NOTHING`,
+	models.PartitionedRefreshTokens: `INSERT INTO refresh_tokens
+		SELECT r.* FROM json_populate_recordset(NULL::refresh_tokens, $1::json) r
+		WHERE EXISTS (SELECT 1 FROM users u WHERE u.id = r.user_id)
+		ON CONFLICT DO NOTHING`,
+}
+
+// partitionRepository implements PartitionRepository interface
+type partitionRepository struct {
+	db *sql.DB
+}
+
+// NewPartitionRepository creates a new partition repository
+func NewPartitionRepository(db *sql.DB) models.PartitionRepository {
+	return &partitionRepository{db: db}
+}
+
+// Create creates the partition of table holding month unless it exists
+func (r *partitionRepository) Create(table string, month time.Time) (string, error) {
+	var name string
+	err := r.db.QueryRow(`SELECT create_monthly_partition($1, $2::date)`, table, month.Format("2006-01-02")).Scan(&name)
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to create partition")
+	}
+	return name, nil
+}
+
+// List gets the monthly partitions of table, oldest first. Partitions not named after
+// their month, e.g. created by hand, are left out.
+func (r *partitionRepository) List(table string) ([]*models.Partition, error) {
+	query := `SELECT child.relname, COALESCE(obj_description(child.oid, 'pg_class') = $2, false)
+			  FROM pg_inherits i
+			  JOIN pg_class parent ON parent.oid = i.inhparent
+			  JOIN pg_class child ON child.oid = i.inhrelid
+			  WHERE parent.relname = $1 AND parent.relnamespace = current_schema()::regnamespace
+			  ORDER BY child.relname`
+
+	rows, err := r.db.Query(query, table, restoredComment)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list partitions")
+	}
+	defer rows.Close()
+
+	var partitions []*models.Partition
+	for rows.Next() {
+		var name string
+		var restored bool
+		if err := rows.Scan(&name, &restored); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan partition")
+		}
+		from, err := time.Parse(partitionSuffixLayout, strings.TrimPrefix(name, table+"_"))
+		if err != nil {
+			continue
+		}
+		partitions = append(partitions, &models.Partition{Table: table, Name: name, From: from, To: from.AddDate(0, 1, 0), Restored: restored})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, "Failed to list partitions")
+	}
+
+	return partitions, nil
+}
+
+// Drop drops a partition and the rows it holds
+func (r *partitionRepository) Drop(partition *models.Partition) error {
+	if _, err := r.db.Exec(fmt.Sprintf(`DROP TABLE IF EXISTS %s`, pq.QuoteIdentifier(partition.Name))); err != nil {
+		return errors.WrapError(err, "Failed to drop partition")
+	}
+	return nil
+}
+
+// HeldUserIDs gets the users under legal hold that rows of a partition belong to
+func (r *partitionRepository) HeldUserIDs(partition *models.Partition) ([]uuid.UUID, error) {
+	query := fmt.Sprintf(`SELECT DISTINCT p.user_id FROM %s p JOIN legal_holds h ON h.user_id = p.user_id`, pq.QuoteIdentifier(partition.Name))
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check legal holds of partition")
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan legal hold")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// beginLong starts a transaction for archiving or restoring, with the statement timeout
+// and query deadline of regular queries raised to archiveTimeout
+func (r *partitionRepository) beginLong() (*sql.Tx, context.Context, context.CancelFunc, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), archiveTimeout)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, errors.WrapError(err, "Failed to begin transaction")
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`SET LOCAL statement_timeout = %d`, archiveTimeout.Milliseconds())); err != nil {
+		_ = tx.Rollback()
+		cancel()
+		return nil, nil, nil, errors.WrapError(err, "Failed to raise statement timeout")
+	}
+	return tx, ctx, cancel, nil
+}
+
+// Archive writes the rows of a partition to w, stores them and drops the partition
+func (r *partitionRepository) Archive(partition *models.Partition, w io.Writer, store func() error) (int, error) {
+	tx, ctx, cancel, err := r.beginLong()
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	name := pq.QuoteIdentifier(partition.Name)
+	// Reads go on while the partition is exported; writes wait and, once it is dropped, fail
+	if _, err := tx.ExecContext(ctx, `LOCK TABLE `+name+` IN EXCLUSIVE MODE`); err != nil {
+		return 0, errors.WrapError(err, "Failed to lock partition")
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT row_to_json(p)::text FROM `+name+` p`)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to export partition")
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return count, errors.WrapError(err, "Failed to scan archived row")
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return count, errors.WrapError(err, "Failed to write archived row")
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, errors.WrapError(err, "Failed to export partition")
+	}
+	rows.Close()
+
+	if err := store(); err != nil {
+		return count, errors.WrapError(err, "Failed to store archive")
+	}
+	if _, err := tx.ExecContext(ctx, `DROP TABLE `+name); err != nil {
+		return count, errors.WrapError(err, "Failed to drop archived partition")
+	}
+	if err := tx.Commit(); err != nil {
+		return count, errors.WrapError(err, "Failed to commit transaction")
+	}
+
+	return count, nil
+}
+
+// Restore recreates a partition from archived rows
+func (r *partitionRepository) Restore(table string, month time.Time, archive io.Reader) (int, error) {
+	query, ok := restoreQueries[table]
+	if !ok {
+		return 0, fmt.Errorf("table %s is not partitioned", table)
+	}
+
+	tx, ctx, cancel, err := r.beginLong()
+	if err != nil {
+		return 0, err
+	}
+	defer cancel()
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	var name string
+	if err := tx.QueryRowContext(ctx, `SELECT create_monthly_partition($1, $2::date)`, table, month.Format("2006-01-02")).Scan(&name); err != nil {
+		return 0, errors.WrapError(err, "Failed to create partition")
+	}
+
+	count := 0
+	var batch []string
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if _, err := tx.ExecContext(ctx, query, "["+strings.Join(batch, ",")+"]"); err != nil {
+			return errors.WrapError(err, "Failed to restore archived rows")
+		}
+		count += len(batch)
+		batch = batch[:0]
+		return nil
+	}
+
+	scanner := bufio.NewScanner(archive)
+	scanner.Buffer(make([]byte, 64*1024), maxArchivedRow)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			batch = append(batch, line)
+		}
+		if len(batch) == restoreBatchSize {
+			if err := flush(); err != nil {
+				return count, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return count, errors.WrapError(err, "Failed to read archive")
+	}
+	if err := flush(); err != nil {
+		return count, err
+	}
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(`COMMENT ON TABLE %s IS %s`, pq.QuoteIdentifier(name), pq.QuoteLiteral(restoredComment))); err != nil {
+		return count, errors.WrapError(err, "Failed to mark partition restored")
+	}
+	if err := tx.Commit(); err != nil {
+		return count, errors.WrapError(err, "Failed to commit transaction")
+	}
+
+	return count, nil
+}
+
+// Release clears the restored mark of a partition
+func (r *partitionRepository) Release(table string, month time.Time) error {
+	name := pq.QuoteIdentifier(table + "_" + month.Format(partitionSuffixLayout))
+	if _, err := r.db.Exec(`COMMENT ON TABLE ` + name + ` IS NULL`); err != nil {
+		return errors.WrapError(err, "Failed to release partition")
+	}
+	return nil
+}
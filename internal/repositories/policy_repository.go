@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// pendingPolicies selects the latest mandatory version of each kind that the user ($1) has
+// accepted neither it nor a later version of
+const pendingPolicies = `SELECT ` + policyDocumentColumns + ` FROM (
+			  SELECT DISTINCT ON (kind) * FROM policy_documents WHERE mandatory ORDER BY kind, published_at DESC
+		  ) d
+		  WHERE NOT EXISTS (
+			  SELECT 1 FROM policy_acceptances a JOIN policy_documents accepted ON accepted.id = a.document_id
+			  WHERE a.user_id = $1 AND accepted.kind = d.kind AND accepted.published_at >= d.published_at
+		  )`
+
+// policyRepository implements PolicyRepository interface
+type policyRepository struct {
+	db *sql.DB
+}
+
+// NewPolicyRepository creates a new policy repository
+func NewPolicyRepository(db *sql.DB) models.PolicyRepository {
+	return &policyRepository{db: db}
+}
+
+// Create publishes a policy version
+func (r *policyRepository) Create(doc *models.PolicyDocument) error {
+	query := `INSERT INTO policy_documents (kind, version, url, mandatory, published_by, published_at)
+			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+
+	err := r.db.QueryRow(query, doc.Kind, doc.Version, doc.URL, doc.Mandatory, doc.PublishedBy, doc.PublishedAt).Scan(&doc.ID)
+	if err != nil {
+		return writeError(err, "Failed to publish policy")
+	}
+
+	return nil
+}
+
+// List gets every published version, newest first
+func (r *policyRepository) List() ([]*models.PolicyDocument, error) {
+	return r.list(`SELECT ` + policyDocumentColumns + ` FROM policy_documents ORDER BY published_at DESC`)
+}
+
+// ListCurrent gets the latest version of each kind
+func (r *policyRepository) ListCurrent() ([]*models.PolicyDocument, error) {
+	return r.list(`SELECT DISTINCT ON (kind) ` + policyDocumentColumns + ` FROM policy_documents ORDER BY kind, published_at DESC`)
+}
+
+// ListPending gets the mandatory versions a user has yet to accept
+func (r *policyRepository) ListPending(userID uuid.UUID) ([]*models.PolicyDocument, error) {
+	return r.list(pendingPolicies+` ORDER BY d.kind`, userID)
+}
+
+// HasPending reports whether a user has mandatory versions to accept
+func (r *policyRepository) HasPending(userID uuid.UUID) (bool, error) {
+	var pending bool
+	query := `SELECT EXISTS(` + pendingPolicies + `)`
+
+	err := r.db.QueryRow(query, userID).Scan(&pending)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check pending policies")
+	}
+
+	return pending, nil
+}
+
+// list runs a multi-row policy document query
+func (r *policyRepository) list(query string, args ...interface{}) ([]*models.PolicyDocument, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	defer rows.Close()
+
+	docs := []*models.PolicyDocument{}
+	for rows.Next() {
+		doc := &models.PolicyDocument{}
+		if err := rows.Scan(policyDocumentFields(doc)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan policy")
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs, nil
+}
+
+// ListAcceptances gets the versions a user accepted, newest first
+func (r *policyRepository) ListAcceptances(userID uuid.UUID) ([]*models.PolicyAcceptance, error) {
+	query := `SELECT d.id, d.kind, d.version, a.accepted_at
+			  FROM policy_acceptances a JOIN policy_documents d ON d.id = a.document_id
+			  WHERE a.user_id = $1 ORDER BY a.accepted_at DESC, d.kind`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policy acceptances")
+	}
+	defer rows.Close()
+
+	acceptances := []*models.PolicyAcceptance{}
+	for rows.Next() {
+		acceptance := &models.PolicyAcceptance{}
+		if err := rows.Scan(&acceptance.DocumentID, &acceptance.Kind, &acceptance.Version, &acceptance.AcceptedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan policy acceptance")
+		}
+		acceptances = append(acceptances, acceptance)
+	}
+
+	return acceptances, nil
+}
+
+// Accept records a user accepting policy versions; versions accepted before keep their
+// original acceptance time
+func (r *policyRepository) Accept(userID uuid.UUID, documentIDs []uuid.UUID, acceptedAt time.Time) error {
+	ids := make([]string, len(documentIDs))
+	for i, id := range documentIDs {
+		ids[i] = id.String()
+	}
+
+	query := `INSERT INTO policy_acceptances (user_id, document_id, accepted_at)
+			  SELECT $1, id, $3 FROM unnest($2::uuid[]) AS id
+			  ON CONFLICT (user_id, document_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, userID, pq.Array(ids), acceptedAt); err != nil {
+		return writeError(err, "Failed to record policy acceptance")
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// postAutosaveRepository implements PostAutosaveRepository interface
+type postAutosaveRepository struct {
+	db *sql.DB
+}
+
+// NewPostAutosaveRepository creates a new post autosave repository
+func NewPostAutosaveRepository(db *sql.DB) models.PostAutosaveRepository {
+	return &postAutosaveRepository{db: db}
+}
+
+// Save replaces the autosaved draft of a post
+func (r *postAutosaveRepository) Save(autosave *models.PostAutosave) error {
+	query := `INSERT INTO post_autosaves (post_id, title, content, saved_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (post_id) DO UPDATE
+			  SET title = EXCLUDED.title, content = EXCLUDED.content, saved_at = EXCLUDED.saved_at`
+
+	if _, err := r.db.Exec(query, autosave.PostID, autosave.Title, autosave.Content, autosave.SavedAt); err != nil {
+		return writeError(err, "Failed to autosave post")
+	}
+
+	return nil
+}
+
+// GetByPostID gets the autosaved draft of a post
+func (r *postAutosaveRepository) GetByPostID(postID uuid.UUID) (*models.PostAutosave, error) {
+	query := `SELECT post_id, title, content, saved_at FROM post_autosaves WHERE post_id = $1`
+
+	autosave := &models.PostAutosave{}
+	err := r.db.QueryRow(query, postID).Scan(&autosave.PostID, &autosave.Title, &autosave.Content, &autosave.SavedAt)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, writeError(err, "Failed to get autosaved post")
+	}
+
+	return autosave, nil
+}
+
+// Delete discards the autosaved draft of a post, if any
+func (r *postAutosaveRepository) Delete(postID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM post_autosaves WHERE post_id = $1`, postID); err != nil {
+		return writeError(err, "Failed to discard autosaved post")
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// postLockRepository implements PostLockRepository interface
+type postLockRepository struct {
+	db *sql.DB
+}
+
+// NewPostLockRepository creates a new post lock repository
+func NewPostLockRepository(db *sql.DB) models.PostLockRepository {
+	return &postLockRepository{db: db}
+}
+
+// Acquire takes the lock when it is free or expired, or renews it for the same token
+func (r *postLockRepository) Acquire(lock *models.PostLock) error {
+	query := `INSERT INTO post_locks (post_id, user_id, token, acquired_at, expires_at)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (post_id) DO UPDATE
+			  SET user_id = EXCLUDED.user_id, token = EXCLUDED.token, expires_at = EXCLUDED.expires_at,
+			      acquired_at = CASE WHEN post_locks.token = EXCLUDED.token THEN post_locks.acquired_at ELSE EXCLUDED.acquired_at END
+			  WHERE post_locks.token = EXCLUDED.token OR post_locks.expires_at <= EXCLUDED.acquired_at
+			  RETURNING acquired_at`
+
+	err := r.db.QueryRow(query, lock.PostID, lock.UserID, lock.Token, lock.AcquiredAt, lock.ExpiresAt).Scan(&lock.AcquiredAt)
+	if err == sql.ErrNoRows {
+		return models.ErrNotFound
+	}
+	if err != nil {
+		return writeError(err, "Failed to acquire post lock")
+	}
+
+	return nil
+}
+
+// GetActive gets the lock of a post unless it has expired
+func (r *postLockRepository) GetActive(postID uuid.UUID) (*models.PostLock, error) {
+	query := `SELECT post_id, user_id, token, acquired_at, expires_at
+			  FROM post_locks WHERE post_id = $1 AND expires_at > $2`
+
+	lock := &models.PostLock{}
+	err := r.db.QueryRow(query, postID, time.Now()).Scan(&lock.PostID, &lock.UserID, &lock.Token, &lock.AcquiredAt, &lock.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, models.ErrNotFound
+	}
+	if err != nil {
+		return nil, writeError(err, "Failed to get post lock")
+	}
+
+	return lock, nil
+}
+
+// Release removes the lock held with token
+func (r *postLockRepository) Release(postID uuid.UUID, token string) error {
+	result, err := r.db.Exec(`DELETE FROM post_locks WHERE post_id = $1 AND token = $2`, postID, token)
+	if err != nil {
+		return writeError(err, "Failed to release post lock")
+	}
+
+	return requireRowsAffected(result, "Failed to release post lock")
+}
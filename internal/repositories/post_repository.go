@@ -2,11 +2,15 @@ package repositories
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/ids"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // postRepository implements PostRepository interface
@@ -19,14 +23,32 @@ func NewPostRepository(db *sql.DB) models.PostRepository {
 	return &postRepository{db: db}
 }
 
+// listedTo is the condition under which the viewer in the query parameter param may list
+// the post aliased alias: their own posts, public posts and followers-only posts of authors
+// they follow. Unlisted and private posts of others are never listed.
+func listedTo(alias, param string) string {
+	return fmt.Sprintf(`(%[1]s.author_id = %[2]s OR %[1]s.visibility = '%[3]s' OR (%[1]s.visibility = '%[4]s'
+		AND EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = %[2]s AND f.followee_id = %[1]s.author_id)))`,
+		alias, param, models.PostVisibilityPublic, models.PostVisibilityFollowers)
+}
+
 // Create creates a new post
 func (r *postRepository) Create(post *models.Post) error {
-	query := `INSERT INTO posts (title, content, author_id, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	if post.ID == uuid.Nil {
+		post.ID = ids.New()
+	}
 
-	err := r.db.QueryRow(query, post.Title, post.Content, post.AuthorID, post.CreatedAt, post.UpdatedAt).Scan(&post.ID)
+	if post.Visibility == "" {
+		post.Visibility = models.PostVisibilityPublic
+	}
+
+	query := `INSERT INTO posts (id, title, content, excerpt, reading_time_minutes, content_format, content_sanitized, language, author_id, is_published, visibility, scheduled_at, published_at, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) RETURNING number`
+
+	err := r.db.QueryRow(query, post.ID, post.Title, post.Content, post.Excerpt, post.ReadingTime, post.ContentFormat, post.ContentSanitized,
+		post.Language, post.AuthorID, post.IsPublished, post.Visibility, post.ScheduledAt, post.PublishedAt, post.CreatedAt, post.UpdatedAt).Scan(&post.Number)
 	if err != nil {
-		return errors.WrapError(err, "Failed to create post")
+		return writeError(err, "Failed to create post")
 	}
 
 	return nil
@@ -35,15 +57,13 @@ func (r *postRepository) Create(post *models.Post) error {
 // GetByID gets a post by ID
 func (r *postRepository) GetByID(id uuid.UUID) (*models.Post, error) {
 	post := &models.Post{}
-	query := `SELECT id, title, content, author_id, created_at, updated_at FROM posts WHERE id = $1`
+	query := `SELECT ` + postColumns + ` FROM posts WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.CreatedAt, &post.UpdatedAt,
-	)
+	err := r.db.QueryRow(query, id).Scan(postFields(post)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, models.ErrNotFound
 		}
 		return nil, errors.WrapError(err, "Failed to get post by ID")
 	}
@@ -51,13 +71,31 @@ func (r *postRepository) GetByID(id uuid.UUID) (*models.Post, error) {
 	return post, nil
 }
 
-// GetByAuthorID gets posts by author ID
-func (r *postRepository) GetByAuthorID(authorID uuid.UUID, limit, offset int) ([]*models.Post, error) {
-	query := `SELECT id, title, content, author_id, created_at, updated_at 
-			  FROM posts WHERE author_id = $1 
-			  ORDER BY created_at DESC LIMIT $2 OFFSET $3`
+// GetByNumber gets a post by its sequence number
+func (r *postRepository) GetByNumber(number int64) (*models.Post, error) {
+	post := &models.Post{}
+	query := `SELECT ` + postColumns + ` FROM posts WHERE number = $1`
+
+	err := r.db.QueryRow(query, number).Scan(postFields(post)...)
 
-	rows, err := r.db.Query(query, authorID, limit, offset)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get post by number")
+	}
+
+	return post, nil
+}
+
+// GetByAuthorID gets the posts by author ID the viewer may list, leaving out archived posts
+// unless includeArchived is set
+func (r *postRepository) GetByAuthorID(authorID, viewerID uuid.UUID, includeArchived bool, limit, offset int) ([]*models.Post, error) {
+	query := `SELECT ` + postColumns + `
+			  FROM posts p WHERE author_id = $1 AND ($2 OR archived_at IS NULL) AND ` + listedTo("p", "$5") + `
+			  ORDER BY created_at DESC LIMIT $3 OFFSET $4`
+
+	rows, err := r.db.Query(query, authorID, includeArchived, limit, offset, viewerID)
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get posts by author ID")
 	}
@@ -66,9 +104,7 @@ func (r *postRepository) GetByAuthorID(authorID uuid.UUID, limit, offset int) ([
 	var posts []*models.Post
 	for rows.Next() {
 		post := &models.Post{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.CreatedAt, &post.UpdatedAt,
-		)
+		err := rows.Scan(postFields(post)...)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to scan post")
 		}
@@ -78,9 +114,9 @@ func (r *postRepository) GetByAuthorID(authorID uuid.UUID, limit, offset int) ([
 	return posts, nil
 }
 
-// GetAll gets all posts
+// GetAll gets all posts, including archived ones
 func (r *postRepository) GetAll(limit, offset int) ([]*models.Post, error) {
-	query := `SELECT id, title, content, author_id, created_at, updated_at 
+	query := `SELECT ` + postColumns + `
 			  FROM posts ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
 	rows, err := r.db.Query(query, limit, offset)
@@ -92,9 +128,7 @@ func (r *postRepository) GetAll(limit, offset int) ([]*models.Post, error) {
 	var posts []*models.Post
 	for rows.Next() {
 		post := &models.Post{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.CreatedAt, &post.UpdatedAt,
-		)
+		err := rows.Scan(postFields(post)...)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to scan post")
 		}
@@ -104,15 +138,59 @@ func (r *postRepository) GetAll(limit, offset int) ([]*models.Post, error) {
 	return posts, nil
 }
 
-// GetAllWithAuthor gets all posts with author information
-func (r *postRepository) GetAllWithAuthor(limit, offset int) ([]*models.Post, error) {
-	query := `SELECT p.id, p.title, p.content, p.author_id, p.created_at, p.updated_at,
+// Export calls fn with every post, including archived ones, oldest first, stopping at the first error
+func (r *postRepository) Export(fn func(*models.Post) error) error {
+	query := `SELECT ` + postColumns + `
+			  FROM posts WHERE (created_at, id) > ($1, $2) ORDER BY created_at, id LIMIT $3`
+
+	var afterCreated time.Time
+	var afterID uuid.UUID
+	for {
+		rows, err := r.db.Query(query, afterCreated, afterID, exportBatchSize)
+		if err != nil {
+			return errors.WrapError(err, "Failed to export posts")
+		}
+
+		batch := make([]*models.Post, 0, exportBatchSize)
+		for rows.Next() {
+			post := &models.Post{}
+			err := rows.Scan(postFields(post)...)
+			if err != nil {
+				rows.Close()
+				return errors.WrapError(err, "Failed to scan post")
+			}
+			batch = append(batch, post)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return errors.WrapError(err, "Failed to export posts")
+		}
+
+		for _, post := range batch {
+			if err := fn(post); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		afterCreated, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// GetAllWithAuthor gets all posts that are not archived and the filter's viewer may list with
+// author information, in the filter's language or with posts in its preferred languages first
+func (r *postRepository) GetAllWithAuthor(filter models.PostFilter, limit, offset int) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `,
 			  u.id, u.username, u.email, u.created_at, u.updated_at
 			  FROM posts p
-			  JOIN users u ON p.author_id = u.id
-			  ORDER BY p.created_at DESC LIMIT $1 OFFSET $2`
+			  LEFT JOIN users u ON p.author_id = u.id
+			  WHERE p.archived_at IS NULL AND ($3::text = '' OR p.language = $3) AND ` + listedTo("p", "$5") + `
+			  ORDER BY p.language = ANY($4) DESC, p.created_at DESC LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.Query(query, limit, offset, filter.Language, pq.Array(filter.PreferredLanguages), filter.ViewerID)
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get posts with author")
 	}
@@ -121,17 +199,27 @@ func (r *postRepository) GetAllWithAuthor(limit, offset int) ([]*models.Post, er
 	var posts []*models.Post
 	for rows.Next() {
 		post := &models.Post{}
-		author := &models.User{}
-
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.CreatedAt, &post.UpdatedAt,
-			&author.ID, &author.Username, &author.Email, &author.CreatedAt, &author.UpdatedAt,
+		var (
+			authorID                     uuid.NullUUID
+			authorUsername, authorEmail  sql.NullString
+			authorCreated, authorUpdated sql.NullTime
 		)
+
+		err := rows.Scan(append(postFields(post), &authorID, &authorUsername, &authorEmail, &authorCreated, &authorUpdated)...)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to scan post with author")
 		}
 
-		post.Author = author
+		// Posts of deleted authors are kept without an author when anonymized
+		if authorID.Valid {
+			post.Author = &models.User{
+				ID:        authorID.UUID,
+				Username:  authorUsername.String,
+				Email:     authorEmail.String,
+				CreatedAt: authorCreated.Time,
+				UpdatedAt: authorUpdated.Time,
+			}
+		}
 		posts = append(posts, post)
 	}
 
@@ -140,35 +228,50 @@ func (r *postRepository) GetAllWithAuthor(limit, offset int) ([]*models.Post, er
 
 // Update updates a post
 func (r *postRepository) Update(post *models.Post) error {
-	query := `UPDATE posts SET title = $1, content = $2, is_published = $3, updated_at = $4 WHERE id = $5`
+	query := `UPDATE posts SET title = $1, content = $2, excerpt = $3, reading_time_minutes = $4, content_format = $5,
+			  content_sanitized = $6, language = $7, is_published = $8, visibility = $9, archived_at = $10, scheduled_at = $11, published_at = $12,
+			  updated_at = $13 WHERE id = $14`
 
-	_, err := r.db.Exec(query, post.Title, post.Content, post.IsPublished, post.UpdatedAt, post.ID)
+	result, err := r.db.Exec(query, post.Title, post.Content, post.Excerpt, post.ReadingTime, post.ContentFormat,
+		post.ContentSanitized, post.Language, post.IsPublished, post.Visibility, post.ArchivedAt, post.ScheduledAt, post.PublishedAt, post.UpdatedAt, post.ID)
 	if err != nil {
-		return errors.WrapError(err, "Failed to update post")
+		return writeError(err, "Failed to update post")
 	}
 
-	return nil
+	return requireRowsAffected(result, "Failed to update post")
 }
 
 // Delete deletes a post
 func (r *postRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM posts WHERE id = $1`
 
-	_, err := r.db.Exec(query, id)
+	result, err := r.db.Exec(query, id)
 	if err != nil {
-		return errors.WrapError(err, "Failed to delete post")
+		return writeError(err, "Failed to delete post")
 	}
 
-	return nil
+	return requireRowsAffected(result, "Failed to delete post")
+}
+
+// IncrementViews counts a read of a post
+func (r *postRepository) IncrementViews(id uuid.UUID) error {
+	query := `UPDATE posts SET view_count = view_count + 1 WHERE id = $1`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return writeError(err, "Failed to count post view")
+	}
+
+	return requireRowsAffected(result, "Failed to count post view")
 }
 
-// GetPublished gets published posts
+// GetPublished gets published posts that are public and not archived
 func (r *postRepository) GetPublished(limit, offset int) ([]*models.Post, error) {
-	query := `SELECT id, title, content, author_id, is_published, created_at, updated_at 
-			  FROM posts WHERE is_published = true 
+	query := `SELECT ` + postColumns + `
+			  FROM posts WHERE is_published = true AND archived_at IS NULL AND visibility = $3
 			  ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.Query(query, limit, offset)
+	rows, err := r.db.Query(query, limit, offset, models.PostVisibilityPublic)
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get published posts")
 	}
@@ -177,9 +280,7 @@ func (r *postRepository) GetPublished(limit, offset int) ([]*models.Post, error)
 	var posts []*models.Post
 	for rows.Next() {
 		post := &models.Post{}
-		err := rows.Scan(
-			&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.IsPublished, &post.CreatedAt, &post.UpdatedAt,
-		)
+		err := rows.Scan(postFields(post)...)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to scan post")
 		}
@@ -189,8 +290,114 @@ func (r *postRepository) GetPublished(limit, offset int) ([]*models.Post, error)
 	return posts, nil
 }
 
-// Count returns the total number of posts
-func (r *postRepository) Count() (int, error) {
+// GetScheduled gets the author's posts scheduled for from or later, and before to, soonest first
+func (r *postRepository) GetScheduled(authorID uuid.UUID, from, to time.Time) ([]*models.Post, error) {
+	query := `SELECT ` + postColumns + `
+			  FROM posts WHERE author_id = $1 AND scheduled_at >= $2 AND scheduled_at < $3
+			  ORDER BY scheduled_at, id`
+
+	rows, err := r.db.Query(query, authorID, from, to)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get scheduled posts")
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		if err := rows.Scan(postFields(post)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post")
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// PublishScheduled publishes the unpublished posts scheduled for now or earlier and clears their
+// schedule, returning the posts it published
+func (r *postRepository) PublishScheduled(now time.Time) ([]*models.Post, error) {
+	query := `UPDATE posts SET is_published = true, scheduled_at = NULL, published_at = $1, updated_at = $1
+			  WHERE scheduled_at <= $1 AND archived_at IS NULL
+			  RETURNING ` + postColumns
+
+	return r.queryPosts(query, "Failed to publish scheduled posts", now)
+}
+
+// IsFollower reports whether the viewer follows the author
+func (r *postRepository) IsFollower(authorID, viewerID uuid.UUID) (bool, error) {
+	var follower bool
+	query := `SELECT EXISTS (SELECT 1 FROM follows WHERE follower_id = $1 AND followee_id = $2)`
+
+	if err := r.db.QueryRow(query, viewerID, authorID).Scan(&follower); err != nil {
+		return false, errors.WrapError(err, "Failed to check follower")
+	}
+
+	return follower, nil
+}
+
+// GetFolloweeIDs gets the IDs of the authors the viewer follows
+func (r *postRepository) GetFolloweeIDs(viewerID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(`SELECT followee_id FROM follows WHERE follower_id = $1`, viewerID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get followees")
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan followee")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetFeed gets a page of the published posts the user may list of the authors they follow,
+// newest first, joining across their follows
+func (r *postRepository) GetFeed(userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `
+			  FROM follows f
+			  JOIN posts p ON p.author_id = f.followee_id
+			  WHERE f.follower_id = $1 AND ` + feedListed + `
+			  ORDER BY p.published_at DESC, p.id DESC
+			  LIMIT $2 OFFSET $3`
+
+	return r.queryPosts(query, "Failed to get feed", userID, limit, offset)
+}
+
+// CountFeed counts the published posts the user may list of the authors they follow
+func (r *postRepository) CountFeed(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM follows f JOIN posts p ON p.author_id = f.followee_id
+			  WHERE f.follower_id = $1 AND ` + feedListed
+
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count feed posts")
+	}
+
+	return count, nil
+}
+
+// Count returns the total number of posts that are not archived and the filter's viewer may
+// list, in the filter's language if set
+func (r *postRepository) Count(filter models.PostFilter) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM posts p WHERE archived_at IS NULL AND ($1::text = '' OR language = $1) AND ` + listedTo("p", "$2")
+
+	err := r.db.QueryRow(query, filter.Language, filter.ViewerID).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count posts")
+	}
+
+	return count, nil
+}
+
+// CountAll returns the total number of posts, including archived ones
+func (r *postRepository) CountAll() (int, error) {
 	var count int
 	query := `SELECT COUNT(*) FROM posts`
 
@@ -202,12 +409,13 @@ func (r *postRepository) Count() (int, error) {
 	return count, nil
 }
 
-// CountByAuthorID returns the total number of posts by author
-func (r *postRepository) CountByAuthorID(authorID uuid.UUID) (int, error) {
+// CountByAuthorID returns the total number of posts by author the viewer may list, leaving out
+// archived posts unless includeArchived is set
+func (r *postRepository) CountByAuthorID(authorID, viewerID uuid.UUID, includeArchived bool) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM posts WHERE author_id = $1`
+	query := `SELECT COUNT(*) FROM posts p WHERE author_id = $1 AND ($2 OR archived_at IS NULL) AND ` + listedTo("p", "$3")
 
-	err := r.db.QueryRow(query, authorID).Scan(&count)
+	err := r.db.QueryRow(query, authorID, includeArchived, viewerID).Scan(&count)
 	if err != nil {
 		return 0, errors.WrapError(err, "Failed to count posts by author")
 	}
@@ -215,12 +423,12 @@ func (r *postRepository) CountByAuthorID(authorID uuid.UUID) (int, error) {
 	return count, nil
 }
 
-// CountPublished returns the total number of published posts
+// CountPublished returns the total number of published posts that are public and not archived
 func (r *postRepository) CountPublished() (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM posts WHERE is_published = true`
+	query := `SELECT COUNT(*) FROM posts WHERE is_published = true AND archived_at IS NULL AND visibility = $1`
 
-	err := r.db.QueryRow(query).Scan(&count)
+	err := r.db.QueryRow(query, models.PostVisibilityPublic).Scan(&count)
 	if err != nil {
 		return 0, errors.WrapError(err, "Failed to count published posts")
 	}
@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// postMatches is the condition under which the published post aliased p matches the search in
+// $1: in full text, like saved searches, or, to tolerate typos, when the query resembles a
+// part of its title (pg_trgm word similarity)
+const postMatches = `p.is_published = true AND p.archived_at IS NULL
+			  AND (to_tsvector('simple', p.title || ' ' || p.content) @@ websearch_to_tsquery('simple', $1)
+			       OR LOWER($1) <% LOWER(p.title))`
+
+// Search gets the published posts the viewer may list matching the query, best matches first
+func (r *postRepository) Search(query string, viewerID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+	sqlQuery := `SELECT ` + qualify("p", postColumns) + `
+			  FROM posts p
+			  WHERE ` + postMatches + ` AND ` + listedTo("p", "$2") + `
+			  ORDER BY ts_rank(to_tsvector('simple', p.title || ' ' || p.content), websearch_to_tsquery('simple', $1))
+			           + word_similarity(LOWER($1), LOWER(p.title)) DESC, p.published_at DESC, p.id
+			  LIMIT $3 OFFSET $4`
+
+	return r.queryPosts(sqlQuery, "Failed to search posts", query, viewerID, limit, offset)
+}
+
+// CountSearch counts the published posts the viewer may list matching the query
+func (r *postRepository) CountSearch(query string, viewerID uuid.UUID) (int, error) {
+	var count int
+	sqlQuery := `SELECT COUNT(*) FROM posts p WHERE ` + postMatches + ` AND ` + listedTo("p", "$2")
+
+	if err := r.db.QueryRow(sqlQuery, query, viewerID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count matching posts")
+	}
+
+	return count, nil
+}
+
+// GetListedByIDs gets those of the posts with the IDs that are published and the viewer may list,
+// to load the results of an external search index, which may lag behind
+func (r *postRepository) GetListedByIDs(ids []uuid.UUID, viewerID uuid.UUID) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `
+			  FROM posts p
+			  WHERE p.id = ANY($1) AND p.is_published = true AND p.archived_at IS NULL AND ` + listedTo("p", "$2")
+
+	return r.queryPosts(query, "Failed to get posts", pq.Array(ids), viewerID)
+}
+
+// SuggestTitles gets the published posts the viewer may list whose titles resemble prefix,
+// titles starting with it first
+func (r *postRepository) SuggestTitles(prefix string, viewerID uuid.UUID, limit int) ([]*models.Post, error) {
+	query := `SELECT ` + qualify("p", postColumns) + `
+			  FROM posts p
+			  WHERE p.is_published = true AND p.archived_at IS NULL AND LOWER($1) <% LOWER(p.title)
+			  AND ` + listedTo("p", "$2") + `
+			  ORDER BY starts_with(LOWER(p.title), LOWER($1)) DESC, word_similarity(LOWER($1), LOWER(p.title)) DESC,
+			           p.published_at DESC, p.id
+			  LIMIT $3`
+
+	return r.queryPosts(query, "Failed to suggest post titles", prefix, viewerID, limit)
+}
+
+// SuggestAuthors gets the usernames resembling prefix of active users with a published public
+// post, usernames starting with it first. Accounts awaiting parental consent cannot publish,
+// so they are never suggested.
+func (r *postRepository) SuggestAuthors(prefix string, limit int) ([]string, error) {
+	query := `SELECT u.username FROM users u
+			  WHERE u.is_active = true AND LOWER($1) <% LOWER(u.username)
+			  AND EXISTS (SELECT 1 FROM posts p WHERE p.author_id = u.id AND p.is_published = true
+			              AND p.archived_at IS NULL AND p.visibility = $3)
+			  ORDER BY starts_with(LOWER(u.username), LOWER($1)) DESC, word_similarity(LOWER($1), LOWER(u.username)) DESC,
+			           u.username
+			  LIMIT $2`
+
+	rows, err := r.db.Query(query, prefix, limit, models.PostVisibilityPublic)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to suggest authors")
+	}
+	defer rows.Close()
+
+	usernames := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan author")
+		}
+		usernames = append(usernames, username)
+	}
+
+	return usernames, rows.Err()
+}
+
+// SimilarWord gets the word of published public post titles most similar to word, or "" when
+// none is similar enough. Only public titles are used, so corrections never reveal words of
+// posts the viewer may not see. The words are kept in post_title_words as posts change.
+func (r *postRepository) SimilarWord(word string) (string, error) {
+	query := `SELECT word FROM post_title_words
+			  WHERE word % LOWER($1)
+			  ORDER BY similarity(word, LOWER($1)) DESC, word
+			  LIMIT 1`
+
+	var similar string
+	err := r.db.QueryRow(query, word).Scan(&similar)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to find similar word")
+	}
+
+	return similar, nil
+}
+
+// queryPosts runs a post listing query
+func (r *postRepository) queryPosts(query, message string, args ...interface{}) ([]*models.Post, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, message)
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		if err := rows.Scan(postFields(post)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post")
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
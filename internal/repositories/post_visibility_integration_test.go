@@ -0,0 +1,301 @@
+//go:build integration
+
+package repositories
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/testutil"
+
+	"github.com/google/uuid"
+)
+
+// visibilityFixture holds an author with a public and an unlisted post, an author whose only
+// post is unlisted, a follower of both and a stranger. Titles carry a word unique to the test,
+// so searches only match its posts.
+type visibilityFixture struct {
+	db                          *sql.DB
+	posts                       models.PostRepository
+	author, unlistedAuthor      *models.User
+	follower, stranger          *models.User
+	public, unlisted, unlisted2 *models.Post
+	word                        string
+}
+
+func newVisibilityFixture(t *testing.T) *visibilityFixture {
+	t.Helper()
+	db := testutil.DB(t)
+	users := NewUserRepository(db, nil)
+	f := &visibilityFixture{db: db, posts: NewPostRepository(db)}
+	hex := strings.ReplaceAll(ids.New().String(), "-", "")
+	f.word = "vis" + hex[len(hex)-10:]
+
+	for _, user := range []**models.User{&f.author, &f.unlistedAuthor, &f.follower, &f.stranger} {
+		*user = newTestUser(true, false, nil)
+	}
+	// Authors are suggested by their usernames, so the one to leave out has the word in theirs
+	f.unlistedAuthor.Username = f.word + "u"
+	for _, user := range []*models.User{f.author, f.unlistedAuthor, f.follower, f.stranger} {
+		if err := users.Create(user); err != nil {
+			t.Fatalf("Create user: %v", err)
+		}
+	}
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	newPost := func(author *models.User, title, visibility string) *models.Post {
+		post := &models.Post{
+			ID: ids.New(), Title: title, Content: title + " content", AuthorID: author.ID, IsPublished: true,
+			Visibility: visibility, PublishedAt: &now, CreatedAt: now, UpdatedAt: now,
+		}
+		if err := f.posts.Create(post); err != nil {
+			t.Fatalf("Create post: %v", err)
+		}
+		return post
+	}
+	f.public = newPost(f.author, f.word+" public", models.PostVisibilityPublic)
+	f.unlisted = newPost(f.author, f.word+" unlisted "+f.word+"secret", models.PostVisibilityUnlisted)
+	f.unlisted2 = newPost(f.unlistedAuthor, f.word+" only unlisted", models.PostVisibilityUnlisted)
+
+	profiles := NewProfileRepository(db)
+	for _, followee := range []*models.User{f.author, f.unlistedAuthor} {
+		if err := profiles.Follow(&models.Follow{FollowerID: f.follower.ID, FolloweeID: followee.ID, CreatedAt: now}); err != nil {
+			t.Fatalf("Follow: %v", err)
+		}
+	}
+	return f
+}
+
+// viewers are the users none of whose listings may show the unlisted posts
+func (f *visibilityFixture) viewers() map[string]uuid.UUID {
+	return map[string]uuid.UUID{"anonymous": uuid.Nil, "follower": f.follower.ID, "stranger": f.stranger.ID}
+}
+
+// assertUnlistedLeftOut checks that posts include the public post, when wantPublic is set,
+// and neither of the unlisted ones
+func (f *visibilityFixture) assertUnlistedLeftOut(t *testing.T, listing string, posts []*models.Post, wantPublic bool) {
+	t.Helper()
+	sawPublic := false
+	for _, post := range posts {
+		switch post.ID {
+		case f.unlisted.ID, f.unlisted2.ID:
+			t.Errorf("%s lists the unlisted post %q", listing, post.Title)
+		case f.public.ID:
+			sawPublic = true
+		}
+	}
+	if wantPublic && !sawPublic {
+		t.Errorf("%s does not list the public post, so it cannot tell the unlisted ones apart", listing)
+	}
+}
+
+func TestUnlistedPostsAreNeverListed(t *testing.T) {
+	f := newVisibilityFixture(t)
+
+	for name, viewerID := range f.viewers() {
+		t.Run(name, func(t *testing.T) {
+			all, err := f.posts.GetAllWithAuthor(models.PostFilter{ViewerID: viewerID}, 1000, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.assertUnlistedLeftOut(t, "GetAllWithAuthor", all, true)
+
+			byAuthor, err := f.posts.GetByAuthorID(f.author.ID, viewerID, false, 100, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.assertUnlistedLeftOut(t, "GetByAuthorID", byAuthor, true)
+			if count, err := f.posts.CountByAuthorID(f.author.ID, viewerID, false); err != nil || count != 1 {
+				t.Errorf("CountByAuthorID = %d, %v; want 1", count, err)
+			}
+
+			found, err := f.posts.Search(f.word, viewerID, 100, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.assertUnlistedLeftOut(t, "Search", found, true)
+			if count, err := f.posts.CountSearch(f.word, viewerID); err != nil || count != 1 {
+				t.Errorf("CountSearch = %d, %v; want 1", count, err)
+			}
+			// Results of the external search index are checked again
+			indexed, err := f.posts.GetListedByIDs([]uuid.UUID{f.public.ID, f.unlisted.ID, f.unlisted2.ID}, viewerID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.assertUnlistedLeftOut(t, "GetListedByIDs", indexed, true)
+
+			suggested, err := f.posts.SuggestTitles(f.word, viewerID, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			f.assertUnlistedLeftOut(t, "SuggestTitles", suggested, true)
+		})
+	}
+
+	published, err := f.posts.GetPublished(1000, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertUnlistedLeftOut(t, "GetPublished", published, true)
+
+	// The author lists their own unlisted post, and anyone reads it by its ID
+	own, err := f.posts.GetByAuthorID(f.author.ID, f.author.ID, false, 100, 0)
+	if err != nil || len(own) != 2 {
+		t.Errorf("GetByAuthorID of the author = %d posts, %v; want 2", len(own), err)
+	}
+	if post, err := f.posts.GetByID(f.unlisted.ID); err != nil || !post.VisibleTo(f.stranger.ID, false) {
+		t.Errorf("GetByID of the unlisted post: %v, %v; want it readable by link", post, err)
+	}
+}
+
+func TestUnlistedPostsAreNeverSuggested(t *testing.T) {
+	f := newVisibilityFixture(t)
+
+	authors, err := f.posts.SuggestAuthors(f.word, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, username := range authors {
+		if strings.EqualFold(username, f.unlistedAuthor.Username) {
+			t.Errorf("SuggestAuthors suggests %q, whose only post is unlisted", username)
+		}
+	}
+
+	// Spelling corrections only draw on public titles
+	similar, err := f.posts.SimilarWord(f.word + "secrets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.EqualFold(similar, f.word+"secret") {
+		t.Errorf("SimilarWord suggests %q, a word of an unlisted title", similar)
+	}
+}
+
+func TestSimilarWordFollowsPostChanges(t *testing.T) {
+	f := newVisibilityFixture(t)
+	misspelled := f.word + "x"
+	assertSuggested := func(step string, want bool) {
+		t.Helper()
+		similar, err := f.posts.SimilarWord(misspelled)
+		if err != nil {
+			t.Fatalf("%s: SimilarWord: %v", step, err)
+		}
+		if (similar == f.word) != want {
+			t.Errorf("%s: SimilarWord(%q) = %q, want %q suggested %v", step, misspelled, similar, f.word, want)
+		}
+	}
+	assertSuggested("public post", true)
+
+	// The unlisted posts share the word, yet it goes once the only public title with it does
+	f.public.Visibility = models.PostVisibilityPrivate
+	if err := f.posts.Update(f.public); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	assertSuggested("made private", false)
+
+	f.public.Visibility = models.PostVisibilityPublic
+	if err := f.posts.Update(f.public); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	assertSuggested("public again", true)
+
+	if err := f.posts.Delete(f.public.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	assertSuggested("deleted", false)
+}
+
+func TestUnlistedPostsAreNeverInFeeds(t *testing.T) {
+	f := newVisibilityFixture(t)
+
+	feed, err := f.posts.GetFeed(f.follower.ID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertUnlistedLeftOut(t, "GetFeed", feed, true)
+	if count, err := f.posts.CountFeed(f.follower.ID); err != nil || count != 1 {
+		t.Errorf("CountFeed = %d, %v; want 1", count, err)
+	}
+
+	// Stored feeds: fanning out an unlisted post, say from a bug in the caller, still leaves it
+	// out when the feed is read, and backfills and rebuilds never store it
+	feeds := NewFeedRepository(f.db)
+	for _, post := range []*models.Post{f.public, f.unlisted, f.unlisted2} {
+		if err := feeds.FanOut(post.ID, post.AuthorID, *post.PublishedAt, 100); err != nil {
+			t.Fatalf("FanOut: %v", err)
+		}
+	}
+	stored, err := feeds.GetPosts(f.follower.ID, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.assertUnlistedLeftOut(t, "stored feed", stored, true)
+	if count, err := feeds.Count(f.follower.ID); err != nil || count != 1 {
+		t.Errorf("stored feed Count = %d, %v; want 1", count, err)
+	}
+
+	for _, author := range []*models.User{f.author, f.unlistedAuthor} {
+		if err := feeds.RemoveAuthor(f.follower.ID, author.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := feeds.Backfill(f.follower.ID, author.ID, 100); err != nil {
+			t.Fatalf("Backfill: %v", err)
+		}
+	}
+	f.assertStoredFeedEntries(t, "Backfill")
+
+	if _, err := feeds.Rebuild(100); err != nil {
+		t.Fatalf("Rebuild: %v", err)
+	}
+	f.assertStoredFeedEntries(t, "Rebuild")
+}
+
+// assertStoredFeedEntries checks that the follower's stored feed holds the public post and
+// no entry for the unlisted ones
+func (f *visibilityFixture) assertStoredFeedEntries(t *testing.T, after string) {
+	t.Helper()
+	rows, err := f.db.Query(`SELECT post_id FROM feed_entries WHERE user_id = $1`, f.follower.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var entries []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		if err := rows.Scan(&post.ID); err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, post)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	f.assertUnlistedLeftOut(t, "feed entries after "+after, entries, true)
+}
+
+func TestUnlistedPostsAreNeverOnProfiles(t *testing.T) {
+	f := newVisibilityFixture(t)
+	profiles := NewProfileRepository(f.db)
+
+	page, err := profiles.GetPage(f.author.Username, 100, nil)
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	f.assertUnlistedLeftOut(t, "profile page", page.LatestPosts, true)
+	if page.PublishedPosts != 1 {
+		t.Errorf("profile counts %d published posts, want 1", page.PublishedPosts)
+	}
+
+	page, err = profiles.GetPage(f.unlistedAuthor.Username, 100, nil)
+	if err != nil {
+		t.Fatalf("GetPage: %v", err)
+	}
+	f.assertUnlistedLeftOut(t, "profile page", page.LatestPosts, false)
+	if page.PublishedPosts != 0 || len(page.LatestPosts) != 0 {
+		t.Errorf("profile of an author with only unlisted posts shows %d posts and counts %d, want none", len(page.LatestPosts), page.PublishedPosts)
+	}
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// profileRepository implements ProfileRepository interface
+type profileRepository struct {
+	db *sql.DB
+}
+
+// NewProfileRepository creates a new profile repository
+func NewProfileRepository(db *sql.DB) models.ProfileRepository {
+	return &profileRepository{db: db}
+}
+
+// GetPage gets the profile page of an active user in a single query: one row per latest
+// published public post, each carrying the profile and its counts (or one row without a post)
+func (r *profileRepository) GetPage(username string, postLimit int, restrictedBornAfter *time.Time) (*models.ProfilePage, error) {
+	query := `SELECT u.id, u.username, u.created_at,
+			  (SELECT COUNT(*) FROM posts WHERE author_id = u.id AND is_published = true AND archived_at IS NULL AND visibility = $4),
+			  (SELECT COUNT(*) FROM follows WHERE followee_id = u.id),
+			  p.id, p.number, p.title, p.excerpt, p.reading_time_minutes, p.created_at, p.updated_at
+			  FROM users u
+			  LEFT JOIN LATERAL (
+			      SELECT id, number, title, excerpt, reading_time_minutes, created_at, updated_at
+			      FROM posts
+			      WHERE author_id = u.id AND is_published = true AND archived_at IS NULL AND visibility = $4
+			      ORDER BY created_at DESC LIMIT $2
+			  ) p ON true
+			  WHERE LOWER(u.username) = LOWER($1) AND u.is_active = true AND u.role <> $5
+			  AND NOT COALESCE(u.birthdate > $3::date AND u.parental_consent_at IS NULL, false)
+			  ORDER BY p.created_at DESC`
+
+	rows, err := r.db.Query(query, username, postLimit, restrictedBornAfter, models.PostVisibilityPublic, models.RoleSandbox)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get profile page")
+	}
+	defer rows.Close()
+
+	var page *models.ProfilePage
+	for rows.Next() {
+		var (
+			profile           models.PublicProfile
+			published, fans   int
+			postID            uuid.NullUUID
+			number            sql.NullInt64
+			title, excerpt    sql.NullString
+			readingTime       sql.NullInt64
+			created, modified sql.NullTime
+		)
+		err := rows.Scan(
+			&profile.ID, &profile.Username, &profile.CreatedAt, &published, &fans,
+			&postID, &number, &title, &excerpt, &readingTime, &created, &modified,
+		)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan profile page")
+		}
+
+		if page == nil {
+			page = &models.ProfilePage{
+				Profile:        profile,
+				PublishedPosts: published,
+				Followers:      fans,
+				LatestPosts:    []*models.Post{},
+			}
+		}
+		if postID.Valid {
+			page.LatestPosts = append(page.LatestPosts, &models.Post{
+				ID:          postID.UUID,
+				Number:      number.Int64,
+				Title:       title.String,
+				Excerpt:     excerpt.String,
+				ReadingTime: int(readingTime.Int64),
+				AuthorID:    profile.ID,
+				IsPublished: true,
+				Visibility:  models.PostVisibilityPublic,
+				CreatedAt:   created.Time,
+				UpdatedAt:   modified.Time,
+			})
+		}
+	}
+	if page == nil {
+		return nil, models.ErrNotFound
+	}
+	return page, nil
+}
+
+// Follow records a follow; following someone already followed is a no-op
+func (r *profileRepository) Follow(follow *models.Follow) error {
+	query := `INSERT INTO follows (follower_id, followee_id, created_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (follower_id, followee_id) DO NOTHING`
+
+	if _, err := r.db.Exec(query, follow.FollowerID, follow.FolloweeID, follow.CreatedAt); err != nil {
+		return writeError(err, "Failed to follow user")
+	}
+
+	return nil
+}
+
+// Unfollow removes a follow
+func (r *profileRepository) Unfollow(followerID, followeeID uuid.UUID) error {
+	query := `DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2`
+
+	result, err := r.db.Exec(query, followerID, followeeID)
+	if err != nil {
+		return writeError(err, "Failed to unfollow user")
+	}
+
+	return requireRowsAffected(result, "Failed to unfollow user")
+}
@@ -0,0 +1,254 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// pushRepository implements PushRepository interface
+type pushRepository struct {
+	db *sql.DB
+}
+
+// NewPushRepository creates a new push repository
+func NewPushRepository(db *sql.DB) models.PushRepository {
+	return &pushRepository{db: db}
+}
+
+// SaveDevice registers a device token, moving it to the user when another user registered it
+// on the same installation before
+func (r *pushRepository) SaveDevice(device *models.PushDevice) error {
+	query := `INSERT INTO push_devices (user_id, platform, token, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $4)
+			  ON CONFLICT (token) DO UPDATE SET user_id = EXCLUDED.user_id, platform = EXCLUDED.platform,
+			      updated_at = EXCLUDED.updated_at,
+			      created_at = CASE WHEN push_devices.user_id = EXCLUDED.user_id THEN push_devices.created_at ELSE EXCLUDED.created_at END
+			  RETURNING id, created_at`
+
+	err := r.db.QueryRow(query, device.UserID, device.Platform, device.Token, device.UpdatedAt).Scan(&device.ID, &device.CreatedAt)
+	if err != nil {
+		return writeError(err, "Failed to save push device")
+	}
+
+	return nil
+}
+
+// ListDevices gets the devices of a user, most recently registered first
+func (r *pushRepository) ListDevices(userID uuid.UUID) ([]*models.PushDevice, error) {
+	query := `SELECT ` + pushDeviceColumns + ` FROM push_devices WHERE user_id = $1 ORDER BY updated_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list push devices")
+	}
+	defer rows.Close()
+
+	devices := []*models.PushDevice{}
+	for rows.Next() {
+		device := &models.PushDevice{}
+		if err := rows.Scan(pushDeviceFields(device)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan push device")
+		}
+		devices = append(devices, device)
+	}
+
+	return devices, rows.Err()
+}
+
+// DeleteDevice deletes a device of a user
+func (r *pushRepository) DeleteDevice(userID, id uuid.UUID) error {
+	result, err := r.db.Exec(`DELETE FROM push_devices WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to delete push device")
+	}
+
+	return requireRowsAffected(result, "Failed to delete push device")
+}
+
+// DeleteDeviceByToken deletes the device registered with a token, if any
+func (r *pushRepository) DeleteDeviceByToken(token string) error {
+	if _, err := r.db.Exec(`DELETE FROM push_devices WHERE token = $1`, token); err != nil {
+		return errors.WrapError(err, "Failed to delete push device")
+	}
+
+	return nil
+}
+
+// TrimDevices deletes the devices of a user registered before their keep newest
+func (r *pushRepository) TrimDevices(userID uuid.UUID, keep int) error {
+	query := `DELETE FROM push_devices WHERE user_id = $1 AND id NOT IN (
+			      SELECT id FROM push_devices WHERE user_id = $1 ORDER BY updated_at DESC LIMIT $2)`
+
+	if _, err := r.db.Exec(query, userID, keep); err != nil {
+		return errors.WrapError(err, "Failed to trim push devices")
+	}
+
+	return nil
+}
+
+// Subscribe subscribes a user to a topic; subscribing again keeps the existing subscription
+func (r *pushRepository) Subscribe(sub *models.PushSubscription) error {
+	query := `INSERT INTO push_subscriptions (user_id, topic, created_at) VALUES ($1, $2, $3)
+			  ON CONFLICT (user_id, topic) DO UPDATE SET topic = EXCLUDED.topic
+			  RETURNING created_at`
+
+	if err := r.db.QueryRow(query, sub.UserID, sub.Topic, sub.CreatedAt).Scan(&sub.CreatedAt); err != nil {
+		return writeError(err, "Failed to subscribe to push topic")
+	}
+
+	return nil
+}
+
+// Unsubscribe deletes a user's subscription to a topic, if any
+func (r *pushRepository) Unsubscribe(userID uuid.UUID, topic string) error {
+	if _, err := r.db.Exec(`DELETE FROM push_subscriptions WHERE user_id = $1 AND topic = $2`, userID, topic); err != nil {
+		return errors.WrapError(err, "Failed to unsubscribe from push topic")
+	}
+
+	return nil
+}
+
+// ListSubscriptions gets the topic subscriptions of a user, newest first
+func (r *pushRepository) ListSubscriptions(userID uuid.UUID) ([]*models.PushSubscription, error) {
+	query := `SELECT ` + pushSubscriptionColumns + ` FROM push_subscriptions WHERE user_id = $1 ORDER BY created_at DESC, topic`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list push subscriptions")
+	}
+	defer rows.Close()
+
+	subs := []*models.PushSubscription{}
+	for rows.Next() {
+		sub := &models.PushSubscription{}
+		if err := rows.Scan(pushSubscriptionFields(sub)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan push subscription")
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// CountSubscriptions counts the topic subscriptions of a user
+func (r *pushRepository) CountSubscriptions(userID uuid.UUID) (int, error) {
+	var count int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM push_subscriptions WHERE user_id = $1`, userID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count push subscriptions")
+	}
+
+	return count, nil
+}
+
+// ListSubscribers lists a page of the users subscribed to a topic, by keyset on their ID
+func (r *pushRepository) ListSubscribers(topic string, after uuid.UUID, limit int) ([]uuid.UUID, error) {
+	query := `SELECT user_id FROM push_subscriptions WHERE topic = $1 AND user_id > $2 ORDER BY user_id LIMIT $3`
+
+	rows, err := r.db.Query(query, topic, after, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list push subscribers")
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan push subscriber")
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// GetQuietHours gets the quiet hours of a user
+func (r *pushRepository) GetQuietHours(userID uuid.UUID) (*models.QuietHours, error) {
+	quiet := &models.QuietHours{UserID: userID}
+	query := `SELECT start_time, end_time, updated_at FROM push_quiet_hours WHERE user_id = $1`
+
+	err := r.db.QueryRow(query, userID).Scan(&quiet.Start, &quiet.End, &quiet.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get quiet hours")
+	}
+
+	return quiet, nil
+}
+
+// SaveQuietHours creates or replaces the quiet hours of a user
+func (r *pushRepository) SaveQuietHours(quiet *models.QuietHours) error {
+	query := `INSERT INTO push_quiet_hours (user_id, start_time, end_time, updated_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id) DO UPDATE SET start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+			      updated_at = EXCLUDED.updated_at`
+
+	if _, err := r.db.Exec(query, quiet.UserID, quiet.Start, quiet.End, quiet.UpdatedAt); err != nil {
+		return writeError(err, "Failed to save quiet hours")
+	}
+
+	return nil
+}
+
+// DeleteQuietHours turns off the quiet hours of a user
+func (r *pushRepository) DeleteQuietHours(userID uuid.UUID) error {
+	if _, err := r.db.Exec(`DELETE FROM push_quiet_hours WHERE user_id = $1`, userID); err != nil {
+		return errors.WrapError(err, "Failed to delete quiet hours")
+	}
+
+	return nil
+}
+
+// Hold stores a notification held during quiet hours, replacing the one held with its collapse key
+func (r *pushRepository) Hold(n *models.HeldPushNotification) error {
+	data, err := json.Marshal(n.Data)
+	if err != nil {
+		return errors.WrapError(err, "Failed to encode held push notification")
+	}
+	query := `INSERT INTO push_held_notifications (user_id, collapse_key, title, body, data, release_at, created_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7)
+			  ON CONFLICT (user_id, collapse_key) DO UPDATE SET title = EXCLUDED.title, body = EXCLUDED.body,
+			      data = EXCLUDED.data, release_at = EXCLUDED.release_at, created_at = EXCLUDED.created_at`
+
+	if _, err := r.db.Exec(query, n.UserID, n.CollapseKey, n.Title, n.Body, data, n.ReleaseAt, n.CreatedAt); err != nil {
+		return writeError(err, "Failed to hold push notification")
+	}
+
+	return nil
+}
+
+// TakeHeldDue deletes the held notifications released at now, earliest first, and returns them
+func (r *pushRepository) TakeHeldDue(now time.Time, limit int) ([]*models.HeldPushNotification, error) {
+	query := `DELETE FROM push_held_notifications WHERE (user_id, collapse_key) IN (
+			      SELECT user_id, collapse_key FROM push_held_notifications WHERE release_at <= $1
+			      ORDER BY release_at LIMIT $2 FOR UPDATE SKIP LOCKED)
+			  RETURNING user_id, collapse_key, title, body, data, release_at, created_at`
+
+	rows, err := r.db.Query(query, now, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to take held push notifications")
+	}
+	defer rows.Close()
+
+	var held []*models.HeldPushNotification
+	for rows.Next() {
+		n := &models.HeldPushNotification{}
+		var data []byte
+		if err := rows.Scan(&n.UserID, &n.CollapseKey, &n.Title, &n.Body, &data, &n.ReleaseAt, &n.CreatedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan held push notification")
+		}
+		if err := json.Unmarshal(data, &n.Data); err != nil {
+			return nil, errors.WrapError(err, "Failed to decode held push notification")
+		}
+		held = append(held, n)
+	}
+
+	return held, rows.Err()
+}
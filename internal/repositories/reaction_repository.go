@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// reactionRepository implements ReactionRepository interface
+type reactionRepository struct {
+	db *sql.DB
+}
+
+// NewReactionRepository creates a new reaction repository
+func NewReactionRepository(db *sql.DB) models.ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// SetForPost sets the user's reaction to a post, replacing any previous one
+func (r *reactionRepository) SetForPost(userID, postID uuid.UUID, reaction string) error {
+	query := `INSERT INTO reactions (user_id, post_id, reaction, created_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id, post_id) WHERE post_id IS NOT NULL
+			  DO UPDATE SET reaction = EXCLUDED.reaction, created_at = EXCLUDED.created_at`
+
+	if _, err := r.db.Exec(query, userID, postID, reaction, time.Now()); err != nil {
+		return writeError(err, "Failed to react to post")
+	}
+
+	return nil
+}
+
+// RemoveForPost removes the user's reaction to a post
+func (r *reactionRepository) RemoveForPost(userID, postID uuid.UUID) error {
+	query := `DELETE FROM reactions WHERE user_id = $1 AND post_id = $2`
+
+	result, err := r.db.Exec(query, userID, postID)
+	if err != nil {
+		return writeError(err, "Failed to remove reaction")
+	}
+
+	return requireRowsAffected(result, "Failed to remove reaction")
+}
+
+// SetForComment sets the user's reaction to a comment, replacing any previous one
+func (r *reactionRepository) SetForComment(userID, commentID uuid.UUID, reaction string) error {
+	query := `INSERT INTO reactions (user_id, comment_id, reaction, created_at) VALUES ($1, $2, $3, $4)
+			  ON CONFLICT (user_id, comment_id) WHERE comment_id IS NOT NULL
+			  DO UPDATE SET reaction = EXCLUDED.reaction, created_at = EXCLUDED.created_at`
+
+	if _, err := r.db.Exec(query, userID, commentID, reaction, time.Now()); err != nil {
+		return writeError(err, "Failed to react to comment")
+	}
+
+	return nil
+}
+
+// RemoveForComment removes the user's reaction to a comment
+func (r *reactionRepository) RemoveForComment(userID, commentID uuid.UUID) error {
+	query := `DELETE FROM reactions WHERE user_id = $1 AND comment_id = $2`
+
+	result, err := r.db.Exec(query, userID, commentID)
+	if err != nil {
+		return writeError(err, "Failed to remove reaction")
+	}
+
+	return requireRowsAffected(result, "Failed to remove reaction")
+}
+
+// CountForPosts counts the reactions to each post by type
+func (r *reactionRepository) CountForPosts(postIDs []uuid.UUID) (map[uuid.UUID]models.ReactionCounts, error) {
+	query := `SELECT post_id, reaction, COUNT(*) FROM reactions
+			  WHERE post_id = ANY($1::uuid[]) GROUP BY post_id, reaction`
+
+	return r.count(query, postIDs)
+}
+
+// CountForComments counts the reactions to each comment by type
+func (r *reactionRepository) CountForComments(commentIDs []uuid.UUID) (map[uuid.UUID]models.ReactionCounts, error) {
+	query := `SELECT comment_id, reaction, COUNT(*) FROM reactions
+			  WHERE comment_id = ANY($1::uuid[]) GROUP BY comment_id, reaction`
+
+	return r.count(query, commentIDs)
+}
+
+// count runs a query selecting target ID, reaction and count rows
+func (r *reactionRepository) count(query string, ids []uuid.UUID) (map[uuid.UUID]models.ReactionCounts, error) {
+	counts := make(map[uuid.UUID]models.ReactionCounts)
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.db.Query(query, uuidArray(ids))
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to count reactions")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id       uuid.UUID
+			reaction string
+			count    int
+		)
+		if err := rows.Scan(&id, &reaction, &count); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan reaction counts")
+		}
+		if counts[id] == nil {
+			counts[id] = make(models.ReactionCounts)
+		}
+		counts[id][reaction] = count
+	}
+
+	return counts, nil
+}
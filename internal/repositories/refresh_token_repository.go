@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
 	"go-backend-api/internal/pkg/errors"
 
 	"github.com/google/uuid"
@@ -12,20 +13,22 @@ import (
 
 // refreshTokenRepository implements RefreshTokenRepository interface
 type refreshTokenRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	clock clock.Clock
 }
 
-// NewRefreshTokenRepository creates a new refresh token repository
-func NewRefreshTokenRepository(db *sql.DB) models.RefreshTokenRepository {
-	return &refreshTokenRepository{db: db}
+// NewRefreshTokenRepository creates a new refresh token repository. Tokens expire by the time
+// of clk (the system clock when nil).
+func NewRefreshTokenRepository(db *sql.DB, clk clock.Clock) models.RefreshTokenRepository {
+	return &refreshTokenRepository{db: db, clock: clock.OrReal(clk)}
 }
 
 // Create creates a new refresh token record
-func (r *refreshTokenRepository) Create(tokenID, tokenHash string, userID uuid.UUID, expiresAt time.Time) error {
-	query := `INSERT INTO refresh_tokens (user_id, token_id, token_hash, expires_at, is_revoked, created_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6)`
+func (r *refreshTokenRepository) Create(tokenID, tokenHash string, userID uuid.UUID, expiresAt time.Time, meta models.SessionMetadata) error {
+	query := `INSERT INTO refresh_tokens (user_id, token_id, token_hash, expires_at, is_revoked, created_at, ip_address, user_agent, country, city, device_id, fingerprint) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7::inet, $8, $9, $10, $11, $12)`
 
-	_, err := r.db.Exec(query, userID, tokenID, tokenHash, expiresAt, false, time.Now())
+	_, err := r.db.Exec(query, userID, tokenID, tokenHash, expiresAt, false, r.clock.Now(), meta.IPAddress, meta.UserAgent, meta.Country, meta.City, meta.DeviceID, meta.Fingerprint)
 	if err != nil {
 		return errors.WrapError(err, "Failed to create refresh token")
 	}
@@ -52,7 +55,7 @@ func (r *refreshTokenRepository) GetByTokenID(tokenID string) (*models.RefreshTo
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, models.ErrNotFound
 		}
 		return nil, errors.WrapError(err, "Failed to get refresh token by token_id")
 	}
@@ -64,7 +67,7 @@ func (r *refreshTokenRepository) GetByTokenID(tokenID string) (*models.RefreshTo
 func (r *refreshTokenRepository) Revoke(tokenID string) error {
 	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1 WHERE token_id = $2`
 
-	_, err := r.db.Exec(query, time.Now(), tokenID)
+	_, err := r.db.Exec(query, r.clock.Now(), tokenID)
 	if err != nil {
 		return errors.WrapError(err, "Failed to revoke refresh token")
 	}
@@ -76,7 +79,7 @@ func (r *refreshTokenRepository) Revoke(tokenID string) error {
 func (r *refreshTokenRepository) RevokeAllForUser(userID uuid.UUID) error {
 	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1 WHERE user_id = $2 AND is_revoked = false`
 
-	_, err := r.db.Exec(query, time.Now(), userID)
+	_, err := r.db.Exec(query, r.clock.Now(), userID)
 	if err != nil {
 		return errors.WrapError(err, "Failed to revoke all refresh tokens for user")
 	}
@@ -84,6 +87,36 @@ func (r *refreshTokenRepository) RevokeAllForUser(userID uuid.UUID) error {
 	return nil
 }
 
+// RevokeIssuedBefore revokes the refresh tokens created at or before cutoff of the given users,
+// or of every user when userIDs is empty
+func (r *refreshTokenRepository) RevokeIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error) {
+	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1
+			  WHERE is_revoked = false AND created_at <= $2 AND (cardinality($3::uuid[]) = 0 OR user_id = ANY($3::uuid[]))`
+
+	result, err := r.db.Exec(query, r.clock.Now(), cutoff, uuidArray(userIDs))
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to revoke refresh tokens")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to revoke refresh tokens")
+	}
+	return affected, nil
+}
+
+// RevokeAllForUserExcept revokes all refresh tokens for a user except the given session
+func (r *refreshTokenRepository) RevokeAllForUserExcept(userID uuid.UUID, keepTokenID string) error {
+	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1 WHERE user_id = $2 AND token_id != $3 AND is_revoked = false`
+
+	_, err := r.db.Exec(query, r.clock.Now(), userID, keepTokenID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to revoke refresh tokens for user")
+	}
+
+	return nil
+}
+
 // IsValid checks if a refresh token is valid (exists, not revoked, not expired)
 func (r *refreshTokenRepository) IsValid(tokenID string) (bool, error) {
 	var isValid bool
@@ -91,10 +124,10 @@ func (r *refreshTokenRepository) IsValid(tokenID string) (bool, error) {
 		SELECT 1 FROM refresh_tokens 
 		WHERE token_id = $1 
 		AND is_revoked = false 
-		AND expires_at > NOW()
+		AND expires_at > $2
 	)`
 
-	err := r.db.QueryRow(query, tokenID).Scan(&isValid)
+	err := r.db.QueryRow(query, tokenID, r.clock.Now()).Scan(&isValid)
 	if err != nil {
 		return false, errors.WrapError(err, "Failed to check refresh token validity")
 	}
@@ -109,11 +142,11 @@ func (r *refreshTokenRepository) IsValidWithLock(tokenID string) (bool, error) {
 		SELECT 1 FROM refresh_tokens 
 		WHERE token_id = $1 
 		AND is_revoked = false 
-		AND expires_at > NOW()
+		AND expires_at > $2
 		FOR UPDATE
 	)`
 
-	err := r.db.QueryRow(query, tokenID).Scan(&isValid)
+	err := r.db.QueryRow(query, tokenID, r.clock.Now()).Scan(&isValid)
 	if err != nil {
 		return false, errors.WrapError(err, "Failed to check refresh token validity")
 	}
@@ -122,7 +155,7 @@ func (r *refreshTokenRepository) IsValidWithLock(tokenID string) (bool, error) {
 }
 
 // RotateToken atomically creates a new refresh token and revokes the old one in a transaction
-func (r *refreshTokenRepository) RotateToken(oldTokenID, newTokenID, newTokenHash string, userID uuid.UUID, expiresAt time.Time) error {
+func (r *refreshTokenRepository) RotateToken(oldTokenID, newTokenID, newTokenHash string, userID uuid.UUID, expiresAt time.Time, meta models.SessionMetadata) error {
 	tx, err := r.db.Begin()
 	if err != nil {
 		return errors.WrapError(err, "Failed to begin transaction")
@@ -147,27 +180,27 @@ func (r *refreshTokenRepository) RotateToken(oldTokenID, newTokenID, newTokenHas
 	err = tx.QueryRow(checkQuery, oldTokenID).Scan(&tokenID, &isRevoked, &expiresAtDB)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return errors.NewErrorWithCode(401, "Invalid refresh token")
+			return models.ErrNotFound
 		}
 		return errors.WrapError(err, "Failed to validate old token")
 	}
 
 	// Check if token is valid (not revoked and not expired)
-	if isRevoked || expiresAtDB.Before(time.Now()) {
-		return errors.NewErrorWithCode(401, "Invalid refresh token")
+	if isRevoked || expiresAtDB.Before(r.clock.Now()) {
+		return models.ErrConflict
 	}
 
 	// Create new token
-	createQuery := `INSERT INTO refresh_tokens (user_id, token_id, token_hash, expires_at, is_revoked, created_at) 
-					VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err = tx.Exec(createQuery, userID, newTokenID, newTokenHash, expiresAt, false, time.Now())
+	createQuery := `INSERT INTO refresh_tokens (user_id, token_id, token_hash, expires_at, is_revoked, created_at, ip_address, user_agent, country, city, device_id, fingerprint) 
+					VALUES ($1, $2, $3, $4, $5, $6, $7::inet, $8, $9, $10, $11, $12)`
+	_, err = tx.Exec(createQuery, userID, newTokenID, newTokenHash, expiresAt, false, r.clock.Now(), meta.IPAddress, meta.UserAgent, meta.Country, meta.City, meta.DeviceID, meta.Fingerprint)
 	if err != nil {
 		return errors.WrapError(err, "Failed to create new refresh token")
 	}
 
 	// Revoke old token
 	revokeQuery := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1 WHERE token_id = $2`
-	_, err = tx.Exec(revokeQuery, time.Now(), oldTokenID)
+	_, err = tx.Exec(revokeQuery, r.clock.Now(), oldTokenID)
 	if err != nil {
 		return errors.WrapError(err, "Failed to revoke old refresh token")
 	}
@@ -180,11 +213,89 @@ func (r *refreshTokenRepository) RotateToken(oldTokenID, newTokenID, newTokenHas
 	return nil
 }
 
+// ListActiveForUser lists the unrevoked, unexpired refresh tokens (sessions) of a user, newest first
+func (r *refreshTokenRepository) ListActiveForUser(userID uuid.UUID) ([]*models.RefreshToken, error) {
+	query := `SELECT id, user_id, token_id, token_hash, expires_at, is_revoked, created_at, revoked_at,
+			  HOST(ip_address), user_agent, country, city, device_id, fingerprint
+			  FROM refresh_tokens WHERE user_id = $1 AND is_revoked = false AND expires_at > $2
+			  ORDER BY created_at DESC`
+
+	rows, err := r.db.Query(query, userID, r.clock.Now())
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list refresh tokens")
+	}
+	defer rows.Close()
+
+	var tokens []*models.RefreshToken
+	for rows.Next() {
+		token := &models.RefreshToken{}
+		err := rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.TokenID,
+			&token.TokenHash,
+			&token.ExpiresAt,
+			&token.IsRevoked,
+			&token.CreatedAt,
+			&token.RevokedAt,
+			&token.IPAddress,
+			&token.UserAgent,
+			&token.Country,
+			&token.City,
+			&token.DeviceID,
+			&token.Fingerprint,
+		)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan refresh token")
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
+
+// RevokeForDevice revokes the active refresh tokens of one device of a user.
+// Devices are identified by their client-provided ID, or by fingerprint when no ID was provided.
+func (r *refreshTokenRepository) RevokeForDevice(userID uuid.UUID, deviceID, fingerprint string) error {
+	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1
+			  WHERE user_id = $2 AND is_revoked = false AND device_id = $3`
+	arg := deviceID
+	if deviceID == "" {
+		query = `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1
+				 WHERE user_id = $2 AND is_revoked = false AND device_id IS NULL AND fingerprint = $3`
+		arg = fingerprint
+	}
+
+	_, err := r.db.Exec(query, r.clock.Now(), userID, arg)
+	if err != nil {
+		return errors.WrapError(err, "Failed to revoke device refresh tokens")
+	}
+
+	return nil
+}
+
+// RevokeOldestBeyond revokes the oldest active refresh tokens of a user so that at most keep remain
+func (r *refreshTokenRepository) RevokeOldestBeyond(userID uuid.UUID, keep int) error {
+	query := `UPDATE refresh_tokens SET is_revoked = true, revoked_at = $1
+			  WHERE id IN (
+				  SELECT id FROM refresh_tokens
+				  WHERE user_id = $2 AND is_revoked = false AND expires_at > $1
+				  ORDER BY created_at DESC OFFSET $3
+			  )`
+
+	_, err := r.db.Exec(query, r.clock.Now(), userID, keep)
+	if err != nil {
+		return errors.WrapError(err, "Failed to evict old refresh tokens")
+	}
+
+	return nil
+}
+
 // DeleteExpired deletes expired refresh tokens
 func (r *refreshTokenRepository) DeleteExpired() error {
-	query := `DELETE FROM refresh_tokens WHERE expires_at < NOW() OR (is_revoked = true AND revoked_at < NOW() - INTERVAL '7 days')`
+	query := `DELETE FROM refresh_tokens WHERE expires_at < $1 OR (is_revoked = true AND revoked_at < $1 - INTERVAL '7 days')`
 
-	_, err := r.db.Exec(query)
+	_, err := r.db.Exec(query, r.clock.Now())
 	if err != nil {
 		return errors.WrapError(err, "Failed to delete expired refresh tokens")
 	}
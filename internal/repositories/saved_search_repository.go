@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/ids"
+
+	"github.com/google/uuid"
+)
+
+// savedSearchRepository implements SavedSearchRepository interface
+type savedSearchRepository struct {
+	db *sql.DB
+}
+
+// NewSavedSearchRepository creates a new saved search repository
+func NewSavedSearchRepository(db *sql.DB) models.SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+// Create creates a new saved search
+func (r *savedSearchRepository) Create(search *models.SavedSearch) error {
+	if search.ID == uuid.Nil {
+		search.ID = ids.New()
+	}
+
+	query := `INSERT INTO saved_searches (id, user_id, name, query, frequency, checked_at, next_check_at, created_at, updated_at)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+
+	_, err := r.db.Exec(query, search.ID, search.UserID, search.Name, search.Query, search.Frequency,
+		search.CheckedAt, search.NextCheckAt, search.CreatedAt, search.UpdatedAt)
+	if err != nil {
+		return writeError(err, "Failed to create saved search")
+	}
+
+	return nil
+}
+
+// GetByID gets a saved search by ID
+func (r *savedSearchRepository) GetByID(id uuid.UUID) (*models.SavedSearch, error) {
+	return r.getOne(`SELECT `+savedSearchColumns+` FROM saved_searches WHERE id = $1`, id)
+}
+
+// GetByUnsubscribeTokenHash gets a saved search by the hash of the unsubscribe token of its latest alert
+func (r *savedSearchRepository) GetByUnsubscribeTokenHash(tokenHash string) (*models.SavedSearch, error) {
+	return r.getOne(`SELECT `+savedSearchColumns+` FROM saved_searches WHERE unsubscribe_token_hash = $1`, tokenHash)
+}
+
+// getOne runs a single-row saved search query
+func (r *savedSearchRepository) getOne(query string, arg interface{}) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{}
+
+	err := r.db.QueryRow(query, arg).Scan(savedSearchFields(search)...)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get saved search")
+	}
+
+	return search, nil
+}
+
+// ListByUser lists the saved searches of a user, oldest first
+func (r *savedSearchRepository) ListByUser(userID uuid.UUID) ([]*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id = $1 ORDER BY created_at, id`
+
+	return r.list(query, userID)
+}
+
+// CountByUser returns how many searches a user saved
+func (r *savedSearchRepository) CountByUser(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM saved_searches WHERE user_id = $1`
+
+	if err := r.db.QueryRow(query, userID).Scan(&count); err != nil {
+		return 0, errors.WrapError(err, "Failed to count saved searches")
+	}
+
+	return count, nil
+}
+
+// ListDue lists the searches with alerts whose next check is due, the longest overdue first
+func (r *savedSearchRepository) ListDue(now time.Time, limit int) ([]*models.SavedSearch, error) {
+	query := `SELECT ` + savedSearchColumns + ` FROM saved_searches
+			  WHERE frequency <> $1 AND next_check_at <= $2
+			  ORDER BY next_check_at, id LIMIT $3`
+
+	return r.list(query, models.SavedSearchOff, now, limit)
+}
+
+// list runs a saved search listing query
+func (r *savedSearchRepository) list(query string, args ...interface{}) ([]*models.SavedSearch, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list saved searches")
+	}
+	defer rows.Close()
+
+	searches := []*models.SavedSearch{}
+	for rows.Next() {
+		search := &models.SavedSearch{}
+		if err := rows.Scan(savedSearchFields(search)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan saved search")
+		}
+		searches = append(searches, search)
+	}
+
+	return searches, rows.Err()
+}
+
+// Matches gets the published posts the search's user may list whose title or content match
+// the query, published after the search was checked and until until, oldest first
+func (r *savedSearchRepository) Matches(search *models.SavedSearch, until time.Time, limit int) ([]*models.Post, error) {
+	query := `SELECT ` + postColumns + `
+			  FROM posts p
+			  WHERE p.is_published = true AND p.archived_at IS NULL
+			  AND p.published_at > $2 AND p.published_at <= $3
+			  AND p.author_id <> $1 AND ` + listedTo("p", "$1") + `
+			  AND to_tsvector('simple', p.title || ' ' || p.content) @@ websearch_to_tsquery('simple', $4)
+			  ORDER BY p.published_at, p.id LIMIT $5`
+
+	rows, err := r.db.Query(query, search.UserID, search.CheckedAt, until, search.Query, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to match saved search")
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post := &models.Post{}
+		if err := rows.Scan(postFields(post)...); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post")
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Update updates a saved search
+func (r *savedSearchRepository) Update(search *models.SavedSearch) error {
+	query := `UPDATE saved_searches SET name = $1, query = $2, frequency = $3, checked_at = $4, next_check_at = $5,
+			  unsubscribe_token_hash = $6, updated_at = $7 WHERE id = $8`
+
+	result, err := r.db.Exec(query, search.Name, search.Query, search.Frequency, search.CheckedAt, search.NextCheckAt,
+		search.UnsubscribeTokenHash, search.UpdatedAt, search.ID)
+	if err != nil {
+		return writeError(err, "Failed to update saved search")
+	}
+
+	return requireRowsAffected(result, "Failed to update saved search")
+}
+
+// Delete deletes a saved search
+func (r *savedSearchRepository) Delete(id uuid.UUID) error {
+	query := `DELETE FROM saved_searches WHERE id = $1`
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return writeError(err, "Failed to delete saved search")
+	}
+
+	return requireRowsAffected(result, "Failed to delete saved search")
+}
@@ -0,0 +1,114 @@
+//go:build integration
+
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/testutil"
+)
+
+func TestUsedSignaturesAreUsedOnce(t *testing.T) {
+	repo := NewUsedSignatureRepository(testutil.DB(t))
+	signature := strings.ReplaceAll(ids.New().String()+ids.New().String(), "-", "")
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+
+	for i, want := range []bool{true, false} {
+		fresh, err := repo.Use(signature, now.Add(time.Minute))
+		if err != nil {
+			t.Fatalf("Use: %v", err)
+		}
+		if fresh != want {
+			t.Errorf("use %d: got fresh %v, want %v", i+1, fresh, want)
+		}
+	}
+
+	if deleted, err := repo.DeleteExpired(now); err != nil || deleted != 0 {
+		t.Errorf("DeleteExpired before expiry = %d, %v; want none", deleted, err)
+	}
+	if deleted, err := repo.DeleteExpired(now.Add(2 * time.Minute)); err != nil || deleted < 1 {
+		t.Errorf("DeleteExpired after expiry = %d, %v; want it deleted", deleted, err)
+	}
+}
+
+func TestIncidentModeRoundTrip(t *testing.T) {
+	db := testutil.DB(t)
+	repo := NewIncidentModeRepository(db)
+	admin := newTestUser(true, false, nil)
+	if err := NewUserRepository(db, nil).Create(admin); err != nil {
+		t.Fatalf("Create user: %v", err)
+	}
+	if err := repo.Disable(); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+
+	if mode, err := repo.Get(); err != nil || mode.Enabled {
+		t.Fatalf("Get while disabled = %+v, %v", mode, err)
+	}
+
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	revocation := &models.TokenRevocation{Cutoff: now, Users: 3, Sessions: 5, Clients: 2}
+	if err := repo.Enable(&models.IncidentMode{Enabled: true, EnabledAt: &now, EnabledBy: &admin.ID, Revocation: revocation}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	mode, err := repo.Get()
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if !mode.Enabled || !mode.EnabledAt.Equal(now) || *mode.EnabledBy != admin.ID || *mode.Revocation != *revocation {
+		t.Errorf("Get = %+v, want it enabled by the admin with the revocation", mode)
+	}
+
+	// Enabling from the config replaces the state, without an admin or revocation
+	later := now.Add(time.Hour)
+	if err := repo.Enable(&models.IncidentMode{Enabled: true, EnabledAt: &later}); err != nil {
+		t.Fatalf("Enable: %v", err)
+	}
+	if mode, err := repo.Get(); err != nil || !mode.EnabledAt.Equal(later) || mode.EnabledBy != nil || mode.Revocation != nil {
+		t.Errorf("Get = %+v, %v; want it enabled without an admin", mode, err)
+	}
+
+	if err := repo.Disable(); err != nil {
+		t.Fatalf("Disable: %v", err)
+	}
+	if mode, err := repo.Get(); err != nil || mode.Enabled {
+		t.Errorf("Get after Disable = %+v, %v; want it disabled", mode, err)
+	}
+}
+
+func TestDenyAllClientTokensSkipsRevokedClientsAndEarlierCutoffs(t *testing.T) {
+	repo := NewOAuthClientRepository(testutil.DB(t))
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	newClient := func() *models.OAuthClient {
+		client := &models.OAuthClient{ClientID: "client_" + ids.New().String(), SecretHash: "hash", Name: "cron", Scopes: []string{}, CreatedAt: now}
+		if err := repo.Create(client); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		return client
+	}
+	active, revoked := newClient(), newClient()
+	if err := repo.Revoke(revoked.ID); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+
+	for _, cutoff := range []time.Time{now, now.Add(-time.Hour)} {
+		if _, err := repo.DenyAllTokensIssuedBefore(cutoff); err != nil {
+			t.Fatalf("DenyAllTokensIssuedBefore: %v", err)
+		}
+	}
+
+	got, err := repo.GetByID(active.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if got.TokensDeniedBefore == nil || !got.TokensDeniedBefore.Equal(now) {
+		t.Errorf("active client denies tokens before %v, want %s", got.TokensDeniedBefore, now)
+	}
+	if got, err := repo.GetByID(revoked.ID); err != nil || got.TokensDeniedBefore != nil {
+		t.Errorf("revoked client = %+v, %v; want no cutoff", got, err)
+	}
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// syncRepository implements SyncRepository interface
+type syncRepository struct {
+	db *sql.DB
+}
+
+// NewSyncRepository creates a new sync repository
+func NewSyncRepository(db *sql.DB) models.SyncRepository {
+	return &syncRepository{db: db}
+}
+
+// PostChanges gets the posts changed after a checkpoint. Posts change at updated_at and
+// deleted posts at the deleted_at of their tombstone; each post appears once, at its last change.
+// Posts the viewer may not list are left out, so unlisted posts cannot be found by syncing.
+func (r *syncRepository) PostChanges(since models.SyncCheckpoint, viewerID uuid.UUID, limit int) ([]*models.PostChange, error) {
+	query := `SELECT id, changed_at, created_at, visible FROM (
+				  SELECT id, updated_at AS changed_at, created_at, archived_at IS NULL AS visible
+				  FROM posts p WHERE (updated_at, id) > ($1, $2) AND ` + listedTo("p", "$5") + `
+				  UNION ALL
+				  SELECT resource_id, deleted_at, NULL, false
+				  FROM deleted_records WHERE resource_type = $3 AND (deleted_at, resource_id) > ($1, $2)
+			  ) changes
+			  ORDER BY changed_at, id LIMIT $4`
+
+	rows, err := r.db.Query(query, since.At, since.ID, models.ResourcePost, limit, viewerID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get post changes")
+	}
+	defer rows.Close()
+
+	changes := []*models.PostChange{}
+	for rows.Next() {
+		change := &models.PostChange{}
+		var createdAt sql.NullTime
+		if err := rows.Scan(&change.ID, &change.ChangedAt, &createdAt, &change.Visible); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan post change")
+		}
+		if createdAt.Valid {
+			change.CreatedAt = &createdAt.Time
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
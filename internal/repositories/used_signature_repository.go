@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// usedSignatureRepository implements UsedSignatureRepository interface
+type usedSignatureRepository struct {
+	db *sql.DB
+}
+
+// NewUsedSignatureRepository creates a new used signature repository
+func NewUsedSignatureRepository(db *sql.DB) models.UsedSignatureRepository {
+	return &usedSignatureRepository{db: db}
+}
+
+// Use records signature as used until expiresAt, reporting false if it already was
+func (r *usedSignatureRepository) Use(signature string, expiresAt time.Time) (bool, error) {
+	result, err := r.db.Exec(`INSERT INTO used_signatures (signature, expires_at) VALUES ($1, $2)
+			  ON CONFLICT (signature) DO NOTHING`, signature, expiresAt)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to record used signature")
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to record used signature")
+	}
+
+	return inserted == 1, nil
+}
+
+// DeleteExpired forgets the signatures that expired before now
+func (r *usedSignatureRepository) DeleteExpired(now time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM used_signatures WHERE expires_at < $1`, now)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to delete used signatures")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to delete used signatures")
+	}
+
+	return deleted, nil
+}
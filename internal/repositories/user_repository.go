@@ -2,32 +2,103 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 
 	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
 	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/fieldcrypt"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/normalize"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // userRepository implements UserRepository interface
 type userRepository struct {
-	db *sql.DB
+	db    *sql.DB
+	clock clock.Clock
 }
 
-// NewUserRepository creates a new user repository
-func NewUserRepository(db *sql.DB) models.UserRepository {
-	return &userRepository{db: db}
+// NewUserRepository creates a new user repository. Timestamps it sets come from clk (the
+// system clock when nil).
+func NewUserRepository(db *sql.DB, clk clock.Clock) models.UserRepository {
+	return &userRepository{db: db, clock: clock.OrReal(clk)}
 }
 
 // Create creates a new user
 func (r *userRepository) Create(user *models.User) error {
-	query := `INSERT INTO users (username, email, password, is_active, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`
+	if user.Role == "" {
+		user.Role = models.RoleUser
+	}
+	if user.Plan == "" {
+		user.Plan = models.PlanFree
+	}
+	if user.ID == uuid.Nil {
+		user.ID = ids.New()
+	}
+
+	query := `INSERT INTO users (id, username, username_skeleton, email, password, role, plan, is_active, must_change_password, last_login, birthdate, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := r.db.Exec(query, user.ID, user.Username, normalize.UsernameSkeleton(user.Username), user.Email, user.Password, user.Role, user.Plan,
+		user.IsActive, user.MustChangePassword, user.LastLogin, user.Birthdate, user.CreatedAt, user.UpdatedAt)
+	if err != nil {
+		return writeError(err, "Failed to create user")
+	}
+
+	return nil
+}
+
+// CreateBatch creates users in a single transaction, so either all of them or none are created.
+// IDs are generated for users without one.
+func (r *userRepository) CreateBatch(users []*models.User) error {
+	userIDs := make([]string, len(users))
+	usernames := make([]string, len(users))
+	skeletons := make([]string, len(users))
+	emails := make([]string, len(users))
+	passwords := make([]string, len(users))
+	roles := make([]string, len(users))
+	plans := make([]string, len(users))
+	active := make([]bool, len(users))
+	mustChange := make([]bool, len(users))
+	lastLogin := make([]*time.Time, len(users))
+	createdAt := make([]time.Time, len(users))
+	for i, user := range users {
+		if user.ID == uuid.Nil {
+			user.ID = ids.New()
+		}
+		if user.Role == "" {
+			user.Role = models.RoleUser
+		}
+		if user.Plan == "" {
+			user.Plan = models.PlanFree
+		}
+		userIDs[i] = user.ID.String()
+		usernames[i] = user.Username
+		skeletons[i] = normalize.UsernameSkeleton(user.Username)
+		emails[i] = user.Email
+		passwords[i] = user.Password
+		roles[i] = user.Role
+		plans[i] = user.Plan
+		active[i] = user.IsActive
+		mustChange[i] = user.MustChangePassword
+		lastLogin[i] = user.LastLogin
+		createdAt[i] = user.CreatedAt
+	}
 
-	err := r.db.QueryRow(query, user.Username, user.Email, user.Password, user.IsActive, user.CreatedAt, user.UpdatedAt).Scan(&user.ID)
+	// A single multi-row statement is atomic on its own
+	query := `INSERT INTO users (id, username, username_skeleton, email, password, role, plan, is_active, must_change_password, last_login, created_at, updated_at)
+			  SELECT id, username, skeleton, email, password, role, plan, is_active, must_change, last_login, created_at, created_at
+			  FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $5::text[], $6::text[], $7::text[], $8::boolean[], $9::boolean[], $10::timestamp[], $11::timestamp[])
+			       AS u(id, username, skeleton, email, password, role, plan, is_active, must_change, last_login, created_at)`
+
+	_, err := r.db.Exec(query, pq.Array(userIDs), pq.Array(usernames), pq.Array(skeletons), pq.Array(emails), pq.Array(passwords),
+		pq.Array(roles), pq.Array(plans), pq.Array(active), pq.Array(mustChange), pq.Array(lastLogin), pq.Array(createdAt))
 	if err != nil {
-		return errors.WrapError(err, "Failed to create user")
+		return writeError(err, "Failed to create users")
 	}
 
 	return nil
@@ -36,15 +107,13 @@ func (r *userRepository) Create(user *models.User) error {
 // GetByID gets a user by ID
 func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, username, email, password, is_active, last_login, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
 
-	err := r.db.QueryRow(query, id).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.IsActive, &user.LastLogin, &user.CreatedAt, &user.UpdatedAt,
-	)
+	err := r.db.QueryRow(query, id).Scan(userFields(user)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, models.ErrNotFound
 		}
 		return nil, errors.WrapError(err, "Failed to get user by ID")
 	}
@@ -55,15 +124,13 @@ func (r *userRepository) GetByID(id uuid.UUID) (*models.User, error) {
 // GetByEmail gets a user by email
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, username, email, password, is_active, last_login, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(email) = LOWER($1)`
 
-	err := r.db.QueryRow(query, email).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.IsActive, &user.LastLogin, &user.CreatedAt, &user.UpdatedAt,
-	)
+	err := r.db.QueryRow(query, email).Scan(userFields(user)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, models.ErrNotFound
 		}
 		return nil, errors.WrapError(err, "Failed to get user by email")
 	}
@@ -74,15 +141,13 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 // GetByUsername gets a user by username
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	user := &models.User{}
-	query := `SELECT id, username, email, password, is_active, last_login, created_at, updated_at FROM users WHERE username = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE LOWER(username) = LOWER($1)`
 
-	err := r.db.QueryRow(query, username).Scan(
-		&user.ID, &user.Username, &user.Email, &user.Password, &user.IsActive, &user.LastLogin, &user.CreatedAt, &user.UpdatedAt,
-	)
+	err := r.db.QueryRow(query, username).Scan(userFields(user)...)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, nil
+			return nil, models.ErrNotFound
 		}
 		return nil, errors.WrapError(err, "Failed to get user by username")
 	}
@@ -90,25 +155,112 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	return user, nil
 }
 
-// Update updates a user
+// Update updates a user. Changing the email clears its undeliverable mark, which belonged to
+// the old address.
 func (r *userRepository) Update(user *models.User) error {
-	query := `UPDATE users SET username = $1, email = $2, is_active = $3, last_login = $4, updated_at = $5 WHERE id = $6`
-
-	_, err := r.db.Exec(query, user.Username, user.Email, user.IsActive, user.LastLogin, user.UpdatedAt, user.ID)
+	query := `UPDATE users SET username = $1, username_skeleton = $2, email = $3, pending_email = $4, is_active = $5, last_login = $6,
+			  phone_number = $7, phone_number_index = $8, updated_at = $9,
+			  email_undeliverable = CASE WHEN LOWER(email) = LOWER($3) THEN email_undeliverable END,
+			  email_undeliverable_at = CASE WHEN LOWER(email) = LOWER($3) THEN email_undeliverable_at END
+			  WHERE id = $10`
+
+	result, err := r.db.Exec(query, user.Username, normalize.UsernameSkeleton(user.Username), user.Email, user.PendingEmail, user.IsActive, user.LastLogin,
+		user.PhoneNumber, user.PhoneNumberIndex, user.UpdatedAt, user.ID)
 	if err != nil {
-		return errors.WrapError(err, "Failed to update user")
+		return writeError(err, "Failed to update user")
 	}
 
-	return nil
+	return requireRowsAffected(result, "Failed to update user")
 }
 
 // Delete deletes a user
 func (r *userRepository) Delete(id uuid.UUID) error {
 	query := `DELETE FROM users WHERE id = $1`
 
-	_, err := r.db.Exec(query, id)
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		return writeError(err, "Failed to delete user")
+	}
+
+	return requireRowsAffected(result, "Failed to delete user")
+}
+
+// DeleteCascade deletes a user and their data in one transaction
+func (r *userRepository) DeleteCascade(deletion *models.UserDeletion, client models.ClientInfo) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	// Lock the user so no posts or sessions are added while their data is removed
+	err = tx.QueryRow(`SELECT username FROM users WHERE id = $1 FOR UPDATE`, deletion.UserID).Scan(&deletion.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return models.ErrNotFound
+		}
+		return errors.WrapError(err, "Failed to get user")
+	}
+
+	postsQuery := `DELETE FROM posts WHERE author_id = $1`
+	if deletion.PostsPolicy == models.DeletedUserPostsAnonymize {
+		postsQuery = `UPDATE posts SET author_id = NULL WHERE author_id = $1`
+	}
+	result, err := tx.Exec(postsQuery, deletion.UserID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to remove posts")
+	}
+	posts, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, "Failed to remove posts")
+	}
+	deletion.Posts = int(posts)
+
+	result, err = tx.Exec(`DELETE FROM refresh_tokens WHERE user_id = $1`, deletion.UserID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+	sessions, err := result.RowsAffected()
+	if err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+	deletion.Sessions = int(sessions)
+
+	// Remaining user data (history, API keys, challenges, stats) is removed by ON DELETE CASCADE
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = $1`, deletion.UserID); err != nil {
+		return writeError(err, "Failed to delete user")
+	}
+
+	// The entry outlives the user, so it references them as the resource rather than the actor
+	details, err := json.Marshal(deletion)
 	if err != nil {
-		return errors.WrapError(err, "Failed to delete user")
+		return errors.WrapError(err, "Failed to encode audit details")
+	}
+	resourceType := "user"
+	entry := &models.AuditLog{
+		Action:       models.AuditActionUserDeleted,
+		ResourceType: &resourceType,
+		ResourceID:   &deletion.UserID,
+		Details:      details,
+		CreatedAt:    r.clock.Now(),
+	}
+	if client.IPAddress != "" {
+		entry.IPAddress = &client.IPAddress
+	}
+	if client.UserAgent != "" {
+		entry.UserAgent = &client.UserAgent
+	}
+	if err := insertAuditLog(tx, entry); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.WrapError(err, "Failed to commit user deletion")
 	}
 
 	return nil
@@ -117,7 +269,7 @@ func (r *userRepository) Delete(id uuid.UUID) error {
 // UpdateLastLogin updates the last login time for a user
 func (r *userRepository) UpdateLastLogin(id uuid.UUID) error {
 	query := `UPDATE users SET last_login = $1, updated_at = $2 WHERE id = $3`
-	now := time.Now()
+	now := r.clock.Now()
 
 	_, err := r.db.Exec(query, now, now, id)
 	if err != nil {
@@ -127,36 +279,333 @@ func (r *userRepository) UpdateLastLogin(id uuid.UUID) error {
 	return nil
 }
 
+// UpdateLastSeen records activity for a user, skipping the write if the stored value is newer than the throttle window
+func (r *userRepository) UpdateLastSeen(id uuid.UUID, seenAt time.Time, throttle time.Duration) error {
+	query := `UPDATE users SET last_seen_at = $1 WHERE id = $2 AND (last_seen_at IS NULL OR last_seen_at < $3)`
+
+	_, err := r.db.Exec(query, seenAt, id, seenAt.Add(-throttle))
+	if err != nil {
+		return errors.WrapError(err, "Failed to update last seen")
+	}
+
+	return nil
+}
+
+// List gets users ordered by creation date, newest first
+func (r *userRepository) List(limit, offset int) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + `
+			  FROM users ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	rows, err := r.db.Query(query, limit, offset)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list users")
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(userFields(user)...)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan user")
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// Export calls fn with every user, oldest first, stopping at the first error
+func (r *userRepository) Export(fn func(*models.User) error) error {
+	query := `SELECT ` + userColumns + `
+			  FROM users WHERE (created_at, id) > ($1, $2) ORDER BY created_at, id LIMIT $3`
+
+	var afterCreated time.Time
+	var afterID uuid.UUID
+	for {
+		rows, err := r.db.Query(query, afterCreated, afterID, exportBatchSize)
+		if err != nil {
+			return errors.WrapError(err, "Failed to export users")
+		}
+
+		batch := make([]*models.User, 0, exportBatchSize)
+		for rows.Next() {
+			user := &models.User{}
+			err := rows.Scan(userFields(user)...)
+			if err != nil {
+				rows.Close()
+				return errors.WrapError(err, "Failed to scan user")
+			}
+			batch = append(batch, user)
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return errors.WrapError(err, "Failed to export users")
+		}
+
+		for _, user := range batch {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		afterCreated, afterID = last.CreatedAt, last.ID
+	}
+}
+
+// Count returns the total number of users
+func (r *userRepository) Count() (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM users`
+
+	err := r.db.QueryRow(query).Scan(&count)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to count users")
+	}
+
+	return count, nil
+}
+
 // Activate activates a user account
 func (r *userRepository) Activate(id uuid.UUID) error {
-	query := `UPDATE users SET is_active = true, updated_at = $1 WHERE id = $2`
-	now := time.Now()
+	query := `UPDATE users SET is_active = true, deactivated_at = NULL, updated_at = $1 WHERE id = $2`
+	now := r.clock.Now()
 
-	_, err := r.db.Exec(query, now, id)
+	result, err := r.db.Exec(query, now, id)
 	if err != nil {
-		return errors.WrapError(err, "Failed to activate user")
+		return writeError(err, "Failed to activate user")
 	}
 
-	return nil
+	return requireRowsAffected(result, "Failed to activate user")
 }
 
 // Deactivate deactivates a user account
 func (r *userRepository) Deactivate(id uuid.UUID) error {
-	query := `UPDATE users SET is_active = false, updated_at = $1 WHERE id = $2`
-	now := time.Now()
+	query := `UPDATE users SET is_active = false, deactivated_at = COALESCE(deactivated_at, $1), updated_at = $1 WHERE id = $2`
+	now := r.clock.Now()
+
+	result, err := r.db.Exec(query, now, id)
+	if err != nil {
+		return writeError(err, "Failed to deactivate user")
+	}
+
+	return requireRowsAffected(result, "Failed to deactivate user")
+}
+
+// UpdatePassword sets a new password hash and clears any forced password change
+func (r *userRepository) UpdatePassword(id uuid.UUID, hashedPassword string) error {
+	query := `UPDATE users SET password = $1, must_change_password = false, updated_at = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, hashedPassword, r.clock.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to update password")
+	}
+
+	return requireRowsAffected(result, "Failed to update password")
+}
+
+// SetMustChangePassword sets whether a user must change their password before using the API
+func (r *userRepository) SetMustChangePassword(id uuid.UUID, mustChange bool) error {
+	query := `UPDATE users SET must_change_password = $1, updated_at = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, mustChange, r.clock.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to update must change password flag")
+	}
+
+	return requireRowsAffected(result, "Failed to update must change password flag")
+}
+
+// UpdatePlan moves a user to another plan
+func (r *userRepository) UpdatePlan(id uuid.UUID, plan string) error {
+	query := `UPDATE users SET plan = $1, updated_at = $2 WHERE id = $3`
+
+	result, err := r.db.Exec(query, plan, r.clock.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to update plan")
+	}
+
+	return requireRowsAffected(result, "Failed to update plan")
+}
+
+// MustChangePassword checks if a user is required to change their password
+func (r *userRepository) MustChangePassword(id uuid.UUID) (bool, error) {
+	var mustChange bool
+	query := `SELECT must_change_password FROM users WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&mustChange)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, models.ErrNotFound
+		}
+		return false, errors.WrapError(err, "Failed to check must change password flag")
+	}
+
+	return mustChange, nil
+}
+
+// DenyTokensIssuedBefore rejects a user's access tokens issued at or before cutoff
+func (r *userRepository) DenyTokensIssuedBefore(id uuid.UUID, cutoff time.Time) error {
+	query := `UPDATE users SET tokens_denied_before = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(query, cutoff, id)
+	if err != nil {
+		return writeError(err, "Failed to deny tokens")
+	}
+
+	return requireRowsAffected(result, "Failed to deny tokens")
+}
 
-	_, err := r.db.Exec(query, now, id)
+// DenyAllTokensIssuedBefore rejects the access tokens issued at or before cutoff of the given
+// users, or of every user when userIDs is empty. An existing later cutoff is kept.
+func (r *userRepository) DenyAllTokensIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error) {
+	query := `UPDATE users SET tokens_denied_before = GREATEST(COALESCE(tokens_denied_before, $1), $1)
+			  WHERE cardinality($2::uuid[]) = 0 OR id = ANY($2::uuid[])`
+
+	result, err := r.db.Exec(query, cutoff, uuidArray(userIDs))
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to deny tokens")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to deny tokens")
+	}
+	return affected, nil
+}
+
+// GetTokenState gets whether a user is active, their role and which of their access tokens are denied
+func (r *userRepository) GetTokenState(id uuid.UUID) (*models.UserTokenState, error) {
+	state := &models.UserTokenState{}
+	query := `SELECT is_active, role, tokens_denied_before FROM users WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(&state.IsActive, &state.Role, &state.TokensDeniedBefore)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get token state")
+	}
+
+	return state, nil
+}
+
+// ListInactiveSince lists users whose last activity (last seen, last login or signup) is before cutoff
+func (r *userRepository) ListInactiveSince(cutoff time.Time) ([]*models.User, error) {
+	query := `SELECT ` + userColumns + `
+			  FROM users WHERE COALESCE(last_seen_at, last_login, created_at) < $1
+			  ORDER BY COALESCE(last_seen_at, last_login, created_at) ASC`
+
+	rows, err := r.db.Query(query, cutoff)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list inactive users")
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user := &models.User{}
+		err := rows.Scan(userFields(user)...)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan user")
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// ListIDsByRoleCreatedBefore lists the IDs of up to limit users with a role created before cutoff, oldest first
+func (r *userRepository) ListIDsByRoleCreatedBefore(role string, cutoff time.Time, limit int) ([]uuid.UUID, error) {
+	query := `SELECT id FROM users WHERE role = $1 AND created_at < $2 ORDER BY created_at ASC LIMIT $3`
+
+	rows, err := r.db.Query(query, role, cutoff, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list users by role")
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, errors.WrapError(err, "Failed to scan user ID")
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.WrapError(err, "Failed to list users by role")
+	}
+
+	return ids, nil
+}
+
+// MarkInactivityWarned records when a user was warned about account inactivity
+func (r *userRepository) MarkInactivityWarned(id uuid.UUID, warnedAt time.Time) error {
+	query := `UPDATE users SET inactivity_warned_at = $1 WHERE id = $2`
+
+	_, err := r.db.Exec(query, warnedAt, id)
 	if err != nil {
-		return errors.WrapError(err, "Failed to deactivate user")
+		return errors.WrapError(err, "Failed to mark inactivity warning")
 	}
 
 	return nil
 }
 
+// MarkEmailUndeliverable marks the address of a user as undeliverable, keeping the first time
+// it was reported and never replacing a complaint with a bounce
+func (r *userRepository) MarkEmailUndeliverable(email, reason string, at time.Time) (bool, error) {
+	query := `UPDATE users SET
+			      email_undeliverable = CASE WHEN email_undeliverable = $2 THEN email_undeliverable ELSE $3 END,
+			      email_undeliverable_at = COALESCE(email_undeliverable_at, $4)
+			  WHERE LOWER(email) = LOWER($1)`
+
+	result, err := r.db.Exec(query, email, models.EmailComplaint, reason, at)
+	if err != nil {
+		return false, writeError(err, "Failed to mark email undeliverable")
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to mark email undeliverable")
+	}
+
+	return affected > 0, nil
+}
+
+// ClearEmailUndeliverable marks the address of a user as deliverable again
+func (r *userRepository) ClearEmailUndeliverable(id uuid.UUID) error {
+	query := `UPDATE users SET email_undeliverable = NULL, email_undeliverable_at = NULL, updated_at = $1 WHERE id = $2`
+
+	result, err := r.db.Exec(query, r.clock.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to clear undeliverable email")
+	}
+
+	return requireRowsAffected(result, "Failed to clear undeliverable email")
+}
+
+// IsEmailUndeliverable checks if the address of a user is marked undeliverable
+func (r *userRepository) IsEmailUndeliverable(email string) (bool, error) {
+	var undeliverable bool
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1) AND email_undeliverable IS NOT NULL)`
+
+	err := r.db.QueryRow(query, email).Scan(&undeliverable)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check undeliverable email")
+	}
+
+	return undeliverable, nil
+}
+
 // ExistsByEmail checks if a user exists with the given email
 func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER($1))`
 
 	err := r.db.QueryRow(query, email).Scan(&exists)
 	if err != nil {
@@ -169,7 +618,7 @@ func (r *userRepository) ExistsByEmail(email string) (bool, error) {
 // ExistsByUsername checks if a user exists with the given username
 func (r *userRepository) ExistsByUsername(username string) (bool, error) {
 	var exists bool
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1))`
 
 	err := r.db.QueryRow(query, username).Scan(&exists)
 	if err != nil {
@@ -178,3 +627,155 @@ func (r *userRepository) ExistsByUsername(username string) (bool, error) {
 
 	return exists, nil
 }
+
+// ExistsByConfusableUsername checks if another user has a username that looks like the given one
+func (r *userRepository) ExistsByConfusableUsername(username string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username_skeleton = $1 AND id != $2)`
+
+	err := r.db.QueryRow(query, normalize.UsernameSkeleton(username), excludeID).Scan(&exists)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check confusable username existence")
+	}
+
+	return exists, nil
+}
+
+// ExistsByPhoneNumberIndex checks if another user has the phone number of a blind index
+func (r *userRepository) ExistsByPhoneNumberIndex(index string, excludeID uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone_number_index = $1 AND id != $2)`
+
+	err := r.db.QueryRow(query, index, excludeID).Scan(&exists)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check phone number existence")
+	}
+
+	return exists, nil
+}
+
+// ReencryptPhoneNumbers re-encrypts a batch of phone numbers not encrypted with the active key.
+// Scanning decrypts them with whichever key they were encrypted with and writing them back
+// encrypts them with the active one; app.key_rotation keeps updated_at as it was.
+func (r *userRepository) ReencryptPhoneNumbers(activeKeyID string, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to begin transaction")
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			// Ignore error - transaction may already be committed
+			_ = err
+		}
+	}()
+
+	if _, err := tx.Exec(`SET LOCAL app.key_rotation = 'on'`); err != nil {
+		return 0, errors.WrapError(err, "Failed to start key rotation")
+	}
+
+	// Key ids cannot contain LIKE wildcards
+	rows, err := tx.Query(`SELECT id, phone_number FROM users
+			  WHERE phone_number IS NOT NULL AND phone_number NOT LIKE $1 || ':%'
+			  LIMIT $2 FOR UPDATE SKIP LOCKED`, activeKeyID, limit)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to list phone numbers to re-encrypt")
+	}
+	defer rows.Close()
+
+	ids := []uuid.UUID{}
+	phoneNumbers := []fieldcrypt.String{}
+	for rows.Next() {
+		var id uuid.UUID
+		var phoneNumber fieldcrypt.String
+		if err := rows.Scan(&id, &phoneNumber); err != nil {
+			return 0, errors.WrapError(err, "Failed to decrypt phone number")
+		}
+		ids = append(ids, id)
+		phoneNumbers = append(phoneNumbers, phoneNumber)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, errors.WrapError(err, "Failed to list phone numbers to re-encrypt")
+	}
+	rows.Close()
+
+	for i, id := range ids {
+		if _, err := tx.Exec(`UPDATE users SET phone_number = $1 WHERE id = $2`, phoneNumbers[i], id); err != nil {
+			return 0, errors.WrapError(err, "Failed to re-encrypt phone number")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, errors.WrapError(err, "Failed to commit transaction")
+	}
+
+	return len(ids), nil
+}
+
+// TakenEmails returns which of emails are used by existing accounts, compared case-insensitively
+func (r *userRepository) TakenEmails(emails []string) (map[string]bool, error) {
+	query := `SELECT e FROM unnest($1::text[]) AS e
+			  WHERE EXISTS(SELECT 1 FROM users WHERE LOWER(email) = LOWER(e))`
+
+	return r.takenIdentifiers(query, "Failed to check email existence", pq.Array(emails))
+}
+
+// TakenUsernames returns which of usernames are used by existing accounts, or could be
+// confused with one (see ExistsByConfusableUsername)
+func (r *userRepository) TakenUsernames(usernames []string) (map[string]bool, error) {
+	skeletons := make([]string, len(usernames))
+	for i, username := range usernames {
+		skeletons[i] = normalize.UsernameSkeleton(username)
+	}
+
+	query := `SELECT u.name FROM unnest($1::text[], $2::text[]) AS u(name, skeleton)
+			  WHERE EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER(u.name) OR username_skeleton = u.skeleton)`
+
+	return r.takenIdentifiers(query, "Failed to check username existence", pq.Array(usernames), pq.Array(skeletons))
+}
+
+// takenIdentifiers runs a query returning a single text column and collects the values
+func (r *userRepository) takenIdentifiers(query, message string, args ...interface{}) (map[string]bool, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.WrapError(err, message)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]bool)
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, errors.WrapError(err, message)
+		}
+		taken[value] = true
+	}
+
+	return taken, nil
+}
+
+// GetPreferences gets a user's personalization settings
+func (r *userRepository) GetPreferences(id uuid.UUID) (*models.UserPreferences, error) {
+	prefs := &models.UserPreferences{}
+	query := `SELECT preferred_languages, timezone FROM users WHERE id = $1`
+
+	err := r.db.QueryRow(query, id).Scan(pq.Array(&prefs.PreferredLanguages), &prefs.Timezone)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get user preferences")
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences replaces a user's personalization settings
+func (r *userRepository) UpdatePreferences(id uuid.UUID, prefs *models.UserPreferences) error {
+	query := `UPDATE users SET preferred_languages = $1, timezone = $2, updated_at = $3 WHERE id = $4`
+
+	result, err := r.db.Exec(query, pq.Array(prefs.PreferredLanguages), prefs.Timezone, r.clock.Now(), id)
+	if err != nil {
+		return writeError(err, "Failed to update user preferences")
+	}
+
+	return requireRowsAffected(result, "Failed to update user preferences")
+}
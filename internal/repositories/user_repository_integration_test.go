@@ -0,0 +1,201 @@
+//go:build integration
+
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/testutil"
+
+	"github.com/google/uuid"
+)
+
+// newTestUser returns a user with a unique username and email, taken from the random end of
+// its time-ordered ID. Timestamps are rounded to the microsecond precision of Postgres.
+func newTestUser(active, mustChange bool, lastLogin *time.Time) *models.User {
+	id := ids.New()
+	hex := strings.ReplaceAll(id.String(), "-", "")
+	name := "it_" + hex[len(hex)-12:]
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	return &models.User{
+		ID:                 id,
+		Username:           name,
+		Email:              name + "@example.com",
+		Password:           "$2a$10$notarealhashnotarealhashnotarealhashnotarealhashnot",
+		IsActive:           active,
+		MustChangePassword: mustChange,
+		LastLogin:          lastLogin,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
+// assertAccountFlags checks the stored is_active, must_change_password and last_login of a user
+func assertAccountFlags(t *testing.T, repo models.UserRepository, id uuid.UUID, active, mustChange bool, lastLogin *time.Time) {
+	t.Helper()
+	user, err := repo.GetByID(id)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if user.IsActive != active {
+		t.Errorf("is_active = %v, want %v", user.IsActive, active)
+	}
+	if user.MustChangePassword != mustChange {
+		t.Errorf("must_change_password = %v, want %v", user.MustChangePassword, mustChange)
+	}
+	switch {
+	case lastLogin == nil && user.LastLogin != nil:
+		t.Errorf("last_login = %v, want NULL", *user.LastLogin)
+	case lastLogin != nil && (user.LastLogin == nil || !user.LastLogin.Equal(*lastLogin)):
+		t.Errorf("last_login = %v, want %v", user.LastLogin, *lastLogin)
+	}
+
+	flagged, err := repo.MustChangePassword(id)
+	if err != nil {
+		t.Fatalf("MustChangePassword: %v", err)
+	}
+	if flagged != mustChange {
+		t.Errorf("MustChangePassword = %v, want %v", flagged, mustChange)
+	}
+	state, err := repo.GetTokenState(id)
+	if err != nil {
+		t.Fatalf("GetTokenState: %v", err)
+	}
+	if state.IsActive != active {
+		t.Errorf("token state is_active = %v, want %v", state.IsActive, active)
+	}
+	if state.Role != user.Role {
+		t.Errorf("token state role = %q, want %q", state.Role, user.Role)
+	}
+}
+
+func TestUserRepositoryCreateRoundTripsAccountFlags(t *testing.T) {
+	repo := NewUserRepository(testutil.DB(t), nil)
+	lastLogin := time.Date(2026, 3, 29, 1, 30, 0, 123456000, time.UTC)
+
+	tests := []struct {
+		name       string
+		active     bool
+		mustChange bool
+		lastLogin  *time.Time
+	}{
+		{"defaults", true, false, nil},
+		{"inactive", false, false, nil},
+		{"must change password", true, true, nil},
+		{"signed in before", true, false, &lastLogin},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user := newTestUser(tt.active, tt.mustChange, tt.lastLogin)
+			if err := repo.Create(user); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			assertAccountFlags(t, repo, user.ID, tt.active, tt.mustChange, tt.lastLogin)
+
+			byEmail, err := repo.GetByEmail(strings.ToUpper(user.Email))
+			if err != nil {
+				t.Fatalf("GetByEmail: %v", err)
+			}
+			if byEmail.IsActive != tt.active || byEmail.MustChangePassword != tt.mustChange {
+				t.Errorf("GetByEmail: is_active %v, must_change_password %v", byEmail.IsActive, byEmail.MustChangePassword)
+			}
+		})
+	}
+}
+
+func TestUserRepositoryCreateBatchRoundTripsAccountFlags(t *testing.T) {
+	repo := NewUserRepository(testutil.DB(t), nil)
+	lastLogin := time.Date(2026, 11, 1, 1, 30, 0, 0, time.UTC)
+	users := []*models.User{
+		newTestUser(true, true, nil),
+		newTestUser(false, false, &lastLogin),
+	}
+
+	if err := repo.CreateBatch(users); err != nil {
+		t.Fatalf("CreateBatch: %v", err)
+	}
+	assertAccountFlags(t, repo, users[0].ID, true, true, nil)
+	assertAccountFlags(t, repo, users[1].ID, false, false, &lastLogin)
+}
+
+func TestUserRepositoryAccountFlagUpdates(t *testing.T) {
+	clk := clock.NewManual(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	repo := NewUserRepository(testutil.DB(t), clk)
+	user := newTestUser(true, false, nil)
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := repo.Deactivate(user.ID); err != nil {
+		t.Fatalf("Deactivate: %v", err)
+	}
+	assertAccountFlags(t, repo, user.ID, false, false, nil)
+	// Stale account purges count from the deactivation
+	deactivated, err := repo.GetByID(user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if deactivated.DeactivatedAt == nil || !deactivated.DeactivatedAt.Equal(clk.Now()) {
+		t.Errorf("deactivated at %v, want now", deactivated.DeactivatedAt)
+	}
+	if err := repo.Activate(user.ID); err != nil {
+		t.Fatalf("Activate: %v", err)
+	}
+	assertAccountFlags(t, repo, user.ID, true, false, nil)
+	if activated, err := repo.GetByID(user.ID); err != nil {
+		t.Fatalf("GetByID: %v", err)
+	} else if activated.DeactivatedAt != nil {
+		t.Errorf("deactivated at %v after Activate, want none", activated.DeactivatedAt)
+	}
+
+	if err := repo.UpdateLastLogin(user.ID); err != nil {
+		t.Fatalf("UpdateLastLogin: %v", err)
+	}
+	signedIn := clk.Now()
+	assertAccountFlags(t, repo, user.ID, true, false, &signedIn)
+
+	if err := repo.SetMustChangePassword(user.ID, true); err != nil {
+		t.Fatalf("SetMustChangePassword: %v", err)
+	}
+	assertAccountFlags(t, repo, user.ID, true, true, &signedIn)
+	// Setting a new password clears the forced change
+	if err := repo.UpdatePassword(user.ID, user.Password); err != nil {
+		t.Fatalf("UpdatePassword: %v", err)
+	}
+	assertAccountFlags(t, repo, user.ID, true, false, &signedIn)
+
+	// Updating the profile keeps last_login and is_active as given
+	stored, err := repo.GetByID(user.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	stored.IsActive = false
+	stored.UpdatedAt = clk.Now()
+	if err := repo.Update(stored); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	assertAccountFlags(t, repo, user.ID, false, false, &signedIn)
+}
+
+func TestUserRepositoryAccountFlagsOfUnknownUsers(t *testing.T) {
+	repo := NewUserRepository(testutil.DB(t), nil)
+	id := uuid.New()
+
+	for name, update := range map[string]func() error{
+		"Activate":              func() error { return repo.Activate(id) },
+		"Deactivate":            func() error { return repo.Deactivate(id) },
+		"SetMustChangePassword": func() error { return repo.SetMustChangePassword(id, true) },
+	} {
+		if err := update(); err != models.ErrNotFound {
+			t.Errorf("%s: got %v, want ErrNotFound", name, err)
+		}
+	}
+	if _, err := repo.MustChangePassword(id); err != models.ErrNotFound {
+		t.Errorf("MustChangePassword: got %v, want ErrNotFound", err)
+	}
+}
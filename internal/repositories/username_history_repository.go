@@ -0,0 +1,123 @@
+package repositories
+
+import (
+	"database/sql"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// usernameHistoryRepository implements UsernameHistoryRepository interface
+type usernameHistoryRepository struct {
+	db *sql.DB
+}
+
+// NewUsernameHistoryRepository creates a new username history repository
+func NewUsernameHistoryRepository(db *sql.DB) models.UsernameHistoryRepository {
+	return &usernameHistoryRepository{db: db}
+}
+
+// Create records a previous username
+func (r *usernameHistoryRepository) Create(entry *models.UsernameHistory) error {
+	query := `INSERT INTO username_history (user_id, old_username, changed_at, reserved_until)
+			  VALUES ($1, $2, $3, $4) RETURNING id`
+
+	err := r.db.QueryRow(query, entry.UserID, entry.OldUsername, entry.ChangedAt, entry.ReservedUntil).Scan(&entry.ID)
+	if err != nil {
+		return writeError(err, "Failed to create username history")
+	}
+
+	return nil
+}
+
+// GetLatestByOldUsername gets the most recent rename away from the given username
+func (r *usernameHistoryRepository) GetLatestByOldUsername(username string) (*models.UsernameHistory, error) {
+	entry := &models.UsernameHistory{}
+	query := `SELECT id, user_id, old_username, changed_at, reserved_until
+			  FROM username_history WHERE LOWER(old_username) = LOWER($1)
+			  ORDER BY changed_at DESC LIMIT 1`
+
+	err := r.db.QueryRow(query, username).Scan(
+		&entry.ID, &entry.UserID, &entry.OldUsername, &entry.ChangedAt, &entry.ReservedUntil,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, models.ErrNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get username history")
+	}
+
+	return entry, nil
+}
+
+// IsReserved checks if a username is still within its cooldown period for another user
+func (r *usernameHistoryRepository) IsReserved(username string, excludeUserID uuid.UUID) (bool, error) {
+	var reserved bool
+	query := `SELECT EXISTS(
+		SELECT 1 FROM username_history
+		WHERE LOWER(old_username) = LOWER($1)
+		AND user_id != $2
+		AND reserved_until > NOW()
+	)`
+
+	err := r.db.QueryRow(query, username, excludeUserID).Scan(&reserved)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to check username reservation")
+	}
+
+	return reserved, nil
+}
+
+// ReservedAmong returns which of usernames are currently reserved for a previous owner
+func (r *usernameHistoryRepository) ReservedAmong(usernames []string) (map[string]bool, error) {
+	query := `SELECT u FROM unnest($1::text[]) AS u
+			  WHERE EXISTS(SELECT 1 FROM username_history WHERE LOWER(old_username) = LOWER(u) AND reserved_until > NOW())`
+
+	rows, err := r.db.Query(query, pq.Array(usernames))
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check username reservation")
+	}
+	defer rows.Close()
+
+	reserved := make(map[string]bool)
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, errors.WrapError(err, "Failed to check username reservation")
+		}
+		reserved[username] = true
+	}
+
+	return reserved, nil
+}
+
+// GetByUserID gets the username history of a user, most recent first
+func (r *usernameHistoryRepository) GetByUserID(userID uuid.UUID) ([]*models.UsernameHistory, error) {
+	query := `SELECT id, user_id, old_username, changed_at, reserved_until
+			  FROM username_history WHERE user_id = $1
+			  ORDER BY changed_at DESC`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get username history")
+	}
+	defer rows.Close()
+
+	var entries []*models.UsernameHistory
+	for rows.Next() {
+		entry := &models.UsernameHistory{}
+		err := rows.Scan(
+			&entry.ID, &entry.UserID, &entry.OldUsername, &entry.ChangedAt, &entry.ReservedUntil,
+		)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to scan username history")
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
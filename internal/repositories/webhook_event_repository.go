@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// webhookEventRepository implements WebhookEventRepository interface
+type webhookEventRepository struct {
+	db *sql.DB
+}
+
+// NewWebhookEventRepository creates a new webhook event repository
+func NewWebhookEventRepository(db *sql.DB) models.WebhookEventRepository {
+	return &webhookEventRepository{db: db}
+}
+
+// Claim records that a webhook event is being processed, unless it was processed or another
+// delivery claimed it since expiredBefore
+func (r *webhookEventRepository) Claim(provider, eventID string, now, expiredBefore time.Time) (models.WebhookClaim, error) {
+	query := `INSERT INTO webhook_events (provider, event_id, received_at, claimed_at) VALUES ($1, $2, $3, $3)
+			  ON CONFLICT (provider, event_id) DO UPDATE SET claimed_at = EXCLUDED.claimed_at
+			  WHERE webhook_events.processed_at IS NULL AND webhook_events.claimed_at < $4
+			  RETURNING true`
+
+	var claimed bool
+	err := r.db.QueryRow(query, provider, eventID, now, expiredBefore).Scan(&claimed)
+	if err == nil {
+		return models.WebhookClaimed, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, writeError(err, "Failed to claim webhook event")
+	}
+
+	// Claimed by another delivery: tell whether it is done. A claim released in between is
+	// reported in flight, so the provider retries.
+	var processed bool
+	err = r.db.QueryRow(`SELECT processed_at IS NOT NULL FROM webhook_events WHERE provider = $1 AND event_id = $2`,
+		provider, eventID).Scan(&processed)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, errors.WrapError(err, "Failed to get webhook event")
+	}
+	if processed {
+		return models.WebhookProcessed, nil
+	}
+	return models.WebhookInFlight, nil
+}
+
+// Complete records that a claimed webhook event was processed
+func (r *webhookEventRepository) Complete(provider, eventID string, now time.Time) error {
+	result, err := r.db.Exec(`UPDATE webhook_events SET processed_at = $3 WHERE provider = $1 AND event_id = $2`, provider, eventID, now)
+	if err != nil {
+		return errors.WrapError(err, "Failed to complete webhook event")
+	}
+	return requireRowsAffected(result, "Failed to complete webhook event")
+}
+
+// Release forgets a claimed webhook event
+func (r *webhookEventRepository) Release(provider, eventID string) error {
+	if _, err := r.db.Exec(`DELETE FROM webhook_events WHERE provider = $1 AND event_id = $2`, provider, eventID); err != nil {
+		return errors.WrapError(err, "Failed to release webhook event")
+	}
+	return nil
+}
+
+// DeleteReceivedBefore forgets the webhook events received before cutoff
+func (r *webhookEventRepository) DeleteReceivedBefore(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec(`DELETE FROM webhook_events WHERE received_at < $1`, cutoff)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to delete webhook events")
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to delete webhook events")
+	}
+
+	return deleted, nil
+}
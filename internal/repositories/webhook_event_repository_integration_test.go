@@ -0,0 +1,63 @@
+//go:build integration
+
+package repositories
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/ids"
+	"go-backend-api/internal/pkg/testutil"
+)
+
+func TestWebhookEventClaims(t *testing.T) {
+	repo := NewWebhookEventRepository(testutil.DB(t))
+	eventID := "evt_" + ids.New().String()
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	timeout := 10 * time.Minute
+
+	claim := func(at time.Time) models.WebhookClaim {
+		t.Helper()
+		state, err := repo.Claim("test", eventID, at, at.Add(-timeout))
+		if err != nil {
+			t.Fatalf("Claim: %v", err)
+		}
+		return state
+	}
+
+	if state := claim(now); state != models.WebhookClaimed {
+		t.Fatalf("first delivery: got %v, want claimed", state)
+	}
+	if state := claim(now.Add(time.Minute)); state != models.WebhookInFlight {
+		t.Errorf("redelivery during processing: got %v, want in flight", state)
+	}
+	// A claim left behind by a crashed instance expires
+	if state := claim(now.Add(timeout + time.Minute)); state != models.WebhookClaimed {
+		t.Errorf("redelivery after the claim expired: got %v, want claimed", state)
+	}
+
+	if err := repo.Complete("test", eventID, now.Add(timeout+2*time.Minute)); err != nil {
+		t.Fatalf("Complete: %v", err)
+	}
+	for _, at := range []time.Time{now.Add(timeout + 3*time.Minute), now.Add(24 * time.Hour)} {
+		if state := claim(at); state != models.WebhookProcessed {
+			t.Errorf("redelivery at %v once processed: got %v, want processed", at, state)
+		}
+	}
+
+	// A released event is processed again
+	failed := "evt_" + ids.New().String()
+	if state, err := repo.Claim("test", failed, now, now.Add(-timeout)); err != nil || state != models.WebhookClaimed {
+		t.Fatalf("Claim: %v, %v", state, err)
+	}
+	if err := repo.Release("test", failed); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if state, err := repo.Claim("test", failed, now.Add(time.Minute), now.Add(time.Minute-timeout)); err != nil || state != models.WebhookClaimed {
+		t.Errorf("retry after a failure: got %v, %v; want claimed", state, err)
+	}
+	if err := repo.Complete("test", "evt_unknown", now); err != models.ErrNotFound {
+		t.Errorf("Complete of an unclaimed event: got %v, want ErrNotFound", err)
+	}
+}
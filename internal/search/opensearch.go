@@ -0,0 +1,233 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// openSearchTimeout bounds a request to the backend, so searches fall back quickly
+const openSearchTimeout = 5 * time.Second
+
+// openSearchMapping is the mapping the posts index is created with
+const openSearchMapping = `{
+  "mappings": {
+    "properties": {
+      "author_id": {"type": "keyword"},
+      "visibility": {"type": "keyword"},
+      "title": {"type": "text"},
+      "content": {"type": "text"},
+      "published_at": {"type": "date"}
+    }
+  }
+}`
+
+// openSearchIndex indexes posts in an OpenSearch or Elasticsearch index over its REST API
+type openSearchIndex struct {
+	url      string
+	index    string
+	username string
+	password string
+	client   *http.Client
+
+	// created tells whether the index is known to exist; until it is, every request first
+	// tries to create it, since documents put before would get a guessed mapping
+	mu      sync.Mutex
+	created bool
+}
+
+func newOpenSearchIndex(cfg Config) *openSearchIndex {
+	return &openSearchIndex{
+		url:      strings.TrimRight(cfg.URL, "/"),
+		index:    cfg.Index,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: openSearchTimeout},
+	}
+}
+
+// Name returns the URL of the index
+func (s *openSearchIndex) Name() string {
+	return s.url + "/" + s.index
+}
+
+// ensureIndex creates the index with its mapping, unless it exists
+func (s *openSearchIndex) ensureIndex() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.created {
+		return nil
+	}
+	if err := s.createIndex(); err != nil {
+		return err
+	}
+	s.created = true
+	return nil
+}
+
+// createIndex sends the request creating the index, accepting an index that exists
+func (s *openSearchIndex) createIndex() error {
+	resp, err := s.do(http.MethodPut, "", []byte(openSearchMapping))
+	if err != nil {
+		return fmt.Errorf("failed to create search index %s: %w", s.Name(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusBadRequest {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		if bytes.Contains(body, []byte("resource_already_exists_exception")) {
+			return nil
+		}
+		return fmt.Errorf("failed to create search index %s: %s", s.Name(), body)
+	}
+	return checkStatus(resp, "create search index "+s.Name())
+}
+
+// Put indexes a document under its post ID
+func (s *openSearchIndex) Put(doc *Document) error {
+	if err := s.ensureIndex(); err != nil {
+		return err
+	}
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to encode post %s: %w", doc.ID, err)
+	}
+
+	resp, err := s.do(http.MethodPut, "/_doc/"+doc.ID.String(), body)
+	if err != nil {
+		return fmt.Errorf("failed to index post %s: %w", doc.ID, err)
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, "index post "+doc.ID.String())
+}
+
+// Delete removes the document of a post; posts that were never indexed are fine
+func (s *openSearchIndex) Delete(id uuid.UUID) error {
+	if err := s.ensureIndex(); err != nil {
+		return err
+	}
+	resp, err := s.do(http.MethodDelete, "/_doc/"+id.String(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to remove post %s from index: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkStatus(resp, "remove post "+id.String()+" from index")
+}
+
+// Search finds the posts matching the query text exactly, in simple query string syntax
+// (quoted phrases and -excluded words), or with typos, that the viewer may list
+func (s *openSearchIndex) Search(query *Query) (*Result, error) {
+	if err := s.ensureIndex(); err != nil {
+		return nil, err
+	}
+	followees := make([]string, len(query.Followees))
+	for i, id := range query.Followees {
+		followees[i] = id.String()
+	}
+	fields := []string{"title^2", "content"}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"from":             query.Offset,
+		"size":             query.Limit,
+		"track_total_hits": true,
+		"_source":          false,
+		"sort":             []interface{}{"_score", map[string]string{"published_at": "desc"}},
+		"query": map[string]interface{}{
+			"bool": map[string]interface{}{
+				"should": []interface{}{
+					map[string]interface{}{"simple_query_string": map[string]interface{}{
+						"query": query.Text, "fields": fields, "default_operator": "and",
+					}},
+					map[string]interface{}{"multi_match": map[string]interface{}{
+						"query": query.Text, "fields": fields, "fuzziness": "AUTO", "operator": "and",
+					}},
+				},
+				"minimum_should_match": 1,
+				"filter": []interface{}{
+					map[string]interface{}{"bool": map[string]interface{}{
+						"should": []interface{}{
+							map[string]interface{}{"term": map[string]string{"visibility": models.PostVisibilityPublic}},
+							map[string]interface{}{"term": map[string]string{"author_id": query.ViewerID.String()}},
+							map[string]interface{}{"bool": map[string]interface{}{"filter": []interface{}{
+								map[string]interface{}{"term": map[string]string{"visibility": models.PostVisibilityFollowers}},
+								map[string]interface{}{"terms": map[string]interface{}{"author_id": followees}},
+							}}},
+						},
+						"minimum_should_match": 1,
+					}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode search: %w", err)
+	}
+
+	resp, err := s.do(http.MethodPost, "/_search", body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search %s: %w", s.Name(), err)
+	}
+	defer resp.Body.Close()
+	if err := checkStatus(resp, "search "+s.Name()); err != nil {
+		return nil, err
+	}
+
+	var found struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, fmt.Errorf("failed to decode search results: %w", err)
+	}
+
+	result := &Result{Total: found.Hits.Total.Value, IDs: make([]uuid.UUID, 0, len(found.Hits.Hits))}
+	for _, hit := range found.Hits.Hits {
+		if id, err := uuid.Parse(hit.ID); err == nil {
+			result.IDs = append(result.IDs, id)
+		}
+	}
+	return result, nil
+}
+
+// do sends a request for a path below the index
+func (s *openSearchIndex) do(method, path string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequest(method, s.url+"/"+url.PathEscape(s.index)+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.username != "" {
+		req.SetBasicAuth(s.username, s.password)
+	}
+	return s.client.Do(req)
+}
+
+// checkStatus turns a non-2xx response into an error carrying the start of its body
+func checkStatus(resp *http.Response, action string) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return fmt.Errorf("failed to %s: status %d: %s", action, resp.StatusCode, bytes.TrimSpace(body))
+}
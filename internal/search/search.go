@@ -0,0 +1,155 @@
+package search
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Search backends
+const (
+	BackendOpenSearch = "opensearch"
+	// BackendElasticsearch speaks the same document and search API as OpenSearch
+	BackendElasticsearch = "elasticsearch"
+)
+
+// DefaultQueueSize is how many index updates wait for the backend before new ones are dropped
+const DefaultQueueSize = 1000
+
+// Document is a post as it is indexed
+type Document struct {
+	ID          uuid.UUID  `json:"-"`
+	AuthorID    *uuid.UUID `json:"author_id"`
+	Visibility  string     `json:"visibility"`
+	Title       string     `json:"title"`
+	Content     string     `json:"content"`
+	PublishedAt *time.Time `json:"published_at"`
+}
+
+// Query is a search of the posts a viewer may list: their own, public ones and the
+// followers-only posts of the authors they follow
+type Query struct {
+	Text      string
+	ViewerID  uuid.UUID
+	Followees []uuid.UUID
+	Limit     int
+	Offset    int
+}
+
+// Result is a page of the IDs of matching posts, best matches first
+type Result struct {
+	IDs   []uuid.UUID
+	Total int
+}
+
+// Index stores post documents and searches them
+type Index interface {
+	// Put indexes a document, replacing the one with its ID
+	Put(doc *Document) error
+	// Delete removes the document with an ID, if indexed
+	Delete(id uuid.UUID) error
+	Search(query *Query) (*Result, error)
+	// Name describes the index, for logs
+	Name() string
+}
+
+// Config selects and configures a search backend
+type Config struct {
+	Backend  string // "opensearch" or "elasticsearch"
+	URL      string
+	Index    string
+	Username string
+	Password string
+}
+
+// New creates the configured search backend, creating its index when it does not exist. A
+// backend that cannot be reached is still returned, with the error, so that searches fall
+// back until it is.
+func New(cfg Config) (Index, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case BackendOpenSearch, BackendElasticsearch:
+		if cfg.URL == "" || cfg.Index == "" {
+			return nil, fmt.Errorf("%s search requires a URL and an index", cfg.Backend)
+		}
+		index := newOpenSearchIndex(cfg)
+		return index, index.ensureIndex()
+	default:
+		return nil, fmt.Errorf("unknown search backend %q", cfg.Backend)
+	}
+}
+
+// update is a queued change of the index: a document to put, or an ID to delete
+type update struct {
+	doc *Document
+	id  uuid.UUID
+}
+
+// Indexer keeps an index up to date in the background, so writes never wait on the search
+// backend or fail with it. Updates made while the queue is full are dropped and logged; a
+// reindex repairs them.
+type Indexer struct {
+	index Index
+	queue chan update
+}
+
+// NewIndexer creates an indexer for index, queueing up to queueSize updates
+func NewIndexer(index Index, queueSize int) *Indexer {
+	if queueSize <= 0 {
+		queueSize = DefaultQueueSize
+	}
+	return &Indexer{index: index, queue: make(chan update, queueSize)}
+}
+
+// Put queues a document to be indexed
+func (i *Indexer) Put(doc *Document) {
+	i.enqueue(update{doc: doc, id: doc.ID})
+}
+
+// Delete queues the removal of the document with an ID
+func (i *Indexer) Delete(id uuid.UUID) {
+	i.enqueue(update{id: id})
+}
+
+// enqueue queues an update without blocking
+func (i *Indexer) enqueue(u update) {
+	select {
+	case i.queue <- u:
+	default:
+		log.Printf("Search index queue is full, dropping update of post %s", u.id)
+	}
+}
+
+// PutNow indexes a document right away, for reindexing
+func (i *Indexer) PutNow(doc *Document) error {
+	return i.index.Put(doc)
+}
+
+// Search searches the index
+func (i *Indexer) Search(query *Query) (*Result, error) {
+	return i.index.Search(query)
+}
+
+// Start applies queued updates in the background until stop is closed
+func (i *Indexer) Start(stop <-chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case u := <-i.queue:
+				var err error
+				if u.doc != nil {
+					err = i.index.Put(u.doc)
+				} else {
+					err = i.index.Delete(u.id)
+				}
+				if err != nil {
+					log.Printf("Failed to update post %s in search index %s: %v", u.id, i.index.Name(), err)
+				}
+			}
+		}
+	}()
+}
@@ -0,0 +1,79 @@
+package seed
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// devPassword is the password of the dev profile's demo users
+const devPassword = "Sample-Dev-Pass9"
+
+// devUsers are the demo users of the dev profile
+var devUsers = []struct{ username, role string }{
+	{"alice", models.RoleAdmin},
+	{"bob", models.RoleUser},
+	{"carol", models.RoleUser},
+	{"dave", models.RoleUser},
+}
+
+// devPosts are the demo posts of the dev profile, by index into devUsers
+var devPosts = []struct {
+	author    int
+	title     string
+	content   string
+	published bool
+}{
+	{0, "Welcome to the demo", "This instance was seeded with demo data.\n\nSign in as any of **alice**, **bob**, **carol** or **dave** to try the API; alice is an admin.", true},
+	{0, "Moderation notes", "A draft only alice can see, for trying out drafts and archiving.", false},
+	{1, "Getting started with Go", "Go is a statically typed, compiled language designed at Google.\n\n## Why Go\n\nIt is simple, fast to build and has great concurrency support.", true},
+	{1, "Error handling patterns", "Errors are values in Go. Wrap them with context as they travel up the stack, and compare them with `errors.Is`.", true},
+	{2, "Designing REST APIs", "Resources are nouns, HTTP methods are verbs.\n\n- Use plural collection names\n- Paginate every list\n- Return consistent error envelopes", true},
+	{2, "PostgreSQL indexing tips", "Index the columns you filter and sort by, and check your plans with `EXPLAIN ANALYZE`.", true},
+	{3, "Half-written thoughts", "Drafts are only visible to their author until they are published.", false},
+}
+
+// devData builds the dev profile: a handful of demo users who follow and react to each other
+func devData(now time.Time) *dataset {
+	data := &dataset{}
+	for i, u := range devUsers {
+		data.users = append(data.users, user{
+			id:        id("user", u.username),
+			username:  u.username,
+			email:     u.username + "@demo.example.com",
+			role:      u.role,
+			createdAt: now.AddDate(0, 0, -30+i),
+		})
+	}
+
+	for i, p := range devPosts {
+		author := data.users[p.author]
+		data.posts = append(data.posts, post{
+			id:        id("post", author.username+":"+p.title),
+			authorID:  author.id,
+			title:     p.title,
+			content:   p.content,
+			published: p.published,
+			views:     int64(10 * (len(devPosts) - i)),
+			createdAt: now.AddDate(0, 0, -20+2*i),
+		})
+	}
+
+	// Everyone follows alice and bob, and likes the published posts of others
+	for _, follower := range data.users {
+		for _, followee := range data.users[:2] {
+			if follower.id != followee.id {
+				data.follows = append(data.follows, [2]uuid.UUID{follower.id, followee.id})
+			}
+		}
+		for j, p := range data.posts {
+			if devPosts[j].published && p.authorID != follower.id {
+				data.reactions = append(data.reactions, reaction{userID: follower.id, postID: p.id, reaction: "like"})
+			}
+		}
+	}
+
+	return data
+}
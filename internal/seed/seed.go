@@ -0,0 +1,292 @@
+package seed
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"go-backend-api/internal/pkg/normalize"
+	"go-backend-api/internal/pkg/security"
+	"go-backend-api/internal/pkg/text"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// Seed profiles
+const (
+	ProfileDev     = "dev"     // A few demo users and posts with a known password
+	ProfileStaging = "staging" // Generated, anonymized data at production-like volume
+)
+
+// Default staging volume
+const (
+	DefaultStagingUsers = 1000
+	DefaultStagingPosts = 10000
+)
+
+// batchSize is how many rows each insert statement writes
+const batchSize = 1000
+
+// namespace derives the IDs of seeded rows, so that every run of a profile writes the same rows
+var namespace = uuid.MustParse("6f1d8c62-4b7e-4f57-9a51-0b8a3f2c9d14")
+
+// Options configures a seeding run
+type Options struct {
+	Profile string
+	// Users and Posts set the staging volume; dev always seeds the same demo data
+	Users int
+	Posts int
+	// Password is the password of every seeded user; required for staging
+	Password string
+	// ExcerptWords and WordsPerMinute summarize posts like the API does
+	ExcerptWords   int
+	WordsPerMinute int
+}
+
+// Result counts the rows a seeding run wrote
+type Result struct {
+	Users     int
+	Posts     int
+	Follows   int
+	Reactions int
+}
+
+// ProfileFor returns the profile matching an environment, or "" when none does
+func ProfileFor(environment string) string {
+	switch environment {
+	case "development":
+		return ProfileDev
+	case "staging":
+		return ProfileStaging
+	default:
+		return ""
+	}
+}
+
+// dataset is the data of a profile
+type dataset struct {
+	users     []user
+	posts     []post
+	follows   [][2]uuid.UUID // Follower, followee
+	reactions []reaction
+}
+
+type user struct {
+	id        uuid.UUID
+	username  string
+	email     string
+	role      string
+	createdAt time.Time
+}
+
+type post struct {
+	id        uuid.UUID
+	authorID  uuid.UUID
+	title     string
+	content   string
+	published bool
+	views     int64
+	createdAt time.Time
+}
+
+type reaction struct {
+	userID   uuid.UUID
+	postID   uuid.UUID
+	reaction string
+}
+
+// Run seeds the data of a profile in one transaction. Seeded rows have fixed IDs and are
+// upserted, so running a profile again restores its rows instead of duplicating them.
+func Run(db *sql.DB, opts Options) (*Result, error) {
+	var data *dataset
+	switch opts.Profile {
+	case ProfileDev:
+		data = devData(time.Now())
+	case ProfileStaging:
+		if opts.Password == "" {
+			return nil, fmt.Errorf("the %s profile needs a password", ProfileStaging)
+		}
+		users, posts := opts.Users, opts.Posts
+		if users <= 0 {
+			users = DefaultStagingUsers
+		}
+		if posts <= 0 {
+			posts = DefaultStagingPosts
+		}
+		data = stagingData(users, posts, time.Now())
+	default:
+		return nil, fmt.Errorf("unknown seed profile %q; profiles are %s and %s", opts.Profile, ProfileDev, ProfileStaging)
+	}
+
+	password := opts.Password
+	if password == "" {
+		password = devPassword
+	}
+	if err := security.DefaultPasswordPolicy().ValidatePassword(password); err != nil {
+		return nil, fmt.Errorf("seed password: %w", err)
+	}
+	// Every seeded user shares the password, so it is hashed once
+	hashedPassword, err := security.HashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &Result{}
+	if result.Users, err = upsertUsers(tx, data.users, hashedPassword); err != nil {
+		return nil, err
+	}
+	if result.Posts, err = upsertPosts(tx, data.posts, opts); err != nil {
+		return nil, err
+	}
+	if result.Follows, err = insertFollows(tx, data.follows); err != nil {
+		return nil, err
+	}
+	if result.Reactions, err = insertReactions(tx, data.reactions); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit seed data: %w", err)
+	}
+	return result, nil
+}
+
+// id derives the fixed ID of a seeded row
+func id(kind string, key string) uuid.UUID {
+	return uuid.NewSHA1(namespace, []byte(kind+":"+key))
+}
+
+// upsertUsers writes users, resetting seeded accounts to their seeded state and password
+func upsertUsers(tx *sql.Tx, users []user, hashedPassword string) (int, error) {
+	query := `INSERT INTO users (id, username, username_skeleton, email, password, role, is_active, created_at, updated_at)
+			  SELECT id, username, skeleton, email, $5, role, true, created_at, created_at
+			  FROM unnest($1::uuid[], $2::text[], $3::text[], $4::text[], $6::text[], $7::timestamp[])
+			       AS u(id, username, skeleton, email, role, created_at)
+			  ON CONFLICT (id) DO UPDATE SET
+			      username = EXCLUDED.username, username_skeleton = EXCLUDED.username_skeleton, email = EXCLUDED.email,
+			      password = EXCLUDED.password, role = EXCLUDED.role, is_active = true, must_change_password = false,
+			      failed_login_attempts = 0, locked_until = NULL, updated_at = CURRENT_TIMESTAMP`
+
+	return inBatches(len(users), func(start, end int) (sql.Result, error) {
+		batch := users[start:end]
+		ids := make([]string, len(batch))
+		usernames := make([]string, len(batch))
+		skeletons := make([]string, len(batch))
+		emails := make([]string, len(batch))
+		roles := make([]string, len(batch))
+		createdAt := make([]time.Time, len(batch))
+		for i, u := range batch {
+			ids[i] = u.id.String()
+			usernames[i] = u.username
+			skeletons[i] = normalize.UsernameSkeleton(u.username)
+			emails[i] = u.email
+			roles[i] = u.role
+			createdAt[i] = u.createdAt
+		}
+		return tx.Exec(query, pq.Array(ids), pq.Array(usernames), pq.Array(skeletons), pq.Array(emails),
+			hashedPassword, pq.Array(roles), pq.Array(createdAt))
+	}, "users")
+}
+
+// upsertPosts writes posts, summarized like the API does, resetting seeded posts to their seeded content
+func upsertPosts(tx *sql.Tx, posts []post, opts Options) (int, error) {
+	query := `INSERT INTO posts (id, author_id, title, content, excerpt, reading_time_minutes, language, is_published, published_at, view_count, created_at, updated_at)
+			  SELECT id, author_id, title, content, excerpt, reading_time, language, is_published, CASE WHEN is_published THEN created_at END, views, created_at, created_at
+			  FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[], $5::text[], $6::int[], $7::text[], $8::boolean[], $9::bigint[], $10::timestamp[])
+			       AS p(id, author_id, title, content, excerpt, reading_time, language, is_published, views, created_at)
+			  ON CONFLICT (id) DO UPDATE SET
+			      author_id = EXCLUDED.author_id, title = EXCLUDED.title, content = EXCLUDED.content,
+			      excerpt = EXCLUDED.excerpt, reading_time_minutes = EXCLUDED.reading_time_minutes,
+			      content_format = 'markdown', content_sanitized = false, language = EXCLUDED.language,
+			      is_published = EXCLUDED.is_published, published_at = EXCLUDED.published_at, view_count = EXCLUDED.view_count, archived_at = NULL,
+			      updated_at = CURRENT_TIMESTAMP`
+
+	return inBatches(len(posts), func(start, end int) (sql.Result, error) {
+		batch := posts[start:end]
+		ids := make([]string, len(batch))
+		authors := make([]string, len(batch))
+		titles := make([]string, len(batch))
+		contents := make([]string, len(batch))
+		excerpts := make([]string, len(batch))
+		readingTimes := make([]int64, len(batch))
+		languages := make([]string, len(batch))
+		published := make([]bool, len(batch))
+		views := make([]int64, len(batch))
+		createdAt := make([]time.Time, len(batch))
+		for i, p := range batch {
+			ids[i] = p.id.String()
+			authors[i] = p.authorID.String()
+			titles[i] = p.title
+			contents[i] = p.content
+			excerpts[i] = text.Excerpt(p.content, opts.ExcerptWords)
+			readingTimes[i] = int64(text.ReadingTime(p.content, opts.WordsPerMinute))
+			languages[i] = text.DetectLanguage(p.title + "\n" + p.content)
+			published[i] = p.published
+			views[i] = p.views
+			createdAt[i] = p.createdAt
+		}
+		return tx.Exec(query, pq.Array(ids), pq.Array(authors), pq.Array(titles), pq.Array(contents), pq.Array(excerpts),
+			pq.Array(readingTimes), pq.Array(languages), pq.Array(published), pq.Array(views), pq.Array(createdAt))
+	}, "posts")
+}
+
+// insertFollows writes the follows that do not exist yet
+func insertFollows(tx *sql.Tx, follows [][2]uuid.UUID) (int, error) {
+	query := `INSERT INTO follows (follower_id, followee_id)
+			  SELECT * FROM unnest($1::uuid[], $2::uuid[])
+			  ON CONFLICT DO NOTHING`
+
+	return inBatches(len(follows), func(start, end int) (sql.Result, error) {
+		followers := make([]string, end-start)
+		followees := make([]string, end-start)
+		for i, follow := range follows[start:end] {
+			followers[i] = follow[0].String()
+			followees[i] = follow[1].String()
+		}
+		return tx.Exec(query, pq.Array(followers), pq.Array(followees))
+	}, "follows")
+}
+
+// insertReactions writes the post reactions that do not exist yet; a user's existing
+// reaction to a post is left as it is
+func insertReactions(tx *sql.Tx, reactions []reaction) (int, error) {
+	query := `INSERT INTO reactions (user_id, post_id, reaction)
+			  SELECT user_id, post_id, reaction::reaction_type FROM unnest($1::uuid[], $2::uuid[], $3::text[]) AS r(user_id, post_id, reaction)
+			  ON CONFLICT (user_id, post_id) WHERE post_id IS NOT NULL DO NOTHING`
+
+	return inBatches(len(reactions), func(start, end int) (sql.Result, error) {
+		users := make([]string, end-start)
+		posts := make([]string, end-start)
+		kinds := make([]string, end-start)
+		for i, r := range reactions[start:end] {
+			users[i] = r.userID.String()
+			posts[i] = r.postID.String()
+			kinds[i] = r.reaction
+		}
+		return tx.Exec(query, pq.Array(users), pq.Array(posts), pq.Array(kinds))
+	}, "reactions")
+}
+
+// inBatches runs write over batches of n rows, returning how many rows were written
+func inBatches(n int, write func(start, end int) (sql.Result, error), table string) (int, error) {
+	written := 0
+	for start := 0; start < n; start += batchSize {
+		result, err := write(start, min(start+batchSize, n))
+		if err != nil {
+			return written, fmt.Errorf("failed to seed %s: %w", table, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return written, fmt.Errorf("failed to seed %s: %w", table, err)
+		}
+		written += int(affected)
+	}
+	return written, nil
+}
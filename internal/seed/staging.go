@@ -0,0 +1,130 @@
+package seed
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// stagingSeed seeds the generator, so every run of the staging profile with the same
+// volume generates the same data
+const stagingSeed = 3449
+
+// stagingAdmins is how many of the generated staging users are admins
+const stagingAdmins = 3
+
+// stagingWords make up generated titles and content
+var stagingWords = strings.Fields(`
+	the a of and to in is that for it with as on be at by this from have are not was
+	data service request response user post feed cache query index database server client
+	build deploy release test review change design system team product feature issue
+	performance latency memory error retry timeout queue worker event stream batch job
+	simple fast reliable secure small large better clear new old first last next every
+	write read update delete create find measure improve ship learn share explain compare
+	today week year morning evening project roadmap notes summary guide lesson idea`)
+
+// stagingReactions are weighted towards likes, as in production
+var stagingReactions = []string{"like", "like", "like", "like", "like", "heart", "heart", "laugh", "wow", "sad", "angry"}
+
+// stagingData generates the staging profile: users with anonymous names and addresses
+// and posts whose authors, audience, reactions and age are skewed the way real ones are
+func stagingData(users, posts int, now time.Time) *dataset {
+	rng := rand.New(rand.NewPCG(stagingSeed, uint64(users)<<32|uint64(posts)))
+	data := &dataset{}
+
+	for i := range users {
+		name := fmt.Sprintf("member%05d", i+1)
+		role := models.RoleUser
+		if i < stagingAdmins {
+			role = models.RoleAdmin
+		}
+		data.users = append(data.users, user{
+			id:        id("user", name),
+			username:  name,
+			email:     name + "@staging.example.test",
+			role:      role,
+			createdAt: now.Add(-time.Duration(rng.Int64N(int64(2 * 365 * 24 * time.Hour)))),
+		})
+	}
+
+	// A few prolific authors write most posts, and the same few are followed the most
+	popular := rand.NewZipf(rng, 1.2, 10, uint64(users-1))
+	for i := range posts {
+		author := data.users[popular.Uint64()]
+		data.posts = append(data.posts, post{
+			id:        id("post", fmt.Sprintf("staging:%06d", i+1)),
+			authorID:  author.id,
+			title:     sentence(rng, 3+rng.IntN(6), false),
+			content:   paragraphs(rng, 1+rng.IntN(8)),
+			published: rng.IntN(10) < 8,
+			views:     int64(rng.ExpFloat64() * 200),
+			createdAt: now.Add(-time.Duration(rng.Int64N(int64(365 * 24 * time.Hour)))),
+		})
+	}
+
+	follows := make(map[[2]uuid.UUID]bool)
+	for _, follower := range data.users {
+		for range rng.IntN(20) {
+			followee := data.users[popular.Uint64()]
+			follow := [2]uuid.UUID{follower.id, followee.id}
+			if followee.id != follower.id && !follows[follow] {
+				follows[follow] = true
+				data.follows = append(data.follows, follow)
+			}
+		}
+	}
+
+	for _, p := range data.posts {
+		if !p.published {
+			continue
+		}
+		reacted := make(map[uuid.UUID]bool)
+		for range int(rng.ExpFloat64() * 4) {
+			reactor := data.users[rng.IntN(users)]
+			if reactor.id != p.authorID && !reacted[reactor.id] {
+				reacted[reactor.id] = true
+				data.reactions = append(data.reactions, reaction{
+					userID:   reactor.id,
+					postID:   p.id,
+					reaction: stagingReactions[rng.IntN(len(stagingReactions))],
+				})
+			}
+		}
+	}
+
+	return data
+}
+
+// sentence generates a capitalized sentence of random words, ending in a period if asked
+func sentence(rng *rand.Rand, words int, period bool) string {
+	picked := make([]string, words)
+	for i := range picked {
+		picked[i] = stagingWords[rng.IntN(len(stagingWords))]
+	}
+	s := strings.ToUpper(picked[0][:1]) + picked[0][1:] + " " + strings.Join(picked[1:], " ")
+	if period {
+		s = strings.TrimSpace(s) + "."
+	}
+	return strings.TrimSpace(s)
+}
+
+// paragraphs generates markdown content of random paragraphs, with the odd heading
+func paragraphs(rng *rand.Rand, n int) string {
+	blocks := make([]string, 0, n)
+	for i := range n {
+		if i > 0 && rng.IntN(4) == 0 {
+			blocks = append(blocks, "## "+sentence(rng, 2+rng.IntN(4), false))
+		}
+		sentences := make([]string, 2+rng.IntN(6))
+		for j := range sentences {
+			sentences[j] = sentence(rng, 6+rng.IntN(14), true)
+		}
+		blocks = append(blocks, strings.Join(sentences, " "))
+	}
+	return strings.Join(blocks, "\n\n")
+}
@@ -0,0 +1,244 @@
+// Package selftest checks that the server is fit to start: its configuration, the strength of
+// its secrets, the database connection and the schema the migrations create. It backs
+// `main --check`, for container healthchecks and CI smoke tests.
+package selftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/config"
+	"go-backend-api/internal/database"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/fieldcrypt"
+	"go-backend-api/internal/pkg/security"
+)
+
+// Check statuses, from best to worst
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn"
+	StatusFail = "fail"
+)
+
+// minSecretLength is the shortest secret accepted, 256 bits of ASCII
+const minSecretLength = 32
+
+// Check is the outcome of one check with the problems it found
+type Check struct {
+	Name     string   `json:"name"`
+	Status   string   `json:"status"`
+	Problems []string `json:"problems,omitempty"`
+	Duration string   `json:"duration"`
+}
+
+// Report is the outcome of a self-test; its status is the worst of its checks
+type Report struct {
+	Status string  `json:"status"`
+	Checks []Check `json:"checks"`
+}
+
+// Passed reports whether no check failed; warnings pass
+func (r *Report) Passed() bool {
+	return r.Status != StatusFail
+}
+
+// WriteText writes the report for people, one line per check followed by its problems
+func (r *Report) WriteText(w io.Writer) {
+	for _, check := range r.Checks {
+		fmt.Fprintf(w, "%-4s  %s (%s)\n", check.Status, check.Name, check.Duration)
+		for _, problem := range check.Problems {
+			fmt.Fprintf(w, "        %s\n", problem)
+		}
+	}
+	fmt.Fprintf(w, "self-test: %s\n", r.Status)
+}
+
+// WriteJSON writes the report as JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// checker collects the failures and warnings of one check
+type checker struct {
+	failures []string
+	warnings []string
+}
+
+func (c *checker) fail(format string, args ...any) {
+	c.failures = append(c.failures, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) warn(format string, args ...any) {
+	c.warnings = append(c.warnings, fmt.Sprintf(format, args...))
+}
+
+// Run runs every check against cfg. The migrations check fails without running when the
+// database cannot be reached.
+func Run(cfg *config.Config) *Report {
+	report := &Report{Status: StatusOK}
+	report.run("config", func(c *checker) { checkConfig(cfg, c) })
+	report.run("secrets", func(c *checker) { checkSecrets(cfg, c) })
+
+	db, err := database.Open(cfg.Database.URL, database.Options{
+		MaxOpenConns:     1,
+		MaxIdleConns:     1,
+		StatementTimeout: cfg.Database.StatementTimeout,
+		QueryTimeout:     cfg.Database.QueryTimeout,
+	})
+	report.run("database", func(c *checker) {
+		if err != nil {
+			c.fail("%v", err)
+		}
+	})
+	report.run("migrations", func(c *checker) {
+		if db == nil {
+			c.fail("skipped: the database is unreachable")
+			return
+		}
+		live, err := database.LiveSchema(db)
+		if err != nil {
+			c.fail("%v", err)
+			return
+		}
+		for _, missing := range database.Drift(database.ExpectedSchema(), live) {
+			c.fail("missing %s: run internal/database/migrations_v2.sql", missing)
+		}
+	})
+	if db != nil {
+		db.Close()
+	}
+
+	if cfg.Database.ReplicaURL != "" {
+		report.run("replica", func(c *checker) {
+			replica, err := database.Open(cfg.Database.ReplicaURL, database.Options{MaxOpenConns: 1, MaxIdleConns: 1})
+			if err != nil {
+				c.fail("%v", err)
+				return
+			}
+			replica.Close()
+		})
+	}
+
+	return report
+}
+
+// run runs one check and records its outcome
+func (r *Report) run(name string, fn func(c *checker)) {
+	start := time.Now()
+	c := &checker{}
+	fn(c)
+
+	check := Check{
+		Name:     name,
+		Status:   StatusOK,
+		Problems: append(c.failures, c.warnings...),
+		Duration: time.Since(start).Round(time.Millisecond).String(),
+	}
+	switch {
+	case len(c.failures) > 0:
+		check.Status = StatusFail
+		r.Status = StatusFail
+	case len(c.warnings) > 0:
+		check.Status = StatusWarn
+		if r.Status == StatusOK {
+			r.Status = StatusWarn
+		}
+	}
+	r.Checks = append(r.Checks, check)
+}
+
+// checkConfig rejects the settings startup would refuse, or that would misbehave
+func checkConfig(cfg *config.Config, c *checker) {
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 1 || port > 65535 {
+		c.fail("PORT %q is not a port number", cfg.Server.Port)
+	}
+	switch cfg.Database.SchemaCheck {
+	case database.SchemaCheckOff, database.SchemaCheckWarn, database.SchemaCheckStrict:
+	default:
+		c.fail("DB_SCHEMA_CHECK %q is invalid: use %q, %q or %q", cfg.Database.SchemaCheck, database.SchemaCheckOff, database.SchemaCheckWarn, database.SchemaCheckStrict)
+	}
+	if cfg.Database.QueryTimeout > 0 && cfg.Database.QueryTimeout <= cfg.Database.StatementTimeout {
+		c.warn("DB_QUERY_TIMEOUT (%s) should exceed DB_STATEMENT_TIMEOUT (%s)", cfg.Database.QueryTimeout, cfg.Database.StatementTimeout)
+	}
+	if cfg.App.DeletedUserPosts != models.DeletedUserPostsDelete && cfg.App.DeletedUserPosts != models.DeletedUserPostsAnonymize {
+		c.fail("DELETED_USER_POSTS %q is invalid: use %q or %q", cfg.App.DeletedUserPosts, models.DeletedUserPostsDelete, models.DeletedUserPostsAnonymize)
+	}
+	if cfg.JWT.AccessExpiration <= 0 || cfg.JWT.RefreshExpiration <= 0 {
+		c.fail("JWT_ACCESS_EXPIRATION and JWT_REFRESH_EXPIRATION must be positive")
+	} else if cfg.JWT.AccessExpiration >= cfg.JWT.RefreshExpiration {
+		c.warn("JWT_ACCESS_EXPIRATION (%s) should be shorter than JWT_REFRESH_EXPIRATION (%s)", cfg.JWT.AccessExpiration, cfg.JWT.RefreshExpiration)
+	}
+	if cfg.Security.FieldEncryptionKeys != "" {
+		if _, err := fieldcrypt.NewKeyring(cfg.Security.FieldEncryptionKeys, cfg.Security.BlindIndexKey); err != nil {
+			c.fail("FIELD_ENCRYPTION_KEYS: %v", err)
+		}
+	}
+	if cfg.Security.CaptchaEnabled {
+		if _, err := security.NewCaptchaVerifier(cfg.Security.CaptchaProvider, cfg.Security.CaptchaSecret); err != nil {
+			c.fail("CAPTCHA_PROVIDER: %v", err)
+		}
+	}
+	if cfg.IsProduction() && cfg.App.Debug {
+		c.warn("DEBUG is on in production")
+	}
+}
+
+// checkSecrets rejects short, placeholder or reused secrets. Outside production they are
+// only warned about, so development defaults keep passing.
+func checkSecrets(cfg *config.Config, c *checker) {
+	report := c.warn
+	if cfg.IsProduction() {
+		report = c.fail
+	}
+
+	secrets := []struct{ name, value string }{
+		{"JWT_ACCESS_SECRET", cfg.JWT.AccessSecretKey},
+		{"JWT_REFRESH_SECRET", cfg.JWT.RefreshSecretKey},
+	}
+	if cfg.Security.AdminSigningSecret != "" {
+		secrets = append(secrets, struct{ name, value string }{"ADMIN_SIGNING_SECRET", cfg.Security.AdminSigningSecret})
+	} else {
+		report("ADMIN_SIGNING_SECRET is not set: destructive admin requests are accepted without a signature")
+	}
+	if cfg.Security.ShortIDSecret != "" {
+		secrets = append(secrets, struct{ name, value string }{"SHORT_ID_SECRET", cfg.Security.ShortIDSecret})
+	} else {
+		report("SHORT_ID_SECRET is not set: short links use the public development key")
+	}
+
+	for _, secret := range secrets {
+		if problem := weakness(secret.value); problem != "" {
+			report("%s %s", secret.name, problem)
+		}
+	}
+	if cfg.JWT.AccessSecretKey == cfg.JWT.RefreshSecretKey {
+		report("JWT_ACCESS_SECRET and JWT_REFRESH_SECRET are the same: leaking one leaks both")
+	}
+}
+
+// weakness describes what makes a secret weak, or returns "" for a strong one
+func weakness(secret string) string {
+	lower := strings.ToLower(secret)
+	switch {
+	case strings.Contains(lower, "change-this") || strings.HasPrefix(lower, "your-"):
+		return "is a placeholder"
+	case len(secret) < minSecretLength:
+		return fmt.Sprintf("is shorter than %d characters", minSecretLength)
+	}
+
+	distinct := make(map[rune]struct{})
+	for _, r := range secret {
+		distinct[r] = struct{}{}
+	}
+	if len(distinct) < 10 {
+		return "has fewer than 10 distinct characters"
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// announcementService implements AnnouncementService interface
+type announcementService struct {
+	announcementRepo models.AnnouncementRepository
+	userRepo         models.UserRepository
+	validator        *validation.Validator
+	clock            clock.Clock
+}
+
+// NewAnnouncementService creates a new announcement service
+func NewAnnouncementService(announcementRepo models.AnnouncementRepository, userRepo models.UserRepository, clk clock.Clock) models.AnnouncementService {
+	return &announcementService{
+		announcementRepo: announcementRepo,
+		userRepo:         userRepo,
+		validator:        validation.NewValidator(),
+		clock:            clock.OrReal(clk),
+	}
+}
+
+// ListActive lists the running announcements whose audience includes the user's current role
+// and plan and that they have not dismissed, the most severe first
+func (s *announcementService) ListActive(userID uuid.UUID) ([]*models.Announcement, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	return s.announcementRepo.ListActive(userID, user.Role, user.Plan, s.clock.Now())
+}
+
+// Dismiss stops showing a running announcement to the user. Announcements outside the user's
+// audience are not found, and pinned ones cannot be dismissed.
+func (s *announcementService) Dismiss(id, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get user")
+	}
+
+	announcement, err := s.announcementRepo.GetByID(id)
+	if err != nil {
+		return writeError(err, errors.ErrAnnouncementNotFound, "Failed to get announcement")
+	}
+	now := s.clock.Now()
+	if !announcement.IsActiveAt(now) || !announcement.IsFor(user.Role, user.Plan) {
+		return errors.ErrAnnouncementNotFound
+	}
+	if !announcement.Dismissible {
+		return errors.ErrAnnouncementPinned
+	}
+
+	return s.announcementRepo.Dismiss(id, userID, now)
+}
+
+// CreateAnnouncement creates an announcement on behalf of an admin
+func (s *announcementService) CreateAnnouncement(adminID uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	now := s.clock.Now()
+	announcement := &models.Announcement{CreatedBy: &adminID, CreatedAt: now}
+	if err := s.apply(announcement, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.announcementRepo.Create(announcement); err != nil {
+		return nil, errors.WrapError(err, "Failed to create announcement")
+	}
+
+	return announcement, nil
+}
+
+// ListAnnouncements lists all announcements, including ones that have ended or not started yet
+func (s *announcementService) ListAnnouncements(page, perPage int) ([]*models.Announcement, int, error) {
+	offset := (page - 1) * perPage
+
+	announcements, err := s.announcementRepo.List(perPage, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.announcementRepo.Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return announcements, total, nil
+}
+
+// UpdateAnnouncement replaces an announcement with the request. Users who dismissed it before
+// are not shown it again.
+func (s *announcementService) UpdateAnnouncement(id uuid.UUID, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	announcement, err := s.announcementRepo.GetByID(id)
+	if err != nil {
+		return nil, writeError(err, errors.ErrAnnouncementNotFound, "Failed to get announcement")
+	}
+
+	if err := s.apply(announcement, req, s.clock.Now()); err != nil {
+		return nil, err
+	}
+
+	if err := s.announcementRepo.Update(announcement); err != nil {
+		return nil, writeError(err, errors.ErrAnnouncementNotFound, "Failed to update announcement")
+	}
+
+	return announcement, nil
+}
+
+// DeleteAnnouncement deletes an announcement together with its dismissals
+func (s *announcementService) DeleteAnnouncement(id uuid.UUID) error {
+	if err := s.announcementRepo.Delete(id); err != nil {
+		return writeError(err, errors.ErrAnnouncementNotFound, "Failed to delete announcement")
+	}
+	return nil
+}
+
+// apply validates the request and sets the announcement's fields from it, defaulting to an
+// info announcement for everyone that users may dismiss. Without a start time, new
+// announcements start now and existing ones keep theirs.
+func (s *announcementService) apply(announcement *models.Announcement, req *models.AnnouncementRequest, now time.Time) error {
+	if err := s.validator.Validate(req); err != nil {
+		return errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	startsAt := announcement.StartsAt
+	if req.StartsAt != nil {
+		startsAt = req.StartsAt.UTC()
+	} else if startsAt.IsZero() {
+		startsAt = now
+	}
+	var endsAt *time.Time
+	if req.EndsAt != nil {
+		t := req.EndsAt.UTC()
+		if !t.After(startsAt) {
+			return errors.ErrAnnouncementWindow
+		}
+		endsAt = &t
+	}
+
+	announcement.Message = req.Message
+	announcement.Severity = req.Severity
+	if announcement.Severity == "" {
+		announcement.Severity = models.AnnouncementInfo
+	}
+	announcement.Dismissible = req.Dismissible == nil || *req.Dismissible
+	announcement.StartsAt = startsAt
+	announcement.EndsAt = endsAt
+	// Empty rather than nil, as the columns are not NULL
+	announcement.Roles = append([]string{}, req.Roles...)
+	announcement.Plans = append([]string{}, req.Plans...)
+	announcement.UpdatedAt = now
+	return nil
+}
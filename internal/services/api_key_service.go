@@ -0,0 +1,200 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyHintLength is how many trailing characters of a key are kept to tell keys apart
+const apiKeyHintLength = 4
+
+// apiKeyService implements APIKeyService interface
+type apiKeyService struct {
+	apiKeyRepo models.APIKeyRepository
+	userRepo   models.UserRepository
+	plans      models.PlanService
+	validator  *validation.Validator
+}
+
+// NewAPIKeyService creates a new API key service. Users may hold as many usable keys as their
+// plan allows, and requests made with a key carry its owner's plan.
+func NewAPIKeyService(apiKeyRepo models.APIKeyRepository, userRepo models.UserRepository, plans models.PlanService) models.APIKeyService {
+	return &apiKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		plans:      plans,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// CreateAPIKey creates an API key for a user. The key may only carry scopes the caller itself holds.
+func (s *apiKeyService) CreateAPIKey(userID uuid.UUID, grantedScopes []string, req *models.CreateAPIKeyRequest) (*models.APIKey, error) {
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !models.IsValidScope(scope) {
+			return nil, errors.NewErrorWithCode(400, "Unknown scope: "+scope)
+		}
+		if !models.HasScope(grantedScopes, scope) {
+			return nil, errors.NewErrorWithCode(403, "Cannot grant scope: "+scope)
+		}
+		if !models.HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	if err := s.checkQuota(userID); err != nil {
+		return nil, err
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate API key")
+	}
+	key := models.APIKeyPrefix + token
+
+	now := time.Now()
+	apiKey := &models.APIKey{
+		UserID:    userID,
+		Name:      req.Name,
+		Key:       key,
+		KeyHash:   auth.HashToken(key),
+		Hint:      key[len(key)-apiKeyHintLength:],
+		Scopes:    scopes,
+		CreatedAt: now,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := now.Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(apiKey); err != nil {
+		return nil, errors.WrapError(err, "Failed to create API key")
+	}
+
+	return apiKey, nil
+}
+
+// checkQuota returns ErrAPIKeyLimit if a user already holds as many usable keys as their plan allows
+func (s *apiKeyService) checkQuota(userID uuid.UUID) error {
+	limits, err := s.plans.GetUserLimits(userID)
+	if err != nil {
+		return err
+	}
+	if limits.APIKeys <= 0 {
+		return nil
+	}
+
+	keys, err := s.apiKeyRepo.ListByUser(userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to list API keys")
+	}
+	usable := 0
+	for _, key := range keys {
+		if key.IsUsable() {
+			usable++
+		}
+	}
+	if usable >= limits.APIKeys {
+		return errors.ErrAPIKeyLimit
+	}
+
+	return nil
+}
+
+// ListAPIKeys lists the API keys of a user
+func (s *apiKeyService) ListAPIKeys(userID uuid.UUID) ([]*models.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list API keys")
+	}
+
+	return keys, nil
+}
+
+// RevokeAPIKey revokes one of a user's API keys
+func (s *apiKeyService) RevokeAPIKey(id, userID uuid.UUID) error {
+	apiKey, err := s.apiKeyRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get API key")
+	}
+	if apiKey.UserID != userID {
+		return errors.ErrAPIKeyNotFound
+	}
+	if apiKey.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.apiKeyRepo.Revoke(id); err != nil {
+		return writeError(err, errors.ErrAPIKeyNotFound, "Failed to revoke API key")
+	}
+
+	return nil
+}
+
+// Authenticate resolves an API key to the claims of its owner, limited to the key's scopes
+func (s *apiKeyService) Authenticate(key string) (*models.TokenClaims, error) {
+	apiKey, err := s.apiKeyRepo.GetByKeyHash(auth.HashToken(key))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get API key")
+	}
+	if !apiKey.IsUsable() {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(apiKey.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+	if !user.IsActive {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	// Scopes the owner no longer holds (e.g. after losing the admin role) are dropped
+	roleScopes := models.ScopesForRole(user.Role)
+	var scopes []string
+	for _, scope := range apiKey.Scopes {
+		if models.HasScope(roleScopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	plan, err := s.plans.ResolvePlan(user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID, time.Now()); err != nil {
+		log.Printf("Failed to record API key use: %v", err)
+	}
+
+	return &models.TokenClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		Plan:     plan,
+		Type:     "api_key",
+		Scopes:   scopes,
+		APIKeyID: &apiKey.ID,
+	}, nil
+}
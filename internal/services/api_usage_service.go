@@ -0,0 +1,194 @@
+package services
+
+import (
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// maxUsageRows bounds the rows of an API key usage report
+const maxUsageRows = 1000
+
+// usageKey identifies the counter of a caller's requests to a route on a day; callers
+// without a user, API key or client have uuid.Nil or "" in its place
+type usageKey struct {
+	day      time.Time
+	userID   uuid.UUID
+	apiKeyID uuid.UUID
+	clientID string
+	method   string
+	route    string
+}
+
+// apiUsageService implements APIUsageService interface. Requests are counted in memory and
+// added to the daily rollups on every flush, so counting never waits on the database.
+type apiUsageService struct {
+	usageRepo  models.APIUsageRepository
+	apiKeyRepo models.APIKeyRepository
+
+	mu     sync.Mutex
+	counts map[usageKey]*models.APIUsage
+}
+
+// NewAPIUsageService creates a new API usage service
+func NewAPIUsageService(usageRepo models.APIUsageRepository, apiKeyRepo models.APIKeyRepository) models.APIUsageService {
+	return &apiUsageService{
+		usageRepo:  usageRepo,
+		apiKeyRepo: apiKeyRepo,
+		counts:     map[usageKey]*models.APIUsage{},
+	}
+}
+
+// Record counts a request towards its caller's usage of its route on its day (UTC)
+func (s *apiUsageService) Record(req *models.APIRequest) {
+	key := usageKey{
+		day:      req.At.UTC().Truncate(24 * time.Hour),
+		clientID: req.ClientID,
+		method:   req.Method,
+		route:    req.Route,
+	}
+	if req.UserID != nil {
+		key.userID = *req.UserID
+	}
+	if req.APIKeyID != nil {
+		key.apiKeyID = *req.APIKeyID
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	usage, ok := s.counts[key]
+	if !ok {
+		usage = &models.APIUsage{
+			Period:   key.day,
+			UserID:   req.UserID,
+			APIKeyID: req.APIKeyID,
+			ClientID: req.ClientID,
+			Method:   req.Method,
+			Route:    req.Route,
+		}
+		s.counts[key] = usage
+	}
+	usage.Requests++
+	if req.Status >= 400 {
+		usage.Errors++
+	}
+}
+
+// Flush adds the requests counted since the last flush to the daily rollups. When the
+// database rejects them, they are kept for the next flush.
+func (s *apiUsageService) Flush() error {
+	s.mu.Lock()
+	counts := s.counts
+	s.counts = map[usageKey]*models.APIUsage{}
+	s.mu.Unlock()
+
+	if len(counts) == 0 {
+		return nil
+	}
+
+	usage := make([]*models.APIUsage, 0, len(counts))
+	for _, u := range counts {
+		usage = append(usage, u)
+	}
+	if err := s.usageRepo.Add(usage); err != nil {
+		s.restore(counts)
+		return errors.WrapError(err, "Failed to flush API usage")
+	}
+
+	return nil
+}
+
+// restore adds counts that could not be flushed back to the ones counted since
+func (s *apiUsageService) restore(counts map[usageKey]*models.APIUsage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, u := range counts {
+		if current, ok := s.counts[key]; ok {
+			current.Requests += u.Requests
+			current.Errors += u.Errors
+			continue
+		}
+		s.counts[key] = u
+	}
+}
+
+// GetAPIKeyUsage reports the usage of one of the user's API keys per route over the last
+// periods days or months, including the current one
+func (s *apiUsageService) GetAPIKeyUsage(keyID, userID uuid.UUID, granularity string, periods int) (*models.APIUsageReport, error) {
+	apiKey, err := s.apiKeyRepo.GetByID(keyID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get API key")
+	}
+	if apiKey.UserID != userID {
+		return nil, errors.ErrAPIKeyNotFound
+	}
+
+	filter := usageFilter(granularity, periods, time.Now())
+	filter.APIKeyID = &keyID
+
+	// One key has few routes, so its whole report fits in one listing
+	usage, err := s.usageRepo.List(filter, maxUsageRows, 0)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get API usage")
+	}
+
+	report := &models.APIUsageReport{
+		Granularity: granularity,
+		From:        filter.From,
+		To:          filter.To,
+		Usage:       usage,
+	}
+	for _, u := range usage {
+		report.Requests += u.Requests
+		report.Errors += u.Errors
+	}
+
+	return report, nil
+}
+
+// ListUsage lists the usage per period, caller and route over the last periods days or months,
+// including the current one, of every caller or those set in the filter
+func (s *apiUsageService) ListUsage(filter *models.APIUsageFilter, periods, page, perPage int) ([]*models.APIUsage, int, error) {
+	window := usageFilter(filter.Granularity, periods, time.Now())
+	filter.From, filter.To = window.From, window.To
+	offset := (page - 1) * perPage
+
+	usage, err := s.usageRepo.List(filter, perPage, offset)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get API usage")
+	}
+
+	total, err := s.usageRepo.Count(filter)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count API usage")
+	}
+
+	return usage, total, nil
+}
+
+// usageFilter returns a filter over the last periods days or months of granularity until the
+// end of the current one, in UTC
+func usageFilter(granularity string, periods int, now time.Time) *models.APIUsageFilter {
+	now = now.UTC()
+	filter := &models.APIUsageFilter{Granularity: granularity}
+	if granularity == models.UsageMonthly {
+		month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		filter.To = month.AddDate(0, 1, 0)
+		filter.From = month.AddDate(0, 1-periods, 0)
+		return filter
+	}
+
+	day := now.Truncate(24 * time.Hour)
+	filter.To = day.AddDate(0, 0, 1)
+	filter.From = day.AddDate(0, 0, 1-periods)
+	return filter
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/google/uuid"
+)
+
+// loginDetails is the audit log detail payload of sign-in events
+type loginDetails struct {
+	RiskScore   int                   `json:"risk_score"`
+	RiskFactors []string              `json:"risk_factors,omitempty"`
+	Location    *security.GeoLocation `json:"location,omitempty"`
+}
+
+// loginAuditDetails builds the audit details of a sign-in from its risk assessment
+func loginAuditDetails(assessment *security.RiskAssessment) *loginDetails {
+	return &loginDetails{
+		RiskScore:   assessment.Score,
+		RiskFactors: assessment.Factors,
+		Location:    assessment.Location,
+	}
+}
+
+// auditRecorder writes audit log entries annotated with the client's location
+type auditRecorder struct {
+	repo models.AuditLogRepository
+	geo  security.GeoLocator
+}
+
+// newAuditRecorder creates an audit recorder. The geo locator may be nil.
+func newAuditRecorder(repo models.AuditLogRepository, geo security.GeoLocator) *auditRecorder {
+	return &auditRecorder{repo: repo, geo: geo}
+}
+
+// record writes an audit log entry. Failures are logged rather than returned so that
+// auditing never blocks the action being audited.
+func (a *auditRecorder) record(userID *uuid.UUID, action string, client models.ClientInfo, details interface{}) {
+	entry := &models.AuditLog{
+		UserID:    userID,
+		Action:    action,
+		CreatedAt: time.Now(),
+	}
+	if client.IPAddress != "" {
+		entry.IPAddress = &client.IPAddress
+	}
+	if client.UserAgent != "" {
+		entry.UserAgent = &client.UserAgent
+	}
+	entry.Country, entry.City = locate(a.geo, client.IPAddress)
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			log.Printf("Failed to encode audit details for %s: %v", action, err)
+		} else {
+			entry.Details = data
+		}
+	}
+
+	if err := a.repo.Create(entry); err != nil {
+		log.Printf("Failed to record audit log %s: %v", action, err)
+	}
+}
+
+// locate returns the country and city of ip, or nils when GeoIP is unavailable or the address is unknown
+func locate(geo security.GeoLocator, ip string) (country, city *string) {
+	if geo == nil || ip == "" {
+		return nil, nil
+	}
+	location := geo.Lookup(ip)
+	if location == nil {
+		return nil, nil
+	}
+	if location.Country != "" {
+		country = &location.Country
+	}
+	if location.City != "" {
+		city = &location.City
+	}
+	return country, city
+}
+
+// sessionMetadata builds the metadata stored with a refresh token issued to client
+func sessionMetadata(geo security.GeoLocator, client models.ClientInfo) models.SessionMetadata {
+	meta := models.SessionMetadata{}
+	if client.IPAddress != "" {
+		meta.IPAddress = &client.IPAddress
+	}
+	if client.UserAgent != "" {
+		meta.UserAgent = &client.UserAgent
+	}
+	if client.DeviceID != "" {
+		meta.DeviceID = &client.DeviceID
+	}
+	if client.Fingerprint != "" {
+		meta.Fingerprint = &client.Fingerprint
+	}
+	meta.Country, meta.City = locate(geo, client.IPAddress)
+	return meta
+}
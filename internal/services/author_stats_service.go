@@ -0,0 +1,65 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// authorStatsService implements AuthorStatsService interface
+type authorStatsService struct {
+	authorStatsRepo models.AuthorStatsRepository
+}
+
+// NewAuthorStatsService creates a new author stats service
+func NewAuthorStatsService(authorStatsRepo models.AuthorStatsRepository) models.AuthorStatsService {
+	return &authorStatsService{authorStatsRepo: authorStatsRepo}
+}
+
+// Compute snapshots today's stats of every author. Running it again on the same day
+// refreshes the day's snapshot.
+func (s *authorStatsService) Compute() error {
+	now := time.Now()
+	if _, err := s.authorStatsRepo.Compute(now.Truncate(24*time.Hour), now); err != nil {
+		return errors.WrapError(err, "Failed to compute author stats")
+	}
+	return nil
+}
+
+// GetForUser gets the snapshots of the last days of an author, including today, newest first
+func (s *authorStatsService) GetForUser(userID uuid.UUID, days int) ([]*models.AuthorStats, error) {
+	since := time.Now().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+
+	snapshots, err := s.authorStatsRepo.History(userID, since)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get author stats")
+	}
+	if snapshots == nil {
+		snapshots = []*models.AuthorStats{}
+	}
+
+	return snapshots, nil
+}
+
+// ListAuthors lists the latest snapshot of every author with pagination
+func (s *authorStatsService) ListAuthors(orderBy string, page, perPage int) ([]*models.AuthorStats, int, error) {
+	offset := (page - 1) * perPage
+
+	snapshots, err := s.authorStatsRepo.ListLatest(orderBy, perPage, offset)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to list author stats")
+	}
+	if snapshots == nil {
+		snapshots = []*models.AuthorStats{}
+	}
+
+	total, err := s.authorStatsRepo.CountLatest()
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count author stats")
+	}
+
+	return snapshots, total, nil
+}
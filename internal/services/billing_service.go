@@ -0,0 +1,218 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"go-backend-api/internal/events"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// Stripe event types the webhook acts on; others are acknowledged and ignored
+const (
+	stripeSubscriptionCreated = "customer.subscription.created"
+	stripeSubscriptionUpdated = "customer.subscription.updated"
+	stripeSubscriptionDeleted = "customer.subscription.deleted"
+)
+
+// stripeSubscriptionEvents maps the Stripe subscription events to the domain events they publish
+var stripeSubscriptionEvents = map[string]string{
+	stripeSubscriptionCreated: events.SubscriptionCreated,
+	stripeSubscriptionUpdated: events.SubscriptionUpdated,
+	stripeSubscriptionDeleted: events.SubscriptionCancelled,
+}
+
+// stripeEvent is the envelope of a Stripe webhook delivery
+type stripeEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Created int64  `json:"created"`
+	Data    struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// stripeSubscription is the part of a Stripe subscription object the webhook reads
+type stripeSubscription struct {
+	ID                string            `json:"id"`
+	Customer          string            `json:"customer"`
+	Status            string            `json:"status"`
+	Metadata          map[string]string `json:"metadata"`
+	CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+	CurrentPeriodEnd  int64             `json:"current_period_end"`
+	Items             struct {
+		Data []struct {
+			Price struct {
+				ID string `json:"id"`
+			} `json:"price"`
+			// Newer API versions report the period per item instead of per subscription
+			CurrentPeriodEnd int64 `json:"current_period_end"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// BillingServiceConfig holds the Stripe settings of the billing service
+type BillingServiceConfig struct {
+	// ProPriceIDs are the Stripe prices of the pro plan; when empty, any active subscription is pro
+	ProPriceIDs []string
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// billingService implements BillingService interface
+type billingService struct {
+	billingRepo models.BillingRepository
+	userRepo    models.UserRepository
+	plans       models.PlanService
+	events      events.Publisher
+	cfg         BillingServiceConfig
+}
+
+// NewBillingService creates a new billing service that moves users between plans as Stripe
+// reports changes to their subscriptions, publishing subscription events to publisher
+func NewBillingService(billingRepo models.BillingRepository, userRepo models.UserRepository, plans models.PlanService, publisher events.Publisher, cfg BillingServiceConfig) models.BillingService {
+	if publisher == nil {
+		publisher = events.Discard
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	return &billingService{
+		billingRepo: billingRepo,
+		userRepo:    userRepo,
+		plans:       plans,
+		events:      publisher,
+		cfg:         cfg,
+	}
+}
+
+// HandleStripeEvent applies a Stripe subscription event; other events are ignored
+func (s *billingService) HandleStripeEvent(payload []byte) error {
+	var event stripeEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.ErrWebhookPayloadInvalid
+	}
+
+	domainEvent, ok := stripeSubscriptionEvents[event.Type]
+	if !ok {
+		return nil
+	}
+
+	var object stripeSubscription
+	if err := json.Unmarshal(event.Data.Object, &object); err != nil || object.ID == "" {
+		return errors.ErrWebhookPayloadInvalid.WithDetails("Invalid subscription in webhook payload")
+	}
+
+	userID, err := s.subscriptionUser(&object)
+	if err != nil {
+		return err
+	}
+	if userID == uuid.Nil {
+		// Retrying cannot help: the subscription was not created for one of our users
+		log.Printf("Ignoring Stripe event %s: no user for subscription %s of customer %s", event.ID, object.ID, object.Customer)
+		return nil
+	}
+
+	now := s.cfg.Clock.Now()
+	sub := &models.Subscription{
+		ID:                object.ID,
+		UserID:            userID,
+		CustomerID:        object.Customer,
+		Status:            object.Status,
+		CancelAtPeriodEnd: object.CancelAtPeriodEnd,
+		EventAt:           time.Unix(event.Created, 0),
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	periodEnd := object.CurrentPeriodEnd
+	if len(object.Items.Data) > 0 {
+		sub.PriceID = object.Items.Data[0].Price.ID
+		if periodEnd == 0 {
+			periodEnd = object.Items.Data[0].CurrentPeriodEnd
+		}
+	}
+	if periodEnd > 0 {
+		end := time.Unix(periodEnd, 0)
+		sub.CurrentPeriodEnd = &end
+	}
+
+	saved, err := s.billingRepo.SaveSubscription(sub)
+	if err != nil {
+		return err
+	}
+	if !saved {
+		// A newer event already updated the subscription
+		return nil
+	}
+
+	s.events.Publish(domainEvent, &events.SubscriptionChange{
+		UserID:         userID,
+		SubscriptionID: sub.ID,
+		CustomerID:     sub.CustomerID,
+		Status:         sub.Status,
+		PriceID:        sub.PriceID,
+	})
+
+	return s.syncPlan(userID)
+}
+
+// subscriptionUser finds the user of a subscription: the user_id set in its metadata at
+// checkout, or the user of earlier subscriptions of its customer. It returns uuid.Nil when
+// there is none.
+func (s *billingService) subscriptionUser(object *stripeSubscription) (uuid.UUID, error) {
+	userID, err := uuid.Parse(object.Metadata["user_id"])
+	if err != nil {
+		userID, err = s.billingRepo.GetUserIDByCustomer(object.Customer)
+		if errors.Is(err, models.ErrNotFound) {
+			return uuid.Nil, nil
+		}
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return uuid.Nil, nil
+		}
+		return uuid.Nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	return userID, nil
+}
+
+// syncPlan moves a user to the pro plan while one of their subscriptions is active on a pro
+// price, and back to free otherwise
+func (s *billingService) syncPlan(userID uuid.UUID) error {
+	subs, err := s.billingRepo.ListSubscriptionsByUser(userID)
+	if err != nil {
+		return err
+	}
+
+	plan := models.PlanFree
+	for _, sub := range subs {
+		if sub.IsActive() && s.isProPrice(sub.PriceID) {
+			plan = models.PlanPro
+			break
+		}
+	}
+
+	_, err = s.plans.ChangePlan(userID, plan, models.PlanSourceStripe)
+	return err
+}
+
+// isProPrice returns true if priceID is a price of the pro plan
+func (s *billingService) isProPrice(priceID string) bool {
+	if len(s.cfg.ProPriceIDs) == 0 {
+		return true
+	}
+	for _, id := range s.cfg.ProPriceIDs {
+		if id == priceID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/normalize"
+
+	"github.com/google/uuid"
+)
+
+// Default limits of how long a break-glass account stays enabled
+const (
+	DefaultBreakGlassDuration    = time.Hour
+	DefaultBreakGlassMaxDuration = 4 * time.Hour
+)
+
+// BreakGlassConfig holds the limits of break-glass accounts
+type BreakGlassConfig struct {
+	// DefaultDuration is how long an account is enabled when no duration is given
+	DefaultDuration time.Duration
+	// MaxDuration caps how long an account can be enabled at once
+	MaxDuration time.Duration
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// breakGlassDetails is the audit log detail payload of break-glass events
+type breakGlassDetails struct {
+	Operator     string     `json:"operator,omitempty"`
+	EnabledUntil *time.Time `json:"enabled_until,omitempty"`
+}
+
+// breakGlassService implements BreakGlassService interface
+type breakGlassService struct {
+	breakGlassRepo   models.BreakGlassRepository
+	userRepo         models.UserRepository
+	refreshTokenRepo models.RefreshTokenRepository
+	audit            *auditRecorder
+	cfg              BreakGlassConfig
+}
+
+// NewBreakGlassService creates a new break-glass account service
+func NewBreakGlassService(breakGlassRepo models.BreakGlassRepository, userRepo models.UserRepository, refreshTokenRepo models.RefreshTokenRepository, auditLogRepo models.AuditLogRepository, cfg BreakGlassConfig) models.BreakGlassService {
+	if cfg.MaxDuration <= 0 {
+		cfg.MaxDuration = DefaultBreakGlassMaxDuration
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	if cfg.DefaultDuration <= 0 || cfg.DefaultDuration > cfg.MaxDuration {
+		cfg.DefaultDuration = min(DefaultBreakGlassDuration, cfg.MaxDuration)
+	}
+	return &breakGlassService{
+		breakGlassRepo:   breakGlassRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		audit:            newAuditRecorder(auditLogRepo, nil),
+		cfg:              cfg,
+	}
+}
+
+// Provision creates the break-glass account of an active admin, or rotates its credential.
+// Either way the account is left disabled.
+func (s *breakGlassService) Provision(email, operator string) (string, error) {
+	user, err := s.admin(email)
+	if err != nil {
+		return "", err
+	}
+	if !user.IsActive {
+		return "", errors.ErrBreakGlassAdminOnly
+	}
+
+	credential, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to generate break-glass credential")
+	}
+	account := &models.BreakGlassAccount{
+		UserID:         user.ID,
+		CredentialHash: auth.HashToken(credential),
+		CreatedAt:      s.cfg.Clock.Now(),
+	}
+	if err := s.breakGlassRepo.Provision(account); err != nil {
+		return "", err
+	}
+
+	s.audit.record(&user.ID, models.AuditActionBreakGlassProvisioned, models.ClientInfo{}, &breakGlassDetails{Operator: operator})
+	log.Printf("BREAK-GLASS: credential of %s provisioned by %s", user.Email, operator)
+	return credential, nil
+}
+
+// Enable enables the break-glass account of an admin until duration from now. A duration of
+// zero or less uses the default; longer durations are capped at the maximum.
+func (s *breakGlassService) Enable(email string, duration time.Duration, operator string) (*models.BreakGlassAccount, error) {
+	user, err := s.admin(email)
+	if err != nil {
+		return nil, err
+	}
+	if duration <= 0 {
+		duration = s.cfg.DefaultDuration
+	}
+	duration = min(duration, s.cfg.MaxDuration)
+
+	until := s.cfg.Clock.Now().Add(duration)
+	if err := s.breakGlassRepo.Enable(user.ID, until, operator); err != nil {
+		return nil, writeError(err, errors.ErrBreakGlassNotFound, "Failed to enable break-glass account")
+	}
+	account, err := s.breakGlassRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.record(&user.ID, models.AuditActionBreakGlassEnabled, models.ClientInfo{}, &breakGlassDetails{Operator: operator, EnabledUntil: &until})
+	log.Printf("BREAK-GLASS: account of %s enabled by %s until %s", user.Email, operator, until.UTC().Format(time.RFC3339))
+	return account, nil
+}
+
+// Disable disables the break-glass account of an admin and revokes every session of theirs,
+// including those opened with it
+func (s *breakGlassService) Disable(email, operator string) error {
+	user, err := s.admin(email)
+	if err != nil {
+		return err
+	}
+	if err := s.breakGlassRepo.Disable(user.ID); err != nil {
+		return writeError(err, errors.ErrBreakGlassNotFound, "Failed to disable break-glass account")
+	}
+	if err := s.signOut(user.ID); err != nil {
+		return err
+	}
+
+	s.audit.record(&user.ID, models.AuditActionBreakGlassDisabled, models.ClientInfo{}, &breakGlassDetails{Operator: operator})
+	log.Printf("BREAK-GLASS: account of %s disabled by %s", user.Email, operator)
+	return nil
+}
+
+// List lists every break-glass account
+func (s *breakGlassService) List() ([]*models.BreakGlassAccount, error) {
+	return s.breakGlassRepo.List()
+}
+
+// Expire disables the break-glass accounts whose time ran out and revokes the sessions of
+// their admins, so access gained with them ends as well
+func (s *breakGlassService) Expire() (int, error) {
+	userIDs, err := s.breakGlassRepo.DisableExpired(s.cfg.Clock.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for _, userID := range userIDs {
+		if err := s.signOut(userID); err != nil {
+			return 0, err
+		}
+		s.audit.record(&userID, models.AuditActionBreakGlassExpired, models.ClientInfo{}, nil)
+		log.Printf("BREAK-GLASS: account of user %s expired", userID)
+	}
+
+	return len(userIDs), nil
+}
+
+// admin gets the admin with an email address, returning ErrBreakGlassAdminOnly for other users
+func (s *breakGlassService) admin(email string) (*models.User, error) {
+	user, err := s.userRepo.GetByEmail(normalize.Email(email))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+	if !user.IsAdmin() {
+		return nil, errors.ErrBreakGlassAdminOnly
+	}
+	return user, nil
+}
+
+// signOut revokes every session and access token of a user
+func (s *breakGlassService) signOut(userID uuid.UUID) error {
+	if err := s.refreshTokenRepo.RevokeAllForUser(userID); err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+	if err := s.userRepo.DenyTokensIssuedBefore(userID, s.cfg.Clock.Now()); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to revoke access tokens")
+	}
+	return nil
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-backend-api/internal/events"
+	"go-backend-api/internal/mailer"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/text"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used when the comment settings are not configured
+const (
+	DefaultCommentMaxDepth    = 4
+	DefaultCommentMaxMentions = 10
+)
+
+// mentionExcerptWords is how much of a comment a mention notification quotes
+const mentionExcerptWords = 50
+
+// CommentServiceConfig holds the threading and mention settings of the comment service
+type CommentServiceConfig struct {
+	// MaxDepth is the deepest reply level; top-level comments are depth 0
+	MaxDepth int
+	// MaxMentions is how many distinct @mentions per comment are notified
+	MaxMentions int
+	// Events receives a CommentCreated event per comment (nil publishes to no one)
+	Events events.Publisher
+}
+
+// commentService implements CommentService interface
+type commentService struct {
+	commentRepo  models.CommentRepository
+	postRepo     models.PostRepository
+	userRepo     models.UserRepository
+	reactionRepo models.ReactionRepository
+	mailer       mailer.Mailer
+	validator    *validation.Validator
+	cfg          CommentServiceConfig
+}
+
+// NewCommentService creates a new comment service
+func NewCommentService(commentRepo models.CommentRepository, postRepo models.PostRepository, userRepo models.UserRepository, reactionRepo models.ReactionRepository, mailer mailer.Mailer, cfg CommentServiceConfig) models.CommentService {
+	if cfg.MaxDepth < 0 {
+		cfg.MaxDepth = DefaultCommentMaxDepth
+	}
+	if cfg.MaxMentions < 0 {
+		cfg.MaxMentions = DefaultCommentMaxMentions
+	}
+	if cfg.Events == nil {
+		cfg.Events = events.Discard
+	}
+	return &commentService{
+		commentRepo:  commentRepo,
+		postRepo:     postRepo,
+		userRepo:     userRepo,
+		reactionRepo: reactionRepo,
+		mailer:       mailer,
+		validator:    validation.NewValidator(),
+		cfg:          cfg,
+	}
+}
+
+// CreateComment comments on a post, or replies to one of its comments, and notifies mentioned users
+func (s *commentService) CreateComment(postID, authorID uuid.UUID, req *models.CreateCommentRequest) (*models.Comment, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	post, err := visiblePost(s.postRepo, postID, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if post.IsArchived() {
+		return nil, errors.ErrPostArchived
+	}
+
+	author, err := s.userRepo.GetByID(authorID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get author")
+	}
+	// Sandbox accounts only comment on their own posts, which nobody else sees
+	if author.Role == models.RoleSandbox && post.AuthorID != authorID {
+		return nil, errors.ErrSandboxRestricted
+	}
+
+	now := time.Now()
+	comment := &models.Comment{
+		PostID:    postID,
+		AuthorID:  authorID,
+		Content:   req.Content,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if req.ParentCommentID != nil {
+		parent, err := s.commentRepo.GetByID(*req.ParentCommentID)
+		if errors.Is(err, models.ErrNotFound) || (err == nil && parent.PostID != postID) {
+			return nil, errors.ErrCommentNotFound
+		}
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to get parent comment")
+		}
+		if parent.Depth >= s.cfg.MaxDepth {
+			return nil, errors.ErrCommentTooDeep
+		}
+		comment.ParentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := s.commentRepo.Create(comment); err != nil {
+		return nil, errors.WrapError(err, "Failed to create comment")
+	}
+
+	author.Sanitize()
+	comment.Author = author
+
+	if author.Role != models.RoleSandbox {
+		s.notifyMentions(comment, post)
+	}
+	s.cfg.Events.Publish(events.CommentCreated, &events.CommentCreation{
+		CommentID: comment.ID,
+		PostID:    postID,
+		AuthorID:  authorID,
+		ParentID:  comment.ParentID,
+	})
+
+	return comment, nil
+}
+
+// GetComments gets the comments of a post in the order they were written, regardless of nesting
+func (s *commentService) GetComments(postID, viewerID uuid.UUID, page, perPage int) ([]*models.Comment, int, error) {
+	if _, err := visiblePost(s.postRepo, postID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	comments, err := s.commentRepo.ListByPost(postID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get comments")
+	}
+
+	total, err := s.commentRepo.CountByPost(postID)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count comments")
+	}
+
+	if err := s.attachReactions(comments); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// GetThreads gets a page of top-level comments with their earliest replies nested below them
+func (s *commentService) GetThreads(postID, viewerID uuid.UUID, page, perPage, repliesPerBranch int) ([]*models.Comment, int, error) {
+	if _, err := visiblePost(s.postRepo, postID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	comments, err := s.commentRepo.ListTopLevel(postID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get comments")
+	}
+
+	total, err := s.commentRepo.CountTopLevel(postID)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count comments")
+	}
+
+	if err := s.nestReplies(comments, repliesPerBranch); err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachReactions(comments); err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// GetReplies pages through the direct replies of a comment, so clients can expand one branch
+func (s *commentService) GetReplies(postID, commentID, viewerID uuid.UUID, page, perPage, repliesPerBranch int) ([]*models.Comment, int, error) {
+	if _, err := visiblePost(s.postRepo, postID, viewerID); err != nil {
+		return nil, 0, err
+	}
+
+	parent, err := s.commentRepo.GetByID(commentID)
+	if errors.Is(err, models.ErrNotFound) || (err == nil && parent.PostID != postID) {
+		return nil, 0, errors.ErrCommentNotFound
+	}
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get comment")
+	}
+
+	replies, err := s.commentRepo.ListRepliesOf(commentID, perPage, (page-1)*perPage)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get replies")
+	}
+
+	if err := s.nestReplies(replies, repliesPerBranch); err != nil {
+		return nil, 0, err
+	}
+	if err := s.attachReactions(replies); err != nil {
+		return nil, 0, err
+	}
+
+	return replies, parent.ReplyCount, nil
+}
+
+// nestReplies attaches the earliest replies of every comment, level by level with one query per
+// level, so fetching a tree costs at most one query per nesting level
+func (s *commentService) nestReplies(comments []*models.Comment, perBranch int) error {
+	level := comments
+	for perBranch > 0 && len(level) > 0 {
+		parents := make(map[uuid.UUID]*models.Comment)
+		var parentIDs []uuid.UUID
+		for _, comment := range level {
+			if comment.ReplyCount > 0 {
+				parents[comment.ID] = comment
+				parentIDs = append(parentIDs, comment.ID)
+			}
+		}
+		if len(parentIDs) == 0 {
+			return nil
+		}
+
+		replies, err := s.commentRepo.ListReplies(parentIDs, perBranch)
+		if err != nil {
+			return errors.WrapError(err, "Failed to get replies")
+		}
+		for _, reply := range replies {
+			parent := parents[*reply.ParentID]
+			parent.Replies = append(parent.Replies, reply)
+		}
+		level = replies
+	}
+
+	return nil
+}
+
+// attachReactions fills in the reaction counts of comments and their nested replies with one query
+func (s *commentService) attachReactions(comments []*models.Comment) error {
+	var all []*models.Comment
+	var collect func(level []*models.Comment)
+	collect = func(level []*models.Comment) {
+		for _, comment := range level {
+			all = append(all, comment)
+			collect(comment.Replies)
+		}
+	}
+	collect(comments)
+
+	ids := make([]uuid.UUID, len(all))
+	for i, comment := range all {
+		ids[i] = comment.ID
+	}
+	counts, err := s.reactionRepo.CountForComments(ids)
+	if err != nil {
+		return errors.WrapError(err, "Failed to count reactions")
+	}
+	for _, comment := range all {
+		comment.Reactions = counts[comment.ID]
+	}
+
+	return nil
+}
+
+// notifyMentions emails the active users mentioned in a comment who can see the post.
+// The comment is already stored; failed notifications are only logged.
+func (s *commentService) notifyMentions(comment *models.Comment, post *models.Post) {
+	for _, username := range text.Mentions(comment.Content, s.cfg.MaxMentions) {
+		user, err := s.userRepo.GetByUsername(username)
+		if err != nil {
+			if !errors.Is(err, models.ErrNotFound) {
+				log.Printf("Failed to look up mentioned user %q: %v", username, err)
+			}
+			continue
+		}
+		if !user.IsActive || user.ID == comment.AuthorID {
+			continue
+		}
+		if user.ID != post.AuthorID && (!post.IsPublished || post.IsArchived()) {
+			continue
+		}
+		if err := checkVisible(s.postRepo, post, user.ID); err != nil {
+			continue
+		}
+
+		msg, err := mailer.Render("comment_mention", user.Email, map[string]interface{}{
+			"Username":  user.Username,
+			"Author":    comment.Author.Username,
+			"PostTitle": post.Title,
+			"Excerpt":   text.Excerpt(comment.Content, mentionExcerptWords),
+		})
+		if err == nil {
+			err = s.mailer.Send(msg)
+		}
+		if err != nil {
+			log.Printf("Failed to notify user %s of mention in comment %s: %v", user.ID, comment.ID, err)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"log"
+	"net/url"
+	"time"
+
+	"go-backend-api/internal/mailer"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/shortid"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used when the digest settings are not configured
+const (
+	DefaultDigestPosts     = 10
+	DefaultDigestPeriod    = 7 * 24 * time.Hour
+	DefaultDigestBatchSize = 100
+)
+
+// DigestServiceConfig holds the content and sending settings of the digest service
+type DigestServiceConfig struct {
+	// BaseURL is the external base URL used to build links in emails
+	BaseURL string
+	// Posts is how many top posts a digest lists
+	Posts int
+	// Period is how far apart a user's digests are sent, and how far back they look
+	Period time.Duration
+	// BatchSize is how many due digests a run loads at a time
+	BatchSize int
+	// SendRate caps how many digests are mailed per second (0 for no cap), to stay within the
+	// mail provider's limits
+	SendRate int
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// digestService implements DigestService interface
+type digestService struct {
+	digestRepo models.DigestRepository
+	userRepo   models.UserRepository
+	mailer     mailer.Mailer
+	validator  *validation.Validator
+	cfg        DigestServiceConfig
+}
+
+// NewDigestService creates a new digest service
+func NewDigestService(digestRepo models.DigestRepository, userRepo models.UserRepository, mailer mailer.Mailer, cfg DigestServiceConfig) models.DigestService {
+	if cfg.Posts <= 0 {
+		cfg.Posts = DefaultDigestPosts
+	}
+	if cfg.Period <= 0 {
+		cfg.Period = DefaultDigestPeriod
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultDigestBatchSize
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	return &digestService{
+		digestRepo: digestRepo,
+		userRepo:   userRepo,
+		mailer:     mailer,
+		validator:  validation.NewValidator(),
+		cfg:        cfg,
+	}
+}
+
+// GetSettings tells whether a user receives the digest
+func (s *digestService) GetSettings(userID uuid.UUID) (*models.DigestSettings, error) {
+	sub, err := s.digestRepo.GetByUser(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return &models.DigestSettings{}, nil
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get digest subscription")
+	}
+
+	return &models.DigestSettings{Enabled: true, NextSendAt: &sub.NextSendAt, LastSentAt: sub.LastSentAt}, nil
+}
+
+// UpdateSettings opts a user in to or out of the digest. The first digest is sent a period
+// after opting in; opting in again keeps the schedule.
+func (s *digestService) UpdateSettings(userID uuid.UUID, req *models.UpdateDigestRequest) (*models.DigestSettings, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	if !*req.Enabled {
+		if err := s.digestRepo.Unsubscribe(userID); err != nil {
+			return nil, err
+		}
+		return &models.DigestSettings{}, nil
+	}
+
+	now := s.cfg.Clock.Now()
+	sub := &models.DigestSubscription{UserID: userID, NextSendAt: now.Add(s.cfg.Period), CreatedAt: now}
+	if err := s.digestRepo.Subscribe(sub); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to subscribe to digest")
+	}
+
+	return s.GetSettings(userID)
+}
+
+// Unsubscribe opts out the user whose latest digest carried the token. Links in earlier
+// digests stop working once a newer digest is sent.
+func (s *digestService) Unsubscribe(token string) error {
+	sub, err := s.digestRepo.GetByUnsubscribeTokenHash(auth.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrInvalidUnsubscribeToken
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get digest subscription")
+	}
+
+	return s.digestRepo.Unsubscribe(sub.UserID)
+}
+
+// SendDigests mails the due digests, loading them in batches and waiting between emails so
+// that no more than SendRate go out per second. Users without top posts in the period are
+// scheduled a period later without an email. A failure for one user is logged and the next
+// one is tried; it is retried on the next run.
+func (s *digestService) SendDigests() error {
+	var throttle <-chan time.Time
+	if s.cfg.SendRate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(s.cfg.SendRate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	now := s.cfg.Clock.Now()
+	sent := 0
+	for {
+		subs, err := s.digestRepo.ListDue(now, s.cfg.BatchSize)
+		if err != nil {
+			return errors.WrapError(err, "Failed to list due digests")
+		}
+
+		failed := 0
+		for _, sub := range subs {
+			mailed, err := s.send(sub, now, throttle)
+			if err != nil {
+				log.Printf("Failed to send digest to user %s: %v", sub.UserID, err)
+				failed++
+				continue
+			}
+			if mailed {
+				sent++
+			}
+		}
+
+		// Failed digests stay due; stop rather than load them again
+		if len(subs) < s.cfg.BatchSize || failed == len(subs) {
+			break
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d digests", sent)
+	}
+	return nil
+}
+
+// send mails one due digest, waiting on throttle first, and reports whether an email was sent
+func (s *digestService) send(sub *models.DigestSubscription, now time.Time, throttle <-chan time.Time) (bool, error) {
+	user, err := s.userRepo.GetByID(sub.UserID)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to get user")
+	}
+
+	var posts []*models.Post
+	if user.IsActive {
+		posts, err = s.digestRepo.TopPosts(sub.UserID, now.Add(-s.cfg.Period), now, s.cfg.Posts)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if len(posts) > 0 {
+		token, err := auth.GenerateOpaqueToken()
+		if err != nil {
+			return false, errors.WrapError(err, "Failed to generate unsubscribe token")
+		}
+		if throttle != nil {
+			<-throttle
+		}
+		if err := s.mail(user, posts, token); err != nil {
+			return false, err
+		}
+		hash := auth.HashToken(token)
+		sub.UnsubscribeTokenHash = &hash
+		sub.LastSentAt = &now
+	}
+
+	sub.NextSendAt = now.Add(s.cfg.Period)
+	sub.UpdatedAt = now
+	if err := s.digestRepo.Update(sub); err != nil {
+		return false, errors.WrapError(err, "Failed to update digest subscription")
+	}
+
+	return len(posts) > 0, nil
+}
+
+// mail renders and sends a digest listing posts
+func (s *digestService) mail(user *models.User, posts []*models.Post, token string) error {
+	items := make([]map[string]string, len(posts))
+	for i, post := range posts {
+		items[i] = map[string]string{
+			"Title":  post.Title,
+			"Author": post.Author.Username,
+			"URL":    s.cfg.BaseURL + "/api/v1/p/" + shortid.Encode(post.Number),
+		}
+	}
+
+	msg, err := mailer.Render("weekly_digest", user.Email, map[string]interface{}{
+		"Username":       user.Username,
+		"Count":          len(posts),
+		"Posts":          items,
+		"UnsubscribeURL": s.cfg.BaseURL + "/api/v1/public/digest/unsubscribe?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		return errors.WrapError(err, "Failed to render email")
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return errors.WrapError(err, "Failed to send email")
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/normalize"
+
+	"github.com/google/uuid"
+)
+
+// sendGridEvent is the part of a SendGrid event webhook event the service reads
+type sendGridEvent struct {
+	Email     string `json:"email"`
+	Event     string `json:"event"`
+	Type      string `json:"type"` // For bounces: "bounce" when permanent, "blocked" when refused for now
+	Timestamp int64  `json:"timestamp"`
+}
+
+// emailDeliveryService implements EmailDeliveryService interface
+type emailDeliveryService struct {
+	userRepo models.UserRepository
+}
+
+// NewEmailDeliveryService creates a new email delivery service
+func NewEmailDeliveryService(userRepo models.UserRepository) models.EmailDeliveryService {
+	return &emailDeliveryService{userRepo: userRepo}
+}
+
+// HandleSendGridEvents marks the addresses of permanent bounces and spam reports undeliverable.
+// Marking is idempotent, so a redelivered batch does no harm.
+func (s *emailDeliveryService) HandleSendGridEvents(payload []byte) error {
+	var events []sendGridEvent
+	if err := json.Unmarshal(payload, &events); err != nil {
+		return errors.ErrWebhookPayloadInvalid
+	}
+
+	for _, event := range events {
+		var reason string
+		switch {
+		case event.Event == "spamreport":
+			reason = models.EmailComplaint
+		case event.Event == "bounce" && event.Type != "blocked":
+			reason = models.EmailBounce
+		default:
+			continue
+		}
+
+		at := time.Unix(event.Timestamp, 0)
+		marked, err := s.userRepo.MarkEmailUndeliverable(normalize.Email(event.Email), reason, at)
+		if err != nil {
+			return errors.WrapError(err, "Failed to mark email undeliverable")
+		}
+		if !marked {
+			// The address changed since, or never belonged to a user
+			log.Printf("Ignoring SendGrid %s event of an address no user has", event.Event)
+		}
+	}
+
+	return nil
+}
+
+// IsSuppressed checks if an address is marked undeliverable
+func (s *emailDeliveryService) IsSuppressed(address string) (bool, error) {
+	return s.userRepo.IsEmailUndeliverable(normalize.Email(address))
+}
+
+// ClearUndeliverable marks the address of a user deliverable again
+func (s *emailDeliveryService) ClearUndeliverable(userID uuid.UUID) error {
+	if err := s.userRepo.ClearEmailUndeliverable(userID); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to clear undeliverable email")
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"sync"
+
+	"go-backend-api/internal/mailer"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// previewRecipient is the address previews are rendered for; they are never sent
+const previewRecipient = "preview@example.com"
+
+// emailTemplateService implements EmailTemplateService interface. It keeps the current version
+// of every overridden template in memory, so that rendering an email never waits on the database.
+type emailTemplateService struct {
+	templateRepo models.EmailTemplateRepository
+	validator    *validation.Validator
+	clock        clock.Clock
+
+	mutex   sync.RWMutex
+	current map[string]*models.EmailTemplateVersion
+}
+
+// NewEmailTemplateService creates a new email template service; call Refresh to load the
+// saved versions
+func NewEmailTemplateService(templateRepo models.EmailTemplateRepository, clk clock.Clock) models.EmailTemplateService {
+	return &emailTemplateService{
+		templateRepo: templateRepo,
+		validator:    validation.NewValidator(),
+		clock:        clock.OrReal(clk),
+		current:      make(map[string]*models.EmailTemplateVersion),
+	}
+}
+
+// List lists the default templates with their current version
+func (s *emailTemplateService) List() ([]*models.EmailTemplateSummary, error) {
+	if err := s.Refresh(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	summaries := []*models.EmailTemplateSummary{}
+	for _, name := range mailer.Names() {
+		summary := &models.EmailTemplateSummary{Name: name}
+		if version, ok := s.current[name]; ok {
+			summary.Version = version.Version
+			summary.UpdatedAt = &version.CreatedAt
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// Get gets a template with its default and every saved version
+func (s *emailTemplateService) Get(name string) (*models.EmailTemplate, error) {
+	defaultSubject, defaultBody, ok := mailer.Default(name)
+	if !ok {
+		return nil, errors.ErrEmailTemplateNotFound
+	}
+
+	versions, err := s.templateRepo.ListByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl := &models.EmailTemplate{
+		Name:           name,
+		Subject:        defaultSubject,
+		Body:           defaultBody,
+		DefaultSubject: defaultSubject,
+		DefaultBody:    defaultBody,
+		Versions:       versions,
+	}
+	if len(versions) > 0 {
+		tmpl.Subject, tmpl.Body, tmpl.Version = versions[0].Subject, versions[0].Body, versions[0].Version
+	}
+
+	return tmpl, nil
+}
+
+// Update saves a new version of a template once it parses. Saving the default's content
+// again is how a template is reverted.
+func (s *emailTemplateService) Update(adminID uuid.UUID, name string, req *models.UpdateEmailTemplateRequest) (*models.EmailTemplateVersion, error) {
+	if _, _, ok := mailer.Default(name); !ok {
+		return nil, errors.ErrEmailTemplateNotFound
+	}
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+	if err := mailer.Parse(name, req.Subject, req.Body); err != nil {
+		return nil, errors.ErrEmailTemplateInvalid.WithDetails(err.Error())
+	}
+
+	version := &models.EmailTemplateVersion{
+		Name:      name,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		CreatedBy: &adminID,
+		CreatedAt: s.clock.Now(),
+	}
+	if err := s.templateRepo.Create(version); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.current[name] = version
+	s.mutex.Unlock()
+
+	return version, nil
+}
+
+// Preview renders a template, or the given subject and body, with sample data
+func (s *emailTemplateService) Preview(name string, req *models.PreviewEmailTemplateRequest) (*models.EmailPreview, error) {
+	defaultSubject, defaultBody, ok := mailer.Default(name)
+	if !ok {
+		return nil, errors.ErrEmailTemplateNotFound
+	}
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	subject, body := req.Subject, req.Body
+	if subject == "" {
+		subject, body = defaultSubject, defaultBody
+		if overrideSubject, overrideBody, ok := s.Template(name); ok {
+			subject, body = overrideSubject, overrideBody
+		}
+	}
+
+	msg, err := mailer.RenderText(name, subject, body, previewRecipient, req.Data)
+	if err != nil {
+		return nil, errors.ErrEmailTemplateInvalid.WithDetails(err.Error())
+	}
+
+	return &models.EmailPreview{Subject: msg.Subject, Body: msg.Body}, nil
+}
+
+// Template returns the current version of an overridden template
+func (s *emailTemplateService) Template(name string) (string, string, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	version, ok := s.current[name]
+	if !ok {
+		return "", "", false
+	}
+	return version.Subject, version.Body, true
+}
+
+// Refresh reloads the current version of every overridden template
+func (s *emailTemplateService) Refresh() error {
+	versions, err := s.templateRepo.ListCurrent()
+	if err != nil {
+		return err
+	}
+
+	current := make(map[string]*models.EmailTemplateVersion, len(versions))
+	for _, version := range versions {
+		// Versions of templates no longer shipped are kept but never used
+		if _, _, ok := mailer.Default(version.Name); !ok {
+			continue
+		}
+		current[version.Name] = version
+	}
+
+	s.mutex.Lock()
+	s.current = current
+	s.mutex.Unlock()
+
+	return nil
+}
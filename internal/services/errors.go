@@ -0,0 +1,14 @@
+package services
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// writeError maps a repository write that matched no rows to notFound, and wraps any other failure
+func writeError(err error, notFound *errors.AppError, message string) error {
+	if errors.Is(err, models.ErrNotFound) {
+		return notFound
+	}
+	return errors.WrapError(err, message)
+}
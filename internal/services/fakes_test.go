@@ -0,0 +1,204 @@
+package services
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// fakeUserRepo keeps users in memory and records the writes made to them. Methods the tests
+// do not need are left to the embedded nil interface and panic when called.
+type fakeUserRepo struct {
+	models.UserRepository
+	mu           sync.Mutex
+	users        map[uuid.UUID]*models.User
+	deniedBefore map[uuid.UUID]time.Time
+	calls        *[]string
+	emailFetches int
+	// timezones are the timezone preferences of users, UTC when unset
+	timezones map[uuid.UUID]string
+}
+
+func newFakeUserRepo(calls *[]string, users ...*models.User) *fakeUserRepo {
+	r := &fakeUserRepo{users: map[uuid.UUID]*models.User{}, deniedBefore: map[uuid.UUID]time.Time{}, calls: calls}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeUserRepo) record(call string) {
+	if r.calls != nil {
+		*r.calls = append(*r.calls, call)
+	}
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	copied := *user
+	return &copied, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.emailFetches++
+	for _, user := range r.users {
+		if user.Email == email {
+			copied := *user
+			return &copied, nil
+		}
+	}
+	return nil, models.ErrNotFound
+}
+
+func (r *fakeUserRepo) setActive(id uuid.UUID, active bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	user.IsActive = active
+	return nil
+}
+
+func (r *fakeUserRepo) Activate(id uuid.UUID) error {
+	r.record("Activate")
+	return r.setActive(id, true)
+}
+
+func (r *fakeUserRepo) Deactivate(id uuid.UUID) error {
+	r.record("Deactivate")
+	return r.setActive(id, false)
+}
+
+func (r *fakeUserRepo) UpdateLastLogin(id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return models.ErrNotFound
+	}
+	now := time.Now()
+	user.LastLogin = &now
+	return nil
+}
+
+func (r *fakeUserRepo) DenyTokensIssuedBefore(id uuid.UUID, cutoff time.Time) error {
+	r.record("DenyTokensIssuedBefore")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return models.ErrNotFound
+	}
+	r.deniedBefore[id] = cutoff
+	return nil
+}
+
+func (r *fakeUserRepo) DenyAllTokensIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error) {
+	r.record("DenyAllTokensIssuedBefore")
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var denied int64
+	for id := range r.users {
+		if len(userIDs) == 0 || slices.Contains(userIDs, id) {
+			r.deniedBefore[id] = cutoff
+			denied++
+		}
+	}
+	return denied, nil
+}
+
+func (r *fakeUserRepo) GetTokenState(id uuid.UUID) (*models.UserTokenState, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, models.ErrNotFound
+	}
+	state := &models.UserTokenState{IsActive: user.IsActive, Role: user.Role}
+	if cutoff, ok := r.deniedBefore[id]; ok {
+		state.TokensDeniedBefore = &cutoff
+	}
+	return state, nil
+}
+
+func (r *fakeUserRepo) GetPreferences(id uuid.UUID) (*models.UserPreferences, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return nil, models.ErrNotFound
+	}
+	return &models.UserPreferences{PreferredLanguages: []string{}, Timezone: r.timezones[id]}, nil
+}
+
+// fakeRefreshTokenRepo records which users had their sessions revoked
+type fakeRefreshTokenRepo struct {
+	models.RefreshTokenRepository
+	mu      sync.Mutex
+	revoked map[uuid.UUID]bool
+	calls   *[]string
+}
+
+func (r *fakeRefreshTokenRepo) RevokeAllForUser(userID uuid.UUID) error {
+	if r.calls != nil {
+		*r.calls = append(*r.calls, "RevokeAllForUser")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.revoked == nil {
+		r.revoked = map[uuid.UUID]bool{}
+	}
+	r.revoked[userID] = true
+	return nil
+}
+
+// Sessions are not kept: signing in only needs storing them to succeed
+func (r *fakeRefreshTokenRepo) Create(tokenID, tokenHash string, userID uuid.UUID, expiresAt time.Time, meta models.SessionMetadata) error {
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeForDevice(userID uuid.UUID, deviceID, fingerprint string) error {
+	return nil
+}
+
+// Bulk revocations revoke nothing, as no sessions are kept
+func (r *fakeRefreshTokenRepo) RevokeIssuedBefore(userIDs []uuid.UUID, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+// fakeClientRepo holds clients machine clients and records the cutoff their tokens are denied at
+type fakeClientRepo struct {
+	models.OAuthClientRepository
+	clients int64
+	cutoff  *time.Time
+}
+
+func (r *fakeClientRepo) DenyAllTokensIssuedBefore(cutoff time.Time) (int64, error) {
+	r.cutoff = &cutoff
+	return r.clients, nil
+}
+
+// fakeAuditLogRepo keeps the audit log entries written
+type fakeAuditLogRepo struct {
+	models.AuditLogRepository
+	mu      sync.Mutex
+	entries []*models.AuditLog
+}
+
+func (r *fakeAuditLogRepo) Create(entry *models.AuditLog) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+	return nil
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"log"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// DefaultFeedMaxEntries is how many posts a stored feed keeps when none is configured
+const DefaultFeedMaxEntries = 500
+
+// DefaultFeedQueueSize is how many feed updates wait for the worker before new ones are dropped
+const DefaultFeedQueueSize = 1000
+
+// feedUpdate is a queued change of the stored feeds, described for logs
+type feedUpdate struct {
+	what  string
+	apply func() error
+}
+
+// FeedFanOut stores the posts of authors in the feeds of their followers as they are published
+// (fan-out-on-write), so a feed is read with one range scan. Changes are applied by a background
+// worker, so writes never wait on fanning out to many followers; updates made while the queue
+// is full are dropped and logged, and a rebuild repairs them.
+type FeedFanOut struct {
+	feedRepo   models.FeedRepository
+	maxEntries int
+	queue      chan feedUpdate
+}
+
+// NewFeedFanOut creates a fan-out keeping up to maxEntries posts per feed, queueing up to
+// queueSize updates
+func NewFeedFanOut(feedRepo models.FeedRepository, maxEntries, queueSize int) *FeedFanOut {
+	if maxEntries < 1 {
+		maxEntries = DefaultFeedMaxEntries
+	}
+	if queueSize < 1 {
+		queueSize = DefaultFeedQueueSize
+	}
+	return &FeedFanOut{
+		feedRepo:   feedRepo,
+		maxEntries: maxEntries,
+		queue:      make(chan feedUpdate, queueSize),
+	}
+}
+
+// PostChanged queues a post to be stored in the feeds of its author's followers when it is
+// published and public or followers-only, and removed from them otherwise
+func (f *FeedFanOut) PostChanged(post *models.Post) {
+	postID, authorID := post.ID, post.AuthorID
+	if post.State() != models.PostStatePublished || authorID == uuid.Nil ||
+		(post.Visibility != models.PostVisibilityPublic && post.Visibility != models.PostVisibilityFollowers) {
+		f.PostDeleted(postID)
+		return
+	}
+
+	publishedAt := post.CreatedAt
+	if post.PublishedAt != nil {
+		publishedAt = *post.PublishedAt
+	}
+	f.enqueue("fan out post "+postID.String(), func() error {
+		return f.feedRepo.FanOut(postID, authorID, publishedAt, f.maxEntries)
+	})
+}
+
+// PostDeleted queues the removal of a post from every feed
+func (f *FeedFanOut) PostDeleted(id uuid.UUID) {
+	f.enqueue("remove post "+id.String(), func() error {
+		return f.feedRepo.RemovePost(id)
+	})
+}
+
+// Followed queues the backfill of the follower's feed with the followee's latest posts
+func (f *FeedFanOut) Followed(followerID, followeeID uuid.UUID) {
+	f.enqueue("backfill feed of "+followerID.String(), func() error {
+		return f.feedRepo.Backfill(followerID, followeeID, f.maxEntries)
+	})
+}
+
+// Unfollowed queues the removal of the followee's posts from the follower's feed
+func (f *FeedFanOut) Unfollowed(followerID, followeeID uuid.UUID) {
+	f.enqueue("remove author from feed of "+followerID.String(), func() error {
+		return f.feedRepo.RemoveAuthor(followerID, followeeID)
+	})
+}
+
+// GetPosts gets a page of a user's stored feed and its number of entries
+func (f *FeedFanOut) GetPosts(userID uuid.UUID, limit, offset int) ([]*models.Post, int, error) {
+	posts, err := f.feedRepo.GetPosts(userID, limit, offset)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to get feed")
+	}
+
+	total, err := f.feedRepo.Count(userID)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count feed entries")
+	}
+
+	return posts, total, nil
+}
+
+// Rebuild rebuilds every stored feed right away, as after turning fan-out on
+func (f *FeedFanOut) Rebuild() (int64, error) {
+	entries, err := f.feedRepo.Rebuild(f.maxEntries)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to rebuild feeds")
+	}
+	return entries, nil
+}
+
+// enqueue queues an update without blocking
+func (f *FeedFanOut) enqueue(what string, apply func() error) {
+	select {
+	case f.queue <- feedUpdate{what: what, apply: apply}:
+	default:
+		log.Printf("Feed queue is full, dropping update to %s", what)
+	}
+}
+
+// Start applies queued updates in the background until stop is closed
+func (f *FeedFanOut) Start(stop <-chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case u := <-f.queue:
+				if err := u.apply(); err != nil {
+					log.Printf("Failed to %s: %v", u.what, err)
+				}
+			}
+		}
+	}()
+}
+
+// GetFeed gets a page of the published posts of the authors the user follows, newest first:
+// from their stored feed with fan-out-on-write, otherwise by joining across their follows
+func (s *postService) GetFeed(userID uuid.UUID, page, perPage int) ([]*models.Post, int, error) {
+	offset := (page - 1) * perPage
+
+	var posts []*models.Post
+	var total int
+	var err error
+	if s.cfg.Feed != nil {
+		if posts, total, err = s.cfg.Feed.GetPosts(userID, perPage, offset); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		if posts, err = s.postRepo.GetFeed(userID, perPage, offset); err != nil {
+			return nil, 0, errors.WrapError(err, "Failed to get feed")
+		}
+		if total, err = s.postRepo.CountFeed(userID); err != nil {
+			return nil, 0, errors.WrapError(err, "Failed to count feed posts")
+		}
+	}
+
+	if err := s.attachReactions(posts...); err != nil {
+		return nil, 0, err
+	}
+
+	return posts, total, nil
+}
+
+// RebuildFeeds rebuilds every stored feed from the follows, returning how many entries it stored
+func (s *postService) RebuildFeeds() (int64, error) {
+	if s.cfg.Feed == nil {
+		return 0, errors.ErrFeedFanOutNotConfigured
+	}
+	return s.cfg.Feed.Rebuild()
+}
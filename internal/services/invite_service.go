@@ -0,0 +1,162 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/normalize"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// InviteServiceConfig holds tunable settings for the invite service
+type InviteServiceConfig struct {
+	// QuotaPerUser is how many invites a regular user may create; admins are unlimited
+	QuotaPerUser int
+	// DefaultTTL is how long an invite is valid when the request does not say
+	DefaultTTL time.Duration
+}
+
+// inviteService implements InviteService interface
+type inviteService struct {
+	inviteRepo models.InviteRepository
+	validator  *validation.Validator
+	cfg        InviteServiceConfig
+}
+
+// NewInviteService creates a new invite service
+func NewInviteService(inviteRepo models.InviteRepository, cfg InviteServiceConfig) models.InviteService {
+	return &inviteService{
+		inviteRepo: inviteRepo,
+		validator:  validation.NewValidator(),
+		cfg:        cfg,
+	}
+}
+
+// CreateInvite creates an invite code. Regular users are limited by their quota and to single-use invites.
+func (s *inviteService) CreateInvite(creatorID uuid.UUID, isAdmin bool, req *models.CreateInviteRequest) (*models.Invite, error) {
+	req.Email = normalize.Email(req.Email)
+
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	if !isAdmin {
+		count, err := s.inviteRepo.CountByCreator(creatorID)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to count invites")
+		}
+		if count >= s.cfg.QuotaPerUser {
+			return nil, errors.NewErrorWithCode(403, "Invite quota exceeded")
+		}
+		req.MaxUses = 1
+	}
+
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+
+	ttl := s.cfg.DefaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	code, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate invite code")
+	}
+
+	now := time.Now()
+	invite := &models.Invite{
+		Code:      code,
+		CodeHash:  auth.HashToken(code),
+		CreatedBy: &creatorID,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if req.Email != "" {
+		invite.Email = &req.Email
+	}
+
+	if err := s.inviteRepo.Create(invite); err != nil {
+		return nil, errors.WrapError(err, "Failed to create invite")
+	}
+
+	return invite, nil
+}
+
+// GetInvite gets an invite together with the registrations made with it
+func (s *inviteService) GetInvite(id uuid.UUID) (*models.Invite, error) {
+	invite, err := s.inviteRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get invite")
+	}
+
+	uses, err := s.inviteRepo.GetUses(id)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get invite uses")
+	}
+	invite.Uses = uses
+
+	return invite, nil
+}
+
+// ListInvites lists all invites with pagination
+func (s *inviteService) ListInvites(page, perPage int) ([]*models.Invite, int, error) {
+	offset := (page - 1) * perPage
+
+	invites, err := s.inviteRepo.List(perPage, offset)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to list invites")
+	}
+
+	total, err := s.inviteRepo.Count()
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count invites")
+	}
+
+	return invites, total, nil
+}
+
+// ListUserInvites lists the invites created by a user
+func (s *inviteService) ListUserInvites(userID uuid.UUID) ([]*models.Invite, error) {
+	invites, err := s.inviteRepo.ListByCreator(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list invites")
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite revokes an invite. Regular users may only revoke their own invites.
+func (s *inviteService) RevokeInvite(id, requesterID uuid.UUID, isAdmin bool) error {
+	invite, err := s.inviteRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrInviteNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get invite")
+	}
+
+	if !isAdmin && (invite.CreatedBy == nil || *invite.CreatedBy != requesterID) {
+		return errors.NewErrorWithCode(403, "You can only revoke your own invites")
+	}
+
+	if invite.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.inviteRepo.Revoke(id); err != nil {
+		return writeError(err, errors.ErrInviteNotFound, "Failed to revoke invite")
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// Deletions a legal hold refuses, as recorded in the audit log
+const (
+	legalHoldActionDeleteAccount = "delete_account"
+	legalHoldActionPurgeAccount  = "lifecycle_purge"
+	legalHoldActionDeletePost    = "delete_post"
+	legalHoldActionDropPartition = "drop_partition"
+)
+
+// legalHoldDetails is the audit log detail payload of placing or clearing a legal hold
+type legalHoldDetails struct {
+	AdminID uuid.UUID `json:"admin_id"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// legalHoldBlockedDetails is the audit log detail payload of a deletion refused by a legal hold
+type legalHoldBlockedDetails struct {
+	Action string `json:"action"`
+	Target string `json:"target,omitempty"` // e.g. the post or partition
+}
+
+// legalHoldGuard refuses deletions of the data of users under legal hold, recording every
+// refused attempt in the audit log
+type legalHoldGuard struct {
+	repo  models.LegalHoldRepository
+	audit *auditRecorder
+}
+
+// newLegalHoldGuard creates a legal hold guard
+func newLegalHoldGuard(repo models.LegalHoldRepository, audit *auditRecorder) *legalHoldGuard {
+	return &legalHoldGuard{repo: repo, audit: audit}
+}
+
+// check returns ErrLegalHold, and audits the attempt, if userID is under legal hold
+func (g *legalHoldGuard) check(userID uuid.UUID, action, target string, client models.ClientInfo) error {
+	held, err := g.repo.IsHeld(userID)
+	if err != nil {
+		return errors.WrapError(err, "Failed to check legal hold")
+	}
+	if !held {
+		return nil
+	}
+
+	g.blocked(userID, action, target, client)
+	return errors.ErrLegalHold
+}
+
+// blocked audits a deletion refused because userID is under legal hold
+func (g *legalHoldGuard) blocked(userID uuid.UUID, action, target string, client models.ClientInfo) {
+	g.audit.record(&userID, models.AuditActionLegalHoldBlocked, client, &legalHoldBlockedDetails{Action: action, Target: target})
+}
+
+// legalHoldService implements LegalHoldService interface
+type legalHoldService struct {
+	legalHoldRepo models.LegalHoldRepository
+	userRepo      models.UserRepository
+	audit         *auditRecorder
+	validator     *validation.Validator
+}
+
+// NewLegalHoldService creates a new legal hold service
+func NewLegalHoldService(legalHoldRepo models.LegalHoldRepository, userRepo models.UserRepository, auditLogRepo models.AuditLogRepository) models.LegalHoldService {
+	return &legalHoldService{
+		legalHoldRepo: legalHoldRepo,
+		userRepo:      userRepo,
+		audit:         newAuditRecorder(auditLogRepo, nil),
+		validator:     validation.NewValidator(),
+	}
+}
+
+// Place places a legal hold on a user, or updates its reason
+func (s *legalHoldService) Place(adminID, userID uuid.UUID, req *models.PlaceLegalHoldRequest, client models.ClientInfo) (*models.LegalHold, error) {
+	req.Reason = strings.TrimSpace(req.Reason)
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	hold := &models.LegalHold{
+		UserID:   userID,
+		Reason:   req.Reason,
+		PlacedBy: &adminID,
+		PlacedAt: time.Now(),
+	}
+	if err := s.legalHoldRepo.Place(hold); err != nil {
+		return nil, errors.WrapError(err, "Failed to place legal hold")
+	}
+
+	s.audit.record(&userID, models.AuditActionLegalHoldPlaced, client, &legalHoldDetails{AdminID: adminID, Reason: req.Reason})
+	return hold, nil
+}
+
+// Get gets the legal hold of a user
+func (s *legalHoldService) Get(userID uuid.UUID) (*models.LegalHold, error) {
+	hold, err := s.legalHoldRepo.GetByUserID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrLegalHoldNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get legal hold")
+	}
+	return hold, nil
+}
+
+// List lists every legal hold, newest first
+func (s *legalHoldService) List() ([]*models.LegalHold, error) {
+	holds, err := s.legalHoldRepo.List()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list legal holds")
+	}
+	return holds, nil
+}
+
+// Clear clears the legal hold of a user, making their data deletable again
+func (s *legalHoldService) Clear(adminID, userID uuid.UUID, client models.ClientInfo) error {
+	if err := s.legalHoldRepo.Clear(userID); err != nil {
+		return writeError(err, errors.ErrLegalHoldNotFound, "Failed to clear legal hold")
+	}
+
+	s.audit.record(&userID, models.AuditActionLegalHoldCleared, client, &legalHoldDetails{AdminID: adminID})
+	return nil
+}
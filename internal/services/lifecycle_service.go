@@ -0,0 +1,203 @@
+package services
+
+import (
+	"strings"
+	"time"
+
+	"go-backend-api/internal/mailer"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// LifecyclePolicy configures the stale account policies. A threshold of zero disables that stage.
+// Each stage only follows the one before it during the same period of inactivity, so a user is
+// never deactivated without having been warned DeactivateAfterDays - WarnAfterDays days before,
+// nor deleted without having been deactivated PurgeAfterDays - DeactivateAfterDays days before,
+// however the thresholds were changed since.
+type LifecyclePolicy struct {
+	// WarnAfterDays is the inactivity after which the user is emailed a warning
+	WarnAfterDays int
+	// DeactivateAfterDays is the inactivity after which a warned account is deactivated
+	DeactivateAfterDays int
+	// PurgeAfterDays is the inactivity after which a deactivated account is deleted
+	PurgeAfterDays int
+	// ExcludedAccounts lists usernames or emails that are never affected; admins are always excluded
+	ExcludedAccounts []string
+	// BaseURL is the external base URL used to build links in emails
+	BaseURL string
+	// DeletedUserPosts is what happens to the posts of purged accounts (see UserServiceConfig)
+	DeletedUserPosts string
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// lifecycleService implements LifecycleService interface
+type lifecycleService struct {
+	userRepo      models.UserRepository
+	legalHoldRepo models.LegalHoldRepository
+	legalHold     *legalHoldGuard
+	mailer        mailer.Mailer
+	policy        LifecyclePolicy
+	excluded      map[string]struct{}
+}
+
+// NewLifecycleService creates a new stale account lifecycle service. Accounts under legal hold are never purged.
+func NewLifecycleService(userRepo models.UserRepository, legalHoldRepo models.LegalHoldRepository, auditLogRepo models.AuditLogRepository, mailer mailer.Mailer, policy LifecyclePolicy) models.LifecycleService {
+	policy.Clock = clock.OrReal(policy.Clock)
+	excluded := make(map[string]struct{}, len(policy.ExcludedAccounts))
+	for _, account := range policy.ExcludedAccounts {
+		excluded[strings.ToLower(strings.TrimSpace(account))] = struct{}{}
+	}
+
+	return &lifecycleService{
+		userRepo:      userRepo,
+		legalHoldRepo: legalHoldRepo,
+		legalHold:     newLegalHoldGuard(legalHoldRepo, newAuditRecorder(auditLogRepo, nil)),
+		mailer:        mailer,
+		policy:        policy,
+		excluded:      excluded,
+	}
+}
+
+// Run applies the stale account policies. In dry-run mode nothing is changed and no email is sent;
+// the report lists what a real run would do.
+func (s *lifecycleService) Run(dryRun bool) (*models.LifecycleReport, error) {
+	now := s.policy.Clock.Now()
+	report := &models.LifecycleReport{
+		DryRun:      dryRun,
+		RanAt:       now,
+		Warned:      []*models.LifecycleCandidate{},
+		Deactivated: []*models.LifecycleCandidate{},
+		Purged:      []*models.LifecycleCandidate{},
+		Held:        []*models.LifecycleCandidate{},
+	}
+
+	minDays := 0
+	for _, days := range []int{s.policy.WarnAfterDays, s.policy.DeactivateAfterDays, s.policy.PurgeAfterDays} {
+		if days > 0 && (minDays == 0 || days < minDays) {
+			minDays = days
+		}
+	}
+	if minDays == 0 {
+		return report, nil
+	}
+
+	users, err := s.userRepo.ListInactiveSince(now.AddDate(0, 0, -minDays))
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list inactive users")
+	}
+
+	for _, user := range users {
+		if s.isExcluded(user) {
+			report.Excluded++
+			continue
+		}
+
+		lastActivity := user.LastActivityAt()
+		candidate := &models.LifecycleCandidate{
+			UserID:         user.ID,
+			Username:       user.Username,
+			Email:          user.Email,
+			LastActivityAt: lastActivity,
+			InactiveDays:   int(now.Sub(lastActivity).Hours() / 24),
+		}
+
+		switch {
+		case s.policy.PurgeAfterDays > 0 && candidate.InactiveDays >= s.policy.PurgeAfterDays && !user.IsActive &&
+			since(user.DeactivatedAt, lastActivity, now, s.policy.PurgeAfterDays-s.policy.DeactivateAfterDays):
+			candidate.Action = models.LifecycleActionPurge
+			held, err := s.legalHoldRepo.IsHeld(user.ID)
+			if err != nil {
+				candidate.Error = errorString(err)
+				report.Purged = append(report.Purged, candidate)
+				continue
+			}
+			if held {
+				// A dry run attempts nothing, so only real runs are audited
+				if !dryRun {
+					s.legalHold.blocked(user.ID, legalHoldActionPurgeAccount, "", models.ClientInfo{})
+				}
+				report.Held = append(report.Held, candidate)
+				continue
+			}
+			if !dryRun {
+				candidate.Error = errorString(s.userRepo.DeleteCascade(&models.UserDeletion{
+					UserID:      user.ID,
+					Reason:      "lifecycle",
+					PostsPolicy: s.policy.DeletedUserPosts,
+				}, models.ClientInfo{}))
+			}
+			report.Purged = append(report.Purged, candidate)
+
+		case s.policy.DeactivateAfterDays > 0 && candidate.InactiveDays >= s.policy.DeactivateAfterDays && user.IsActive &&
+			since(user.InactivityWarnedAt, lastActivity, now, s.policy.DeactivateAfterDays-s.policy.WarnAfterDays):
+			candidate.Action = models.LifecycleActionDeactivate
+			if !dryRun {
+				candidate.Error = errorString(s.userRepo.Deactivate(user.ID))
+			}
+			report.Deactivated = append(report.Deactivated, candidate)
+
+		case s.policy.WarnAfterDays > 0 && candidate.InactiveDays >= s.policy.WarnAfterDays && user.IsActive &&
+			(user.InactivityWarnedAt == nil || user.InactivityWarnedAt.Before(lastActivity)):
+			// Warn once per period of inactivity; new activity makes the user eligible again
+			candidate.Action = models.LifecycleActionWarn
+			if !dryRun {
+				candidate.Error = errorString(s.warn(user, candidate.InactiveDays, now))
+			}
+			report.Warned = append(report.Warned, candidate)
+		}
+	}
+
+	return report, nil
+}
+
+// since reports whether the previous stage happened at, during the inactivity that began at
+// lastActivity, at least days before now
+func since(at *time.Time, lastActivity, now time.Time, days int) bool {
+	return at != nil && !at.Before(lastActivity) && !now.Before(at.AddDate(0, 0, max(days, 0)))
+}
+
+// warn emails an inactivity warning to the user and records it. Deactivation follows the
+// warning after the days between the two stages.
+func (s *lifecycleService) warn(user *models.User, inactiveDays int, now time.Time) error {
+	deactivateInDays := 0
+	if s.policy.DeactivateAfterDays > 0 {
+		deactivateInDays = max(s.policy.DeactivateAfterDays-s.policy.WarnAfterDays, s.policy.DeactivateAfterDays-inactiveDays, 0)
+	}
+
+	msg, err := mailer.Render("account_inactive_warning", user.Email, map[string]interface{}{
+		"Username":         user.Username,
+		"InactiveDays":     inactiveDays,
+		"DeactivateInDays": deactivateInDays,
+		"LoginURL":         s.policy.BaseURL,
+	})
+	if err != nil {
+		return errors.WrapError(err, "Failed to render email")
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return errors.WrapError(err, "Failed to send email")
+	}
+
+	return s.userRepo.MarkInactivityWarned(user.ID, now)
+}
+
+// isExcluded checks if a user is exempt from the stale account policies
+func (s *lifecycleService) isExcluded(user *models.User) bool {
+	if user.IsAdmin() {
+		return true
+	}
+	if _, ok := s.excluded[strings.ToLower(user.Username)]; ok {
+		return true
+	}
+	_, ok := s.excluded[strings.ToLower(user.Email)]
+	return ok
+}
+
+// errorString returns the message of err, or an empty string if err is nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,79 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+// inactiveUsers lists its users as inactive; other UserRepository methods are not used
+type inactiveUsers struct {
+	models.UserRepository
+	users []*models.User
+}
+
+func (r *inactiveUsers) ListInactiveSince(cutoff time.Time) ([]*models.User, error) {
+	return r.users, nil
+}
+
+// noLegalHolds holds no account
+type noLegalHolds struct{ models.LegalHoldRepository }
+
+func (noLegalHolds) IsHeld(userID uuid.UUID) (bool, error) { return false, nil }
+
+func TestLifecycleStagesOnlyFollowTheirPredecessor(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	daysAgo := func(days int) *time.Time {
+		at := now.AddDate(0, 0, -days)
+		return &at
+	}
+
+	tests := []struct {
+		name                string
+		active              bool
+		lastSeen            *time.Time
+		warned, deactivated *time.Time
+		want                string // Empty when nothing is done
+	}{
+		// Accounts from before activity was tracked look inactive since they were created
+		{"never warned", true, nil, nil, nil, models.LifecycleActionWarn},
+		{"warned recently", true, daysAgo(200), daysAgo(10), nil, ""},
+		{"warned long enough ago", true, daysAgo(200), daysAgo(30), nil, models.LifecycleActionDeactivate},
+		{"warned before the last activity", true, daysAgo(70), daysAgo(100), nil, models.LifecycleActionWarn},
+		{"deactivated recently", false, daysAgo(200), daysAgo(60), daysAgo(10), ""},
+		{"deactivated long enough ago", false, daysAgo(200), daysAgo(100), daysAgo(30), models.LifecycleActionPurge},
+		{"inactive without deactivation", false, daysAgo(200), nil, nil, ""},
+	}
+
+	users := &inactiveUsers{}
+	for _, tt := range tests {
+		users.users = append(users.users, &models.User{
+			ID: uuid.New(), Username: tt.name, Role: models.RoleUser, IsActive: tt.active,
+			LastSeenAt: tt.lastSeen, InactivityWarnedAt: tt.warned, DeactivatedAt: tt.deactivated,
+			CreatedAt: now.AddDate(-2, 0, 0),
+		})
+	}
+	svc := NewLifecycleService(users, noLegalHolds{}, nil, nil, LifecyclePolicy{
+		WarnAfterDays: 30, DeactivateAfterDays: 60, PurgeAfterDays: 90, Clock: clock.NewManual(now),
+	})
+	report, err := svc.Run(true)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	actions := map[string]string{}
+	for _, candidates := range [][]*models.LifecycleCandidate{report.Warned, report.Deactivated, report.Purged} {
+		for _, candidate := range candidates {
+			actions[candidate.Username] = candidate.Action
+		}
+	}
+	for _, tt := range tests {
+		if got := actions[tt.name]; got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// loginStatsService implements LoginStatsService interface
+type loginStatsService struct {
+	loginStatsRepo models.LoginStatsRepository
+}
+
+// NewLoginStatsService creates a new login stats service
+func NewLoginStatsService(loginStatsRepo models.LoginStatsRepository) models.LoginStatsService {
+	return &loginStatsService{loginStatsRepo: loginStatsRepo}
+}
+
+// Rollup aggregates new login audit logs into the hourly statistics
+func (s *loginStatsService) Rollup() error {
+	if _, err := s.loginStatsRepo.Rollup(); err != nil {
+		return errors.WrapError(err, "Failed to roll up login stats")
+	}
+	return nil
+}
+
+// Report summarizes sign-in attempts over the last hours, including the current hour,
+// with the limit IPs and accounts that saw the most failures
+func (s *loginStatsService) Report(hours, limit int) (*models.LoginStatsReport, error) {
+	// Roll up first so the report includes attempts since the last scheduled run
+	if err := s.Rollup(); err != nil {
+		return nil, err
+	}
+
+	to := time.Now().Truncate(time.Hour).Add(time.Hour)
+	from := to.Add(-time.Duration(hours) * time.Hour)
+
+	hourly, err := s.loginStatsRepo.Hourly(from, to)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get hourly login stats")
+	}
+
+	topIPs, err := s.loginStatsRepo.TopIPs(from, to, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get login stats by IP")
+	}
+
+	topUsers, err := s.loginStatsRepo.TopUsers(from, to, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get login stats by user")
+	}
+
+	report := &models.LoginStatsReport{
+		From:     from,
+		To:       to,
+		Hourly:   hourly,
+		TopIPs:   topIPs,
+		TopUsers: topUsers,
+	}
+	for _, hour := range hourly {
+		report.Successes += hour.Successes
+		report.Failures += hour.Failures
+	}
+
+	return report, nil
+}
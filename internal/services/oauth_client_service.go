@@ -0,0 +1,158 @@
+package services
+
+import (
+	"crypto/subtle"
+	"log"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// oauthClientIDPrefix marks public client IDs
+const oauthClientIDPrefix = "client_"
+
+// oauthClientService implements OAuthClientService interface
+type oauthClientService struct {
+	clientRepo models.OAuthClientRepository
+	jwtMgr     *auth.JWTManager
+	validator  *validation.Validator
+}
+
+// NewOAuthClientService creates a new OAuth client service
+func NewOAuthClientService(clientRepo models.OAuthClientRepository, jwtMgr *auth.JWTManager) models.OAuthClientService {
+	return &oauthClientService{
+		clientRepo: clientRepo,
+		jwtMgr:     jwtMgr,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// CreateClient registers a machine client. The secret is only returned here.
+func (s *oauthClientService) CreateClient(creatorID uuid.UUID, req *models.CreateOAuthClientRequest) (*models.OAuthClient, error) {
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	var scopes []string
+	for _, scope := range req.Scopes {
+		if !models.IsValidScope(scope) {
+			return nil, errors.NewErrorWithCode(400, "Unknown scope: "+scope)
+		}
+		if !models.HasScope(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	id, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate client ID")
+	}
+	secret, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate client secret")
+	}
+
+	client := &models.OAuthClient{
+		ClientID:     oauthClientIDPrefix + id[:24],
+		ClientSecret: secret,
+		SecretHash:   auth.HashToken(secret),
+		Name:         req.Name,
+		Scopes:       scopes,
+		CreatedBy:    &creatorID,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := s.clientRepo.Create(client); err != nil {
+		return nil, errors.WrapError(err, "Failed to create OAuth client")
+	}
+
+	return client, nil
+}
+
+// ListClients lists all registered machine clients
+func (s *oauthClientService) ListClients() ([]*models.OAuthClient, error) {
+	clients, err := s.clientRepo.List()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list OAuth clients")
+	}
+
+	return clients, nil
+}
+
+// RevokeClient revokes a machine client. Tokens already issued are rejected from then on by
+// TokenDenylistMiddleware.
+func (s *oauthClientService) RevokeClient(id uuid.UUID) error {
+	client, err := s.clientRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrClientNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get OAuth client")
+	}
+	if client.RevokedAt != nil {
+		return nil
+	}
+
+	if err := s.clientRepo.Revoke(id); err != nil {
+		return writeError(err, errors.ErrClientNotFound, "Failed to revoke OAuth client")
+	}
+
+	return nil
+}
+
+// IssueToken exchanges client credentials for a scoped access token
+func (s *oauthClientService) IssueToken(req *models.ClientTokenRequest) (*models.ClientTokenResponse, error) {
+	if req.GrantType != models.GrantTypeClientCredentials {
+		return nil, errors.ErrUnsupportedGrantType
+	}
+	if req.ClientID == "" || req.ClientSecret == "" {
+		return nil, errors.ErrInvalidClient
+	}
+
+	client, err := s.clientRepo.GetByClientID(req.ClientID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidClient
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get OAuth client")
+	}
+
+	secretHash := auth.HashToken(req.ClientSecret)
+	if client.RevokedAt != nil || subtle.ConstantTimeCompare([]byte(secretHash), []byte(client.SecretHash)) != 1 {
+		return nil, errors.ErrInvalidClient
+	}
+
+	// Clients may narrow their token to a subset of their registered scopes
+	scopes := client.Scopes
+	if requested := strings.Fields(req.Scope); len(requested) > 0 {
+		for _, scope := range requested {
+			if !models.HasScope(client.Scopes, scope) {
+				return nil, errors.ErrInvalidScope
+			}
+		}
+		scopes = requested
+	}
+
+	accessToken, err := s.jwtMgr.GenerateClientToken(client.ClientID, scopes)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate access token")
+	}
+
+	if err := s.clientRepo.TouchLastUsed(client.ID, time.Now()); err != nil {
+		log.Printf("Failed to record OAuth client use: %v", err)
+	}
+
+	return &models.ClientTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(s.jwtMgr.GetAccessDuration().Seconds()),
+		Scope:       strings.Join(scopes, " "),
+	}, nil
+}
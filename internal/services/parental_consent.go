@@ -0,0 +1,128 @@
+package services
+
+import (
+	"net/url"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/normalize"
+
+	"github.com/google/uuid"
+)
+
+// birthdateLayout is the format of birthdates in requests
+const birthdateLayout = "2006-01-02"
+
+// parentalConsentDetails is the audit log detail payload of a confirmed parental consent
+type parentalConsentDetails struct {
+	ConsentID uuid.UUID `json:"consent_id"`
+}
+
+// checkAge parses the birthdate of a registration and enforces the configured age gate,
+// returning nil when no birthdate was given
+func (s *userService) checkAge(req *models.CreateUserRequest) (*time.Time, error) {
+	if req.Birthdate == "" {
+		if s.cfg.RequireBirthdate {
+			return nil, errors.ErrBirthdateRequired
+		}
+		return nil, nil
+	}
+
+	// The format was validated with the request
+	birthdate, err := time.Parse(birthdateLayout, req.Birthdate)
+	if err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+	now := s.cfg.Clock.Now()
+	if birthdate.After(now) {
+		return nil, errors.NewAppErrorWithDetails(400, "Validation failed", "Birthdate cannot be in the future", nil)
+	}
+	if s.cfg.MinimumAge > 0 && models.AgeOn(birthdate, now) < s.cfg.MinimumAge {
+		return nil, errors.ErrBelowMinimumAge
+	}
+	if req.ParentEmail == req.Email && models.AgeOn(birthdate, now) < s.cfg.ParentalConsentAge {
+		return nil, errors.NewAppErrorWithDetails(400, "Validation failed", "The parent email must differ from the account email", nil)
+	}
+
+	return &birthdate, nil
+}
+
+// RequestParentalConsent sends a consent link to the parent of a user who needs parental
+// consent, e.g. when no parent email was given at registration or the link expired
+func (s *userService) RequestParentalConsent(id uuid.UUID, req *models.ParentalConsentRequest) error {
+	req.ParentEmail = normalize.Email(req.ParentEmail)
+	if err := s.validator.Validate(req); err != nil {
+		return errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get user")
+	}
+	if !user.NeedsParentalConsent(s.cfg.ParentalConsentAge, s.cfg.Clock.Now()) {
+		return errors.NewAppErrorWithDetails(409, "Parental consent is not required", "This account is not restricted", nil)
+	}
+	if req.ParentEmail == user.Email {
+		return errors.NewAppErrorWithDetails(400, "Validation failed", "The parent email must differ from the account email", nil)
+	}
+
+	return s.requestParentalConsent(user, req.ParentEmail)
+}
+
+// requestParentalConsent creates a parental consent request and mails its link to the parent
+func (s *userService) requestParentalConsent(user *models.User, parentEmail string) error {
+	now := s.cfg.Clock.Now()
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return errors.WrapError(err, "Failed to generate parental consent token")
+	}
+
+	consent := &models.ParentalConsent{
+		UserID:      user.ID,
+		ParentEmail: parentEmail,
+		TokenHash:   auth.HashToken(token),
+		ExpiresAt:   now.Add(s.cfg.ParentalConsentTTL),
+		CreatedAt:   now,
+	}
+	if err := s.parentalConsentRepo.Create(consent); err != nil {
+		return errors.WrapError(err, "Failed to create parental consent request")
+	}
+
+	return s.sendTemplate("parental_consent_request", parentEmail, map[string]interface{}{
+		"Username":   user.Username,
+		"Email":      user.Email,
+		"ConsentURL": s.cfg.BaseURL + "/api/v1/auth/parental-consent/confirm?token=" + url.QueryEscape(token),
+		"ExpiresIn":  s.cfg.ParentalConsentTTL.String(),
+	})
+}
+
+// ConfirmParentalConsent records a parent's consent using the token sent to them, lifting
+// the restrictions of the account
+func (s *userService) ConfirmParentalConsent(token string, client models.ClientInfo) error {
+	consent, err := s.parentalConsentRepo.GetByTokenHash(auth.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrInvalidConsentToken
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get parental consent request")
+	}
+	if consent.ConfirmedAt != nil || s.cfg.Clock.Now().After(consent.ExpiresAt) {
+		return errors.ErrInvalidConsentToken
+	}
+
+	if err := s.parentalConsentRepo.Confirm(consent.ID, s.cfg.Clock.Now()); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			return errors.ErrInvalidConsentToken
+		}
+		return errors.WrapError(err, "Failed to confirm parental consent")
+	}
+
+	s.audit.record(&consent.UserID, models.AuditActionParentalConsent, client, &parentalConsentDetails{ConsentID: consent.ID})
+	return nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/storage"
+)
+
+// DefaultPartitionMonthsAhead is how many months ahead partitions are created by default
+const DefaultPartitionMonthsAhead = 3
+
+// PartitionPolicy configures partition maintenance
+type PartitionPolicy struct {
+	// MonthsAhead is how many months after the current one get partitions ahead of time.
+	// Refresh tokens are partitioned by expiry, so it must cover the refresh token lifetime.
+	MonthsAhead int
+	// Retention is how long after the end of its month a partition of each table is kept;
+	// tables without a positive retention are never dropped
+	Retention map[string]time.Duration
+	// Archive, if set, stores a gzipped export of each partition of the Archived tables
+	// before it is dropped; partitions that cannot be archived are kept
+	Archive  storage.Storage
+	Archived map[string]bool
+}
+
+// partitionService implements PartitionService interface
+type partitionService struct {
+	partitionRepo models.PartitionRepository
+	audit         *auditRecorder
+	policy        PartitionPolicy
+}
+
+// NewPartitionService creates a new partition maintenance service
+func NewPartitionService(partitionRepo models.PartitionRepository, auditLogRepo models.AuditLogRepository, policy PartitionPolicy) models.PartitionService {
+	if policy.MonthsAhead <= 0 {
+		policy.MonthsAhead = DefaultPartitionMonthsAhead
+	}
+	return &partitionService{partitionRepo: partitionRepo, audit: newAuditRecorder(auditLogRepo, nil), policy: policy}
+}
+
+// Maintain creates the partitions of the current and coming months of every partitioned
+// table, then archives and drops the partitions whose rows are all past retention. Dropping
+// a partition removes its rows without the table scans and dead tuples of a DELETE.
+// Restored partitions are kept until released, and partitions holding rows of users under
+// legal hold until the holds are cleared.
+func (s *partitionService) Maintain() (*models.PartitionMaintenanceReport, error) {
+	now := time.Now().UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	report := &models.PartitionMaintenanceReport{}
+
+	for _, table := range models.PartitionedTables {
+		for ahead := 0; ahead <= s.policy.MonthsAhead; ahead++ {
+			name, err := s.partitionRepo.Create(table, month.AddDate(0, ahead, 0))
+			if err != nil {
+				return report, errors.WrapError(err, "Failed to create partitions")
+			}
+			report.Created = append(report.Created, name)
+		}
+
+		retention := s.policy.Retention[table]
+		if retention <= 0 {
+			continue
+		}
+		partitions, err := s.partitionRepo.List(table)
+		if err != nil {
+			return report, errors.WrapError(err, "Failed to list partitions")
+		}
+		for _, partition := range partitions {
+			if partition.To.Add(retention).After(now) {
+				continue
+			}
+			if partition.Restored {
+				log.Printf("Keeping restored partition %s past its retention; release it to drop it", partition.Name)
+				continue
+			}
+			held, err := s.partitionRepo.HeldUserIDs(partition)
+			if err != nil {
+				return report, errors.WrapError(err, "Failed to check legal holds")
+			}
+			if len(held) > 0 {
+				for _, userID := range held {
+					s.audit.record(&userID, models.AuditActionLegalHoldBlocked, models.ClientInfo{}, &legalHoldBlockedDetails{
+						Action: legalHoldActionDropPartition,
+						Target: partition.Name,
+					})
+				}
+				log.Printf("Keeping partition %s past its retention: it holds rows of %d users under legal hold", partition.Name, len(held))
+				report.Held = append(report.Held, partition.Name)
+				continue
+			}
+			if s.policy.Archive != nil && s.policy.Archived[table] {
+				rows, err := s.archive(partition)
+				if err != nil {
+					return report, err
+				}
+				log.Printf("Archived %d rows of partition %s to %s and dropped it", rows, partition.Name, s.policy.Archive.Name())
+				report.Archived = append(report.Archived, partition.Name)
+				report.Dropped = append(report.Dropped, partition.Name)
+				continue
+			}
+			if err := s.partitionRepo.Drop(partition); err != nil {
+				return report, errors.WrapError(err, "Failed to drop partition")
+			}
+			log.Printf("Dropped partition %s past its retention of %s", partition.Name, retention)
+			report.Dropped = append(report.Dropped, partition.Name)
+		}
+	}
+
+	return report, nil
+}
+
+// archiveKey is the storage key of the archive of a partition
+func archiveKey(table, partition string) string {
+	return table + "/" + partition + ".jsonl.gz"
+}
+
+// archive exports a partition to a gzipped temporary file, stores it and drops the partition
+func (s *partitionService) archive(partition *models.Partition) (int, error) {
+	file, err := os.CreateTemp("", partition.Name+"-*.jsonl.gz")
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to create archive file")
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	compressed := gzip.NewWriter(file)
+	rows, err := s.partitionRepo.Archive(partition, compressed, func() error {
+		if err := compressed.Close(); err != nil {
+			return err
+		}
+		size, err := file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		return s.policy.Archive.Put(archiveKey(partition.Table, partition.Name), file, size)
+	})
+	if err != nil {
+		return rows, errors.WrapError(err, "Failed to archive partition")
+	}
+	return rows, nil
+}
+
+// Restore recreates the partition of table holding month from its archive. The partition
+// is kept past its retention until released.
+func (s *partitionService) Restore(table string, month time.Time) (int, error) {
+	if s.policy.Archive == nil {
+		return 0, fmt.Errorf("no archive storage is configured")
+	}
+	name := table + "_" + month.Format("2006_01")
+	archive, err := s.policy.Archive.Get(archiveKey(table, name))
+	if err != nil {
+		return 0, fmt.Errorf("failed to open archive of %s: %w", name, err)
+	}
+	defer archive.Close()
+
+	decompressed, err := gzip.NewReader(archive)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read archive of %s: %w", name, err)
+	}
+	rows, err := s.partitionRepo.Restore(table, month, decompressed)
+	if err != nil {
+		return rows, errors.WrapError(err, "Failed to restore partition")
+	}
+	return rows, nil
+}
+
+// Release lets retention archive and drop a restored partition again
+func (s *partitionService) Release(table string, month time.Time) error {
+	if err := s.partitionRepo.Release(table, month); err != nil {
+		return errors.WrapError(err, "Failed to release partition")
+	}
+	return nil
+}
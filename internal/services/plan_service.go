@@ -0,0 +1,130 @@
+package services
+
+import (
+	"log"
+
+	"go-backend-api/internal/events"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// StoredPlanResolver resolves users to the plan stored on them, which admins and billing
+// webhooks update
+type StoredPlanResolver struct{}
+
+// ResolvePlan returns the plan stored on user
+func (StoredPlanResolver) ResolvePlan(user *models.User) (string, error) {
+	return user.Plan, nil
+}
+
+// planService implements PlanService interface
+type planService struct {
+	userRepo  models.UserRepository
+	resolver  models.PlanResolver
+	limits    map[string]*models.PlanLimits
+	events    events.Publisher
+	validator *validation.Validator
+}
+
+// NewPlanService creates a new plan service granting the limits of each plan. Plans are
+// resolved by resolver, or read from the users when it is nil. Plan changes are published
+// to publisher (nil discards them).
+func NewPlanService(userRepo models.UserRepository, resolver models.PlanResolver, limits map[string]*models.PlanLimits, publisher events.Publisher) models.PlanService {
+	if resolver == nil {
+		resolver = StoredPlanResolver{}
+	}
+	if publisher == nil {
+		publisher = events.Discard
+	}
+	if limits[models.PlanFree] == nil {
+		limits[models.PlanFree] = &models.PlanLimits{Plan: models.PlanFree}
+	}
+	return &planService{
+		userRepo:  userRepo,
+		resolver:  resolver,
+		limits:    limits,
+		events:    publisher,
+		validator: validation.NewValidator(),
+	}
+}
+
+// ResolvePlan tells which plan a user is on. Unknown plans, e.g. from an external billing
+// system, are taken as free so that users are never granted a plan by mistake.
+func (s *planService) ResolvePlan(user *models.User) (string, error) {
+	plan, err := s.resolver.ResolvePlan(user)
+	if err != nil {
+		return "", errors.WrapError(err, "Failed to resolve plan")
+	}
+	if _, ok := s.limits[plan]; !ok {
+		if plan != "" {
+			log.Printf("Unknown plan %q of user %s, using %s", plan, user.ID, models.PlanFree)
+		}
+		return models.PlanFree, nil
+	}
+
+	return plan, nil
+}
+
+// Limits returns the limits of plan, or of the free plan when it is unknown
+func (s *planService) Limits(plan string) *models.PlanLimits {
+	if limits, ok := s.limits[plan]; ok {
+		return limits
+	}
+	return s.limits[models.PlanFree]
+}
+
+// GetUserLimits returns the limits of the plan a user is on
+func (s *planService) GetUserLimits(userID uuid.UUID) (*models.PlanLimits, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	plan, err := s.ResolvePlan(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.Limits(plan), nil
+}
+
+// UpdatePlan moves a user to another plan on behalf of an admin
+func (s *planService) UpdatePlan(userID uuid.UUID, req *models.UpdatePlanRequest) (*models.PlanLimits, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	return s.ChangePlan(userID, req.Plan, models.PlanSourceAdmin)
+}
+
+// ChangePlan moves a user to a known plan, publishing PlanChanged when it differs from the
+// stored one
+func (s *planService) ChangePlan(userID uuid.UUID, plan, source string) (*models.PlanLimits, error) {
+	if !models.IsValidPlan(plan) {
+		return nil, errors.NewInvalidParamError("plan", "must be free or pro")
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+	if user.Plan == plan {
+		return s.Limits(plan), nil
+	}
+
+	if err := s.userRepo.UpdatePlan(userID, plan); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to update plan")
+	}
+
+	s.events.Publish(events.PlanChanged, &events.PlanChange{UserID: userID, From: user.Plan, To: plan, Source: source})
+	return s.Limits(plan), nil
+}
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// policyService implements PolicyService interface
+type policyService struct {
+	policyRepo models.PolicyRepository
+	validator  *validation.Validator
+}
+
+// NewPolicyService creates a new policy service
+func NewPolicyService(policyRepo models.PolicyRepository) models.PolicyService {
+	return &policyService{
+		policyRepo: policyRepo,
+		validator:  validation.NewValidator(),
+	}
+}
+
+// Publish publishes a new version of a policy, which becomes current immediately
+func (s *policyService) Publish(publisherID uuid.UUID, req *models.PublishPolicyRequest) (*models.PolicyDocument, error) {
+	req.Version = strings.TrimSpace(req.Version)
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	doc := &models.PolicyDocument{
+		Kind:        req.Kind,
+		Version:     req.Version,
+		URL:         req.URL,
+		Mandatory:   req.Mandatory == nil || *req.Mandatory,
+		PublishedBy: &publisherID,
+		PublishedAt: time.Now(),
+	}
+	if err := s.policyRepo.Create(doc); err != nil {
+		if errors.Is(err, models.ErrDuplicate) {
+			return nil, errors.NewAppErrorWithDetails(409, "Policy version already published", "Publish the changes under a new version", nil)
+		}
+		return nil, errors.WrapError(err, "Failed to publish policy")
+	}
+
+	return doc, nil
+}
+
+// ListDocuments lists every published policy version, newest first
+func (s *policyService) ListDocuments() ([]*models.PolicyDocument, error) {
+	docs, err := s.policyRepo.List()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	return docs, nil
+}
+
+// ListCurrent lists the current version of each policy
+func (s *policyService) ListCurrent() ([]*models.PolicyDocument, error) {
+	docs, err := s.policyRepo.ListCurrent()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	return docs, nil
+}
+
+// GetStatus gets the current policy versions and which of them a user accepted or must accept
+func (s *policyService) GetStatus(userID uuid.UUID) (*models.PolicyStatus, error) {
+	current, err := s.policyRepo.ListCurrent()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	pending, err := s.policyRepo.ListPending(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list pending policies")
+	}
+	accepted, err := s.policyRepo.ListAcceptances(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policy acceptances")
+	}
+
+	return &models.PolicyStatus{Current: current, Pending: pending, Accepted: accepted}, nil
+}
+
+// Accept records a user accepting current policy versions
+func (s *policyService) Accept(userID uuid.UUID, req *models.AcceptPoliciesRequest) (*models.PolicyStatus, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	current, err := s.policyRepo.ListCurrent()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	ids, err := acceptedPolicies(current, req.AcceptedPolicies, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.policyRepo.Accept(userID, ids, time.Now()); err != nil {
+		return nil, errors.WrapError(err, "Failed to record policy acceptance")
+	}
+
+	return s.GetStatus(userID)
+}
+
+// acceptedPolicies resolves the versions accepted by kind to current policy documents.
+// Versions that are not current are rejected, so that nobody accepts a version they were
+// not shown; with requireAll, every current document must be accepted.
+func acceptedPolicies(current []*models.PolicyDocument, accepted map[string]string, requireAll bool) ([]uuid.UUID, error) {
+	currentByKind := make(map[string]*models.PolicyDocument, len(current))
+	for _, doc := range current {
+		currentByKind[doc.Kind] = doc
+	}
+
+	var ids []uuid.UUID
+	for kind, version := range accepted {
+		doc, ok := currentByKind[kind]
+		if !ok {
+			return nil, errors.ErrPolicyVersionNotCurrent.WithDetails(fmt.Sprintf("No %s policy is published", kind))
+		}
+		if version != doc.Version {
+			return nil, errors.ErrPolicyVersionNotCurrent.WithDetails(fmt.Sprintf("The current %s version is %s", kind, doc.Version))
+		}
+		ids = append(ids, doc.ID)
+	}
+
+	if requireAll {
+		var missing []string
+		for _, doc := range current {
+			if _, ok := accepted[doc.Kind]; !ok {
+				missing = append(missing, doc.Kind+" "+doc.Version)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return nil, errors.ErrPolicyAcceptanceRequired.WithDetails("Accept " + strings.Join(missing, ", ") + " in accepted_policies")
+		}
+	}
+
+	return ids, nil
+}
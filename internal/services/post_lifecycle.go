@@ -0,0 +1,129 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/events"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// postAction is an author's request to move a post through its lifecycle
+type postAction string
+
+// Post lifecycle actions
+const (
+	postActionPublish    postAction = "publish"
+	postActionSchedule   postAction = "schedule"
+	postActionUnschedule postAction = "unschedule"
+	postActionUnpublish  postAction = "unpublish"
+	postActionArchive    postAction = "archive"
+	postActionUnarchive  postAction = "unarchive"
+)
+
+// postTransition is the state an action moves a post to, and the states it may start from
+type postTransition struct {
+	from []models.PostState
+	to   models.PostState
+}
+
+// postTransitions is the post lifecycle:
+//
+//	draft ⇄ scheduled → published → archived → draft
+//
+// Drafts and scheduled posts may be archived and published right away, a schedule can be moved
+// or cancelled, and published posts can be taken back to drafts. Archived posts only leave the
+// archive as drafts.
+var postTransitions = map[postAction]postTransition{
+	postActionPublish:    {from: []models.PostState{models.PostStateDraft, models.PostStateScheduled}, to: models.PostStatePublished},
+	postActionSchedule:   {from: []models.PostState{models.PostStateDraft, models.PostStateScheduled}, to: models.PostStateScheduled},
+	postActionUnschedule: {from: []models.PostState{models.PostStateScheduled}, to: models.PostStateDraft},
+	postActionUnpublish:  {from: []models.PostState{models.PostStatePublished, models.PostStateScheduled}, to: models.PostStateDraft},
+	postActionArchive:    {from: []models.PostState{models.PostStateDraft, models.PostStateScheduled, models.PostStatePublished}, to: models.PostStateArchived},
+	postActionUnarchive:  {from: []models.PostState{models.PostStateArchived}, to: models.PostStateDraft},
+}
+
+// checkTransition verifies that the lifecycle allows an action on a post in the state from,
+// returning the error code telling the author why it does not
+func checkTransition(action postAction, from models.PostState) error {
+	for _, state := range postTransitions[action].from {
+		if state == from {
+			return nil
+		}
+	}
+
+	switch {
+	case action == postActionUnarchive:
+		return errors.ErrPostNotArchived
+	case from == models.PostStateArchived:
+		return errors.ErrPostArchived
+	case action == postActionPublish:
+		return errors.ErrPostAlreadyPublished
+	case action == postActionSchedule:
+		return errors.ErrPostPublished
+	case action == postActionUnschedule && from == models.PostStatePublished:
+		return errors.ErrPostAlreadyPublished
+	default:
+		return errors.ErrPostNotPublished
+	}
+}
+
+// publishedAction returns the lifecycle action that gives a post the published state requested
+// by an update, and false when the post is in that state already
+func publishedAction(post *models.Post, isPublished *bool) (postAction, bool) {
+	switch {
+	case isPublished == nil:
+		return "", false
+	case *isPublished && !post.IsPublished:
+		return postActionPublish, true
+	case !*isPublished && (post.IsPublished || post.IsScheduled()):
+		// Unpublishing also cancels a schedule
+		return postActionUnpublish, true
+	default:
+		return "", false
+	}
+}
+
+// transitionPost applies a lifecycle action to a post in memory, setting the fields of the state
+// it moves to; scheduledAt is the time a scheduled post goes live. Publishing and scheduling are
+// refused to authors awaiting parental consent. The caller saves the post.
+func (s *postService) transitionPost(post *models.Post, action postAction, scheduledAt *time.Time) error {
+	if err := checkTransition(action, post.State()); err != nil {
+		return err
+	}
+	if action == postActionPublish || action == postActionSchedule {
+		if err := s.checkCanPublish(post.AuthorID); err != nil {
+			return err
+		}
+	}
+
+	now := s.cfg.Clock.Now()
+	switch postTransitions[action].to {
+	case models.PostStateDraft:
+		post.IsPublished = false
+		post.ScheduledAt = nil
+		post.ArchivedAt = nil
+	case models.PostStateScheduled:
+		post.ScheduledAt = scheduledAt
+	case models.PostStatePublished:
+		post.IsPublished = true
+		post.ScheduledAt = nil
+		post.PublishedAt = &now
+	case models.PostStateArchived:
+		post.IsPublished = false
+		post.ScheduledAt = nil
+		post.ArchivedAt = &now
+	}
+	post.UpdatedAt = now
+
+	return nil
+}
+
+// postPublished announces a post that just went live
+func (s *postService) postPublished(post *models.Post) {
+	s.cfg.Events.Publish(events.PostPublished, &events.PostPublication{
+		PostID:   post.ID,
+		AuthorID: post.AuthorID,
+		Title:    post.Title,
+	})
+}
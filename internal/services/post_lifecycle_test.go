@@ -0,0 +1,126 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// postIn returns a post of author in state
+func postIn(state models.PostState, author uuid.UUID, at time.Time) *models.Post {
+	post := &models.Post{ID: uuid.New(), AuthorID: author}
+	switch state {
+	case models.PostStateScheduled:
+		scheduledAt := at.Add(time.Hour)
+		post.ScheduledAt = &scheduledAt
+	case models.PostStatePublished:
+		post.IsPublished = true
+		post.PublishedAt = &at
+	case models.PostStateArchived:
+		post.ArchivedAt = &at
+	}
+	return post
+}
+
+func TestCheckTransition(t *testing.T) {
+	draft, scheduled, published, archived := models.PostStateDraft, models.PostStateScheduled, models.PostStatePublished, models.PostStateArchived
+	tests := []struct {
+		action postAction
+		from   models.PostState
+		want   error
+	}{
+		{postActionPublish, draft, nil},
+		{postActionPublish, scheduled, nil},
+		{postActionPublish, published, errors.ErrPostAlreadyPublished},
+		{postActionPublish, archived, errors.ErrPostArchived},
+		{postActionSchedule, draft, nil},
+		{postActionSchedule, scheduled, nil},
+		{postActionSchedule, published, errors.ErrPostPublished},
+		{postActionSchedule, archived, errors.ErrPostArchived},
+		{postActionUnschedule, draft, errors.ErrPostNotPublished},
+		{postActionUnschedule, scheduled, nil},
+		{postActionUnschedule, published, errors.ErrPostAlreadyPublished},
+		{postActionUnschedule, archived, errors.ErrPostArchived},
+		{postActionUnpublish, draft, errors.ErrPostNotPublished},
+		{postActionUnpublish, scheduled, nil},
+		{postActionUnpublish, published, nil},
+		{postActionUnpublish, archived, errors.ErrPostArchived},
+		{postActionArchive, draft, nil},
+		{postActionArchive, scheduled, nil},
+		{postActionArchive, published, nil},
+		{postActionArchive, archived, errors.ErrPostArchived},
+		{postActionUnarchive, draft, errors.ErrPostNotArchived},
+		{postActionUnarchive, scheduled, errors.ErrPostNotArchived},
+		{postActionUnarchive, published, errors.ErrPostNotArchived},
+		{postActionUnarchive, archived, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.action)+" "+string(tt.from), func(t *testing.T) {
+			if err := checkTransition(tt.action, tt.from); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransitionPostMovesItToTheTargetState(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	author := &models.User{ID: uuid.New()}
+	s := &postService{userRepo: newFakeUserRepo(nil, author), cfg: PostServiceConfig{Clock: clock.NewManual(now)}}
+	scheduledAt := now.Add(24 * time.Hour)
+
+	for action, transition := range postTransitions {
+		for _, from := range transition.from {
+			t.Run(string(action)+" "+string(from), func(t *testing.T) {
+				post := postIn(from, author.ID, now.Add(-time.Hour))
+				if err := s.transitionPost(post, action, &scheduledAt); err != nil {
+					t.Fatalf("transitionPost: %v", err)
+				}
+				if got := post.State(); got != transition.to {
+					t.Errorf("post is %s, want %s", got, transition.to)
+				}
+				if !post.UpdatedAt.Equal(now) {
+					t.Errorf("updated at %s, want %s", post.UpdatedAt, now)
+				}
+				switch transition.to {
+				case models.PostStatePublished:
+					if post.PublishedAt == nil || !post.PublishedAt.Equal(now) {
+						t.Errorf("published at %v, want %s", post.PublishedAt, now)
+					}
+				case models.PostStateScheduled:
+					if post.ScheduledAt == nil || !post.ScheduledAt.Equal(scheduledAt) {
+						t.Errorf("scheduled at %v, want %s", post.ScheduledAt, scheduledAt)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestAuthorsAwaitingParentalConsentCannotPublish(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	birthdate := now.AddDate(-12, 0, 0)
+	child := &models.User{ID: uuid.New(), Birthdate: &birthdate}
+	s := &postService{userRepo: newFakeUserRepo(nil, child), cfg: PostServiceConfig{ParentalConsentAge: 13, Clock: clock.NewManual(now)}}
+	scheduledAt := now.Add(time.Hour)
+
+	for _, action := range []postAction{postActionPublish, postActionSchedule} {
+		post := postIn(models.PostStateDraft, child.ID, now)
+		if err := s.transitionPost(post, action, &scheduledAt); !errors.Is(err, errors.ErrParentalConsentRequired) {
+			t.Errorf("%s: got %v, want ErrParentalConsentRequired", action, err)
+		}
+		if post.State() != models.PostStateDraft {
+			t.Errorf("%s: refused post is %s, want it left a draft", action, post.State())
+		}
+	}
+	// Taking posts down needs no consent
+	post := postIn(models.PostStateDraft, child.ID, now)
+	if err := s.transitionPost(post, postActionArchive, nil); err != nil {
+		t.Errorf("archive: %v", err)
+	}
+}
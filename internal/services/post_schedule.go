@@ -0,0 +1,116 @@
+package services
+
+import (
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/localtime"
+
+	"github.com/google/uuid"
+)
+
+// Calendar ranges of the post schedule
+const (
+	// defaultScheduleDays is how many days after from the schedule lists without a to
+	defaultScheduleDays = 30
+	// maxScheduleRange is the longest range the schedule lists, a year with room for a leap
+	// day and a daylight saving change
+	maxScheduleRange = 367 * 24 * time.Hour
+)
+
+// GetSchedule lists the author's scheduled posts between from and to. Dates are calendar
+// days in the author's timezone: from starts at the beginning of its day and to ends at the
+// end of its day, however long daylight saving changes make those days. Without from the
+// schedule starts today, and without to it spans defaultScheduleDays.
+func (s *postService) GetSchedule(authorID uuid.UUID, from, to string) (*models.PostSchedule, error) {
+	loc := s.authorLocation(authorID)
+
+	start := localtime.StartOfDay(s.cfg.Clock.Now().In(loc), loc)
+	if from != "" {
+		var err error
+		if start, err = scheduleBound(from, loc, false); err != nil {
+			return nil, err
+		}
+	}
+	end := localtime.StartOfDay(start.In(loc).AddDate(0, 0, defaultScheduleDays), loc)
+	if to != "" {
+		var err error
+		if end, err = scheduleBound(to, loc, true); err != nil {
+			return nil, err
+		}
+	}
+	if !end.After(start) || end.Sub(start) > maxScheduleRange {
+		return nil, errors.ErrScheduleRangeInvalid
+	}
+
+	posts, err := s.postRepo.GetScheduled(authorID, start, end)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get scheduled posts")
+	}
+
+	return &models.PostSchedule{Location: loc, From: start.In(loc), To: end.In(loc), Posts: posts}, nil
+}
+
+// scheduleBound parses a bound of the schedule range: an RFC 3339 time as is, or a date in
+// loc, which starts the range at its first instant or, as its end, ends it after its last
+func scheduleBound(value string, loc *time.Location, end bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	date, err := localtime.ParseDate(value)
+	if err != nil {
+		return time.Time{}, errors.ErrScheduleRangeInvalid
+	}
+	if end {
+		date = date.AddDate(0, 0, 1)
+	}
+	return localtime.StartOfDay(date, loc), nil
+}
+
+// PublishScheduledPosts publishes the posts whose scheduled time has come. It runs as a
+// background job, so posts go live within an interval of their scheduled time.
+func (s *postService) PublishScheduledPosts() error {
+	published, err := s.postRepo.PublishScheduled(s.cfg.Clock.Now())
+	if err != nil {
+		return errors.WrapError(err, "Failed to publish scheduled posts")
+	}
+	for _, post := range published {
+		s.postChanged(post)
+		s.postPublished(post)
+	}
+	if len(published) > 0 {
+		log.Printf("Published %d scheduled posts", len(published))
+	}
+	return nil
+}
+
+// scheduledTime parses the time a post of the author is scheduled for. Local times are read
+// in the author's timezone: of a time that occurs twice when clocks are set back the first
+// occurrence is taken, and a time skipped when clocks jump forward is rejected rather than
+// moved, since the author could not have meant it.
+func (s *postService) scheduledTime(authorID uuid.UUID, value string) (*time.Time, error) {
+	t, err := localtime.Parse(value, s.authorLocation(authorID))
+	if errors.Is(err, localtime.ErrNonexistent) {
+		return nil, errors.ErrScheduledTimeSkipped
+	}
+	if err != nil {
+		return nil, errors.ErrScheduledTimeInvalid
+	}
+	if !t.After(s.cfg.Clock.Now()) {
+		return nil, errors.ErrScheduledTimeInPast
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// authorLocation returns the timezone an author chose, UTC when it cannot be read
+func (s *postService) authorLocation(authorID uuid.UUID) *time.Location {
+	prefs, err := s.userRepo.GetPreferences(authorID)
+	if err != nil {
+		log.Printf("Failed to get the timezone of user %s, using UTC: %v", authorID, err)
+		return time.UTC
+	}
+	return prefs.Location()
+}
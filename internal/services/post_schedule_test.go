@@ -0,0 +1,152 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// fakePostRepo records the range of the last schedule it was asked for; other PostRepository
+// methods are not used
+type fakePostRepo struct {
+	models.PostRepository
+	from, to time.Time
+}
+
+func (r *fakePostRepo) GetScheduled(authorID uuid.UUID, from, to time.Time) ([]*models.Post, error) {
+	r.from, r.to = from, to
+	return []*models.Post{}, nil
+}
+
+// newScheduleService returns a post service for a single author in timezone, at now
+func newScheduleService(t *testing.T, timezone string, now time.Time) (*postService, *fakePostRepo, uuid.UUID) {
+	t.Helper()
+	if _, err := time.LoadLocation(timezone); err != nil {
+		t.Skipf("timezone data for %s not available: %v", timezone, err)
+	}
+	author := &models.User{ID: uuid.New(), Role: models.RoleUser}
+	users := newFakeUserRepo(nil, author)
+	users.timezones = map[uuid.UUID]string{author.ID: timezone}
+	posts := &fakePostRepo{}
+	svc := NewPostService(posts, users, nil, nil, nil, nil, nil, PostServiceConfig{Clock: clock.NewManual(now)})
+	return svc.(*postService), posts, author.ID
+}
+
+func TestScheduleSpansWholeLocalDaysAcrossDaylightSavingChanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		from, to string
+		start    time.Time
+		end      time.Time
+	}{
+		{"spring forward", "America/New_York", "2026-03-08", "2026-03-08",
+			time.Date(2026, 3, 8, 5, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 4, 0, 0, 0, time.UTC)},
+		{"fall back", "America/New_York", "2026-11-01", "2026-11-01",
+			time.Date(2026, 11, 1, 4, 0, 0, 0, time.UTC), time.Date(2026, 11, 2, 5, 0, 0, 0, time.UTC)},
+		// Clocks in Chile jump from midnight to 01:00, so the day starts at 01:00
+		{"midnight skipped", "America/Santiago", "2026-09-06", "2026-09-06",
+			time.Date(2026, 9, 6, 4, 0, 0, 0, time.UTC), time.Date(2026, 9, 7, 3, 0, 0, 0, time.UTC)},
+		// Today, in the author's timezone, and the 30 days after it, across spring forward
+		{"default range", "America/New_York", "", "",
+			time.Date(2026, 3, 7, 5, 0, 0, 0, time.UTC), time.Date(2026, 4, 6, 4, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, posts, authorID := newScheduleService(t, tt.timezone, time.Date(2026, 3, 7, 12, 0, 0, 0, time.UTC))
+
+			schedule, err := svc.GetSchedule(authorID, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetSchedule: %v", err)
+			}
+			if !posts.from.Equal(tt.start) || !posts.to.Equal(tt.end) {
+				t.Errorf("queried [%v, %v), want [%v, %v)", posts.from.UTC(), posts.to.UTC(), tt.start, tt.end)
+			}
+			if schedule.Location.String() != tt.timezone {
+				t.Errorf("schedule is in %v, want %s", schedule.Location, tt.timezone)
+			}
+			// The bounds are shown as local midnights, or when the day starts without one
+			if !schedule.From.Equal(tt.start) || schedule.From.Location().String() != tt.timezone {
+				t.Errorf("from = %v, want %v in %s", schedule.From, tt.start, tt.timezone)
+			}
+			if !schedule.To.Equal(tt.end) {
+				t.Errorf("to = %v, want %v", schedule.To, tt.end)
+			}
+		})
+	}
+}
+
+func TestScheduledTimeAcrossDaylightSavingChanges(t *testing.T) {
+	svc, _, authorID := newScheduleService(t, "America/New_York", time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC))
+
+	tests := []struct {
+		name  string
+		value string
+		want  time.Time
+		err   error
+	}{
+		{"skipped by spring forward", "2026-03-08T02:30", time.Time{}, errors.ErrScheduledTimeSkipped},
+		{"after spring forward", "2026-03-08T03:00", time.Date(2026, 3, 8, 7, 0, 0, 0, time.UTC), nil},
+		// Of a time that occurs twice the first occurrence, in EDT, is taken
+		{"repeated by fall back", "2026-11-01T01:30", time.Date(2026, 11, 1, 5, 30, 0, 0, time.UTC), nil},
+		// An offset picks the second occurrence, in EST
+		{"repeated with an offset", "2026-11-01T01:30:00-05:00", time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), nil},
+		// 19:00 in New York is the current time, which is not in the future
+		{"now", "2026-02-28T19:00", time.Time{}, errors.ErrScheduledTimeInPast},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.scheduledTime(authorID, tt.value)
+			if tt.err != nil {
+				if err != tt.err {
+					t.Fatalf("got %v, %v; want %v", got, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("scheduledTime: %v", err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("got %v, want %v in UTC", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuietHoursEndAcrossDaylightSavingChanges(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data not available: %v", err)
+	}
+	quiet := &models.QuietHours{Start: "22:00", End: "07:00"}
+
+	tests := []struct {
+		name  string
+		now   time.Time
+		until time.Time
+	}{
+		// Nights are an hour shorter when clocks jump forward and an hour longer when they are
+		// set back; quiet hours end at 07:00 local either way
+		{"spring forward night", time.Date(2026, 3, 8, 3, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 11, 0, 0, 0, time.UTC)},
+		{"fall back night", time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC), time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+		{"second 01:30 of fall back", time.Date(2026, 11, 1, 6, 30, 0, 0, time.UTC), time.Date(2026, 11, 1, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			until, ok := quietHoursEnd(quiet, tt.now.In(newYork))
+			if !ok || !until.Equal(tt.until) {
+				t.Errorf("got %v, %v; want %v", until.UTC(), ok, tt.until)
+			}
+		})
+	}
+
+	// 07:00 local on the morning after fall back is 12:00 UTC, not 11:00
+	if _, ok := quietHoursEnd(quiet, time.Date(2026, 11, 1, 11, 30, 0, 0, time.UTC).In(newYork)); !ok {
+		t.Errorf("06:30 EST after fall back: not in quiet hours")
+	}
+}
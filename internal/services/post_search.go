@@ -0,0 +1,287 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/shortid"
+	"go-backend-api/internal/search"
+
+	"github.com/google/uuid"
+)
+
+// Search limits
+const (
+	// maxSearchQueryLength is the longest search in characters, as for saved searches
+	maxSearchQueryLength = 200
+	// minSuggestPrefixLength is the shortest partly typed search that gets suggestions
+	minSuggestPrefixLength = 2
+	// sparseSearchResults is the number of matches under which a corrected query is offered
+	sparseSearchResults = 3
+	// maxCorrectedWords is how many words of a query are looked up for corrections
+	maxCorrectedWords = 5
+)
+
+// searchWord matches the words of a search, leaving its quotes and operators
+var searchWord = regexp.MustCompile(`[\p{L}\p{N}]+`)
+
+// SearchPosts searches the published posts the viewer may list, in the external search index
+// when one is configured and reachable, otherwise in full text or by typo-tolerant title
+// similarity in Postgres, best matches first. When fewer than sparseSearchResults posts match, the
+// query is offered with its misspelled words replaced by the closest words of public titles.
+func (s *postService) SearchPosts(viewerID uuid.UUID, query string, page, perPage int) (*models.PostSearchResult, error) {
+	query, err := searchQuery("q", query, 1)
+	if err != nil {
+		return nil, err
+	}
+	offset := (page - 1) * perPage
+
+	result, err := s.searchIndex(query, viewerID, perPage, offset)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		posts, err := s.postRepo.Search(query, viewerID, perPage, offset)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to search posts")
+		}
+
+		total, err := s.postRepo.CountSearch(query, viewerID)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to count matching posts")
+		}
+		result = &models.PostSearchResult{Posts: posts, Total: total}
+	}
+
+	if err := s.attachReactions(result.Posts...); err != nil {
+		return nil, err
+	}
+
+	if result.Total < sparseSearchResults {
+		if result.DidYouMean, err = s.correctQuery(query); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// searchIndex searches the external search index, when configured, loading the matching posts
+// from the database in the index's order. Matches the index has but the database no longer
+// lists to the viewer are left out. It returns nil, to search Postgres instead, without an
+// index or when the index fails.
+func (s *postService) searchIndex(query string, viewerID uuid.UUID, limit, offset int) (*models.PostSearchResult, error) {
+	if s.cfg.Search == nil {
+		return nil, nil
+	}
+
+	var followees []uuid.UUID
+	if viewerID != uuid.Nil {
+		var err error
+		if followees, err = s.postRepo.GetFolloweeIDs(viewerID); err != nil {
+			return nil, errors.WrapError(err, "Failed to get followees")
+		}
+	}
+
+	found, err := s.cfg.Search.Search(&search.Query{
+		Text:      query,
+		ViewerID:  viewerID,
+		Followees: followees,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		log.Printf("Search index failed, searching the database instead: %v", err)
+		return nil, nil
+	}
+
+	posts, err := s.postRepo.GetListedByIDs(found.IDs, viewerID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get matching posts")
+	}
+	byID := make(map[uuid.UUID]*models.Post, len(posts))
+	for _, post := range posts {
+		byID[post.ID] = post
+	}
+
+	result := &models.PostSearchResult{Posts: make([]*models.Post, 0, len(posts)), Total: found.Total}
+	for _, id := range found.IDs {
+		if post, ok := byID[id]; ok {
+			result.Posts = append(result.Posts, post)
+		}
+	}
+
+	return result, nil
+}
+
+// postChanged passes a created or changed post on to the external search index and the stored
+// feeds, when configured
+func (s *postService) postChanged(post *models.Post) {
+	s.indexPost(post)
+	if s.cfg.Feed != nil {
+		s.cfg.Feed.PostChanged(post)
+	}
+}
+
+// indexPost updates a post in the external search index, when configured: published posts are
+// indexed and all others removed
+func (s *postService) indexPost(post *models.Post) {
+	if s.cfg.Search == nil {
+		return
+	}
+	if post.State() != models.PostStatePublished {
+		s.cfg.Search.Delete(post.ID)
+		return
+	}
+	s.cfg.Search.Put(searchDocument(post))
+}
+
+// ReindexSearch indexes every published post in the external search index and removes all
+// other posts from it, returning how many posts it indexed. Documents of deleted posts may
+// remain, but searches never return posts the database no longer lists.
+func (s *postService) ReindexSearch() (int, error) {
+	if s.cfg.Search == nil {
+		return 0, errors.ErrSearchIndexNotConfigured
+	}
+
+	indexed := 0
+	err := s.postRepo.Export(func(post *models.Post) error {
+		if post.State() != models.PostStatePublished {
+			s.cfg.Search.Delete(post.ID)
+			return nil
+		}
+		if err := s.cfg.Search.PutNow(searchDocument(post)); err != nil {
+			return err
+		}
+		indexed++
+		return nil
+	})
+	if err != nil {
+		return indexed, errors.WrapError(err, "Failed to reindex posts")
+	}
+
+	return indexed, nil
+}
+
+// searchDocument maps a post to its search index document
+func searchDocument(post *models.Post) *search.Document {
+	doc := &search.Document{
+		ID:          post.ID,
+		Visibility:  post.Visibility,
+		Title:       post.Title,
+		Content:     post.Content,
+		PublishedAt: post.PublishedAt,
+	}
+	// Anonymized posts are indexed without an author
+	if post.AuthorID != uuid.Nil {
+		authorID := post.AuthorID
+		doc.AuthorID = &authorID
+	}
+	return doc
+}
+
+// SuggestSearch offers up to limit completions of a partly typed search: titles of posts the
+// viewer may list and usernames of authors of public posts, titles first
+func (s *postService) SuggestSearch(viewerID uuid.UUID, prefix string, limit int) ([]*models.SearchSuggestion, error) {
+	prefix, err := searchQuery("q", prefix, minSuggestPrefixLength)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.SuggestTitles(prefix, viewerID, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to suggest post titles")
+	}
+	authors, err := s.postRepo.SuggestAuthors(prefix, limit)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to suggest authors")
+	}
+
+	// Half the suggestions are kept for authors when there are both
+	titles := len(posts)
+	if titles+len(authors) > limit {
+		titles = limit - min(len(authors), limit/2)
+		if titles > len(posts) {
+			titles = len(posts)
+		}
+	}
+
+	suggestions := []*models.SearchSuggestion{}
+	seen := map[string]bool{}
+	for _, post := range posts[:titles] {
+		// Posts sharing a title are suggested once
+		if key := strings.ToLower(post.Title); !seen[key] {
+			seen[key] = true
+			id := post.ID
+			suggestions = append(suggestions, &models.SearchSuggestion{
+				Type:    models.SearchSuggestionTitle,
+				Text:    post.Title,
+				PostID:  &id,
+				ShortID: shortid.Encode(post.Number),
+			})
+		}
+	}
+	for _, username := range authors {
+		if len(suggestions) >= limit {
+			break
+		}
+		suggestions = append(suggestions, &models.SearchSuggestion{
+			Type: models.SearchSuggestionAuthor,
+			Text: username,
+		})
+	}
+
+	return suggestions, nil
+}
+
+// correctQuery returns the query with its words replaced by the most similar words of public
+// post titles, or "" when no word needs correcting
+func (s *postService) correctQuery(query string) (string, error) {
+	corrections := map[string]string{}
+	looked := 0
+	for _, word := range searchWord.FindAllString(strings.ToLower(query), -1) {
+		// Short words and the or operator are too ambiguous to correct
+		if _, ok := corrections[word]; ok || utf8.RuneCountInString(word) < 3 || word == "or" {
+			continue
+		}
+		if looked == maxCorrectedWords {
+			break
+		}
+		looked++
+
+		similar, err := s.postRepo.SimilarWord(word)
+		if err != nil {
+			return "", errors.WrapError(err, "Failed to correct search")
+		}
+		corrections[word] = similar
+	}
+
+	corrected := false
+	result := searchWord.ReplaceAllStringFunc(query, func(word string) string {
+		similar := corrections[strings.ToLower(word)]
+		if similar == "" || similar == strings.ToLower(word) {
+			return word
+		}
+		corrected = true
+		return similar
+	})
+	if !corrected {
+		return "", nil
+	}
+
+	return result, nil
+}
+
+// searchQuery trims a search given in the parameter name and checks its length
+func searchQuery(name, query string, minLength int) (string, error) {
+	query = strings.TrimSpace(query)
+	if n := utf8.RuneCountInString(query); n < minLength || n > maxSearchQueryLength {
+		return "", errors.NewInvalidParamError(name, fmt.Sprintf("%s must be %d to %d characters", name, minLength, maxSearchQueryLength))
+	}
+	return query, nil
+}
@@ -1,28 +1,88 @@
 package services
 
 import (
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
 	"time"
 
+	"go-backend-api/internal/events"
 	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
 	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/shortid"
+	"go-backend-api/internal/pkg/text"
 	"go-backend-api/internal/pkg/validation"
+	"go-backend-api/internal/search"
 
 	"github.com/google/uuid"
 )
 
+// Defaults used when the content and editing settings are not configured
+const (
+	DefaultPostLockTTL         = 2 * time.Minute
+	DefaultPostMaxContentBytes = 100000
+)
+
+// PostServiceConfig holds the content and editing settings of the post service
+type PostServiceConfig struct {
+	// ExcerptWords is how many words of the content the stored excerpt keeps
+	ExcerptWords int
+	// WordsPerMinute is the reading speed the estimated reading time is based on
+	WordsPerMinute int
+	// MaxContentBytes is the largest content a post or autosave may have, before sanitizing
+	MaxContentBytes int
+	// LockTTL is how long an editing lock lasts after it was taken or last renewed
+	LockTTL time.Duration
+	// ParentalConsentAge keeps authors younger than it from publishing until a parent consents (0 disables)
+	ParentalConsentAge int
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+	// Search indexes published posts in an external search backend and serves searches from
+	// it (nil searches Postgres only)
+	Search *search.Indexer
+	// Feed stores published posts in the feeds of their authors' followers and serves feeds
+	// from them (nil joins across follows when reading feeds)
+	Feed *FeedFanOut
+	// Events receives a PostPublished event whenever a post goes live (nil publishes to no one)
+	Events events.Publisher
+}
+
 // postService implements PostService interface
 type postService struct {
-	postRepo  models.PostRepository
-	userRepo  models.UserRepository
-	validator *validation.Validator
+	postRepo     models.PostRepository
+	userRepo     models.UserRepository
+	reactionRepo models.ReactionRepository
+	lockRepo     models.PostLockRepository
+	autosaveRepo models.PostAutosaveRepository
+	legalHold    *legalHoldGuard
+	validator    *validation.Validator
+	cfg          PostServiceConfig
 }
 
 // NewPostService creates a new post service
-func NewPostService(postRepo models.PostRepository, userRepo models.UserRepository) models.PostService {
+func NewPostService(postRepo models.PostRepository, userRepo models.UserRepository, reactionRepo models.ReactionRepository, lockRepo models.PostLockRepository, autosaveRepo models.PostAutosaveRepository, legalHoldRepo models.LegalHoldRepository, auditLogRepo models.AuditLogRepository, cfg PostServiceConfig) models.PostService {
+	if cfg.LockTTL <= 0 {
+		cfg.LockTTL = DefaultPostLockTTL
+	}
+	if cfg.MaxContentBytes < 1 {
+		cfg.MaxContentBytes = DefaultPostMaxContentBytes
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	if cfg.Events == nil {
+		cfg.Events = events.Discard
+	}
 	return &postService{
-		postRepo:  postRepo,
-		userRepo:  userRepo,
-		validator: validation.NewValidator(),
+		postRepo:     postRepo,
+		userRepo:     userRepo,
+		reactionRepo: reactionRepo,
+		lockRepo:     lockRepo,
+		autosaveRepo: autosaveRepo,
+		legalHold:    newLegalHoldGuard(legalHoldRepo, newAuditRecorder(auditLogRepo, nil)),
+		validator:    validation.NewValidator(),
+		cfg:          cfg,
 	}
 }
 
@@ -35,62 +95,158 @@ func (s *postService) CreatePost(authorID uuid.UUID, req *models.CreatePostReque
 
 	// Verify author exists
 	author, err := s.userRepo.GetByID(authorID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get author")
 	}
-	if author == nil {
-		return nil, errors.ErrUserNotFound
+	if (req.IsPublished || req.ScheduledAt != "") && author.NeedsParentalConsent(s.cfg.ParentalConsentAge, s.cfg.Clock.Now()) {
+		return nil, errors.ErrParentalConsentRequired
+	}
+	var scheduledAt *time.Time
+	if req.ScheduledAt != "" {
+		if req.IsPublished {
+			return nil, errors.ErrPostPublished
+		}
+		if scheduledAt, err = s.scheduledTime(authorID, req.ScheduledAt); err != nil {
+			return nil, err
+		}
 	}
 
 	// Create post
 	post := &models.Post{
-		Title:     req.Title,
-		Content:   req.Content,
-		AuthorID:  authorID,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Title:         req.Title,
+		Content:       req.Content,
+		ContentFormat: req.Format,
+		AuthorID:      authorID,
+		IsPublished:   req.IsPublished,
+		Visibility:    req.Visibility,
+		ScheduledAt:   scheduledAt,
+		CreatedAt:     s.cfg.Clock.Now(),
+		UpdatedAt:     s.cfg.Clock.Now(),
+	}
+	if author.Role == models.RoleSandbox {
+		// Sandbox posts are never shown to anyone else
+		post.Visibility = models.PostVisibilityPrivate
+	}
+	if post.IsPublished {
+		post.PublishedAt = &post.CreatedAt
+	}
+	if post.ContentFormat == "" {
+		post.ContentFormat = models.ContentFormatMarkdown
+	}
+	if err := s.prepareContent(post); err != nil {
+		return nil, err
 	}
 
 	if err := s.postRepo.Create(post); err != nil {
 		return nil, errors.WrapError(err, "Failed to create post")
 	}
+	s.postChanged(post)
+	if post.IsPublished {
+		s.postPublished(post)
+	}
 
 	return post, nil
 }
 
-// GetPostByID gets a post by ID
-func (s *postService) GetPostByID(id uuid.UUID) (*models.Post, error) {
+// GetPostByID gets a post by ID. Archived posts are only found by their author, and others
+// only find the posts their visibility lets them read.
+func (s *postService) GetPostByID(id, viewerID uuid.UUID) (*models.Post, error) {
 	post, err := s.postRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrPostNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get post")
 	}
-	if post == nil {
+	if post.IsArchived() && post.AuthorID != viewerID {
 		return nil, errors.ErrPostNotFound
 	}
+	if err := checkVisible(s.postRepo, post, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.withDetails(post, viewerID)
+}
 
+// GetPostByShortID gets a post by the short ID of its shareable link. Links only lead to
+// published posts, so drafts cannot be found by guessing short IDs.
+func (s *postService) GetPostByShortID(shortID string, viewerID uuid.UUID) (*models.Post, error) {
+	number, err := shortid.Decode(shortID)
+	if err != nil {
+		return nil, errors.ErrPostNotFound
+	}
+	post, err := s.postRepo.GetByNumber(number)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get post")
+	}
+	if !post.IsPublished || post.IsArchived() {
+		return nil, errors.ErrPostNotFound
+	}
+	if err := checkVisible(s.postRepo, post, viewerID); err != nil {
+		return nil, err
+	}
+
+	return s.withDetails(post, viewerID)
+}
+
+// withDetails attaches the author, reactions and, for the author, the editing lock to a post
+// being read, and counts the view of anyone else
+func (s *postService) withDetails(post *models.Post, viewerID uuid.UUID) (*models.Post, error) {
 	// Get author information
 	author, err := s.userRepo.GetByID(post.AuthorID)
-	if err != nil {
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
 		return nil, errors.WrapError(err, "Failed to get post author")
 	}
 	if author != nil {
-		author.Password = "" // Clear password
+		author.Sanitize()
 		post.Author = author
 	}
 
+	if err := s.attachReactions(post); err != nil {
+		return nil, err
+	}
+	if post.AuthorID == viewerID {
+		if err := s.attachLock(post); err != nil {
+			return nil, err
+		}
+	} else {
+		// Views feed the author stats; failing to count one should not fail the read
+		if err := s.postRepo.IncrementViews(post.ID); err != nil {
+			log.Printf("Failed to count view of post %s: %v", post.ID, err)
+		}
+	}
+
 	return post, nil
 }
 
 // GetPosts gets all posts with pagination
-func (s *postService) GetPosts(page, perPage int) ([]*models.Post, int, error) {
+func (s *postService) GetPosts(viewerID uuid.UUID, lang string, page, perPage int) ([]*models.Post, int, error) {
 	offset := (page - 1) * perPage
 
-	posts, err := s.postRepo.GetAllWithAuthor(perPage, offset)
+	// Without a language filter, the viewer's preferred languages come first
+	filter := models.PostFilter{Language: lang, ViewerID: viewerID}
+	if lang == "" && viewerID != uuid.Nil {
+		prefs, err := s.userRepo.GetPreferences(viewerID)
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
+			return nil, 0, errors.WrapError(err, "Failed to get user preferences")
+		}
+		if prefs != nil {
+			filter.PreferredLanguages = prefs.PreferredLanguages
+		}
+	}
+
+	posts, err := s.postRepo.GetAllWithAuthor(filter, perPage, offset)
 	if err != nil {
 		return nil, 0, errors.WrapError(err, "Failed to get posts")
 	}
 
-	total, err := s.postRepo.Count()
+	total, err := s.postRepo.Count(filter)
 	if err != nil {
 		return nil, 0, errors.WrapError(err, "Failed to count posts")
 	}
@@ -98,23 +254,30 @@ func (s *postService) GetPosts(page, perPage int) ([]*models.Post, int, error) {
 	// Clear passwords from author information
 	for _, post := range posts {
 		if post.Author != nil {
-			post.Author.Password = ""
+			post.Author.Sanitize()
 		}
 	}
 
+	if err := s.attachReactions(posts...); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
-// GetPostsByAuthor gets posts by author with pagination
-func (s *postService) GetPostsByAuthor(authorID uuid.UUID, page, perPage int) ([]*models.Post, int, error) {
+// GetPostsByAuthor gets posts by author with pagination.
+// Archived posts are only included when the author is viewing their own posts, and others
+// only get the posts they may list.
+func (s *postService) GetPostsByAuthor(authorID, viewerID uuid.UUID, page, perPage int) ([]*models.Post, int, error) {
 	offset := (page - 1) * perPage
+	includeArchived := authorID == viewerID
 
-	posts, err := s.postRepo.GetByAuthorID(authorID, perPage, offset)
+	posts, err := s.postRepo.GetByAuthorID(authorID, viewerID, includeArchived, perPage, offset)
 	if err != nil {
 		return nil, 0, errors.WrapError(err, "Failed to get posts by author")
 	}
 
-	total, err := s.postRepo.CountByAuthorID(authorID)
+	total, err := s.postRepo.CountByAuthorID(authorID, viewerID, includeArchived)
 	if err != nil {
 		return nil, 0, errors.WrapError(err, "Failed to count posts by author")
 	}
@@ -122,15 +285,19 @@ func (s *postService) GetPostsByAuthor(authorID uuid.UUID, page, perPage int) ([
 	// Get author information for each post
 	for _, post := range posts {
 		author, err := s.userRepo.GetByID(post.AuthorID)
-		if err != nil {
+		if err != nil && !errors.Is(err, models.ErrNotFound) {
 			return nil, 0, errors.WrapError(err, "Failed to get post author")
 		}
 		if author != nil {
-			author.Password = "" // Clear password
+			author.Sanitize()
 			post.Author = author
 		}
 	}
 
+	if err := s.attachReactions(posts...); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
@@ -143,65 +310,130 @@ func (s *postService) UpdatePost(id, authorID uuid.UUID, req *models.UpdatePostR
 
 	// Get existing post
 	post, err := s.postRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrPostNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get post")
 	}
-	if post == nil {
-		return nil, errors.ErrPostNotFound
-	}
 
 	// Check if user is the author
 	if post.AuthorID != authorID {
 		return nil, errors.ErrForbidden
 	}
 
+	if err := s.checkLock(post, req.LockToken); err != nil {
+		return nil, err
+	}
+
 	// Update fields if provided
 	if req.Title != "" {
 		post.Title = req.Title
 	}
-	if req.Content != "" {
-		post.Content = req.Content
+	if req.Content != "" || req.Format != "" {
+		if req.Content != "" {
+			post.Content = req.Content
+			post.ContentSanitized = false
+		}
+		if req.Format != "" {
+			post.ContentFormat = req.Format
+		}
+		if err := s.prepareContent(post); err != nil {
+			return nil, err
+		}
+	}
+	if req.Visibility != "" && req.Visibility != post.Visibility {
+		author, err := s.userRepo.GetByID(authorID)
+		if err != nil {
+			return nil, writeError(err, errors.ErrUserNotFound, "Failed to get author")
+		}
+		if author.Role == models.RoleSandbox {
+			return nil, errors.ErrSandboxRestricted
+		}
+		post.Visibility = req.Visibility
+	}
+	// The fields set the state they describe, so resending the current state is not a transition
+	action, transition := publishedAction(post, req.IsPublished)
+	if transition {
+		if err := s.transitionPost(post, action, nil); err != nil {
+			return nil, err
+		}
+	}
+	if req.ScheduledAt != nil && *req.ScheduledAt != "" {
+		if err := checkTransition(postActionSchedule, post.State()); err != nil {
+			return nil, err
+		}
+		scheduledAt, err := s.scheduledTime(authorID, *req.ScheduledAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.transitionPost(post, postActionSchedule, scheduledAt); err != nil {
+			return nil, err
+		}
+	} else if req.ScheduledAt != nil && post.IsScheduled() {
+		if err := s.transitionPost(post, postActionUnschedule, nil); err != nil {
+			return nil, err
+		}
 	}
 
-	post.UpdatedAt = time.Now()
+	post.UpdatedAt = s.cfg.Clock.Now()
 
 	// Update post
 	if err := s.postRepo.Update(post); err != nil {
-		return nil, errors.WrapError(err, "Failed to update post")
+		return nil, writeError(err, errors.ErrPostNotFound, "Failed to update post")
+	}
+	s.postChanged(post)
+	if transition && action == postActionPublish {
+		s.postPublished(post)
+	}
+
+	// The saved post supersedes any autosaved draft
+	if err := s.autosaveRepo.Delete(post.ID); err != nil {
+		log.Printf("Failed to discard autosave of post %s: %v", post.ID, err)
 	}
 
 	// Get author information
 	author, err := s.userRepo.GetByID(post.AuthorID)
-	if err != nil {
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
 		return nil, errors.WrapError(err, "Failed to get post author")
 	}
 	if author != nil {
-		author.Password = "" // Clear password
+		author.Sanitize()
 		post.Author = author
 	}
 
+	if err := s.attachReactions(post); err != nil {
+		return nil, err
+	}
+
 	return post, nil
 }
 
-// DeletePost deletes a post
+// DeletePost deletes a post, unless its author is under legal hold
 func (s *postService) DeletePost(id, authorID uuid.UUID) error {
 	// Get existing post
 	post, err := s.postRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrPostNotFound
+	}
 	if err != nil {
 		return errors.WrapError(err, "Failed to get post")
 	}
-	if post == nil {
-		return errors.ErrPostNotFound
-	}
 
 	// Check if user is the author
 	if post.AuthorID != authorID {
 		return errors.ErrForbidden
 	}
+	if err := s.legalHold.check(authorID, legalHoldActionDeletePost, post.ID.String(), models.ClientInfo{}); err != nil {
+		return err
+	}
 
 	// Delete post
 	if err := s.postRepo.Delete(id); err != nil {
-		return errors.WrapError(err, "Failed to delete post")
+		return writeError(err, errors.ErrPostNotFound, "Failed to delete post")
+	}
+	if s.cfg.Search != nil {
+		s.cfg.Search.Delete(id)
 	}
 
 	return nil
@@ -221,65 +453,356 @@ func (s *postService) GetPublishedPosts(page, perPage int) ([]*models.Post, int,
 		return nil, 0, errors.WrapError(err, "Failed to count published posts")
 	}
 
+	if err := s.attachReactions(posts...); err != nil {
+		return nil, 0, err
+	}
+
 	return posts, total, nil
 }
 
-// PublishPost publishes a post
-func (s *postService) PublishPost(id, authorID uuid.UUID) error {
-	// Get existing post
-	post, err := s.postRepo.GetByID(id)
+// ListAllPosts gets every post, including archived and unpublished ones, with pagination
+func (s *postService) ListAllPosts(page, perPage int) ([]*models.Post, int, error) {
+	offset := (page - 1) * perPage
+
+	posts, err := s.postRepo.GetAll(perPage, offset)
 	if err != nil {
-		return errors.WrapError(err, "Failed to get post")
+		return nil, 0, errors.WrapError(err, "Failed to get posts")
 	}
-	if post == nil {
-		return errors.NewErrorWithCode(404, "Post not found")
+
+	total, err := s.postRepo.CountAll()
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count posts")
 	}
 
-	// Check if user owns the post
-	if post.AuthorID != authorID {
-		return errors.NewErrorWithCode(403, "Not authorized to publish this post")
+	if err := s.attachReactions(posts...); err != nil {
+		return nil, 0, err
 	}
 
-	// Update post
-	post.IsPublished = true
-	post.UpdatedAt = time.Now()
+	return posts, total, nil
+}
+
+// ExportPosts calls fn with every post, oldest first. Reactions are not attached,
+// since posts are handed over one at a time and counting would cost a query per post.
+func (s *postService) ExportPosts(fn func(*models.Post) error) error {
+	return s.postRepo.Export(fn)
+}
 
-	err = s.postRepo.Update(post)
+// PublishPost publishes a draft, or a scheduled post ahead of time
+func (s *postService) PublishPost(id, authorID uuid.UUID) error {
+	post, err := s.getOwnPost(id, authorID)
 	if err != nil {
-		return errors.WrapError(err, "Failed to publish post")
+		return err
+	}
+	if err := s.transitionPost(post, postActionPublish, nil); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.Update(post); err != nil {
+		return writeError(err, errors.ErrPostNotFound, "Failed to publish post")
 	}
+	s.postChanged(post)
+	s.postPublished(post)
 
 	return nil
 }
 
-// UnpublishPost unpublishes a post
+// checkCanPublish rejects authors awaiting parental consent, whose posts must stay private
+func (s *postService) checkCanPublish(authorID uuid.UUID) error {
+	author, err := s.userRepo.GetByID(authorID)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get author")
+	}
+	if author.NeedsParentalConsent(s.cfg.ParentalConsentAge, s.cfg.Clock.Now()) {
+		return errors.ErrParentalConsentRequired
+	}
+	return nil
+}
+
+// UnpublishPost takes a published post back to a draft, or cancels the schedule of a scheduled one
 func (s *postService) UnpublishPost(id, authorID uuid.UUID) error {
-	// Get existing post
-	post, err := s.postRepo.GetByID(id)
+	post, err := s.getOwnPost(id, authorID)
 	if err != nil {
-		return errors.WrapError(err, "Failed to get post")
+		return err
 	}
-	if post == nil {
-		return errors.NewErrorWithCode(404, "Post not found")
+	if err := s.transitionPost(post, postActionUnpublish, nil); err != nil {
+		return err
 	}
 
-	// Check if user owns the post
-	if post.AuthorID != authorID {
-		return errors.NewErrorWithCode(403, "Not authorized to unpublish this post")
+	if err := s.postRepo.Update(post); err != nil {
+		return writeError(err, errors.ErrPostNotFound, "Failed to unpublish post")
 	}
+	s.postChanged(post)
 
-	// Update post
-	post.IsPublished = false
-	post.UpdatedAt = time.Now()
+	return nil
+}
 
-	err = s.postRepo.Update(post)
+// ArchivePost archives a post, unpublishing or unscheduling it and hiding it from everyone but its author
+func (s *postService) ArchivePost(id, authorID uuid.UUID) (*models.Post, error) {
+	post, err := s.getOwnPost(id, authorID)
 	if err != nil {
-		return errors.WrapError(err, "Failed to unpublish post")
+		return nil, err
+	}
+	if err := s.transitionPost(post, postActionArchive, nil); err != nil {
+		return nil, err
 	}
 
+	if err := s.postRepo.Update(post); err != nil {
+		return nil, writeError(err, errors.ErrPostNotFound, "Failed to archive post")
+	}
+	s.postChanged(post)
+
+	return post, nil
+}
+
+// UnarchivePost restores an archived post as a draft, so it has to be published again explicitly
+func (s *postService) UnarchivePost(id, authorID uuid.UUID) (*models.Post, error) {
+	post, err := s.getOwnPost(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.transitionPost(post, postActionUnarchive, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.postRepo.Update(post); err != nil {
+		return nil, writeError(err, errors.ErrPostNotFound, "Failed to unarchive post")
+	}
+	s.postChanged(post)
+
+	return post, nil
+}
+
+// LockPost takes the editing lock of a post, or renews it when the request carries the current lock's token.
+// The lock is refused while another session holds it.
+func (s *postService) LockPost(id, authorID uuid.UUID, req *models.PostLockRequest) (*models.PostLock, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	if _, err := s.getOwnPost(id, authorID); err != nil {
+		return nil, err
+	}
+
+	token := req.LockToken
+	if token == "" {
+		var err error
+		if token, err = auth.GenerateOpaqueToken(); err != nil {
+			return nil, errors.WrapError(err, "Failed to generate lock token")
+		}
+	}
+
+	now := s.cfg.Clock.Now()
+	lock := &models.PostLock{
+		PostID:     id,
+		UserID:     authorID,
+		Token:      token,
+		AcquiredAt: now,
+		ExpiresAt:  now.Add(s.cfg.LockTTL),
+	}
+	if err := s.lockRepo.Acquire(lock); err != nil {
+		return nil, writeError(err, errors.ErrPostLocked, "Failed to lock post")
+	}
+
+	return lock, nil
+}
+
+// UnlockPost releases the editing lock held with token
+func (s *postService) UnlockPost(id, authorID uuid.UUID, token string) error {
+	if _, err := s.getOwnPost(id, authorID); err != nil {
+		return err
+	}
+
+	if err := s.lockRepo.Release(id, token); err != nil {
+		return writeError(err, errors.ErrPostLockNotHeld, "Failed to unlock post")
+	}
+
+	return nil
+}
+
+// AutosavePost stores the unsaved draft of a post for recovery, leaving the post itself untouched
+func (s *postService) AutosavePost(id, authorID uuid.UUID, req *models.AutosavePostRequest) (*models.PostAutosave, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	post, err := s.getOwnPost(id, authorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkLock(post, req.LockToken); err != nil {
+		return nil, err
+	}
+	if err := s.checkContentSize(req.Content); err != nil {
+		return nil, err
+	}
+
+	autosave := &models.PostAutosave{
+		PostID:  id,
+		Title:   req.Title,
+		Content: req.Content,
+		SavedAt: s.cfg.Clock.Now(),
+	}
+	if err := s.autosaveRepo.Save(autosave); err != nil {
+		return nil, errors.WrapError(err, "Failed to autosave post")
+	}
+
+	return autosave, nil
+}
+
+// GetAutosave gets the autosaved draft of a post
+func (s *postService) GetAutosave(id, authorID uuid.UUID) (*models.PostAutosave, error) {
+	if _, err := s.getOwnPost(id, authorID); err != nil {
+		return nil, err
+	}
+
+	autosave, err := s.autosaveRepo.GetByPostID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrAutosaveNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get autosaved post")
+	}
+
+	return autosave, nil
+}
+
+// checkLock loads the editing lock of a post and, while one is held,
+// refuses writes that do not carry its token
+func (s *postService) checkLock(post *models.Post, token string) error {
+	if err := s.attachLock(post); err != nil {
+		return err
+	}
+	if post.Lock != nil && post.Lock.Token != token {
+		return errors.ErrPostLocked
+	}
+	return nil
+}
+
+// attachLock sets the active editing lock of a post, if any
+func (s *postService) attachLock(post *models.Post) error {
+	lock, err := s.lockRepo.GetActive(post.ID)
+	if errors.Is(err, models.ErrNotFound) {
+		post.Lock = nil
+		return nil
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get post lock")
+	}
+
+	post.Lock = lock
+	return nil
+}
+
+// attachReactions fills in the reaction counts of posts with one query
+func (s *postService) attachReactions(posts ...*models.Post) error {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+	counts, err := s.reactionRepo.CountForPosts(ids)
+	if err != nil {
+		return errors.WrapError(err, "Failed to count reactions")
+	}
+	for _, post := range posts {
+		post.Reactions = counts[post.ID]
+	}
+	return nil
+}
+
+// visiblePost gets a post the viewer may comment and react on: published, not archived and
+// readable at its visibility, or their own
+func visiblePost(postRepo models.PostRepository, postID, viewerID uuid.UUID) (*models.Post, error) {
+	post, err := postRepo.GetByID(postID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get post")
+	}
+
+	if post.AuthorID != viewerID && (!post.IsPublished || post.IsArchived()) {
+		return nil, errors.ErrPostNotFound
+	}
+	if err := checkVisible(postRepo, post, viewerID); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// checkVisible verifies that the viewer may read a post at its visibility, looking up whether
+// they follow its author only for followers-only posts. Posts the viewer may not read are not
+// found, so that their existence is not revealed.
+func checkVisible(postRepo models.PostRepository, post *models.Post, viewerID uuid.UUID) error {
+	follower := false
+	if post.Visibility == models.PostVisibilityFollowers && viewerID != uuid.Nil && post.AuthorID != viewerID {
+		var err error
+		if follower, err = postRepo.IsFollower(post.AuthorID, viewerID); err != nil {
+			return errors.WrapError(err, "Failed to check post visibility")
+		}
+	}
+	if !post.VisibleTo(viewerID, follower) {
+		return errors.ErrPostNotFound
+	}
+	return nil
+}
+
+// prepareContent enforces the content size limit, sanitizes HTML content, and derives the summary and language.
+// ContentSanitized stays set once sanitizing changed the submitted content, until new content is submitted.
+func (s *postService) prepareContent(post *models.Post) error {
+	if err := s.checkContentSize(post.Content); err != nil {
+		return err
+	}
+
+	if post.ContentFormat == models.ContentFormatHTML {
+		var changed bool
+		post.Content, changed = text.SanitizeHTML(post.Content)
+		post.ContentSanitized = post.ContentSanitized || changed
+		if strings.TrimSpace(post.Content) == "" {
+			return errors.NewAppErrorWithReason(http.StatusBadRequest, "CONTENT_EMPTY", "Content is empty once unsafe HTML is removed")
+		}
+	}
+
+	s.summarize(post)
+	post.Language = text.DetectLanguage(post.Title + "\n" + post.Content)
+	return nil
+}
+
+// checkContentSize rejects content over the configured size
+func (s *postService) checkContentSize(content string) error {
+	if len(content) > s.cfg.MaxContentBytes {
+		return errors.NewAppErrorWithReason(http.StatusRequestEntityTooLarge, "CONTENT_TOO_LARGE",
+			fmt.Sprintf("Content must be at most %d bytes", s.cfg.MaxContentBytes))
+	}
 	return nil
 }
 
+// summarize derives the excerpt and reading time listings show instead of the full content
+func (s *postService) summarize(post *models.Post) {
+	post.Excerpt = text.Excerpt(post.Content, s.cfg.ExcerptWords)
+	post.ReadingTime = text.ReadingTime(post.Content, s.cfg.WordsPerMinute)
+}
+
+// getOwnPost gets a post that must belong to authorID
+func (s *postService) getOwnPost(id, authorID uuid.UUID) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrPostNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get post")
+	}
+
+	if post.AuthorID != authorID {
+		return nil, errors.ErrForbidden
+	}
+
+	return post, nil
+}
+
 // ValidatePost validates a post entity
 func (s *postService) ValidatePost(post *models.Post) error {
 	return s.validator.Validate(post)
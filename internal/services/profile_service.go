@@ -0,0 +1,106 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// DefaultProfileLatestPosts is how many posts a profile page lists when none is configured
+const DefaultProfileLatestPosts = 5
+
+// profileService implements ProfileService interface
+type profileService struct {
+	profileRepo models.ProfileRepository
+	userRepo    models.UserRepository
+	userService models.UserService
+	feed        *FeedFanOut
+	latestPosts int
+	consentAge  int
+}
+
+// NewProfileService creates a new profile service listing latestPosts posts per profile page.
+// Lookups by a previous username are resolved to a redirect through the user service. Users
+// under parentalConsentAge without parental consent have no profile and cannot be followed.
+// Follows are passed on to feed, when feeds are stored (nil otherwise).
+func NewProfileService(profileRepo models.ProfileRepository, userRepo models.UserRepository, userService models.UserService, feed *FeedFanOut, latestPosts, parentalConsentAge int) models.ProfileService {
+	if latestPosts < 1 {
+		latestPosts = DefaultProfileLatestPosts
+	}
+	return &profileService{
+		profileRepo: profileRepo,
+		userRepo:    userRepo,
+		userService: userService,
+		feed:        feed,
+		latestPosts: latestPosts,
+		consentAge:  parentalConsentAge,
+	}
+}
+
+// GetProfilePage gets the public profile page of a user by username
+func (s *profileService) GetProfilePage(username string) (*models.ProfilePage, *models.UsernameRedirect, error) {
+	page, err := s.profileRepo.GetPage(username, s.latestPosts, models.ParentalConsentCutoff(s.consentAge, time.Now()))
+	if err == nil {
+		return page, nil, nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return nil, nil, errors.WrapError(err, "Failed to get profile page")
+	}
+
+	// Not a current username; it may be a previous one
+	_, redirect, err := s.userService.GetPublicProfile(username)
+	if err != nil {
+		return nil, nil, err
+	}
+	if redirect == nil {
+		return nil, nil, errors.ErrUserNotFound
+	}
+	return nil, redirect, nil
+}
+
+// FollowUser makes followerID follow an active user
+func (s *profileService) FollowUser(followerID, followeeID uuid.UUID) error {
+	if followerID == followeeID {
+		return errors.NewErrorWithCode(400, "You cannot follow yourself")
+	}
+
+	followee, err := s.userRepo.GetByID(followeeID)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get user")
+	}
+	if !followee.IsActive || followee.NeedsParentalConsent(s.consentAge, time.Now()) || followee.Role == models.RoleSandbox {
+		return errors.ErrUserNotFound
+	}
+
+	follow := &models.Follow{
+		FollowerID: followerID,
+		FolloweeID: followeeID,
+		CreatedAt:  time.Now(),
+	}
+	if err := s.profileRepo.Follow(follow); err != nil {
+		return errors.WrapError(err, "Failed to follow user")
+	}
+	if s.feed != nil {
+		s.feed.Followed(followerID, followeeID)
+	}
+
+	return nil
+}
+
+// UnfollowUser stops followerID following followeeID
+func (s *profileService) UnfollowUser(followerID, followeeID uuid.UUID) error {
+	if err := s.profileRepo.Unfollow(followerID, followeeID); err != nil {
+		return writeError(err, errors.NewErrorWithCode(404, "Not following this user"), "Failed to unfollow user")
+	}
+	if s.feed != nil {
+		s.feed.Unfollowed(followerID, followeeID)
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"go-backend-api/internal/events"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/text"
+	"go-backend-api/internal/push"
+
+	"github.com/google/uuid"
+)
+
+// DefaultPushQueueSize is how many events wait for the push worker before new ones are dropped
+const DefaultPushQueueSize = 1000
+
+// Push fan-out batches
+const (
+	// pushSubscriberPage is how many subscribers of a topic are loaded at a time
+	pushSubscriberPage = 500
+	// pushHeldBatch is how many held notifications are released at a time
+	pushHeldBatch = 100
+	// pushExcerptWords is how much of a comment its notification quotes
+	pushExcerptWords = 20
+)
+
+// PushFanOut sends push notifications of published posts and new comments to the devices of
+// the users subscribed to their topics. Events are processed by a background worker, so
+// publishing never waits on push services; events arriving while the queue is full are
+// dropped and logged.
+//
+// A topic's notifications share its name as collapse key, so a device that was offline only
+// shows the latest. Notifications arriving during a user's quiet hours are held until the
+// quiet hours end, likewise keeping the latest per collapse key.
+type PushFanOut struct {
+	pushRepo    models.PushRepository
+	postRepo    models.PostRepository
+	commentRepo models.CommentRepository
+	userRepo    models.UserRepository
+	senders     map[string]push.Sender
+	clock       clock.Clock
+	queue       chan *events.Event
+}
+
+// NewPushFanOut creates a fan-out sending through the senders of each platform, queueing up
+// to queueSize events
+func NewPushFanOut(pushRepo models.PushRepository, postRepo models.PostRepository, commentRepo models.CommentRepository, userRepo models.UserRepository, senders map[string]push.Sender, queueSize int, clk clock.Clock) *PushFanOut {
+	if queueSize < 1 {
+		queueSize = DefaultPushQueueSize
+	}
+	return &PushFanOut{
+		pushRepo:    pushRepo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		userRepo:    userRepo,
+		senders:     senders,
+		clock:       clock.OrReal(clk),
+		queue:       make(chan *events.Event, queueSize),
+	}
+}
+
+// HandleEvent queues a PostPublished or CommentCreated event without blocking; it is
+// subscribed to the event bus
+func (f *PushFanOut) HandleEvent(event *events.Event) {
+	if event.Type != events.PostPublished && event.Type != events.CommentCreated {
+		return
+	}
+	select {
+	case f.queue <- event:
+	default:
+		log.Printf("Push queue is full, dropping notifications of %s", event.Type)
+	}
+}
+
+// Start processes queued events in the background until stop is closed
+func (f *PushFanOut) Start(stop <-chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			case event := <-f.queue:
+				if err := f.process(event); err != nil {
+					log.Printf("Failed to send push notifications of %s: %v", event.Type, err)
+				}
+			}
+		}
+	}()
+}
+
+// process notifies the subscribers of the topic an event belongs to
+func (f *PushFanOut) process(event *events.Event) error {
+	switch data := event.Data.(type) {
+	case *events.PostPublication:
+		return f.postPublished(data)
+	case *events.CommentCreation:
+		return f.commentCreated(data)
+	}
+	return nil
+}
+
+// postPublished notifies the subscribers of an author of a post they published, if it is still
+// published and listed to them: public posts to every subscriber, followers-only posts to the
+// subscribers following the author. Unlisted and private posts are not notified.
+func (f *PushFanOut) postPublished(data *events.PostPublication) error {
+	post, err := f.livePost(data.PostID)
+	if post == nil || err != nil {
+		return err
+	}
+	if post.Visibility != models.PostVisibilityPublic && post.Visibility != models.PostVisibilityFollowers {
+		return nil
+	}
+	author, err := f.userRepo.GetByID(post.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to get author: %w", err)
+	}
+
+	topic := models.PushTopic(models.PushTopicAuthor, post.AuthorID)
+	return f.notify(topic, post, author.ID, &push.Notification{
+		Title:       author.Username + " published a new post",
+		Body:        post.Title,
+		CollapseKey: topic,
+		Data:        map[string]string{"type": events.PostPublished, "topic": topic, "post_id": post.ID.String()},
+	})
+}
+
+// commentCreated notifies the subscribers of a post of a comment written on it, if the post is
+// still published and the subscriber may read it
+func (f *PushFanOut) commentCreated(data *events.CommentCreation) error {
+	post, err := f.livePost(data.PostID)
+	if post == nil || err != nil {
+		return err
+	}
+	comment, err := f.commentRepo.GetByID(data.CommentID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get comment: %w", err)
+	}
+	author, err := f.userRepo.GetByID(comment.AuthorID)
+	if err != nil {
+		return fmt.Errorf("failed to get comment author: %w", err)
+	}
+
+	topic := models.PushTopic(models.PushTopicPost, post.ID)
+	return f.notify(topic, post, author.ID, &push.Notification{
+		Title:       author.Username + " commented on " + post.Title,
+		Body:        text.Excerpt(comment.Content, pushExcerptWords),
+		CollapseKey: topic,
+		Data: map[string]string{
+			"type":       events.CommentCreated,
+			"topic":      topic,
+			"post_id":    post.ID.String(),
+			"comment_id": comment.ID.String(),
+		},
+	})
+}
+
+// livePost gets a post that is published and not archived, or nil when it no longer is
+func (f *PushFanOut) livePost(id uuid.UUID) (*models.Post, error) {
+	post, err := f.postRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if !post.IsPublished || post.IsArchived() {
+		return nil, nil
+	}
+	return post, nil
+}
+
+// notify sends n to the subscribers of a topic who may read post, except the user who caused
+// it. A failure for one subscriber is logged and the next one is notified.
+func (f *PushFanOut) notify(topic string, post *models.Post, causedBy uuid.UUID, n *push.Notification) error {
+	after := uuid.Nil
+	for {
+		subscribers, err := f.pushRepo.ListSubscribers(topic, after, pushSubscriberPage)
+		if err != nil {
+			return err
+		}
+		for _, userID := range subscribers {
+			if userID == causedBy {
+				continue
+			}
+			if err := checkVisible(f.postRepo, post, userID); err != nil {
+				if !errors.Is(err, errors.ErrPostNotFound) {
+					log.Printf("Failed to check whether user %s may read post %s: %v", userID, post.ID, err)
+				}
+				continue
+			}
+			if err := f.deliver(userID, n); err != nil {
+				log.Printf("Failed to push %s to user %s: %v", topic, userID, err)
+			}
+		}
+		if len(subscribers) < pushSubscriberPage {
+			return nil
+		}
+		after = subscribers[len(subscribers)-1]
+	}
+}
+
+// deliver sends a notification to the devices of an active user, or holds it until their
+// quiet hours end
+func (f *PushFanOut) deliver(userID uuid.UUID, n *push.Notification) error {
+	user, err := f.userRepo.GetByID(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !user.IsActive {
+		return nil
+	}
+
+	devices, err := f.pushRepo.ListDevices(userID)
+	if err != nil || len(devices) == 0 {
+		return err
+	}
+
+	now := f.clock.Now()
+	if releaseAt, quiet := f.quietUntil(userID, now); quiet {
+		return f.pushRepo.Hold(&models.HeldPushNotification{
+			UserID:      userID,
+			CollapseKey: n.CollapseKey,
+			Title:       n.Title,
+			Body:        n.Body,
+			Data:        n.Data,
+			ReleaseAt:   releaseAt,
+			CreatedAt:   now,
+		})
+	}
+
+	f.send(devices, n)
+	return nil
+}
+
+// send sends a notification to each device through the sender of its platform, forgetting
+// devices whose token the push service no longer knows
+func (f *PushFanOut) send(devices []*models.PushDevice, n *push.Notification) {
+	for _, device := range devices {
+		sender := f.senders[device.Platform]
+		if sender == nil {
+			continue
+		}
+		message := *n
+		message.Token = device.Token
+
+		err := sender.Send(&message)
+		if errors.Is(err, push.ErrUnregistered) {
+			if err := f.pushRepo.DeleteDeviceByToken(device.Token); err != nil {
+				log.Printf("Failed to forget unregistered push device %s: %v", device.ID, err)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to push to device %s through %s: %v", device.ID, sender.Name(), err)
+		}
+	}
+}
+
+// quietUntil returns when the quiet hours a user is in at now end, and false when they are not
+// in quiet hours. Quiet hours that cannot be read are not applied, so notifications are sent.
+func (f *PushFanOut) quietUntil(userID uuid.UUID, now time.Time) (time.Time, bool) {
+	quiet, err := f.pushRepo.GetQuietHours(userID)
+	if err != nil {
+		if !errors.Is(err, models.ErrNotFound) {
+			log.Printf("Failed to get the quiet hours of user %s: %v", userID, err)
+		}
+		return time.Time{}, false
+	}
+
+	loc := time.UTC
+	if prefs, err := f.userRepo.GetPreferences(userID); err != nil {
+		log.Printf("Failed to get the timezone of user %s, using UTC: %v", userID, err)
+	} else {
+		loc = prefs.Location()
+	}
+
+	return quietHoursEnd(quiet, now.In(loc))
+}
+
+// quietHoursEnd returns when the quiet hours the local time is in end, in its location, and
+// false when it is outside of them
+func quietHoursEnd(quiet *models.QuietHours, local time.Time) (time.Time, bool) {
+	start, err := time.Parse("15:04", quiet.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := time.Parse("15:04", quiet.End)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	minute := local.Hour()*60 + local.Minute()
+	from, to := start.Hour()*60+start.Minute(), end.Hour()*60+end.Minute()
+	if from < to && (minute < from || minute >= to) || from > to && minute < from && minute >= to {
+		return time.Time{}, false
+	}
+
+	// Quiet hours spanning midnight that started today end tomorrow
+	day := local
+	if minute >= to {
+		day = local.AddDate(0, 0, 1)
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), end.Hour(), end.Minute(), 0, 0, local.Location()), true
+}
+
+// SendHeld sends the notifications held during quiet hours that have ended. It runs as a
+// background job, so held notifications go out within an interval of the end of the quiet hours.
+func (f *PushFanOut) SendHeld() error {
+	sent := 0
+	for {
+		held, err := f.pushRepo.TakeHeldDue(f.clock.Now(), pushHeldBatch)
+		if err != nil {
+			return err
+		}
+		for _, n := range held {
+			devices, err := f.pushRepo.ListDevices(n.UserID)
+			if err != nil {
+				log.Printf("Failed to get the push devices of user %s: %v", n.UserID, err)
+				continue
+			}
+			f.send(devices, &push.Notification{Title: n.Title, Body: n.Body, CollapseKey: n.CollapseKey, Data: n.Data})
+			sent++
+		}
+		if len(held) < pushHeldBatch {
+			break
+		}
+	}
+	if sent > 0 {
+		log.Printf("Sent %d push notifications held during quiet hours", sent)
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"log"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used when the push settings are not configured
+const (
+	DefaultPushMaxDevices = 10
+	DefaultPushMaxTopics  = 200
+)
+
+// PushServiceConfig holds the limits of the push service
+type PushServiceConfig struct {
+	// MaxDevices is how many devices a user receives notifications on; registering another
+	// forgets the least recently registered
+	MaxDevices int
+	// MaxTopics is how many topics a user may subscribe to
+	MaxTopics int
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// pushService implements PushService interface
+type pushService struct {
+	pushRepo  models.PushRepository
+	postRepo  models.PostRepository
+	userRepo  models.UserRepository
+	validator *validation.Validator
+	cfg       PushServiceConfig
+}
+
+// NewPushService creates a new push service
+func NewPushService(pushRepo models.PushRepository, postRepo models.PostRepository, userRepo models.UserRepository, cfg PushServiceConfig) models.PushService {
+	if cfg.MaxDevices <= 0 {
+		cfg.MaxDevices = DefaultPushMaxDevices
+	}
+	if cfg.MaxTopics <= 0 {
+		cfg.MaxTopics = DefaultPushMaxTopics
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	return &pushService{
+		pushRepo:  pushRepo,
+		postRepo:  postRepo,
+		userRepo:  userRepo,
+		validator: validation.NewValidator(),
+		cfg:       cfg,
+	}
+}
+
+// RegisterDevice registers a device of the user for push notifications. Registering a token
+// again refreshes it, and a token registered by another account on the same installation
+// moves to this user.
+func (s *pushService) RegisterDevice(userID uuid.UUID, req *models.RegisterDeviceRequest) (*models.PushDevice, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	now := s.cfg.Clock.Now()
+	device := &models.PushDevice{
+		UserID:    userID,
+		Platform:  req.Platform,
+		Token:     req.Token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.pushRepo.SaveDevice(device); err != nil {
+		return nil, err
+	}
+
+	if err := s.pushRepo.TrimDevices(userID, s.cfg.MaxDevices); err != nil {
+		log.Printf("Failed to trim the push devices of user %s: %v", userID, err)
+	}
+
+	return device, nil
+}
+
+// ListDevices lists the devices of the user, most recently registered first
+func (s *pushService) ListDevices(userID uuid.UUID) ([]*models.PushDevice, error) {
+	return s.pushRepo.ListDevices(userID)
+}
+
+// DeleteDevice stops push notifications to a device of the user
+func (s *pushService) DeleteDevice(userID, id uuid.UUID) error {
+	if err := s.pushRepo.DeleteDevice(userID, id); err != nil {
+		return writeError(err, errors.ErrPushDeviceNotFound, "Failed to delete push device")
+	}
+	return nil
+}
+
+// ListTopics lists the topics the user is subscribed to, newest first
+func (s *pushService) ListTopics(userID uuid.UUID) ([]*models.PushSubscription, error) {
+	return s.pushRepo.ListSubscriptions(userID)
+}
+
+// Subscribe subscribes the user to the posts of an author or the comments of a post they may
+// read. Subscribing again keeps the subscription.
+func (s *pushService) Subscribe(userID uuid.UUID, topic string) (*models.PushSubscription, error) {
+	kind, id, ok := models.ParsePushTopic(topic)
+	if !ok {
+		return nil, errors.ErrPushTopicInvalid
+	}
+	topic = models.PushTopic(kind, id)
+
+	switch kind {
+	case models.PushTopicAuthor:
+		if _, err := s.userRepo.GetByID(id); err != nil {
+			if errors.Is(err, models.ErrNotFound) {
+				return nil, errors.ErrUserNotFound
+			}
+			return nil, errors.WrapError(err, "Failed to get author")
+		}
+	case models.PushTopicPost:
+		if _, err := visiblePost(s.postRepo, id, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	count, err := s.pushRepo.CountSubscriptions(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= s.cfg.MaxTopics {
+		subs, err := s.pushRepo.ListSubscriptions(userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, sub := range subs {
+			if sub.Topic == topic {
+				return sub, nil
+			}
+		}
+		return nil, errors.ErrPushTopicLimit
+	}
+
+	sub := &models.PushSubscription{UserID: userID, Topic: topic, CreatedAt: s.cfg.Clock.Now()}
+	if err := s.pushRepo.Subscribe(sub); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to subscribe to push topic")
+	}
+
+	return sub, nil
+}
+
+// Unsubscribe unsubscribes the user from a topic; unsubscribing from a topic the user is not
+// subscribed to succeeds
+func (s *pushService) Unsubscribe(userID uuid.UUID, topic string) error {
+	kind, id, ok := models.ParsePushTopic(topic)
+	if !ok {
+		return errors.ErrPushTopicInvalid
+	}
+	return s.pushRepo.Unsubscribe(userID, models.PushTopic(kind, id))
+}
+
+// GetQuietHours gets the quiet hours of the user and the timezone they are read in
+func (s *pushService) GetQuietHours(userID uuid.UUID) (*models.QuietHoursSettings, error) {
+	prefs, err := s.userRepo.GetPreferences(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get preferences")
+	}
+	settings := &models.QuietHoursSettings{Timezone: prefs.Location().String()}
+
+	quiet, err := s.pushRepo.GetQuietHours(userID)
+	if errors.Is(err, models.ErrNotFound) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	settings.Enabled, settings.Start, settings.End = true, quiet.Start, quiet.End
+	return settings, nil
+}
+
+// UpdateQuietHours sets the quiet hours of the user, or turns them off when both times are empty
+func (s *pushService) UpdateQuietHours(userID uuid.UUID, req *models.UpdateQuietHoursRequest) (*models.QuietHoursSettings, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	if req.Start == "" {
+		if err := s.pushRepo.DeleteQuietHours(userID); err != nil {
+			return nil, err
+		}
+		return s.GetQuietHours(userID)
+	}
+
+	quiet := &models.QuietHours{UserID: userID, Start: req.Start, End: req.End, UpdatedAt: s.cfg.Clock.Now()}
+	if err := s.pushRepo.SaveQuietHours(quiet); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to save quiet hours")
+	}
+
+	return s.GetQuietHours(userID)
+}
@@ -0,0 +1,143 @@
+package services
+
+import (
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// errNoReaction is returned when removing a reaction the user never made
+var errNoReaction = errors.NewErrorWithCode(404, "No reaction to remove")
+
+// reactionService implements ReactionService interface
+type reactionService struct {
+	reactionRepo models.ReactionRepository
+	postRepo     models.PostRepository
+	commentRepo  models.CommentRepository
+	validator    *validation.Validator
+}
+
+// NewReactionService creates a new reaction service
+func NewReactionService(reactionRepo models.ReactionRepository, postRepo models.PostRepository, commentRepo models.CommentRepository) models.ReactionService {
+	return &reactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		validator:    validation.NewValidator(),
+	}
+}
+
+// ReactToPost sets the user's reaction to a post, replacing their previous one
+func (s *reactionService) ReactToPost(postID, userID uuid.UUID, req *models.ReactionRequest) (models.ReactionCounts, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+	if err := s.checkPost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.SetForPost(userID, postID, req.Reaction); err != nil {
+		return nil, errors.WrapError(err, "Failed to react to post")
+	}
+
+	return s.postCounts(postID)
+}
+
+// UnreactToPost removes the user's reaction to a post
+func (s *reactionService) UnreactToPost(postID, userID uuid.UUID) (models.ReactionCounts, error) {
+	if err := s.checkPost(postID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.RemoveForPost(userID, postID); err != nil {
+		return nil, writeError(err, errNoReaction, "Failed to remove reaction")
+	}
+
+	return s.postCounts(postID)
+}
+
+// ReactToComment sets the user's reaction to a comment, replacing their previous one
+func (s *reactionService) ReactToComment(postID, commentID, userID uuid.UUID, req *models.ReactionRequest) (models.ReactionCounts, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+	if err := s.checkComment(postID, commentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.SetForComment(userID, commentID, req.Reaction); err != nil {
+		return nil, errors.WrapError(err, "Failed to react to comment")
+	}
+
+	return s.commentCounts(commentID)
+}
+
+// UnreactToComment removes the user's reaction to a comment
+func (s *reactionService) UnreactToComment(postID, commentID, userID uuid.UUID) (models.ReactionCounts, error) {
+	if err := s.checkComment(postID, commentID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.RemoveForComment(userID, commentID); err != nil {
+		return nil, writeError(err, errNoReaction, "Failed to remove reaction")
+	}
+
+	return s.commentCounts(commentID)
+}
+
+// checkPost verifies that the user can react on a post: visible to them and not archived
+func (s *reactionService) checkPost(postID, userID uuid.UUID) error {
+	post, err := visiblePost(s.postRepo, postID, userID)
+	if err != nil {
+		return err
+	}
+	if post.IsArchived() {
+		return errors.ErrPostArchived
+	}
+	return nil
+}
+
+// checkComment verifies that a comment belongs to a post the user can react on
+func (s *reactionService) checkComment(postID, commentID, userID uuid.UUID) error {
+	if err := s.checkPost(postID, userID); err != nil {
+		return err
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if errors.Is(err, models.ErrNotFound) || (err == nil && comment.PostID != postID) {
+		return errors.ErrCommentNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get comment")
+	}
+
+	return nil
+}
+
+// postCounts gets the reaction counts of a post
+func (s *reactionService) postCounts(postID uuid.UUID) (models.ReactionCounts, error) {
+	counts, err := s.reactionRepo.CountForPosts([]uuid.UUID{postID})
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to count reactions")
+	}
+	return nonNilCounts(counts[postID]), nil
+}
+
+// commentCounts gets the reaction counts of a comment
+func (s *reactionService) commentCounts(commentID uuid.UUID) (models.ReactionCounts, error) {
+	counts, err := s.reactionRepo.CountForComments([]uuid.UUID{commentID})
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to count reactions")
+	}
+	return nonNilCounts(counts[commentID]), nil
+}
+
+// nonNilCounts returns empty counts instead of nil, so responses render {} rather than null
+func nonNilCounts(counts models.ReactionCounts) models.ReactionCounts {
+	if counts == nil {
+		return models.ReactionCounts{}
+	}
+	return counts
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"encoding/hex"
+	"log"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// sandboxPurgeBatch is how many expired sandbox accounts are deleted per query
+const sandboxPurgeBatch = 100
+
+// CreateSandboxUser creates a throwaway account with the sandbox role for the API console
+// demo mode and signs it in. Its password is random and never disclosed, so the account is
+// only usable with the tokens returned, until PurgeSandboxUsers deletes it.
+func (s *userService) CreateSandboxUser(client models.ClientInfo) (*models.LoginResponse, error) {
+	suffix := uuid.New()
+	username := "sandbox_" + hex.EncodeToString(suffix[:6])
+
+	password, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate password")
+	}
+	hashedPassword, err := s.hasher.Generate(password, bcrypt.DefaultCost)
+	if err != nil {
+		return nil, hashError(err, "Failed to hash password")
+	}
+
+	now := s.cfg.Clock.Now()
+	user := &models.User{
+		Username:  username,
+		Email:     username + "@" + models.SandboxEmailDomain,
+		Password:  hashedPassword,
+		Role:      models.RoleSandbox,
+		IsActive:  true,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, errors.WrapError(err, "Failed to create sandbox user")
+	}
+
+	loginResp, err := s.issueTokens(user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Created sandbox user %s for %s", user.ID, client.IPAddress)
+	return loginResp, nil
+}
+
+// PurgeSandboxUsers deletes the sandbox accounts created before cutoff with their posts and
+// sessions. Accounts under legal hold are kept.
+func (s *userService) PurgeSandboxUsers(cutoff time.Time) (int, error) {
+	purged := 0
+	for {
+		ids, err := s.userRepo.ListIDsByRoleCreatedBefore(models.RoleSandbox, cutoff, sandboxPurgeBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		deleted := 0
+		for _, id := range ids {
+			if err := s.legalHold.check(id, legalHoldActionDeleteAccount, "", models.ClientInfo{}); err != nil {
+				continue
+			}
+			err := s.userRepo.DeleteCascade(&models.UserDeletion{
+				UserID:      id,
+				Reason:      "sandbox",
+				PostsPolicy: models.DeletedUserPostsDelete,
+			}, models.ClientInfo{})
+			if err != nil && !errors.Is(err, models.ErrNotFound) {
+				return purged, err
+			}
+			deleted++
+		}
+		purged += deleted
+
+		// A short batch is the last one; a batch of only held accounts would repeat forever
+		if len(ids) < sandboxPurgeBatch || deleted == 0 {
+			return purged, nil
+		}
+	}
+}
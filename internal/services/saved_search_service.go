@@ -0,0 +1,335 @@
+package services
+
+import (
+	"log"
+	"net/url"
+	"time"
+
+	"go-backend-api/internal/mailer"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/shortid"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// Defaults used when the saved search settings are not configured
+const (
+	DefaultSavedSearchLimit      = 20
+	DefaultSavedSearchAlertPosts = 10
+)
+
+// savedSearchBatchSize is how many due searches an alert run loads at a time
+const savedSearchBatchSize = 100
+
+// SavedSearchServiceConfig holds the limits and alert settings of the saved search service
+type SavedSearchServiceConfig struct {
+	// BaseURL is the external base URL used to build links in emails
+	BaseURL string
+	// MaxPerUser is how many searches a user may save when their plan does not say
+	MaxPerUser int
+	// AlertPosts is how many matching posts an alert lists; it only mentions there are more
+	AlertPosts int
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// savedSearchService implements SavedSearchService interface
+type savedSearchService struct {
+	savedSearchRepo models.SavedSearchRepository
+	userRepo        models.UserRepository
+	plans           models.PlanService
+	mailer          mailer.Mailer
+	validator       *validation.Validator
+	cfg             SavedSearchServiceConfig
+}
+
+// NewSavedSearchService creates a new saved search service. Users may save as many searches
+// as their plan allows.
+func NewSavedSearchService(savedSearchRepo models.SavedSearchRepository, userRepo models.UserRepository, plans models.PlanService, mailer mailer.Mailer, cfg SavedSearchServiceConfig) models.SavedSearchService {
+	if cfg.MaxPerUser <= 0 {
+		cfg.MaxPerUser = DefaultSavedSearchLimit
+	}
+	if cfg.AlertPosts <= 0 {
+		cfg.AlertPosts = DefaultSavedSearchAlertPosts
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	return &savedSearchService{
+		savedSearchRepo: savedSearchRepo,
+		userRepo:        userRepo,
+		plans:           plans,
+		mailer:          mailer,
+		validator:       validation.NewValidator(),
+		cfg:             cfg,
+	}
+}
+
+// CreateSavedSearch saves a search for a user. Only posts published from now on are alerted.
+func (s *savedSearchService) CreateSavedSearch(userID uuid.UUID, req *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	if req.Frequency == "" {
+		req.Frequency = models.SavedSearchDaily
+	}
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	limits, err := s.plans.GetUserLimits(userID)
+	if err != nil {
+		return nil, err
+	}
+	maxPerUser := limits.SavedSearches
+	if maxPerUser <= 0 {
+		maxPerUser = s.cfg.MaxPerUser
+	}
+
+	count, err := s.savedSearchRepo.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxPerUser {
+		return nil, errors.ErrSavedSearchLimit
+	}
+
+	now := s.cfg.Clock.Now()
+	search := &models.SavedSearch{
+		UserID:    userID,
+		Name:      req.Name,
+		Query:     req.Query,
+		Frequency: req.Frequency,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.restart(search, now)
+
+	if err := s.savedSearchRepo.Create(search); err != nil {
+		return nil, errors.WrapError(err, "Failed to create saved search")
+	}
+
+	return search, nil
+}
+
+// ListSavedSearches lists the saved searches of a user
+func (s *savedSearchService) ListSavedSearches(userID uuid.UUID) ([]*models.SavedSearch, error) {
+	searches, err := s.savedSearchRepo.ListByUser(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list saved searches")
+	}
+
+	return searches, nil
+}
+
+// UpdateSavedSearch changes one of a user's saved searches. Changing the query or turning
+// alerts back on starts over from now, so posts published before are not alerted.
+func (s *savedSearchService) UpdateSavedSearch(id, userID uuid.UUID, req *models.UpdateSavedSearchRequest) (*models.SavedSearch, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	search, err := s.getOwnSearch(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.cfg.Clock.Now()
+	restart := false
+	if req.Name != "" {
+		search.Name = req.Name
+	}
+	if req.Query != "" && req.Query != search.Query {
+		search.Query = req.Query
+		restart = true
+	}
+	if req.Frequency != "" && req.Frequency != search.Frequency {
+		restart = restart || search.Frequency == models.SavedSearchOff
+		search.Frequency = req.Frequency
+		if !restart {
+			// Keep the posts since the last check, just send them on the new schedule
+			period, _ := models.SavedSearchPeriod(search.Frequency)
+			search.NextCheckAt = search.CheckedAt.Add(period)
+		}
+	}
+	if restart {
+		s.restart(search, now)
+	}
+	search.UpdatedAt = now
+
+	if err := s.savedSearchRepo.Update(search); err != nil {
+		return nil, writeError(err, errors.ErrSavedSearchNotFound, "Failed to update saved search")
+	}
+
+	return search, nil
+}
+
+// DeleteSavedSearch deletes one of a user's saved searches
+func (s *savedSearchService) DeleteSavedSearch(id, userID uuid.UUID) error {
+	if _, err := s.getOwnSearch(id, userID); err != nil {
+		return err
+	}
+
+	if err := s.savedSearchRepo.Delete(id); err != nil {
+		return writeError(err, errors.ErrSavedSearchNotFound, "Failed to delete saved search")
+	}
+
+	return nil
+}
+
+// Unsubscribe turns off the alerts of the search whose latest alert carried the token, keeping
+// the search itself. Links in earlier alerts stop working once a newer alert is sent.
+func (s *savedSearchService) Unsubscribe(token string) error {
+	search, err := s.savedSearchRepo.GetByUnsubscribeTokenHash(auth.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrInvalidUnsubscribeToken
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get saved search")
+	}
+	if search.Frequency == models.SavedSearchOff {
+		return nil
+	}
+
+	search.Frequency = models.SavedSearchOff
+	search.UpdatedAt = s.cfg.Clock.Now()
+	if err := s.savedSearchRepo.Update(search); err != nil {
+		return writeError(err, errors.ErrInvalidUnsubscribeToken, "Failed to unsubscribe from saved search")
+	}
+
+	return nil
+}
+
+// SendAlerts emails the users of due searches the posts published since the search was last
+// checked that match it and that they may see. Searches without new matches are checked again
+// a period later without an email. A failure for one search is logged and the next one is
+// tried; it is retried on the next run.
+func (s *savedSearchService) SendAlerts() error {
+	now := s.cfg.Clock.Now()
+	sent := 0
+	for {
+		searches, err := s.savedSearchRepo.ListDue(now, savedSearchBatchSize)
+		if err != nil {
+			return errors.WrapError(err, "Failed to list due saved searches")
+		}
+
+		failed := 0
+		for _, search := range searches {
+			alerted, err := s.alert(search, now)
+			if err != nil {
+				log.Printf("Failed to send alert for saved search %s: %v", search.ID, err)
+				failed++
+				continue
+			}
+			if alerted {
+				sent++
+			}
+		}
+
+		// Failed searches stay due; stop rather than load them again
+		if len(searches) < savedSearchBatchSize || failed == len(searches) {
+			break
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("Sent %d saved search alerts", sent)
+	}
+	return nil
+}
+
+// alert checks one due search, mailing its new matches to its user, and reports whether
+// an email was sent
+func (s *savedSearchService) alert(search *models.SavedSearch, now time.Time) (bool, error) {
+	user, err := s.userRepo.GetByID(search.UserID)
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to get user")
+	}
+
+	var posts []*models.Post
+	if user.IsActive {
+		// One extra post tells whether there are more than the alert lists
+		posts, err = s.savedSearchRepo.Matches(search, now, s.cfg.AlertPosts+1)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if len(posts) > 0 {
+		token, err := auth.GenerateOpaqueToken()
+		if err != nil {
+			return false, errors.WrapError(err, "Failed to generate unsubscribe token")
+		}
+		if err := s.send(user, search, posts, token); err != nil {
+			return false, err
+		}
+		hash := auth.HashToken(token)
+		search.UnsubscribeTokenHash = &hash
+	}
+
+	search.CheckedAt = now
+	period, _ := models.SavedSearchPeriod(search.Frequency)
+	search.NextCheckAt = now.Add(period)
+	if err := s.savedSearchRepo.Update(search); err != nil {
+		return false, errors.WrapError(err, "Failed to update saved search")
+	}
+
+	return len(posts) > 0, nil
+}
+
+// send mails an alert listing matching posts, noting when there are more than the alert's limit
+func (s *savedSearchService) send(user *models.User, search *models.SavedSearch, posts []*models.Post, token string) error {
+	more := len(posts) > s.cfg.AlertPosts
+	if more {
+		posts = posts[:s.cfg.AlertPosts]
+	}
+
+	items := make([]map[string]string, len(posts))
+	for i, post := range posts {
+		items[i] = map[string]string{
+			"Title": post.Title,
+			"URL":   s.cfg.BaseURL + "/api/v1/p/" + shortid.Encode(post.Number),
+		}
+	}
+
+	msg, err := mailer.Render("saved_search_alert", user.Email, map[string]interface{}{
+		"Username":       user.Username,
+		"Name":           search.Name,
+		"Query":          search.Query,
+		"Count":          len(posts),
+		"Posts":          items,
+		"More":           more,
+		"Frequency":      search.Frequency,
+		"UnsubscribeURL": s.cfg.BaseURL + "/api/v1/public/saved-searches/unsubscribe?token=" + url.QueryEscape(token),
+	})
+	if err != nil {
+		return errors.WrapError(err, "Failed to render email")
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return errors.WrapError(err, "Failed to send email")
+	}
+
+	return nil
+}
+
+// getOwnSearch gets a saved search of the user, reporting other users' searches as not found
+func (s *savedSearchService) getOwnSearch(id, userID uuid.UUID) (*models.SavedSearch, error) {
+	search, err := s.savedSearchRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrSavedSearchNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get saved search")
+	}
+	if search.UserID != userID {
+		return nil, errors.ErrSavedSearchNotFound
+	}
+
+	return search, nil
+}
+
+// restart makes a search look for posts published from now on, due a period later
+func (s *savedSearchService) restart(search *models.SavedSearch, now time.Time) {
+	period, _ := models.SavedSearchPeriod(search.Frequency)
+	search.CheckedAt = now
+	search.NextCheckAt = now.Add(period)
+}
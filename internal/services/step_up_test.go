@@ -0,0 +1,71 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// fakeLoginChallengeRepo keeps a single challenge, counting attempts atomically like the
+// login_challenges table
+type fakeLoginChallengeRepo struct {
+	models.LoginChallengeRepository
+	mu        sync.Mutex
+	challenge models.LoginChallenge
+}
+
+func (r *fakeLoginChallengeRepo) GetByID(id uuid.UUID) (*models.LoginChallenge, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.challenge.ID {
+		return nil, models.ErrNotFound
+	}
+	challenge := r.challenge
+	return &challenge, nil
+}
+
+func (r *fakeLoginChallengeRepo) UseAttempt(id uuid.UUID, maxAttempts int, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id != r.challenge.ID || r.challenge.Attempts >= maxAttempts || r.challenge.ConsumedAt != nil || !now.Before(r.challenge.ExpiresAt) {
+		return false, nil
+	}
+	r.challenge.Attempts++
+	return true, nil
+}
+
+func TestParallelStepUpGuessesStayWithinTheAttemptLimit(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Role: models.RoleUser, IsActive: true}
+	challenges := &fakeLoginChallengeRepo{challenge: models.LoginChallenge{
+		ID: uuid.New(), UserID: user.ID, CodeHash: auth.HashToken("123456"), ExpiresAt: time.Now().Add(time.Hour),
+	}}
+	svc := NewUserService(newFakeUserRepo(nil, user), &fakeRefreshTokenRepo{}, nil, nil, nil, &fakeAuditLogRepo{}, challenges, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		UserServiceConfig{StepUpMaxAttempts: 5})
+
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			code := "00000" + string(rune('0'+i%10))
+			if _, err := svc.VerifyLogin(&models.VerifyLoginRequest{ChallengeID: challenges.challenge.ID, Code: code}); err != errors.ErrInvalidLoginChallenge {
+				t.Errorf("wrong code: got %v, want ErrInvalidLoginChallenge", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := challenges.challenge.Attempts; got != 5 {
+		t.Errorf("counted %d attempts, want the limit of 5", got)
+	}
+	// Once the attempts are used up, the right code is refused too
+	if _, err := svc.VerifyLogin(&models.VerifyLoginRequest{ChallengeID: challenges.challenge.ID, Code: "123456"}); err != errors.ErrInvalidLoginChallenge {
+		t.Errorf("right code after the limit: got %v, want ErrInvalidLoginChallenge", err)
+	}
+}
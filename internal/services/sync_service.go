@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/base64"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+// syncService implements SyncService interface
+type syncService struct {
+	syncRepo   models.SyncRepository
+	tombstones models.TombstoneService
+}
+
+// NewSyncService creates a new sync service
+func NewSyncService(syncRepo models.SyncRepository, tombstones models.TombstoneService) models.SyncService {
+	return &syncService{syncRepo: syncRepo, tombstones: tombstones}
+}
+
+// PostChanges lists the post changes after since, sorted into created, updated and deleted.
+// The repository is asked for one change more than limit to tell whether more are waiting.
+func (s *syncService) PostChanges(since string, viewerID uuid.UUID, limit int) (*models.SyncChanges, error) {
+	checkpoint, err := parseSyncCheckpoint(since)
+	if err != nil {
+		return nil, err
+	}
+	// Deletions before the horizon were pruned, so the changes would silently miss them
+	if since != "" && checkpoint.At.Before(s.tombstones.Horizon()) {
+		return nil, errors.ErrSyncCheckpointExpired
+	}
+
+	changes, err := s.syncRepo.PostChanges(checkpoint, viewerID, limit+1)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get post changes")
+	}
+
+	result := &models.SyncChanges{
+		Created: []uuid.UUID{},
+		Updated: []uuid.UUID{},
+		Deleted: []uuid.UUID{},
+	}
+	if len(changes) > limit {
+		changes, result.HasMore = changes[:limit], true
+	}
+	sinceAt := checkpoint.At
+	for _, change := range changes {
+		switch {
+		case !change.Visible:
+			result.Deleted = append(result.Deleted, change.ID)
+		case change.CreatedAt != nil && change.CreatedAt.After(sinceAt):
+			result.Created = append(result.Created, change.ID)
+		default:
+			result.Updated = append(result.Updated, change.ID)
+		}
+		checkpoint = models.SyncCheckpoint{At: change.ChangedAt, ID: change.ID}
+	}
+	result.Cursor = encodeSyncCursor(checkpoint)
+
+	return result, nil
+}
+
+// parseSyncCheckpoint reads since as an RFC 3339 timestamp or a cursor
+func parseSyncCheckpoint(since string) (models.SyncCheckpoint, error) {
+	if since == "" {
+		return models.SyncCheckpoint{}, nil
+	}
+	if at, err := time.Parse(time.RFC3339Nano, since); err == nil {
+		return models.SyncCheckpoint{At: at.UTC()}, nil
+	}
+
+	invalid := errors.NewInvalidParamError("since", "since must be an RFC 3339 timestamp or a cursor returned by an earlier sync")
+	raw, err := base64.RawURLEncoding.DecodeString(since)
+	if err != nil {
+		return models.SyncCheckpoint{}, invalid
+	}
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return models.SyncCheckpoint{}, invalid
+	}
+	checkpoint := models.SyncCheckpoint{}
+	if checkpoint.At, err = time.Parse(time.RFC3339Nano, at); err != nil {
+		return models.SyncCheckpoint{}, invalid
+	}
+	if checkpoint.ID, err = uuid.Parse(id); err != nil {
+		return models.SyncCheckpoint{}, invalid
+	}
+	return checkpoint, nil
+}
+
+// encodeSyncCursor encodes a checkpoint as an opaque cursor
+func encodeSyncCursor(checkpoint models.SyncCheckpoint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(checkpoint.At.UTC().Format(time.RFC3339Nano) + "|" + checkpoint.ID.String()))
+}
@@ -0,0 +1,227 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/validation"
+
+	"github.com/google/uuid"
+)
+
+// DefaultIncidentAccessTokenTTL is the lifetime of access tokens issued in incident mode
+const DefaultIncidentAccessTokenTTL = 5 * time.Minute
+
+// TokenRevocationConfig holds incident response settings
+type TokenRevocationConfig struct {
+	// IncidentAccessTokenTTL is the lifetime of access tokens issued in incident mode
+	// (DefaultIncidentAccessTokenTTL when not positive)
+	IncidentAccessTokenTTL time.Duration
+	// IncidentMode starts the service in incident mode, without revoking any token; the first
+	// Refresh stores it as enabled for every instance
+	IncidentMode bool
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
+// tokenRevocationDetails is the audit log detail payload of bulk token revocations
+type tokenRevocationDetails struct {
+	UserIDs  []uuid.UUID `json:"user_ids,omitempty"` // Empty when every user's tokens were revoked
+	Cutoff   time.Time   `json:"cutoff"`
+	Users    int64       `json:"users"`
+	Sessions int64       `json:"sessions"`
+	Clients  int64       `json:"clients"`
+	// APIKeysRevoked is always false: bulk revocations leave API keys alone, and they are
+	// revoked individually
+	APIKeysRevoked bool `json:"api_keys_revoked"`
+}
+
+// tokenRevocationService implements TokenRevocationService interface
+type tokenRevocationService struct {
+	userRepo         models.UserRepository
+	refreshTokenRepo models.RefreshTokenRepository
+	clientRepo       models.OAuthClientRepository
+	incidentRepo     models.IncidentModeRepository
+	jwtMgr           *auth.JWTManager
+	audit            *auditRecorder
+	validator        *validation.Validator
+	cfg              TokenRevocationConfig
+
+	mutex     sync.Mutex
+	incident  models.IncidentMode
+	refreshed bool // Whether the stored state was loaded once
+}
+
+// NewTokenRevocationService creates a new token revocation service. Incident mode is stored in
+// incidentRepo and shared by every instance; call Refresh to load it, and periodically to pick up
+// changes made on other instances.
+func NewTokenRevocationService(userRepo models.UserRepository, refreshTokenRepo models.RefreshTokenRepository, clientRepo models.OAuthClientRepository, incidentRepo models.IncidentModeRepository, auditLogRepo models.AuditLogRepository, jwtMgr *auth.JWTManager, cfg TokenRevocationConfig) models.TokenRevocationService {
+	if cfg.IncidentAccessTokenTTL <= 0 {
+		cfg.IncidentAccessTokenTTL = DefaultIncidentAccessTokenTTL
+	}
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	s := &tokenRevocationService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		clientRepo:       clientRepo,
+		incidentRepo:     incidentRepo,
+		jwtMgr:           jwtMgr,
+		audit:            newAuditRecorder(auditLogRepo, nil),
+		validator:        validation.NewValidator(),
+		cfg:              cfg,
+	}
+	if cfg.IncidentMode {
+		now := s.cfg.Clock.Now()
+		s.incident = models.IncidentMode{Enabled: true, EnabledAt: &now}
+		jwtMgr.LimitAccessDuration(cfg.IncidentAccessTokenTTL)
+	}
+	return s
+}
+
+// RevokeTokens revokes the sessions and access tokens issued at or before req.IssuedBefore, or
+// now, of the given users or of everyone; revoking everyone's also denies the access tokens of
+// every machine client. Affected users have to sign in again. API keys are not revoked.
+func (s *tokenRevocationService) RevokeTokens(adminID uuid.UUID, req *models.RevokeTokensRequest, client models.ClientInfo) (*models.TokenRevocation, error) {
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	cutoff := s.cfg.Clock.Now()
+	if req.IssuedBefore != nil {
+		// A future cutoff would also deny tokens issued until then, locking users out
+		if req.IssuedBefore.After(cutoff) {
+			return nil, errors.NewAppErrorWithDetails(400, "Validation failed", "issued_before must not be in the future", nil)
+		}
+		cutoff = *req.IssuedBefore
+	}
+
+	revocation, err := s.revoke(req.UserIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.record(&adminID, models.AuditActionTokensRevoked, client, &tokenRevocationDetails{
+		UserIDs:  req.UserIDs,
+		Cutoff:   revocation.Cutoff,
+		Users:    revocation.Users,
+		Sessions: revocation.Sessions,
+		Clients:  revocation.Clients,
+	})
+	log.Printf("Admin %s revoked tokens issued before %s of %d users (%d sessions) and %d machine clients", adminID, cutoff.UTC().Format(time.RFC3339), revocation.Users, revocation.Sessions, revocation.Clients)
+	return revocation, nil
+}
+
+// IncidentMode returns the state of incident mode
+func (s *tokenRevocationService) IncidentMode() *models.IncidentMode {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.snapshot()
+}
+
+// SetIncidentMode enables or disables incident mode. Enabling it shortens the lifetime of access
+// tokens issued from now on and revokes every user and machine client token issued so far,
+// including the admin's own, but not API keys. Enabling it again while it is on revokes again.
+func (s *tokenRevocationService) SetIncidentMode(adminID uuid.UUID, enabled bool, client models.ClientInfo) (*models.IncidentMode, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !enabled {
+		if err := s.incidentRepo.Disable(); err != nil {
+			return nil, err
+		}
+		s.jwtMgr.LimitAccessDuration(0)
+		s.incident = models.IncidentMode{}
+		s.audit.record(&adminID, models.AuditActionIncidentModeDisabled, client, nil)
+		log.Printf("Incident mode disabled by admin %s", adminID)
+		return s.snapshot(), nil
+	}
+
+	// Shorten new tokens first, so none issued after the cutoff outlives the short TTL
+	s.jwtMgr.LimitAccessDuration(s.cfg.IncidentAccessTokenTTL)
+	now := s.cfg.Clock.Now()
+	revocation, err := s.revoke(nil, now)
+	if err != nil {
+		return nil, err
+	}
+	incident := models.IncidentMode{Enabled: true, EnabledAt: &now, EnabledBy: &adminID, Revocation: revocation}
+	if err := s.incidentRepo.Enable(&incident); err != nil {
+		return nil, err
+	}
+	s.incident = incident
+
+	s.audit.record(&adminID, models.AuditActionIncidentModeEnabled, client, &tokenRevocationDetails{
+		Cutoff:   revocation.Cutoff,
+		Users:    revocation.Users,
+		Sessions: revocation.Sessions,
+		Clients:  revocation.Clients,
+	})
+	log.Printf("Incident mode enabled by admin %s: access tokens now last %s; revoked the tokens of %d users (%d sessions) and %d machine clients", adminID, s.cfg.IncidentAccessTokenTTL, revocation.Users, revocation.Sessions, revocation.Clients)
+	return s.snapshot(), nil
+}
+
+// Refresh reloads the state of incident mode stored by any instance, applying its access token
+// lifetime. When the service starts in incident mode, the first refresh stores it as enabled.
+func (s *tokenRevocationService) Refresh() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	incident, err := s.incidentRepo.Get()
+	if err != nil {
+		return err
+	}
+	switch {
+	case !s.refreshed && s.cfg.IncidentMode && !incident.Enabled:
+		incident = &s.incident
+		if err := s.incidentRepo.Enable(incident); err != nil {
+			return err
+		}
+	case s.refreshed && incident.Enabled && !s.incident.Enabled:
+		log.Printf("Incident mode enabled on another instance: access tokens now last %s", s.cfg.IncidentAccessTokenTTL)
+	case s.refreshed && !incident.Enabled && s.incident.Enabled:
+		log.Printf("Incident mode disabled on another instance")
+	}
+	s.refreshed = true
+
+	if incident.Enabled {
+		s.jwtMgr.LimitAccessDuration(s.cfg.IncidentAccessTokenTTL)
+	} else {
+		s.jwtMgr.LimitAccessDuration(0)
+	}
+	s.incident = *incident
+	return nil
+}
+
+// revoke revokes sessions first, so no access token is refreshed from them once access tokens
+// are denied, then denies the access tokens issued at or before cutoff; without userIDs, those
+// of machine clients as well
+func (s *tokenRevocationService) revoke(userIDs []uuid.UUID, cutoff time.Time) (*models.TokenRevocation, error) {
+	sessions, err := s.refreshTokenRepo.RevokeIssuedBefore(userIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	users, err := s.userRepo.DenyAllTokensIssuedBefore(userIDs, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	revocation := &models.TokenRevocation{Cutoff: cutoff, Users: users, Sessions: sessions}
+	if len(userIDs) == 0 {
+		if revocation.Clients, err = s.clientRepo.DenyAllTokensIssuedBefore(cutoff); err != nil {
+			return nil, err
+		}
+	}
+	return revocation, nil
+}
+
+// snapshot copies the incident mode state; the caller holds the mutex
+func (s *tokenRevocationService) snapshot() *models.IncidentMode {
+	incident := s.incident
+	incident.AccessTokenTTL = s.jwtMgr.GetAccessDuration().String()
+	return &incident
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
+
+	"github.com/google/uuid"
+)
+
+// memoryIncidentRepo stores incident mode in memory, like the incident_mode table
+type memoryIncidentRepo struct {
+	mu   sync.Mutex
+	mode *models.IncidentMode // nil while disabled
+}
+
+func (r *memoryIncidentRepo) Get() (*models.IncidentMode, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.mode == nil {
+		return &models.IncidentMode{}, nil
+	}
+	mode := *r.mode
+	return &mode, nil
+}
+
+func (r *memoryIncidentRepo) Enable(mode *models.IncidentMode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := *mode
+	stored.AccessTokenTTL = ""
+	r.mode = &stored
+	return nil
+}
+
+func (r *memoryIncidentRepo) Disable() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.mode = nil
+	return nil
+}
+
+// newRevocationInstance returns a token revocation service and the JWT manager of an instance
+// storing incident mode in repo
+func newRevocationInstance(repo models.IncidentModeRepository, users *fakeUserRepo, startInIncidentMode bool) (models.TokenRevocationService, *auth.JWTManager) {
+	jwtMgr := auth.NewJWTManager("access-secret", "refresh-secret", "issuer", "audience", time.Hour, 24*time.Hour)
+	svc := NewTokenRevocationService(users, &fakeRefreshTokenRepo{}, &fakeClientRepo{}, repo, &fakeAuditLogRepo{}, jwtMgr, TokenRevocationConfig{
+		IncidentMode: startInIncidentMode,
+		Clock:        clock.NewManual(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)),
+	})
+	return svc, jwtMgr
+}
+
+func TestIncidentModeIsSharedByEveryInstance(t *testing.T) {
+	repo := &memoryIncidentRepo{}
+	admin := &models.User{ID: uuid.New(), Role: models.RoleAdmin}
+	users := newFakeUserRepo(nil, admin)
+	first, firstJWT := newRevocationInstance(repo, users, false)
+	second, secondJWT := newRevocationInstance(repo, users, false)
+	for _, svc := range []models.TokenRevocationService{first, second} {
+		if err := svc.Refresh(); err != nil {
+			t.Fatalf("Refresh: %v", err)
+		}
+	}
+
+	if _, err := first.SetIncidentMode(admin.ID, true, models.ClientInfo{}); err != nil {
+		t.Fatalf("SetIncidentMode: %v", err)
+	}
+	if got := firstJWT.GetAccessDuration(); got != DefaultIncidentAccessTokenTTL {
+		t.Errorf("instance enabling it issues tokens for %s, want %s", got, DefaultIncidentAccessTokenTTL)
+	}
+	if err := second.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := secondJWT.GetAccessDuration(); got != DefaultIncidentAccessTokenTTL {
+		t.Errorf("other instance issues tokens for %s after refreshing, want %s", got, DefaultIncidentAccessTokenTTL)
+	}
+	incident := second.IncidentMode()
+	if !incident.Enabled || incident.EnabledBy == nil || *incident.EnabledBy != admin.ID || incident.Revocation == nil || incident.Revocation.Users != 1 {
+		t.Errorf("other instance reports %+v, want it enabled by the admin with the revocation", incident)
+	}
+
+	if _, err := second.SetIncidentMode(admin.ID, false, models.ClientInfo{}); err != nil {
+		t.Fatalf("SetIncidentMode: %v", err)
+	}
+	if err := first.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := firstJWT.GetAccessDuration(); got != time.Hour || first.IncidentMode().Enabled {
+		t.Errorf("after disabling on the other instance: tokens last %s, enabled %v; want it off", got, first.IncidentMode().Enabled)
+	}
+}
+
+func TestStartingInIncidentModeEnablesItForEveryInstance(t *testing.T) {
+	repo := &memoryIncidentRepo{}
+	users := newFakeUserRepo(nil)
+	started, startedJWT := newRevocationInstance(repo, users, true)
+	// Tokens are short-lived before the state is loaded, in case loading it fails
+	if got := startedJWT.GetAccessDuration(); got != DefaultIncidentAccessTokenTTL {
+		t.Errorf("before refreshing: tokens last %s, want %s", got, DefaultIncidentAccessTokenTTL)
+	}
+	if err := started.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+
+	other, otherJWT := newRevocationInstance(repo, users, false)
+	if err := other.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := otherJWT.GetAccessDuration(); got != DefaultIncidentAccessTokenTTL || !other.IncidentMode().Enabled {
+		t.Errorf("other instance: tokens last %s, enabled %v; want incident mode", got, other.IncidentMode().Enabled)
+	}
+
+	// Disabled by an admin, it stays off on the instance configured to start in it
+	if _, err := other.SetIncidentMode(uuid.New(), false, models.ClientInfo{}); err != nil {
+		t.Fatalf("SetIncidentMode: %v", err)
+	}
+	if err := started.Refresh(); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := startedJWT.GetAccessDuration(); got != time.Hour {
+		t.Errorf("after an admin disabled it: tokens last %s, want an hour", got)
+	}
+}
+
+func TestRevokingEveryonesTokensDeniesMachineClientTokens(t *testing.T) {
+	now := time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC)
+	alice := &models.User{ID: uuid.New()}
+	tests := []struct {
+		name        string
+		userIDs     []uuid.UUID
+		wantClients int64
+	}{
+		{name: "everyone", wantClients: 3},
+		{name: "listed users", userIDs: []uuid.UUID{alice.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients := &fakeClientRepo{clients: 3}
+			audit := &fakeAuditLogRepo{}
+			jwtMgr := auth.NewJWTManager("access-secret", "refresh-secret", "issuer", "audience", time.Hour, 24*time.Hour)
+			svc := NewTokenRevocationService(newFakeUserRepo(nil, alice), &fakeRefreshTokenRepo{}, clients, &memoryIncidentRepo{}, audit, jwtMgr, TokenRevocationConfig{
+				Clock: clock.NewManual(now),
+			})
+
+			revocation, err := svc.RevokeTokens(uuid.New(), &models.RevokeTokensRequest{UserIDs: tt.userIDs}, models.ClientInfo{})
+			if err != nil {
+				t.Fatalf("RevokeTokens: %v", err)
+			}
+			if revocation.Clients != tt.wantClients {
+				t.Errorf("got %d clients, want %d", revocation.Clients, tt.wantClients)
+			}
+			if denied := clients.cutoff != nil; denied != (tt.wantClients > 0) {
+				t.Errorf("client tokens denied: %v, want %v", denied, tt.wantClients > 0)
+			} else if denied && !clients.cutoff.Equal(now) {
+				t.Errorf("client tokens denied before %s, want %s", clients.cutoff, now)
+			}
+
+			// The audit entry states that API keys were left alone
+			var details tokenRevocationDetails
+			if len(audit.entries) != 1 || json.Unmarshal(audit.entries[0].Details, &details) != nil {
+				t.Fatalf("got audit entries %+v, want one with details", audit.entries)
+			}
+			if details.Clients != tt.wantClients || details.APIKeysRevoked || !strings.Contains(string(audit.entries[0].Details), `"api_keys_revoked":false`) {
+				t.Errorf("got audit details %s, want %d clients and api_keys_revoked false", audit.entries[0].Details, tt.wantClients)
+			}
+		})
+	}
+}
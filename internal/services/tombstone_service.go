@@ -0,0 +1,45 @@
+package services
+
+import (
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+)
+
+// tombstoneService implements TombstoneService interface
+type tombstoneService struct {
+	deletedRecordRepo models.DeletedRecordRepository
+	retentionDays     int
+}
+
+// NewTombstoneService creates a new tombstone service keeping tombstones retentionDays
+// days; 0 keeps them forever
+func NewTombstoneService(deletedRecordRepo models.DeletedRecordRepository, retentionDays int) models.TombstoneService {
+	return &tombstoneService{
+		deletedRecordRepo: deletedRecordRepo,
+		retentionDays:     retentionDays,
+	}
+}
+
+// Prune deletes the tombstones past their retention
+func (s *tombstoneService) Prune() (int64, error) {
+	horizon := s.Horizon()
+	if horizon.IsZero() {
+		return 0, nil
+	}
+
+	pruned, err := s.deletedRecordRepo.Prune(horizon)
+	if err != nil {
+		return 0, errors.WrapError(err, "Failed to prune tombstones")
+	}
+	return pruned, nil
+}
+
+// Horizon is the time before which tombstones are pruned
+func (s *tombstoneService) Horizon() time.Time {
+	if s.retentionDays <= 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -s.retentionDays)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/clock"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/google/uuid"
+)
+
+func newDeactivationService(t *testing.T, calls *[]string, users ...*models.User) (models.UserService, *fakeUserRepo, *fakeRefreshTokenRepo, *clock.Manual) {
+	t.Helper()
+	userRepo := newFakeUserRepo(calls, users...)
+	refreshRepo := &fakeRefreshTokenRepo{calls: calls}
+	clk := clock.NewManual(time.Date(2026, 10, 14, 9, 0, 0, 0, time.UTC))
+	svc := NewUserService(userRepo, refreshRepo, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, UserServiceConfig{Clock: clk})
+	return svc, userRepo, refreshRepo, clk
+}
+
+func TestDeactivateUserRevokesSessionsAndTokens(t *testing.T) {
+	user := &models.User{ID: uuid.New(), IsActive: true}
+	var calls []string
+	svc, userRepo, refreshRepo, clk := newDeactivationService(t, &calls, user)
+
+	if err := svc.DeactivateUser(user.ID); err != nil {
+		t.Fatalf("DeactivateUser: %v", err)
+	}
+
+	// The account is deactivated first, so refreshes and sign-ins starting during the
+	// revocation are refused; tokens they minted are denied with the account inactive
+	want := []string{"Deactivate", "RevokeAllForUser", "DenyTokensIssuedBefore"}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %v, want %v", calls, want)
+	}
+	if !refreshRepo.revoked[user.ID] {
+		t.Error("refresh tokens were not revoked")
+	}
+	state, err := userRepo.GetTokenState(user.ID)
+	if err != nil {
+		t.Fatalf("GetTokenState: %v", err)
+	}
+	if state.IsActive {
+		t.Error("account is still active")
+	}
+	if state.TokensDeniedBefore == nil || !state.TokensDeniedBefore.Equal(clk.Now()) {
+		t.Errorf("tokens denied before %v, want %v", state.TokensDeniedBefore, clk.Now())
+	}
+}
+
+func TestActivateUserKeepsTokensDenied(t *testing.T) {
+	user := &models.User{ID: uuid.New(), IsActive: true}
+	svc, userRepo, _, clk := newDeactivationService(t, nil, user)
+
+	if err := svc.DeactivateUser(user.ID); err != nil {
+		t.Fatalf("DeactivateUser: %v", err)
+	}
+	cutoff := clk.Now()
+	clk.Advance(time.Hour)
+	if err := svc.ActivateUser(user.ID); err != nil {
+		t.Fatalf("ActivateUser: %v", err)
+	}
+
+	state, err := userRepo.GetTokenState(user.ID)
+	if err != nil {
+		t.Fatalf("GetTokenState: %v", err)
+	}
+	if !state.IsActive {
+		t.Error("account was not activated")
+	}
+	if state.TokensDeniedBefore == nil || !state.TokensDeniedBefore.Equal(cutoff) {
+		t.Errorf("tokens denied before %v, want the deactivation time %v", state.TokensDeniedBefore, cutoff)
+	}
+}
+
+func TestDeactivateUnknownUser(t *testing.T) {
+	var calls []string
+	svc, _, _, _ := newDeactivationService(t, &calls)
+
+	if err := svc.DeactivateUser(uuid.New()); err != errors.ErrUserNotFound {
+		t.Errorf("got %v, want ErrUserNotFound", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("unexpected writes %v", calls)
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/normalize"
+	"go-backend-api/internal/pkg/security"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultImportMaxRows is how many rows a user import may have when no limit is configured
+const DefaultImportMaxRows = 50000
+
+// importChunkSize is how many users an import checks and creates per transaction
+const importChunkSize = 500
+
+// importPasswordLength is the length of generated temporary passwords
+const importPasswordLength = 20
+
+// importRow is a row of an import being processed
+type importRow struct {
+	*models.ImportUserRow
+	result *models.ImportRowResult
+}
+
+// ImportUsers creates users from rows, reporting the outcome of every row. Rows are validated
+// like registrations, then checked against existing accounts and created chunk by chunk, each
+// chunk in its own transaction: a chunk that fails to insert fails all of its rows but leaves
+// earlier chunks in place. A dry run stops after the checks.
+//
+// Once users were created the report must reach the caller, as it holds their only copy of the
+// temporary passwords, so later failures fail the remaining rows instead of the whole import.
+func (s *userService) ImportUsers(rows []*models.ImportUserRow, dryRun bool) (*models.UserImportReport, error) {
+	maxRows := s.cfg.ImportMaxRows
+	if maxRows <= 0 {
+		maxRows = DefaultImportMaxRows
+	}
+	if len(rows) == 0 {
+		return nil, errors.NewAppErrorWithReason(http.StatusBadRequest, "IMPORT_EMPTY", "Import has no rows")
+	}
+	if len(rows) > maxRows {
+		return nil, errors.NewAppErrorWithReason(http.StatusRequestEntityTooLarge, "IMPORT_TOO_LARGE", "Import has more than the allowed number of rows").
+			WithDetails("At most " + strconv.Itoa(maxRows) + " rows can be imported at once; split the file")
+	}
+
+	report := &models.UserImportReport{DryRun: dryRun, Total: len(rows), Rows: make([]*models.ImportRowResult, len(rows))}
+	pending := s.validateImportRows(rows, report)
+
+	created := false
+	for start := 0; start < len(pending); start += importChunkSize {
+		chunk, err := s.importChunk(pending[start:min(start+importChunkSize, len(pending))], dryRun)
+		if err != nil {
+			if !created {
+				return nil, err
+			}
+			log.Printf("User import stopped after %d of %d rows: %v", start, len(pending), err)
+			for _, row := range pending[start:] {
+				row.result.Error = "Not processed: the import stopped early; retry this row"
+			}
+			break
+		}
+		created = created || chunk > 0
+	}
+
+	for _, result := range report.Rows {
+		switch result.Status {
+		case models.ImportRowCreated:
+			report.Created++
+		case models.ImportRowValid:
+			report.Valid++
+		default:
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+// validateImportRows normalizes and validates every row on its own and against earlier rows,
+// recording a result for each, and returns the rows that passed
+func (s *userService) validateImportRows(rows []*models.ImportUserRow, report *models.UserImportReport) []importRow {
+	seenEmails := make(map[string]int)
+	seenSkeletons := make(map[string]int)
+
+	pending := make([]importRow, 0, len(rows))
+	for i, row := range rows {
+		row.Email = normalize.Email(row.Email)
+		row.Username = normalize.Username(row.Username)
+		result := &models.ImportRowResult{Row: i + 1, Username: row.Username, Email: row.Email, Status: models.ImportRowFailed}
+		report.Rows[i] = result
+
+		if err := s.validator.Validate(row); err != nil {
+			messages := make([]string, 0)
+			for _, fieldErr := range s.validator.GetValidationErrors(err) {
+				messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+			}
+			result.Error = "Validation failed: " + strings.Join(messages, "; ")
+			continue
+		}
+		if s.blocklist.IsReservedUsername(row.Username) {
+			result.Error = errors.ErrReservedUsername.Message
+			continue
+		}
+		if s.blocklist.IsBlockedEmail(row.Email) {
+			result.Error = errors.ErrBlockedEmailDomain.Message
+			continue
+		}
+
+		// Duplicates within the file are reported against the first occurrence
+		email, skeleton := strings.ToLower(row.Email), normalize.UsernameSkeleton(row.Username)
+		if first, ok := seenEmails[email]; ok {
+			result.Error = "Email duplicates row " + strconv.Itoa(first)
+			continue
+		}
+		if first, ok := seenSkeletons[skeleton]; ok {
+			result.Error = "Username duplicates or is too similar to row " + strconv.Itoa(first)
+			continue
+		}
+		seenEmails[email] = result.Row
+		seenSkeletons[skeleton] = result.Row
+
+		pending = append(pending, importRow{ImportUserRow: row, result: result})
+	}
+
+	return pending
+}
+
+// importChunk checks a chunk of rows and, unless this is a dry run, creates their users,
+// returning how many were created
+func (s *userService) importChunk(chunk []importRow, dryRun bool) (int, error) {
+	chunk, err := s.checkImportConflicts(chunk)
+	if err != nil {
+		return 0, err
+	}
+	if dryRun {
+		for _, row := range chunk {
+			row.result.Status = models.ImportRowValid
+		}
+		return 0, nil
+	}
+	return s.createImportChunk(chunk)
+}
+
+// checkImportConflicts fails the rows whose email or username is taken by an existing account
+// or reserved for a previous owner, and returns the others
+func (s *userService) checkImportConflicts(chunk []importRow) ([]importRow, error) {
+	emails := make([]string, len(chunk))
+	usernames := make([]string, len(chunk))
+	for i, row := range chunk {
+		emails[i] = row.Email
+		usernames[i] = row.Username
+	}
+
+	takenEmails, err := s.userRepo.TakenEmails(emails)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check user existence")
+	}
+	takenUsernames, err := s.userRepo.TakenUsernames(usernames)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check username existence")
+	}
+	reserved, err := s.usernameHistoryRepo.ReservedAmong(usernames)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check username reservation")
+	}
+
+	free := make([]importRow, 0, len(chunk))
+	for _, row := range chunk {
+		switch {
+		case takenEmails[row.Email]:
+			row.result.Error = errors.ErrUserExists.Message
+		case takenUsernames[row.Username]:
+			row.result.Error = "Username already taken or too similar to an existing username"
+		case reserved[row.Username]:
+			row.result.Error = errors.ErrUsernameReserved.Message
+		default:
+			free = append(free, row)
+		}
+	}
+
+	return free, nil
+}
+
+// createImportChunk creates the users of a chunk in one transaction with temporary passwords,
+// returning how many were created
+func (s *userService) createImportChunk(chunk []importRow) (int, error) {
+	now := time.Now()
+	users := make([]*models.User, len(chunk))
+	passwords := make([]string, len(chunk))
+	for i := range chunk {
+		password, err := security.GenerateSecurePassword(importPasswordLength)
+		if err != nil {
+			return 0, errors.WrapError(err, "Failed to generate temporary password")
+		}
+		passwords[i] = password
+	}
+	hashes, err := s.hashImportPasswords(passwords)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, row := range chunk {
+		users[i] = &models.User{
+			Username:           row.Username,
+			Email:              row.Email,
+			Password:           hashes[i],
+			Role:               row.Role,
+			IsActive:           true,
+			MustChangePassword: true,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}
+	}
+
+	if err := s.userRepo.CreateBatch(users); err != nil {
+		// Usually an account registered since the checks; the other chunks are unaffected
+		message := "Failed to create the users of this chunk"
+		if errors.Is(err, models.ErrDuplicate) {
+			message = "Not created: a user of this chunk was registered during the import; retry these rows"
+		}
+		log.Printf("Failed to create %d imported users: %v", len(chunk), err)
+		for _, row := range chunk {
+			row.result.Error = message
+		}
+		return 0, nil
+	}
+
+	for i, row := range chunk {
+		row.result.Status = models.ImportRowCreated
+		row.result.UserID = &users[i].ID
+		row.result.TemporaryPassword = passwords[i]
+	}
+	return len(chunk), nil
+}
+
+// hashImportPasswords hashes temporary passwords at the cost of every other password, in
+// parallel on half of the hash pool's slots so sign-ins keep the rest during a large import
+func (s *userService) hashImportPasswords(passwords []string) ([]string, error) {
+	workers := max(1, s.hasher.Stats().MaxParallel/2)
+	hashes := make([]string, len(passwords))
+	errs := make([]error, len(passwords))
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(passwords)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				hashes[i], errs[i] = s.hasher.Generate(passwords[i], bcrypt.DefaultCost)
+			}
+		}()
+	}
+	for i := range passwords {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, hashError(err, "Failed to hash password")
+		}
+	}
+	return hashes, nil
+}
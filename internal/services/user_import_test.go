@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"go-backend-api/internal/pkg/security"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHashImportPasswordsUsesDefaultCost(t *testing.T) {
+	s := &userService{hasher: security.NewHashPool(4, 0)}
+	passwords := []string{"first-temporary-pw", "second-temporary-pw", "third-temporary-pw"}
+
+	hashes, err := s.hashImportPasswords(passwords)
+	if err != nil {
+		t.Fatalf("hashImportPasswords: %v", err)
+	}
+	for i, hash := range hashes {
+		if cost, err := bcrypt.Cost([]byte(hash)); err != nil || cost != bcrypt.DefaultCost {
+			t.Errorf("hash %d: cost %d (%v), want %d", i, cost, err, bcrypt.DefaultCost)
+		}
+		if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(passwords[i])); err != nil {
+			t.Errorf("hash %d does not match its password: %v", i, err)
+		}
+	}
+}
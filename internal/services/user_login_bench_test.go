@@ -0,0 +1,47 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// BenchmarkAuthenticateUser measures sign-in apart from bcrypt, whose cost is set by the
+// stored hash: the user's hash uses the minimum cost, so the rest of the work shows.
+// fetches/op reports the user lookups per sign-in, which must stay at one.
+func BenchmarkAuthenticateUser(b *testing.B) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.MinCost)
+	if err != nil {
+		b.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Username: "known", Email: "known@example.com", Password: string(hash), Role: models.RoleUser, IsActive: true}
+	userRepo := newFakeUserRepo(nil, user)
+	jwtMgr := auth.NewJWTManager("benchmark-access-secret-0123456789", "benchmark-refresh-secret-0123456789", "go-backend-api", "go-backend-api", 15*time.Minute, 7*24*time.Hour)
+	svc := NewUserService(userRepo, &fakeRefreshTokenRepo{}, nil, nil, nil, &fakeAuditLogRepo{}, nil, nil, nil, nil, nil, jwtMgr, nil, security.NewHashPool(4, 64), nil, nil, nil, UserServiceConfig{})
+
+	for _, bm := range []struct {
+		name, password string
+		wantErr        bool
+	}{
+		{"success", "correct-password", false},
+		{"wrong password", "wrong-password", true},
+	} {
+		b.Run(bm.name, func(b *testing.B) {
+			userRepo.emailFetches = 0
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_, _, err := svc.AuthenticateUser(&models.LoginRequest{Email: "known@example.com", Password: bm.password})
+				if (err != nil) != bm.wantErr {
+					b.Fatalf("AuthenticateUser: %v", err)
+				}
+			}
+			b.ReportMetric(float64(userRepo.emailFetches)/float64(b.N), "fetches/op")
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-backend-api/internal/handlers"
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/metrics"
+	"go-backend-api/internal/pkg/security"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// loginFixture serves POST /auth/login through the real handler and service, with a single
+// active user known@example.com whose password is correct-password
+type loginFixture struct {
+	router   *gin.Engine
+	hasher   *security.HashPool
+	audit    *fakeAuditLogRepo
+	failures *metrics.Counter
+	userID   uuid.UUID
+}
+
+func newLoginFixture(t *testing.T) *loginFixture {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("correct-password"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Username: "known", Email: "known@example.com", Password: string(hash), Role: models.RoleUser, IsActive: true}
+
+	f := &loginFixture{hasher: security.NewHashPool(2, 8), audit: &fakeAuditLogRepo{}, failures: &metrics.Counter{}, userID: user.ID}
+	svc := NewUserService(newFakeUserRepo(nil, user), &fakeRefreshTokenRepo{}, nil, nil, nil, f.audit, nil, nil, nil, nil, nil, nil, nil, f.hasher, nil, nil, nil,
+		UserServiceConfig{LoginFailures: f.failures})
+
+	gin.SetMode(gin.TestMode)
+	f.router = gin.New()
+	f.router.POST("/auth/login", handlers.NewAuthHandler(svc, nil, nil).Login)
+	return f
+}
+
+// login signs in and returns the response and how many bcrypt operations it ran
+func (f *loginFixture) login(t *testing.T, email, password string) (*httptest.ResponseRecorder, uint64) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"email": email, "password": password})
+	before := f.hasher.Stats().Completed
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewReader(body)))
+	return w, f.hasher.Stats().Completed - before
+}
+
+func TestLoginDoesNotRevealWhetherAnAccountExists(t *testing.T) {
+	f := newLoginFixture(t)
+
+	unknown, unknownHashes := f.login(t, "nobody@example.com", "correct-password")
+	wrong, wrongHashes := f.login(t, "known@example.com", "wrong-password")
+
+	if unknown.Code != http.StatusUnauthorized || wrong.Code != http.StatusUnauthorized {
+		t.Fatalf("got %d for an unknown email and %d for a wrong password, want 401 for both", unknown.Code, wrong.Code)
+	}
+	if !bytes.Equal(unknown.Body.Bytes(), wrong.Body.Bytes()) {
+		t.Errorf("bodies differ:\nunknown email:  %s\nwrong password: %s", unknown.Body, wrong.Body)
+	}
+	for _, header := range []string{"Content-Type", "Content-Length", "Set-Cookie"} {
+		if unknown.Header().Get(header) != wrong.Header().Get(header) {
+			t.Errorf("%s differs: %q for an unknown email, %q for a wrong password", header, unknown.Header().Get(header), wrong.Header().Get(header))
+		}
+	}
+	var envelope struct {
+		Error struct {
+			Reason string `json:"reason"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(wrong.Body.Bytes(), &envelope); err != nil || envelope.Error.Reason != "INVALID_CREDENTIALS" {
+		t.Errorf("got body %s, want reason INVALID_CREDENTIALS", wrong.Body)
+	}
+
+	// The unknown email pays for CompareDummy as the wrong password pays for Compare, so both
+	// spend one bcrypt comparison at the same cost
+	if unknownHashes != 1 || wrongHashes != 1 {
+		t.Errorf("ran %d bcrypt operations for an unknown email and %d for a wrong password, want 1 each", unknownHashes, wrongHashes)
+	}
+
+	// Both count as failed sign-ins; only the known account is named in the audit log
+	if got := f.failures.Value(); got != 2 {
+		t.Errorf("counted %d failed sign-ins, want 2", got)
+	}
+	if len(f.audit.entries) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(f.audit.entries))
+	}
+	for i, entry := range f.audit.entries {
+		if entry.Action != models.AuditActionLoginFailed {
+			t.Errorf("audit entry %d: action %q, want %q", i, entry.Action, models.AuditActionLoginFailed)
+		}
+	}
+	if f.audit.entries[0].UserID != nil {
+		t.Errorf("unknown email audited against user %v", *f.audit.entries[0].UserID)
+	}
+	if id := f.audit.entries[1].UserID; id == nil || *id != f.userID {
+		t.Errorf("wrong password audited against %v, want %v", id, f.userID)
+	}
+}
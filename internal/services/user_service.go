@@ -1,42 +1,144 @@
 package services
 
 import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/url"
+	"slices"
+	"strings"
 	"time"
 
+	"go-backend-api/internal/mailer"
 	"go-backend-api/internal/models"
 	"go-backend-api/internal/pkg/auth"
+	"go-backend-api/internal/pkg/clock"
 	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/fieldcrypt"
+	"go-backend-api/internal/pkg/metrics"
+	"go-backend-api/internal/pkg/normalize"
+	"go-backend-api/internal/pkg/security"
 	"go-backend-api/internal/pkg/validation"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// UserServiceConfig holds tunable settings for the user service
+type UserServiceConfig struct {
+	// UsernameCooldown is how long a previous username stays reserved for its owner
+	UsernameCooldown time.Duration
+	// EmailChangeTTL is how long the confirmation link sent to a new email address is valid
+	EmailChangeTTL time.Duration
+	// EmailChangeUndoTTL is how long the undo link sent to the old email address is valid
+	EmailChangeUndoTTL time.Duration
+	// BaseURL is the external base URL used to build links in emails
+	BaseURL string
+	// InviteOnly requires a valid invite code to register
+	InviteOnly bool
+	// StepUpThreshold is the sign-in risk score at or above which an emailed one-time code is required
+	StepUpThreshold int
+	// StepUpCodeTTL is how long a step-up code is valid
+	StepUpCodeTTL time.Duration
+	// StepUpMaxAttempts is how many codes may be tried on a challenge, the right one included
+	StepUpMaxAttempts int
+	// NotifyNewSignIns emails users when their account is signed in to from a new IP address
+	NotifyNewSignIns bool
+	// MaxDevicesPerUser limits simultaneous sessions; the oldest is evicted when exceeded (0 is unlimited)
+	MaxDevicesPerUser int
+	// PasswordPolicy is enforced on registration and password changes (nil uses the default policy)
+	PasswordPolicy *security.PasswordPolicy
+	// DeletedUserPosts is what happens to the posts of deleted users: models.DeletedUserPostsDelete or models.DeletedUserPostsAnonymize
+	DeletedUserPosts string
+	// LoginFailures counts failed sign-in attempts for alerting (may be nil)
+	LoginFailures *metrics.Counter
+	// BreakGlassLogins counts sign-ins with break-glass accounts for alerting (may be nil)
+	BreakGlassLogins *metrics.Counter
+	// ImportMaxRows caps the rows of a bulk user import (DefaultImportMaxRows when not positive)
+	ImportMaxRows int
+	// ParentalConsentAge restricts accounts younger than it until a parent consents (0 disables)
+	ParentalConsentAge int
+	// ParentalConsentTTL is how long the consent link sent to a parent is valid
+	ParentalConsentTTL time.Duration
+	// MinimumAge refuses registrations of younger users (0 allows any age)
+	MinimumAge int
+	// RequireBirthdate requires a birthdate to register
+	RequireBirthdate bool
+	// Clock tells the current time (nil is the system clock)
+	Clock clock.Clock
+}
+
 // userService implements UserService interface
 type userService struct {
-	userRepo         models.UserRepository
-	refreshTokenRepo models.RefreshTokenRepository
-	jwtMgr           *auth.JWTManager
-	validator        *validation.Validator
+	userRepo            models.UserRepository
+	refreshTokenRepo    models.RefreshTokenRepository
+	usernameHistoryRepo models.UsernameHistoryRepository
+	emailChangeRepo     models.EmailChangeRepository
+	inviteRepo          models.InviteRepository
+	auditLogRepo        models.AuditLogRepository
+	loginChallengeRepo  models.LoginChallengeRepository
+	policyRepo          models.PolicyRepository
+	parentalConsentRepo models.ParentalConsentRepository
+	breakGlassRepo      models.BreakGlassRepository
+	legalHold           *legalHoldGuard
+	jwtMgr              *auth.JWTManager
+	validator           *validation.Validator
+	blocklist           *security.Blocklist
+	hasher              *security.HashPool
+	riskScorer          *security.RiskScorer
+	geo                 security.GeoLocator
+	audit               *auditRecorder
+	mailer              mailer.Mailer
+	cfg                 UserServiceConfig
 }
 
 // NewUserService creates a new user service
-func NewUserService(userRepo models.UserRepository, refreshTokenRepo models.RefreshTokenRepository, jwtMgr *auth.JWTManager) models.UserService {
+func NewUserService(userRepo models.UserRepository, refreshTokenRepo models.RefreshTokenRepository, usernameHistoryRepo models.UsernameHistoryRepository, emailChangeRepo models.EmailChangeRepository, inviteRepo models.InviteRepository, auditLogRepo models.AuditLogRepository, loginChallengeRepo models.LoginChallengeRepository, policyRepo models.PolicyRepository, parentalConsentRepo models.ParentalConsentRepository, legalHoldRepo models.LegalHoldRepository, breakGlassRepo models.BreakGlassRepository, jwtMgr *auth.JWTManager, blocklist *security.Blocklist, hasher *security.HashPool, riskScorer *security.RiskScorer, geo security.GeoLocator, mailer mailer.Mailer, cfg UserServiceConfig) models.UserService {
+	cfg.Clock = clock.OrReal(cfg.Clock)
+	audit := newAuditRecorder(auditLogRepo, geo)
 	return &userService{
-		userRepo:         userRepo,
-		refreshTokenRepo: refreshTokenRepo,
-		jwtMgr:           jwtMgr,
-		validator:        validation.NewValidator(),
+		userRepo:            userRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		usernameHistoryRepo: usernameHistoryRepo,
+		emailChangeRepo:     emailChangeRepo,
+		inviteRepo:          inviteRepo,
+		auditLogRepo:        auditLogRepo,
+		loginChallengeRepo:  loginChallengeRepo,
+		policyRepo:          policyRepo,
+		parentalConsentRepo: parentalConsentRepo,
+		breakGlassRepo:      breakGlassRepo,
+		legalHold:           newLegalHoldGuard(legalHoldRepo, audit),
+		jwtMgr:              jwtMgr,
+		validator:           validation.NewValidatorWithPasswordPolicy(cfg.PasswordPolicy),
+		blocklist:           blocklist,
+		hasher:              hasher,
+		riskScorer:          riskScorer,
+		geo:                 geo,
+		audit:               audit,
+		mailer:              mailer,
+		cfg:                 cfg,
 	}
 }
 
 // CreateUser creates a new user
 func (s *userService) CreateUser(req *models.CreateUserRequest) (*models.User, error) {
+	// Normalize identifiers so lookups and uniqueness checks are case- and encoding-insensitive
+	req.Email = normalize.Email(req.Email)
+	req.Username = normalize.Username(req.Username)
+
 	// Validate request
 	if err := s.validator.Validate(req); err != nil {
 		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
 	}
 
+	// Check configured blocklists
+	if s.blocklist.IsReservedUsername(req.Username) {
+		return nil, errors.ErrReservedUsername
+	}
+	if s.blocklist.IsBlockedEmail(req.Email) {
+		return nil, errors.ErrBlockedEmailDomain
+	}
+
 	// Check if user already exists
 	exists, err := s.userRepo.ExistsByEmail(req.Email)
 	if err != nil {
@@ -54,63 +156,170 @@ func (s *userService) CreateUser(req *models.CreateUserRequest) (*models.User, e
 		return nil, errors.NewAppErrorWithDetails(409, "Username already taken", "Username must be unique", nil)
 	}
 
+	confusable, err := s.userRepo.ExistsByConfusableUsername(req.Username, uuid.Nil)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check username existence")
+	}
+	if confusable {
+		return nil, errors.ErrUsernameConfusable
+	}
+
+	// Previous usernames stay reserved for their owner during the cooldown period
+	reserved, err := s.usernameHistoryRepo.IsReserved(req.Username, uuid.Nil)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check username reservation")
+	}
+	if reserved {
+		return nil, errors.ErrUsernameReserved
+	}
+
+	// Every current policy must be accepted, in its current version
+	currentPolicies, err := s.policyRepo.ListCurrent()
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list policies")
+	}
+	acceptedPolicyIDs, err := acceptedPolicies(currentPolicies, req.AcceptedPolicies, true)
+	if err != nil {
+		return nil, err
+	}
+
+	birthdate, err := s.checkAge(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// In invite-only mode, take one use of the invite before creating the account
+	var invite *models.Invite
+	if s.cfg.InviteOnly {
+		invite, err = s.redeemInvite(req.InviteCode, req.Email)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := s.hasher.Generate(req.Password, bcrypt.DefaultCost)
 	if err != nil {
-		return nil, errors.WrapError(err, "Failed to hash password")
+		s.releaseInvite(invite)
+		return nil, hashError(err, "Failed to hash password")
 	}
 
 	// Create user (active by default)
 	user := &models.User{
 		Username:  req.Username,
 		Email:     req.Email,
-		Password:  string(hashedPassword),
+		Password:  hashedPassword,
 		IsActive:  true,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		Birthdate: birthdate,
+		CreatedAt: s.cfg.Clock.Now(),
+		UpdatedAt: s.cfg.Clock.Now(),
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
+		s.releaseInvite(invite)
 		return nil, errors.WrapError(err, "Failed to create user")
 	}
 
-	// Clear password from response
-	user.Password = ""
+	if invite != nil {
+		if err := s.inviteRepo.RecordUse(invite.ID, user.ID); err != nil {
+			return nil, errors.WrapError(err, "Failed to record invite use")
+		}
+	}
+
+	if len(acceptedPolicyIDs) > 0 {
+		if err := s.policyRepo.Accept(user.ID, acceptedPolicyIDs, user.CreatedAt); err != nil {
+			return nil, errors.WrapError(err, "Failed to record policy acceptance")
+		}
+	}
+
+	// The account exists either way; without a parent email it stays restricted until a
+	// consent request is made from it
+	if req.ParentEmail != "" && user.NeedsParentalConsent(s.cfg.ParentalConsentAge, user.CreatedAt) {
+		if err := s.requestParentalConsent(user, req.ParentEmail); err != nil {
+			log.Printf("Failed to request parental consent for user %s: %v", user.ID, err)
+		}
+	}
+
+	user.Sanitize()
 
 	return user, nil
 }
 
+// redeemInvite validates an invite code for the given email and atomically claims one use of it
+func (s *userService) redeemInvite(code, email string) (*models.Invite, error) {
+	if code == "" {
+		return nil, errors.ErrInviteRequired
+	}
+
+	invite, err := s.inviteRepo.GetByCodeHash(auth.HashToken(code))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidInvite
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get invite")
+	}
+	if !invite.IsUsable() {
+		return nil, errors.ErrInvalidInvite
+	}
+	if invite.Email != nil && *invite.Email != email {
+		return nil, errors.ErrInvalidInvite
+	}
+
+	claimed, err := s.inviteRepo.Claim(invite.ID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to claim invite")
+	}
+	if !claimed {
+		return nil, errors.ErrInvalidInvite
+	}
+
+	return invite, nil
+}
+
+// releaseInvite gives back an invite use when registration fails after the invite was claimed
+func (s *userService) releaseInvite(invite *models.Invite) {
+	if invite == nil {
+		return
+	}
+	if err := s.inviteRepo.Release(invite.ID); err != nil {
+		log.Printf("Failed to release invite %s: %v", invite.ID, err)
+	}
+}
+
 // GetUserByID gets a user by ID
 func (s *userService) GetUserByID(id uuid.UUID) (*models.User, error) {
 	user, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		return nil, errors.ErrUserNotFound
-	}
 
-	// Clear password from response
-	user.Password = ""
+	user.Sanitize()
 
 	return user, nil
 }
 
 // GetUserByEmail gets a user by email
 func (s *userService) GetUserByEmail(email string) (*models.User, error) {
-	user, err := s.userRepo.GetByEmail(email)
+	user, err := s.userRepo.GetByEmail(normalize.Email(email))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		return nil, errors.ErrUserNotFound
-	}
 
-	return user, nil
+	return user.Sanitize(), nil
 }
 
 // UpdateUser updates a user
 func (s *userService) UpdateUser(id uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
+	// Normalize identifiers so lookups and uniqueness checks are case- and encoding-insensitive
+	req.Email = normalize.Email(req.Email)
+	req.Username = normalize.Username(req.Username)
+
 	// Validate request
 	if err := s.validator.Validate(req); err != nil {
 		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
@@ -118,65 +327,463 @@ func (s *userService) UpdateUser(id uuid.UUID, req *models.UpdateUserRequest) (*
 
 	// Get existing user
 	user, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		return nil, errors.ErrUserNotFound
-	}
 
 	// Update fields if provided
-	if req.Username != "" {
-		// Check if username is already taken by another user
-		exists, err := s.userRepo.ExistsByUsername(req.Username)
+	oldUsername := user.Username
+	if req.Username != "" && req.Username != user.Username {
+		if s.blocklist.IsReservedUsername(req.Username) {
+			return nil, errors.ErrReservedUsername
+		}
+
+		// Check if username is already taken by another user (a case-only change keeps the same owner)
+		if !strings.EqualFold(req.Username, user.Username) {
+			exists, err := s.userRepo.ExistsByUsername(req.Username)
+			if err != nil {
+				return nil, errors.WrapError(err, "Failed to check username existence")
+			}
+			if exists {
+				return nil, errors.NewAppErrorWithDetails(409, "Username already taken", "Username must be unique", nil)
+			}
+		}
+
+		confusable, err := s.userRepo.ExistsByConfusableUsername(req.Username, user.ID)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to check username existence")
 		}
-		if exists && user.Username != req.Username {
-			return nil, errors.NewAppErrorWithDetails(409, "Username already taken", "Username must be unique", nil)
+		if confusable {
+			return nil, errors.ErrUsernameConfusable
+		}
+
+		// Check if username was recently released by another user
+		reserved, err := s.usernameHistoryRepo.IsReserved(req.Username, user.ID)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to check username reservation")
+		}
+		if reserved {
+			return nil, errors.ErrUsernameReserved
 		}
 		user.Username = req.Username
 	}
 
-	if req.Email != "" {
+	// Email changes only take effect once the new address is confirmed
+	var newEmail string
+	if req.Email != "" && !strings.EqualFold(req.Email, user.Email) {
+		if s.blocklist.IsBlockedEmail(req.Email) {
+			return nil, errors.ErrBlockedEmailDomain
+		}
+
 		// Check if email is already taken by another user
 		exists, err := s.userRepo.ExistsByEmail(req.Email)
 		if err != nil {
 			return nil, errors.WrapError(err, "Failed to check email existence")
 		}
-		if exists && user.Email != req.Email {
+		if exists {
 			return nil, errors.NewAppErrorWithDetails(409, "Email already taken", "Email must be unique", nil)
 		}
-		user.Email = req.Email
+		newEmail = req.Email
+		user.PendingEmail = &newEmail
+	}
+
+	// Phone numbers are stored encrypted and checked for uniqueness by their blind index
+	if req.PhoneNumber != "" && req.PhoneNumber != string(user.PhoneNumber) {
+		keyring := fieldcrypt.Default()
+		if keyring == nil {
+			return nil, errors.ErrEncryptionUnavailable
+		}
+		index := keyring.BlindIndex(req.PhoneNumber)
+		exists, err := s.userRepo.ExistsByPhoneNumberIndex(index, user.ID)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to check phone number existence")
+		}
+		if exists {
+			return nil, errors.ErrPhoneNumberTaken
+		}
+		user.PhoneNumber = fieldcrypt.String(req.PhoneNumber)
+		user.PhoneNumberIndex = &index
 	}
 
-	user.UpdatedAt = time.Now()
+	user.UpdatedAt = s.cfg.Clock.Now()
 
 	// Update user
 	if err := s.userRepo.Update(user); err != nil {
-		return nil, errors.WrapError(err, "Failed to update user")
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to update user")
+	}
+
+	// Record the previous username so it stays reserved and redirects to the new one
+	if user.Username != oldUsername {
+		entry := &models.UsernameHistory{
+			UserID:        user.ID,
+			OldUsername:   oldUsername,
+			ChangedAt:     user.UpdatedAt,
+			ReservedUntil: user.UpdatedAt.Add(s.cfg.UsernameCooldown),
+		}
+		if err := s.usernameHistoryRepo.Create(entry); err != nil {
+			return nil, errors.WrapError(err, "Failed to record username history")
+		}
 	}
 
-	// Clear password from response
-	user.Password = ""
+	if newEmail != "" {
+		if err := s.requestEmailChange(user, newEmail); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Sanitize()
 
 	return user, nil
 }
 
-// DeleteUser deletes a user
-func (s *userService) DeleteUser(id uuid.UUID) error {
-	// Check if user exists
-	user, err := s.userRepo.GetByID(id)
+// GetPublicProfile gets a public profile by username, resolving previous usernames to a redirect
+func (s *userService) GetPublicProfile(username string) (*models.PublicProfile, *models.UsernameRedirect, error) {
+	user, err := s.userRepo.GetByUsername(username)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, nil, errors.WrapError(err, "Failed to get user")
+	}
+	if user != nil && user.IsActive {
+		// Accounts awaiting parental consent and sandbox accounts cannot be discovered
+		if user.NeedsParentalConsent(s.cfg.ParentalConsentAge, s.cfg.Clock.Now()) || user.Role == models.RoleSandbox {
+			return nil, nil, errors.ErrUserNotFound
+		}
+		return toPublicProfile(user), nil, nil
+	}
+
+	// Fall back to the username history
+	entry, err := s.usernameHistoryRepo.GetLatestByOldUsername(username)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, errors.ErrUserNotFound
+	}
 	if err != nil {
-		return errors.WrapError(err, "Failed to get user")
+		return nil, nil, errors.WrapError(err, "Failed to get username history")
 	}
-	if user == nil {
-		return errors.ErrUserNotFound
+
+	renamed, err := s.userRepo.GetByID(entry.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, nil, errors.WrapError(err, "Failed to get user")
+	}
+	if !renamed.IsActive || renamed.NeedsParentalConsent(s.cfg.ParentalConsentAge, s.cfg.Clock.Now()) {
+		return nil, nil, errors.ErrUserNotFound
+	}
+
+	return nil, &models.UsernameRedirect{
+		OldUsername: username,
+		Username:    renamed.Username,
+	}, nil
+}
+
+// ListUsers gets all users with pagination
+func (s *userService) ListUsers(page, perPage int) ([]*models.User, int, error) {
+	offset := (page - 1) * perPage
+
+	users, err := s.userRepo.List(perPage, offset)
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to list users")
+	}
+
+	total, err := s.userRepo.Count()
+	if err != nil {
+		return nil, 0, errors.WrapError(err, "Failed to count users")
+	}
+
+	for _, user := range users {
+		user.Sanitize()
+	}
+
+	return users, total, nil
+}
+
+// ExportUsers calls fn with every user, oldest first, sanitized like ListUsers
+func (s *userService) ExportUsers(fn func(*models.User) error) error {
+	return s.userRepo.Export(func(user *models.User) error {
+		user.Sanitize()
+		return fn(user)
+	})
+}
+
+// requestEmailChange creates a pending email change, mails a confirmation link to the new
+// address and an undo link to the current one
+func (s *userService) requestEmailChange(user *models.User, newEmail string) error {
+	now := s.cfg.Clock.Now()
+
+	// A new request supersedes any earlier pending one
+	if err := s.emailChangeRepo.CancelPendingForUser(user.ID, now); err != nil {
+		return errors.WrapError(err, "Failed to cancel previous email change")
+	}
+
+	token, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return errors.WrapError(err, "Failed to generate email change token")
+	}
+	undoToken, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return errors.WrapError(err, "Failed to generate email change token")
+	}
+
+	changeReq := &models.EmailChangeRequest{
+		UserID:        user.ID,
+		OldEmail:      user.Email,
+		NewEmail:      newEmail,
+		TokenHash:     auth.HashToken(token),
+		UndoTokenHash: auth.HashToken(undoToken),
+		ExpiresAt:     now.Add(s.cfg.EmailChangeTTL),
+		UndoExpiresAt: now.Add(s.cfg.EmailChangeUndoTTL),
+		CreatedAt:     now,
+	}
+	if err := s.emailChangeRepo.Create(changeReq); err != nil {
+		return errors.WrapError(err, "Failed to create email change request")
+	}
+
+	data := map[string]interface{}{
+		"Username":      user.Username,
+		"OldEmail":      user.Email,
+		"NewEmail":      newEmail,
+		"ConfirmURL":    s.cfg.BaseURL + "/api/v1/auth/email/confirm?token=" + url.QueryEscape(token),
+		"UndoURL":       s.cfg.BaseURL + "/api/v1/auth/email/undo?token=" + url.QueryEscape(undoToken),
+		"ExpiresIn":     s.cfg.EmailChangeTTL.String(),
+		"UndoExpiresIn": s.cfg.EmailChangeUndoTTL.String(),
+	}
+
+	if err := s.sendTemplate("email_change_confirm", newEmail, data); err != nil {
+		return err
+	}
+	return s.sendTemplate("email_change_notice", user.Email, data)
+}
+
+// ConfirmEmailChange applies a pending email change using the token sent to the new address
+func (s *userService) ConfirmEmailChange(token string) (*models.User, error) {
+	changeReq, err := s.emailChangeRepo.GetByTokenHash(auth.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get email change request")
+	}
+	if !changeReq.IsPending() || s.cfg.Clock.Now().After(changeReq.ExpiresAt) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+
+	user, err := s.userRepo.GetByID(changeReq.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	// The address may have been claimed by someone else since the request was made
+	exists, err := s.userRepo.ExistsByEmail(changeReq.NewEmail)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to check email existence")
+	}
+	if exists {
+		return nil, errors.NewAppErrorWithDetails(409, "Email already taken", "Email must be unique", nil)
+	}
+
+	now := s.cfg.Clock.Now()
+	user.Email = changeReq.NewEmail
+	user.PendingEmail = nil
+	user.UpdatedAt = now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to update user")
+	}
+	if err := s.emailChangeRepo.MarkConfirmed(changeReq.ID, now); err != nil {
+		return nil, errors.WrapError(err, "Failed to confirm email change request")
+	}
+
+	return user.Sanitize(), nil
+}
+
+// UndoEmailChange cancels a pending email change, or reverts a confirmed one, using the
+// token sent to the old address. Reverting also revokes all sessions since the change
+// may have been made by someone who took over the account.
+func (s *userService) UndoEmailChange(token string) (*models.User, error) {
+	changeReq, err := s.emailChangeRepo.GetByUndoTokenHash(auth.HashToken(token))
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get email change request")
+	}
+	if changeReq.CancelledAt != nil || s.cfg.Clock.Now().After(changeReq.UndoExpiresAt) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+
+	user, err := s.userRepo.GetByID(changeReq.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidEmailToken
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	now := s.cfg.Clock.Now()
+	wasConfirmed := changeReq.ConfirmedAt != nil
+	if wasConfirmed {
+		user.Email = changeReq.OldEmail
+	}
+	user.PendingEmail = nil
+	user.UpdatedAt = now
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to update user")
+	}
+	if err := s.emailChangeRepo.MarkCancelled(changeReq.ID, now); err != nil {
+		return nil, errors.WrapError(err, "Failed to cancel email change request")
+	}
+
+	if wasConfirmed {
+		if err := s.refreshTokenRepo.RevokeAllForUser(user.ID); err != nil {
+			return nil, errors.WrapError(err, "Failed to revoke sessions")
+		}
+	}
+
+	return user.Sanitize(), nil
+}
+
+// GetPreferences gets the personalization settings of a user
+func (s *userService) GetPreferences(id uuid.UUID) (*models.UserPreferences, error) {
+	prefs, err := s.userRepo.GetPreferences(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get user preferences")
+	}
+
+	return prefs, nil
+}
+
+// UpdatePreferences replaces the personalization settings of a user
+func (s *userService) UpdatePreferences(id uuid.UUID, req *models.UpdatePreferencesRequest) (*models.UserPreferences, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	// Keep the first occurrence of each language, so the order of preference is preserved
+	prefs := &models.UserPreferences{PreferredLanguages: []string{}, Timezone: req.Timezone}
+	if prefs.Timezone == "" {
+		prefs.Timezone = models.DefaultTimezone
+	}
+	for _, lang := range req.PreferredLanguages {
+		if !slices.Contains(prefs.PreferredLanguages, lang) {
+			prefs.PreferredLanguages = append(prefs.PreferredLanguages, lang)
+		}
+	}
+
+	if err := s.userRepo.UpdatePreferences(id, prefs); err != nil {
+		return nil, writeError(err, errors.ErrUserNotFound, "Failed to update user preferences")
+	}
+
+	return prefs, nil
+}
+
+// reencryptBatchSize is how many phone numbers are re-encrypted per transaction
+const reencryptBatchSize = 500
+
+// RotateEncryptionKeys re-encrypts phone numbers still encrypted with an old key in batches,
+// until none are left. Once it has run after a rotation, old keys may be removed.
+func (s *userService) RotateEncryptionKeys() error {
+	keyring := fieldcrypt.Default()
+	if keyring == nil {
+		return nil
+	}
+
+	total := 0
+	for {
+		count, err := s.userRepo.ReencryptPhoneNumbers(keyring.ActiveKeyID(), reencryptBatchSize)
+		total += count
+		if err != nil {
+			return err
+		}
+		if count < reencryptBatchSize {
+			break
+		}
+	}
+	if total > 0 {
+		log.Printf("Re-encrypted %d phone numbers with key %s", total, keyring.ActiveKeyID())
+	}
+
+	return nil
+}
+
+// CheckPasswordStrength estimates how guessable a password is and whether it meets the registration policy
+func (s *userService) CheckPasswordStrength(req *models.PasswordStrengthRequest) (*models.PasswordStrengthResponse, error) {
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	estimate := security.EstimatePasswordStrength(req.Password, req.Username, req.Email)
+
+	return &models.PasswordStrengthResponse{
+		Score:        estimate.Score,
+		GuessesLog10: estimate.GuessesLog10,
+		CrackTime:    estimate.CrackTime,
+		Warning:      estimate.Warning,
+		Suggestions:  estimate.Suggestions,
+		MeetsPolicy:  s.validator.ValidateVar(req.Password, "password") == nil,
+	}, nil
+}
+
+// sendTemplate renders and sends a transactional email
+func (s *userService) sendTemplate(name, to string, data interface{}) error {
+	msg, err := mailer.Render(name, to, data)
+	if err != nil {
+		return errors.WrapError(err, "Failed to render email")
+	}
+	if err := s.mailer.Send(msg); err != nil {
+		return errors.WrapError(err, "Failed to send email")
 	}
+	return nil
+}
 
-	// Delete user
-	if err := s.userRepo.Delete(id); err != nil {
-		return errors.WrapError(err, "Failed to delete user")
+// localTimeLayout is how times are shown in emails, with the abbreviation of their timezone
+const localTimeLayout = "Mon, 02 Jan 2006 15:04 MST"
+
+// localTime formats t for people in loc
+func localTime(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format(localTimeLayout)
+}
+
+// userLocation returns the timezone a user chose, UTC when it cannot be read
+func (s *userService) userLocation(id uuid.UUID) *time.Location {
+	prefs, err := s.userRepo.GetPreferences(id)
+	if err != nil {
+		log.Printf("Failed to get the timezone of user %s, using UTC: %v", id, err)
+		return time.UTC
+	}
+	return prefs.Location()
+}
+
+// toPublicProfile maps a user to its public profile
+func toPublicProfile(user *models.User) *models.PublicProfile {
+	return &models.PublicProfile{
+		ID:        user.ID,
+		Username:  user.Username,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+// DeleteUser deletes a user with their sessions, and deletes or anonymizes their posts per the
+// configured policy, unless the user is under legal hold
+func (s *userService) DeleteUser(id uuid.UUID, client models.ClientInfo) error {
+	if err := s.legalHold.check(id, legalHoldActionDeleteAccount, "", client); err != nil {
+		return err
+	}
+
+	deletion := &models.UserDeletion{
+		UserID:      id,
+		Reason:      "self",
+		PostsPolicy: s.cfg.DeletedUserPosts,
+	}
+	if err := s.userRepo.DeleteCascade(deletion, client); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to delete user")
 	}
 
 	return nil
@@ -188,22 +795,22 @@ func (s *userService) RefreshToken(req *models.RefreshTokenRequest) (*models.Log
 	claims, err := s.jwtMgr.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
 		// Generic error message - don't reveal token state
-		return nil, errors.NewErrorWithCode(401, "Invalid refresh token")
+		return nil, errors.ErrInvalidRefreshToken
 	}
 
 	// Step 2: Get user
 	user, err := s.userRepo.GetByID(claims.UserID)
+	if errors.Is(err, models.ErrNotFound) {
+		// Generic error message - don't reveal user existence
+		return nil, errors.ErrInvalidRefreshToken
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		// Generic error message - don't reveal user existence
-		return nil, errors.NewErrorWithCode(401, "Invalid refresh token")
-	}
 
 	// Step 3: Check if user is active
 	if !user.IsActive {
-		return nil, errors.NewErrorWithCode(403, "Account is deactivated")
+		return nil, errors.ErrAccountDeactivated
 	}
 
 	// Step 4: Generate new token pair (with new token_id)
@@ -220,25 +827,45 @@ func (s *userService) RefreshToken(req *models.RefreshTokenRequest) (*models.Log
 
 	// Step 6: Hash new refresh token
 	tokenHash := auth.HashRefreshToken(tokenPair.RefreshToken)
-	expiresAt := time.Now().Add(s.jwtMgr.GetRefreshDuration())
+	expiresAt := s.cfg.Clock.Now().Add(s.jwtMgr.GetRefreshDuration())
 
 	// Step 7: Atomically rotate token (validate old token with lock, create new, revoke old)
 	// This prevents race conditions and ensures atomicity
-	err = s.refreshTokenRepo.RotateToken(claims.TokenID, newRefreshClaims.TokenID, tokenHash, user.ID, expiresAt)
-	if err != nil {
+	err = s.refreshTokenRepo.RotateToken(claims.TokenID, newRefreshClaims.TokenID, tokenHash, user.ID, expiresAt, sessionMetadata(s.geo, req.Client))
+	if errors.Is(err, models.ErrNotFound) || errors.Is(err, models.ErrConflict) {
 		// Generic error message - don't reveal why token is invalid
-		return nil, errors.NewErrorWithCode(401, "Invalid refresh token")
+		return nil, errors.ErrInvalidRefreshToken
 	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to rotate refresh token")
+	}
+
+	// A refresh counts as a login for activity tracking
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		return nil, errors.WrapError(err, "Failed to update last login")
+	}
+	now := s.cfg.Clock.Now()
+	user.LastLogin = &now
 
 	return &models.LoginResponse{
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
 		TokenType:    tokenPair.TokenType,
 		ExpiresIn:    tokenPair.ExpiresIn,
-		User:         *user,
+		User:         *user.Sanitize(),
 	}, nil
 }
 
+// ListSessions lists the active sessions of a user
+func (s *userService) ListSessions(userID uuid.UUID) ([]*models.RefreshToken, error) {
+	sessions, err := s.refreshTokenRepo.ListActiveForUser(userID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to list sessions")
+	}
+
+	return sessions, nil
+}
+
 // Logout logs out a user by revoking the refresh token
 func (s *userService) Logout(userID uuid.UUID, tokenID string) error {
 	// Revoke the refresh token associated with this token_id
@@ -249,6 +876,73 @@ func (s *userService) Logout(userID uuid.UUID, tokenID string) error {
 	return nil
 }
 
+// ChangePassword changes a user's password after verifying the current one.
+// Other sessions are revoked; the session identified by tokenID stays signed in.
+func (s *userService) ChangePassword(id uuid.UUID, tokenID string, req *models.ChangePasswordRequest) error {
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get user")
+	}
+
+	// Check current password
+	if err := s.hasher.Compare(user.Password, req.CurrentPassword); err != nil {
+		if err == security.ErrHashPoolSaturated {
+			return errors.ErrServerBusy
+		}
+		return errors.NewErrorWithCode(400, "Current password is incorrect")
+	}
+
+	if req.CurrentPassword == req.NewPassword {
+		return errors.NewErrorWithCode(400, "New password must be different from the current password")
+	}
+
+	hashedPassword, err := s.hasher.Generate(req.NewPassword, bcrypt.DefaultCost)
+	if err != nil {
+		return hashError(err, "Failed to hash password")
+	}
+
+	// Updating the password also clears a forced password change
+	if err := s.userRepo.UpdatePassword(id, hashedPassword); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to update password")
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllForUserExcept(id, tokenID); err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+
+	return nil
+}
+
+// ForcePasswordReset requires a user to change their password and signs them out everywhere
+func (s *userService) ForcePasswordReset(id uuid.UUID) error {
+	// Check if user exists
+	_, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
+	if err != nil {
+		return errors.WrapError(err, "Failed to get user")
+	}
+
+	if err := s.userRepo.SetMustChangePassword(id, true); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to flag user for password reset")
+	}
+
+	if err := s.refreshTokenRepo.RevokeAllForUser(id); err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+
+	return nil
+}
+
 // ValidateUser validates a user entity
 func (s *userService) ValidateUser(user *models.User) error {
 	return s.validator.Validate(user)
@@ -257,17 +951,17 @@ func (s *userService) ValidateUser(user *models.User) error {
 // ActivateUser activates a user account
 func (s *userService) ActivateUser(id uuid.UUID) error {
 	// Check if user exists
-	user, err := s.userRepo.GetByID(id)
+	_, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
 	if err != nil {
 		return errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		return errors.ErrUserNotFound
-	}
 
 	// Activate user
 	if err := s.userRepo.Activate(id); err != nil {
-		return errors.WrapError(err, "Failed to activate user")
+		return writeError(err, errors.ErrUserNotFound, "Failed to activate user")
 	}
 
 	return nil
@@ -276,52 +970,332 @@ func (s *userService) ActivateUser(id uuid.UUID) error {
 // DeactivateUser deactivates a user account
 func (s *userService) DeactivateUser(id uuid.UUID) error {
 	// Check if user exists
-	user, err := s.userRepo.GetByID(id)
+	_, err := s.userRepo.GetByID(id)
+	if errors.Is(err, models.ErrNotFound) {
+		return errors.ErrUserNotFound
+	}
 	if err != nil {
 		return errors.WrapError(err, "Failed to get user")
 	}
-	if user == nil {
-		return errors.ErrUserNotFound
-	}
 
-	// Deactivate user
+	// Deactivate user first so refreshes and sign-ins that start from now on are refused
 	if err := s.userRepo.Deactivate(id); err != nil {
-		return errors.WrapError(err, "Failed to deactivate user")
+		return writeError(err, errors.ErrUserNotFound, "Failed to deactivate user")
+	}
+
+	// Revoke sessions, then deny every access token issued so far. Tokens minted by a refresh
+	// that raced with this call are still rejected while the account stays inactive.
+	if err := s.refreshTokenRepo.RevokeAllForUser(id); err != nil {
+		return errors.WrapError(err, "Failed to revoke sessions")
+	}
+	if err := s.userRepo.DenyTokensIssuedBefore(id, s.cfg.Clock.Now()); err != nil {
+		return writeError(err, errors.ErrUserNotFound, "Failed to revoke access tokens")
 	}
 
 	return nil
 }
 
-// AuthenticateUser authenticates a user with email and password
-func (s *userService) AuthenticateUser(req *models.LoginRequest) (*models.LoginResponse, error) {
+// AuthenticateUser authenticates a user with email and password.
+// When risk scoring flags the sign-in, no tokens are issued; a step-up challenge is returned instead
+// and the user must confirm the one-time code emailed to them via VerifyLogin.
+func (s *userService) AuthenticateUser(req *models.LoginRequest) (*models.LoginResponse, *models.StepUpChallenge, error) {
+	req.Email = normalize.Email(req.Email)
+
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	// Get user by email. Unknown emails still pay for a bcrypt compare and get the same
+	// 401 as a wrong password, so responses reveal neither account existence nor timing.
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if errors.Is(err, models.ErrNotFound) {
+		if err := s.hasher.CompareDummy(req.Password); err == security.ErrHashPoolSaturated {
+			return nil, nil, errors.ErrServerBusy
+		}
+		s.audit.record(nil, models.AuditActionLoginFailed, req.Client, nil)
+		s.cfg.LoginFailures.Inc()
+		return nil, nil, errors.ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, nil, errors.WrapError(err, "Failed to get user")
+	}
+
+	// Check password
+	err = s.hasher.Compare(user.Password, req.Password)
+	if err == security.ErrHashPoolSaturated {
+		return nil, nil, errors.ErrServerBusy
+	}
+	if err != nil {
+		s.audit.record(&user.ID, models.AuditActionLoginFailed, req.Client, nil)
+		s.cfg.LoginFailures.Inc()
+		return nil, nil, errors.ErrInvalidCredentials
+	}
+	user.Sanitize()
+
+	// Check if user is active
+	if !user.IsActive {
+		return nil, nil, errors.ErrAccountDeactivated
+	}
+
+	history, err := s.loginHistory(user, req.Client)
+	if err != nil {
+		return nil, nil, err
+	}
+	assessment := s.assessLoginRisk(req.Client, history)
+	if s.riskScorer != nil && assessment.Score >= s.cfg.StepUpThreshold {
+		challenge, err := s.startStepUp(user, req.Client, assessment)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	loginResp, err := s.issueTokens(user, req.Client)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.completeSignIn(user, req.Client, history, assessment)
+
+	return loginResp, nil, nil
+}
+
+// VerifyLogin completes a step-up sign-in with the one-time code sent to the user
+func (s *userService) VerifyLogin(req *models.VerifyLoginRequest) (*models.LoginResponse, error) {
 	// Validate request
 	if err := s.validator.Validate(req); err != nil {
 		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
 	}
 
-	// Get user by email
-	user, err := s.GetUserByEmail(req.Email)
+	challenge, err := s.loginChallengeRepo.GetByID(req.ChallengeID)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, errors.ErrInvalidLoginChallenge
+	}
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to get login challenge")
+	}
+
+	// Every attempt, right or wrong, is counted before the code is compared, so parallel
+	// guesses cannot exceed the limit
+	allowed, err := s.loginChallengeRepo.UseAttempt(challenge.ID, s.cfg.StepUpMaxAttempts, s.cfg.Clock.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, errors.ErrInvalidLoginChallenge
+	}
+
+	if subtle.ConstantTimeCompare([]byte(auth.HashToken(req.Code)), []byte(challenge.CodeHash)) != 1 {
+		s.audit.record(&challenge.UserID, models.AuditActionStepUpFailed, req.Client, nil)
+		s.cfg.LoginFailures.Inc()
+		return nil, errors.ErrInvalidLoginChallenge
+	}
+
+	// Consume atomically so a code can only be used once even under concurrent requests
+	consumed, err := s.loginChallengeRepo.Consume(challenge.ID)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to consume login challenge")
+	}
+	if !consumed {
+		return nil, errors.ErrInvalidLoginChallenge
+	}
+
+	user, err := s.GetUserByID(challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountDeactivated
+	}
+
+	history, err := s.loginHistory(user, req.Client)
 	if err != nil {
 		return nil, err
 	}
 
-	// Get user with password for authentication
-	userWithPassword, err := s.userRepo.GetByEmail(req.Email)
+	loginResp, err := s.issueTokens(user, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	s.audit.record(&user.ID, models.AuditActionStepUpSuccess, req.Client, nil)
+	s.completeSignIn(user, req.Client, history, s.assessLoginRisk(req.Client, history))
+
+	return loginResp, nil
+}
+
+// AuthenticateBreakGlass signs an admin in with their break-glass credential while an operator
+// has it enabled. It skips step-up verification, so every attempt is audited and logged loudly.
+func (s *userService) AuthenticateBreakGlass(req *models.BreakGlassLoginRequest) (*models.LoginResponse, error) {
+	req.Email = normalize.Email(req.Email)
+
+	// Validate request
+	if err := s.validator.Validate(req); err != nil {
+		return nil, errors.WrapErrorWithCode(err, 400, "Validation failed")
+	}
+
+	user, err := s.userRepo.GetByEmail(req.Email)
+	if errors.Is(err, models.ErrNotFound) {
+		return nil, s.breakGlassFailed(nil, req)
+	}
 	if err != nil {
 		return nil, errors.WrapError(err, "Failed to get user")
 	}
 
-	// Check password
-	err = bcrypt.CompareHashAndPassword([]byte(userWithPassword.Password), []byte(req.Password))
+	// Unknown, disabled, expired and mismatched credentials all get the same 401
+	account, err := s.breakGlassRepo.GetByUserID(user.ID)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return nil, errors.WrapError(err, "Failed to get break-glass account")
+	}
+	now := s.cfg.Clock.Now()
+	if account == nil || !account.EnabledAt(now) || !user.IsAdmin() ||
+		subtle.ConstantTimeCompare([]byte(auth.HashToken(req.Credential)), []byte(account.CredentialHash)) != 1 {
+		return nil, s.breakGlassFailed(&user.ID, req)
+	}
+	if !user.IsActive {
+		return nil, errors.ErrAccountDeactivated
+	}
+	user.Sanitize()
+
+	loginResp, err := s.issueTokens(user, req.Client)
 	if err != nil {
-		return nil, errors.ErrUnauthorized
+		return nil, err
+	}
+	if err := s.breakGlassRepo.MarkUsed(user.ID, now); err != nil {
+		log.Printf("Failed to record break-glass sign-in of user %s: %v", user.ID, err)
 	}
 
-	// Check if user is active
-	if !userWithPassword.IsActive {
-		return nil, errors.NewErrorWithCode(403, "Account is deactivated")
+	operator := ""
+	if account.EnabledBy != nil {
+		operator = *account.EnabledBy
+	}
+	s.audit.record(&user.ID, models.AuditActionBreakGlassLogin, req.Client, &breakGlassDetails{Operator: operator, EnabledUntil: account.EnabledUntil})
+	s.cfg.BreakGlassLogins.Inc()
+	log.Printf("BREAK-GLASS: %s signed in with their break-glass account from %s (enabled by %s until %s)",
+		user.Email, req.Client.IPAddress, operator, account.EnabledUntil.UTC().Format(time.RFC3339))
+
+	return loginResp, nil
+}
+
+// breakGlassFailed audits and logs a refused break-glass sign-in, returning its error
+func (s *userService) breakGlassFailed(userID *uuid.UUID, req *models.BreakGlassLoginRequest) error {
+	s.audit.record(userID, models.AuditActionBreakGlassLoginFailed, req.Client, nil)
+	s.cfg.LoginFailures.Inc()
+	log.Printf("BREAK-GLASS: refused sign-in as %s from %s", req.Email, req.Client.IPAddress)
+	return errors.ErrInvalidCredentials
+}
+
+// completeSignIn records a successful sign-in and notifies the user when it came from a new IP address
+func (s *userService) completeSignIn(user *models.User, client models.ClientInfo, history security.LoginHistory, assessment *security.RiskAssessment) {
+	s.audit.record(&user.ID, models.AuditActionLoginSuccess, client, loginAuditDetails(assessment))
+
+	if !s.cfg.NotifyNewSignIns || !history.HasPreviousLogins || history.KnownIP {
+		return
+	}
+
+	location := ""
+	if country, city := locate(s.geo, client.IPAddress); country != nil {
+		location = *country
+		if city != nil {
+			location = *city + ", " + location
+		}
 	}
 
+	// The sign-in already succeeded; a failed notification must not undo it
+	if err := s.sendTemplate("new_sign_in_notice", user.Email, map[string]interface{}{
+		"Username":  user.Username,
+		"IPAddress": client.IPAddress,
+		"Location":  location,
+		"UserAgent": client.UserAgent,
+		"Time":      localTime(s.cfg.Clock.Now(), s.userLocation(user.ID)),
+	}); err != nil {
+		log.Printf("Failed to send sign-in notification to user %s: %v", user.ID, err)
+	}
+}
+
+// loginHistory summarizes the user's previous successful sign-ins relative to client
+func (s *userService) loginHistory(user *models.User, client models.ClientInfo) (security.LoginHistory, error) {
+	// Without a previous sign-in or a client IP there is nothing to compare against
+	history := security.LoginHistory{KnownIP: true}
+	if s.riskScorer == nil && !s.cfg.NotifyNewSignIns {
+		return history, nil
+	}
+
+	last, err := s.auditLogRepo.GetLatestByUserAndAction(user.ID, models.AuditActionLoginSuccess)
+	if err != nil && !errors.Is(err, models.ErrNotFound) {
+		return history, errors.WrapError(err, "Failed to get login history")
+	}
+
+	history.HasPreviousLogins = last != nil
+	if last != nil {
+		history.LastLoginAt = last.CreatedAt
+		var details loginDetails
+		if err := json.Unmarshal(last.Details, &details); err == nil {
+			history.LastLocation = details.Location
+		}
+
+		if client.IPAddress != "" {
+			history.KnownIP, err = s.auditLogRepo.ExistsForUserFromIP(user.ID, models.AuditActionLoginSuccess, client.IPAddress)
+			if err != nil {
+				return history, errors.WrapError(err, "Failed to get login history")
+			}
+		}
+	}
+
+	return history, nil
+}
+
+// assessLoginRisk scores a sign-in against the user's login history.
+// It returns an empty assessment when risk scoring is disabled.
+func (s *userService) assessLoginRisk(client models.ClientInfo, history security.LoginHistory) *security.RiskAssessment {
+	if s.riskScorer == nil {
+		return &security.RiskAssessment{}
+	}
+	return s.riskScorer.Assess(client.IPAddress, history, s.cfg.Clock.Now())
+}
+
+// startStepUp creates a login challenge and emails its one-time code to the user
+func (s *userService) startStepUp(user *models.User, client models.ClientInfo, assessment *security.RiskAssessment) (*models.StepUpChallenge, error) {
+	code, err := auth.GenerateNumericCode(6)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to generate verification code")
+	}
+
+	now := s.cfg.Clock.Now()
+	challenge := &models.LoginChallenge{
+		UserID:    user.ID,
+		CodeHash:  auth.HashToken(code),
+		ExpiresAt: now.Add(s.cfg.StepUpCodeTTL),
+		CreatedAt: now,
+	}
+	if client.IPAddress != "" {
+		challenge.IPAddress = &client.IPAddress
+	}
+
+	if err := s.loginChallengeRepo.Create(challenge); err != nil {
+		return nil, errors.WrapError(err, "Failed to create login challenge")
+	}
+
+	if err := s.sendTemplate("login_otp", user.Email, map[string]interface{}{
+		"Username":  user.Username,
+		"Code":      code,
+		"IPAddress": client.IPAddress,
+		"ExpiresIn": s.cfg.StepUpCodeTTL.String(),
+	}); err != nil {
+		return nil, err
+	}
+
+	s.audit.record(&user.ID, models.AuditActionStepUpRequired, client, loginAuditDetails(assessment))
+
+	return &models.StepUpChallenge{
+		ChallengeID: challenge.ID,
+		Method:      models.StepUpMethodEmailOTP,
+		ExpiresAt:   challenge.ExpiresAt,
+	}, nil
+}
+
+// issueTokens generates and stores a new token pair for a user and records the login
+func (s *userService) issueTokens(user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
 	// Generate JWT token pair
 	tokenPair, err := s.jwtMgr.GenerateTokenPair(user)
 	if err != nil {
@@ -338,18 +1312,45 @@ func (s *userService) AuthenticateUser(req *models.LoginRequest) (*models.LoginR
 	tokenHash := auth.HashRefreshToken(tokenPair.RefreshToken)
 
 	// Calculate expiration time from refresh token duration
-	expiresAt := time.Now().Add(s.jwtMgr.GetRefreshDuration())
+	expiresAt := s.cfg.Clock.Now().Add(s.jwtMgr.GetRefreshDuration())
+
+	// Each device holds a single session: signing in again replaces the device's previous session
+	if err := s.refreshTokenRepo.RevokeForDevice(user.ID, client.DeviceID, client.Fingerprint); err != nil {
+		return nil, errors.WrapError(err, "Failed to replace device session")
+	}
 
 	// Store refresh token in database
-	if err := s.refreshTokenRepo.Create(refreshClaims.TokenID, tokenHash, user.ID, expiresAt); err != nil {
+	if err := s.refreshTokenRepo.Create(refreshClaims.TokenID, tokenHash, user.ID, expiresAt, sessionMetadata(s.geo, client)); err != nil {
 		return nil, errors.WrapError(err, "Failed to store refresh token")
 	}
 
+	// Enforce the device limit by evicting the oldest sessions
+	if s.cfg.MaxDevicesPerUser > 0 {
+		if err := s.refreshTokenRepo.RevokeOldestBeyond(user.ID, s.cfg.MaxDevicesPerUser); err != nil {
+			return nil, errors.WrapError(err, "Failed to evict old sessions")
+		}
+	}
+
+	// Record successful login
+	if err := s.userRepo.UpdateLastLogin(user.ID); err != nil {
+		return nil, errors.WrapError(err, "Failed to update last login")
+	}
+	now := s.cfg.Clock.Now()
+	user.LastLogin = &now
+
 	return &models.LoginResponse{
 		AccessToken:  tokenPair.AccessToken,
 		RefreshToken: tokenPair.RefreshToken,
 		TokenType:    tokenPair.TokenType,
 		ExpiresIn:    tokenPair.ExpiresIn,
-		User:         *user,
+		User:         *user.Sanitize(),
 	}, nil
 }
+
+// hashError maps a password hashing failure to an API error, shedding load with 503 when the hash pool is full
+func hashError(err error, message string) error {
+	if err == security.ErrHashPoolSaturated {
+		return errors.ErrServerBusy
+	}
+	return errors.WrapError(err, message)
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Storage backends
+const (
+	BackendLocal = "local"
+	BackendS3    = "s3"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("storage: object not found")
+
+// Storage stores objects under slash-separated keys
+type Storage interface {
+	// Put stores size bytes read from body under key, replacing an existing object
+	Put(key string, body io.Reader, size int64) error
+	// Get opens the object stored under key; the caller closes it
+	Get(key string) (io.ReadCloser, error)
+	// Name describes where objects are stored, for logs
+	Name() string
+}
+
+// Config selects and configures a storage backend
+type Config struct {
+	Backend  string // "local" or "s3"
+	LocalDir string
+
+	S3Bucket          string
+	S3Region          string
+	S3Endpoint        string // Defaults to AWS; set for S3-compatible services
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3PathStyle       bool // Address buckets as endpoint/bucket rather than bucket.endpoint
+	Prefix            string
+}
+
+// New creates the configured storage backend
+func New(cfg Config) (Storage, error) {
+	prefix := strings.Trim(cfg.Prefix, "/")
+	switch strings.ToLower(strings.TrimSpace(cfg.Backend)) {
+	case BackendLocal:
+		if cfg.LocalDir == "" {
+			return nil, fmt.Errorf("local storage requires a directory")
+		}
+		return &localStorage{dir: cfg.LocalDir, prefix: prefix}, nil
+	case BackendS3:
+		if cfg.S3Bucket == "" || cfg.S3Region == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, fmt.Errorf("s3 storage requires a bucket, region and credentials")
+		}
+		return newS3Storage(cfg, prefix), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// join prefixes a key
+func join(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "/" + key
+}
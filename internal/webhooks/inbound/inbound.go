@@ -0,0 +1,232 @@
+// Package inbound receives webhooks from third-party services. Each provider (Stripe, GitHub,
+// SendGrid...) is registered with a verifier authenticating its deliveries, how to tell its
+// events apart, and a handler function; the registry takes care of verification, processing
+// each event once, and letting the provider retry deliveries whose processing failed.
+package inbound
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+	"go-backend-api/internal/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodyBytes caps the size of a delivery
+const MaxBodyBytes = 1 << 20
+
+// ClaimTimeout is how long an event stays claimed by a delivery that neither completed nor
+// released it, say because its instance crashed, before a redelivery may claim it. It should
+// outlast the slowest handler.
+const ClaimTimeout = 10 * time.Minute
+
+// Delivery is a webhook request received from a provider
+type Delivery struct {
+	Provider   string
+	Header     http.Header
+	Body       []byte // Exactly as sent, since signatures cover the raw bytes
+	ReceivedAt time.Time
+}
+
+// HandlerFunc processes a verified delivery. Returning an error makes the delivery fail with
+// it, so that the provider retries it.
+type HandlerFunc func(d *Delivery) error
+
+// EventIDFunc returns the ID of the event a delivery carries, which deliveries are
+// deduplicated by; "" processes the delivery whatever was processed before
+type EventIDFunc func(d *Delivery) (string, error)
+
+// Provider is a source of webhooks
+type Provider struct {
+	// Name is the path segment deliveries are posted to, e.g. stripe for /webhooks/stripe
+	Name     string
+	Verifier Verifier
+	// EventID identifies events for idempotent processing; nil processes every delivery,
+	// for providers whose handlers are idempotent themselves
+	EventID EventIDFunc
+	Handle  HandlerFunc
+}
+
+// Store records the events being processed and processed per provider
+type Store interface {
+	// Claim records at now that an event is being processed, unless it was processed or is
+	// being processed under a claim made since expiredBefore
+	Claim(provider, eventID string, now, expiredBefore time.Time) (models.WebhookClaim, error)
+	// Complete records at now that a claimed event was processed
+	Complete(provider, eventID string, now time.Time) error
+	// Release forgets a claimed event whose processing failed, so its redelivery is processed
+	Release(provider, eventID string) error
+}
+
+// Registry dispatches deliveries to the registered providers
+type Registry struct {
+	store Store
+
+	mutex     sync.RWMutex
+	providers map[string]*Provider
+}
+
+// NewRegistry creates a registry recording processed events in store
+func NewRegistry(store Store) *Registry {
+	return &Registry{store: store, providers: make(map[string]*Provider)}
+}
+
+// Register adds a provider, replacing any with its name
+func (r *Registry) Register(provider *Provider) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.providers[provider.Name] = provider
+}
+
+// Providers returns the names of the registered providers
+func (r *Registry) Providers() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Process verifies a delivery and hands it to its provider, unless its event was already
+// processed, in which case it returns true. The event is claimed before it is handled,
+// completed once handled, and released when handling fails or panics, so a retried delivery
+// is processed again. A delivery of an event another delivery is still processing fails with
+// ErrWebhookInFlight rather than being acknowledged, since that processing may yet fail.
+func (r *Registry) Process(d *Delivery) (duplicate bool, err error) {
+	r.mutex.RLock()
+	provider := r.providers[d.Provider]
+	r.mutex.RUnlock()
+	if provider == nil {
+		return false, errors.ErrWebhookProviderNotFound
+	}
+
+	if err := provider.Verifier.Verify(d); err != nil {
+		log.Printf("Refused %s webhook: %v", d.Provider, err)
+		return false, errors.ErrWebhookSignatureInvalid
+	}
+
+	var eventID string
+	if provider.EventID != nil {
+		if eventID, err = provider.EventID(d); err != nil {
+			return false, errors.ErrWebhookPayloadInvalid.WithDetails(err.Error())
+		}
+	}
+	if eventID == "" {
+		return false, provider.Handle(d)
+	}
+
+	now := time.Now()
+	claim, err := r.store.Claim(d.Provider, eventID, now, now.Add(-ClaimTimeout))
+	if err != nil {
+		return false, errors.WrapError(err, "Failed to claim webhook event")
+	}
+	switch claim {
+	case models.WebhookProcessed:
+		return true, nil
+	case models.WebhookInFlight:
+		return false, errors.ErrWebhookInFlight
+	}
+
+	released := false
+	release := func() {
+		released = true
+		if err := r.store.Release(d.Provider, eventID); err != nil {
+			log.Printf("Failed to release %s webhook event %s: %v", d.Provider, eventID, err)
+		}
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			if !released {
+				release()
+			}
+			panic(p)
+		}
+	}()
+
+	if err := provider.Handle(d); err != nil {
+		release()
+		return false, err
+	}
+
+	// The event was handled, so acknowledge it even if this fails; its claim then expires
+	// and only a redelivery after ClaimTimeout processes it again
+	if err := r.store.Complete(d.Provider, eventID, time.Now()); err != nil {
+		log.Printf("Failed to complete %s webhook event %s: %v", d.Provider, eventID, err)
+	}
+
+	return false, nil
+}
+
+// Handler serves deliveries posted to the provider named by the :provider path parameter
+func (r *Registry) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, MaxBodyBytes))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			response.Error(c, errors.ErrWebhookTooLarge)
+			return
+		}
+		if err != nil {
+			response.BadRequest(c, "Failed to read webhook payload")
+			return
+		}
+
+		duplicate, err := r.Process(&Delivery{
+			Provider:   c.Param("provider"),
+			Header:     c.Request.Header,
+			Body:       body,
+			ReceivedAt: time.Now(),
+		})
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+
+		if duplicate {
+			response.SuccessWithMessage(c, "Webhook already processed", nil)
+			return
+		}
+		response.SuccessWithMessage(c, "Webhook received", nil)
+	}
+}
+
+// JSONField identifies events by a top-level string field of a JSON body, such as Stripe's id
+func JSONField(name string) EventIDFunc {
+	return func(d *Delivery) (string, error) {
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(d.Body, &fields); err != nil {
+			return "", err
+		}
+		var id string
+		if raw, ok := fields[name]; ok {
+			if err := json.Unmarshal(raw, &id); err != nil {
+				return "", err
+			}
+		}
+		if id == "" {
+			return "", fmt.Errorf("missing %s", name)
+		}
+		return id, nil
+	}
+}
+
+// HeaderField identifies events by a request header, such as GitHub's X-GitHub-Delivery
+func HeaderField(name string) EventIDFunc {
+	return func(d *Delivery) (string, error) {
+		id := d.Header.Get(name)
+		if id == "" {
+			return "", fmt.Errorf("missing %s header", name)
+		}
+		return id, nil
+	}
+}
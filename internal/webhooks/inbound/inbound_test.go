@@ -0,0 +1,149 @@
+package inbound
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go-backend-api/internal/models"
+	"go-backend-api/internal/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+// memoryStore keeps claims in memory, like the webhook_events table
+type memoryStore struct {
+	mu     sync.Mutex
+	events map[string]*memoryEvent
+}
+
+type memoryEvent struct {
+	claimedAt time.Time
+	processed bool
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{events: map[string]*memoryEvent{}}
+}
+
+func (s *memoryStore) Claim(provider, eventID string, now, expiredBefore time.Time) (models.WebhookClaim, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event, ok := s.events[provider+"/"+eventID]
+	switch {
+	case !ok:
+		s.events[provider+"/"+eventID] = &memoryEvent{claimedAt: now}
+		return models.WebhookClaimed, nil
+	case event.processed:
+		return models.WebhookProcessed, nil
+	case event.claimedAt.Before(expiredBefore):
+		event.claimedAt = now
+		return models.WebhookClaimed, nil
+	}
+	return models.WebhookInFlight, nil
+}
+
+func (s *memoryStore) Complete(provider, eventID string, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[provider+"/"+eventID].processed = true
+	return nil
+}
+
+func (s *memoryStore) Release(provider, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.events, provider+"/"+eventID)
+	return nil
+}
+
+// trusting accepts every delivery
+type trusting struct{}
+
+func (trusting) Verify(*Delivery) error { return nil }
+
+// deliver posts an event with id to the test provider
+func deliver(r http.Handler, id string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhooks/test", strings.NewReader(`{"id":"`+id+`"}`)))
+	return w
+}
+
+func newTestRouter(store Store, handle HandlerFunc) *gin.Engine {
+	registry := NewRegistry(store)
+	registry.Register(&Provider{Name: "test", Verifier: trusting{}, EventID: JSONField("id"), Handle: handle})
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/webhooks/:provider", registry.Handler())
+	return r
+}
+
+func TestRedeliveriesOfAnEventInFlightAreRetried(t *testing.T) {
+	started := make(chan struct{})
+	finish := make(chan error)
+	var handled sync.WaitGroup
+	calls := 0
+	r := newTestRouter(newMemoryStore(), func(*Delivery) error {
+		calls++
+		started <- struct{}{}
+		return <-finish
+	})
+
+	// The first delivery fails once a redelivery arrived, which must not have been acknowledged
+	handled.Add(1)
+	go func() {
+		defer handled.Done()
+		if w := deliver(r, "evt_1"); w.Code != http.StatusInternalServerError {
+			t.Errorf("failing delivery: got %d, want 500", w.Code)
+		}
+	}()
+	<-started
+	w := deliver(r, "evt_1")
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), "WEBHOOK_IN_FLIGHT") {
+		t.Fatalf("redelivery in flight: got %d %s, want 409 WEBHOOK_IN_FLIGHT", w.Code, w.Body)
+	}
+	finish <- errors.ErrInternal
+	handled.Wait()
+
+	// The provider's retry processes the event, and later redeliveries are acknowledged
+	handled.Add(1)
+	go func() {
+		defer handled.Done()
+		if w := deliver(r, "evt_1"); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Webhook received") {
+			t.Errorf("retry: got %d %s, want it processed", w.Code, w.Body)
+		}
+	}()
+	<-started
+	finish <- nil
+	handled.Wait()
+
+	w = deliver(r, "evt_1")
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "Webhook already processed") {
+		t.Errorf("redelivery once processed: got %d %s, want it acknowledged", w.Code, w.Body)
+	}
+	if calls != 2 {
+		t.Errorf("handled %d times, want 2", calls)
+	}
+}
+
+func TestAbandonedClaimsExpire(t *testing.T) {
+	store := newMemoryStore()
+	// Claimed by a delivery whose instance crashed before completing or releasing it
+	store.events["test/evt_1"] = &memoryEvent{claimedAt: time.Now().Add(-ClaimTimeout - time.Minute)}
+	store.events["test/evt_2"] = &memoryEvent{claimedAt: time.Now().Add(-time.Minute)}
+	calls := 0
+	r := newTestRouter(store, func(*Delivery) error {
+		calls++
+		return nil
+	})
+
+	if w := deliver(r, "evt_1"); w.Code != http.StatusOK || calls != 1 {
+		t.Errorf("expired claim: got %d after %d calls, want it processed", w.Code, calls)
+	}
+	if w := deliver(r, "evt_2"); w.Code != http.StatusConflict {
+		t.Errorf("recent claim: got %d, want 409", w.Code)
+	}
+}
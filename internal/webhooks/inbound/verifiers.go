@@ -0,0 +1,121 @@
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"go-backend-api/internal/pkg/security"
+)
+
+// Verifier authenticates the deliveries of a provider
+type Verifier interface {
+	// Verify returns an error if the delivery was not sent by the provider
+	Verify(d *Delivery) error
+}
+
+// HMAC verifies deliveries signed with an HMAC-SHA256 of their body, as GitHub does with
+// X-Hub-Signature-256: sha256=<hex>
+type HMAC struct {
+	Header string
+	// Prefix precedes the signature in the header, e.g. "sha256="
+	Prefix string
+	Secret string
+	// Base64 reads the signature as base64 instead of hex
+	Base64 bool
+}
+
+// Verify checks the signature header against the body
+func (v *HMAC) Verify(d *Delivery) error {
+	value := d.Header.Get(v.Header)
+	if value == "" {
+		return security.ErrSignatureMissing
+	}
+	encoded, ok := strings.CutPrefix(value, v.Prefix)
+	if !ok {
+		return security.ErrSignatureMalformed
+	}
+
+	var signature []byte
+	var err error
+	if v.Base64 {
+		signature, err = base64.StdEncoding.DecodeString(encoded)
+	} else {
+		signature, err = hex.DecodeString(encoded)
+	}
+	if err != nil {
+		return security.ErrSignatureMalformed
+	}
+
+	mac := hmac.New(sha256.New, []byte(v.Secret))
+	mac.Write(d.Body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return security.ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Basic verifies deliveries sent with HTTP Basic credentials, as configured in the webhook
+// URL of providers that do not sign their payloads, like SendGrid's event webhook
+type Basic struct {
+	Username string
+	Password string
+}
+
+// Verify checks the Basic credentials of the delivery
+func (v *Basic) Verify(d *Delivery) error {
+	req := http.Request{Header: d.Header}
+	username, password, ok := req.BasicAuth()
+	if !ok {
+		return security.ErrSignatureMissing
+	}
+	// Both are compared so that the time taken does not tell which one is wrong
+	userOK := subtle.ConstantTimeCompare([]byte(username), []byte(v.Username)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(v.Password)) == 1
+	if !userOK || !passwordOK {
+		return security.ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Token verifies deliveries carrying a shared token in a header, e.g. GitLab's X-Gitlab-Token
+type Token struct {
+	Header string
+	// Prefix precedes the token in the header, e.g. "Bearer "
+	Prefix string
+	Token  string
+}
+
+// Verify checks the token header of the delivery
+func (v *Token) Verify(d *Delivery) error {
+	value := d.Header.Get(v.Header)
+	if value == "" {
+		return security.ErrSignatureMissing
+	}
+	token, ok := strings.CutPrefix(value, v.Prefix)
+	if !ok {
+		return security.ErrSignatureMalformed
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(v.Token)) != 1 {
+		return security.ErrSignatureMismatch
+	}
+	return nil
+}
+
+// Stripe verifies the Stripe-Signature header of Stripe deliveries
+type Stripe struct {
+	// Secret is the signing secret of the webhook endpoint (whsec_...)
+	Secret string
+	// Tolerance is how old a delivery's signature may be (0 is security.DefaultStripeSignatureTolerance)
+	Tolerance time.Duration
+}
+
+// Verify checks the signature against the time the delivery was received
+func (v *Stripe) Verify(d *Delivery) error {
+	return security.VerifyStripeSignature(d.Header.Get(security.StripeSignatureHeader), d.Body, v.Secret, v.Tolerance, d.ReceivedAt)
+}